@@ -22,8 +22,10 @@ import (
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun"
+	"github.com/tektoncd/pipeline/pkg/reconciler/server"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/injection/sharedmain"
 	"knative.dev/pkg/signals"
@@ -45,6 +47,8 @@ var (
 	buildGCSFetcherImage     = flag.String("build-gcs-fetcher-image", "", "The container image containing our GCS fetcher binary.")
 	prImage                  = flag.String("pr-image", "", "The container image containing our PR binary.")
 	imageDigestExporterImage = flag.String("imagedigest-exporter-image", "", "The container image containing our image digest exporter binary.")
+	awsCLIImage              = flag.String("awscli-image", "", "The container image containing the aws CLI, required to copy workspaces from and to S3")
+	trivyImage               = flag.String("trivy-image", "", "The container image containing Trivy, required to scan the images of steps for vulnerabilities")
 	namespace                = flag.String("namespace", corev1.NamespaceAll, "Namespace to restrict informer to. Optional, defaults to all namespaces.")
 )
 
@@ -61,12 +65,18 @@ func main() {
 		BuildGCSFetcherImage:     *buildGCSFetcherImage,
 		PRImage:                  *prImage,
 		ImageDigestExporterImage: *imageDigestExporterImage,
+		AWSCLIImage:              *awsCLIImage,
+		TrivyImage:               *trivyImage,
 	}
 	if err := images.Validate(); err != nil {
 		log.Fatal(err)
 	}
-	sharedmain.MainWithContext(injection.WithNamespaceScope(signals.NewContext(), *namespace), ControllerLogKey,
-		taskrun.NewController(*namespace, images),
-		pipelinerun.NewController(*namespace, images),
+	ctx := injection.WithNamespaceScope(signals.NewContext(), *namespace)
+	// The controllers register their endpoints on the server
+	endpoints := server.New()
+	go endpoints.Serve(ctx)
+	sharedmain.MainWithContext(server.WithServer(ctx, endpoints), ControllerLogKey,
+		taskrun.NewController(*namespace, images, clock.RealClock{}),
+		pipelinerun.NewController(*namespace, images, clock.RealClock{}),
 	)
 }
@@ -17,6 +17,9 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"log"
 	"os"
@@ -25,6 +28,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/credentials"
 	"github.com/tektoncd/pipeline/pkg/credentials/dockercreds"
 	"github.com/tektoncd/pipeline/pkg/credentials/gitcreds"
@@ -39,6 +43,14 @@ var (
 	postFile            = flag.String("post_file", "", "If specified, file to write upon completion")
 	terminationPath     = flag.String("termination_path", "/tekton/termination", "If specified, file to write upon termination")
 	results             = flag.String("results", "", "If specified, list of file names that might contain task results")
+	resultJSONPaths     = flag.String("result_json_paths", "", "If specified, JSON object mapping result names to the JSONPath extracting their value")
+	resultsLogDelimiter = flag.String("results_log_delimiter", "", "If specified, print the task results to stdout enclosed by this delimiter instead of writing them to the termination message")
+	restartOnFailure    = flag.Bool("restart_on_failure", false, "If specified, run the entrypoint again when it exits with a non-zero exit code")
+	hermetic            = flag.Bool("hermetic", false, "If specified, run the entrypoint without network")
+	stdoutResults       = flag.Bool("stdout_results", false, "If specified, set the task results the entrypoint prints result markers for to its stdout")
+	timeout             = flag.Duration("timeout", 0, "If specified, kill the entrypoint once it has run for this long, plus the time left unused by the previous steps")
+	preStepHook         = flag.String("pre_step_hook", "", "If specified, shell script to run before the entrypoint, which isn't run if the script fails")
+	postStepHook        = flag.String("post_step_hook", "", "If specified, shell script to run after the entrypoint, with its exit code as argument")
 	waitPollingInterval = time.Second
 )
 
@@ -59,17 +71,39 @@ func main() {
 		}
 	}
 
+	var jsonPaths map[string]string
+	if *resultJSONPaths != "" {
+		if err := json.Unmarshal([]byte(*resultJSONPaths), &jsonPaths); err != nil {
+			log.Fatalf("Error parsing result JSONPaths: %v", err)
+		}
+	}
+
+	rr := &realRunner{hermetic: *hermetic}
+	if *stdoutResults {
+		rr.stdoutResults = &entrypoint.StdoutResultsWriter{
+			W:          os.Stdout,
+			ResultsDir: pipeline.DefaultResultPath,
+			Results:    strings.Split(*results, ","),
+		}
+	}
+
 	e := entrypoint.Entrypointer{
-		Entrypoint:      *ep,
-		WaitFiles:       strings.Split(*waitFiles, ","),
-		WaitFileContent: *waitFileContent,
-		PostFile:        *postFile,
-		TerminationPath: *terminationPath,
-		Args:            flag.Args(),
-		Waiter:          &realWaiter{},
-		Runner:          &realRunner{},
-		PostWriter:      &realPostWriter{},
-		Results:         strings.Split(*results, ","),
+		Entrypoint:          *ep,
+		WaitFiles:           strings.Split(*waitFiles, ","),
+		WaitFileContent:     *waitFileContent,
+		PostFile:            *postFile,
+		TerminationPath:     *terminationPath,
+		Args:                flag.Args(),
+		Waiter:              &realWaiter{},
+		Runner:              rr,
+		PostWriter:          &realPostWriter{},
+		Results:             strings.Split(*results, ","),
+		ResultJSONPaths:     jsonPaths,
+		ResultsLogDelimiter: *resultsLogDelimiter,
+		RestartOnFailure:    *restartOnFailure,
+		Timeout:             *timeout,
+		PreStepHook:         *preStepHook,
+		PostStepHook:        *postStepHook,
 	}
 
 	// Copy any creds injected by the controller into the $HOME directory of the current
@@ -79,6 +113,10 @@ func main() {
 	}
 
 	if err := e.Go(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Print("Step timed out")
+			os.Exit(1)
+		}
 		switch t := err.(type) {
 		case skipError:
 			log.Print("Skipping step because a previous step failed")
@@ -86,6 +124,12 @@ func main() {
 		case termination.MessageLengthError:
 			log.Print(err.Error())
 			os.Exit(1)
+		case entrypoint.ResultExtractionError:
+			log.Print(err.Error())
+			os.Exit(1)
+		case hermeticError:
+			log.Print(err.Error())
+			os.Exit(1)
 		case *exec.ExitError:
 			// Copied from https://stackoverflow.com/questions/10385551/get-exit-code-go
 			// This works on both Unix and Windows. Although
@@ -0,0 +1,33 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import "fmt"
+
+// hermeticError is returned when a hermetic step can't be isolated from the
+// network on the node it runs on.
+type hermeticError struct {
+	err error
+}
+
+func (e hermeticError) Error() string {
+	return fmt.Sprintf("hermetic step could not be isolated from the network: the step needs the CAP_SYS_ADMIN capability, or the node needs to allow unprivileged user namespaces: %v", e.err)
+}
+
+func (e hermeticError) Unwrap() error {
+	return e.err
+}
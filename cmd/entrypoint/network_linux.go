@@ -0,0 +1,52 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// startWithoutNetwork starts the command returned by newCommand in a new
+// network namespace, where only a loopback interface that is down exists.
+//
+// Creating a network namespace requires CAP_SYS_ADMIN, which privileged steps
+// have. Otherwise the network namespace is created in a new user namespace,
+// mapping the user and group of the step to themselves, which works on nodes
+// allowing unprivileged user namespaces.
+func startWithoutNetwork(newCommand func() *exec.Cmd) (*exec.Cmd, error) {
+	cmd := newCommand()
+	cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWNET
+	err := cmd.Start()
+	if err == nil || !errors.Is(err, syscall.EPERM) {
+		return cmd, err
+	}
+
+	cmd = newCommand()
+	cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+	cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Geteuid(), HostID: os.Geteuid(), Size: 1}}
+	cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getegid(), HostID: os.Getegid(), Size: 1}}
+	if err := cmd.Start(); err != nil {
+		return nil, hermeticError{err: err}
+	}
+	return cmd, nil
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestHelperGet isn't a real test: it is run by the runners in
+// TestRealRunnerHermetic as the command of a step, to get the URL in
+// HELPER_GET_URL and exit with a non-zero exit code if it can't.
+func TestHelperGet(t *testing.T) {
+	url := os.Getenv("HELPER_GET_URL")
+	if url == "" {
+		return
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	resp.Body.Close()
+	os.Exit(0)
+}
+
+// TestRealRunnerHermetic runs a command getting a local HTTP server with a
+// regular runner and with a hermetic runner. It verifies that only the
+// command run by the hermetic runner can't reach the server.
+func TestRealRunnerHermetic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	os.Setenv("HELPER_GET_URL", srv.URL)
+	defer os.Unsetenv("HELPER_GET_URL")
+	get := []string{os.Args[0], "-test.run=^TestHelperGet$"}
+
+	rr := realRunner{}
+	if err := rr.Run(context.Background(), get...); err != nil {
+		t.Fatalf("Expected the server to be reachable by a regular step, got %v", err)
+	}
+
+	rr = realRunner{hermetic: true}
+	err := rr.Run(context.Background(), get...)
+	var hermeticErr hermeticError
+	if errors.As(err, &hermeticErr) {
+		t.Skipf("Network namespaces can't be created here: %v", err)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected the server to be unreachable by a hermetic step, got %v", err)
+	}
+}
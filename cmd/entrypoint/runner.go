@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -15,11 +16,16 @@ import (
 // realRunner actually runs commands.
 type realRunner struct {
 	signals chan os.Signal
+	// hermetic runs the commands without network.
+	hermetic bool
+	// stdoutResults, when set, copies the stdout of the commands to os.Stdout
+	// and sets the results they print result markers for.
+	stdoutResults *entrypoint.StdoutResultsWriter
 }
 
 var _ entrypoint.Runner = (*realRunner)(nil)
 
-func (rr *realRunner) Run(args ...string) error {
+func (rr *realRunner) Run(ctx context.Context, args ...string) error {
 	if len(args) == 0 {
 		return nil
 	}
@@ -33,16 +39,31 @@ func (rr *realRunner) Run(args ...string) error {
 	signal.Notify(rr.signals)
 	defer signal.Reset()
 
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	// dedicated PID group used to forward signals to
-	// main process and all children
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	newCommand := func() *exec.Cmd {
+		cmd := exec.Command(name, args...)
+		cmd.Stdout = os.Stdout
+		if rr.stdoutResults != nil {
+			cmd.Stdout = rr.stdoutResults
+		}
+		cmd.Stderr = os.Stderr
+		// dedicated PID group used to forward signals to
+		// main process and all children
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return cmd
+	}
 
 	// Start defined command
-	if err := cmd.Start(); err != nil {
-		return err
+	var cmd *exec.Cmd
+	if rr.hermetic {
+		var err error
+		if cmd, err = startWithoutNetwork(newCommand); err != nil {
+			return err
+		}
+	} else {
+		cmd = newCommand()
+		if err := cmd.Start(); err != nil {
+			return err
+		}
 	}
 
 	// Goroutine for signals forwarding
@@ -55,8 +76,28 @@ func (rr *realRunner) Run(args ...string) error {
 		}
 	}()
 
+	// Goroutine killing the main process and all children once ctx is done
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		case <-exited:
+		}
+	}()
+
 	// Wait for command to exit
-	if err := cmd.Wait(); err != nil {
+	err := cmd.Wait()
+	if rr.stdoutResults != nil {
+		if fErr := rr.stdoutResults.Flush(); fErr != nil && err == nil {
+			return fErr
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
 
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
 )
 
 // TestRealRunnerSignalForwarding will artificially put an interrupt signal (SIGINT) in the rr.signals chan.
@@ -14,9 +21,47 @@ func TestRealRunnerSignalForwarding(t *testing.T) {
 	rr := realRunner{}
 	rr.signals = make(chan os.Signal, 1)
 	rr.signals <- syscall.SIGINT
-	if err := rr.Run("sleep", "3600"); err.Error() == "signal: interrupt" {
+	if err := rr.Run(context.Background(), "sleep", "3600"); err.Error() == "signal: interrupt" {
 		t.Logf("SIGINT forwarded to Entrypoint")
 	} else {
 		t.Fatalf("Unexpected error received: %v", err)
 	}
 }
+
+// TestRealRunnerTimeout checks that the command is killed once its context is
+// done, and that the error of the context is returned.
+func TestRealRunnerTimeout(t *testing.T) {
+	rr := realRunner{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rr.Run(ctx, "sleep", "3600"); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the command to time out, got error: %v", err)
+	}
+}
+
+// TestRealRunnerStdoutResults checks that the results the command prints
+// result markers for are set, including when its last line is incomplete.
+func TestRealRunnerStdoutResults(t *testing.T) {
+	resultsDir := t.TempDir()
+	var stdout bytes.Buffer
+	rr := realRunner{stdoutResults: &entrypoint.StdoutResultsWriter{
+		W:          &stdout,
+		ResultsDir: resultsDir,
+		Results:    []string{"foo", "bar"},
+	}}
+	if err := rr.Run(context.Background(), "sh", "-c", `echo "::set-result name=foo::hello"; printf "::set-result name=bar::world"`); err != nil {
+		t.Fatalf("Unexpected error running the command: %v", err)
+	}
+	for name, want := range map[string]string{"foo": "hello", "bar": "world"} {
+		got, err := ioutil.ReadFile(filepath.Join(resultsDir, name))
+		if err != nil {
+			t.Fatalf("Error reading result %q: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("Expected result %q to be %q but got %q", name, want, got)
+		}
+	}
+	if want := "::set-result name=foo::hello\n::set-result name=bar::world"; stdout.String() != want {
+		t.Errorf("Expected stdout %q but got %q", want, stdout.String())
+	}
+}
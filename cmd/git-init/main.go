@@ -53,31 +53,35 @@ func main() {
 		logger.Fatalf("Error fetching git repository: %s", err)
 	}
 
-	commit, err := git.ShowCommit(logger, "HEAD", fetchSpec.Path)
+	commit, err := git.ShowCommitInfo(logger, "HEAD", fetchSpec.Path)
 	if err != nil {
 		logger.Fatalf("Error parsing revision %s of git repository: %s", fetchSpec.Revision, err)
 	}
-	resourceName := os.Getenv("TEKTON_RESOURCE_NAME")
-	output := []v1beta1.PipelineResourceResult{
-		{
-			Key:   "commit",
-			Value: commit,
-			ResourceRef: v1beta1.PipelineResourceRef{
-				Name: resourceName,
-			},
-			ResourceName: resourceName,
-		},
-		{
-			Key:   "url",
-			Value: fetchSpec.URL,
+	output := resourceResults(os.Getenv("TEKTON_RESOURCE_NAME"), fetchSpec.URL, commit)
+
+	if err := termination.WriteMessage(terminationMessagePath, output); err != nil {
+		logger.Fatalf("Error writing message to %s : %s", terminationMessagePath, err)
+	}
+}
+
+// resourceResults returns the results of the git resource resourceName, describing the
+// commit checked out from url.
+func resourceResults(resourceName, url string, commit git.Commit) []v1beta1.PipelineResourceResult {
+	var output []v1beta1.PipelineResourceResult
+	for _, r := range []struct{ key, value string }{
+		{"commit", commit.SHA},
+		{"short-commit", commit.ShortSHA},
+		{"committer-date", commit.CommitterDate},
+		{"url", url},
+	} {
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:   r.key,
+			Value: r.value,
 			ResourceRef: v1beta1.PipelineResourceRef{
 				Name: resourceName,
 			},
 			ResourceName: resourceName,
-		},
-	}
-
-	if err := termination.WriteMessage(terminationMessagePath, output); err != nil {
-		logger.Fatalf("Error writing message to %s : %s", terminationMessagePath, err)
+		})
 	}
+	return output
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/git"
+	"github.com/tektoncd/pipeline/pkg/termination"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestResourceResults(t *testing.T) {
+	commit := git.Commit{
+		SHA:           "8b1e0e2bd7fa9fd4ffb6cdd2e9e9d1d3c4f5a6b7",
+		ShortSHA:      "8b1e0e2",
+		CommitterDate: "2021-03-04T10:11:12+01:00",
+	}
+	ref := v1beta1.PipelineResourceRef{Name: "source"}
+	want := []v1beta1.PipelineResourceResult{{
+		Key:          "commit",
+		Value:        "8b1e0e2bd7fa9fd4ffb6cdd2e9e9d1d3c4f5a6b7",
+		ResourceRef:  ref,
+		ResourceName: "source",
+	}, {
+		Key:          "committer-date",
+		Value:        "2021-03-04T10:11:12+01:00",
+		ResourceRef:  ref,
+		ResourceName: "source",
+	}, {
+		Key:          "short-commit",
+		Value:        "8b1e0e2",
+		ResourceRef:  ref,
+		ResourceName: "source",
+	}, {
+		Key:          "url",
+		Value:        "https://github.com/tektoncd/pipeline",
+		ResourceRef:  ref,
+		ResourceName: "source",
+	}}
+
+	// The results are written to the termination message, so make sure they
+	// are read back, sorted by key, by the controller.
+	path := filepath.Join(t.TempDir(), "termination")
+	if err := termination.WriteMessage(path, resourceResults("source", "https://github.com/tektoncd/pipeline", commit)); err != nil {
+		t.Fatalf("Error writing message: %v", err)
+	}
+	msg, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading message: %v", err)
+	}
+	got, err := termination.ParseMessage(string(msg))
+	if err != nil {
+		t.Fatalf("Error parsing message: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected resource results %s", diff.PrintWantGot(d))
+	}
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/tektoncd/pipeline/pkg/termination"
 	"knative.dev/pkg/logging"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/image"
 )
@@ -56,6 +58,9 @@ func main() {
 	for _, imageResource := range imageResources {
 		ii, err := layout.ImageIndexFromPath(imageResource.OutputImageDir)
 		if err != nil {
+			if imageResource.Verify {
+				logger.Fatalf("No index.json found to verify the image of %s: %v", imageResource.Name, err)
+			}
 			logger.Infof("No index.json found for: %s", imageResource.Name)
 			continue
 		}
@@ -63,6 +68,12 @@ func main() {
 		if err != nil {
 			logger.Fatalf("Unexpected error getting image digest for %s: %v", imageResource.Name, err)
 		}
+		if imageResource.Verify {
+			// The credentials of the step's docker config are used to query the registry.
+			if err := verifyDigest(imageResource.URL, digest, remote.WithAuthFromKeychain(authn.DefaultKeychain)); err != nil {
+				logger.Fatalf("Error verifying the image of %s: %v", imageResource.Name, err)
+			}
+		}
 		output = append(output, v1beta1.PipelineResourceResult{
 			Key:          "digest",
 			Value:        digest.String(),
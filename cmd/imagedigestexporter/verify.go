@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	// verifyAttempts is the number of times the registry is queried when it can't be reached
+	verifyAttempts = 3
+)
+
+// verifyBackoff is the time to wait before querying the registry again after a network failure
+var verifyBackoff = 2 * time.Second
+
+// verifyDigest checks that the manifest of digest was pushed to the repository of url, and that
+// the tag of url, if it has one, points to digest. This catches builds which failed to push the
+// image, but left an index.json behind.
+func verifyDigest(url string, digest v1.Hash, opts ...remote.Option) error {
+	ref, err := name.ParseReference(url)
+	if err != nil {
+		return fmt.Errorf("invalid image url %q: %w", url, err)
+	}
+	if err := verifyManifest(ref.Context().Digest(digest.String()), digest, opts...); err != nil {
+		return err
+	}
+	// Only check the tag when the url explicitly sets one, since the digest could be pushed
+	// without updating the default "latest" tag.
+	if tag, err := name.NewTag(url, name.StrictValidation); err == nil {
+		return verifyManifest(tag, digest, opts...)
+	}
+	return nil
+}
+
+// verifyManifest checks that the manifest of ref exists and has the given digest, querying
+// the registry again when it can't be reached.
+func verifyManifest(ref name.Reference, digest v1.Hash, opts ...remote.Option) error {
+	var err error
+	for attempt := 0; attempt < verifyAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(verifyBackoff)
+		}
+		var desc *remote.Descriptor
+		desc, err = remote.Get(ref, opts...)
+		if err == nil {
+			if desc.Digest != digest {
+				return fmt.Errorf("manifest of %s has digest %s, but the image digest is %s", ref, desc.Digest, digest)
+			}
+			return nil
+		}
+		if !isTransient(err) {
+			return fmt.Errorf("manifest of %s not found: %w", ref, err)
+		}
+	}
+	return fmt.Errorf("couldn't verify manifest of %s after %d attempts: %w", ref, verifyAttempts, err)
+}
+
+// isTransient returns true if err is a network failure or a server error, and the request
+// could succeed if it was made again.
+func isTransient(err error) bool {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return true
+	}
+	return terr.StatusCode >= http.StatusInternalServerError || terr.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	verifyBackoff = 0
+
+	// failures is the number of manifest requests the fake registry fails with a server error.
+	failures := 0
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := u.Host + "/images/app"
+
+	push := func(ref string) v1.Hash {
+		t.Helper()
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := name.ParseReference(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(r, img); err != nil {
+			t.Fatalf("could not push image: %v", err)
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return digest
+	}
+	pushed := push(repo + ":v1")
+	// The v2 tag points to pushedByDigest, then to pushedLast.
+	pushedByDigest := push(repo + ":v2")
+	pushedLast := push(repo + ":v2")
+	notPushed, err := v1.NewHash("sha256:" + strings.Repeat("a", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		digest   v1.Hash
+		failures int
+		wantErr  string
+	}{{
+		name:   "tag pointing to the digest",
+		url:    repo + ":v1",
+		digest: pushed,
+	}, {
+		name:   "no tag",
+		url:    repo,
+		digest: pushedByDigest,
+	}, {
+		name:    "digest not pushed",
+		url:     repo,
+		digest:  notPushed,
+		wantErr: "not found",
+	}, {
+		name:    "tag pointing to another digest",
+		url:     repo + ":v2",
+		digest:  pushedByDigest,
+		wantErr: "has digest " + pushedLast.String(),
+	}, {
+		name:     "registry recovering",
+		url:      repo + ":v1",
+		digest:   pushed,
+		failures: verifyAttempts - 1,
+	}, {
+		name:     "registry unavailable",
+		url:      repo + ":v1",
+		digest:   pushed,
+		failures: verifyAttempts,
+		wantErr:  "after 3 attempts",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			failures = tc.failures
+			err := verifyDigest(tc.url, tc.digest)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyDigest() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected verifyDigest() to fail with %q but got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
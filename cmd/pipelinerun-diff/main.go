@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// pipelinerun-diff compares the executions of two PipelineRuns of the same Pipeline:
+//
+//	pipelinerun-diff [-kubeconfig path] [-namespace ns] [-output text|json] <run1> <run2>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/rundiff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Defaults to the kubeconfig of kubectl.")
+	namespace  = flag.String("namespace", "", "Namespace of the PipelineRuns. Defaults to the namespace of the current context.")
+	output     = flag.String("output", "text", "Format of the diff, either text or json.")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <run1> <run2>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output != "text" && *output != "json" {
+		log.Fatalf("Unknown output format %q, expected text or json", *output)
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = *kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: *namespace},
+	})
+	ns, _, err := clientConfig.Namespace()
+	if err != nil {
+		log.Fatalf("Error getting the namespace: %v", err)
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		log.Fatalf("Error building kubeconfig: %v", err)
+	}
+	client, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error building pipeline clientset: %v", err)
+	}
+
+	left, err := client.TektonV1beta1().PipelineRuns(ns).Get(flag.Arg(0), metav1.GetOptions{})
+	if err != nil {
+		log.Fatalf("Error getting PipelineRun %s: %v", flag.Arg(0), err)
+	}
+	right, err := client.TektonV1beta1().PipelineRuns(ns).Get(flag.Arg(1), metav1.GetOptions{})
+	if err != nil {
+		log.Fatalf("Error getting PipelineRun %s: %v", flag.Arg(1), err)
+	}
+	d, err := rundiff.Compare(left, right)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "json" {
+		b, err := d.JSON()
+		if err != nil {
+			log.Fatalf("Error encoding the diff: %v", err)
+		}
+		fmt.Println(string(b))
+		return
+	}
+	if err := d.Write(os.Stdout); err != nil {
+		log.Fatalf("Error writing the diff: %v", err)
+	}
+}
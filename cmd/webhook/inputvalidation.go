@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// inputValidationLookup fetches Tasks and ConfigMaps straight from the API
+// server: the informers of the webhook are scoped to its own namespace.
+type inputValidationLookup struct {
+	kubeclient     kubernetes.Interface
+	pipelineclient clientset.Interface
+}
+
+var _ v1beta1.InputValidationLookup = (*inputValidationLookup)(nil)
+
+func (l *inputValidationLookup) GetTaskSpec(ctx context.Context, namespace string, ref *v1beta1.TaskRef) (*v1beta1.TaskSpec, error) {
+	var (
+		spec v1beta1.TaskSpec
+		err  error
+	)
+	if ref.Kind == v1beta1.ClusterTaskKind {
+		var ct *v1beta1.ClusterTask
+		if ct, err = l.pipelineclient.TektonV1beta1().ClusterTasks().Get(ref.Name, metav1.GetOptions{}); err == nil {
+			spec = ct.Spec
+		}
+	} else {
+		var t *v1beta1.Task
+		if t, err = l.pipelineclient.TektonV1beta1().Tasks(namespace).Get(ref.Name, metav1.GetOptions{}); err == nil {
+			spec = t.Spec
+		}
+	}
+	if errors.IsNotFound(err) {
+		// The Task may be created after the TaskRun, which then waits for it.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (l *inputValidationLookup) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	return l.kubeclient.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+}
@@ -24,9 +24,12 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
-	"github.com/tektoncd/pipeline/pkg/contexts"
+	"github.com/tektoncd/pipeline/pkg/apis/validator"
+	"github.com/tektoncd/pipeline/pkg/auditlog"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
 	"github.com/tektoncd/pipeline/pkg/system"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
@@ -37,28 +40,16 @@ import (
 	"knative.dev/pkg/webhook"
 	"knative.dev/pkg/webhook/certificates"
 	"knative.dev/pkg/webhook/configmaps"
-	"knative.dev/pkg/webhook/resourcesemantics"
 	"knative.dev/pkg/webhook/resourcesemantics/conversion"
 	"knative.dev/pkg/webhook/resourcesemantics/defaulting"
 	"knative.dev/pkg/webhook/resourcesemantics/validation"
 )
 
-var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
-	// v1alpha1
-	v1alpha1.SchemeGroupVersion.WithKind("Pipeline"):         &v1alpha1.Pipeline{},
-	v1alpha1.SchemeGroupVersion.WithKind("Task"):             &v1alpha1.Task{},
-	v1alpha1.SchemeGroupVersion.WithKind("ClusterTask"):      &v1alpha1.ClusterTask{},
-	v1alpha1.SchemeGroupVersion.WithKind("TaskRun"):          &v1alpha1.TaskRun{},
-	v1alpha1.SchemeGroupVersion.WithKind("PipelineRun"):      &v1alpha1.PipelineRun{},
-	v1alpha1.SchemeGroupVersion.WithKind("Condition"):        &v1alpha1.Condition{},
-	v1alpha1.SchemeGroupVersion.WithKind("PipelineResource"): &v1alpha1.PipelineResource{},
-	v1alpha1.SchemeGroupVersion.WithKind("Run"):              &v1alpha1.Run{},
-	// v1beta1
-	v1beta1.SchemeGroupVersion.WithKind("Pipeline"):    &v1beta1.Pipeline{},
-	v1beta1.SchemeGroupVersion.WithKind("Task"):        &v1beta1.Task{},
-	v1beta1.SchemeGroupVersion.WithKind("ClusterTask"): &v1beta1.ClusterTask{},
-	v1beta1.SchemeGroupVersion.WithKind("TaskRun"):     &v1beta1.TaskRun{},
-	v1beta1.SchemeGroupVersion.WithKind("PipelineRun"): &v1beta1.PipelineRun{},
+// validatorConfig returns the current configuration of store the resources
+// are defaulted and validated with.
+func validatorConfig(store *defaultconfig.Store) validator.Config {
+	cfg := store.Load()
+	return validator.Config{FeatureFlags: cfg.FeatureFlags, Defaults: cfg.Defaults}
 }
 
 func newDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
@@ -75,11 +66,11 @@ func newDefaultingAdmissionController(ctx context.Context, cmw configmap.Watcher
 		"/defaulting",
 
 		// The resources to validate and default.
-		types,
+		validator.Types,
 
 		// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
 		func(ctx context.Context) context.Context {
-			return contexts.WithUpgradeViaDefaulting(store.ToContext(ctx))
+			return validator.Context(ctx, validatorConfig(store))
 		},
 
 		// Whether to disallow unknown fields.
@@ -91,6 +82,11 @@ func newValidationAdmissionController(ctx context.Context, cmw configmap.Watcher
 	// Decorate contexts with the current state of the config.
 	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"))
 	store.WatchConfigs(cmw)
+	lookup := &inputValidationLookup{
+		kubeclient:     kubeclient.Get(ctx),
+		pipelineclient: pipelineclient.Get(ctx),
+	}
+	vulnerabilities := newVulnerabilityLookup(ctx, lookup)
 	return validation.NewAdmissionController(ctx,
 
 		// Name of the resource webhook.
@@ -100,11 +96,12 @@ func newValidationAdmissionController(ctx context.Context, cmw configmap.Watcher
 		"/resource-validation",
 
 		// The resources to validate and default.
-		types,
+		validator.Types,
 
 		// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
 		func(ctx context.Context) context.Context {
-			return contexts.WithUpgradeViaDefaulting(store.ToContext(ctx))
+			ctx = v1beta1.WithInputValidationLookup(validator.Context(ctx, validatorConfig(store)), lookup)
+			return v1beta1.WithVulnerabilityLookup(ctx, vulnerabilities)
 		},
 
 		// Whether to disallow unknown fields.
@@ -123,13 +120,25 @@ func newConfigValidationController(ctx context.Context, cmw configmap.Watcher) *
 
 		// The configmaps to validate.
 		configmap.Constructors{
-			logging.ConfigMapName():               logging.NewConfigFromConfigMap,
-			defaultconfig.GetDefaultsConfigName(): defaultconfig.NewDefaultsFromConfigMap,
-			pkgleaderelection.ConfigMapName():     pkgleaderelection.NewConfigFromConfigMap,
+			logging.ConfigMapName():                   logging.NewConfigFromConfigMap,
+			defaultconfig.GetDefaultsConfigName():     defaultconfig.NewDefaultsFromConfigMap,
+			defaultconfig.GetFeatureFlagsConfigName(): defaultconfig.NewFeatureFlagsFromConfigMap,
+			pkgleaderelection.ConfigMapName():         pkgleaderelection.NewConfigFromConfigMap,
 		},
 	)
 }
 
+func newAuditLogValidationController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+	return auditlog.NewAdmissionController(ctx,
+
+		// Name of the audit log webhook.
+		"auditlog.webhook.pipeline.tekton.dev",
+
+		// The path on which to serve the webhook.
+		"/auditlog-validation",
+	)
+}
+
 func newConversionController(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 	// nolint: golint
 	var (
@@ -219,6 +228,7 @@ func main() {
 		newDefaultingAdmissionController,
 		newValidationAdmissionController,
 		newConfigValidationController,
+		newAuditLogValidationController,
 		newConversionController,
 	)
 }
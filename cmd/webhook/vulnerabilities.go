@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/vulnscan"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// vulnerabilitySyncTimeout is how long a lookup waits for the VulnerabilitySummaries
+// to be synced the first time they are looked up.
+const vulnerabilitySyncTimeout = 5 * time.Second
+
+// vulnerabilityLookup looks up the critical vulnerabilities of images in the
+// VulnerabilitySummaries of the namespace of the TaskRun. The informers of the
+// webhook are scoped to its own namespace, so the summaries are watched with an
+// informer of their own, started by the first lookup: they are only looked up
+// when the "block-on-critical-vulns" feature flag is set.
+type vulnerabilityLookup struct {
+	*inputValidationLookup
+	stopCh <-chan struct{}
+
+	once   sync.Once
+	lister listers.VulnerabilitySummaryLister
+	synced cache.InformerSynced
+}
+
+var _ v1beta1.VulnerabilityLookup = (*vulnerabilityLookup)(nil)
+
+func newVulnerabilityLookup(ctx context.Context, lookup *inputValidationLookup) *vulnerabilityLookup {
+	return &vulnerabilityLookup{
+		inputValidationLookup: lookup,
+		stopCh:                ctx.Done(),
+	}
+}
+
+func (l *vulnerabilityLookup) CriticalVulnerabilities(ctx context.Context, namespace, image string) ([]string, error) {
+	l.once.Do(func() {
+		factory := externalversions.NewSharedInformerFactory(l.pipelineclient, 0)
+		informer := factory.Tekton().V1alpha1().VulnerabilitySummaries()
+		l.lister = informer.Lister()
+		l.synced = informer.Informer().HasSynced
+		factory.Start(l.stopCh)
+	})
+	ctx, cancel := context.WithTimeout(ctx, vulnerabilitySyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), l.synced) {
+		return nil, errors.New("timed out waiting for the VulnerabilitySummaries to be synced")
+	}
+
+	summaries, err := l.lister.VulnerabilitySummaries(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	items := make([]v1alpha1.VulnerabilitySummary, 0, len(summaries))
+	for _, s := range summaries {
+		items = append(items, *s)
+	}
+	return vulnscan.CriticalVulnerabilities(items, image), nil
+}
@@ -755,6 +755,13 @@ func TaskRunServiceAccountName(sa string) TaskRunSpecOp {
 	}
 }
 
+// TaskRunPodTemplate add a custom PodTemplate to the TaskRun
+func TaskRunPodTemplate(podTemplate *v1alpha1.PodTemplate) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.PodTemplate = podTemplate
+	}
+}
+
 // TaskRunParam sets the Params to the TaskSpec
 func TaskRunParam(name, value string, additionalValues ...string) TaskRunSpecOp {
 	arrayOrString := ArrayOrString(value, additionalValues...)
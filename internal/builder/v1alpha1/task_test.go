@@ -424,3 +424,38 @@ func TestTaskRunWithPodTemplate(t *testing.T) {
 		t.Fatalf("TaskRun diff -want, +got: %v", d)
 	}
 }
+
+func TestTaskRunSpecTimeoutServiceAccountAndPodTemplate(t *testing.T) {
+	podTemplate := &v1alpha1.PodTemplate{SchedulerName: "custom-scheduler"}
+	for _, tc := range []struct {
+		name string
+		ops  []tb.TaskRunSpecOp
+		want v1alpha1.TaskRunSpec
+	}{{
+		name: "timeout",
+		ops:  []tb.TaskRunSpecOp{tb.TaskRunTimeout(5 * time.Minute)},
+		want: v1alpha1.TaskRunSpec{Timeout: &metav1.Duration{Duration: 5 * time.Minute}},
+	}, {
+		name: "nil timeout",
+		ops:  []tb.TaskRunSpecOp{tb.TaskRunTimeout(5 * time.Minute), tb.TaskRunNilTimeout},
+		want: v1alpha1.TaskRunSpec{},
+	}, {
+		name: "service account name",
+		ops:  []tb.TaskRunSpecOp{tb.TaskRunServiceAccountName("sa")},
+		want: v1alpha1.TaskRunSpec{ServiceAccountName: "sa"},
+	}, {
+		name: "pod template",
+		ops:  []tb.TaskRunSpecOp{tb.TaskRunPodTemplate(podTemplate)},
+		want: v1alpha1.TaskRunSpec{PodTemplate: podTemplate},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := v1alpha1.TaskRunSpec{}
+			for _, op := range tc.ops {
+				op(&spec)
+			}
+			if d := cmp.Diff(tc.want, spec); d != "" {
+				t.Errorf("TaskRunSpec diff -want, +got: %v", d)
+			}
+		})
+	}
+}
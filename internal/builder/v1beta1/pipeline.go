@@ -24,6 +24,7 @@ import (
 	resource "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 )
 
@@ -110,6 +111,11 @@ func PipelineRunCancelled(spec *v1beta1.PipelineRunSpec) {
 	spec.Status = v1beta1.PipelineRunSpecStatusCancelled
 }
 
+// PipelineRunPaused sets the status to pause to the PipelineRunSpec.
+func PipelineRunPaused(spec *v1beta1.PipelineRunSpec) {
+	spec.Status = v1beta1.PipelineRunSpecStatusPause
+}
+
 // PipelineDeclaredResource adds a resource declaration to the Pipeline Spec,
 // with the specified name and type.
 func PipelineDeclaredResource(name string, t v1beta1.PipelineResourceType) PipelineSpecOp {
@@ -222,6 +228,20 @@ func Retries(retries int) PipelineTaskOp {
 	}
 }
 
+// ContinueOnFailure allows the PipelineRun to carry on when the TaskRun of the PipelineTask fails.
+func ContinueOnFailure() PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.ContinueOnFailure = true
+	}
+}
+
+// FailurePolicy sets the failure policy of the final task.
+func FailurePolicy(policy v1beta1.PipelineTaskFailurePolicy) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.FailurePolicy = policy
+	}
+}
+
 // RunAfter will update the provided Pipeline Task to indicate that it
 // should be run after the provided list of Pipeline Task names.
 func RunAfter(tasks ...string) PipelineTaskOp {
@@ -237,6 +257,13 @@ func PipelineTaskRefKind(kind v1beta1.TaskKind) PipelineTaskOp {
 	}
 }
 
+// PipelineTaskRefBundle sets the Bundle to the PipelineTaskRef.
+func PipelineTaskRefBundle(bundle string) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.TaskRef.Bundle = bundle
+	}
+}
+
 // PipelineTaskParam adds a ResourceParam, with specified name and value, to the PipelineTask.
 func PipelineTaskParam(name string, value string, additionalValues ...string) PipelineTaskOp {
 	arrayOrString := ArrayOrString(value, additionalValues...)
@@ -305,6 +332,18 @@ func PipelineTaskCondition(conditionRef string, ops ...PipelineTaskConditionOp)
 	}
 }
 
+// PipelineTaskWhenExpression adds a WhenExpression with the specified input, operator and values
+// to the PipelineTask.
+func PipelineTaskWhenExpression(input string, operator selection.Operator, values ...string) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.WhenExpressions = append(pt.WhenExpressions, v1beta1.WhenExpression{
+			Input:    input,
+			Operator: operator,
+			Values:   values,
+		})
+	}
+}
+
 // PipelineTaskConditionParam adds a parameter to a PipelineTaskCondition
 func PipelineTaskConditionParam(name, val string) PipelineTaskConditionOp {
 	return func(condition *v1beta1.PipelineTaskCondition) {
@@ -343,6 +382,18 @@ func PipelineTaskWorkspaceBinding(name, workspace, subPath string) PipelineTaskO
 	}
 }
 
+// PipelineTaskWorkspaceBindingCondition adds a workspace with the specified name, workspace
+// and condition, which is bound only when the condition is true.
+func PipelineTaskWorkspaceBindingCondition(name, workspace, condition string) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.Workspaces = append(pt.Workspaces, v1beta1.WorkspacePipelineTaskBinding{
+			Name:      name,
+			Workspace: workspace,
+			Condition: condition,
+		})
+	}
+}
+
 // PipelineTaskTimeout sets the timeout for the PipelineTask.
 func PipelineTaskTimeout(duration time.Duration) PipelineTaskOp {
 	return func(pt *v1beta1.PipelineTask) {
@@ -497,6 +548,20 @@ func PipelineRunNilTimeout(prs *v1beta1.PipelineRunSpec) {
 	prs.Timeout = nil
 }
 
+// PipelineRunConcurrency sets the concurrency key and policy to the PipelineRunSpec.
+func PipelineRunConcurrency(key string, policy v1beta1.ConcurrencyPolicy) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.Concurrency = &v1beta1.PipelineRunConcurrency{Key: key, Policy: policy}
+	}
+}
+
+// PipelineRunFailurePolicy sets the failure policy to the PipelineRunSpec.
+func PipelineRunFailurePolicy(policy v1beta1.PipelineRunFailurePolicy) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.FailurePolicy = policy
+	}
+}
+
 // PipelineRunNodeSelector sets the Node selector to the PipelineRunSpec.
 func PipelineRunNodeSelector(values map[string]string) PipelineRunSpecOp {
 	return func(prs *v1beta1.PipelineRunSpec) {
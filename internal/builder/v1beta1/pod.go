@@ -190,3 +190,17 @@ func PodStatusConditions(cond corev1.PodCondition) PodStatusOp {
 		status.Conditions = append(status.Conditions, cond)
 	}
 }
+
+// PodStatusContainerStatuses adds ContainerStatuses to the Pod status.
+func PodStatusContainerStatuses(statuses ...corev1.ContainerStatus) PodStatusOp {
+	return func(status *corev1.PodStatus) {
+		status.ContainerStatuses = append(status.ContainerStatuses, statuses...)
+	}
+}
+
+// PodStatusInitContainerStatuses adds InitContainerStatuses to the Pod status.
+func PodStatusInitContainerStatuses(statuses ...corev1.ContainerStatus) PodStatusOp {
+	return func(status *corev1.PodStatus) {
+		status.InitContainerStatuses = append(status.InitContainerStatuses, statuses...)
+	}
+}
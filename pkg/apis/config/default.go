@@ -19,25 +19,33 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 )
 
 const (
-	DefaultTimeoutMinutes          = 60
-	NoTimeoutDuration              = 0 * time.Minute
-	defaultTimeoutMinutesKey       = "default-timeout-minutes"
-	defaultServiceAccountKey       = "default-service-account"
-	defaultManagedByLabelValueKey  = "default-managed-by-label-value"
-	DefaultManagedByLabelValue     = "tekton-pipelines"
-	defaultPodTemplateKey          = "default-pod-template"
-	defaultCloudEventsSinkKey      = "default-cloud-events-sink"
-	DefaultCloudEventSinkValue     = ""
-	defaultTaskRunWorkspaceBinding = "default-task-run-workspace-binding"
+	DefaultTimeoutMinutes                = 60
+	NoTimeoutDuration                    = 0 * time.Minute
+	defaultTimeoutMinutesKey             = "default-timeout-minutes"
+	defaultServiceAccountKey             = "default-service-account"
+	defaultManagedByLabelValueKey        = "default-managed-by-label-value"
+	DefaultManagedByLabelValue           = "tekton-pipelines"
+	defaultPodTemplateKey                = "default-pod-template"
+	defaultCloudEventsSinkKey            = "default-cloud-events-sink"
+	DefaultCloudEventSinkValue           = ""
+	defaultTaskRunWorkspaceBinding       = "default-task-run-workspace-binding"
+	defaultPropagatedMetadataPrefixesKey = "default-propagated-metadata-prefixes"
+	defaultTTLSecondsAfterFinishedKey    = "default-ttl-seconds-after-finished"
+	defaultInitContainerResourcesKey     = "default-init-container-resources"
+	defaultNopContainerResourcesKey      = "default-nop-container-resources"
+	defaultLogURLTemplateKey             = "default-log-url-template"
 )
 
 // Defaults holds the default configurations
@@ -49,6 +57,24 @@ type Defaults struct {
 	DefaultPodTemplate             *pod.Template
 	DefaultCloudEventsSink         string
 	DefaultTaskRunWorkspaceBinding string
+	// DefaultPropagatedMetadataPrefixes restricts the labels and annotations propagated
+	// from runs to the resources they create to the keys starting with one of its
+	// prefixes. All labels and annotations are propagated when it is empty.
+	DefaultPropagatedMetadataPrefixes []string
+	// DefaultTTLSecondsAfterFinished is the number of seconds after which finished
+	// runs are deleted when they don't set their own TTL. Finished runs are kept
+	// when it is nil.
+	DefaultTTLSecondsAfterFinished *int32
+	// DefaultInitContainerResources are the resource requirements of the init
+	// containers Tekton adds to the Pods of TaskRuns. They have none when it is nil.
+	DefaultInitContainerResources *corev1.ResourceRequirements
+	// DefaultNopContainerResources are the resource requirements of the containers
+	// Tekton runs the nop image in. Their built-in ones are kept when it is nil.
+	DefaultNopContainerResources *corev1.ResourceRequirements
+	// DefaultLogURLTemplate is the Go template the URL of the logs of each step of
+	// TaskRuns is rendered with, over their namespace, podName, containerName and
+	// taskRunName. The URL of the logs isn't recorded when it is empty.
+	DefaultLogURLTemplate string
 }
 
 // GetDefaultsConfigName returns the name of the configmap containing all
@@ -75,7 +101,12 @@ func (cfg *Defaults) Equals(other *Defaults) bool {
 		other.DefaultManagedByLabelValue == cfg.DefaultManagedByLabelValue &&
 		other.DefaultPodTemplate.Equals(cfg.DefaultPodTemplate) &&
 		other.DefaultCloudEventsSink == cfg.DefaultCloudEventsSink &&
-		other.DefaultTaskRunWorkspaceBinding == cfg.DefaultTaskRunWorkspaceBinding
+		other.DefaultTaskRunWorkspaceBinding == cfg.DefaultTaskRunWorkspaceBinding &&
+		reflect.DeepEqual(other.DefaultPropagatedMetadataPrefixes, cfg.DefaultPropagatedMetadataPrefixes) &&
+		reflect.DeepEqual(other.DefaultTTLSecondsAfterFinished, cfg.DefaultTTLSecondsAfterFinished) &&
+		equality.Semantic.DeepEqual(other.DefaultInitContainerResources, cfg.DefaultInitContainerResources) &&
+		equality.Semantic.DeepEqual(other.DefaultNopContainerResources, cfg.DefaultNopContainerResources) &&
+		other.DefaultLogURLTemplate == cfg.DefaultLogURLTemplate
 }
 
 // NewDefaultsFromMap returns a Config given a map corresponding to a ConfigMap
@@ -117,9 +148,56 @@ func NewDefaultsFromMap(cfgMap map[string]string) (*Defaults, error) {
 	if bindingYAML, ok := cfgMap[defaultTaskRunWorkspaceBinding]; ok {
 		tc.DefaultTaskRunWorkspaceBinding = bindingYAML
 	}
+
+	if prefixes, ok := cfgMap[defaultPropagatedMetadataPrefixesKey]; ok {
+		for _, prefix := range strings.Split(prefixes, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				tc.DefaultPropagatedMetadataPrefixes = append(tc.DefaultPropagatedMetadataPrefixes, prefix)
+			}
+		}
+	}
+
+	if defaultTTL, ok := cfgMap[defaultTTLSecondsAfterFinishedKey]; ok {
+		ttl, err := strconv.ParseInt(defaultTTL, 10, 32)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: %q is not a non-negative number of seconds", defaultTTLSecondsAfterFinishedKey, defaultTTL)
+		}
+		ttlSeconds := int32(ttl)
+		tc.DefaultTTLSecondsAfterFinished = &ttlSeconds
+	}
+
+	if resources, ok := cfgMap[defaultInitContainerResourcesKey]; ok {
+		requirements, err := parseResourceRequirements(defaultInitContainerResourcesKey, resources)
+		if err != nil {
+			return nil, err
+		}
+		tc.DefaultInitContainerResources = requirements
+	}
+
+	if resources, ok := cfgMap[defaultNopContainerResourcesKey]; ok {
+		requirements, err := parseResourceRequirements(defaultNopContainerResourcesKey, resources)
+		if err != nil {
+			return nil, err
+		}
+		tc.DefaultNopContainerResources = requirements
+	}
+
+	if logURLTemplate, ok := cfgMap[defaultLogURLTemplateKey]; ok {
+		tc.DefaultLogURLTemplate = logURLTemplate
+	}
 	return &tc, nil
 }
 
+// parseResourceRequirements parses the YAML value of the key into resource requirements,
+// failing on malformed quantities.
+func parseResourceRequirements(key, value string) (*corev1.ResourceRequirements, error) {
+	var requirements corev1.ResourceRequirements
+	if err := yaml.Unmarshal([]byte(value), &requirements); err != nil {
+		return nil, fmt.Errorf("failed parsing defaults config %q: %w", key, err)
+	}
+	return &requirements, nil
+}
+
 // NewDefaultsFromConfigMap returns a Config for the given configmap
 func NewDefaultsFromConfigMap(config *corev1.ConfigMap) (*Defaults, error) {
 	return NewDefaultsFromMap(config.Data)
@@ -24,6 +24,8 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestNewDefaultsFromConfigMap(t *testing.T) {
@@ -36,9 +38,12 @@ func TestNewDefaultsFromConfigMap(t *testing.T) {
 	testCases := []testCase{
 		{
 			expectedConfig: &config.Defaults{
-				DefaultTimeoutMinutes:      50,
-				DefaultServiceAccount:      "tekton",
-				DefaultManagedByLabelValue: "something-else",
+				DefaultTimeoutMinutes:             50,
+				DefaultServiceAccount:             "tekton",
+				DefaultManagedByLabelValue:        "something-else",
+				DefaultPropagatedMetadataPrefixes: []string{"team.example.com/", "app.kubernetes.io/"},
+				DefaultTTLSecondsAfterFinished:    int32Ptr(3600),
+				DefaultLogURLTemplate:             "https://logs.example.com/{{.namespace}}/{{.podName}}/{{.containerName}}",
 			},
 			fileName: config.GetDefaultsConfigName(),
 		},
@@ -55,6 +60,35 @@ func TestNewDefaultsFromConfigMap(t *testing.T) {
 			},
 			fileName: "config-defaults-with-pod-template",
 		},
+		{
+			expectedConfig: &config.Defaults{
+				DefaultTimeoutMinutes:      config.DefaultTimeoutMinutes,
+				DefaultManagedByLabelValue: config.DefaultManagedByLabelValue,
+				DefaultInitContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("16Mi"),
+					},
+				},
+				DefaultNopContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("5m"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("32Mi"),
+					},
+				},
+			},
+			fileName: "config-defaults-with-resources",
+		},
+		{
+			expectedError: true,
+			fileName:      "config-defaults-ttl-err",
+		},
+		{
+			expectedError: true,
+			fileName:      "config-defaults-resources-err",
+		},
 		// the github.com/ghodss/yaml package in the vendor directory does not support UnmarshalStrict
 		// update it, switch to UnmarshalStrict in defaults.go, then uncomment these tests
 		// {
@@ -192,6 +226,74 @@ func TestEquals(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "different default propagated metadata prefixes",
+			left: &config.Defaults{
+				DefaultPropagatedMetadataPrefixes: []string{"example.com/"},
+			},
+			right: &config.Defaults{
+				DefaultPropagatedMetadataPrefixes: []string{"team.example.com/"},
+			},
+			expected: false,
+		},
+		{
+			name: "same default propagated metadata prefixes",
+			left: &config.Defaults{
+				DefaultPropagatedMetadataPrefixes: []string{"example.com/"},
+			},
+			right: &config.Defaults{
+				DefaultPropagatedMetadataPrefixes: []string{"example.com/"},
+			},
+			expected: true,
+		},
+		{
+			name: "different default ttl seconds after finished",
+			left: &config.Defaults{
+				DefaultTTLSecondsAfterFinished: int32Ptr(60),
+			},
+			right: &config.Defaults{
+				DefaultTTLSecondsAfterFinished: int32Ptr(120),
+			},
+			expected: false,
+		},
+		{
+			name: "same default ttl seconds after finished",
+			left: &config.Defaults{
+				DefaultTTLSecondsAfterFinished: int32Ptr(60),
+			},
+			right: &config.Defaults{
+				DefaultTTLSecondsAfterFinished: int32Ptr(60),
+			},
+			expected: true,
+		},
+		{
+			name: "different default init container resources",
+			left: &config.Defaults{
+				DefaultInitContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Mi")},
+				},
+			},
+			right: &config.Defaults{
+				DefaultInitContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "same default init container resources",
+			left: &config.Defaults{
+				DefaultInitContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Mi")},
+				},
+			},
+			right: &config.Defaults{
+				DefaultInitContainerResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Mi")},
+				},
+			},
+			expected: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -221,3 +323,7 @@ func verifyConfigFileWithExpectedError(t *testing.T, fileName string) {
 		t.Errorf("NewDefaultsFromConfigMap(actual) was expected to return an error")
 	}
 }
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
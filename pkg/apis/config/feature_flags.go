@@ -19,29 +19,108 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
 const (
-	disableHomeEnvOverwriteKey              = "disable-home-env-overwrite"
-	disableWorkingDirOverwriteKey           = "disable-working-directory-overwrite"
-	disableAffinityAssistantKey             = "disable-affinity-assistant"
-	runningInEnvWithInjectedSidecarsKey     = "running-in-environment-with-injected-sidecars"
-	DefaultDisableHomeEnvOverwrite          = false
-	DefaultDisableWorkingDirOverwrite       = false
-	DefaultDisableAffinityAssistant         = false
-	DefaultRunningInEnvWithInjectedSidecars = true
+	disableHomeEnvOverwriteKey                = "disable-home-env-overwrite"
+	disableWorkingDirOverwriteKey             = "disable-working-directory-overwrite"
+	disableAffinityAssistantKey               = "disable-affinity-assistant"
+	disableAffinityWithCustomSchedulerKey     = "disable-affinity-with-custom-scheduler"
+	disableCredsInitKey                       = "disable-creds-init"
+	runningInEnvWithInjectedSidecarsKey       = "running-in-environment-with-injected-sidecars"
+	enableAPIFieldsKey                        = "enable-api-fields"
+	enableImageDigestPinningKey               = "enable-image-digest-pinning"
+	enableImagePreWarmKey                     = "enable-image-pre-warm"
+	enableRetryPodPruningKey                  = "enable-retry-pod-pruning"
+	enableFailedAttemptResultsKey             = "enable-failed-attempt-results"
+	enableStepMetricsKey                      = "enable-step-metrics"
+	enableStdoutResultsKey                    = "enable-stdout-results"
+	enableUnusedParamWarningsKey              = "enable-unused-param-warnings"
+	enableVulnerabilityScanningKey            = "enable-vulnerability-scanning"
+	blockOnCriticalVulnsKey                   = "block-on-critical-vulns"
+	evictedPodPolicyKey                       = "evicted-pod-policy"
+	resultsFromKey                            = "results-from"
+	missingReferencePolicyKey                 = "missing-reference-policy"
+	missingReferenceTimeoutKey                = "missing-reference-timeout"
+	seccompLocalhostProfileDirKey             = "seccomp-localhost-profile-dir"
+	DefaultDisableHomeEnvOverwrite            = false
+	DefaultDisableWorkingDirOverwrite         = false
+	DefaultDisableAffinityAssistant           = false
+	DefaultDisableAffinityWithCustomScheduler = false
+	DefaultDisableCredsInit                   = false
+	DefaultRunningInEnvWithInjectedSidecars   = true
+	DefaultEnableAPIFields                    = StableAPIFields
+	DefaultEnableImageDigestPinning           = false
+	DefaultEnableImagePreWarm                 = false
+	DefaultEnableRetryPodPruning              = false
+	DefaultEnableFailedAttemptResults         = false
+	DefaultEnableStepMetrics                  = false
+	DefaultEnableStdoutResults                = false
+	DefaultEnableUnusedParamWarnings          = false
+	DefaultEnableVulnerabilityScanning        = false
+	DefaultBlockOnCriticalVulns               = false
+	DefaultEvictedPodPolicy                   = FailEvictedPodPolicy
+	DefaultResultsFrom                        = TerminationMessageResultsFrom
+	DefaultMissingReferencePolicy             = FailMissingReferencePolicy
+	DefaultMissingReferenceTimeout            = 5 * time.Minute
+	DefaultSeccompLocalhostProfileDir         = ""
+
+	// StableAPIFields is the value of "enable-api-fields" allowing only stable fields
+	StableAPIFields = "stable"
+	// AlphaAPIFields is the value of "enable-api-fields" allowing alpha fields as well
+	AlphaAPIFields = "alpha"
+
+	// FailEvictedPodPolicy is the value of "evicted-pod-policy" failing TaskRuns whose Pod was evicted
+	FailEvictedPodPolicy = "fail"
+	// RetryEvictedPodPolicy is the value of "evicted-pod-policy" running the TaskRuns whose Pod was
+	// evicted again in a new Pod
+	RetryEvictedPodPolicy = "retry"
+
+	// TerminationMessageResultsFrom is the value of "results-from" reading the results of Steps
+	// from the termination message of their container
+	TerminationMessageResultsFrom = "termination-message"
+	// ContainerLogsResultsFrom is the value of "results-from" reading the results of Steps from
+	// the logs of their container
+	ContainerLogsResultsFrom = "container-logs"
+	// FailMissingReferencePolicy is the value of "missing-reference-policy" failing PipelineRuns
+	// referencing Tasks or Conditions which don't exist as soon as they are reconciled
+	FailMissingReferencePolicy = "fail"
+	// WaitMissingReferencePolicy is the value of "missing-reference-policy" making PipelineRuns
+	// referencing Tasks or Conditions which don't exist wait for them to be created, up to
+	// "missing-reference-timeout" after they started
+	WaitMissingReferencePolicy = "wait"
 )
 
 // FeatureFlags holds the features configurations
 // +k8s:deepcopy-gen=true
 type FeatureFlags struct {
-	DisableHomeEnvOverwrite          bool
-	DisableWorkingDirOverwrite       bool
-	DisableAffinityAssistant         bool
-	RunningInEnvWithInjectedSidecars bool
+	DisableHomeEnvOverwrite            bool
+	DisableWorkingDirOverwrite         bool
+	DisableAffinityAssistant           bool
+	DisableAffinityWithCustomScheduler bool
+	DisableCredsInit                   bool
+	RunningInEnvWithInjectedSidecars   bool
+	EnableAPIFields                    string
+	EnableImageDigestPinning           bool
+	EnableImagePreWarm                 bool
+	EnableRetryPodPruning              bool
+	EnableFailedAttemptResults         bool
+	EnableStepMetrics                  bool
+	EnableStdoutResults                bool
+	EnableUnusedParamWarnings          bool
+	EnableVulnerabilityScanning        bool
+	BlockOnCriticalVulns               bool
+	EvictedPodPolicy                   string
+	ResultsFrom                        string
+	MissingReferencePolicy             string
+	MissingReferenceTimeout            time.Duration
+	SeccompLocalhostProfileDir         string
 }
 
 // GetFeatureFlagsConfigName returns the name of the configmap containing all
@@ -78,12 +157,156 @@ func NewFeatureFlagsFromMap(cfgMap map[string]string) (*FeatureFlags, error) {
 	if err := setFeature(disableAffinityAssistantKey, DefaultDisableAffinityAssistant, &tc.DisableAffinityAssistant); err != nil {
 		return nil, err
 	}
+	if err := setFeature(disableAffinityWithCustomSchedulerKey, DefaultDisableAffinityWithCustomScheduler, &tc.DisableAffinityWithCustomScheduler); err != nil {
+		return nil, err
+	}
+	if err := setFeature(disableCredsInitKey, DefaultDisableCredsInit, &tc.DisableCredsInit); err != nil {
+		return nil, err
+	}
 	if err := setFeature(runningInEnvWithInjectedSidecarsKey, DefaultRunningInEnvWithInjectedSidecars, &tc.RunningInEnvWithInjectedSidecars); err != nil {
 		return nil, err
 	}
+	if err := setEnabledAPIFields(cfgMap, &tc.EnableAPIFields); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableImageDigestPinningKey, DefaultEnableImageDigestPinning, &tc.EnableImageDigestPinning); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableImagePreWarmKey, DefaultEnableImagePreWarm, &tc.EnableImagePreWarm); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableRetryPodPruningKey, DefaultEnableRetryPodPruning, &tc.EnableRetryPodPruning); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableFailedAttemptResultsKey, DefaultEnableFailedAttemptResults, &tc.EnableFailedAttemptResults); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableStepMetricsKey, DefaultEnableStepMetrics, &tc.EnableStepMetrics); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableStdoutResultsKey, DefaultEnableStdoutResults, &tc.EnableStdoutResults); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableUnusedParamWarningsKey, DefaultEnableUnusedParamWarnings, &tc.EnableUnusedParamWarnings); err != nil {
+		return nil, err
+	}
+	if err := setFeature(enableVulnerabilityScanningKey, DefaultEnableVulnerabilityScanning, &tc.EnableVulnerabilityScanning); err != nil {
+		return nil, err
+	}
+	if err := setFeature(blockOnCriticalVulnsKey, DefaultBlockOnCriticalVulns, &tc.BlockOnCriticalVulns); err != nil {
+		return nil, err
+	}
+	if err := setEvictedPodPolicy(cfgMap, &tc.EvictedPodPolicy); err != nil {
+		return nil, err
+	}
+	if err := setResultsFrom(cfgMap, &tc.ResultsFrom); err != nil {
+		return nil, err
+	}
+	if err := setMissingReferencePolicy(cfgMap, &tc.MissingReferencePolicy); err != nil {
+		return nil, err
+	}
+	if err := setMissingReferenceTimeout(cfgMap, &tc.MissingReferenceTimeout); err != nil {
+		return nil, err
+	}
+	if err := setSeccompLocalhostProfileDir(cfgMap, &tc.SeccompLocalhostProfileDir); err != nil {
+		return nil, err
+	}
 	return &tc, nil
 }
 
+// setEnabledAPIFields sets the "enable-api-fields" flag based on the content of a given map.
+// If the flag is set to an invalid value, an error is returned.
+func setEnabledAPIFields(cfgMap map[string]string, feature *string) error {
+	value := DefaultEnableAPIFields
+	if cfg, ok := cfgMap[enableAPIFieldsKey]; ok {
+		value = strings.ToLower(cfg)
+	}
+	switch value {
+	case StableAPIFields, AlphaAPIFields:
+		*feature = value
+		return nil
+	default:
+		return fmt.Errorf("invalid value for feature flag %q: %q", enableAPIFieldsKey, value)
+	}
+}
+
+// setEvictedPodPolicy sets the "evicted-pod-policy" flag based on the content of a given map.
+// If the flag is set to an invalid value, an error is returned.
+func setEvictedPodPolicy(cfgMap map[string]string, feature *string) error {
+	value := DefaultEvictedPodPolicy
+	if cfg, ok := cfgMap[evictedPodPolicyKey]; ok {
+		value = strings.ToLower(cfg)
+	}
+	switch value {
+	case FailEvictedPodPolicy, RetryEvictedPodPolicy:
+		*feature = value
+		return nil
+	default:
+		return fmt.Errorf("invalid value for feature flag %q: %q", evictedPodPolicyKey, value)
+	}
+}
+
+// setResultsFrom sets the "results-from" flag based on the content of a given map.
+// If the flag is set to an invalid value, an error is returned.
+func setResultsFrom(cfgMap map[string]string, feature *string) error {
+	value := DefaultResultsFrom
+	if cfg, ok := cfgMap[resultsFromKey]; ok {
+		value = strings.ToLower(cfg)
+	}
+	switch value {
+	case TerminationMessageResultsFrom, ContainerLogsResultsFrom:
+		*feature = value
+		return nil
+	default:
+		return fmt.Errorf("invalid value for feature flag %q: %q", resultsFromKey, value)
+	}
+}
+
+// setMissingReferencePolicy sets the "missing-reference-policy" flag based on the content of a given map.
+// If the flag is set to an invalid value, an error is returned.
+func setMissingReferencePolicy(cfgMap map[string]string, feature *string) error {
+	value := DefaultMissingReferencePolicy
+	if cfg, ok := cfgMap[missingReferencePolicyKey]; ok {
+		value = strings.ToLower(cfg)
+	}
+	switch value {
+	case FailMissingReferencePolicy, WaitMissingReferencePolicy:
+		*feature = value
+		return nil
+	default:
+		return fmt.Errorf("invalid value for feature flag %q: %q", missingReferencePolicyKey, value)
+	}
+}
+
+// setMissingReferenceTimeout sets the "missing-reference-timeout" flag based on the content of a given map.
+// If the flag isn't a positive duration, an error is returned.
+func setMissingReferenceTimeout(cfgMap map[string]string, feature *time.Duration) error {
+	value := DefaultMissingReferenceTimeout
+	if cfg, ok := cfgMap[missingReferenceTimeoutKey]; ok {
+		d, err := time.ParseDuration(cfg)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid value for feature flag %q: %q", missingReferenceTimeoutKey, cfg)
+		}
+		value = d
+	}
+	*feature = value
+	return nil
+}
+
+// setSeccompLocalhostProfileDir sets the "seccomp-localhost-profile-dir" flag based on the content of a given map.
+// If the flag isn't a relative path within the seccomp profile root of the kubelet, an error is returned.
+func setSeccompLocalhostProfileDir(cfgMap map[string]string, feature *string) error {
+	value := DefaultSeccompLocalhostProfileDir
+	if cfg, ok := cfgMap[seccompLocalhostProfileDirKey]; ok && cfg != "" {
+		value = path.Clean(cfg)
+		if path.IsAbs(value) || value == "." || strings.HasPrefix(value, "..") {
+			return fmt.Errorf("invalid value for feature flag %q: %q", seccompLocalhostProfileDirKey, cfg)
+		}
+	}
+	*feature = value
+	return nil
+}
+
 // NewFeatureFlagsFromConfigMap returns a Config for the given configmap
 func NewFeatureFlagsFromConfigMap(config *corev1.ConfigMap) (*FeatureFlags, error) {
 	return NewFeatureFlagsFromMap(config.Data)
@@ -19,6 +19,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
@@ -36,15 +37,37 @@ func TestNewFeatureFlagsFromConfigMap(t *testing.T) {
 		{
 			expectedConfig: &config.FeatureFlags{
 				RunningInEnvWithInjectedSidecars: config.DefaultRunningInEnvWithInjectedSidecars,
+				EnableAPIFields:                  config.StableAPIFields,
+				EvictedPodPolicy:                 config.FailEvictedPodPolicy,
+				ResultsFrom:                      config.TerminationMessageResultsFrom,
+				MissingReferencePolicy:           config.FailMissingReferencePolicy,
+				MissingReferenceTimeout:          config.DefaultMissingReferenceTimeout,
 			},
 			fileName: config.GetFeatureFlagsConfigName(),
 		},
 		{
 			expectedConfig: &config.FeatureFlags{
-				DisableHomeEnvOverwrite:          true,
-				DisableWorkingDirOverwrite:       true,
-				DisableAffinityAssistant:         true,
-				RunningInEnvWithInjectedSidecars: false,
+				DisableHomeEnvOverwrite:            true,
+				DisableWorkingDirOverwrite:         true,
+				DisableAffinityAssistant:           true,
+				DisableAffinityWithCustomScheduler: true,
+				DisableCredsInit:                   true,
+				RunningInEnvWithInjectedSidecars:   false,
+				EnableAPIFields:                    config.AlphaAPIFields,
+				EnableImageDigestPinning:           true,
+				EnableImagePreWarm:                 true,
+				EnableRetryPodPruning:              true,
+				EnableFailedAttemptResults:         true,
+				EnableStepMetrics:                  true,
+				EnableStdoutResults:                true,
+				EnableUnusedParamWarnings:          true,
+				EnableVulnerabilityScanning:        true,
+				BlockOnCriticalVulns:               true,
+				EvictedPodPolicy:                   config.RetryEvictedPodPolicy,
+				ResultsFrom:                        config.ContainerLogsResultsFrom,
+				MissingReferencePolicy:             config.WaitMissingReferencePolicy,
+				MissingReferenceTimeout:            10 * time.Minute,
+				SeccompLocalhostProfileDir:         "tekton",
 			},
 			fileName: "feature-flags-all-flags-set",
 		},
@@ -59,10 +82,57 @@ func TestNewFeatureFlagsFromEmptyConfigMap(t *testing.T) {
 	FeatureFlagsConfigEmptyName := "feature-flags-empty"
 	expectedConfig := &config.FeatureFlags{
 		RunningInEnvWithInjectedSidecars: true,
+		EnableAPIFields:                  config.StableAPIFields,
+		EvictedPodPolicy:                 config.FailEvictedPodPolicy,
+		ResultsFrom:                      config.TerminationMessageResultsFrom,
+		MissingReferencePolicy:           config.FailMissingReferencePolicy,
+		MissingReferenceTimeout:          config.DefaultMissingReferenceTimeout,
 	}
 	verifyConfigFileWithExpectedFeatureFlagsConfig(t, FeatureFlagsConfigEmptyName, expectedConfig)
 }
 
+func TestNewFeatureFlagsFromConfigMapWithInvalidAPIFields(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-api-fields")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
+func TestNewFeatureFlagsFromConfigMapWithInvalidEvictedPodPolicy(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-evicted-pod-policy")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
+func TestNewFeatureFlagsFromConfigMapWithInvalidResultsFrom(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-results-from")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
+func TestNewFeatureFlagsFromConfigMapWithInvalidMissingReferencePolicy(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-missing-reference-policy")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
+func TestNewFeatureFlagsFromConfigMapWithInvalidMissingReferenceTimeout(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-missing-reference-timeout")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
+func TestNewFeatureFlagsFromConfigMapWithInvalidSeccompLocalhostProfileDir(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, "feature-flags-invalid-seccomp-localhost-profile-dir")
+	if _, err := config.NewFeatureFlagsFromConfigMap(cm); err == nil {
+		t.Error("NewFeatureFlagsFromConfigMap(actual) was expected to return an error")
+	}
+}
+
 func TestGetFeatureFlagsConfigName(t *testing.T) {
 	for _, tc := range []struct {
 		description         string
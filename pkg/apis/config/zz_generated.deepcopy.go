@@ -22,6 +22,7 @@ package config
 
 import (
 	pod "github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	v1 "k8s.io/api/core/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -64,6 +65,26 @@ func (in *Defaults) DeepCopyInto(out *Defaults) {
 		*out = new(pod.Template)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultPropagatedMetadataPrefixes != nil {
+		in, out := &in.DefaultPropagatedMetadataPrefixes, &out.DefaultPropagatedMetadataPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultTTLSecondsAfterFinished != nil {
+		in, out := &in.DefaultTTLSecondsAfterFinished, &out.DefaultTTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultInitContainerResources != nil {
+		in, out := &in.DefaultInitContainerResources, &out.DefaultInitContainerResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultNopContainerResources != nil {
+		in, out := &in.DefaultNopContainerResources, &out.DefaultNopContainerResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -44,11 +44,17 @@ type Images struct {
 	PRImage string
 	// ImageDigestExporterImage is the container image containing our image digest exporter binary.
 	ImageDigestExporterImage string
+	// AWSCLIImage is the container image containing the aws CLI, used to copy workspaces from and to S3.
+	// It is optional, and only needed by the TaskRuns with S3 annotations.
+	AWSCLIImage string
+	// TrivyImage is the container image containing Trivy, used to scan the images of Steps for vulnerabilities.
+	// It is optional, and only needed when the "enable-vulnerability-scanning" feature flag is set.
+	TrivyImage string
 
-	// NOTE: Make sure to add any new images to Validate below!
+	// NOTE: Make sure to add any new required images to Validate below!
 }
 
-// Validate returns an error if any image is not set.
+// Validate returns an error if any required image is not set.
 func (i Images) Validate() error {
 	var unset []string
 	for _, f := range []struct {
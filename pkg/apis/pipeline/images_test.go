@@ -18,11 +18,21 @@ func TestValidate(t *testing.T) {
 		BuildGCSFetcherImage:     "set",
 		PRImage:                  "set",
 		ImageDigestExporterImage: "set",
+		AWSCLIImage:              "set",
+		TrivyImage:               "set",
 	}
 	if err := valid.Validate(); err != nil {
 		t.Errorf("valid Images returned error: %v", err)
 	}
 
+	// The awscli image is only needed to copy workspaces from and to S3, and the
+	// trivy image to scan the images of steps for vulnerabilities.
+	valid.AWSCLIImage = ""
+	valid.TrivyImage = ""
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid Images without optional images returned error: %v", err)
+	}
+
 	invalid := pipeline.Images{
 		EntrypointImage:          "set",
 		NopImage:                 "set",
@@ -34,6 +44,8 @@ func TestValidate(t *testing.T) {
 		BuildGCSFetcherImage:     "", // unset!
 		PRImage:                  "", // unset!
 		ImageDigestExporterImage: "set",
+		AWSCLIImage:              "set",
+		TrivyImage:               "set",
 	}
 	wantErr := "found unset image flags: [build-gcs-fetcher git pr shell]"
 	if err := invalid.Validate(); err == nil {
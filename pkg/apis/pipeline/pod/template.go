@@ -101,6 +101,12 @@ type Template struct {
 	// HostNetwork specifies whether the pod may use the node network namespace
 	// +optional
 	HostNetwork bool `json:"hostNetwork,omitempty"`
+
+	// GPUType is the type of GPU accelerator the pod must run on, e.g.
+	// "nvidia-tesla-t4". It schedules the pod on the nodes with the
+	// "accelerator" label set to it.
+	// +optional
+	GPUType string `json:"gpuType,omitempty"`
 }
 
 func (tpl *Template) Equals(other *Template) bool {
@@ -114,3 +120,64 @@ func (tpl *Template) Equals(other *Template) bool {
 
 	return reflect.DeepEqual(tpl, other)
 }
+
+// MergePodTemplateWithDefault returns the template of a run, completed field
+// by field with the default template: every field the run doesn't set, e.g.
+// its Tolerations when it only sets a NodeSelector, is taken from the default
+// template, while the fields it sets replace the default ones. HostNetwork is
+// enabled if either template enables it. Neither template is modified.
+func MergePodTemplateWithDefault(tpl, defaultTpl *Template) *Template {
+	switch {
+	case tpl == nil:
+		return defaultTpl.DeepCopy()
+	case defaultTpl == nil:
+		return tpl.DeepCopy()
+	}
+	merged := tpl.DeepCopy()
+	def := defaultTpl.DeepCopy()
+
+	if merged.NodeSelector == nil {
+		merged.NodeSelector = def.NodeSelector
+	}
+	if merged.Tolerations == nil {
+		merged.Tolerations = def.Tolerations
+	}
+	if merged.Affinity == nil {
+		merged.Affinity = def.Affinity
+	}
+	if merged.SecurityContext == nil {
+		merged.SecurityContext = def.SecurityContext
+	}
+	if merged.Volumes == nil {
+		merged.Volumes = def.Volumes
+	}
+	if merged.RuntimeClassName == nil {
+		merged.RuntimeClassName = def.RuntimeClassName
+	}
+	if merged.AutomountServiceAccountToken == nil {
+		merged.AutomountServiceAccountToken = def.AutomountServiceAccountToken
+	}
+	if merged.DNSPolicy == nil {
+		merged.DNSPolicy = def.DNSPolicy
+	}
+	if merged.DNSConfig == nil {
+		merged.DNSConfig = def.DNSConfig
+	}
+	if merged.EnableServiceLinks == nil {
+		merged.EnableServiceLinks = def.EnableServiceLinks
+	}
+	if merged.PriorityClassName == nil {
+		merged.PriorityClassName = def.PriorityClassName
+	}
+	if merged.SchedulerName == "" {
+		merged.SchedulerName = def.SchedulerName
+	}
+	if merged.ImagePullSecrets == nil {
+		merged.ImagePullSecrets = def.ImagePullSecrets
+	}
+	if merged.GPUType == "" {
+		merged.GPUType = def.GPUType
+	}
+	merged.HostNetwork = merged.HostNetwork || def.HostNetwork
+	return merged
+}
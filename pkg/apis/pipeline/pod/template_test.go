@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestMergePodTemplateWithDefault(t *testing.T) {
+	runAsUser, defaultRunAsUser := int64(1000), int64(0)
+	gvisor, kata := "gvisor", "kata"
+	yes, no := true, false
+	dnsNone, dnsDefault := corev1.DNSNone, corev1.DNSDefault
+	high, low := "high", "low"
+
+	// Each field is set to a different value in the run template and in the
+	// default template.
+	fields := []struct {
+		field string
+		run   func(*Template)
+		def   func(*Template)
+	}{{
+		field: "NodeSelector",
+		run:   func(t *Template) { t.NodeSelector = map[string]string{"disk": "ssd"} },
+		def:   func(t *Template) { t.NodeSelector = map[string]string{"zone": "a"} },
+	}, {
+		field: "Tolerations",
+		run: func(t *Template) {
+			t.Tolerations = []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}
+		},
+		def: func(t *Template) {
+			t.Tolerations = []corev1.Toleration{{Key: "ci", Operator: corev1.TolerationOpExists}}
+		},
+	}, {
+		field: "Affinity",
+		run: func(t *Template) {
+			t.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}}
+		},
+		def: func(t *Template) {
+			t.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+		},
+	}, {
+		field: "SecurityContext",
+		run:   func(t *Template) { t.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &runAsUser} },
+		def:   func(t *Template) { t.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &defaultRunAsUser} },
+	}, {
+		field: "Volumes",
+		run:   func(t *Template) { t.Volumes = []corev1.Volume{{Name: "cache"}} },
+		def:   func(t *Template) { t.Volumes = []corev1.Volume{{Name: "certs"}} },
+	}, {
+		field: "RuntimeClassName",
+		run:   func(t *Template) { t.RuntimeClassName = &gvisor },
+		def:   func(t *Template) { t.RuntimeClassName = &kata },
+	}, {
+		field: "AutomountServiceAccountToken",
+		run:   func(t *Template) { t.AutomountServiceAccountToken = &yes },
+		def:   func(t *Template) { t.AutomountServiceAccountToken = &no },
+	}, {
+		field: "DNSPolicy",
+		run:   func(t *Template) { t.DNSPolicy = &dnsNone },
+		def:   func(t *Template) { t.DNSPolicy = &dnsDefault },
+	}, {
+		field: "DNSConfig",
+		run:   func(t *Template) { t.DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"1.1.1.1"}} },
+		def:   func(t *Template) { t.DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"8.8.8.8"}} },
+	}, {
+		field: "EnableServiceLinks",
+		run:   func(t *Template) { t.EnableServiceLinks = &no },
+		def:   func(t *Template) { t.EnableServiceLinks = &yes },
+	}, {
+		field: "PriorityClassName",
+		run:   func(t *Template) { t.PriorityClassName = &high },
+		def:   func(t *Template) { t.PriorityClassName = &low },
+	}, {
+		field: "SchedulerName",
+		run:   func(t *Template) { t.SchedulerName = "volcano" },
+		def:   func(t *Template) { t.SchedulerName = "batch" },
+	}, {
+		field: "ImagePullSecrets",
+		run:   func(t *Template) { t.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}} },
+		def:   func(t *Template) { t.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "mirror"}} },
+	}, {
+		field: "GPUType",
+		run:   func(t *Template) { t.GPUType = "nvidia-tesla-t4" },
+		def:   func(t *Template) { t.GPUType = "nvidia-tesla-v100" },
+	}, {
+		field: "HostNetwork",
+		run:   func(t *Template) { t.HostNetwork = true },
+		def:   func(t *Template) { t.HostNetwork = true },
+	}}
+
+	tested := sets.NewString()
+	for _, f := range fields {
+		tested.Insert(f.field)
+		run, def := &Template{}, &Template{}
+		f.run(run)
+		f.def(def)
+		for _, c := range []struct {
+			desc string
+			tpl  *Template
+			def  *Template
+			want *Template
+		}{{
+			desc: "set by the run",
+			tpl:  run,
+			def:  &Template{},
+			want: run,
+		}, {
+			desc: "set by the default",
+			tpl:  &Template{},
+			def:  def,
+			want: def,
+		}, {
+			desc: "set by both",
+			tpl:  run,
+			def:  def,
+			want: run,
+		}, {
+			desc: "set by neither",
+			tpl:  &Template{},
+			def:  &Template{},
+			want: &Template{},
+		}} {
+			t.Run(f.field+" "+c.desc, func(t *testing.T) {
+				tplBefore, defBefore := c.tpl.DeepCopy(), c.def.DeepCopy()
+				got := MergePodTemplateWithDefault(c.tpl, c.def)
+				if d := cmp.Diff(c.want, got); d != "" {
+					t.Errorf("MergePodTemplateWithDefault %s", diff.PrintWantGot(d))
+				}
+				if d := cmp.Diff(tplBefore, c.tpl); d != "" {
+					t.Errorf("The run template was modified %s", diff.PrintWantGot(d))
+				}
+				if d := cmp.Diff(defBefore, c.def); d != "" {
+					t.Errorf("The default template was modified %s", diff.PrintWantGot(d))
+				}
+			})
+		}
+	}
+
+	// Every field of Template must have its merge tested.
+	tpl := reflect.TypeOf(Template{})
+	for i := 0; i < tpl.NumField(); i++ {
+		if name := tpl.Field(i).Name; !tested.Has(name) {
+			t.Errorf("The merge of the field %s isn't tested", name)
+		}
+	}
+}
+
+func TestMergePodTemplateWithDefault_Combined(t *testing.T) {
+	yes, no := true, false
+	defaultTpl := &Template{
+		Tolerations:                  []corev1.Toleration{{Key: "ci", Operator: corev1.TolerationOpExists}},
+		NodeSelector:                 map[string]string{"zone": "a"},
+		AutomountServiceAccountToken: &no,
+	}
+	for _, c := range []struct {
+		desc string
+		tpl  *Template
+		def  *Template
+		want *Template
+	}{{
+		desc: "no templates",
+	}, {
+		desc: "only the default template",
+		def:  defaultTpl,
+		want: defaultTpl,
+	}, {
+		desc: "only the run template",
+		tpl:  &Template{SchedulerName: "volcano"},
+		want: &Template{SchedulerName: "volcano"},
+	}, {
+		desc: "default tolerations kept when the run sets a node selector",
+		tpl: &Template{
+			NodeSelector:                 map[string]string{"disk": "ssd"},
+			AutomountServiceAccountToken: &yes,
+		},
+		def: defaultTpl,
+		want: &Template{
+			Tolerations:                  []corev1.Toleration{{Key: "ci", Operator: corev1.TolerationOpExists}},
+			NodeSelector:                 map[string]string{"disk": "ssd"},
+			AutomountServiceAccountToken: &yes,
+		},
+	}, {
+		desc: "empty lists set by the run replace the default ones",
+		tpl:  &Template{Tolerations: []corev1.Toleration{}},
+		def:  defaultTpl,
+		want: &Template{
+			Tolerations:                  []corev1.Toleration{},
+			NodeSelector:                 map[string]string{"zone": "a"},
+			AutomountServiceAccountToken: &no,
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got := MergePodTemplateWithDefault(c.tpl, c.def)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("MergePodTemplateWithDefault %s", diff.PrintWantGot(d))
+			}
+			if c.def != nil && got == c.def {
+				t.Error("Expected a copy of the default template")
+			}
+		})
+	}
+}
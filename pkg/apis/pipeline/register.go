@@ -48,6 +48,13 @@ const (
 
 	// RunKey is used as the label identifier for a Run
 	RunKey = "/run"
+
+	// TaskTagLabelKeyPrefix is the prefix of the labels identifying the tags of a Task
+	TaskTagLabelKeyPrefix = "tags." + GroupName + "/"
+
+	// TTLSecondsAfterFinishedKey is used as the annotation identifier for the number of
+	// seconds after which a finished run is deleted
+	TTLSecondsAfterFinishedKey = "/ttl-seconds-after-finished"
 )
 
 var (
@@ -32,6 +32,11 @@ func (source *ClusterTask) ConvertTo(ctx context.Context, obj apis.Convertible)
 	switch sink := obj.(type) {
 	case *v1beta1.ClusterTask:
 		sink.ObjectMeta = source.ObjectMeta
+		stash := deprecatedFieldsByPath{}
+		source.Spec.stashDeprecatedFields("spec", stash)
+		if err := setDeprecatedFieldsAnnotation(&sink.ObjectMeta, stash); err != nil {
+			return err
+		}
 		return source.Spec.ConvertTo(ctx, &sink.Spec)
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
@@ -43,7 +48,15 @@ func (sink *ClusterTask) ConvertFrom(ctx context.Context, obj apis.Convertible)
 	switch source := obj.(type) {
 	case *v1beta1.ClusterTask:
 		sink.ObjectMeta = source.ObjectMeta
-		return sink.Spec.ConvertFrom(ctx, &source.Spec)
+		stash, err := popDeprecatedFieldsAnnotation(&sink.ObjectMeta)
+		if err != nil {
+			return err
+		}
+		if err := sink.Spec.ConvertFrom(ctx, &source.Spec); err != nil {
+			return err
+		}
+		sink.Spec.restoreDeprecatedFields("spec", stash)
+		return nil
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
 	}
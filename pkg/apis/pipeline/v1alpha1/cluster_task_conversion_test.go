@@ -189,7 +189,6 @@ func TestClusterTaskConversionFromDeprecated(t *testing.T) {
 	tests := []struct {
 		name     string
 		in       *ClusterTask
-		want     *ClusterTask
 		badField string
 	}{{
 		name: "inputs params",
@@ -209,22 +208,6 @@ func TestClusterTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &ClusterTask{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Params: []v1beta1.ParamSpec{{
-						Name:        "param-1",
-						Type:        v1beta1.ParamTypeString,
-						Description: "My first param",
-					}},
-				},
-			},
-		},
 	}, {
 		name: "inputs resource",
 		in: &ClusterTask{
@@ -242,23 +225,6 @@ func TestClusterTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &ClusterTask{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Resources: &v1beta1.TaskResources{
-						Inputs: []v1beta1.TaskResource{{ResourceDeclaration: v1beta1.ResourceDeclaration{
-							Name: "input-1",
-							Type: resource.PipelineResourceTypeGit,
-						}}},
-					},
-				},
-			},
-		},
 	}, {
 		name: "outputs resource",
 		in: &ClusterTask{
@@ -276,23 +242,6 @@ func TestClusterTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &ClusterTask{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Resources: &v1beta1.TaskResources{
-						Outputs: []v1beta1.TaskResource{{ResourceDeclaration: v1beta1.ResourceDeclaration{
-							Name: "output-1",
-							Type: resource.PipelineResourceTypeGit,
-						}}},
-					},
-				},
-			},
-		},
 	}}
 	for _, test := range tests {
 		for _, version := range versions {
@@ -313,7 +262,7 @@ func TestClusterTaskConversionFromDeprecated(t *testing.T) {
 					t.Errorf("ConvertFrom() = %v", err)
 				}
 				t.Logf("ConvertFrom() = %#v", got)
-				if d := cmp.Diff(test.want, got); d != "" {
+				if d := cmp.Diff(test.in, got); d != "" {
 					t.Errorf("roundtrip %s", diff.PrintWantGot(d))
 				}
 			})
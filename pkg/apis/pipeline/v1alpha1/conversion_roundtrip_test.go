@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+)
+
+const roundTripIterations = 200
+
+// newRoundTripFuzzer returns a fuzzer generating v1alpha1 objects that could pass validation,
+// i.e. that don't declare the same things both through deprecated fields and their replacement.
+func newRoundTripFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().RandSource(rand.NewSource(seed)).NilChance(.3).NumElements(0, 2).MaxDepth(8).Funcs(
+		// TypeMeta is set by the API server for the requested version.
+		func(*metav1.TypeMeta, fuzz.Continue) {},
+		// Kubernetes types and status are copied as is by the conversion, keep them small.
+		func(om *metav1.ObjectMeta, c fuzz.Continue) {
+			c.Fuzz(&om.Name)
+			c.Fuzz(&om.Namespace)
+			c.Fuzz(&om.Labels)
+			c.Fuzz(&om.Annotations)
+		},
+		func(s *v1beta1.Step, c fuzz.Continue) {
+			c.Fuzz(&s.Name)
+			c.Fuzz(&s.Image)
+			c.Fuzz(&s.Script)
+		},
+		func(s *v1beta1.Sidecar, c fuzz.Continue) {
+			c.Fuzz(&s.Name)
+			c.Fuzz(&s.Image)
+			c.Fuzz(&s.Script)
+		},
+		func(ctr *corev1.Container, c fuzz.Continue) {
+			c.Fuzz(&ctr.Image)
+		},
+		func(v *corev1.Volume, c fuzz.Continue) {
+			c.Fuzz(&v.Name)
+		},
+		func(t *pod.Template, c fuzz.Continue) {
+			c.Fuzz(&t.NodeSelector)
+		},
+		func(d *metav1.Duration, c fuzz.Continue) {
+			d.Duration = time.Duration(c.Rand.Int63())
+		},
+		func(s *v1beta1.TaskRunStatus, c fuzz.Continue) {
+			c.Fuzz(&s.PodName)
+		},
+		func(s *v1beta1.PipelineRunStatus, c fuzz.Continue) {
+			c.Fuzz(&s.ObservedGeneration)
+		},
+		// v1beta1 Pipelines don't have a status.
+		func(p *Pipeline, c fuzz.Continue) {
+			c.FuzzNoCustom(p)
+			p.Status = nil
+		},
+		func(ts *TaskSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(ts)
+			if ts.Inputs != nil {
+				if len(ts.Inputs.Params) > 0 {
+					ts.Params = nil
+				}
+				if len(ts.Inputs.Resources) > 0 && ts.Resources != nil {
+					ts.Resources.Inputs = nil
+				}
+				if len(ts.Inputs.Params) == 0 && len(ts.Inputs.Resources) == 0 {
+					ts.Inputs = nil
+				}
+			}
+			if ts.Outputs != nil {
+				if len(ts.Outputs.Resources) > 0 && ts.Resources != nil {
+					ts.Resources.Outputs = nil
+				}
+				if len(ts.Outputs.Resources) == 0 && len(ts.Outputs.Results) == 0 {
+					ts.Outputs = nil
+				}
+			}
+			if ts.Resources != nil && len(ts.Resources.Inputs) == 0 && len(ts.Resources.Outputs) == 0 {
+				ts.Resources = nil
+			}
+		},
+		func(trs *TaskRunSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(trs)
+			if trs.Inputs != nil {
+				if len(trs.Inputs.Params) > 0 {
+					trs.Params = nil
+				}
+				if len(trs.Inputs.Resources) > 0 && trs.Resources != nil {
+					trs.Resources.Inputs = nil
+				}
+				if len(trs.Inputs.Params) == 0 && len(trs.Inputs.Resources) == 0 {
+					trs.Inputs = nil
+				}
+			}
+			if trs.Outputs != nil {
+				if len(trs.Outputs.Resources) > 0 && trs.Resources != nil {
+					trs.Resources.Outputs = nil
+				}
+				if len(trs.Outputs.Resources) == 0 {
+					trs.Outputs = nil
+				}
+			}
+			if trs.Resources != nil && len(trs.Resources.Inputs) == 0 && len(trs.Resources.Outputs) == 0 {
+				trs.Resources = nil
+			}
+		},
+	)
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   func() apis.Convertible
+		via  func() apis.Convertible
+	}{{
+		name: "task",
+		in:   func() apis.Convertible { return &Task{} },
+		via:  func() apis.Convertible { return &v1beta1.Task{} },
+	}, {
+		name: "clustertask",
+		in:   func() apis.Convertible { return &ClusterTask{} },
+		via:  func() apis.Convertible { return &v1beta1.ClusterTask{} },
+	}, {
+		name: "taskrun",
+		in:   func() apis.Convertible { return &TaskRun{} },
+		via:  func() apis.Convertible { return &v1beta1.TaskRun{} },
+	}, {
+		name: "pipeline",
+		in:   func() apis.Convertible { return &Pipeline{} },
+		via:  func() apis.Convertible { return &v1beta1.Pipeline{} },
+	}, {
+		name: "pipelinerun",
+		in:   func() apis.Convertible { return &PipelineRun{} },
+		via:  func() apis.Convertible { return &v1beta1.PipelineRun{} },
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newRoundTripFuzzer(1)
+			for i := 0; i < roundTripIterations; i++ {
+				in := tc.in()
+				f.Fuzz(in)
+				want := in.(runtime.Object).DeepCopyObject()
+
+				via := tc.via()
+				if err := in.ConvertTo(context.Background(), via); err != nil {
+					t.Fatalf("ConvertTo() = %v", err)
+				}
+				got := tc.in()
+				if err := got.ConvertFrom(context.Background(), via); err != nil {
+					t.Fatalf("ConvertFrom() = %v", err)
+				}
+				if d := cmp.Diff(want, got, cmpopts.EquateEmpty()); d != "" {
+					t.Fatalf("roundtrip %s", diff.PrintWantGot(d))
+				}
+				if d := cmp.Diff(want, in, cmpopts.EquateEmpty()); d != "" {
+					t.Fatalf("ConvertTo() modified its source %s", diff.PrintWantGot(d))
+				}
+			}
+		})
+	}
+}
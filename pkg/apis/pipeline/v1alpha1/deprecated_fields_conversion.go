@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeprecatedFieldsAnnotationKey is the annotation used to stash which fields of a
+// v1beta1 object were converted from deprecated v1alpha1 fields (inputs and outputs),
+// so that converting it back to v1alpha1 restores them.
+const DeprecatedFieldsAnnotationKey = "tekton.dev/v1alpha1-deprecated-fields"
+
+// deprecatedFields records the deprecated v1alpha1 fields used by a TaskSpec or TaskRunSpec.
+type deprecatedFields struct {
+	InputParams     bool `json:"inputParams,omitempty"`
+	InputResources  bool `json:"inputResources,omitempty"`
+	OutputResources bool `json:"outputResources,omitempty"`
+	// OutputResults have no v1beta1 equivalent, so they are stashed as is.
+	OutputResults []TestResult `json:"outputResults,omitempty"`
+}
+
+func (df deprecatedFields) isEmpty() bool {
+	return !df.InputParams && !df.InputResources && !df.OutputResources && len(df.OutputResults) == 0
+}
+
+// deprecatedFieldsByPath holds the deprecatedFields of every spec of an object, keyed
+// by the path of the spec in the object (e.g. "spec.tasks[0].taskSpec").
+type deprecatedFieldsByPath map[string]deprecatedFields
+
+func (stash deprecatedFieldsByPath) add(path string, df deprecatedFields) {
+	if !df.isEmpty() {
+		stash[path] = df
+	}
+}
+
+// setDeprecatedFieldsAnnotation stores the stash in the annotations of meta, if it isn't empty.
+// The annotations are copied so that the ones of the source object are left untouched.
+func setDeprecatedFieldsAnnotation(meta *metav1.ObjectMeta, stash deprecatedFieldsByPath) error {
+	if len(stash) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(stash)
+	if err != nil {
+		return fmt.Errorf("error stashing deprecated fields: %w", err)
+	}
+	annotations := make(map[string]string, len(meta.Annotations)+1)
+	for k, v := range meta.Annotations {
+		annotations[k] = v
+	}
+	annotations[DeprecatedFieldsAnnotationKey] = string(b)
+	meta.Annotations = annotations
+	return nil
+}
+
+// popDeprecatedFieldsAnnotation returns the stash stored in the annotations of meta, and
+// removes it from them. The annotations are copied so that the ones of the source object
+// are left untouched.
+func popDeprecatedFieldsAnnotation(meta *metav1.ObjectMeta) (deprecatedFieldsByPath, error) {
+	stash := deprecatedFieldsByPath{}
+	value, ok := meta.Annotations[DeprecatedFieldsAnnotationKey]
+	if !ok {
+		return stash, nil
+	}
+	if err := json.Unmarshal([]byte(value), &stash); err != nil {
+		return nil, fmt.Errorf("error restoring deprecated fields: %w", err)
+	}
+	var annotations map[string]string
+	if len(meta.Annotations) > 1 {
+		annotations = make(map[string]string, len(meta.Annotations)-1)
+		for k, v := range meta.Annotations {
+			if k != DeprecatedFieldsAnnotationKey {
+				annotations[k] = v
+			}
+		}
+	}
+	meta.Annotations = annotations
+	return stash, nil
+}
+
+func (ts *TaskSpec) stashDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	df := deprecatedFields{}
+	if ts.Inputs != nil {
+		df.InputParams = len(ts.Inputs.Params) > 0
+		df.InputResources = len(ts.Inputs.Resources) > 0
+	}
+	if ts.Outputs != nil {
+		df.OutputResources = len(ts.Outputs.Resources) > 0
+		df.OutputResults = ts.Outputs.Results
+	}
+	stash.add(path, df)
+}
+
+// restoreDeprecatedFields moves the fields of ts converted from deprecated fields back to them.
+func (ts *TaskSpec) restoreDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	df, ok := stash[path]
+	if !ok {
+		return
+	}
+	// Resources is shared with the v1beta1 source, copy it before moving fields out of it
+	ts.Resources = ts.Resources.DeepCopy()
+	if df.InputParams || df.InputResources {
+		ts.Inputs = &Inputs{}
+	}
+	if df.InputParams {
+		ts.Inputs.Params, ts.Params = ts.Params, nil
+	}
+	if df.InputResources && ts.Resources != nil {
+		ts.Inputs.Resources, ts.Resources.Inputs = ts.Resources.Inputs, nil
+	}
+	if df.OutputResources || len(df.OutputResults) > 0 {
+		ts.Outputs = &Outputs{Results: df.OutputResults}
+	}
+	if df.OutputResources && ts.Resources != nil {
+		ts.Outputs.Resources, ts.Resources.Outputs = ts.Resources.Outputs, nil
+	}
+	if ts.Resources != nil && len(ts.Resources.Inputs) == 0 && len(ts.Resources.Outputs) == 0 {
+		ts.Resources = nil
+	}
+}
+
+func (trs *TaskRunSpec) stashDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	df := deprecatedFields{}
+	if trs.Inputs != nil {
+		df.InputParams = len(trs.Inputs.Params) > 0
+		df.InputResources = len(trs.Inputs.Resources) > 0
+	}
+	if trs.Outputs != nil {
+		df.OutputResources = len(trs.Outputs.Resources) > 0
+	}
+	stash.add(path, df)
+	if trs.TaskSpec != nil {
+		trs.TaskSpec.stashDeprecatedFields(path+".taskSpec", stash)
+	}
+}
+
+// restoreDeprecatedFields moves the fields of trs converted from deprecated fields back to them.
+func (trs *TaskRunSpec) restoreDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	if trs.TaskSpec != nil {
+		trs.TaskSpec.restoreDeprecatedFields(path+".taskSpec", stash)
+	}
+	df, ok := stash[path]
+	if !ok {
+		return
+	}
+	// Resources is shared with the v1beta1 source, copy it before moving fields out of it
+	trs.Resources = trs.Resources.DeepCopy()
+	if df.InputParams || df.InputResources {
+		trs.Inputs = &TaskRunInputs{}
+	}
+	if df.InputParams {
+		trs.Inputs.Params, trs.Params = trs.Params, nil
+	}
+	if df.InputResources && trs.Resources != nil {
+		trs.Inputs.Resources, trs.Resources.Inputs = trs.Resources.Inputs, nil
+	}
+	if df.OutputResources && trs.Resources != nil {
+		trs.Outputs = &TaskRunOutputs{}
+		trs.Outputs.Resources, trs.Resources.Outputs = trs.Resources.Outputs, nil
+	}
+	if trs.Resources != nil && len(trs.Resources.Inputs) == 0 && len(trs.Resources.Outputs) == 0 {
+		trs.Resources = nil
+	}
+}
+
+func (ps *PipelineSpec) stashDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	for i, pt := range ps.Tasks {
+		if pt.TaskSpec != nil {
+			pt.TaskSpec.stashDeprecatedFields(fmt.Sprintf("%s.tasks[%d].taskSpec", path, i), stash)
+		}
+	}
+}
+
+// restoreDeprecatedFields moves the fields of the embedded specs of ps converted from
+// deprecated fields back to them.
+func (ps *PipelineSpec) restoreDeprecatedFields(path string, stash deprecatedFieldsByPath) {
+	for i, pt := range ps.Tasks {
+		if pt.TaskSpec != nil {
+			pt.TaskSpec.restoreDeprecatedFields(fmt.Sprintf("%s.tasks[%d].taskSpec", path, i), stash)
+		}
+	}
+}
@@ -25,7 +25,11 @@ import (
 	"knative.dev/pkg/apis"
 )
 
-const FinallyFieldName = "finally"
+const (
+	FinallyFieldName           = "finally"
+	ContinueOnFailureFieldName = "continueOnFailure"
+	WhenExpressionsFieldName   = "when"
+)
 
 var _ apis.Convertible = (*Pipeline)(nil)
 
@@ -34,6 +38,11 @@ func (source *Pipeline) ConvertTo(ctx context.Context, obj apis.Convertible) err
 	switch sink := obj.(type) {
 	case *v1beta1.Pipeline:
 		sink.ObjectMeta = source.ObjectMeta
+		stash := deprecatedFieldsByPath{}
+		source.Spec.stashDeprecatedFields("spec", stash)
+		if err := setDeprecatedFieldsAnnotation(&sink.ObjectMeta, stash); err != nil {
+			return err
+		}
 		return source.Spec.ConvertTo(ctx, &sink.Spec)
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
@@ -45,6 +54,7 @@ func (source *PipelineSpec) ConvertTo(ctx context.Context, sink *v1beta1.Pipelin
 	sink.Params = source.Params
 	sink.Workspaces = source.Workspaces
 	sink.Description = source.Description
+	sink.Results = source.Results
 	if len(source.Tasks) > 0 {
 		sink.Tasks = make([]v1beta1.PipelineTask, len(source.Tasks))
 		for i := range source.Tasks {
@@ -81,7 +91,15 @@ func (sink *Pipeline) ConvertFrom(ctx context.Context, obj apis.Convertible) err
 	switch source := obj.(type) {
 	case *v1beta1.Pipeline:
 		sink.ObjectMeta = source.ObjectMeta
-		return sink.Spec.ConvertFrom(ctx, source.Spec)
+		stash, err := popDeprecatedFieldsAnnotation(&sink.ObjectMeta)
+		if err != nil {
+			return err
+		}
+		if err := sink.Spec.ConvertFrom(ctx, source.Spec); err != nil {
+			return err
+		}
+		sink.Spec.restoreDeprecatedFields("spec", stash)
+		return nil
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
 	}
@@ -92,6 +110,7 @@ func (sink *PipelineSpec) ConvertFrom(ctx context.Context, source v1beta1.Pipeli
 	sink.Params = source.Params
 	sink.Workspaces = source.Workspaces
 	sink.Description = source.Description
+	sink.Results = source.Results
 	if len(source.Tasks) > 0 {
 		sink.Tasks = make([]PipelineTask, len(source.Tasks))
 		for i := range source.Tasks {
@@ -123,5 +142,13 @@ func (sink *PipelineTask) ConvertFrom(ctx context.Context, source v1beta1.Pipeli
 	sink.Params = source.Params
 	sink.Workspaces = source.Workspaces
 	sink.Timeout = source.Timeout
+	// continueOnFailure was introduced in v1beta1 and not available in v1alpha1
+	if source.ContinueOnFailure {
+		return ConvertErrorf(ContinueOnFailureFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	// when expressions were introduced in v1beta1 and not available in v1alpha1
+	if len(source.WhenExpressions) > 0 {
+		return ConvertErrorf(WhenExpressionsFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
 	return nil
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 )
 
@@ -255,3 +256,54 @@ func TestPipelineConversionFromBetaToAlphaWithFinally_Failure(t *testing.T) {
 		}
 	})
 }
+
+func TestPipelineConversionFromBetaToAlphaWithContinueOnFailure_Failure(t *testing.T) {
+	p := &v1beta1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{Name: "mytask", TaskRef: &TaskRef{Name: "task"}, ContinueOnFailure: true}},
+		},
+	}
+	got := &Pipeline{}
+	err := got.ConvertFrom(context.Background(), p)
+	if err == nil {
+		t.Fatal("ConvertFrom() should have failed")
+	}
+	// conversion error (cce) contains the field name which resulted in the failure and should be equal to "continueOnFailure" here
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != ContinueOnFailureFieldName {
+		t.Errorf("ConvertFrom() = %v, expected a conversion error for field %q", err, ContinueOnFailureFieldName)
+	}
+}
+
+func TestPipelineConversionFromBetaToAlphaWithWhenExpressions_Failure(t *testing.T) {
+	p := &v1beta1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{
+				Name:    "mytask",
+				TaskRef: &TaskRef{Name: "task"},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    "foo",
+					Operator: selection.In,
+					Values:   []string{"foo"},
+				}},
+			}},
+		},
+	}
+	got := &Pipeline{}
+	err := got.ConvertFrom(context.Background(), p)
+	if err == nil {
+		t.Fatal("ConvertFrom() should have failed")
+	}
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != WhenExpressionsFieldName {
+		t.Errorf("ConvertFrom() = %v, expected a conversion error for field %q", err, WhenExpressionsFieldName)
+	}
+}
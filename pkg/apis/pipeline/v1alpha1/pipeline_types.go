@@ -18,7 +18,7 @@ package v1alpha1
 
 import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
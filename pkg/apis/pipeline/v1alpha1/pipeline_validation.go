@@ -18,12 +18,13 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	"github.com/tektoncd/pipeline/pkg/list"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/substitution"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -130,9 +131,14 @@ func validateFrom(tasks []PipelineTask) *apis.FieldError {
 
 // validateGraph ensures the Pipeline's dependency Graph (DAG) make sense: that there is no dependency
 // cycle or that they rely on values from Tasks that ran previously, and that the PipelineResource
-// is actually an output of the Task it should come from.
+// is actually an output of the Task it should come from. A dependency cycle is reported by
+// itself, naming the Tasks forming it.
 func validateGraph(tasks []PipelineTask) error {
 	if _, err := dag.Build(PipelineTaskList(tasks)); err != nil {
+		var cycle *dag.CycleError
+		if errors.As(err, &cycle) {
+			return cycle
+		}
 		return err
 	}
 	return nil
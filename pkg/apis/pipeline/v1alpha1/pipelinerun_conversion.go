@@ -32,6 +32,13 @@ func (source *PipelineRun) ConvertTo(ctx context.Context, obj apis.Convertible)
 	switch sink := obj.(type) {
 	case *v1beta1.PipelineRun:
 		sink.ObjectMeta = source.ObjectMeta
+		stash := deprecatedFieldsByPath{}
+		if source.Spec.PipelineSpec != nil {
+			source.Spec.PipelineSpec.stashDeprecatedFields("spec.pipelineSpec", stash)
+		}
+		if err := setDeprecatedFieldsAnnotation(&sink.ObjectMeta, stash); err != nil {
+			return err
+		}
 		if err := source.Spec.ConvertTo(ctx, &sink.Spec); err != nil {
 			return err
 		}
@@ -58,6 +65,12 @@ func (source *PipelineRunSpec) ConvertTo(ctx context.Context, sink *v1beta1.Pipe
 	sink.Timeout = source.Timeout
 	sink.PodTemplate = source.PodTemplate
 	sink.Workspaces = source.Workspaces
+	if len(source.TaskRunSpecs) > 0 {
+		sink.TaskRunSpecs = make([]v1beta1.PipelineTaskRunSpec, len(source.TaskRunSpecs))
+		for i, trs := range source.TaskRunSpecs {
+			sink.TaskRunSpecs[i] = v1beta1.PipelineTaskRunSpec(trs)
+		}
+	}
 	return nil
 }
 
@@ -66,9 +79,16 @@ func (sink *PipelineRun) ConvertFrom(ctx context.Context, obj apis.Convertible)
 	switch source := obj.(type) {
 	case *v1beta1.PipelineRun:
 		sink.ObjectMeta = source.ObjectMeta
+		stash, err := popDeprecatedFieldsAnnotation(&sink.ObjectMeta)
+		if err != nil {
+			return err
+		}
 		if err := sink.Spec.ConvertFrom(ctx, &source.Spec); err != nil {
 			return err
 		}
+		if sink.Spec.PipelineSpec != nil {
+			sink.Spec.PipelineSpec.restoreDeprecatedFields("spec.pipelineSpec", stash)
+		}
 		sink.Status = source.Status
 		return nil
 	default:
@@ -92,5 +112,11 @@ func (sink *PipelineRunSpec) ConvertFrom(ctx context.Context, source *v1beta1.Pi
 	sink.Timeout = source.Timeout
 	sink.PodTemplate = source.PodTemplate
 	sink.Workspaces = source.Workspaces
+	if len(source.TaskRunSpecs) > 0 {
+		sink.TaskRunSpecs = make([]PipelineTaskRunSpec, len(source.TaskRunSpecs))
+		for i, trs := range source.TaskRunSpecs {
+			sink.TaskRunSpecs[i] = PipelineTaskRunSpec(trs)
+		}
+	}
 	return nil
 }
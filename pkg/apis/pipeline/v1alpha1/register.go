@@ -62,6 +62,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PipelineResourceList{},
 		&Run{},
 		&RunList{},
+		&VulnerabilitySummary{},
+		&VulnerabilitySummaryList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
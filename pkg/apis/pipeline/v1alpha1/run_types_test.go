@@ -22,6 +22,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -153,7 +154,7 @@ status:
 			},
 			RunStatusFields: v1alpha1.RunStatusFields{
 				// Results are parsed correctly.
-				Results: []v1beta1.TaskRunResult{{
+				Results: []runv1alpha1.RunResult{{
 					Name:  "foo",
 					Value: "bar",
 				}},
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -54,5 +55,9 @@ func (rs *RunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if rs.Status != "" && rs.Status != RunSpecStatusCancelled {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s", rs.Status, RunSpecStatusCancelled), "spec.status")
+	}
+
 	return nil
 }
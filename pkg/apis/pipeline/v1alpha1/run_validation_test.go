@@ -93,6 +93,18 @@ func TestRun_Invalid(t *testing.T) {
 			},
 		},
 		want: apis.ErrMultipleOneOf("spec.params"),
+	}, {
+		name: "invalid status",
+		run: &v1alpha1.Run{
+			Spec: v1alpha1.RunSpec{
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: "blah",
+					Kind:       "blah",
+				},
+				Status: "RunPaused",
+			},
+		},
+		want: apis.ErrInvalidValue("RunPaused should be RunCancelled", "spec.status"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			err := c.run.Validate(context.Background())
@@ -145,6 +157,17 @@ func TestRun_Valid(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "cancelled",
+		run: &v1alpha1.Run{
+			Spec: v1alpha1.RunSpec{
+				Ref: &v1alpha1.TaskRef{
+					APIVersion: "blah",
+					Kind:       "blah",
+				},
+				Status: v1alpha1.RunSpecStatusCancelled,
+			},
+		},
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			if err := c.run.Validate(context.Background()); err != nil {
@@ -32,6 +32,11 @@ func (source *Task) ConvertTo(ctx context.Context, obj apis.Convertible) error {
 	switch sink := obj.(type) {
 	case *v1beta1.Task:
 		sink.ObjectMeta = source.ObjectMeta
+		stash := deprecatedFieldsByPath{}
+		source.Spec.stashDeprecatedFields("spec", stash)
+		if err := setDeprecatedFieldsAnnotation(&sink.ObjectMeta, stash); err != nil {
+			return err
+		}
 		return source.Spec.ConvertTo(ctx, &sink.Spec)
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
@@ -43,11 +48,17 @@ func (source *TaskSpec) ConvertTo(ctx context.Context, sink *v1beta1.TaskSpec) e
 	sink.Volumes = source.Volumes
 	sink.StepTemplate = source.StepTemplate
 	sink.Sidecars = source.Sidecars
+	sink.InitContainers = source.InitContainers
 	sink.Workspaces = source.Workspaces
 	sink.Results = source.Results
-	sink.Resources = source.Resources
+	sink.InputValidation = source.InputValidation
+	sink.Platforms = source.Platforms
+	sink.Hooks = source.Hooks
+	sink.Resources = source.Resources.DeepCopy()
 	sink.Params = source.Params
 	sink.Description = source.Description
+	sink.Author = source.Author
+	sink.Tags = source.Tags
 	if source.Inputs != nil {
 		if len(source.Inputs.Params) > 0 && len(source.Params) > 0 {
 			// This shouldn't happen as it shouldn't pass validation
@@ -106,7 +117,15 @@ func (sink *Task) ConvertFrom(ctx context.Context, obj apis.Convertible) error {
 	switch source := obj.(type) {
 	case *v1beta1.Task:
 		sink.ObjectMeta = source.ObjectMeta
-		return sink.Spec.ConvertFrom(ctx, &source.Spec)
+		stash, err := popDeprecatedFieldsAnnotation(&sink.ObjectMeta)
+		if err != nil {
+			return err
+		}
+		if err := sink.Spec.ConvertFrom(ctx, &source.Spec); err != nil {
+			return err
+		}
+		sink.Spec.restoreDeprecatedFields("spec", stash)
+		return nil
 	default:
 		return fmt.Errorf("unknown version, got: %T", sink)
 	}
@@ -117,10 +136,16 @@ func (sink *TaskSpec) ConvertFrom(ctx context.Context, source *v1beta1.TaskSpec)
 	sink.Volumes = source.Volumes
 	sink.StepTemplate = source.StepTemplate
 	sink.Sidecars = source.Sidecars
+	sink.InitContainers = source.InitContainers
 	sink.Workspaces = source.Workspaces
 	sink.Results = source.Results
+	sink.InputValidation = source.InputValidation
+	sink.Platforms = source.Platforms
+	sink.Hooks = source.Hooks
 	sink.Params = source.Params
 	sink.Resources = source.Resources
 	sink.Description = source.Description
+	sink.Author = source.Author
+	sink.Tags = source.Tags
 	return nil
 }
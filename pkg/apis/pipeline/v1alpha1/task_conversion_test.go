@@ -190,7 +190,6 @@ func TestTaskConversionFromDeprecated(t *testing.T) {
 	tests := []struct {
 		name     string
 		in       *Task
-		want     *Task
 		badField string
 	}{{
 		name: "inputs params",
@@ -210,22 +209,6 @@ func TestTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &Task{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Params: []v1beta1.ParamSpec{{
-						Name:        "param-1",
-						Type:        v1beta1.ParamTypeString,
-						Description: "My first param",
-					}},
-				},
-			},
-		},
 	}, {
 		name: "inputs resource",
 		in: &Task{
@@ -243,23 +226,6 @@ func TestTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &Task{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Resources: &v1beta1.TaskResources{
-						Inputs: []v1beta1.TaskResource{{ResourceDeclaration: v1beta1.ResourceDeclaration{
-							Name: "input-1",
-							Type: resource.PipelineResourceTypeGit,
-						}}},
-					},
-				},
-			},
-		},
 	}, {
 		name: "outputs resource",
 		in: &Task{
@@ -277,23 +243,6 @@ func TestTaskConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &Task{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskSpec{
-				TaskSpec: v1beta1.TaskSpec{
-					Resources: &v1beta1.TaskResources{
-						Outputs: []v1beta1.TaskResource{{ResourceDeclaration: v1beta1.ResourceDeclaration{
-							Name: "output-1",
-							Type: resource.PipelineResourceTypeGit,
-						}}},
-					},
-				},
-			},
-		},
 	}}
 	for _, test := range tests {
 		for _, version := range versions {
@@ -314,7 +263,7 @@ func TestTaskConversionFromDeprecated(t *testing.T) {
 					t.Errorf("ConvertFrom() = %v", err)
 				}
 				t.Logf("ConvertFrom() = %#v", got)
-				if d := cmp.Diff(test.want, got); d != "" {
+				if d := cmp.Diff(test.in, got); d != "" {
 					t.Errorf("roundtrip %s", diff.PrintWantGot(d))
 				}
 			})
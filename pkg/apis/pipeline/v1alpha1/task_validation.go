@@ -63,6 +63,10 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if err := ts.ValidateEnabledAPIFields(ctx); err != nil {
+		return err
+	}
+
 	if ts.Inputs != nil {
 		if len(ts.Inputs.Params) > 0 && len(ts.Params) > 0 {
 			return apis.ErrMultipleOneOf("inputs.params", "params")
@@ -32,6 +32,11 @@ func (source *TaskRun) ConvertTo(ctx context.Context, obj apis.Convertible) erro
 	switch sink := obj.(type) {
 	case *v1beta1.TaskRun:
 		sink.ObjectMeta = source.ObjectMeta
+		stash := deprecatedFieldsByPath{}
+		source.Spec.stashDeprecatedFields("spec", stash)
+		if err := setDeprecatedFieldsAnnotation(&sink.ObjectMeta, stash); err != nil {
+			return err
+		}
 		if err := source.Spec.ConvertTo(ctx, &sink.Spec); err != nil {
 			return err
 		}
@@ -56,7 +61,7 @@ func (source *TaskRunSpec) ConvertTo(ctx context.Context, sink *v1beta1.TaskRunS
 	sink.PodTemplate = source.PodTemplate
 	sink.Workspaces = source.Workspaces
 	sink.Params = source.Params
-	sink.Resources = source.Resources
+	sink.Resources = source.Resources.DeepCopy()
 	// Deprecated fields
 	if source.Inputs != nil {
 		if len(source.Inputs.Params) > 0 && len(source.Params) > 0 {
@@ -119,9 +124,14 @@ func (sink *TaskRun) ConvertFrom(ctx context.Context, obj apis.Convertible) erro
 	switch source := obj.(type) {
 	case *v1beta1.TaskRun:
 		sink.ObjectMeta = source.ObjectMeta
+		stash, err := popDeprecatedFieldsAnnotation(&sink.ObjectMeta)
+		if err != nil {
+			return err
+		}
 		if err := sink.Spec.ConvertFrom(ctx, &source.Spec); err != nil {
 			return err
 		}
+		sink.Spec.restoreDeprecatedFields("spec", stash)
 		sink.Status = source.Status
 		return nil
 	default:
@@ -304,7 +304,6 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 	tests := []struct {
 		name     string
 		in       *TaskRun
-		want     *TaskRun
 		badField string
 	}{{
 		name: "inputs params",
@@ -323,19 +322,6 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &TaskRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskRunSpec{
-				Params: []Param{{
-					Name:  "p2",
-					Value: v1beta1.ArrayOrString{StringVal: "bar"}},
-				},
-			},
-		},
 	}, {
 		name: "inputs resource",
 		in: &TaskRun{
@@ -356,24 +342,6 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &TaskRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskRunSpec{
-				Resources: &v1beta1.TaskRunResources{
-					Inputs: []v1beta1.TaskResourceBinding{{
-						PipelineResourceBinding: v1beta1.PipelineResourceBinding{
-							Name:        "i1",
-							ResourceRef: &v1beta1.PipelineResourceRef{Name: "r1"},
-						},
-						Paths: []string{"foo", "bar"},
-					}},
-				},
-			},
-		},
 	}, {
 		name: "outputs resource",
 		in: &TaskRun{
@@ -394,24 +362,6 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 				},
 			},
 		},
-		want: &TaskRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:       "foo",
-				Namespace:  "bar",
-				Generation: 1,
-			},
-			Spec: TaskRunSpec{
-				Resources: &v1beta1.TaskRunResources{
-					Outputs: []v1beta1.TaskResourceBinding{{
-						PipelineResourceBinding: v1beta1.PipelineResourceBinding{
-							Name:        "o1",
-							ResourceRef: &v1beta1.PipelineResourceRef{Name: "r1"},
-						},
-						Paths: []string{"foo", "bar"},
-					}},
-				},
-			},
-		},
 	}}
 	for _, test := range tests {
 		for _, version := range versions {
@@ -432,7 +382,7 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 					t.Errorf("ConvertFrom() = %v", err)
 				}
 				t.Logf("ConvertFrom() = %#v", got)
-				if d := cmp.Diff(test.want, got); d != "" {
+				if d := cmp.Diff(test.in, got); d != "" {
 					t.Errorf("roundtrip %s", diff.PrintWantGot(d))
 				}
 			})
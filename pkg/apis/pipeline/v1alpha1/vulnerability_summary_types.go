@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VulnerabilitySummary holds the vulnerabilities Trivy found in the images
+// of the Steps of a completed TaskRun. It is owned by the TaskRun.
+//
+// +k8s:openapi-gen=true
+type VulnerabilitySummary struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec VulnerabilitySummarySpec `json:"spec,omitempty"`
+}
+
+// VulnerabilitySummarySpec holds the result of the scan of each image.
+type VulnerabilitySummarySpec struct {
+	// TaskRunName is the name of the TaskRun whose Step images were scanned.
+	TaskRunName string `json:"taskRunName"`
+	// Images holds the vulnerabilities found in each image.
+	// +optional
+	Images []ImageVulnerabilities `json:"images,omitempty"`
+}
+
+// ImageVulnerabilities counts the vulnerabilities of an image by severity.
+type ImageVulnerabilities struct {
+	// Image is the image reference of the Steps, e.g. ubuntu:20.04.
+	Image string `json:"image"`
+	// ImageID is the image the Steps ran, by digest.
+	// +optional
+	ImageID string `json:"imageID,omitempty"`
+	// +optional
+	Critical int `json:"critical,omitempty"`
+	// +optional
+	High int `json:"high,omitempty"`
+	// +optional
+	Medium int `json:"medium,omitempty"`
+	// +optional
+	Low int `json:"low,omitempty"`
+	// +optional
+	Unknown int `json:"unknown,omitempty"`
+	// CriticalVulnerabilities holds the IDs of the critical vulnerabilities,
+	// e.g. CVE-2021-3156.
+	// +optional
+	CriticalVulnerabilities []string `json:"criticalVulnerabilities,omitempty"`
+	// Error is set if the image couldn't be scanned.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VulnerabilitySummaryList contains a list of VulnerabilitySummary
+type VulnerabilitySummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VulnerabilitySummary `json:"items"`
+}
@@ -26,4 +26,5 @@ var _ apis.Defaultable = (*ClusterTask)(nil)
 
 func (t *ClusterTask) SetDefaults(ctx context.Context) {
 	t.Spec.SetDefaults(ctx)
+	setTagLabels(&t.ObjectMeta, t.Spec.Tags)
 }
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/jsonschema"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// DefaultInputValidationKey is the ConfigMap key the JSON Schema of a Task is
+// read from when its configMapRef doesn't name one.
+const DefaultInputValidationKey = "schema.json"
+
+// InputValidation declares how the params of the TaskRuns of a Task are
+// validated when they are admitted.
+type InputValidation struct {
+	// ConfigMapRef selects the ConfigMap, in the namespace of the TaskRun,
+	// holding the JSON Schema the params must match.
+	ConfigMapRef *InputValidationConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+// InputValidationConfigMapRef selects a key of a ConfigMap.
+type InputValidationConfigMapRef struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Key holding the JSON Schema. Defaults to "schema.json".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// Validate checks that the ConfigMap holding the schema is named.
+func (iv *InputValidation) Validate(ctx context.Context) *apis.FieldError {
+	if iv.ConfigMapRef == nil {
+		return apis.ErrMissingField("configMapRef")
+	}
+	if iv.ConfigMapRef.Name == "" {
+		return apis.ErrMissingField("configMapRef.name")
+	}
+	return nil
+}
+
+// InputValidationLookup fetches the objects needed to validate the params of
+// a TaskRun against the JSON Schema of its Task.
+type InputValidationLookup interface {
+	// GetTaskSpec returns the spec of the referenced Task or ClusterTask, or
+	// nil if it doesn't exist (yet).
+	GetTaskSpec(ctx context.Context, namespace string, ref *TaskRef) (*TaskSpec, error)
+	// GetConfigMap returns the named ConfigMap.
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+}
+
+type inputValidationLookupKey struct{}
+
+// WithInputValidationLookup enables the validation of the params of TaskRuns
+// against the JSON Schema of their Task, using l to fetch the Task and schema.
+func WithInputValidationLookup(ctx context.Context, l InputValidationLookup) context.Context {
+	return context.WithValue(ctx, inputValidationLookupKey{}, l)
+}
+
+func getInputValidationLookup(ctx context.Context) InputValidationLookup {
+	l, _ := ctx.Value(inputValidationLookupKey{}).(InputValidationLookup)
+	return l
+}
+
+// validateInputs validates the params of tr against the JSON Schema of its
+// Task, if the Task declares one. Params the TaskRun doesn't set are
+// validated with their default value.
+func (tr *TaskRun) validateInputs(ctx context.Context, l InputValidationLookup) *apis.FieldError {
+	ts := tr.Spec.TaskSpec
+	if ts == nil {
+		var err error
+		if ts, err = l.GetTaskSpec(ctx, tr.Namespace, tr.Spec.TaskRef); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("failed to get Task %q to validate params: %v", tr.Spec.TaskRef.Name, err),
+				Paths:   []string{"spec.taskRef"},
+			}
+		}
+	}
+	if ts == nil || ts.InputValidation == nil || ts.InputValidation.ConfigMapRef == nil {
+		return nil
+	}
+
+	ref := ts.InputValidation.ConfigMapRef
+	key := ref.Key
+	if key == "" {
+		key = DefaultInputValidationKey
+	}
+	cm, err := l.GetConfigMap(ctx, tr.Namespace, ref.Name)
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("failed to get ConfigMap %q holding the JSON Schema of the params: %v", ref.Name, err),
+			Paths:   []string{"spec.params"},
+		}
+	}
+	data, ok := cm.Data[key]
+	if !ok {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("ConfigMap %q has no key %q holding the JSON Schema of the params", ref.Name, key),
+			Paths:   []string{"spec.params"},
+		}
+	}
+	schema, err := jsonschema.Parse([]byte(data))
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("ConfigMap %q key %q: %v", ref.Name, key, err),
+			Paths:   []string{"spec.params"},
+		}
+	}
+
+	var errs *apis.FieldError
+	for _, e := range schema.Validate(paramsObject(ts.Params, tr.Spec.Params)) {
+		path := "spec.params"
+		if e.Path != "" {
+			path = "spec.params." + e.Path
+		}
+		errs = errs.Also(apis.ErrInvalidValue(e.Message, path))
+	}
+	return errs
+}
+
+// paramsObject returns the values of params, falling back to the defaults of
+// specs, as a JSON object.
+func paramsObject(specs []ParamSpec, params []Param) map[string]interface{} {
+	obj := map[string]interface{}{}
+	for _, ps := range specs {
+		if ps.Default != nil {
+			obj[ps.Name] = paramValue(*ps.Default)
+		}
+	}
+	for _, p := range params {
+		obj[p.Name] = paramValue(p.Value)
+	}
+	return obj
+}
+
+func paramValue(v ArrayOrString) interface{} {
+	if v.Type != ParamTypeArray {
+		return v.StringVal
+	}
+	items := make([]interface{}, len(v.ArrayVal))
+	for i, s := range v.ArrayVal {
+		items[i] = s
+	}
+	return items
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const deploySchema = `{
+  "type": "object",
+  "required": ["environment"],
+  "properties": {
+    "environment": {"type": "string", "enum": ["staging", "production"]},
+    "replicas": {"type": "string", "pattern": "^[0-9]+$"},
+    "regions": {"type": "array", "minItems": 1}
+  }
+}`
+
+type fakeInputValidationLookup struct {
+	tasks      map[string]*v1beta1.TaskSpec
+	configMaps map[string]*corev1.ConfigMap
+}
+
+func (l *fakeInputValidationLookup) GetTaskSpec(_ context.Context, _ string, ref *v1beta1.TaskRef) (*v1beta1.TaskSpec, error) {
+	return l.tasks[ref.Name], nil
+}
+
+func (l *fakeInputValidationLookup) GetConfigMap(_ context.Context, _, name string) (*corev1.ConfigMap, error) {
+	if cm, ok := l.configMaps[name]; ok {
+		return cm, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestTaskRun_ValidateInputs(t *testing.T) {
+	deploy := &v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{
+			{Name: "environment", Type: v1beta1.ParamTypeString},
+			{Name: "replicas", Type: v1beta1.ParamTypeString, Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "1"}},
+			{Name: "regions", Type: v1beta1.ParamTypeArray},
+		},
+		Steps: []v1beta1.Step{{Container: corev1.Container{Name: "deploy", Image: "myimage"}}},
+		InputValidation: &v1beta1.InputValidation{
+			ConfigMapRef: &v1beta1.InputValidationConfigMapRef{Name: "schemas", Key: "deploy.json"},
+		},
+	}
+	lookup := &fakeInputValidationLookup{
+		tasks: map[string]*v1beta1.TaskSpec{"deploy": deploy},
+		configMaps: map[string]*corev1.ConfigMap{
+			"schemas": {Data: map[string]string{"deploy.json": deploySchema, "broken.json": `{"oneOf": []}`}},
+		},
+	}
+	taskRun := func(params ...v1beta1.Param) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy-run", Namespace: "foo"},
+			Spec: v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "deploy"},
+				Params:  params,
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		tr   *v1beta1.TaskRun
+		want *apis.FieldError
+	}{{
+		name: "valid params",
+		tr: taskRun(
+			v1beta1.Param{Name: "environment", Value: v1beta1.NewArrayOrString("staging")},
+			v1beta1.Param{Name: "regions", Value: v1beta1.NewArrayOrString("eu", "us")},
+		),
+	}, {
+		name: "invalid params",
+		tr: taskRun(
+			v1beta1.Param{Name: "environment", Value: v1beta1.NewArrayOrString("dev")},
+			v1beta1.Param{Name: "replicas", Value: v1beta1.NewArrayOrString("two")},
+		),
+		want: apis.ErrInvalidValue(`must be one of ["staging","production"]`, "spec.params.environment").
+			Also(apis.ErrInvalidValue(`must match pattern "^[0-9]+$"`, "spec.params.replicas")),
+	}, {
+		name: "missing required param",
+		tr:   taskRun(),
+		want: apis.ErrInvalidValue("is required", "spec.params.environment"),
+	}, {
+		name: "task not found",
+		tr: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "missing"}},
+		},
+	}, {
+		name: "embedded task spec",
+		tr: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+			Spec: v1beta1.TaskRunSpec{
+				TaskSpec: deploy,
+				Params:   []v1beta1.Param{{Name: "environment", Value: v1beta1.NewArrayOrString("dev")}},
+			},
+		},
+		want: apis.ErrInvalidValue(`must be one of ["staging","production"]`, "spec.params.environment"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+			ctx = apis.WithinCreate(v1beta1.WithInputValidationLookup(ctx, lookup))
+			err := tc.tr.Validate(ctx)
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("TaskRun.Validate() = %v", err)
+				}
+				return
+			}
+			if d := cmp.Diff(tc.want.Error(), err.Error()); d != "" {
+				t.Errorf("TaskRun.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+
+	t.Run("not validated on update", func(t *testing.T) {
+		tr := taskRun()
+		ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+		ctx = apis.WithinUpdate(v1beta1.WithInputValidationLookup(ctx, lookup), tr)
+		if err := tr.Validate(ctx); err != nil {
+			t.Errorf("TaskRun.Validate() = %v", err)
+		}
+	})
+}
+
+func TestTaskRun_ValidateInputs_SchemaErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ref  *v1beta1.InputValidationConfigMapRef
+		want string
+	}{{
+		name: "missing configmap",
+		ref:  &v1beta1.InputValidationConfigMapRef{Name: "missing"},
+		want: `failed to get ConfigMap "missing" holding the JSON Schema of the params: not found: spec.params`,
+	}, {
+		name: "missing key",
+		ref:  &v1beta1.InputValidationConfigMapRef{Name: "schemas"},
+		want: `ConfigMap "schemas" has no key "schema.json" holding the JSON Schema of the params: spec.params`,
+	}, {
+		name: "unsupported schema",
+		ref:  &v1beta1.InputValidationConfigMapRef{Name: "schemas", Key: "broken.json"},
+		want: `ConfigMap "schemas" key "broken.json": invalid JSON Schema: json: unknown field "oneOf": spec.params`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			lookup := &fakeInputValidationLookup{
+				configMaps: map[string]*corev1.ConfigMap{
+					"schemas": {Data: map[string]string{"broken.json": `{"oneOf": []}`}},
+				},
+			}
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+				Spec: v1beta1.TaskRunSpec{
+					TaskSpec: &v1beta1.TaskSpec{
+						Steps:           []v1beta1.Step{{Container: corev1.Container{Name: "step", Image: "myimage"}}},
+						InputValidation: &v1beta1.InputValidation{ConfigMapRef: tc.ref},
+					},
+				},
+			}
+			ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+			ctx = apis.WithinCreate(v1beta1.WithInputValidationLookup(ctx, lookup))
+			if d := cmp.Diff(tc.want, tr.Validate(ctx).Error()); d != "" {
+				t.Errorf("TaskRun.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestInputValidation_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		iv   *v1beta1.InputValidation
+		want *apis.FieldError
+	}{{
+		name: "valid",
+		iv:   &v1beta1.InputValidation{ConfigMapRef: &v1beta1.InputValidationConfigMapRef{Name: "schemas"}},
+	}, {
+		name: "missing configMapRef",
+		iv:   &v1beta1.InputValidation{},
+		want: apis.ErrMissingField("configMapRef"),
+	}, {
+		name: "missing name",
+		iv:   &v1beta1.InputValidation{ConfigMapRef: &v1beta1.InputValidationConfigMapRef{Key: "schema.json"}},
+		want: apis.ErrMissingField("configMapRef.name"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want.Error(), tc.iv.Validate(context.Background()).Error()); d != "" {
+				t.Errorf("InputValidation.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -84,8 +84,8 @@ func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, e
 			merged.Args = []string{}
 		}
 
-		// Pass through original step Script, for later conversion.
-		steps[i] = Step{Container: *merged, Script: s.Script}
+		// Pass through original step Script, ExternalSecrets, Hermetic, GPU and SeccompProfile, for later conversion.
+		steps[i] = Step{Container: *merged, Script: s.Script, EnvFromExternalSecrets: s.EnvFromExternalSecrets, Hermetic: s.Hermetic, GPU: s.GPU, SeccompProfile: s.SeccompProfile}
 	}
 	return steps, nil
 }
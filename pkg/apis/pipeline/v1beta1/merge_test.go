@@ -29,6 +29,8 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 	resourceQuantityCmp := cmp.Comparer(func(x, y resource.Quantity) bool {
 		return x.Cmp(y) == 0
 	})
+	trueB, falseB := true, false
+	userA, userRoot := int64(1000), int64(0)
 
 	for _, tc := range []struct {
 		name     string
@@ -101,6 +103,78 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 				Value: "NEW_VALUE",
 			}},
 		}}},
+	}, {
+		name: "security-context-from-template",
+		template: &corev1.Container{
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot: &trueB,
+				RunAsUser:    &userA,
+			},
+		},
+		steps: []Step{{Container: corev1.Container{
+			Image: "some-image",
+		}}, {Container: corev1.Container{
+			Image:           "some-other-image",
+			SecurityContext: &corev1.SecurityContext{},
+		}}},
+		expected: []Step{{Container: corev1.Container{
+			Image: "some-image",
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot: &trueB,
+				RunAsUser:    &userA,
+			},
+		}}, {Container: corev1.Container{
+			Image: "some-other-image",
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot: &trueB,
+				RunAsUser:    &userA,
+			},
+		}}},
+	}, {
+		name: "security-context-overridden-by-step",
+		template: &corev1.Container{
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot: &trueB,
+				RunAsUser:    &userA,
+				Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		},
+		steps: []Step{{Container: corev1.Container{
+			Image: "some-image",
+			SecurityContext: &corev1.SecurityContext{
+				// Explicit zero values of pointer fields must win over the template.
+				RunAsNonRoot: &falseB,
+				RunAsUser:    &userRoot,
+			},
+		}}},
+		expected: []Step{{Container: corev1.Container{
+			Image: "some-image",
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot: &falseB,
+				RunAsUser:    &userRoot,
+				Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		}}},
+	}, {
+		name: "env-from-external-secrets-passed-through",
+		template: &corev1.Container{
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+			}},
+		},
+		steps: []Step{{
+			Container:              corev1.Container{Image: "some-image"},
+			EnvFromExternalSecrets: []ExternalSecretEnvSource{{Name: "credentials"}},
+		}},
+		expected: []Step{{
+			Container: corev1.Container{
+				Image: "some-image",
+				EnvFrom: []corev1.EnvFromSource{{
+					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+				}},
+			},
+			EnvFromExternalSecrets: []ExternalSecretEnvSource{{Name: "credentials"}},
+		}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			result, err := MergeStepsWithStepTemplate(tc.template, tc.steps)
@@ -66,6 +66,10 @@ type ResourceParam = resource.ResourceParam
 type Param struct {
 	Name  string        `json:"name"`
 	Value ArrayOrString `json:"value"`
+	// Append appends the array Value to the default of the parameter,
+	// instead of replacing it.
+	// +optional
+	Append bool `json:"append,omitempty"`
 }
 
 // ParamType indicates the type of an input parameter;
@@ -17,7 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -127,10 +127,30 @@ type PipelineTask struct {
 	// +optional
 	Conditions []PipelineTaskCondition `json:"conditions,omitempty"`
 
+	// WhenExpressions is a list of when expressions that need to be true for the task to run
+	// +optional
+	WhenExpressions WhenExpressions `json:"when,omitempty"`
+
+	// WhenScope is what is skipped when the WhenExpressions evaluate to false,
+	// Branch (the default) or Task
+	// +optional
+	WhenScope WhenScope `json:"whenScope,omitempty"`
+
 	// Retries represents how many times this task should be retried in case of task failure: ConditionSucceeded set to False
 	// +optional
 	Retries int `json:"retries,omitempty"`
 
+	// ContinueOnFailure marks the failure of this task as non-fatal: once its retries are
+	// exhausted, the tasks depending on it are scheduled as if it had succeeded, and its
+	// failure doesn't fail the PipelineRun.
+	// +optional
+	ContinueOnFailure bool `json:"continueOnFailure,omitempty"`
+
+	// FailurePolicy of a final task set to "ignore" records its failure without
+	// failing the PipelineRun, which only fails if one of the tasks did.
+	// +optional
+	FailurePolicy PipelineTaskFailurePolicy `json:"failurePolicy,omitempty"`
+
 	// RunAfter is the list of PipelineTask names that should be executed before
 	// this Task executes. (Used to force a specific ordering in graph execution.)
 	// +optional
@@ -156,6 +176,14 @@ type PipelineTask struct {
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+// PipelineTaskFailurePolicy is the policy applied to the PipelineRun when a final
+// task fails.
+type PipelineTaskFailurePolicy string
+
+// PipelineTaskFailurePolicyIgnore records the failure of a final task without
+// failing the PipelineRun.
+const PipelineTaskFailurePolicyIgnore PipelineTaskFailurePolicy = "ignore"
+
 func (pt *PipelineTask) TaskSpecMetadata() PipelineTaskMetadata {
 	return pt.TaskSpec.Metadata
 }
@@ -166,40 +194,73 @@ func (pt PipelineTask) HashKey() string {
 
 func (pt PipelineTask) Deps() []string {
 	deps := []string{}
-	deps = append(deps, pt.RunAfter...)
+	for _, d := range pt.Dependencies() {
+		deps = append(deps, d.Task)
+	}
+	return deps
+}
+
+// Dependencies returns the PipelineTasks pt depends on, with the reason it depends on them.
+func (pt PipelineTask) Dependencies() []dag.Dependency {
+	deps := []dag.Dependency{}
+	add := func(kind dag.DependencyKind, tasks ...string) {
+		for _, t := range tasks {
+			deps = append(deps, dag.Dependency{Task: t, Kind: kind})
+		}
+	}
+	addResultRefs := func(kind dag.DependencyKind, expressions []string) {
+		for _, resultRef := range NewResultRefs(expressions) {
+			add(kind, resultRef.PipelineTask)
+		}
+	}
+	add(dag.RunAfterDependency, pt.RunAfter...)
 	if pt.Resources != nil {
 		for _, rd := range pt.Resources.Inputs {
-			deps = append(deps, rd.From...)
+			add(dag.FromDependency, rd.From...)
 		}
 	}
 	// Add any dependents from conditional resources.
 	for _, cond := range pt.Conditions {
 		for _, rd := range cond.Resources {
-			deps = append(deps, rd.From...)
+			add(dag.FromDependency, rd.From...)
 		}
 		for _, param := range cond.Params {
-			expressions, ok := GetVarSubstitutionExpressionsForParam(param)
-			if ok {
-				resultRefs := NewResultRefs(expressions)
-				for _, resultRef := range resultRefs {
-					deps = append(deps, resultRef.PipelineTask)
-				}
+			if expressions, ok := GetVarSubstitutionExpressionsForParam(param); ok {
+				addResultRefs(dag.ResultDependency, expressions)
 			}
 		}
 	}
 	// Add any dependents from task results
 	for _, param := range pt.Params {
-		expressions, ok := GetVarSubstitutionExpressionsForParam(param)
-		if ok {
-			resultRefs := NewResultRefs(expressions)
-			for _, resultRef := range resultRefs {
-				deps = append(deps, resultRef.PipelineTask)
-			}
+		if expressions, ok := GetVarSubstitutionExpressionsForParam(param); ok {
+			addResultRefs(dag.ResultDependency, expressions)
 		}
 	}
+	// Add any dependents from task results referenced by env values of embedded steps
+	if expressions, ok := GetVarSubstitutionExpressionsForStepEnvs(pt.EmbeddedSteps()); ok {
+		addResultRefs(dag.ResultDependency, expressions)
+	}
+	// Add any dependents from task results referenced by when expressions
+	for _, v := range pt.WhenExpressions.getVariables() {
+		addResultRefs(dag.WhenDependency, validateString(v))
+	}
 	return deps
 }
 
+// EmbeddedSteps returns the steps of the embedded TaskSpec, if any.
+func (pt PipelineTask) EmbeddedSteps() []Step {
+	if pt.TaskSpec == nil || pt.TaskSpec.TaskSpec == nil {
+		return nil
+	}
+	return pt.TaskSpec.Steps
+}
+
+// IsCustomTask returns true if pt references a custom task, which is run by
+// creating a Run instead of a TaskRun.
+func (pt PipelineTask) IsCustomTask() bool {
+	return pt.TaskRef != nil && pt.TaskRef.APIVersion != ""
+}
+
 type PipelineTaskList []PipelineTask
 
 func (l PipelineTaskList) Items() []dag.Task {
@@ -210,6 +271,28 @@ func (l PipelineTaskList) Items() []dag.Task {
 	return tasks
 }
 
+// FinalTaskList is the list of the final tasks of a Pipeline. The final tasks are all run once the
+// tasks of the Pipeline are done, so the results they use don't make them depend on each other.
+type FinalTaskList []PipelineTask
+
+func (l FinalTaskList) Items() []dag.Task {
+	tasks := []dag.Task{}
+	for _, t := range l {
+		tasks = append(tasks, finalTask{t})
+	}
+	return tasks
+}
+
+// finalTask is a final task of a Pipeline, which doesn't depend on any other PipelineTask of the graph
+// of the final tasks.
+type finalTask struct {
+	PipelineTask
+}
+
+func (finalTask) Deps() []string {
+	return nil
+}
+
 // PipelineTaskParam is used to provide arbitrary string parameters to a Task.
 type PipelineTaskParam struct {
 	Name  string `json:"name"`
@@ -18,17 +18,21 @@ package v1beta1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	"github.com/tektoncd/pipeline/pkg/list"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/substitution"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
 )
 
 var _ apis.Validatable = (*Pipeline)(nil)
@@ -39,7 +43,14 @@ func (p *Pipeline) Validate(ctx context.Context) *apis.FieldError {
 	if err := validate.ObjectMetadata(p.GetObjectMeta()); err != nil {
 		return err.ViaField("metadata")
 	}
-	return p.Spec.Validate(ctx)
+	if err := p.Spec.Validate(ctx); err != nil {
+		return err
+	}
+	// Unused params are surfaced as warnings rather than rejecting the Pipeline.
+	for _, warning := range p.Spec.UnusedParamWarnings(ctx) {
+		logging.FromContext(ctx).Warnf("Pipeline %s/%s: %s", p.Namespace, p.Name, warning)
+	}
+	return nil
 }
 
 // validateDeclaredResources ensures that the specified resources have unique names and
@@ -141,9 +152,14 @@ func validateFrom(tasks []PipelineTask) *apis.FieldError {
 
 // validateGraph ensures the Pipeline's dependency Graph (DAG) make sense: that there is no dependency
 // cycle or that they rely on values from Tasks that ran previously, and that the PipelineResource
-// is actually an output of the Task it should come from.
+// is actually an output of the Task it should come from. A dependency cycle is reported by
+// itself, naming the Tasks forming it.
 func validateGraph(tasks []PipelineTask) error {
 	if _, err := dag.Build(PipelineTaskList(tasks)); err != nil {
+		var cycle *dag.CycleError
+		if errors.As(err, &cycle) {
+			return cycle
+		}
 		return err
 	}
 	return nil
@@ -181,6 +197,14 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrInvalidValue(err.Error(), "spec.tasks.params.value")
 	}
 
+	if err := validateStepEnvResults(ps.Tasks); err != nil {
+		return apis.ErrInvalidValue(err.Error(), "spec.tasks.taskSpec.steps.env.value")
+	}
+
+	if err := validateWhenExpressionsResults(ps.Tasks); err != nil {
+		return apis.ErrInvalidValue(err.Error(), "spec.tasks.when")
+	}
+
 	// The parameter variables should be valid
 	if err := validatePipelineParameterVariables(ps.Tasks, ps.Params); err != nil {
 		return err
@@ -195,6 +219,14 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 	}
 
 	// Validate the pipeline's workspaces.
+	for i, ws := range ps.Workspaces {
+		if ws.Optional {
+			if err := ValidateEnabledAPIFields(ctx, "optional workspaces", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{fmt.Sprintf("spec.workspaces[%d].optional", i)}
+				return err
+			}
+		}
+	}
 	if err := validatePipelineWorkspaces(ps.Workspaces, ps.Tasks, ps.Finally); err != nil {
 		return err
 	}
@@ -208,7 +240,11 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
-	if err := validateFinalTasks(ps.Finally); err != nil {
+	if err := validateFinalTasks(ps.Tasks, ps.Finally); err != nil {
+		return err
+	}
+
+	if err := validateExecutionStatusVariables(ps.Tasks, ps.Results); err != nil {
 		return err
 	}
 
@@ -225,6 +261,9 @@ func validatePipelineTasks(ctx context.Context, tasks []PipelineTask, finalTasks
 		if err = validatePipelineTaskName(ctx, "spec.tasks", i, t, taskNames); err != nil {
 			return err
 		}
+		if t.FailurePolicy != "" {
+			return apis.ErrInvalidValue(fmt.Sprintf("failurePolicy is only allowed under spec.finally, pipeline task %s has failurePolicy specified", t.Name), fmt.Sprintf("spec.tasks[%d].failurePolicy", i))
+		}
 	}
 	for i, t := range finalTasks {
 		if err = validatePipelineTaskName(ctx, "spec.finally", i, t, taskNames); err != nil {
@@ -243,12 +282,21 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 				"For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
 		}
 	}
+	if t.TaskRef != nil && (t.TaskRef.APIVersion != "" || (t.TaskRef.Kind != "" && t.TaskRef.Kind != NamespacedTaskKind && t.TaskRef.Kind != ClusterTaskKind)) {
+		if err := validateCustomTask(ctx, fmt.Sprintf(prefix+"[%d]", i), t); err != nil {
+			return err
+		}
+		if _, ok := taskNames[t.Name]; ok {
+			return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].name", i))
+		}
+		taskNames[t.Name] = struct{}{}
+	}
 	// can't have both taskRef and taskSpec at the same time
-	if (t.TaskRef != nil && t.TaskRef.Name != "") && t.TaskSpec != nil {
+	if (t.TaskRef != nil && (t.TaskRef.Name != "" || t.IsCustomTask())) && t.TaskSpec != nil {
 		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i))
 	}
 	// Check that one of TaskRef and TaskSpec is present
-	if (t.TaskRef == nil || (t.TaskRef != nil && t.TaskRef.Name == "")) && t.TaskSpec == nil {
+	if (t.TaskRef == nil || (t.TaskRef != nil && t.TaskRef.Name == "" && !t.IsCustomTask())) && t.TaskSpec == nil {
 		return apis.ErrMissingOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i))
 	}
 	// Validate TaskSpec if it's present
@@ -257,16 +305,72 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 			return err
 		}
 	}
-	if t.TaskRef != nil && t.TaskRef.Name != "" {
+	if len(t.WhenExpressions) > 0 {
+		if err := ValidateEnabledAPIFields(ctx, "when expressions", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{fmt.Sprintf(prefix+"[%d].when", i)}
+			return err
+		}
+		if err := t.WhenExpressions.validate(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].when", i))
+		}
+	}
+	for j, ws := range t.Workspaces {
+		if ws.Condition == "" {
+			continue
+		}
+		if err := ValidateEnabledAPIFields(ctx, "workspace binding conditions", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{fmt.Sprintf(prefix+"[%d].workspaces[%d].condition", i, j)}
+			return err
+		}
+		if err := ws.validateCondition(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].workspaces[%d]", i, j))
+		}
+	}
+	if err := validateParamsAppend(ctx, t.Params); err != nil {
+		return err.ViaField(fmt.Sprintf(prefix+"[%d]", i))
+	}
+	if t.WhenScope != "" {
+		switch {
+		case t.WhenScope != WhenScopeBranch && t.WhenScope != WhenScopeTask:
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s or %s", t.WhenScope, WhenScopeBranch, WhenScopeTask), fmt.Sprintf(prefix+"[%d].whenScope", i))
+		case len(t.WhenExpressions) == 0:
+			return apis.ErrGeneric("whenScope requires when expressions", fmt.Sprintf(prefix+"[%d].whenScope", i))
+		}
+	}
+	if t.FailurePolicy != "" {
+		if err := ValidateEnabledAPIFields(ctx, "failurePolicy", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{fmt.Sprintf(prefix+"[%d].failurePolicy", i)}
+			return err
+		}
+		if t.FailurePolicy != PipelineTaskFailurePolicyIgnore {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s", t.FailurePolicy, PipelineTaskFailurePolicyIgnore), fmt.Sprintf(prefix+"[%d].failurePolicy", i))
+		}
+	}
+	if t.TaskRef != nil && t.TaskRef.Name != "" && !t.IsCustomTask() {
 		// Task names are appended to the container name, which must exist and
 		// must be a valid k8s name
 		if errSlice := validation.IsQualifiedName(t.Name); len(errSlice) != 0 {
 			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf(prefix+"[%d].name", i))
 		}
-		// TaskRef name must be a valid k8s name
-		if errSlice := validation.IsQualifiedName(t.TaskRef.Name); len(errSlice) != 0 {
+		// TaskRef name must be a valid k8s name, which is only known once the
+		// params it references are substituted
+		if len(substitution.ExtractVariableNames(t.TaskRef.Name, "params")) > 0 {
+			if err := ValidateEnabledAPIFields(ctx, "params in taskRef.name", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{fmt.Sprintf(prefix+"[%d].taskRef.name", i)}
+				return err
+			}
+		} else if errSlice := validation.IsQualifiedName(t.TaskRef.Name); len(errSlice) != 0 {
 			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf(prefix+"[%d].taskRef.name", i))
 		}
+		if t.TaskRef.Bundle != "" {
+			if err := ValidateEnabledAPIFields(ctx, "bundles", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{fmt.Sprintf(prefix+"[%d].taskRef.bundle", i)}
+				return err
+			}
+			if _, err := name.ParseReference(t.TaskRef.Bundle); err != nil {
+				return apis.ErrInvalidValue(fmt.Sprintf("invalid bundle reference: %v", err), fmt.Sprintf(prefix+"[%d].taskRef.bundle", i))
+			}
+		}
 		if _, ok := taskNames[t.Name]; ok {
 			return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].name", i))
 		}
@@ -275,6 +379,35 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 	return nil
 }
 
+// validateCustomTask validates the PipelineTask t at path, whose taskRef references
+// a custom task. Custom tasks are run by their own controllers through Runs, which
+// only get the params of t, so t can't use the features which need a TaskRun.
+func validateCustomTask(ctx context.Context, path string, t PipelineTask) *apis.FieldError {
+	if err := ValidateEnabledAPIFields(ctx, "custom tasks", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{path + ".taskRef"}
+		return err
+	}
+	if t.TaskRef.APIVersion == "" {
+		return apis.ErrMissingField(path + ".taskRef.apiVersion")
+	}
+	if t.TaskRef.Kind == "" {
+		return apis.ErrMissingField(path + ".taskRef.kind")
+	}
+	if t.TaskRef.Bundle != "" {
+		return apis.ErrDisallowedFields(path + ".taskRef.bundle")
+	}
+	if t.Resources != nil {
+		return apis.ErrDisallowedFields(path + ".resources")
+	}
+	if len(t.Conditions) > 0 {
+		return apis.ErrDisallowedFields(path + ".conditions")
+	}
+	if len(t.Workspaces) > 0 {
+		return apis.ErrDisallowedFields(path + ".workspaces")
+	}
+	return nil
+}
+
 // validatePipelineWorkspaces validates the specified workspaces, ensuring having unique name without any empty string,
 // and validates that all the referenced workspaces (by pipeline tasks) are specified in the pipeline
 func validatePipelineWorkspaces(wss []PipelineWorkspaceDeclaration, pts []PipelineTask, finalTasks []PipelineTask) *apis.FieldError {
@@ -312,6 +445,23 @@ func validatePipelineWorkspaces(wss []PipelineWorkspaceDeclaration, pts []Pipeli
 			}
 		}
 	}
+
+	// The $(workspaces.<name>.bound) variables used in when expressions should reference
+	// workspaces declared in the Pipeline.
+	for i, pt := range pts {
+		for _, value := range pt.WhenExpressions.getVariables() {
+			if err := substitution.ValidateVariable(fmt.Sprintf("tasks[%d].when", i), value, "workspaces", "when expression", "spec", wsTable); err != nil {
+				return err
+			}
+		}
+	}
+	for i, t := range finalTasks {
+		for _, value := range t.WhenExpressions.getVariables() {
+			if err := substitution.ValidateVariable(fmt.Sprintf("finally[%d].when", i), value, "workspaces", "when expression", "spec", wsTable); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -361,6 +511,30 @@ func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec
 
 func validatePipelineVariables(tasks []PipelineTask, prefix string, paramNames sets.String, arrayParamNames sets.String) *apis.FieldError {
 	for _, task := range tasks {
+		for _, value := range task.WhenExpressions.getVariables() {
+			if err := validatePipelineVariable("when", value, prefix, paramNames); err != nil {
+				return err
+			}
+			if err := validatePipelineNoArrayReferenced("when", value, prefix, arrayParamNames); err != nil {
+				return err
+			}
+		}
+		for _, ws := range task.Workspaces {
+			if err := validatePipelineVariable(fmt.Sprintf("workspace[%s].condition", ws.Name), ws.Condition, prefix, paramNames); err != nil {
+				return err
+			}
+			if err := validatePipelineNoArrayReferenced(fmt.Sprintf("workspace[%s].condition", ws.Name), ws.Condition, prefix, arrayParamNames); err != nil {
+				return err
+			}
+		}
+		if task.TaskRef != nil {
+			if err := validatePipelineVariable("taskRef.name", task.TaskRef.Name, prefix, paramNames); err != nil {
+				return err
+			}
+			if err := validatePipelineNoArrayReferenced("taskRef.name", task.TaskRef.Name, prefix, arrayParamNames); err != nil {
+				return err
+			}
+		}
 		for _, param := range task.Params {
 			if param.Value.Type == ParamTypeString {
 				if err := validatePipelineVariable(fmt.Sprintf("param[%s]", param.Name), param.Value.StringVal, prefix, paramNames); err != nil {
@@ -396,6 +570,49 @@ func validatePipelineArraysIsolated(name, value, prefix string, vars sets.String
 	return substitution.ValidateVariableIsolated(name, value, prefix, "task parameter", "pipelinespec.params", vars)
 }
 
+// UnusedParamWarnings returns a warning for each param declared by the pipeline which isn't referenced
+// by the params of its tasks and their conditions, by their when expressions, by the names of the Tasks
+// they reference or by the pipeline results.
+// It returns nil unless the enable-unused-param-warnings feature flag is set.
+func (ps *PipelineSpec) UnusedParamWarnings(ctx context.Context) []string {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableUnusedParamWarnings {
+		return nil
+	}
+	used := sets.NewString()
+	addUsed := func(values ...string) {
+		for _, value := range values {
+			used.Insert(substitution.ExtractVariableNames(value, "params")...)
+		}
+	}
+	addUsedParams := func(params []Param) {
+		for _, param := range params {
+			addUsed(param.Value.StringVal)
+			addUsed(param.Value.ArrayVal...)
+		}
+	}
+	for _, task := range append(append([]PipelineTask{}, ps.Tasks...), ps.Finally...) {
+		addUsedParams(task.Params)
+		for _, condition := range task.Conditions {
+			addUsedParams(condition.Params)
+		}
+		addUsed(task.WhenExpressions.getVariables()...)
+		if task.TaskRef != nil {
+			addUsed(task.TaskRef.Name)
+		}
+	}
+	for _, result := range ps.Results {
+		addUsed(result.Value)
+	}
+
+	var warnings []string
+	for _, p := range ps.Params {
+		if !used.Has(p.Name) {
+			warnings = append(warnings, fmt.Sprintf("param %q is declared in spec.params but never used", p.Name))
+		}
+	}
+	return warnings
+}
+
 func validatePipelineContextVariables(tasks []PipelineTask) *apis.FieldError {
 	pipelineRunContextNames := sets.NewString().Insert(
 		"name",
@@ -446,6 +663,75 @@ func validateParamResults(tasks []PipelineTask) error {
 	return nil
 }
 
+// validateStepEnvResults ensures that task result variables used in the env values of
+// embedded steps are properly configured and reference results declared by the referenced task
+func validateStepEnvResults(tasks []PipelineTask) error {
+	declaredResults := declaredTaskResults(tasks)
+	for _, task := range tasks {
+		expressions, ok := GetVarSubstitutionExpressionsForStepEnvs(task.EmbeddedSteps())
+		if !ok {
+			continue
+		}
+		if err := validateDeclaredResultRefs(task.Name, expressions, declaredResults); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWhenExpressionsResults ensures that task result variables used in the input and the
+// values of when expressions are properly configured and reference results declared by the
+// referenced task
+func validateWhenExpressionsResults(tasks []PipelineTask) error {
+	declaredResults := declaredTaskResults(tasks)
+	for _, task := range tasks {
+		expressions, ok := GetVarSubstitutionExpressionsForWhenExpressions(task.WhenExpressions)
+		if !ok {
+			continue
+		}
+		if err := validateDeclaredResultRefs(task.Name, expressions, declaredResults); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declaredTaskResults returns the names of the results declared by the embedded specs of the
+// tasks, keyed by task.
+func declaredTaskResults(tasks []PipelineTask) map[string]sets.String {
+	declaredResults := map[string]sets.String{}
+	for _, task := range tasks {
+		if task.TaskSpec != nil && task.TaskSpec.TaskSpec != nil {
+			names := sets.NewString()
+			for _, r := range task.TaskSpec.Results {
+				names.Insert(r.Name)
+			}
+			declaredResults[task.Name] = names
+		}
+	}
+	return declaredResults
+}
+
+// validateDeclaredResultRefs ensures that the result references among the expressions used by
+// the task called name are well formed and reference results declared by the referenced task.
+func validateDeclaredResultRefs(name string, expressions []string, declaredResults map[string]sets.String) error {
+	if !LooksLikeContainsResultRefs(expressions) {
+		return nil
+	}
+	expressions = filter(expressions, looksLikeResultRef)
+	resultRefs := NewResultRefs(expressions)
+	if len(expressions) != len(resultRefs) {
+		return fmt.Errorf("expected all of the expressions %v to be result expressions but only %v were", expressions, resultRefs)
+	}
+	for _, resultRef := range resultRefs {
+		// Results of tasks referenced by taskRef can only be checked once the Task is resolved
+		if names, ok := declaredResults[resultRef.PipelineTask]; ok && !names.Has(resultRef.Result) {
+			return fmt.Errorf("task %q references result %q which is not declared by task %q", name, resultRef.Result, resultRef.PipelineTask)
+		}
+	}
+	return nil
+}
+
 func filter(arr []string, cond func(string) bool) []string {
 	result := []string{}
 	for i := range arr {
@@ -480,7 +766,7 @@ func validateTasksAndFinallySection(ps *PipelineSpec) *apis.FieldError {
 	return nil
 }
 
-func validateFinalTasks(finalTasks []PipelineTask) *apis.FieldError {
+func validateFinalTasks(tasks []PipelineTask, finalTasks []PipelineTask) *apis.FieldError {
 	for _, f := range finalTasks {
 		if len(f.RunAfter) != 0 {
 			return apis.ErrInvalidValue(fmt.Sprintf("no runAfter allowed under spec.finally, final task %s has runAfter specified", f.Name), "spec.finally")
@@ -488,9 +774,15 @@ func validateFinalTasks(finalTasks []PipelineTask) *apis.FieldError {
 		if len(f.Conditions) != 0 {
 			return apis.ErrInvalidValue(fmt.Sprintf("no conditions allowed under spec.finally, final task %s has conditions specified", f.Name), "spec.finally")
 		}
+		if f.WhenScope != "" {
+			return apis.ErrInvalidValue(fmt.Sprintf("no whenScope allowed under spec.finally, final task %s has whenScope specified", f.Name), "spec.finally")
+		}
+		if f.ContinueOnFailure {
+			return apis.ErrInvalidValue(fmt.Sprintf("no continueOnFailure allowed under spec.finally, final task %s has continueOnFailure specified", f.Name), "spec.finally")
+		}
 	}
 
-	if err := validateTaskResultReferenceNotUsed(finalTasks); err != nil {
+	if err := validateFinalTaskReferences(tasks, finalTasks); err != nil {
 		return err
 	}
 
@@ -501,17 +793,78 @@ func validateFinalTasks(finalTasks []PipelineTask) *apis.FieldError {
 	return nil
 }
 
-func validateTaskResultReferenceNotUsed(tasks []PipelineTask) *apis.FieldError {
+// validateFinalTaskReferences ensures that the final tasks only use the results and the execution
+// status of the tasks of the Pipeline, which are all done when the final tasks are run.
+func validateFinalTaskReferences(tasks []PipelineTask, finalTasks []PipelineTask) *apis.FieldError {
+	taskNames := sets.NewString()
 	for _, t := range tasks {
+		taskNames.Insert(t.Name)
+	}
+	for _, f := range finalTasks {
+		var expressions []string
+		for _, p := range f.Params {
+			if e, ok := GetVarSubstitutionExpressionsForParam(p); ok {
+				expressions = append(expressions, e...)
+			}
+		}
+		if e, ok := GetVarSubstitutionExpressionsForStepEnvs(f.EmbeddedSteps()); ok {
+			expressions = append(expressions, e...)
+		}
+		if e, ok := GetVarSubstitutionExpressionsForWhenExpressions(f.WhenExpressions); ok {
+			expressions = append(expressions, e...)
+		}
+		if LooksLikeContainsResultRefs(expressions) {
+			resultExpressions := filter(expressions, looksLikeResultRef)
+			resultRefs := NewResultRefs(resultExpressions)
+			if len(resultExpressions) != len(resultRefs) {
+				return apis.ErrInvalidValue(fmt.Sprintf("expected all of the expressions %v to be result expressions but only %v were", resultExpressions, resultRefs), "spec.finally.task.params")
+			}
+			for _, resultRef := range resultRefs {
+				if !taskNames.Has(resultRef.PipelineTask) {
+					return apis.ErrInvalidValue(fmt.Sprintf("final task %s uses result %s of %s which is not a task of spec.tasks", f.Name, resultRef.Result, resultRef.PipelineTask), "spec.finally.task.params")
+				}
+			}
+		}
+		for _, expression := range expressions {
+			if name, ok := pipelineTaskStatusRef(expression); ok && !taskNames.Has(name) {
+				return apis.ErrInvalidValue(fmt.Sprintf("final task %s uses the execution status of %s which is not a task of spec.tasks", f.Name, name), "spec.finally.task.params")
+			}
+		}
+	}
+	return nil
+}
+
+// validateExecutionStatusVariables ensures that the execution status of the pipeline tasks is only
+// used by the final tasks, as it is only known once the tasks of the Pipeline are done.
+func validateExecutionStatusVariables(tasks []PipelineTask, results []PipelineResult) *apis.FieldError {
+	for i, t := range tasks {
+		var expressions []string
 		for _, p := range t.Params {
-			expressions, ok := GetVarSubstitutionExpressionsForParam(p)
-			if ok {
-				if LooksLikeContainsResultRefs(expressions) {
-					return apis.ErrInvalidValue(fmt.Sprintf("no task result allowed under params,"+
-						"final task param %s has set task result as its value", p.Name), "spec.finally.task.params")
+			if e, ok := GetVarSubstitutionExpressionsForParam(p); ok {
+				expressions = append(expressions, e...)
+			}
+		}
+		for _, c := range t.Conditions {
+			for _, p := range c.Params {
+				if e, ok := GetVarSubstitutionExpressionsForParam(p); ok {
+					expressions = append(expressions, e...)
 				}
 			}
 		}
+		if e, ok := GetVarSubstitutionExpressionsForStepEnvs(t.EmbeddedSteps()); ok {
+			expressions = append(expressions, e...)
+		}
+		if e, ok := GetVarSubstitutionExpressionsForWhenExpressions(t.WhenExpressions); ok {
+			expressions = append(expressions, e...)
+		}
+		if UsesExecutionStatus(expressions) {
+			return apis.ErrInvalidValue(fmt.Sprintf("pipeline task %s uses the execution status of tasks, which only final tasks can use", t.Name), fmt.Sprintf("spec.tasks[%d]", i))
+		}
+	}
+	for i, r := range results {
+		if expressions, ok := GetVarSubstitutionExpressionsForPipelineResult(r); ok && UsesExecutionStatus(expressions) {
+			return apis.ErrInvalidValue(fmt.Sprintf("pipeline result %s uses the execution status of tasks, which only final tasks can use", r.Name), fmt.Sprintf("spec.results[%d].value", i))
+		}
 	}
 	return nil
 }
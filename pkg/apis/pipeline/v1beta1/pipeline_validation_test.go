@@ -21,8 +21,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"knative.dev/pkg/apis"
 )
 
 func TestPipeline_Validate_Success(t *testing.T) {
@@ -249,6 +254,15 @@ func TestValidatePipelineTasks_Success(t *testing.T) {
 			Name:     "foo",
 			TaskSpec: &EmbeddedTask{TaskSpec: getTaskSpec()},
 		}},
+	}, {
+		name: "pipeline tasks referencing the same task",
+		tasks: []PipelineTask{{
+			Name:    "deploy-staging",
+			TaskRef: &TaskRef{Name: "deploy"},
+		}, {
+			Name:    "deploy-prod",
+			TaskRef: &TaskRef{Name: "deploy"},
+		}},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -311,6 +325,312 @@ func TestValidatePipelineTasks_Failure(t *testing.T) {
 	}
 }
 
+func TestValidatePipelineTasks_CustomTasks(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []PipelineTask
+		alphaDisabled bool
+		expectedError *apis.FieldError
+	}{{
+		name: "custom task",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Kind: "Example", Name: "my-example"},
+			Params:  []Param{{Name: "p", Value: NewArrayOrString("v")}},
+		}},
+	}, {
+		name: "custom task without name",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Kind: "Wait"},
+		}},
+	}, {
+		name: "custom task without alpha",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+		}},
+		alphaDisabled: true,
+		expectedError: &apis.FieldError{
+			Message: `custom tasks requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+			Paths:   []string{"spec.tasks[0].taskRef"},
+		},
+	}, {
+		name: "custom task without kind",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Name: "my-example"},
+		}},
+		expectedError: apis.ErrMissingField("spec.tasks[0].taskRef.kind"),
+	}, {
+		name: "custom kind without apiVersion",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Kind: "Example", Name: "my-example"},
+		}},
+		expectedError: apis.ErrMissingField("spec.tasks[0].taskRef.apiVersion"),
+	}, {
+		name: "custom task with resources",
+		tasks: []PipelineTask{{
+			Name:      "foo",
+			TaskRef:   &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+			Resources: &PipelineTaskResources{Inputs: []PipelineTaskInputResource{{Name: "src", Resource: "git"}}},
+		}},
+		expectedError: apis.ErrDisallowedFields("spec.tasks[0].resources"),
+	}, {
+		name: "custom task with workspaces",
+		tasks: []PipelineTask{{
+			Name:       "foo",
+			TaskRef:    &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+			Workspaces: []WorkspacePipelineTaskBinding{{Name: "src", Workspace: "src"}},
+		}},
+		expectedError: apis.ErrDisallowedFields("spec.tasks[0].workspaces"),
+	}, {
+		name: "custom task with taskSpec",
+		tasks: []PipelineTask{{
+			Name:     "foo",
+			TaskRef:  &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+			TaskSpec: &EmbeddedTask{TaskSpec: getTaskSpec()},
+		}},
+		expectedError: apis.ErrMultipleOneOf("spec.tasks[0].taskRef", "spec.tasks[0].taskSpec"),
+	}, {
+		name: "duplicate custom tasks",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+		}, {
+			Name:    "foo",
+			TaskRef: &TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+		}},
+		expectedError: apis.ErrMultipleOneOf("spec.tasks[1].name"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			if !tt.alphaDisabled {
+				cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			}
+			ctx := config.ToContext(context.Background(), cfg)
+			err := validatePipelineTasks(ctx, tt.tasks, []PipelineTask{})
+			if tt.expectedError == nil {
+				if err != nil {
+					t.Errorf("Pipeline.validatePipelineTasks() returned error for valid custom tasks: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Pipeline.validatePipelineTasks() did not return error for invalid custom tasks")
+			}
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("Pipeline.validatePipelineTasks() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidatePipelineTasks_WorkspaceConditions(t *testing.T) {
+	tests := []struct {
+		name          string
+		condition     string
+		expectedError *apis.FieldError
+	}{{
+		name:      "param condition",
+		condition: `$(params.cache-enabled) == "true"`,
+	}, {
+		name:      "invalid operator",
+		condition: `$(params.cache-enabled) = "true"`,
+		expectedError: apis.ErrInvalidValue(`condition "$(params.cache-enabled) = \"true\"" should compare two operands with == or !=`,
+			"spec.tasks[0].workspaces[0].condition"),
+	}, {
+		name:      "result reference",
+		condition: `$(tasks.check.results.cache) == "true"`,
+		expectedError: apis.ErrInvalidValue(`condition "$(tasks.check.results.cache) == \"true\"" can't reference the results or the status of tasks`,
+			"spec.tasks[0].workspaces[0].condition"),
+	}, {
+		name:      "status reference",
+		condition: `"$(tasks.check.status)" != "Failed"`,
+		expectedError: apis.ErrInvalidValue(`condition "\"$(tasks.check.status)\" != \"Failed\"" can't reference the results or the status of tasks`,
+			"spec.tasks[0].workspaces[0].condition"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []PipelineTask{{
+				Name:    "build",
+				TaskRef: &TaskRef{Name: "build"},
+				Workspaces: []WorkspacePipelineTaskBinding{{
+					Name:      "cache",
+					Workspace: "cache",
+					Condition: tt.condition,
+				}},
+			}}
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			ctx := config.ToContext(context.Background(), cfg)
+			err := validatePipelineTasks(ctx, tasks, []PipelineTask{})
+			if tt.expectedError == nil {
+				if err != nil {
+					t.Errorf("Pipeline.validatePipelineTasks() returned error for valid workspace condition: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Pipeline.validatePipelineTasks() did not return error for invalid workspace condition")
+			}
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("Pipeline.validatePipelineTasks() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidatePipelineTasks_ParamsAppend(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         ArrayOrString
+		expectedError *apis.FieldError
+	}{{
+		name:  "array value",
+		value: NewArrayOrString("--verbose", "$(params.flags[*])"),
+	}, {
+		name:          "string value",
+		value:         NewArrayOrString("--verbose"),
+		expectedError: apis.ErrInvalidValue(`only array values can be appended to the default of parameter "flags"`, "spec.tasks[0].params[0].append"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []PipelineTask{{
+				Name:    "build",
+				TaskRef: &TaskRef{Name: "build"},
+				Params:  []Param{{Name: "flags", Value: tt.value, Append: true}},
+			}}
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			ctx := config.ToContext(context.Background(), cfg)
+			err := validatePipelineTasks(ctx, tasks, []PipelineTask{})
+			if tt.expectedError == nil {
+				if err != nil {
+					t.Errorf("Pipeline.validatePipelineTasks() returned error for valid appended param: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Pipeline.validatePipelineTasks() did not return error for invalid appended param")
+			}
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("Pipeline.validatePipelineTasks() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidatePipelineTasks_FailurePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []PipelineTask
+		finalTasks    []PipelineTask
+		alpha         bool
+		expectedError *apis.FieldError
+	}{{
+		name:       "ignored final task failure",
+		tasks:      []PipelineTask{{Name: "build", TaskRef: &TaskRef{Name: "build"}}},
+		finalTasks: []PipelineTask{{Name: "notify", TaskRef: &TaskRef{Name: "notify"}, FailurePolicy: PipelineTaskFailurePolicyIgnore}},
+		alpha:      true,
+	}, {
+		name:          "unknown failure policy",
+		tasks:         []PipelineTask{{Name: "build", TaskRef: &TaskRef{Name: "build"}}},
+		finalTasks:    []PipelineTask{{Name: "notify", TaskRef: &TaskRef{Name: "notify"}, FailurePolicy: "retry"}},
+		alpha:         true,
+		expectedError: apis.ErrInvalidValue("retry should be ignore", "spec.finally[0].failurePolicy"),
+	}, {
+		name:          "failure policy of a task",
+		tasks:         []PipelineTask{{Name: "build", TaskRef: &TaskRef{Name: "build"}, FailurePolicy: PipelineTaskFailurePolicyIgnore}},
+		alpha:         true,
+		expectedError: apis.ErrInvalidValue("failurePolicy is only allowed under spec.finally, pipeline task build has failurePolicy specified", "spec.tasks[0].failurePolicy"),
+	}, {
+		name:       "failure policy without alpha fields",
+		tasks:      []PipelineTask{{Name: "build", TaskRef: &TaskRef{Name: "build"}}},
+		finalTasks: []PipelineTask{{Name: "notify", TaskRef: &TaskRef{Name: "notify"}, FailurePolicy: PipelineTaskFailurePolicyIgnore}},
+		expectedError: &apis.FieldError{
+			Message: `failurePolicy requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+			Paths:   []string{"spec.finally[0].failurePolicy"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			if tt.alpha {
+				cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			}
+			ctx := config.ToContext(context.Background(), cfg)
+			err := validatePipelineTasks(ctx, tt.tasks, tt.finalTasks)
+			if tt.expectedError == nil {
+				if err != nil {
+					t.Errorf("Pipeline.validatePipelineTasks() returned error for valid failure policy: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Pipeline.validatePipelineTasks() did not return error for invalid failure policy")
+			}
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("Pipeline.validatePipelineTasks() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidatePipelineSpec_TaskRefNameParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        []ParamSpec
+		alpha         bool
+		expectedError string
+	}{{
+		name:   "string param",
+		params: []ParamSpec{{Name: "language", Type: ParamTypeString}},
+		alpha:  true,
+	}, {
+		name:          "undeclared param",
+		alpha:         true,
+		expectedError: `non-existent variable in "build-$(params.language)" for task parameter taskRef.name: pipelinespec.params.taskRef.name`,
+	}, {
+		name:          "array param",
+		params:        []ParamSpec{{Name: "language", Type: ParamTypeArray}},
+		alpha:         true,
+		expectedError: `variable type invalid in "build-$(params.language)" for task parameter taskRef.name: pipelinespec.params.taskRef.name`,
+	}, {
+		name:          "params without alpha fields",
+		params:        []ParamSpec{{Name: "language", Type: ParamTypeString}},
+		expectedError: `params in taskRef.name requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.tasks[0].taskRef.name`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			if tt.alpha {
+				cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			}
+			ctx := config.ToContext(context.Background(), cfg)
+			ps := &PipelineSpec{
+				Params: tt.params,
+				Tasks:  []PipelineTask{{Name: "build", TaskRef: &TaskRef{Name: "build-$(params.language)"}}},
+			}
+			err := ps.Validate(ctx)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("PipelineSpec.Validate() returned error for valid params in taskRef.name: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("PipelineSpec.Validate() did not return error for invalid params in taskRef.name")
+			}
+			if d := cmp.Diff(tt.expectedError, err.Error()); d != "" {
+				t.Errorf("PipelineSpec.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestValidateFrom_Success(t *testing.T) {
 	desc := "valid pipeline task - from resource referring to valid output resource of the pipeline task"
 	tasks := []PipelineTask{{
@@ -630,19 +950,66 @@ func TestValidateGraph_Success(t *testing.T) {
 }
 
 func TestValidateGraph_Failure(t *testing.T) {
-	desc := "invalid dependency graph between the tasks with cyclic dependency"
-	tasks := []PipelineTask{{
-		Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}, RunAfter: []string{"bar"},
+	tests := []struct {
+		name    string
+		tasks   []PipelineTask
+		wantErr string
+	}{{
+		name: "cycle between two tasks",
+		tasks: []PipelineTask{{
+			Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}, RunAfter: []string{"bar"},
+		}, {
+			Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}, RunAfter: []string{"foo"},
+		}},
+		wantErr: "cycle detected: bar -> foo -> bar",
 	}, {
-		Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}, RunAfter: []string{"foo"},
+		name: "cycle between three tasks",
+		tasks: []PipelineTask{{
+			Name: "foo", TaskRef: &TaskRef{Name: "foo-task"},
+		}, {
+			Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}, RunAfter: []string{"foo", "baz"},
+		}, {
+			Name: "qux", TaskRef: &TaskRef{Name: "qux-task"}, RunAfter: []string{"bar"},
+		}, {
+			Name: "baz", TaskRef: &TaskRef{Name: "baz-task"}, RunAfter: []string{"qux"},
+		}},
+		wantErr: "cycle detected: baz -> bar -> qux -> baz",
+	}, {
+		name: "task depending on itself",
+		tasks: []PipelineTask{{
+			Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}, RunAfter: []string{"foo"},
+		}},
+		wantErr: `cycle detected; task "foo" depends on itself`,
 	}}
-	t.Run(desc, func(t *testing.T) {
-		err := validateGraph(tasks)
-		if err == nil {
-			t.Error("Pipeline.validateGraph() did not return error for invalid DAG of pipeline tasks:", desc)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGraph(tt.tasks)
+			if err == nil {
+				t.Fatal("Pipeline.validateGraph() did not return error for invalid DAG of pipeline tasks")
+			}
+			if d := cmp.Diff(tt.wantErr, err.Error()); d != "" {
+				t.Errorf("Pipeline.validateGraph() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
 
-		}
-	})
+func TestPipelineSpec_Validate_Cycle(t *testing.T) {
+	ps := &PipelineSpec{
+		Tasks: []PipelineTask{{
+			Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}, RunAfter: []string{"bar"},
+		}, {
+			Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}, RunAfter: []string{"foo"},
+		}},
+	}
+	want := apis.ErrInvalidValue("cycle detected: bar -> foo -> bar", "spec.tasks")
+	err := ps.Validate(context.Background())
+	if err == nil {
+		t.Fatal("PipelineSpec.Validate() did not return error for a cycle of pipeline tasks")
+	}
+	if d := cmp.Diff(want.Error(), err.Error()); d != "" {
+		t.Errorf("PipelineSpec.Validate() %s", diff.PrintWantGot(d))
+	}
 }
 
 func TestValidateParamResults_Success(t *testing.T) {
@@ -689,6 +1056,145 @@ func TestValidateParamResults_Failure(t *testing.T) {
 	})
 }
 
+func TestValidateStepEnvResults_Success(t *testing.T) {
+	tasks := []PipelineTask{{
+		Name: "a-task",
+		TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+			Results: []TaskResult{{Name: "output"}},
+			Steps:   []Step{{Container: corev1.Container{Name: "foo", Image: "bar"}}},
+		}},
+	}, {
+		Name:    "b-task",
+		TaskRef: &TaskRef{Name: "b-task"},
+	}, {
+		Name: "c-task",
+		TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+			Steps: []Step{{Container: corev1.Container{
+				Name: "foo", Image: "bar",
+				Env: []corev1.EnvVar{
+					{Name: "A", Value: "$(tasks.a-task.results.output)"},
+					{Name: "B", Value: "prefix-$(tasks.b-task.results.anything)"},
+				},
+			}}},
+		}},
+	}}
+	if err := validateStepEnvResults(tasks); err != nil {
+		t.Errorf("Pipeline.validateStepEnvResults() returned error for valid pipeline: %v", err)
+	}
+}
+
+func TestValidateStepEnvResults_Failure(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []PipelineTask
+	}{{
+		name: "malformed result reference in step env",
+		tasks: []PipelineTask{{
+			Name: "a-task", TaskRef: &TaskRef{Name: "a-task"},
+		}, {
+			Name: "b-task",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Steps: []Step{{Container: corev1.Container{
+					Name: "foo", Image: "bar",
+					Env: []corev1.EnvVar{{Name: "A", Value: "$(tasks.a-task.resultTypo.output)"}},
+				}}},
+			}},
+		}},
+	}, {
+		name: "step env referencing a result not declared by the embedded task",
+		tasks: []PipelineTask{{
+			Name: "a-task",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Results: []TaskResult{{Name: "output"}},
+				Steps:   []Step{{Container: corev1.Container{Name: "foo", Image: "bar"}}},
+			}},
+		}, {
+			Name: "b-task",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Steps: []Step{{Container: corev1.Container{
+					Name: "foo", Image: "bar",
+					Env: []corev1.EnvVar{{Name: "A", Value: "$(tasks.a-task.results.missing)"}},
+				}}},
+			}},
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateStepEnvResults(tt.tasks); err == nil {
+				t.Errorf("Pipeline.validateStepEnvResults() did not return error for invalid pipeline: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestValidateWhenExpressionsResults_Success(t *testing.T) {
+	tasks := []PipelineTask{{
+		Name: "a-task",
+		TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+			Results: []TaskResult{{Name: "output"}},
+			Steps:   []Step{{Container: corev1.Container{Name: "foo", Image: "bar"}}},
+		}},
+	}, {
+		Name:    "b-task",
+		TaskRef: &TaskRef{Name: "b-task"},
+	}, {
+		Name:    "c-task",
+		TaskRef: &TaskRef{Name: "c-task"},
+		WhenExpressions: WhenExpressions{{
+			Input:    "$(tasks.a-task.results.output)",
+			Operator: selection.In,
+			Values:   []string{"foo", "prefix-$(tasks.b-task.results.anything)"},
+		}},
+	}}
+	if err := validateWhenExpressionsResults(tasks); err != nil {
+		t.Errorf("Pipeline.validateWhenExpressionsResults() returned error for valid pipeline: %v", err)
+	}
+}
+
+func TestValidateWhenExpressionsResults_Failure(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []PipelineTask
+	}{{
+		name: "malformed result reference in when expression values",
+		tasks: []PipelineTask{{
+			Name: "a-task", TaskRef: &TaskRef{Name: "a-task"},
+		}, {
+			Name:    "b-task",
+			TaskRef: &TaskRef{Name: "b-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"$(tasks.a-task.resultTypo.output)"},
+			}},
+		}},
+	}, {
+		name: "when expression values referencing a result not declared by the embedded task",
+		tasks: []PipelineTask{{
+			Name: "a-task",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Results: []TaskResult{{Name: "output"}},
+				Steps:   []Step{{Container: corev1.Container{Name: "foo", Image: "bar"}}},
+			}},
+		}, {
+			Name:    "b-task",
+			TaskRef: &TaskRef{Name: "b-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "foo",
+				Operator: selection.NotIn,
+				Values:   []string{"$(tasks.a-task.results.missing)"},
+			}},
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWhenExpressionsResults(tt.tasks); err == nil {
+				t.Errorf("Pipeline.validateWhenExpressionsResults() did not return error for invalid pipeline: %s", tt.name)
+			}
+		})
+	}
+}
+
 func TestValidatePipelineResults_Success(t *testing.T) {
 	desc := "valid pipeline with valid pipeline results syntax"
 	results := []PipelineResult{{
@@ -795,6 +1301,28 @@ func TestValidatePipelineParameterVariables_Failure(t *testing.T) {
 		params []ParamSpec
 		tasks  []PipelineTask
 	}{{
+		name: "invalid pipeline task with a workspace condition referencing a param missing from the param declarations",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			Workspaces: []WorkspacePipelineTaskBinding{{
+				Name:      "cache",
+				Workspace: "cache",
+				Condition: `$(params.does-not-exist) == "true"`,
+			}},
+		}},
+	}, {
+		name: "invalid pipeline task with a when expression referencing a param missing from the param declarations",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "$(params.does-not-exist)",
+				Operator: selection.In,
+				Values:   []string{"foo"},
+			}},
+		}},
+	}, {
 		name: "invalid pipeline task with a parameter which is missing from the param declarations",
 		tasks: []PipelineTask{{
 			Name:    "foo",
@@ -948,6 +1476,116 @@ func TestValidatePipelineParameterVariables_Failure(t *testing.T) {
 	}
 }
 
+func TestPipelineSpec_UnusedParamWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		ps      *PipelineSpec
+		want    []string
+	}{{
+		name:    "params used by task params, conditions, when expressions, task references and results",
+		enabled: true,
+		ps: &PipelineSpec{
+			Params: []ParamSpec{{
+				Name: "string", Type: ParamTypeString,
+			}, {
+				Name: "language", Type: ParamTypeString,
+			}, {
+				Name: "array", Type: ParamTypeArray,
+			}, {
+				Name: "condition", Type: ParamTypeString,
+			}, {
+				Name: "when", Type: ParamTypeString,
+			}, {
+				Name: "result", Type: ParamTypeString,
+			}, {
+				Name: "final", Type: ParamTypeString,
+			}},
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Params: []Param{{
+					Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "prefix-$(params.string)"},
+				}, {
+					Name: "b-param", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"$(params.array[*])"}},
+				}},
+				Conditions: []PipelineTaskCondition{{
+					ConditionRef: "some-condition",
+					Params: []Param{{
+						Name: "c-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.condition)"},
+					}},
+				}},
+				WhenExpressions: []WhenExpression{{
+					Input:    "$(params.when)",
+					Operator: selection.In,
+					Values:   []string{"foo"},
+				}},
+			}},
+			Finally: []PipelineTask{{
+				Name:    "bar",
+				TaskRef: &TaskRef{Name: "bar-$(params.language)"},
+				Params: []Param{{
+					Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.final)"},
+				}},
+			}},
+			Results: []PipelineResult{{
+				Name:  "result",
+				Value: "$(params.result)",
+			}},
+		},
+	}, {
+		name:    "unused params",
+		enabled: true,
+		ps: &PipelineSpec{
+			Params: []ParamSpec{{
+				Name: "used", Type: ParamTypeString,
+			}, {
+				Name: "unused", Type: ParamTypeString,
+			}, {
+				Name: "unused-array", Type: ParamTypeArray,
+			}},
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Params: []Param{{
+					Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.used)"},
+				}},
+			}},
+		},
+		want: []string{
+			`param "unused" is declared in spec.params but never used`,
+			`param "unused-array" is declared in spec.params but never used`,
+		},
+	}, {
+		name:    "unused params with the feature flag disabled",
+		enabled: false,
+		ps: &PipelineSpec{
+			Params: []ParamSpec{{
+				Name: "unused", Type: ParamTypeString,
+			}},
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableUnusedParamWarnings = tt.enabled
+			// The when expressions are an alpha feature.
+			cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			ctx := config.ToContext(context.Background(), cfg)
+			if d := cmp.Diff(tt.want, tt.ps.UnusedParamWarnings(ctx)); d != "" {
+				t.Errorf("PipelineSpec.UnusedParamWarnings() %s", diff.PrintWantGot(d))
+			}
+			if err := tt.ps.Validate(ctx); err != nil {
+				t.Errorf("PipelineSpec.Validate() returned error for pipeline with unused params: %v", err)
+			}
+		})
+	}
+}
+
 func TestValidatePipelineWorkspaces_Success(t *testing.T) {
 	desc := "unused pipeline spec workspaces do not cause an error"
 	workspaces := []PipelineWorkspaceDeclaration{{
@@ -1001,6 +1639,19 @@ func TestValidatePipelineWorkspaces_Failure(t *testing.T) {
 		tasks: []PipelineTask{{
 			Name: "foo", TaskRef: &TaskRef{Name: "foo"},
 		}},
+	}, {
+		name: "when expressions relying on a non-existent pipeline workspace cause an error",
+		workspaces: []PipelineWorkspaceDeclaration{{
+			Name: "foo",
+		}},
+		tasks: []PipelineTask{{
+			Name: "foo", TaskRef: &TaskRef{Name: "foo"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "$(workspaces.cache.bound)",
+				Operator: selection.In,
+				Values:   []string{"true"},
+			}},
+		}},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1076,10 +1727,73 @@ func TestValidatePipelineWithFinalTasks_Success(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "valid pipeline with final tasks using the results and the execution status of tasks",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Tasks: []PipelineTask{{
+					Name:    "non-final-task",
+					TaskRef: &TaskRef{Name: "non-final-task"},
+				}},
+				Finally: []PipelineTask{{
+					Name:    "final-task",
+					TaskRef: &TaskRef{Name: "final-task"},
+					Params: []Param{{
+						Name: "commit", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.non-final-task.results.commit)"},
+					}, {
+						Name: "status", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.non-final-task.status)"},
+					}, {
+						Name: "aggregate-status", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.status)"},
+					}},
+				}},
+			},
+		},
+	}, {
+		name: "valid pipeline with final tasks guarded by when expressions",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Params: []ParamSpec{{Name: "notify", Type: ParamTypeString}},
+				Tasks: []PipelineTask{{
+					Name:    "non-final-task",
+					TaskRef: &TaskRef{Name: "non-final-task"},
+				}},
+				Finally: []PipelineTask{{
+					Name:    "notify-on-failure",
+					TaskRef: &TaskRef{Name: "notify"},
+					WhenExpressions: WhenExpressions{{
+						Input:    "$(tasks.status)",
+						Operator: selection.In,
+						Values:   []string{"Failed"},
+					}, {
+						Input:    "$(params.notify)",
+						Operator: selection.In,
+						Values:   []string{"true"},
+					}},
+				}, {
+					Name:    "publish",
+					TaskRef: &TaskRef{Name: "publish"},
+					WhenExpressions: WhenExpressions{{
+						Input:    "$(tasks.non-final-task.results.commit)",
+						Operator: selection.NotIn,
+						Values:   []string{""},
+					}, {
+						Input:    "$(tasks.non-final-task.status)",
+						Operator: selection.In,
+						Values:   []string{"Succeeded"},
+					}},
+				}},
+			},
+		},
 	}}
+	// The when expressions are an alpha feature.
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+	ctx := config.ToContext(context.Background(), cfg)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.p.Validate(context.Background())
+			err := tt.p.Validate(ctx)
 			if err != nil {
 				t.Errorf("Pipeline.Validate() returned error for valid pipeline with finally: %s: %v", tt.name, err)
 			}
@@ -1341,6 +2055,25 @@ func TestValidateFinalTasks_Failure(t *testing.T) {
 				ConditionRef: "some-condition",
 			}},
 		}},
+	}, {
+		name: "invalid pipeline with final task specifying whenScope",
+		finalTasks: []PipelineTask{{
+			Name:    "final-task",
+			TaskRef: &TaskRef{Name: "final-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"foo"},
+			}},
+			WhenScope: WhenScopeTask,
+		}},
+	}, {
+		name: "invalid pipeline with final task specifying continueOnFailure",
+		finalTasks: []PipelineTask{{
+			Name:              "final-task",
+			TaskRef:           &TaskRef{Name: "final-task"},
+			ContinueOnFailure: true,
+		}},
 	}, {
 		name: "invalid pipeline with final task output resources referring to other task input",
 		finalTasks: []PipelineTask{{
@@ -1353,7 +2086,7 @@ func TestValidateFinalTasks_Failure(t *testing.T) {
 			},
 		}},
 	}, {
-		name: "invalid pipeline with final tasks having reference to task results",
+		name: "invalid pipeline with final tasks having reference to results of a task not in spec.tasks",
 		finalTasks: []PipelineTask{{
 			Name:    "final-task",
 			TaskRef: &TaskRef{Name: "final-task"},
@@ -1361,10 +2094,71 @@ func TestValidateFinalTasks_Failure(t *testing.T) {
 				Name: "param1", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.a-task.results.output)"},
 			}},
 		}},
+	}, {
+		name: "invalid pipeline with final tasks having reference to results of a task not in spec.tasks in step env",
+		finalTasks: []PipelineTask{{
+			Name: "final-task",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Steps: []Step{{Container: corev1.Container{
+					Name: "foo", Image: "bar",
+					Env: []corev1.EnvVar{{Name: "A", Value: "$(tasks.a-task.results.output)"}},
+				}}},
+			}},
+		}},
+	}, {
+		name: "invalid pipeline with final tasks having reference to results of another final task",
+		finalTasks: []PipelineTask{{
+			Name:    "final-task-1",
+			TaskRef: &TaskRef{Name: "final-task"},
+		}, {
+			Name:    "final-task-2",
+			TaskRef: &TaskRef{Name: "final-task"},
+			Params: []Param{{
+				Name: "param1", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.final-task-1.results.output)"},
+			}},
+		}},
+	}, {
+		name: "invalid pipeline with final tasks having reference to the execution status of a task not in spec.tasks",
+		finalTasks: []PipelineTask{{
+			Name:    "final-task",
+			TaskRef: &TaskRef{Name: "final-task"},
+			Params: []Param{{
+				Name: "param1", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.a-task.status)"},
+			}},
+		}},
+	}, {
+		name: "invalid pipeline with final task when expressions using the execution status of a task not in spec.tasks",
+		finalTasks: []PipelineTask{{
+			Name:    "final-task",
+			TaskRef: &TaskRef{Name: "final-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "$(tasks.a-task.status)",
+				Operator: selection.In,
+				Values:   []string{"Failed"},
+			}},
+		}},
+	}, {
+		name: "invalid pipeline with final task when expressions using results of another final task",
+		finalTasks: []PipelineTask{{
+			Name:    "final-task-1",
+			TaskRef: &TaskRef{Name: "final-task"},
+		}, {
+			Name:    "final-task-2",
+			TaskRef: &TaskRef{Name: "final-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "$(tasks.final-task-1.results.output)",
+				Operator: selection.In,
+				Values:   []string{"foo"},
+			}},
+		}},
+	}}
+	tasks := []PipelineTask{{
+		Name:    "non-final-task",
+		TaskRef: &TaskRef{Name: "non-final-task"},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateFinalTasks(tt.finalTasks)
+			err := validateFinalTasks(tasks, tt.finalTasks)
 			if err == nil {
 				t.Errorf("Pipeline.ValidateFinalTasks() did not return error for invalid pipeline: %s", tt.name)
 			}
@@ -1372,6 +2166,68 @@ func TestValidateFinalTasks_Failure(t *testing.T) {
 	}
 }
 
+func TestValidateExecutionStatusVariables_Failure(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []PipelineTask
+		results []PipelineResult
+	}{{
+		name: "execution status of a task in params",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			Params: []Param{{
+				Name: "status", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(tasks.bar.status)"},
+			}},
+		}},
+	}, {
+		name: "aggregate execution status in params",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			Params: []Param{{
+				Name: "status", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"$(tasks.status)"}},
+			}},
+		}},
+	}, {
+		name: "execution status of a task in when expressions",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			WhenExpressions: WhenExpressions{{
+				Input:    "$(tasks.bar.status)",
+				Operator: selection.In,
+				Values:   []string{"Failed"},
+			}},
+		}},
+	}, {
+		name: "aggregate execution status in step env",
+		tasks: []PipelineTask{{
+			Name: "foo",
+			TaskSpec: &EmbeddedTask{TaskSpec: &TaskSpec{
+				Steps: []Step{{Container: corev1.Container{
+					Name: "foo", Image: "bar",
+					Env: []corev1.EnvVar{{Name: "STATUS", Value: "$(tasks.status)"}},
+				}}},
+			}},
+		}},
+	}, {
+		name: "execution status of a task in pipeline results",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+		}},
+		results: []PipelineResult{{Name: "status", Value: "$(tasks.foo.status)"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateExecutionStatusVariables(tt.tasks, tt.results); err == nil {
+				t.Errorf("validateExecutionStatusVariables() did not return error for invalid pipeline: %s", tt.name)
+			}
+		})
+	}
+}
+
 func TestContextValid(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1466,7 +2322,7 @@ func TestContextInvalid(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if err := validatePipelineContextVariables(tt.tasks); err == nil {
-				t.Errorf("Pipeline.validatePipelineContextVariables() did not return error for invalid pipeline parameters: %s, %s", tt.name, tt.tasks[0].Params)
+				t.Errorf("Pipeline.validatePipelineContextVariables() did not return error for invalid pipeline parameters: %s, %v", tt.name, tt.tasks[0].Params)
 			}
 		})
 	}
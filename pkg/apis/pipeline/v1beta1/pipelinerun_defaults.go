@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
@@ -42,12 +43,13 @@ func (prs *PipelineRunSpec) SetDefaults(ctx context.Context) {
 		prs.ServiceAccountName = defaultSA
 	}
 
-	defaultPodTemplate := cfg.Defaults.DefaultPodTemplate
-	if prs.PodTemplate == nil {
-		prs.PodTemplate = defaultPodTemplate
-	}
+	prs.PodTemplate = pod.MergePodTemplateWithDefault(prs.PodTemplate, cfg.Defaults.DefaultPodTemplate)
 
 	if prs.PipelineSpec != nil {
 		prs.PipelineSpec.SetDefaults(ctx)
 	}
+
+	if prs.Concurrency != nil && prs.Concurrency.Policy == "" {
+		prs.Concurrency.Policy = ConcurrencyPolicyQueue
+	}
 }
@@ -78,6 +78,19 @@ func TestPipelineRunSpec_SetDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "concurrency policy is empty",
+			prs: &v1beta1.PipelineRunSpec{
+				Concurrency: &v1beta1.PipelineRunConcurrency{Key: "production"},
+			},
+			want: &v1beta1.PipelineRunSpec{
+				Timeout: &metav1.Duration{Duration: config.DefaultTimeoutMinutes * time.Minute},
+				Concurrency: &v1beta1.PipelineRunConcurrency{
+					Key:    "production",
+					Policy: v1beta1.ConcurrencyPolicyQueue,
+				},
+			},
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -22,7 +22,9 @@ import (
 
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
@@ -102,6 +104,15 @@ func (pr *PipelineRun) IsPause() bool {
 	return pr.Spec.Status == PipelineRunSpecStatusPause
 }
 
+// ConcurrencyKey returns the concurrency key of the PipelineRun, or an empty
+// string if it can run concurrently with any other PipelineRun.
+func (pr *PipelineRun) ConcurrencyKey() string {
+	if pr.Spec.Concurrency == nil {
+		return ""
+	}
+	return pr.Spec.Concurrency.Key
+}
+
 // GetRunKey return the pipelinerun key for timeout handler map
 func (pr *PipelineRun) GetRunKey() string {
 	// The address of the pointer is a threadsafe unique identifier for the pipelinerun
@@ -188,8 +199,106 @@ type PipelineRunSpec struct {
 	// TaskRunSpecs holds a set of runtime specs
 	// +optional
 	TaskRunSpecs []PipelineTaskRunSpec `json:"taskRunSpecs,omitempty"`
+	// Concurrency limits the PipelineRuns sharing its key to one active
+	// PipelineRun per namespace.
+	// +optional
+	Concurrency *PipelineRunConcurrency `json:"concurrency,omitempty"`
+	// TriggerOnConfigMapChange lists ConfigMaps, in the namespace of the
+	// PipelineRun, whose changes create a new PipelineRun from this one.
+	// +optional
+	TriggerOnConfigMapChange []corev1.ObjectReference `json:"triggerOnConfigMapChange,omitempty"`
+	// IsolatedNamespace runs the TaskRuns of the PipelineRun in a namespace
+	// created for it, which is deleted once the PipelineRun is done.
+	// +optional
+	IsolatedNamespace bool `json:"isolatedNamespace,omitempty"`
+	// CheckpointInterval is how often the execution state of the PipelineRun
+	// is saved, so that the controller can restore it when it restarts.
+	// +optional
+	CheckpointInterval *metav1.Duration `json:"checkpointInterval,omitempty"`
+	// NetworkPolicies declare the traffic allowed between the TaskRuns of the
+	// PipelineRun, which is translated into NetworkPolicies.
+	// +optional
+	NetworkPolicies []NetworkPolicyRule `json:"networkPolicies,omitempty"`
+	// NodePools assign the TaskRuns of the PipelineRun to pools of nodes,
+	// by adding the node selector of their pool to their pod template.
+	// +optional
+	NodePools []NodePoolSpec `json:"nodePools,omitempty"`
+	// DefaultNodePool is the name of the node pool of the TaskRuns whose
+	// PipelineTask isn't matched by any node pool.
+	// +optional
+	DefaultNodePool string `json:"defaultNodePool,omitempty"`
+	// FailurePolicy decides whether the PipelineRun keeps scheduling PipelineTasks
+	// once one of them failed. Defaults to "FailFast".
+	// +optional
+	FailurePolicy PipelineRunFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// PipelineRunFailurePolicy is the policy applied to a PipelineRun when one of its
+// PipelineTasks fails.
+type PipelineRunFailurePolicy string
+
+const (
+	// PipelineRunFailurePolicyFailFast stops scheduling PipelineTasks once one of
+	// them failed, and fails the PipelineRun when the running ones are done.
+	PipelineRunFailurePolicyFailFast PipelineRunFailurePolicy = "FailFast"
+	// PipelineRunFailurePolicyCompleteAll keeps scheduling the PipelineTasks whose
+	// parents succeeded after a PipelineTask failed, and fails the PipelineRun once
+	// they are all done.
+	PipelineRunFailurePolicyCompleteAll PipelineRunFailurePolicy = "CompleteAll"
+)
+
+// NodePoolSpec matches PipelineTasks by name or by the tags of their Task, and
+// runs their TaskRuns on the nodes selected by its node selector.
+type NodePoolSpec struct {
+	// Name identifies the node pool in the PipelineRun.
+	Name string `json:"name"`
+	// PipelineTasks are the names of the PipelineTasks run in the node pool.
+	// +optional
+	PipelineTasks []string `json:"pipelineTasks,omitempty"`
+	// Tags match the PipelineTasks whose Task has one of them.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// NodeSelector selects the nodes of the node pool.
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+// NetworkPolicyRule allows the Pods of the TaskRuns of a PipelineTask to receive
+// traffic from the Pods of the TaskRuns of other PipelineTasks of the PipelineRun.
+// The Pods of the PipelineTask receive no other traffic.
+type NetworkPolicyRule struct {
+	// Name identifies the rule in the PipelineRun.
+	Name string `json:"name"`
+	// To is the name of the PipelineTask receiving the traffic.
+	To string `json:"to"`
+	// From are the names of the PipelineTasks allowed to send traffic to To.
+	From []string `json:"from"`
+	// Ports the traffic is allowed on. Defaults to all ports.
+	// +optional
+	Ports []networkingv1.NetworkPolicyPort `json:"ports,omitempty"`
 }
 
+// PipelineRunConcurrency serializes the PipelineRuns of a namespace sharing a key.
+type PipelineRunConcurrency struct {
+	// Key identifies the PipelineRuns that can't be active at the same time.
+	Key string `json:"key"`
+	// Policy decides what happens to a PipelineRun started while another PipelineRun
+	// with the same key is active. Defaults to "queue".
+	// +optional
+	Policy ConcurrencyPolicy `json:"policy,omitempty"`
+}
+
+// ConcurrencyPolicy is the policy applied to PipelineRuns sharing a concurrency key.
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyQueue makes a PipelineRun wait for the active PipelineRun
+	// with the same key to be done before it starts.
+	ConcurrencyPolicyQueue ConcurrencyPolicy = "queue"
+	// ConcurrencyPolicyCancelPrevious cancels the active PipelineRuns with the same
+	// key when a PipelineRun starts.
+	ConcurrencyPolicyCancelPrevious ConcurrencyPolicy = "cancelPrevious"
+)
+
 // PipelineRunSpecStatus defines the pipelinerun spec status the user can provide
 type PipelineRunSpecStatus string
 
@@ -244,6 +353,9 @@ const (
 	PipelineRunReasonStopping PipelineRunReason = "PipelineRunStopping"
 
 	PipelineRunReasonPause PipelineRunReason = "Paused"
+	// PipelineRunReasonQueued is the reason set when the PipelineRun waits for the active
+	// PipelineRun with the same concurrency key to be done before it starts
+	PipelineRunReasonQueued PipelineRunReason = "Queued"
 )
 
 func (t PipelineRunReason) String() string {
@@ -333,14 +445,54 @@ type PipelineRunStatusFields struct {
 	// +optional
 	TaskRuns map[string]*PipelineRunTaskRunStatus `json:"taskRuns,omitempty"`
 
+	// map of PipelineRunRunStatus with the Run name as the key
+	// +optional
+	Runs map[string]*PipelineRunRunStatus `json:"runs,omitempty"`
+
 	// PipelineResults are the list of results written out by the pipeline task's containers
 	// +optional
 	PipelineResults []PipelineRunResult `json:"pipelineResults,omitempty"`
 
 	// PipelineRunSpec contains the exact spec used to instantiate the run
 	PipelineSpec *PipelineSpec `json:"pipelineSpec,omitempty"`
+
+	// list of tasks that were skipped due to their conditions, missing workspaces
+	// or skipped parent tasks
+	// +optional
+	SkippedTasks []SkippedTask `json:"skippedTasks,omitempty"`
 }
 
+// SkippedTask is used to describe the Tasks that were skipped, and why
+type SkippedTask struct {
+	// Name is the Pipeline Task name
+	Name string `json:"name"`
+	// Reason is the cause of the PipelineTask being skipped
+	Reason SkippingReason `json:"reason"`
+}
+
+// SkippingReason explains why a PipelineTask was skipped
+type SkippingReason string
+
+const (
+	// ConditionCheckSkip means the conditions of the PipelineTask evaluated to false
+	ConditionCheckSkip SkippingReason = "ConditionCheckFailed"
+	// WhenExpressionsSkip means the when expressions of the PipelineTask evaluated to false
+	WhenExpressionsSkip SkippingReason = "WhenExpressionsEvaluatedToFalse"
+	// FinallyWhenExpressionsSkip means the when expressions of the final task evaluated to false
+	// once the PipelineTasks were done
+	FinallyWhenExpressionsSkip SkippingReason = "FinallyWhenExpressionsEvaluatedToFalse"
+	// ParentTasksSkip means a PipelineTask the PipelineTask depends on was skipped
+	ParentTasksSkip SkippingReason = "ParentTasksSkipped"
+	// StoppingSkip means the PipelineRun stopped scheduling PipelineTasks because one of them failed
+	StoppingSkip SkippingReason = "PipelineRunStopping"
+	// ParentTasksFailedSkip means a PipelineTask the PipelineTask depends on failed, while
+	// the PipelineRun completes the other PipelineTasks with the CompleteAll failure policy
+	ParentTasksFailedSkip SkippingReason = "ParentTasksFailed"
+	// MissingResultsOrWorkspaceSkip means the PipelineTask uses an optional workspace
+	// which isn't bound by the PipelineRun
+	MissingResultsOrWorkspaceSkip SkippingReason = "MissingResultsOrWorkspace"
+)
+
 // PipelineRunResult used to describe the results of a pipeline
 type PipelineRunResult struct {
 	// Name is the result's name as declared by the Pipeline
@@ -360,6 +512,19 @@ type PipelineRunTaskRunStatus struct {
 	// ConditionChecks maps the name of a condition check to its Status
 	// +optional
 	ConditionChecks map[string]*PipelineRunConditionCheckStatus `json:"conditionChecks,omitempty"`
+	// NonFatal is true when the TaskRun failed but its PipelineTask sets ContinueOnFailure,
+	// or is a final task ignoring its failure, so that its failure doesn't fail the PipelineRun
+	// +optional
+	NonFatal bool `json:"nonFatal,omitempty"`
+}
+
+// PipelineRunRunStatus contains the name of the PipelineTask for this Run and the Run's Status
+type PipelineRunRunStatus struct {
+	// PipelineTaskName is the name of the PipelineTask.
+	PipelineTaskName string `json:"pipelineTaskName,omitempty"`
+	// Status is the RunStatus for the corresponding Run
+	// +optional
+	Status *runv1alpha1.RunStatus `json:"status,omitempty"`
 }
 
 // PipelineRunConditionCheckStatus returns the condition check status
@@ -416,3 +581,32 @@ func (pr *PipelineRun) GetTaskRunSpecs(pipelineTaskName string) (string, *PodTem
 	}
 	return serviceAccountName, taskPodTemplate
 }
+
+// GetNodePool returns the node pool of the PipelineTask named pipelineTaskName,
+// whose Task has the given tags, or nil if it has none. The node pools naming
+// the PipelineTask take precedence over the ones matching its tags, and the
+// default node pool applies to the PipelineTasks matched by no node pool.
+func (pr *PipelineRun) GetNodePool(pipelineTaskName string, tags []string) *NodePoolSpec {
+	for i, np := range pr.Spec.NodePools {
+		for _, name := range np.PipelineTasks {
+			if name == pipelineTaskName {
+				return &pr.Spec.NodePools[i]
+			}
+		}
+	}
+	for i, np := range pr.Spec.NodePools {
+		for _, tag := range np.Tags {
+			for _, t := range tags {
+				if tag == t {
+					return &pr.Spec.NodePools[i]
+				}
+			}
+		}
+	}
+	for i, np := range pr.Spec.NodePools {
+		if np.Name == pr.Spec.DefaultNodePool {
+			return &pr.Spec.NodePools[i]
+		}
+	}
+	return nil
+}
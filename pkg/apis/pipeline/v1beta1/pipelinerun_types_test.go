@@ -419,3 +419,60 @@ func TestPipelineRunGetPodSpec(t *testing.T) {
 		}
 	}
 }
+
+func TestPipelineRunGetNodePool(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "prs"},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name: "build",
+				Tags: []string{"build", "image-build"},
+			}, {
+				Name:          "deploy",
+				PipelineTasks: []string{"deploy", "build-chart"},
+				Tags:          []string{"deploy"},
+			}, {
+				Name: "default",
+			}},
+			DefaultNodePool: "default",
+		},
+	}
+	for _, tc := range []struct {
+		name             string
+		pipelineTaskName string
+		tags             []string
+		want             string
+	}{{
+		name:             "matched by name",
+		pipelineTaskName: "deploy",
+		want:             "deploy",
+	}, {
+		name:             "matched by tag",
+		pipelineTaskName: "compile",
+		tags:             []string{"test", "image-build"},
+		want:             "build",
+	}, {
+		name:             "name takes precedence over tags",
+		pipelineTaskName: "build-chart",
+		tags:             []string{"build"},
+		want:             "deploy",
+	}, {
+		name:             "default node pool",
+		pipelineTaskName: "test",
+		tags:             []string{"test"},
+		want:             "default",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			np := pr.GetNodePool(tc.pipelineTaskName, tc.tags)
+			if np == nil || np.Name != tc.want {
+				t.Errorf("GetNodePool() = %v, want node pool %q", np, tc.want)
+			}
+		})
+	}
+
+	pr.Spec.DefaultNodePool = ""
+	if np := pr.GetNodePool("test", nil); np != nil {
+		t.Errorf("GetNodePool() = %v, want nil without default node pool", np)
+	}
+}
@@ -19,9 +19,15 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
@@ -32,7 +38,16 @@ func (pr *PipelineRun) Validate(ctx context.Context) *apis.FieldError {
 	if err := validate.ObjectMetadata(pr.GetObjectMeta()).ViaField("metadata"); err != nil {
 		return err
 	}
-	return pr.Spec.Validate(ctx)
+	if err := pr.Spec.Validate(ctx); err != nil {
+		return err
+	}
+	for i, ref := range pr.Spec.TriggerOnConfigMapChange {
+		if ref.Namespace != "" && ref.Namespace != pr.Namespace {
+			return apis.ErrInvalidValue(fmt.Sprintf("ConfigMap must be in the namespace of the PipelineRun %q", pr.Namespace), "namespace").
+				ViaFieldIndex("spec.triggerOnConfigMapChange", i)
+		}
+	}
+	return nil
 }
 
 // Validate pipelinerun spec
@@ -51,6 +66,12 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("spec.pipelineref.name", "spec.pipelinespec")
 	}
 
+	if ps.PipelineRef != nil {
+		if err := validateRefNameParams(ctx, ps.PipelineRef.Name, ps.Params, "spec.pipelineref.name"); err != nil {
+			return err
+		}
+	}
+
 	// Validate PipelineSpec if it's present
 	if ps.PipelineSpec != nil {
 		if err := ps.PipelineSpec.Validate(ctx); err != nil {
@@ -71,6 +92,55 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if err := validatePodTemplate(ps.PodTemplate).ViaField("spec.podTemplate"); err != nil {
+		return err
+	}
+	for i, trs := range ps.TaskRunSpecs {
+		if err := validatePodTemplate(trs.TaskPodTemplate).ViaField("taskPodTemplate").ViaFieldIndex("spec.taskRunSpecs", i); err != nil {
+			return err
+		}
+	}
+
+	if ps.Concurrency != nil {
+		if err := ps.Concurrency.Validate(ctx); err != nil {
+			return err.ViaField("spec.concurrency")
+		}
+	}
+
+	if err := validateConfigMapTriggers(ctx, ps.TriggerOnConfigMapChange).ViaField("spec.triggerOnConfigMapChange"); err != nil {
+		return err
+	}
+
+	if ps.IsolatedNamespace {
+		if err := validateIsolatedNamespace(ctx, ps); err != nil {
+			return err
+		}
+	}
+
+	if ps.CheckpointInterval != nil {
+		if err := validateCheckpointInterval(ctx, ps.CheckpointInterval); err != nil {
+			return err.ViaField("spec.checkpointInterval")
+		}
+	}
+
+	if err := validateNetworkPolicies(ctx, ps.NetworkPolicies).ViaField("spec.networkPolicies"); err != nil {
+		return err
+	}
+
+	if err := validateNodePools(ctx, ps.NodePools, ps.DefaultNodePool).ViaField("spec"); err != nil {
+		return err
+	}
+
+	if ps.FailurePolicy != "" {
+		if err := validateFailurePolicy(ctx, ps.FailurePolicy); err != nil {
+			return err.ViaField("spec.failurePolicy")
+		}
+	}
+
+	if err := validateParamsAppend(ctx, ps.Params).ViaField("spec"); err != nil {
+		return err
+	}
+
 	if ps.Workspaces != nil {
 		wsNames := make(map[string]int)
 		for idx, ws := range ps.Workspaces {
@@ -78,6 +148,11 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 			if err := ws.Validate(ctx).ViaField(field); err != nil {
 				return err
 			}
+			if ws.CleanupAfterCompletion {
+				// The claim is shared by every TaskRun of the Pipeline, so it
+				// cannot be removed when any single one of them completes.
+				return apis.ErrDisallowedFields(field + ".cleanupAfterCompletion")
+			}
 			if prevIdx, alreadyExists := wsNames[ws.Name]; alreadyExists {
 				return &apis.FieldError{
 					Message: fmt.Sprintf("workspace %q provided by pipelinerun more than once, at index %d and %d", ws.Name, prevIdx, idx),
@@ -86,7 +161,174 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 			}
 			wsNames[ws.Name] = idx
 		}
+		if err := ValidateWorkspaceBindingCollisions(ps.Workspaces).ViaField("spec"); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// Validate checks that the concurrency key is set and that the policy is known.
+func (c *PipelineRunConcurrency) Validate(ctx context.Context) *apis.FieldError {
+	if err := ValidateEnabledAPIFields(ctx, "concurrency", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	if c.Key == "" {
+		return apis.ErrMissingField("key")
+	}
+	switch c.Policy {
+	case "", ConcurrencyPolicyQueue, ConcurrencyPolicyCancelPrevious:
+		return nil
+	default:
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s or %s", c.Policy, ConcurrencyPolicyQueue, ConcurrencyPolicyCancelPrevious), "policy")
+	}
+}
+
+// validateIsolatedNamespace checks that a PipelineRun running in an isolated
+// namespace only uses what can be provided in that namespace.
+func validateIsolatedNamespace(ctx context.Context, ps *PipelineRunSpec) *apis.FieldError {
+	if err := ValidateEnabledAPIFields(ctx, "isolatedNamespace", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{"spec.isolatedNamespace"}
+		return err
+	}
+	if len(ps.Resources) > 0 {
+		return apis.ErrGeneric("PipelineResources can't be used by a PipelineRun running in an isolated namespace", "spec.resources")
+	}
+	for i, ws := range ps.Workspaces {
+		if ws.PersistentVolumeClaim != nil {
+			// Claims can't be mounted from another namespace, a new one has to be created from a template.
+			return apis.ErrGeneric("persistentVolumeClaim workspaces can't be used by a PipelineRun running in an isolated namespace, use a volumeClaimTemplate instead",
+				fmt.Sprintf("spec.workspaces[%d].persistentVolumeClaim", i))
+		}
+	}
+	return nil
+}
+
+// validateCheckpointInterval checks that the execution state of a PipelineRun
+// is saved after a positive interval.
+func validateCheckpointInterval(ctx context.Context, interval *metav1.Duration) *apis.FieldError {
+	if err := ValidateEnabledAPIFields(ctx, "checkpointInterval", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	if interval.Duration <= 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be > 0", interval.Duration), apis.CurrentField)
+	}
+	return nil
+}
+
+// validateFailurePolicy checks that the failure policy of a PipelineRun is known.
+func validateFailurePolicy(ctx context.Context, policy PipelineRunFailurePolicy) *apis.FieldError {
+	if err := ValidateEnabledAPIFields(ctx, "failurePolicy", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	switch policy {
+	case PipelineRunFailurePolicyFailFast, PipelineRunFailurePolicyCompleteAll:
+		return nil
+	default:
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s or %s", policy, PipelineRunFailurePolicyFailFast, PipelineRunFailurePolicyCompleteAll), apis.CurrentField)
+	}
+}
+
+// validateConfigMapTriggers checks that the ConfigMaps triggering new
+// PipelineRuns are named, once each.
+func validateConfigMapTriggers(ctx context.Context, refs []corev1.ObjectReference) *apis.FieldError {
+	if len(refs) == 0 {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "triggerOnConfigMapChange", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	seen := sets.NewString()
+	for i, ref := range refs {
+		switch {
+		case ref.Name == "":
+			return apis.ErrMissingField("name").ViaIndex(i)
+		case ref.Kind != "" && ref.Kind != "ConfigMap":
+			return apis.ErrInvalidValue(ref.Kind+" should be ConfigMap", "kind").ViaIndex(i)
+		case ref.APIVersion != "" && ref.APIVersion != "v1":
+			return apis.ErrInvalidValue(ref.APIVersion+" should be v1", "apiVersion").ViaIndex(i)
+		case seen.Has(ref.Name):
+			return apis.ErrMultipleOneOf("name").ViaIndex(i)
+		}
+		seen.Insert(ref.Name)
+	}
+	return nil
+}
+
+// validateNetworkPolicies checks that the rules allowing traffic between the
+// TaskRuns of a PipelineRun are named once each, and name the PipelineTasks
+// sending and receiving the traffic.
+func validateNetworkPolicies(ctx context.Context, rules []NetworkPolicyRule) *apis.FieldError {
+	if len(rules) == 0 {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "networkPolicies", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	seen := sets.NewString()
+	for i, rule := range rules {
+		switch {
+		case rule.Name == "":
+			return apis.ErrMissingField("name").ViaIndex(i)
+		case seen.Has(rule.Name):
+			return apis.ErrMultipleOneOf("name").ViaIndex(i)
+		case rule.To == "":
+			return apis.ErrMissingField("to").ViaIndex(i)
+		case len(rule.From) == 0:
+			return apis.ErrMissingField("from").ViaIndex(i)
+		}
+		// The rule is named after the NetworkPolicy created for it.
+		if errs := validation.IsDNS1123Label(rule.Name); len(errs) != 0 {
+			return apis.ErrInvalidValue(strings.Join(errs, ","), "name").ViaIndex(i)
+		}
+		for j, from := range rule.From {
+			if from == "" {
+				return apis.ErrInvalidValue("PipelineTask names can't be empty", fmt.Sprintf("from[%d]", j)).ViaIndex(i)
+			}
+		}
+		seen.Insert(rule.Name)
+	}
+	return nil
+}
+
+// validateNodePools checks that the node pools are named once each, and select
+// nodes with valid labels, and that the default node pool is one of them.
+func validateNodePools(ctx context.Context, pools []NodePoolSpec, defaultPool string) *apis.FieldError {
+	if len(pools) == 0 && defaultPool == "" {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "nodePools", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{"nodePools"}
+		return err
+	}
+	seen := sets.NewString()
+	for i, np := range pools {
+		switch {
+		case np.Name == "":
+			return apis.ErrMissingField("name").ViaFieldIndex("nodePools", i)
+		case seen.Has(np.Name):
+			return apis.ErrMultipleOneOf("name").ViaFieldIndex("nodePools", i)
+		case len(np.NodeSelector) == 0:
+			return apis.ErrMissingField("nodeSelector").ViaFieldIndex("nodePools", i)
+		}
+		for key, value := range np.NodeSelector {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				return apis.ErrInvalidKeyName(key, "nodeSelector", strings.Join(errs, ",")).ViaFieldIndex("nodePools", i)
+			}
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				return apis.ErrInvalidValue(fmt.Sprintf("%s: %s", value, strings.Join(errs, ",")), "nodeSelector."+key).ViaFieldIndex("nodePools", i)
+			}
+		}
+		seen.Insert(np.Name)
+	}
+	if defaultPool != "" && !seen.Has(defaultPool) {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be the name of a node pool", defaultPool), "defaultNodePool")
+	}
+	return nil
+}
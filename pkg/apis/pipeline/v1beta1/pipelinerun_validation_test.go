@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
@@ -107,6 +108,24 @@ func TestPipelineRun_Invalidate(t *testing.T) {
 	}
 }
 
+func TestPipelineRun_Invalidate_ConfigMapTriggerNamespace(t *testing.T) {
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "prname"},
+			TriggerOnConfigMapChange: []corev1.ObjectReference{
+				{Name: "settings", Namespace: "foo"},
+				{Name: "other", Namespace: "bar"},
+			},
+		},
+	}
+	want := apis.ErrInvalidValue(`ConfigMap must be in the namespace of the PipelineRun "foo"`, "spec.triggerOnConfigMapChange[1].namespace")
+	err := pr.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields))
+	if d := cmp.Diff(want.Error(), err.Error()); d != "" {
+		t.Errorf("PipelineRun.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestPipelineRun_Validate(t *testing.T) {
 	tests := []struct {
 		name string
@@ -211,16 +230,258 @@ func TestPipelineRunSpec_Invalidate(t *testing.T) {
 			Message: "expected exactly one, got neither",
 			Paths: []string{
 				"spec.workspaces[0].configmap",
+				"spec.workspaces[0].csi",
 				"spec.workspaces[0].emptydir",
 				"spec.workspaces[0].persistentvolumeclaim",
 				"spec.workspaces[0].secret",
 				"spec.workspaces[0].volumeclaimtemplate",
 			},
+			Details: `workspace binding "ws" must use exactly one volume source`,
+		},
+	}, {
+		name: "workspaces must not mount the same claim at the same subPath",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:                  "source",
+				SubPath:               "src/",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "cache",
+				SubPath:               "./src",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}},
+		},
+		wantErr: &apis.FieldError{
+			Message: `workspace bindings "source" and "cache" both mount persistentVolumeClaim "shared" at subPath "src"`,
+			Paths:   []string{"spec.workspaces"},
+		},
+	}, {
+		name: "workspaces can't be cleaned up after completion",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:                   "ws",
+				PersistentVolumeClaim:  &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+				CleanupAfterCompletion: true,
+			}},
+		},
+		wantErr: apis.ErrDisallowedFields("spec.workspaces[0].cleanupAfterCompletion"),
+	}, {
+		name: "task pod template volume colliding with tekton volumes",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			TaskRunSpecs: []v1beta1.PipelineTaskRunSpec{{
+				PipelineTaskName: "mytask",
+				TaskPodTemplate: &v1beta1.PodTemplate{
+					Volumes: []corev1.Volume{{
+						Name:         "tekton-internal-tools",
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					}},
+				},
+			}},
+		},
+		wantErr: &apis.FieldError{
+			Message: `volume name "tekton-internal-tools" cannot start with "tekton-internal-"`,
+			Paths:   []string{"spec.taskRunSpecs[0].taskPodTemplate.volumes[0].name"},
+		},
+	}, {
+		name: "concurrency without key",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			Concurrency: &v1beta1.PipelineRunConcurrency{},
+		},
+		wantErr: apis.ErrMissingField("spec.concurrency.key"),
+	}, {
+		name: "concurrency with unknown policy",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			Concurrency: &v1beta1.PipelineRunConcurrency{
+				Key:    "production",
+				Policy: "cancelNext",
+			},
 		},
+		wantErr: apis.ErrInvalidValue("cancelNext should be queue or cancelPrevious", "spec.concurrency.policy"),
+	}, {
+		name: "configmap trigger without name",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:              &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			TriggerOnConfigMapChange: []corev1.ObjectReference{{Kind: "ConfigMap"}},
+		},
+		wantErr: apis.ErrMissingField("spec.triggerOnConfigMapChange[0].name"),
+	}, {
+		name: "configmap trigger of another kind",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:              &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "settings", Kind: "Secret"}},
+		},
+		wantErr: apis.ErrInvalidValue("Secret should be ConfigMap", "spec.triggerOnConfigMapChange[0].kind"),
+	}, {
+		name: "configmap trigger of another apiVersion",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:              &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "settings", APIVersion: "v2"}},
+		},
+		wantErr: apis.ErrInvalidValue("v2 should be v1", "spec.triggerOnConfigMapChange[0].apiVersion"),
+	}, {
+		name: "configmap triggers repeated",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:              &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "settings"}, {Name: "settings", Kind: "ConfigMap"}},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.triggerOnConfigMapChange[1].name"),
+	}, {
+		name: "isolated namespace with resources",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:       &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			IsolatedNamespace: true,
+			Resources: []v1beta1.PipelineResourceBinding{{
+				Name:        "source",
+				ResourceRef: &v1beta1.PipelineResourceRef{Name: "git-resource"},
+			}},
+		},
+		wantErr: apis.ErrGeneric("PipelineResources can't be used by a PipelineRun running in an isolated namespace", "spec.resources"),
+	}, {
+		name: "isolated namespace with a persistentVolumeClaim workspace",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:       &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			IsolatedNamespace: true,
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:     "cache",
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}, {
+				Name:                  "source",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "source-pvc"},
+			}},
+		},
+		wantErr: apis.ErrGeneric("persistentVolumeClaim workspaces can't be used by a PipelineRun running in an isolated namespace, use a volumeClaimTemplate instead", "spec.workspaces[1].persistentVolumeClaim"),
+	}, {
+		name: "checkpoint interval not positive",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:        &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			CheckpointInterval: &metav1.Duration{Duration: 0},
+		},
+		wantErr: apis.ErrInvalidValue("0s should be > 0", "spec.checkpointInterval"),
+	}, {
+		name: "unknown failure policy",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef:   &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			FailurePolicy: "Retry",
+		},
+		wantErr: apis.ErrInvalidValue("Retry should be FailFast or CompleteAll", "spec.failurePolicy"),
+	}, {
+		name: "network policy without from",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NetworkPolicies: []v1beta1.NetworkPolicyRule{{
+				Name: "to-server",
+				To:   "server",
+			}},
+		},
+		wantErr: apis.ErrMissingField("spec.networkPolicies[0].from"),
+	}, {
+		name: "network policies with the same name",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NetworkPolicies: []v1beta1.NetworkPolicyRule{{
+				Name: "to-server",
+				To:   "server",
+				From: []string{"test"},
+			}, {
+				Name: "to-server",
+				To:   "server",
+				From: []string{"load-test"},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.networkPolicies[1].name"),
+	}, {
+		name: "network policy with an invalid name",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NetworkPolicies: []v1beta1.NetworkPolicyRule{{
+				Name: "To_Server",
+				To:   "server",
+				From: []string{"test"},
+			}},
+		},
+		wantErr: apis.ErrInvalidValue("a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')", "spec.networkPolicies[0].name"),
+	}, {
+		name: "node pools with the same name",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:         "build",
+				NodeSelector: map[string]string{"pool": "build"},
+			}, {
+				Name:         "build",
+				NodeSelector: map[string]string{"pool": "test"},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.nodePools[1].name"),
+	}, {
+		name: "node pool without node selector",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NodePools:   []v1beta1.NodePoolSpec{{Name: "build"}},
+		},
+		wantErr: apis.ErrMissingField("spec.nodePools[0].nodeSelector"),
+	}, {
+		name: "node pool with an invalid label key",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:         "build",
+				NodeSelector: map[string]string{"pool/": "build"},
+			}},
+		},
+		wantErr: apis.ErrInvalidKeyName("pool/", "spec.nodePools[0].nodeSelector", "name part must be non-empty,name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')"),
+	}, {
+		name: "node pool with an invalid label value",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:         "build",
+				NodeSelector: map[string]string{"pool": "build pool"},
+			}},
+		},
+		wantErr: apis.ErrInvalidValue("build pool: a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')", "spec.nodePools[0].nodeSelector.pool"),
+	}, {
+		name: "unknown default node pool",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:         "build",
+				NodeSelector: map[string]string{"pool": "build"},
+			}},
+			DefaultNodePool: "deploy",
+		},
+		wantErr: apis.ErrInvalidValue("deploy should be the name of a node pool", "spec.defaultNodePool"),
+	}, {
+		name: "string param appended to the default",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+			Params: []v1beta1.Param{{
+				Name:   "flags",
+				Value:  v1beta1.NewArrayOrString("--verbose"),
+				Append: true,
+			}},
+		},
+		wantErr: apis.ErrInvalidValue(`only array values can be appended to the default of parameter "flags"`, "spec.params[0].append"),
 	}}
 	for _, ps := range tests {
 		t.Run(ps.name, func(t *testing.T) {
-			err := ps.spec.Validate(context.Background())
+			ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+			err := ps.spec.Validate(ctx)
 			if d := cmp.Diff(ps.wantErr.Error(), err.Error()); d != "" {
 				t.Errorf("PipelineRunSpec.Validate/%s (-want, +got) = %v", ps.name, d)
 			}
@@ -244,12 +505,195 @@ func TestPipelineRunSpec_Validate(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "PipelineRun with concurrency",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			Concurrency: &v1beta1.PipelineRunConcurrency{
+				Key:    "production",
+				Policy: v1beta1.ConcurrencyPolicyCancelPrevious,
+			},
+		},
+	}, {
+		name: "PipelineRun in an isolated namespace",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			IsolatedNamespace: true,
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:                "source",
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaim{},
+			}, {
+				Name:      "settings",
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+			}},
+		},
+	}, {
+		name: "PipelineRun with a checkpoint interval",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			CheckpointInterval: &metav1.Duration{Duration: 30 * time.Second},
+		},
+	}, {
+		name: "PipelineRun completing all tasks",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			FailurePolicy: v1beta1.PipelineRunFailurePolicyCompleteAll,
+		},
+	}, {
+		name: "PipelineRun with network policies",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			NetworkPolicies: []v1beta1.NetworkPolicyRule{{
+				Name: "to-server",
+				To:   "server",
+				From: []string{"test", "load-test"},
+			}},
+		},
+	}, {
+		name: "PipelineRun with node pools",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:          "build",
+				PipelineTasks: []string{"compile"},
+				Tags:          []string{"build"},
+				NodeSelector:  map[string]string{"example.com/pool": "build"},
+			}, {
+				Name:         "default",
+				NodeSelector: map[string]string{"example.com/pool": "default"},
+			}},
+			DefaultNodePool: "default",
+		},
 	}}
 	for _, ps := range tests {
 		t.Run(ps.name, func(t *testing.T) {
-			if err := ps.spec.Validate(context.Background()); err != nil {
+			ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+			if err := ps.spec.Validate(ctx); err != nil {
 				t.Errorf("PipelineRunSpec.Validate/%s (-want, +got) = %v", ps.name, err)
 			}
 		})
 	}
 }
+
+func TestPipelineRunSpec_Invalidate_IsolatedNamespaceNotAlpha(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef:       &v1beta1.PipelineRef{Name: "pipelinerefname"},
+		IsolatedNamespace: true,
+	}
+	want := `isolatedNamespace requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.isolatedNamespace`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating an isolated namespace without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_Invalidate_CheckpointIntervalNotAlpha(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef:        &v1beta1.PipelineRef{Name: "pipelinerefname"},
+		CheckpointInterval: &metav1.Duration{Duration: time.Minute},
+	}
+	want := `checkpointInterval requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.checkpointInterval`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating a checkpoint interval without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_Invalidate_FailurePolicyNotAlpha(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef:   &v1beta1.PipelineRef{Name: "pipelinerefname"},
+		FailurePolicy: v1beta1.PipelineRunFailurePolicyCompleteAll,
+	}
+	want := `failurePolicy requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.failurePolicy`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating a failure policy without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_RefNameParams(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef: &v1beta1.PipelineRef{Name: "deploy-$(params.env)"},
+		Params:      []v1beta1.Param{{Name: "env", Value: v1beta1.NewArrayOrString("staging")}},
+	}
+	if err := spec.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields)); err != nil {
+		t.Errorf("PipelineRunSpec.Validate() = %v", err)
+	}
+
+	want := `params in the name of a reference requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.pipelineref.name`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating params in the name of the pipelineRef without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+
+	spec.Params = nil
+	want = `invalid value: "deploy-$(params.env)" references "env", which isn't a string param of the run: spec.pipelineref.name`
+	err = spec.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields))
+	if err == nil {
+		t.Fatal("Expected an error validating a missing param in the name of the pipelineRef")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_Invalidate_NetworkPoliciesNotAlpha(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+		NetworkPolicies: []v1beta1.NetworkPolicyRule{{
+			Name: "to-server",
+			To:   "server",
+			From: []string{"test"},
+		}},
+	}
+	want := `networkPolicies requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.networkPolicies`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating network policies without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_Invalidate_NodePoolsNotAlpha(t *testing.T) {
+	spec := v1beta1.PipelineRunSpec{
+		PipelineRef: &v1beta1.PipelineRef{Name: "pipelinerefname"},
+		NodePools: []v1beta1.NodePoolSpec{{
+			Name:         "build",
+			NodeSelector: map[string]string{"pool": "build"},
+		}},
+	}
+	want := `nodePools requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.nodePools`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error validating node pools without alpha fields enabled")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
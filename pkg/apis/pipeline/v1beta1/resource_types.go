@@ -51,6 +51,9 @@ const (
 
 	// PipelineResourceTypeCloudEvent indicates that this source is a cloud event URI
 	PipelineResourceTypeCloudEvent PipelineResourceType = resource.PipelineResourceTypeCloudEvent
+
+	// PipelineResourceTypeHTTP indicates that this source is a file downloaded over HTTP(S).
+	PipelineResourceTypeHTTP PipelineResourceType = resource.PipelineResourceTypeHTTP
 )
 
 // AllResourceTypes can be used for validation to check if a provided Resource type is one of the known types.
@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ParseResultJSONPath parses the JSONPath template of the result called name. The
+// template must be enclosed in curly braces, e.g. "{.image.digest}", and report
+// missing keys as errors.
+func ParseResultJSONPath(name, path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") || !strings.HasSuffix(path, "}") {
+		return nil, fmt.Errorf("JSONPath %q must be enclosed in curly braces, e.g. \"{.image.digest}\"", path)
+	}
+	j := jsonpath.New(name)
+	if err := j.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	j.AllowMissingKeys(false)
+	return j, nil
+}
@@ -38,6 +38,12 @@ const (
 	variableSubstitutionFormat = `\$\([_a-zA-Z0-9.-]+(\.[_a-zA-Z0-9.-]+)*\)`
 	// ResultNameFormat Constant used to define the the regex Result.Name should follow
 	ResultNameFormat = `^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
+	// PipelineTasksAggregateStatus is the variable resolving to the aggregate execution status
+	// of the tasks of a Pipeline, which final tasks can use
+	PipelineTasksAggregateStatus = "tasks.status"
+	// PipelineTaskStatusSuffix Constant used to define the "status" part of the variable resolving
+	// to the execution status of a pipeline task, $(tasks.<pipelineTask>.status)
+	PipelineTaskStatusSuffix = "status"
 )
 
 var variableSubstitutionRegex = regexp.MustCompile(variableSubstitutionFormat)
@@ -106,6 +112,27 @@ func GetVarSubstitutionExpressionsForPipelineResult(result PipelineResult) ([]st
 	return allExpressions, len(allExpressions) != 0
 }
 
+// GetVarSubstitutionExpressionsForStepEnvs extracts all the value between "$(" and ")"" for the env values of steps
+func GetVarSubstitutionExpressionsForStepEnvs(steps []Step) ([]string, bool) {
+	var allExpressions []string
+	for _, step := range steps {
+		for _, env := range step.Env {
+			allExpressions = append(allExpressions, validateString(env.Value)...)
+		}
+	}
+	return allExpressions, len(allExpressions) != 0
+}
+
+// GetVarSubstitutionExpressionsForWhenExpressions extracts all the value between "$(" and ")"" for the input and
+// the values of when expressions
+func GetVarSubstitutionExpressionsForWhenExpressions(wes WhenExpressions) ([]string, bool) {
+	var allExpressions []string
+	for _, v := range wes.getVariables() {
+		allExpressions = append(allExpressions, validateString(v)...)
+	}
+	return allExpressions, len(allExpressions) != 0
+}
+
 func validateString(value string) []string {
 	expressions := variableSubstitutionRegex.FindAllString(value, -1)
 	if expressions == nil {
@@ -129,3 +156,24 @@ func parseExpression(substitutionExpression string) (string, string, error) {
 	}
 	return subExpressions[1], subExpressions[3], nil
 }
+
+// pipelineTaskStatusRef returns the name of the pipeline task whose execution status is
+// referenced by the expression $(tasks.<pipelineTask>.status), if it is one.
+func pipelineTaskStatusRef(expression string) (string, bool) {
+	subExpressions := strings.Split(expression, ".")
+	if len(subExpressions) != 3 || subExpressions[0] != ResultTaskPart || subExpressions[2] != PipelineTaskStatusSuffix {
+		return "", false
+	}
+	return subExpressions[1], true
+}
+
+// UsesExecutionStatus returns true if one of the expressions references the execution status of
+// a pipeline task, or the aggregate execution status of the tasks of the Pipeline.
+func UsesExecutionStatus(expressions []string) bool {
+	for _, expression := range expressions {
+		if _, ok := pipelineTaskStatusRef(expression); ok || expression == PipelineTasksAggregateStatus {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+/*
+ Copyright 2021 The Tekton Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestApplySidecarReplacements(t *testing.T) {
+	replacements := map[string]string{
+		"replace.me": "replaced!",
+	}
+
+	arrayReplacements := map[string][]string{
+		"array.replace.me": {"val1", "val2"},
+	}
+
+	s := v1beta1.Sidecar{
+		Script: "$(replace.me)",
+		Container: corev1.Container{
+			Name:    "$(replace.me)",
+			Image:   "$(replace.me)",
+			Command: []string{"$(array.replace.me)"},
+			Args:    []string{"$(array.replace.me)"},
+			Env: []corev1.EnvVar{{
+				Name:  "not_me",
+				Value: "$(replace.me)",
+			}},
+		},
+	}
+
+	expected := v1beta1.Sidecar{
+		Script: "replaced!",
+		Container: corev1.Container{
+			Name:    "replaced!",
+			Image:   "replaced!",
+			Command: []string{"val1", "val2"},
+			Args:    []string{"val1", "val2"},
+			Env: []corev1.EnvVar{{
+				Name:  "not_me",
+				Value: "replaced!",
+			}},
+		},
+	}
+	v1beta1.ApplySidecarReplacements(&s, replacements, arrayReplacements)
+	if d := cmp.Diff(s, expected); d != "" {
+		t.Errorf("Sidecar replacements failed: %s", d)
+	}
+}
@@ -22,5 +22,9 @@ import (
 
 func ApplyStepReplacements(step *Step, stringReplacements map[string]string, arrayReplacements map[string][]string) {
 	step.Script = substitution.ApplyReplacements(step.Script, stringReplacements)
+	for i := range step.EnvFromExternalSecrets {
+		step.EnvFromExternalSecrets[i].Name = substitution.ApplyReplacements(step.EnvFromExternalSecrets[i].Name, stringReplacements)
+		step.EnvFromExternalSecrets[i].Prefix = substitution.ApplyReplacements(step.EnvFromExternalSecrets[i].Prefix, stringReplacements)
+	}
 	ApplyContainerReplacements(&step.Container, stringReplacements, arrayReplacements)
 }
@@ -77,6 +77,10 @@ func TestApplyStepReplacements(t *testing.T) {
 				SubPath:   "$(replace.me)",
 			}},
 		},
+		EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{
+			Name:   "$(replace.me)",
+			Prefix: "$(replace.me)",
+		}},
 	}
 
 	expected := v1beta1.Step{
@@ -123,6 +127,10 @@ func TestApplyStepReplacements(t *testing.T) {
 				SubPath:   "replaced!",
 			}},
 		},
+		EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{
+			Name:   "replaced!",
+			Prefix: "replaced!",
+		}},
 	}
 	v1beta1.ApplyStepReplacements(&s, replacements, arrayReplacements)
 	if d := cmp.Diff(s, expected); d != "" {
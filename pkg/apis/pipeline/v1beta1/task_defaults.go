@@ -19,6 +19,8 @@ package v1beta1
 import (
 	"context"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
@@ -26,6 +28,21 @@ var _ apis.Defaultable = (*Task)(nil)
 
 func (t *Task) SetDefaults(ctx context.Context) {
 	t.Spec.SetDefaults(ctx)
+	setTagLabels(&t.ObjectMeta, t.Spec.Tags)
+}
+
+// setTagLabels adds a label for each of the tags, so tasks can be
+// selected by tag, e.g. with -l tags.tekton.dev/<tag>.
+func setTagLabels(meta *metav1.ObjectMeta, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	for _, tag := range tags {
+		meta.Labels[pipeline.TaskTagLabelKeyPrefix+tag] = "true"
+	}
 }
 
 // SetDefaults set any defaults for the task spec
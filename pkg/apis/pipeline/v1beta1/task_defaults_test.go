@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_SetDefaults(t *testing.T) {
+	cases := []struct {
+		desc string
+		task *v1beta1.Task
+		want *v1beta1.Task
+	}{{
+		desc: "empty task must not change after setting defaults",
+		task: &v1beta1.Task{},
+		want: &v1beta1.Task{},
+	}, {
+		desc: "tags are added as labels",
+		task: &v1beta1.Task{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+			Spec:       v1beta1.TaskSpec{Tags: []string{"build", "image"}},
+		},
+		want: &v1beta1.Task{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				"app":                   "foo",
+				"tags.tekton.dev/build": "true",
+				"tags.tekton.dev/image": "true",
+			}},
+			Spec: v1beta1.TaskSpec{Tags: []string{"build", "image"}},
+		},
+	}}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.task.SetDefaults(context.Background())
+			if d := cmp.Diff(tc.want, tc.task); d != "" {
+				t.Errorf("Task.SetDefaults() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestClusterTask_SetDefaults(t *testing.T) {
+	ct := &v1beta1.ClusterTask{Spec: v1beta1.TaskSpec{Tags: []string{"build"}}}
+	want := &v1beta1.ClusterTask{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tags.tekton.dev/build": "true"}},
+		Spec:       v1beta1.TaskSpec{Tags: []string{"build"}},
+	}
+	ct.SetDefaults(context.Background())
+	if d := cmp.Diff(want, ct); d != "" {
+		t.Errorf("ClusterTask.SetDefaults() %s", diff.PrintWantGot(d))
+	}
+}
@@ -26,10 +26,21 @@ const (
 	TaskRunResultType ResultType = "TaskRunResult"
 	// PipelineResourceResultType default pipeline result value
 	PipelineResourceResultType ResultType = "PipelineResourceResult"
+	// InternalTektonResultType is the type of the values written to the termination message
+	// by the entrypoint for the controller, which aren't results of the Task
+	InternalTektonResultType ResultType = "InternalTektonResult"
 	// UnknownResultType default unknown result type value
 	UnknownResultType ResultType = ""
 )
 
+// ResultExtractionErrorKey is the key of the InternalTektonResultType value holding the
+// reason why a result couldn't be extracted with its JSONPath.
+const ResultExtractionErrorKey = "ResultExtractionError"
+
+// StepTimeoutExceededKey is the key of the InternalTektonResultType value holding the
+// timeout of a Step which was stopped because it ran for longer.
+const StepTimeoutExceededKey = "StepTimeoutExceeded"
+
 // +genclient
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -81,6 +92,15 @@ type TaskSpec struct {
 	// +optional
 	Description string `json:"description,omitempty"`
 
+	// Author is the person or organization maintaining the task.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// Tags are keywords describing the task. Each tag is copied to a
+	// tags.tekton.dev/<tag> label so tasks can be filtered by tag.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
 	// Steps are the steps of the build; each step is run sequentially with the
 	// source mounted into /workspace.
 	Steps []Step `json:"steps,omitempty"`
@@ -97,11 +117,47 @@ type TaskSpec struct {
 	// the steps start and end after the steps complete.
 	Sidecars []Sidecar `json:"sidecars,omitempty"`
 
+	// InitContainers are run to completion, in order, before any of the Task's
+	// steps and sidecars start, ahead of the init containers added by Tekton.
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
 	// Workspaces are the volumes that this Task requires.
 	Workspaces []WorkspaceDeclaration `json:"workspaces,omitempty"`
 
 	// Results are values that this Task can output
 	Results []TaskResult `json:"results,omitempty"`
+
+	// InputValidation validates the params of the TaskRuns of this Task
+	// against a JSON Schema when they are admitted.
+	// +optional
+	InputValidation *InputValidation `json:"inputValidation,omitempty"`
+
+	// Platforms are the platforms the steps are run on, in the "os/arch" form,
+	// e.g. "linux/arm64". A TaskRun runs one Pod per platform, on a node of that
+	// platform, and succeeds once all of them succeed.
+	// +optional
+	Platforms []string `json:"platforms,omitempty"`
+
+	// Hooks are shell script lines run in the container of each of the steps,
+	// before and after the step.
+	// +optional
+	Hooks *TaskHooks `json:"hooks,omitempty"`
+}
+
+// TaskHooks are shell script lines run by a shell started before and after
+// the command of each Step, in the same container, so that they share its
+// filesystem and environment. The image of each Step must provide sh.
+type TaskHooks struct {
+	// PreStep lines are run before each Step. The Step isn't run if they
+	// exit with a non-zero exit code.
+	// +optional
+	PreStep []string `json:"preStep,omitempty"`
+
+	// PostStep lines are run after each Step, whether it failed or not, with
+	// its exit code in $code.
+	// +optional
+	PostStep []string `json:"postStep,omitempty"`
 }
 
 // TaskResult used to describe the results of a task
@@ -112,6 +168,21 @@ type TaskResult struct {
 	// Description is a human-readable description of the result
 	// +optional
 	Description string `json:"description"`
+
+	// JSONPath is a JSONPath template, e.g. "{.image.digest}", used to extract the
+	// value of the result from the JSON document written to its file by a Step.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Aliases are former names of the result which can still be used to reference it,
+	// so that renaming a result doesn't break the Pipelines consuming it.
+	// +optional
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Default is the value of the result when the PipelineTask running the Task
+	// is skipped by its when expressions with the Task scope.
+	// +optional
+	Default *string `json:"default,omitempty"`
 }
 
 // Step embeds the Container type, which allows it to include fields not
@@ -123,6 +194,114 @@ type Step struct {
 	//
 	// If Script is not empty, the Step cannot have an Command or Args.
 	Script string `json:"script,omitempty"`
+
+	// EnvFromExternalSecrets lists the External Secrets Operator ExternalSecrets
+	// whose Secret populates the environment variables of the Step, as a secretRef
+	// in envFrom would. They are resolved to their Secret when the Pod is created.
+	// +optional
+	EnvFromExternalSecrets []ExternalSecretEnvSource `json:"envFromExternalSecrets,omitempty"`
+
+	// Hermetic runs the Step without network access, so that all its inputs
+	// come from the resources and workspaces of the Task.
+	// +optional
+	Hermetic bool `json:"hermetic,omitempty"`
+
+	// GPU requests GPUs for the Step. They are added to the limits of its
+	// resources, as GPUs can't be overcommitted. At most one Step of a Task
+	// can request GPUs.
+	// +optional
+	GPU *StepGPU `json:"gpu,omitempty"`
+
+	// ForEach runs the Step once for each element of an array parameter of
+	// the Task, in parallel. The next Step starts once all of them completed.
+	// +optional
+	ForEach *StepForEach `json:"forEach,omitempty"`
+
+	// SeccompProfile is the seccomp profile filtering the system calls of the
+	// Step. It is set with the seccomp annotation of the Pod for the container
+	// of the Step.
+	// +optional
+	SeccompProfile *SeccompProfile `json:"seccompProfile,omitempty"`
+}
+
+// StepForEach runs a Step once for each element of an array parameter. In
+// each iteration, $(forEach.item) is replaced by the element and
+// $(forEach.index) by its index. The results written by the iterations are
+// merged into a JSON array per result, in the order of the elements.
+type StepForEach struct {
+	// ParamName is the name of the array parameter of the Task to iterate over.
+	ParamName string `json:"paramName"`
+}
+
+// SeccompProfileType is the kind of seccomp profile of a Step.
+type SeccompProfileType string
+
+const (
+	// SeccompProfileTypeUnconfined runs the Step without seccomp filtering.
+	SeccompProfileTypeUnconfined SeccompProfileType = "Unconfined"
+	// SeccompProfileTypeRuntimeDefault filters the system calls of the Step with
+	// the default profile of the container runtime.
+	SeccompProfileTypeRuntimeDefault SeccompProfileType = "RuntimeDefault"
+	// SeccompProfileTypeLocalhost filters the system calls of the Step with a
+	// profile installed on the node.
+	SeccompProfileTypeLocalhost SeccompProfileType = "Localhost"
+)
+
+// SeccompProfile is the seccomp profile of a Step. It mirrors the
+// SeccompProfile of containers in Kubernetes 1.19+.
+type SeccompProfile struct {
+	// Type of the profile, "Unconfined", "RuntimeDefault" or "Localhost".
+	Type SeccompProfileType `json:"type"`
+
+	// LocalhostProfile is the path of the profile file on the node, relative to
+	// the seccomp profile root of the kubelet. It must be set for the
+	// "Localhost" type only.
+	// +optional
+	LocalhostProfile *string `json:"localhostProfile,omitempty"`
+}
+
+// GPUVendor is the vendor of the GPUs requested by a Step, which determines
+// the name of the extended resource they are requested as.
+type GPUVendor string
+
+const (
+	// GPUVendorNVIDIA requests NVIDIA GPUs, as the nvidia.com/gpu resource.
+	GPUVendorNVIDIA GPUVendor = "nvidia"
+	// GPUVendorAMD requests AMD GPUs, as the amd.com/gpu resource.
+	GPUVendorAMD GPUVendor = "amd"
+)
+
+// StepGPU is a request of GPUs for a Step.
+type StepGPU struct {
+	// Vendor of the GPUs, "nvidia" or "amd". Defaults to "nvidia".
+	// +optional
+	Vendor GPUVendor `json:"vendor,omitempty"`
+
+	// Count is the number of GPUs the Step needs.
+	Count int64 `json:"count"`
+}
+
+// ResourceName returns the name of the extended resource the GPUs are requested as.
+func (g StepGPU) ResourceName() corev1.ResourceName {
+	if g.Vendor == GPUVendorAMD {
+		return "amd.com/gpu"
+	}
+	return "nvidia.com/gpu"
+}
+
+// ExternalSecretEnvSource selects an External Secrets Operator ExternalSecret
+// to populate the environment variables of a Step with.
+type ExternalSecretEnvSource struct {
+	// Name of the ExternalSecret in the namespace of the TaskRun.
+	Name string `json:"name"`
+
+	// Prefix is an optional identifier to prepend to each key in the Secret.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Optional specifies whether the ExternalSecret and its Secret may be missing.
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
 }
 
 // Sidecar embeds the Container type, which allows it to include fields not
@@ -134,6 +313,12 @@ type Sidecar struct {
 	//
 	// If Script is not empty, the Step cannot have an Command or Args.
 	Script string `json:"script,omitempty"`
+
+	// RestartPolicy of the sidecar. One of Always or OnFailure.
+	// Sidecars with OnFailure are restarted when they exit with a non-zero exit code.
+	// Always, like leaving it unset, doesn't restart sidecars that exit.
+	// +optional
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -155,6 +340,11 @@ type TaskRef struct {
 	// API version of the referent
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
+	// Bundle is the reference of an OCI image, a Tekton Bundle, containing the
+	// referenced Task. It is fetched at runtime instead of the Task installed
+	// in the cluster.
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
 }
 
 // Check that Pipeline may be validated and defaulted.
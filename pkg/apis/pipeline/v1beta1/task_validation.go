@@ -22,6 +22,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
 	"github.com/tektoncd/pipeline/pkg/substitution"
 	corev1 "k8s.io/api/core/v1"
@@ -32,6 +34,17 @@ import (
 
 var _ apis.Validatable = (*Task)(nil)
 
+// maxDescriptionLength is the maximum number of characters in the description of a Task.
+const maxDescriptionLength = 1024
+
+// reservedMountPath is the directory Tekton mounts its internal volumes under, such as the
+// one results are written to. Only pipeline.HomeDir can be mounted there by users.
+const reservedMountPath = "/tekton"
+
+// minServiceAccountTokenExpirationSeconds is the shortest validity the API server accepts
+// for projected service account tokens.
+const minServiceAccountTokenExpirationSeconds = 600
+
 func (t *Task) Validate(ctx context.Context) *apis.FieldError {
 	if err := validate.ObjectMetadata(t.GetObjectMeta()); err != nil {
 		return err.ViaField("metadata")
@@ -44,9 +57,15 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 	if len(ts.Steps) == 0 {
 		return apis.ErrMissingField("steps")
 	}
+	if err := validateTaskMetadata(ts.Description, ts.Tags); err != nil {
+		return err
+	}
 	if err := ValidateVolumes(ts.Volumes).ViaField("volumes"); err != nil {
 		return err
 	}
+	if err := validateServiceAccountTokenProjections(ts.Volumes).ViaField("volumes"); err != nil {
+		return err
+	}
 	if err := ValidateDeclaredWorkspaces(ts.Workspaces, ts.Steps, ts.StepTemplate); err != nil {
 		return err
 	}
@@ -62,6 +81,36 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if err := validateStepsForEach(ts.Steps, ts.Params).ViaField("steps"); err != nil {
+		return err
+	}
+
+	if err := validateSeccompProfiles(ctx, ts.Steps).ViaField("steps"); err != nil {
+		return err
+	}
+
+	if err := validateSidecars(ts.Sidecars).ViaField("sidecars"); err != nil {
+		return err
+	}
+
+	if err := validateInitContainers(ts.InitContainers).ViaField("initContainers"); err != nil {
+		return err
+	}
+
+	if err := validatePlatforms(ts.Platforms).ViaField("platforms"); err != nil {
+		return err
+	}
+
+	if err := ts.ValidateEnabledAPIFields(ctx); err != nil {
+		return err
+	}
+
+	if ts.InputValidation != nil {
+		if err := ts.InputValidation.Validate(ctx).ViaField("inputValidation"); err != nil {
+			return err
+		}
+	}
+
 	// Validate Resources declaration
 	if err := ts.Resources.Validate(ctx); err != nil {
 		return err
@@ -91,6 +140,10 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if err := validateWorkspaceVariables(ts.Steps, ts.Workspaces); err != nil {
+		return err
+	}
+
 	if err := ValidateResults(ts.Results); err != nil {
 		return err
 	}
@@ -99,6 +152,10 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if err := validateResultVariables(ts.Steps, ts.Results); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -107,8 +164,39 @@ func ValidateResults(results []TaskResult) *apis.FieldError {
 		if !resultNameFormatRegex.MatchString(result.Name) {
 			return apis.ErrInvalidKeyName(result.Name, fmt.Sprintf("results[%d].name", index), fmt.Sprintf("Name must consist of alphanumeric characters, '-', '_', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my-name',  or 'my_name', regex used for validation is '%s')", ResultNameFormat))
 		}
+		if result.JSONPath != "" {
+			if _, err := ParseResultJSONPath(result.Name, result.JSONPath); err != nil {
+				return apis.ErrInvalidValue(err.Error(), fmt.Sprintf("results[%d].jsonPath", index))
+			}
+		}
+		for i, alias := range result.Aliases {
+			if !resultNameFormatRegex.MatchString(alias) {
+				return apis.ErrInvalidKeyName(alias, fmt.Sprintf("results[%d].aliases[%d]", index, i), fmt.Sprintf("Alias must match the result name format '%s'", ResultNameFormat))
+			}
+		}
 	}
 
+	return ValidateResultAliases(results)
+}
+
+// ValidateResultAliases checks that the aliases of the results don't collide with the
+// name or the aliases of any result, as a reference to them would be ambiguous.
+func ValidateResultAliases(results []TaskResult) *apis.FieldError {
+	seen := make(map[string]string, len(results))
+	for _, result := range results {
+		seen[result.Name] = result.Name
+	}
+	for index, result := range results {
+		for i, alias := range result.Aliases {
+			if other, ok := seen[alias]; ok {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("alias %q of result %q collides with result %q", alias, result.Name, other),
+					Paths:   []string{fmt.Sprintf("results[%d].aliases[%d]", index, i)},
+				}
+			}
+			seen[alias] = result.Name
+		}
+	}
 	return nil
 }
 
@@ -137,6 +225,13 @@ func ValidateDeclaredWorkspaces(workspaces []WorkspaceDeclaration, steps []Step,
 			}
 		}
 		wsNames.Insert(w.Name)
+		// Workspaces must not be mounted where Tekton mounts its internal volumes
+		if isReservedMountPath(w.GetMountPath()) {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("workspace %q cannot be mounted under %s/ (mounted at %q)", w.Name, reservedMountPath, w.GetMountPath()),
+				Paths:   []string{"workspaces.mountpath"},
+			}
+		}
 		// Workspaces must not try to use mount paths that are already used
 		mountPath := filepath.Clean(w.GetMountPath())
 		if _, ok := mountPaths[mountPath]; ok {
@@ -150,6 +245,16 @@ func ValidateDeclaredWorkspaces(workspaces []WorkspaceDeclaration, steps []Step,
 	return nil
 }
 
+// isReservedMountPath returns true if p is reservedMountPath or a path under it, other
+// than pipeline.HomeDir and the paths under it.
+func isReservedMountPath(p string) bool {
+	p = filepath.Clean(p)
+	if p == pipeline.HomeDir || strings.HasPrefix(p, pipeline.HomeDir+"/") {
+		return false
+	}
+	return p == reservedMountPath || strings.HasPrefix(p, reservedMountPath+"/")
+}
+
 func ValidateVolumes(volumes []corev1.Volume) *apis.FieldError {
 	// Task must not have duplicate volume names.
 	vols := sets.NewString()
@@ -165,9 +270,113 @@ func ValidateVolumes(volumes []corev1.Volume) *apis.FieldError {
 	return nil
 }
 
+// validateServiceAccountTokenProjections checks the serviceAccountToken sources of projected
+// volumes, which would otherwise only be rejected when the Pod is created.
+func validateServiceAccountTokenProjections(volumes []corev1.Volume) *apis.FieldError {
+	for i, v := range volumes {
+		if v.Projected == nil {
+			continue
+		}
+		for j, source := range v.Projected.Sources {
+			token := source.ServiceAccountToken
+			if token == nil {
+				continue
+			}
+			if token.Path == "" {
+				return apis.ErrMissingField("path").ViaField("serviceAccountToken").ViaFieldIndex("sources", j).ViaField("projected").ViaIndex(i)
+			}
+			if token.ExpirationSeconds != nil && *token.ExpirationSeconds < minServiceAccountTokenExpirationSeconds {
+				return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= %d", *token.ExpirationSeconds, minServiceAccountTokenExpirationSeconds), "expirationSeconds").
+					ViaField("serviceAccountToken").ViaFieldIndex("sources", j).ViaField("projected").ViaIndex(i)
+			}
+		}
+	}
+	return nil
+}
+
+func validateSidecars(sidecars []Sidecar) *apis.FieldError {
+	for idx, sc := range sidecars {
+		if sc.Script != "" && len(sc.Command) > 0 {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("sidecar %d script cannot be used with command", idx),
+				Paths:   []string{"script"},
+			}
+		}
+		switch sc.RestartPolicy {
+		case "", corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure:
+		default:
+			return apis.ErrInvalidValue(sc.RestartPolicy, "restartPolicy")
+		}
+	}
+	return nil
+}
+
+// validatePlatforms checks that the platforms are distinct and in the "os/arch"
+// form, each part being usable as the value of a node label.
+func validatePlatforms(platforms []string) *apis.FieldError {
+	seen := sets.NewString()
+	for idx, p := range platforms {
+		parts := strings.Split(p, "/")
+		if len(parts) != 2 || len(validation.IsDNS1123Label(parts[0])) > 0 || len(validation.IsDNS1123Label(parts[1])) > 0 {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("invalid platform %q", p),
+				Paths:   []string{""},
+				Details: `Platforms must be in the "os/arch" form, e.g. "linux/arm64"`,
+			}).ViaIndex(idx)
+		}
+		if seen.Has(p) {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("platform %q is listed more than once", p),
+				Paths:   []string{""},
+			}).ViaIndex(idx)
+		}
+		seen.Insert(p)
+	}
+	return nil
+}
+
+func validateInitContainers(initContainers []corev1.Container) *apis.FieldError {
+	// Task must not have unnamed or duplicate init container names.
+	names := sets.NewString()
+	for idx, c := range initContainers {
+		if c.Name == "" {
+			return apis.ErrMissingField("name").ViaIndex(idx)
+		}
+		if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("invalid value %q", c.Name),
+				Paths:   []string{"name"},
+				Details: "Init container name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+			}).ViaIndex(idx)
+		}
+		if names.Has(c.Name) {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("multiple init containers with same name %q", c.Name),
+				Paths:   []string{"name"},
+			}).ViaIndex(idx)
+		}
+		names.Insert(c.Name)
+
+		if c.Image == "" {
+			return apis.ErrMissingField("image").ViaIndex(idx)
+		}
+		for _, vm := range c.VolumeMounts {
+			if strings.HasPrefix(vm.Name, "tekton-internal-") {
+				return (&apis.FieldError{
+					Message: fmt.Sprintf(`init container %d volumeMount name %q cannot start with "tekton-internal-"`, idx, vm.Name),
+					Paths:   []string{"volumeMounts.name"},
+				}).ViaIndex(idx)
+			}
+		}
+	}
+	return nil
+}
+
 func validateSteps(steps []Step) *apis.FieldError {
 	// Task must not have duplicate step names.
 	names := sets.NewString()
+	// Task must not have more than one step requesting GPUs.
+	gpuStep := -1
 	for idx, s := range steps {
 		if s.Image == "" {
 			return apis.ErrMissingField("Image")
@@ -190,10 +399,9 @@ func validateSteps(steps []Step) *apis.FieldError {
 		}
 
 		for _, vm := range s.VolumeMounts {
-			if strings.HasPrefix(vm.MountPath, "/tekton/") &&
-				!strings.HasPrefix(vm.MountPath, "/tekton/home") {
+			if isReservedMountPath(vm.MountPath) {
 				return &apis.FieldError{
-					Message: fmt.Sprintf("step %d volumeMount cannot be mounted under /tekton/ (volumeMount %q mounted at %q)", idx, vm.Name, vm.MountPath),
+					Message: fmt.Sprintf("step %d volumeMount cannot be mounted under %s/ (volumeMount %q mounted at %q)", idx, reservedMountPath, vm.Name, vm.MountPath),
 					Paths:   []string{"volumeMounts.mountPath"},
 				}
 			}
@@ -204,6 +412,128 @@ func validateSteps(steps []Step) *apis.FieldError {
 				}
 			}
 		}
+
+		for i, es := range s.EnvFromExternalSecrets {
+			if es.Name == "" {
+				return apis.ErrMissingField("name").ViaFieldIndex("envFromExternalSecrets", i).ViaIndex(idx)
+			}
+		}
+
+		if s.GPU != nil {
+			if s.GPU.Count <= 0 {
+				return apis.ErrInvalidValue(s.GPU.Count, "gpu.count").ViaIndex(idx)
+			}
+			if s.GPU.Vendor != "" && s.GPU.Vendor != GPUVendorNVIDIA && s.GPU.Vendor != GPUVendorAMD {
+				return apis.ErrInvalidValue(s.GPU.Vendor, "gpu.vendor").ViaIndex(idx)
+			}
+			if gpuStep >= 0 {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("steps %d and %d both request GPUs, at most one step can", gpuStep, idx),
+					Paths:   []string{fmt.Sprintf("[%d].gpu", idx)},
+				}
+			}
+			gpuStep = idx
+		}
+	}
+	return nil
+}
+
+// validateStepsForEach checks that the steps with a forEach are named, as the
+// names of their iterations are derived from theirs, that no other step is named
+// like one of their iterations, and that they iterate over an array parameter of
+// the Task.
+func validateStepsForEach(steps []Step, params []ParamSpec) *apis.FieldError {
+	for idx, s := range steps {
+		if s.ForEach == nil {
+			continue
+		}
+		if s.Name == "" {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("step %d with a forEach must have a name", idx),
+				Paths:   []string{fmt.Sprintf("[%d].name", idx)},
+			}
+		}
+		for i, other := range steps {
+			if i != idx && isForEachIterationName(s.Name, other.Name) {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("step %d is named like an iteration of step %q, whose iterations run as %s-<index>", i, s.Name, s.Name),
+					Paths:   []string{fmt.Sprintf("[%d].name", i)},
+				}
+			}
+		}
+		if s.ForEach.ParamName == "" {
+			return apis.ErrMissingField("forEach.paramName").ViaIndex(idx)
+		}
+		var param *ParamSpec
+		for i := range params {
+			if params[i].Name == s.ForEach.ParamName {
+				param = &params[i]
+			}
+		}
+		if param == nil || param.Type != ParamTypeArray {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("step %d iterates over %q, which is not an array parameter of the Task", idx, s.ForEach.ParamName),
+				Paths:   []string{fmt.Sprintf("[%d].forEach.paramName", idx)},
+			}
+		}
+	}
+	return nil
+}
+
+// isForEachIterationName returns true if name is the name of an iteration of the
+// step with a forEach named step, i.e. <step>-<index>.
+func isForEachIterationName(step, name string) bool {
+	index := strings.TrimPrefix(name, step+"-")
+	if index == name || index == "" {
+		return false
+	}
+	for _, r := range index {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSeccompProfiles checks the seccomp profiles of the steps. The webhook
+// can't see the files on the nodes, so when "seccomp-localhost-profile-dir" is
+// set, the Localhost profiles must be in that directory, where the profiles are
+// installed on all nodes.
+func validateSeccompProfiles(ctx context.Context, steps []Step) *apis.FieldError {
+	profileDir := config.FromContextOrDefaults(ctx).FeatureFlags.SeccompLocalhostProfileDir
+	for idx, s := range steps {
+		p := s.SeccompProfile
+		if p == nil {
+			continue
+		}
+		switch p.Type {
+		case SeccompProfileTypeUnconfined, SeccompProfileTypeRuntimeDefault:
+			if p.LocalhostProfile != nil {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("step %d localhostProfile can only be set for the %s seccompProfile type", idx, SeccompProfileTypeLocalhost),
+					Paths:   []string{fmt.Sprintf("[%d].seccompProfile.localhostProfile", idx)},
+				}
+			}
+		case SeccompProfileTypeLocalhost:
+			if p.LocalhostProfile == nil || *p.LocalhostProfile == "" {
+				return apis.ErrMissingField("seccompProfile.localhostProfile").ViaIndex(idx)
+			}
+			profile := *p.LocalhostProfile
+			if filepath.IsAbs(profile) || filepath.Clean(profile) != profile || strings.HasPrefix(profile, "..") {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("step %d localhostProfile %q must be a path relative to the seccomp profile root of the kubelet", idx, profile),
+					Paths:   []string{fmt.Sprintf("[%d].seccompProfile.localhostProfile", idx)},
+				}
+			}
+			if profileDir != "" && !strings.HasPrefix(profile, profileDir+"/") {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("step %d localhostProfile %q must be a profile in %q", idx, profile, profileDir),
+					Paths:   []string{fmt.Sprintf("[%d].seccompProfile.localhostProfile", idx)},
+				}
+			}
+		default:
+			return apis.ErrInvalidValue(p.Type, "seccompProfile.type").ViaIndex(idx)
+		}
 	}
 	return nil
 }
@@ -286,6 +616,44 @@ func ValidateResourcesVariables(steps []Step, resources *TaskResources) *apis.Fi
 	return validateVariables(steps, "resources.(?:inputs|outputs)", resourceNames)
 }
 
+// validateTaskMetadata checks that the description isn't longer than maxDescriptionLength and
+// that the tags can be used in label keys.
+func validateTaskMetadata(description string, tags []string) *apis.FieldError {
+	if len(description) > maxDescriptionLength {
+		return apis.ErrInvalidValue(fmt.Sprintf("description must be at most %d characters", maxDescriptionLength), "description")
+	}
+	for i, tag := range tags {
+		if errs := validation.IsDNS1123Label(tag); len(errs) > 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("invalid tag %q: %s", tag, strings.Join(errs, ",")), fmt.Sprintf("tags[%d]", i))
+		}
+	}
+	return nil
+}
+
+// validateResultVariables checks that the $(results.<name>.path) variables used in steps
+// reference declared results, and that $(tekton.results.dir) is spelled correctly.
+func validateResultVariables(steps []Step, results []TaskResult) *apis.FieldError {
+	resultNames := sets.NewString()
+	for _, r := range results {
+		resultNames.Insert(r.Name)
+		resultNames.Insert(r.Aliases...)
+	}
+	if err := validateVariables(steps, "results", resultNames); err != nil {
+		return err
+	}
+	return validateVariables(steps, "tekton\\.results", sets.NewString("dir"))
+}
+
+// validateWorkspaceVariables checks that the $(workspaces.<name>.path), $(workspaces.<name>.claim)
+// and $(workspaces.<name>.volume) variables used in steps reference declared workspaces.
+func validateWorkspaceVariables(steps []Step, workspaces []WorkspaceDeclaration) *apis.FieldError {
+	workspaceNames := sets.NewString()
+	for _, w := range workspaces {
+		workspaceNames.Insert(w.Name)
+	}
+	return validateVariables(steps, "workspaces", workspaceNames)
+}
+
 func validateArrayUsage(steps []Step, prefix string, vars sets.String) *apis.FieldError {
 	for _, step := range steps {
 		if err := validateTaskNoArrayReferenced("name", step.Name, prefix, vars); err != nil {
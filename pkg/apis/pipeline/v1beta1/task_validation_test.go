@@ -18,11 +18,13 @@ package v1beta1_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
@@ -236,6 +238,15 @@ func TestTaskSpecValidate(t *testing.T) {
 				}},
 			}}},
 		},
+	}, {
+		name: "valid step using results directory",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Script:    "date | tee $(results.date.path) && ls $(tekton.results.dir)",
+			}},
+			Results: []v1beta1.TaskResult{{Name: "date"}},
+		},
 	}, {
 		name: "valid workspace",
 		fields: fields{
@@ -317,6 +328,26 @@ func TestTaskSpecValidate(t *testing.T) {
 				hello "$(context.taskRun.namespace)"`,
 			}},
 		},
+	}, {
+		name: "valid workspace variables",
+		fields: fields{
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name: "source",
+			}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"--path=$(workspaces.source.path)"},
+					Env: []corev1.EnvVar{{
+						Name:  "VOLUME",
+						Value: "$(workspaces.source.volume)",
+					}},
+				},
+				Script: `
+				#!/usr/bin/env  bash
+				snapshot "$(workspaces.source.claim)"`,
+			}},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -339,13 +370,16 @@ func TestTaskSpecValidate(t *testing.T) {
 
 func TestTaskSpecValidateError(t *testing.T) {
 	type fields struct {
-		Params       []v1beta1.ParamSpec
-		Resources    *v1beta1.TaskResources
-		Steps        []v1beta1.Step
-		Volumes      []corev1.Volume
-		StepTemplate *corev1.Container
-		Workspaces   []v1beta1.WorkspaceDeclaration
-		Results      []v1beta1.TaskResult
+		Params         []v1beta1.ParamSpec
+		Resources      *v1beta1.TaskResources
+		Steps          []v1beta1.Step
+		Volumes        []corev1.Volume
+		StepTemplate   *corev1.Container
+		Workspaces     []v1beta1.WorkspaceDeclaration
+		Results        []v1beta1.TaskResult
+		Sidecars       []v1beta1.Sidecar
+		InitContainers []corev1.Container
+		Platforms      []string
 	}
 	tests := []struct {
 		name          string
@@ -533,6 +567,19 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `non-existent variable in "--flag=$(params.inexistent)" for step arg[0]`,
 			Paths:   []string{"taskspec.steps.arg[0]"},
 		},
+	}, {
+		name: "inexistent workspace variable",
+		fields: fields{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"--claim=$(workspaces.inexistent.claim)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "--claim=$(workspaces.inexistent.claim)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
 	}, {
 		name: "array used in unaccepted field",
 		fields: fields{
@@ -735,6 +782,23 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `multiple volumes with same name "workspace"`,
 			Paths:   []string{"volumes.name"},
 		},
+	}, {
+		name: "projected service account token without path",
+		fields: fields{
+			Steps: validSteps,
+			Volumes: []corev1.Volume{{
+				Name: "token",
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Audience: "vault"},
+					}},
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"volumes[0].projected.sources[0].serviceAccountToken.path"},
+		},
 	}, {
 		name: "step with script and command",
 		fields: fields{
@@ -750,6 +814,124 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: "step 0 script cannot be used with command",
 			Paths:   []string{"steps.script"},
 		},
+	}, {
+		name: "step envFromExternalSecrets without name",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container:              corev1.Container{Image: "myimage"},
+				EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{Prefix: "CREDS_"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"steps[0].envFromExternalSecrets[0].name"},
+		},
+	}, {
+		name: "step gpu without count",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				GPU:       &v1beta1.StepGPU{Vendor: v1beta1.GPUVendorNVIDIA},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: 0",
+			Paths:   []string{"steps[0].gpu.count"},
+		},
+	}, {
+		name: "step gpu of unknown vendor",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				GPU:       &v1beta1.StepGPU{Vendor: "intel", Count: 1},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: intel",
+			Paths:   []string{"steps[0].gpu.vendor"},
+		},
+	}, {
+		name: "several steps requesting gpus",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "train", Image: "myimage"},
+				GPU:       &v1beta1.StepGPU{Count: 2},
+			}, {
+				Container: corev1.Container{Name: "evaluate", Image: "myimage"},
+			}, {
+				Container: corev1.Container{Name: "export", Image: "myimage"},
+				GPU:       &v1beta1.StepGPU{Vendor: v1beta1.GPUVendorAMD, Count: 1},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "steps 0 and 2 both request GPUs, at most one step can",
+			Paths:   []string{"steps[2].gpu"},
+		},
+	}, {
+		name: "unnamed forEach step",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{Name: "targets", Type: v1beta1.ParamTypeArray}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "step 0 with a forEach must have a name",
+			Paths:   []string{"steps[0].name"},
+		},
+	}, {
+		name: "step named like an iteration of a forEach step",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{Name: "targets", Type: v1beta1.ParamTypeArray}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "build", Image: "myimage"},
+				ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+			}, {
+				Container: corev1.Container{Name: "build-0", Image: "myimage"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 1 is named like an iteration of step "build", whose iterations run as build-<index>`,
+			Paths:   []string{"steps[1].name"},
+		},
+	}, {
+		name: "forEach without paramName",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "test", Image: "myimage"},
+				ForEach:   &v1beta1.StepForEach{},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"steps[0].forEach.paramName"},
+		},
+	}, {
+		name: "forEach over a string param",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{Name: "target", Type: v1beta1.ParamTypeString}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "test", Image: "myimage"},
+				ForEach:   &v1beta1.StepForEach{ParamName: "target"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 iterates over "target", which is not an array parameter of the Task`,
+			Paths:   []string{"steps[0].forEach.paramName"},
+		},
+	}, {
+		name: "forEach over an undeclared param",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "test", Image: "myimage"},
+				ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 iterates over "targets", which is not an array parameter of the Task`,
+			Paths:   []string{"steps[0].forEach.paramName"},
+		},
 	}, {
 		name: "step volume mounts under /tekton/",
 		fields: fields{
@@ -765,6 +947,103 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `step 0 volumeMount cannot be mounted under /tekton/ (volumeMount "foo" mounted at "/tekton/foo")`,
 			Paths:   []string{"steps.volumeMounts.mountPath"},
 		},
+	}, {
+		name: "step volume mounts under nested /tekton/ directory",
+		fields: fields{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "foo",
+					MountPath: "/tekton/results/foo",
+				}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 volumeMount cannot be mounted under /tekton/ (volumeMount "foo" mounted at "/tekton/results/foo")`,
+			Paths:   []string{"steps.volumeMounts.mountPath"},
+		},
+	}, {
+		name: "step volume mounts at /tekton",
+		fields: fields{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "foo",
+					MountPath: "/tekton",
+				}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 volumeMount cannot be mounted under /tekton/ (volumeMount "foo" mounted at "/tekton")`,
+			Paths:   []string{"steps.volumeMounts.mountPath"},
+		},
+	}, {
+		name: "step volume mounts under /tekton/ via unclean path",
+		fields: fields{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "foo",
+					MountPath: "/tekton/home/../results",
+				}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 volumeMount cannot be mounted under /tekton/ (volumeMount "foo" mounted at "/tekton/home/../results")`,
+			Paths:   []string{"steps.volumeMounts.mountPath"},
+		},
+	}, {
+		name: "step volume mounts under /tekton/ with home prefix",
+		fields: fields{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "foo",
+					MountPath: "/tekton/homefoo",
+				}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `step 0 volumeMount cannot be mounted under /tekton/ (volumeMount "foo" mounted at "/tekton/homefoo")`,
+			Paths:   []string{"steps.volumeMounts.mountPath"},
+		},
+	}, {
+		name: "workspace mount path under /tekton/",
+		fields: fields{
+			Steps: validSteps,
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name:      "some-workspace",
+				MountPath: "/tekton/results",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `workspace "some-workspace" cannot be mounted under /tekton/ (mounted at "/tekton/results")`,
+			Paths:   []string{"workspaces.mountpath"},
+		},
+	}, {
+		name: "step references undeclared result",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Script:    "date | tee $(results.missing.path)",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "date | tee $(results.missing.path)" for step script`,
+			Paths:   []string{"taskspec.steps.script"},
+		},
+	}, {
+		name: "step references unknown tekton results variable",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Script:    "ls $(tekton.results.directory)",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "ls $(tekton.results.directory)" for step script`,
+			Paths:   []string{"taskspec.steps.script"},
+		},
 	}, {
 		name: "step volume mount name starts with tekton-internal-",
 		fields: fields{
@@ -921,17 +1200,143 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `non-existent variable in "\n\t\t\t\t#!/usr/bin/env  bash\n\t\t\t\thello \"$(context.task.missing)\"" for step script`,
 			Paths:   []string{"taskspec.steps.script"},
 		},
+	}, {
+		name: "invalid sidecar restart policy",
+		fields: fields{
+			Steps: validSteps,
+			Sidecars: []v1beta1.Sidecar{{
+				Container:     corev1.Container{Name: "sidecar", Image: "my-image"},
+				RestartPolicy: corev1.RestartPolicyNever,
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: Never`,
+			Paths:   []string{"sidecars.restartPolicy"},
+		},
+	}, {
+		name: "sidecar script with command",
+		fields: fields{
+			Steps: validSteps,
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{
+					Name:    "sidecar",
+					Image:   "my-image",
+					Command: []string{"serve"},
+				},
+				Script: "echo hello",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `sidecar 0 script cannot be used with command`,
+			Paths:   []string{"sidecars.script"},
+		},
+	}, {
+		name: "unnamed init container",
+		fields: fields{
+			Steps:          validSteps,
+			InitContainers: []corev1.Container{{Image: "my-image"}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"initContainers[0].name"},
+		},
+	}, {
+		name: "invalid init container name",
+		fields: fields{
+			Steps:          validSteps,
+			InitContainers: []corev1.Container{{Name: "Setup", Image: "my-image"}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value "Setup"`,
+			Paths:   []string{"initContainers[0].name"},
+			Details: "Init container name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+		},
+	}, {
+		name: "duplicate init container names",
+		fields: fields{
+			Steps: validSteps,
+			InitContainers: []corev1.Container{{
+				Name:  "setup",
+				Image: "my-image",
+			}, {
+				Name:  "setup",
+				Image: "my-other-image",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `multiple init containers with same name "setup"`,
+			Paths:   []string{"initContainers[1].name"},
+		},
+	}, {
+		name: "init container without image",
+		fields: fields{
+			Steps:          validSteps,
+			InitContainers: []corev1.Container{{Name: "setup"}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"initContainers[0].image"},
+		},
+	}, {
+		name: "init container mounting an internal volume",
+		fields: fields{
+			Steps: validSteps,
+			InitContainers: []corev1.Container{{
+				Name:         "setup",
+				Image:        "my-image",
+				VolumeMounts: []corev1.VolumeMount{{Name: "tekton-internal-tools", MountPath: "/tools"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `init container 0 volumeMount name "tekton-internal-tools" cannot start with "tekton-internal-"`,
+			Paths:   []string{"initContainers[0].volumeMounts.name"},
+		},
+	}, {
+		name: "platform without arch",
+		fields: fields{
+			Steps:     validSteps,
+			Platforms: []string{"linux/amd64", "linux"},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid platform "linux"`,
+			Paths:   []string{"platforms[1]"},
+			Details: `Platforms must be in the "os/arch" form, e.g. "linux/arm64"`,
+		},
+	}, {
+		name: "platform with a variant",
+		fields: fields{
+			Steps:     validSteps,
+			Platforms: []string{"linux/arm/v7"},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid platform "linux/arm/v7"`,
+			Paths:   []string{"platforms[0]"},
+			Details: `Platforms must be in the "os/arch" form, e.g. "linux/arm64"`,
+		},
+	}, {
+		name: "duplicate platforms",
+		fields: fields{
+			Steps:     validSteps,
+			Platforms: []string{"linux/amd64", "linux/arm64", "linux/amd64"},
+		},
+		expectedError: apis.FieldError{
+			Message: `platform "linux/amd64" is listed more than once`,
+			Paths:   []string{"platforms[2]"},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ts := &v1beta1.TaskSpec{
-				Params:       tt.fields.Params,
-				Resources:    tt.fields.Resources,
-				Steps:        tt.fields.Steps,
-				Volumes:      tt.fields.Volumes,
-				StepTemplate: tt.fields.StepTemplate,
-				Workspaces:   tt.fields.Workspaces,
-				Results:      tt.fields.Results,
+				Params:         tt.fields.Params,
+				Resources:      tt.fields.Resources,
+				Steps:          tt.fields.Steps,
+				Volumes:        tt.fields.Volumes,
+				StepTemplate:   tt.fields.StepTemplate,
+				Workspaces:     tt.fields.Workspaces,
+				Results:        tt.fields.Results,
+				Sidecars:       tt.fields.Sidecars,
+				InitContainers: tt.fields.InitContainers,
+				Platforms:      tt.fields.Platforms,
 			}
 			ctx := context.Background()
 			ts.SetDefaults(ctx)
@@ -945,3 +1350,245 @@ func TestTaskSpecValidateError(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskSpecValidate_SeccompProfile(t *testing.T) {
+	profile := func(p string) *string { return &p }
+	for _, tc := range []struct {
+		name       string
+		profileDir string
+		profile    *v1beta1.SeccompProfile
+		wantErr    *apis.FieldError
+	}{{
+		name:    "runtime default",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault},
+	}, {
+		name:    "unconfined",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeUnconfined},
+	}, {
+		name:    "localhost",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: profile("profiles/audit.json")},
+	}, {
+		name:       "localhost in the profile dir",
+		profileDir: "tekton",
+		profile:    &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: profile("tekton/audit.json")},
+	}, {
+		name:    "unknown type",
+		profile: &v1beta1.SeccompProfile{Type: "Strict"},
+		wantErr: &apis.FieldError{
+			Message: `invalid value: Strict`,
+			Paths:   []string{"steps[0].seccompProfile.type"},
+		},
+	}, {
+		name:    "localhost without a profile",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost},
+		wantErr: &apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"steps[0].seccompProfile.localhostProfile"},
+		},
+	}, {
+		name:    "profile for runtime default",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault, LocalhostProfile: profile("audit.json")},
+		wantErr: &apis.FieldError{
+			Message: `step 0 localhostProfile can only be set for the Localhost seccompProfile type`,
+			Paths:   []string{"steps[0].seccompProfile.localhostProfile"},
+		},
+	}, {
+		name:    "absolute profile",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: profile("/etc/audit.json")},
+		wantErr: &apis.FieldError{
+			Message: `step 0 localhostProfile "/etc/audit.json" must be a path relative to the seccomp profile root of the kubelet`,
+			Paths:   []string{"steps[0].seccompProfile.localhostProfile"},
+		},
+	}, {
+		name:    "profile outside of the profile root",
+		profile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: profile("../audit.json")},
+		wantErr: &apis.FieldError{
+			Message: `step 0 localhostProfile "../audit.json" must be a path relative to the seccomp profile root of the kubelet`,
+			Paths:   []string{"steps[0].seccompProfile.localhostProfile"},
+		},
+	}, {
+		name:       "localhost outside of the profile dir",
+		profileDir: "tekton",
+		profile:    &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: profile("profiles/audit.json")},
+		wantErr: &apis.FieldError{
+			Message: `step 0 localhostProfile "profiles/audit.json" must be a profile in "tekton"`,
+			Paths:   []string{"steps[0].seccompProfile.localhostProfile"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+			cfg.FeatureFlags.SeccompLocalhostProfileDir = tc.profileDir
+			ctx := config.ToContext(context.Background(), cfg)
+			ts := &v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{
+					Container:      corev1.Container{Name: "mystep", Image: "myimage"},
+					SeccompProfile: tc.profile,
+				}},
+			}
+			err := ts.Validate(ctx)
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Errorf("TaskSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if d := cmp.Diff(tc.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("TaskSpec.Validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidateResults_JSONPath(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		jsonPath      string
+		expectedError *apis.FieldError
+	}{{
+		name:     "valid jsonPath",
+		jsonPath: "{.image.digest}",
+	}, {
+		name:     "valid jsonPath with array index",
+		jsonPath: "{.items[0].name}",
+	}, {
+		name:     "jsonPath not enclosed in braces",
+		jsonPath: ".image.digest",
+		expectedError: &apis.FieldError{
+			Message: `invalid value: JSONPath ".image.digest" must be enclosed in curly braces, e.g. "{.image.digest}"`,
+			Paths:   []string{"results[0].jsonPath"},
+		},
+	}, {
+		name:     "unparsable jsonPath",
+		jsonPath: "{.items[}",
+		expectedError: &apis.FieldError{
+			Message: `invalid value: invalid JSONPath "{.items[}": unterminated array`,
+			Paths:   []string{"results[0].jsonPath"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v1beta1.ValidateResults([]v1beta1.TaskResult{{Name: "digest", JSONPath: tc.jsonPath}})
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("ValidateResults() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing")
+			}
+			if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("ValidateResults() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidateResults_Aliases(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		results       []v1beta1.TaskResult
+		expectedError *apis.FieldError
+	}{{
+		name: "valid aliases",
+		results: []v1beta1.TaskResult{
+			{Name: "image-digest", Aliases: []string{"digest", "IMAGE_DIGEST"}},
+			{Name: "image-url", Aliases: []string{"url"}},
+		},
+	}, {
+		name:    "invalid alias",
+		results: []v1beta1.TaskResult{{Name: "image-digest", Aliases: []string{"-digest"}}},
+		expectedError: &apis.FieldError{
+			Message: `invalid key name "-digest"`,
+			Paths:   []string{"results[0].aliases[0]"},
+			Details: "Alias must match the result name format '^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$'",
+		},
+	}, {
+		name: "alias colliding with a result",
+		results: []v1beta1.TaskResult{
+			{Name: "digest"},
+			{Name: "image-digest", Aliases: []string{"digest"}},
+		},
+		expectedError: &apis.FieldError{
+			Message: `alias "digest" of result "image-digest" collides with result "digest"`,
+			Paths:   []string{"results[1].aliases[0]"},
+		},
+	}, {
+		name: "alias colliding with another alias",
+		results: []v1beta1.TaskResult{
+			{Name: "image-digest", Aliases: []string{"digest"}},
+			{Name: "chart-digest", Aliases: []string{"digest"}},
+		},
+		expectedError: &apis.FieldError{
+			Message: `alias "digest" of result "chart-digest" collides with result "image-digest"`,
+			Paths:   []string{"results[1].aliases[0]"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v1beta1.ValidateResults(tc.results)
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("ValidateResults() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing")
+			}
+			if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("ValidateResults() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskSpecValidateMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		description   string
+		tags          []string
+		expectedError *apis.FieldError
+	}{{
+		name:        "valid description and tags",
+		description: "Builds an image",
+		tags:        []string{"build", "image-2"},
+	}, {
+		name:        "description at the maximum length",
+		description: strings.Repeat("a", 1024),
+	}, {
+		name:        "description too long",
+		description: strings.Repeat("a", 1025),
+		expectedError: &apis.FieldError{
+			Message: "invalid value: description must be at most 1024 characters",
+			Paths:   []string{"description"},
+		},
+	}, {
+		name: "tag isn't a DNS label",
+		tags: []string{"build", "Build_Image"},
+		expectedError: &apis.FieldError{
+			Message: `invalid value: invalid tag "Build_Image": a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+			Paths:   []string{"tags[1]"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := &v1beta1.TaskSpec{
+				Description: tc.description,
+				Tags:        tc.tags,
+				Steps:       validSteps,
+			}
+			err := ts.Validate(context.Background())
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("TaskSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing")
+			}
+			if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("TaskSpec.Validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -124,6 +124,7 @@ func TestTaskRunSpec_SetDefaults(t *testing.T) {
 }
 
 func TestTaskRunDefaulting(t *testing.T) {
+	automountServiceAccountToken := false
 	tests := []struct {
 		name string
 		in   *v1beta1.TaskRun
@@ -369,6 +370,52 @@ func TestTaskRunDefaulting(t *testing.T) {
 			})
 			return s.ToContext(ctx)
 		},
+	}, {
+		name: "TaskRef pod template merged with default config pod template",
+		in: &v1beta1.TaskRun{
+			Spec: v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "foo"},
+				PodTemplate: &v1beta1.PodTemplate{
+					NodeSelector: map[string]string{
+						"label2": "value2",
+					},
+				},
+			},
+		},
+		want: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"app.kubernetes.io/managed-by": "tekton-pipelines"},
+			},
+			Spec: v1beta1.TaskRunSpec{
+				TaskRef:            &v1beta1.TaskRef{Name: "foo", Kind: v1beta1.NamespacedTaskKind},
+				Timeout:            &metav1.Duration{Duration: 5 * time.Minute},
+				ServiceAccountName: "tekton",
+				PodTemplate: &v1beta1.PodTemplate{
+					NodeSelector: map[string]string{
+						"label2": "value2",
+					},
+					Tolerations: []corev1.Toleration{{
+						Key:      "ci",
+						Operator: corev1.TolerationOpExists,
+					}},
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+				},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.GetDefaultsConfigName(),
+				},
+				Data: map[string]string{
+					"default-timeout-minutes": "5",
+					"default-service-account": "tekton",
+					"default-pod-template":    "{nodeSelector: {'label': 'value'}, tolerations: [{key: ci, operator: Exists}], automountServiceAccountToken: false}",
+				},
+			})
+			return s.ToContext(ctx)
+		},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -23,6 +23,7 @@ import (
 	apisconfig "github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
@@ -58,11 +59,45 @@ type TaskRunSpec struct {
 	// Refer Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// DistributeTimeout divides the timeout of the TaskRun evenly among its
+	// Steps, each Step being given the time left unused by the previous ones
+	// on top of its share.
+	// +optional
+	DistributeTimeout bool `json:"distributeTimeout,omitempty"`
 	// PodTemplate holds pod specific configuration
 	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
 	// Workspaces is a list of WorkspaceBindings from volumes to workspaces.
 	// +optional
 	Workspaces []WorkspaceBinding `json:"workspaces,omitempty"`
+	// StepOverrides overrides fields of the Steps of the Task at run time.
+	// +optional
+	StepOverrides []TaskRunStepOverride `json:"stepOverrides,omitempty"`
+	// Debug configures the debugging of the TaskRun while it is running.
+	// +optional
+	Debug *TaskRunDebug `json:"debug,omitempty"`
+	// AuditLog records the transitions of the TaskRun, one JSON line each,
+	// in the audit-<TaskRun name> ConfigMap of its namespace.
+	// +optional
+	AuditLog bool `json:"auditLog,omitempty"`
+}
+
+// TaskRunDebug configures the debugging of a running TaskRun.
+type TaskRunDebug struct {
+	// AddEphemeralContainer is injected into the Pod of the TaskRun as an
+	// ephemeral container while it is running, e.g. to inspect its Steps from
+	// a shell. It can be set once the TaskRun started. Ephemeral containers
+	// can't be removed from a Pod, so changing it injects another container.
+	// +optional
+	AddEphemeralContainer *corev1.EphemeralContainer `json:"addEphemeralContainer,omitempty"`
+}
+
+// TaskRunStepOverride overrides fields of the Step of a Task with the same name.
+type TaskRunStepOverride struct {
+	// Name is the name of the Step to override.
+	Name string `json:"name"`
+	// ImagePullPolicy replaces the imagePullPolicy of the Step.
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 }
 
 // TaskRunSpecStatus defines the taskrun spec status the user can provide
@@ -116,6 +151,14 @@ const (
 	TaskRunReasonCancelled TaskRunReason = "TaskRunCancelled"
 	// TaskRunReasonTimedOut is the reason set when the Taskrun has timed out
 	TaskRunReasonTimedOut TaskRunReason = "TaskRunTimeout"
+	// TaskRunReasonEvicted is the reason set when the Pod of the TaskRun was evicted from its node
+	TaskRunReasonEvicted TaskRunReason = "PodEvicted"
+	// TaskRunReasonResultExtractionFailed is the reason set when the value of a result couldn't
+	// be extracted with its JSONPath
+	TaskRunReasonResultExtractionFailed TaskRunReason = "TaskRunResultExtractionFailed"
+	// TaskRunReasonStepTimedOut is the reason set when a Step used up its share of the
+	// timeout of a TaskRun distributing its timeout among its Steps
+	TaskRunReasonStepTimedOut TaskRunReason = "TaskRunStepTimeout"
 )
 
 func (t TaskRunReason) String() string {
@@ -196,6 +239,17 @@ type TaskRunStatusFields struct {
 	// +optional
 	TaskRunResults []TaskRunResult `json:"taskResults,omitempty"`
 
+	// TaskResultsSize is the total size in bytes of the values of the TaskRunResults.
+	// The results read from the termination message of the steps are limited by
+	// its maximum size.
+	// +optional
+	TaskResultsSize int64 `json:"taskResultsSize,omitempty"`
+
+	// PlatformResults are the results written out by the Pod of each platform
+	// of the task, keyed by platform.
+	// +optional
+	PlatformResults map[string][]TaskRunResult `json:"platformResults,omitempty"`
+
 	// The list has one entry per sidecar in the manifest. Each entry is
 	// represents the imageid of the corresponding sidecar.
 	Sidecars []SidecarState `json:"sidecars,omitempty"`
@@ -260,6 +314,33 @@ type StepState struct {
 	Name                  string `json:"name,omitempty"`
 	ContainerName         string `json:"container,omitempty"`
 	ImageID               string `json:"imageID,omitempty"`
+	// Percentage is a rough estimate of the progress of the step, computed from the time
+	// elapsed since the step started against the timeout of the TaskRun. It is 100 once
+	// the step has terminated, and unset when the TaskRun has no timeout.
+	// +optional
+	Percentage *int32 `json:"percentage,omitempty"`
+	// Metrics reports the resources used by the step, when the "enable-step-metrics"
+	// feature flag is set.
+	// +optional
+	Metrics *StepMetrics `json:"metrics,omitempty"`
+	// LogURL is the URL of the logs of the step, rendered from the
+	// default-log-url-template of the config-defaults ConfigMap.
+	// +optional
+	LogURL string `json:"logURL,omitempty"`
+}
+
+// StepMetrics reports the duration of a step and the peak of its resource usage,
+// sampled from metrics-server while it runs.
+type StepMetrics struct {
+	// Duration is the time the step took to run, once it has terminated.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// PeakMemory is the highest memory usage of the step sampled.
+	// +optional
+	PeakMemory *resource.Quantity `json:"peakMemory,omitempty"`
+	// PeakCPU is the highest CPU usage of the step sampled.
+	// +optional
+	PeakCPU *resource.Quantity `json:"peakCPU,omitempty"`
 }
 
 // SidecarState reports the results of running a sidecar in a Task.
@@ -19,14 +19,26 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"github.com/tektoncd/pipeline/pkg/substitution"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
+// The limits Kubernetes enforces on the DNS config of a Pod.
+const (
+	maxDNSNameservers     = 3
+	maxDNSSearchPaths     = 6
+	maxDNSSearchListChars = 256
+)
+
 var _ apis.Validatable = (*TaskRun)(nil)
 
 // Validate taskrun
@@ -34,7 +46,24 @@ func (tr *TaskRun) Validate(ctx context.Context) *apis.FieldError {
 	if err := validate.ObjectMetadata(tr.GetObjectMeta()).ViaField("metadata"); err != nil {
 		return err
 	}
-	return tr.Spec.Validate(ctx)
+	if err := tr.Spec.Validate(ctx); err != nil {
+		return err
+	}
+	// The params are only checked against the JSON Schema of the Task, and
+	// the images of the Steps for vulnerabilities, when the TaskRun is
+	// created, so that changes to the schema or newly found vulnerabilities
+	// don't block updates to running TaskRuns.
+	if !apis.IsInCreate(ctx) {
+		return nil
+	}
+	var errs *apis.FieldError
+	if l := getInputValidationLookup(ctx); l != nil {
+		errs = errs.Also(tr.validateInputs(ctx, l))
+	}
+	if l := getVulnerabilityLookup(ctx); l != nil && config.FromContextOrDefaults(ctx).FeatureFlags.BlockOnCriticalVulns {
+		errs = errs.Also(tr.validateStepImages(ctx, l))
+	}
+	return errs
 }
 
 // Validate taskrun spec
@@ -53,6 +82,17 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("spec.taskref.name", "spec.taskspec")
 	}
 
+	// Bundles are only resolved for the tasks of a Pipeline
+	if ts.TaskRef != nil && ts.TaskRef.Bundle != "" {
+		return apis.ErrDisallowedFields("spec.taskref.bundle")
+	}
+
+	if ts.TaskRef != nil {
+		if err := validateRefNameParams(ctx, ts.TaskRef.Name, ts.Params, "spec.taskref.name"); err != nil {
+			return err
+		}
+	}
+
 	// Validate TaskSpec if it's present
 	if ts.TaskSpec != nil {
 		if err := ts.TaskSpec.Validate(ctx); err != nil {
@@ -86,6 +126,172 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if err := ts.validateDistributeTimeout(ctx); err != nil {
+		return err
+	}
+
+	if err := validatePodTemplate(ts.PodTemplate).ViaField("spec.podTemplate"); err != nil {
+		return err
+	}
+
+	if err := validateStepOverrides(ctx, ts.StepOverrides).ViaField("spec.stepOverrides"); err != nil {
+		return err
+	}
+
+	if err := validateDebug(ctx, ts.Debug).ViaField("spec.debug"); err != nil {
+		return err
+	}
+
+	if err := validateParamsAppend(ctx, ts.Params).ViaField("spec"); err != nil {
+		return err
+	}
+
+	if ts.AuditLog {
+		if err := ValidateEnabledAPIFields(ctx, "auditLog", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"spec.auditLog"}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateDistributeTimeout checks that the timeout distributed among the Steps
+// isn't disabled.
+func (ts *TaskRunSpec) validateDistributeTimeout(ctx context.Context) *apis.FieldError {
+	if !ts.DistributeTimeout {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "distributeTimeout", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{"spec.distributeTimeout"}
+		return err
+	}
+	if ts.Timeout != nil && ts.Timeout.Duration == config.NoTimeoutDuration {
+		return apis.ErrGeneric("the timeout can't be distributed among the Steps when it is disabled", "spec.distributeTimeout", "spec.timeout")
+	}
+	return nil
+}
+
+// validateStepOverrides checks that each Step is overridden once, with a valid
+// imagePullPolicy. Whether the Steps exist is checked once the Task is resolved.
+func validateStepOverrides(ctx context.Context, overrides []TaskRunStepOverride) *apis.FieldError {
+	if len(overrides) == 0 {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "stepOverrides", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{apis.CurrentField}
+		return err
+	}
+	seen := sets.NewString()
+	for i, o := range overrides {
+		switch {
+		case o.Name == "":
+			return apis.ErrMissingField("name").ViaIndex(i)
+		case seen.Has(o.Name):
+			return apis.ErrMultipleOneOf("name").ViaIndex(i)
+		}
+		switch o.ImagePullPolicy {
+		case "", corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent:
+		default:
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s, %s or %s", o.ImagePullPolicy, corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent), "imagePullPolicy").ViaIndex(i)
+		}
+		seen.Insert(o.Name)
+	}
+	return nil
+}
+
+// reservedVolumePrefixes are the prefixes of the names of the volumes Tekton declares
+// in the Pod of a TaskRun, which the volumes of pod templates can't use.
+var reservedVolumePrefixes = []string{"tekton-internal-", "tekton-creds-init-home-"}
+
+// validatePodTemplate makes sure the volumes of the pod template can be added to the
+// volumes Tekton declares in the Pod of a TaskRun, and that its DNS settings and
+// scheduler name are valid.
+func validatePodTemplate(tpl *PodTemplate) *apis.FieldError {
+	if tpl == nil {
+		return nil
+	}
+	if err := validateDNS(tpl); err != nil {
+		return err
+	}
+	// An empty scheduler name leaves the Pod to the default scheduler.
+	if tpl.SchedulerName != "" {
+		if errs := validation.IsDNS1123Subdomain(tpl.SchedulerName); len(errs) > 0 {
+			return apis.ErrInvalidValue(tpl.SchedulerName, "schedulerName")
+		}
+	}
+	if err := ValidateVolumes(tpl.Volumes).ViaField("volumes"); err != nil {
+		return err
+	}
+	for i, v := range tpl.Volumes {
+		for _, prefix := range reservedVolumePrefixes {
+			if strings.HasPrefix(v.Name, prefix) {
+				err := &apis.FieldError{
+					Message: fmt.Sprintf(`volume name %q cannot start with %q`, v.Name, prefix),
+					Paths:   []string{"name"},
+				}
+				return err.ViaFieldIndex("volumes", i)
+			}
+		}
+	}
+	return validateServiceAccountTokenProjections(tpl.Volumes).ViaField("volumes")
+}
+
+// validateDNS makes sure the DNS policy and config of a pod template would be accepted
+// by Kubernetes, so that invalid values are reported before the Pod of a TaskRun is created.
+func validateDNS(tpl *PodTemplate) *apis.FieldError {
+	policy, config := tpl.DNSPolicy, tpl.DNSConfig
+	if policy != nil {
+		switch *policy {
+		case corev1.DNSClusterFirst, corev1.DNSDefault, corev1.DNSNone:
+		case corev1.DNSClusterFirstWithHostNet:
+			if !tpl.HostNetwork {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("dnsPolicy %q requires hostNetwork to be true", corev1.DNSClusterFirstWithHostNet),
+					Paths:   []string{"dnsPolicy"},
+				}
+			}
+		default:
+			return apis.ErrInvalidValue(string(*policy), "dnsPolicy")
+		}
+		if *policy == corev1.DNSNone && (config == nil || len(config.Nameservers) == 0) {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("at least one nameserver is required when dnsPolicy is %q", corev1.DNSNone),
+				Paths:   []string{"dnsConfig.nameservers"},
+			}
+		}
+	}
+	if config == nil {
+		return nil
+	}
+	if len(config.Nameservers) > maxDNSNameservers {
+		return apis.ErrInvalidValue(fmt.Sprintf("must not have more than %d nameservers", maxDNSNameservers), "dnsConfig.nameservers")
+	}
+	for i, ns := range config.Nameservers {
+		if net.ParseIP(ns) == nil {
+			return apis.ErrInvalidArrayValue(ns, "dnsConfig.nameservers", i)
+		}
+	}
+	if len(config.Searches) > maxDNSSearchPaths {
+		return apis.ErrInvalidValue(fmt.Sprintf("must not have more than %d search paths", maxDNSSearchPaths), "dnsConfig.searches")
+	}
+	searchesLength := 0
+	for i, search := range config.Searches {
+		// A trailing dot is allowed, as in resolv.conf.
+		search = strings.TrimSuffix(search, ".")
+		if errs := validation.IsDNS1123Subdomain(search); len(errs) > 0 {
+			return apis.ErrInvalidArrayValue(config.Searches[i], "dnsConfig.searches", i)
+		}
+		searchesLength += len(search)
+	}
+	if searchesLength+len(config.Searches)-1 > maxDNSSearchListChars {
+		return apis.ErrInvalidValue(fmt.Sprintf("must not have more than %d characters (including spaces) in the search list", maxDNSSearchListChars), "dnsConfig.searches")
+	}
+	for i, option := range config.Options {
+		if option.Name == "" {
+			return apis.ErrMissingField("name").ViaFieldIndex("dnsConfig.options", i)
+		}
+	}
 	return nil
 }
 
@@ -103,7 +309,7 @@ func validateWorkspaceBindings(ctx context.Context, wb []WorkspaceBinding) *apis
 		}
 	}
 
-	return nil
+	return ValidateWorkspaceBindingCollisions(wb).ViaField("spec")
 }
 
 func validateParameters(params []Param) *apis.FieldError {
@@ -117,3 +323,69 @@ func validateParameters(params []Param) *apis.FieldError {
 	}
 	return nil
 }
+
+// validateRefNameParams checks that the params referenced by name, the name of a
+// taskRef or pipelineRef, are string params of the run, which substitutes them
+// when the alpha API fields are enabled.
+func validateRefNameParams(ctx context.Context, name string, params []Param, path string) *apis.FieldError {
+	referenced := substitution.ExtractVariableNames(name, "params")
+	if len(referenced) == 0 {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "params in the name of a reference", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{path}
+		return err
+	}
+	stringParams := sets.NewString()
+	for _, p := range params {
+		if p.Value.Type == ParamTypeString {
+			stringParams.Insert(p.Name)
+		}
+	}
+	for _, r := range referenced {
+		if !stringParams.Has(r) {
+			return apis.ErrInvalidValue(fmt.Sprintf("%q references %q, which isn't a string param of the run", name, r), path)
+		}
+	}
+	return nil
+}
+
+// validateParamsAppend checks that only array values are appended to the
+// defaults of their parameters.
+func validateParamsAppend(ctx context.Context, params []Param) *apis.FieldError {
+	for i, p := range params {
+		if !p.Append {
+			continue
+		}
+		if err := ValidateEnabledAPIFields(ctx, "append", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{fmt.Sprintf("params[%d].append", i)}
+			return err
+		}
+		if p.Value.Type != ParamTypeArray {
+			return apis.ErrInvalidValue(fmt.Sprintf("only array values can be appended to the default of parameter %q", p.Name), fmt.Sprintf("params[%d].append", i))
+		}
+	}
+	return nil
+}
+
+// validateDebug checks that the ephemeral container to inject into the Pod is
+// named and has an image.
+func validateDebug(ctx context.Context, debug *TaskRunDebug) *apis.FieldError {
+	if debug == nil || debug.AddEphemeralContainer == nil {
+		return nil
+	}
+	if err := ValidateEnabledAPIFields(ctx, "debug", config.AlphaAPIFields); err != nil {
+		err.Paths = []string{"addEphemeralContainer"}
+		return err
+	}
+	c := debug.AddEphemeralContainer
+	switch {
+	case c.Name == "":
+		return apis.ErrMissingField("addEphemeralContainer.name")
+	case len(validation.IsDNS1123Label(c.Name)) > 0:
+		return apis.ErrInvalidValue(c.Name, "addEphemeralContainer.name")
+	case c.Image == "":
+		return apis.ErrMissingField("addEphemeralContainer.image")
+	}
+	return nil
+}
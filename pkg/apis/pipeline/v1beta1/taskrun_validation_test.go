@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resource "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
@@ -111,6 +112,25 @@ func TestTaskRun_Workspaces_Invalid(t *testing.T) {
 			},
 		},
 		wantErr: apis.ErrMultipleOneOf("spec.workspaces.name"),
+	}, {
+		name: "bind the same claim and subPath twice",
+		tr: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "taskname"},
+			Spec: v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "task"},
+				Workspaces: []v1beta1.WorkspaceBinding{{
+					Name:                  "source",
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+				}, {
+					Name:                  "output",
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+				}},
+			},
+		},
+		wantErr: &apis.FieldError{
+			Message: `workspace bindings "source" and "output" both mount persistentVolumeClaim "shared" at subPath ""`,
+			Paths:   []string{"spec.workspaces"},
+		},
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -126,6 +146,10 @@ func TestTaskRun_Workspaces_Invalid(t *testing.T) {
 }
 
 func TestTaskRunSpec_Invalidate(t *testing.T) {
+	tokenExpirationSeconds := int64(60)
+	invalidDNSPolicy := corev1.DNSPolicy("ClusterLast")
+	noneDNSPolicy := corev1.DNSNone
+	hostNetDNSPolicy := corev1.DNSClusterFirstWithHostNet
 	tests := []struct {
 		name    string
 		spec    v1beta1.TaskRunSpec
@@ -154,6 +178,15 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			},
 		},
 		wantErr: apis.ErrDisallowedFields("spec.taskspec", "spec.taskref"),
+	}, {
+		name: "taskref with a bundle",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{
+				Name:   "taskrefname",
+				Bundle: "registry.example.com/tasks:v1",
+			},
+		},
+		wantErr: apis.ErrDisallowedFields("spec.taskref.bundle"),
 	}, {
 		name: "negative pipeline timeout",
 		spec: v1beta1.TaskRunSpec{
@@ -200,6 +233,145 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
 		},
 		wantErr: apis.ErrMultipleOneOf("spec.params.name"),
+	}, {
+		name: "pod template volume colliding with tekton volumes",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name:         "tekton-internal-home",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+			},
+		},
+		wantErr: &apis.FieldError{
+			Message: `volume name "tekton-internal-home" cannot start with "tekton-internal-"`,
+			Paths:   []string{"spec.podTemplate.volumes[0].name"},
+		},
+	}, {
+		name: "pod template volume colliding with the credentials home volumes",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name:         "tekton-creds-init-home-0",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+			},
+		},
+		wantErr: &apis.FieldError{
+			Message: `volume name "tekton-creds-init-home-0" cannot start with "tekton-creds-init-home-"`,
+			Paths:   []string{"spec.podTemplate.volumes[0].name"},
+		},
+	}, {
+		name: "pod template service account token without path",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name: "token",
+					VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Audience: "vault"},
+						}},
+					}},
+				}},
+			},
+		},
+		wantErr: apis.ErrMissingField("spec.podTemplate.volumes[0].projected.sources[0].serviceAccountToken.path"),
+	}, {
+		name: "pod template service account token expiring too early",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name: "token",
+					VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          "vault",
+								ExpirationSeconds: &tokenExpirationSeconds,
+								Path:              "token",
+							},
+						}},
+					}},
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("60 should be >= 600", "spec.podTemplate.volumes[0].projected.sources[0].serviceAccountToken.expirationSeconds"),
+	}, {
+		name: "pod template with an invalid dns policy",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:     &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{DNSPolicy: &invalidDNSPolicy},
+		},
+		wantErr: apis.ErrInvalidValue("ClusterLast", "spec.podTemplate.dnsPolicy"),
+	}, {
+		name: "pod template with dns policy ClusterFirstWithHostNet without host network",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:     &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{DNSPolicy: &hostNetDNSPolicy},
+		},
+		wantErr: &apis.FieldError{
+			Message: `dnsPolicy "ClusterFirstWithHostNet" requires hostNetwork to be true`,
+			Paths:   []string{"spec.podTemplate.dnsPolicy"},
+		},
+	}, {
+		name: "pod template with dns policy None and no nameserver",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSPolicy: &noneDNSPolicy,
+				DNSConfig: &corev1.PodDNSConfig{Searches: []string{"tekton.local"}},
+			},
+		},
+		wantErr: &apis.FieldError{
+			Message: `at least one nameserver is required when dnsPolicy is "None"`,
+			Paths:   []string{"spec.podTemplate.dnsConfig.nameservers"},
+		},
+	}, {
+		name: "pod template with an invalid nameserver",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSConfig: &corev1.PodDNSConfig{Nameservers: []string{"8.8.8.8", "dns.local"}},
+			},
+		},
+		wantErr: apis.ErrInvalidArrayValue("dns.local", "spec.podTemplate.dnsConfig.nameservers", 1),
+	}, {
+		name: "pod template with too many nameservers",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSConfig: &corev1.PodDNSConfig{Nameservers: []string{"1.1.1.1", "1.0.0.1", "8.8.8.8", "8.8.4.4"}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("must not have more than 3 nameservers", "spec.podTemplate.dnsConfig.nameservers"),
+	}, {
+		name: "pod template with an invalid search domain",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSConfig: &corev1.PodDNSConfig{Searches: []string{"Tekton_Local"}},
+			},
+		},
+		wantErr: apis.ErrInvalidArrayValue("Tekton_Local", "spec.podTemplate.dnsConfig.searches", 0),
+	}, {
+		name: "pod template with a dns option without name",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSConfig: &corev1.PodDNSConfig{Options: []corev1.PodDNSConfigOption{{}}},
+			},
+		},
+		wantErr: apis.ErrMissingField("spec.podTemplate.dnsConfig.options[0].name"),
+	}, {
+		name: "pod template with an invalid scheduler name",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:     &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{SchedulerName: "Batch Scheduler"},
+		},
+		wantErr: apis.ErrInvalidValue("Batch Scheduler", "spec.podTemplate.schedulerName"),
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -211,7 +383,256 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 	}
 }
 
+func TestTaskRunSpec_InvalidStepOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []v1beta1.TaskRunStepOverride
+		wantErr   *apis.FieldError
+	}{{
+		name:      "missing step name",
+		overrides: []v1beta1.TaskRunStepOverride{{ImagePullPolicy: corev1.PullAlways}},
+		wantErr:   apis.ErrMissingField("spec.stepOverrides[0].name"),
+	}, {
+		name: "duplicate step name",
+		overrides: []v1beta1.TaskRunStepOverride{{
+			Name:            "build",
+			ImagePullPolicy: corev1.PullAlways,
+		}, {
+			Name:            "build",
+			ImagePullPolicy: corev1.PullNever,
+		}},
+		wantErr: apis.ErrMultipleOneOf("spec.stepOverrides[1].name"),
+	}, {
+		name: "invalid imagePullPolicy",
+		overrides: []v1beta1.TaskRunStepOverride{{
+			Name:            "build",
+			ImagePullPolicy: "Sometimes",
+		}},
+		wantErr: apis.ErrInvalidValue("Sometimes should be one of Always, Never or IfNotPresent", "spec.stepOverrides[0].imagePullPolicy"),
+	}}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			spec := v1beta1.TaskRunSpec{
+				TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+				StepOverrides: ts.overrides,
+			}
+			err := spec.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields))
+			if d := cmp.Diff(ts.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("TaskRunSpec.Validate/%s %s", ts.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskRunSpec_InvalidDebug(t *testing.T) {
+	tests := []struct {
+		name      string
+		container corev1.EphemeralContainer
+		wantErr   *apis.FieldError
+	}{{
+		name: "missing name",
+		container: corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Image: "busybox"},
+		},
+		wantErr: apis.ErrMissingField("spec.debug.addEphemeralContainer.name"),
+	}, {
+		name: "invalid name",
+		container: corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "Debugger", Image: "busybox"},
+		},
+		wantErr: apis.ErrInvalidValue("Debugger", "spec.debug.addEphemeralContainer.name"),
+	}, {
+		name: "missing image",
+		container: corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"},
+		},
+		wantErr: apis.ErrMissingField("spec.debug.addEphemeralContainer.image"),
+	}}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			spec := v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				Debug:   &v1beta1.TaskRunDebug{AddEphemeralContainer: &ts.container},
+			}
+			err := spec.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields))
+			if d := cmp.Diff(ts.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("TaskRunSpec.Validate/%s %s", ts.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskRunSpec_DistributeTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout *metav1.Duration
+		alpha   bool
+		wantErr string
+	}{{
+		name:  "default timeout",
+		alpha: true,
+	}, {
+		name:    "explicit timeout",
+		timeout: &metav1.Duration{Duration: time.Hour},
+		alpha:   true,
+	}, {
+		name:    "disabled timeout",
+		timeout: &metav1.Duration{Duration: 0},
+		alpha:   true,
+		wantErr: "the timeout can't be distributed among the Steps when it is disabled: spec.distributeTimeout, spec.timeout",
+	}, {
+		name:    "alpha fields disabled",
+		timeout: &metav1.Duration{Duration: time.Hour},
+		wantErr: `distributeTimeout requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.distributeTimeout`,
+	}}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			spec := v1beta1.TaskRunSpec{
+				TaskRef:           &v1beta1.TaskRef{Name: "mytask"},
+				Timeout:           ts.timeout,
+				DistributeTimeout: true,
+			}
+			ctx := context.Background()
+			if ts.alpha {
+				ctx = withEnabledAPIFields(ctx, config.AlphaAPIFields)
+			}
+			err := spec.Validate(ctx)
+			if ts.wantErr == "" {
+				if err != nil {
+					t.Errorf("TaskRunSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if d := cmp.Diff(ts.wantErr, err.Error()); d != "" {
+				t.Errorf("TaskRunSpec.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskRunSpec_AuditLog(t *testing.T) {
+	spec := v1beta1.TaskRunSpec{
+		TaskRef:  &v1beta1.TaskRef{Name: "mytask"},
+		AuditLog: true,
+	}
+	if err := spec.Validate(withEnabledAPIFields(context.Background(), config.AlphaAPIFields)); err != nil {
+		t.Errorf("TaskRunSpec.Validate() = %v", err)
+	}
+	want := `auditLog requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.auditLog`
+	err := spec.Validate(context.Background())
+	if err == nil {
+		t.Fatalf("Expected an error, got nil")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("TaskRunSpec.Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskRunSpec_ParamsAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   v1beta1.ArrayOrString
+		alpha   bool
+		wantErr string
+	}{{
+		name:  "array value",
+		value: v1beta1.NewArrayOrString("--verbose", "--debug"),
+		alpha: true,
+	}, {
+		name:    "string value",
+		value:   v1beta1.NewArrayOrString("--verbose"),
+		alpha:   true,
+		wantErr: `invalid value: only array values can be appended to the default of parameter "flags": spec.params[0].append`,
+	}, {
+		name:    "alpha fields disabled",
+		value:   v1beta1.NewArrayOrString("--verbose", "--debug"),
+		wantErr: `append requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.params[0].append`,
+	}}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			spec := v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+				Params:  []v1beta1.Param{{Name: "flags", Value: ts.value, Append: true}},
+			}
+			ctx := context.Background()
+			if ts.alpha {
+				ctx = withEnabledAPIFields(ctx, config.AlphaAPIFields)
+			}
+			err := spec.Validate(ctx)
+			if ts.wantErr == "" {
+				if err != nil {
+					t.Errorf("TaskRunSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if d := cmp.Diff(ts.wantErr, err.Error()); d != "" {
+				t.Errorf("TaskRunSpec.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskRunSpec_RefNameParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []v1beta1.Param
+		alpha   bool
+		wantErr string
+	}{{
+		name:   "string param",
+		params: []v1beta1.Param{{Name: "language", Value: v1beta1.NewArrayOrString("go")}},
+		alpha:  true,
+	}, {
+		name:    "missing param",
+		alpha:   true,
+		wantErr: `invalid value: "build-$(params.language)" references "language", which isn't a string param of the run: spec.taskref.name`,
+	}, {
+		name:    "array param",
+		params:  []v1beta1.Param{{Name: "language", Value: v1beta1.NewArrayOrString("go", "python")}},
+		alpha:   true,
+		wantErr: `invalid value: "build-$(params.language)" references "language", which isn't a string param of the run: spec.taskref.name`,
+	}, {
+		name:    "alpha fields disabled",
+		params:  []v1beta1.Param{{Name: "language", Value: v1beta1.NewArrayOrString("go")}},
+		wantErr: `params in the name of a reference requires "enable-api-fields" feature gate to be "alpha" but it is "stable": spec.taskref.name`,
+	}}
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			spec := v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "build-$(params.language)"},
+				Params:  ts.params,
+			}
+			ctx := context.Background()
+			if ts.alpha {
+				ctx = withEnabledAPIFields(ctx, config.AlphaAPIFields)
+			}
+			err := spec.Validate(ctx)
+			if ts.wantErr == "" {
+				if err != nil {
+					t.Errorf("TaskRunSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if d := cmp.Diff(ts.wantErr, err.Error()); d != "" {
+				t.Errorf("TaskRunSpec.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestTaskRunSpec_Validate(t *testing.T) {
+	noneDNSPolicy := corev1.DNSNone
+	hostNetDNSPolicy := corev1.DNSClusterFirstWithHostNet
+	ndots := "2"
 	tests := []struct {
 		name string
 		spec v1beta1.TaskRunSpec
@@ -264,6 +685,52 @@ func TestTaskRunSpec_Validate(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "pod template with a projected service account token",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name: "custom-token",
+					VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience: "sigstore",
+								Path:     "token",
+							},
+						}},
+					}},
+				}},
+			},
+		},
+	}, {
+		name: "pod template with dns config",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				DNSPolicy: &noneDNSPolicy,
+				DNSConfig: &corev1.PodDNSConfig{
+					Nameservers: []string{"8.8.8.8", "2001:4860:4860::8888"},
+					Searches:    []string{"tekton.local", "svc.cluster.local."},
+					Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+				},
+			},
+		},
+	}, {
+		name: "pod template with dns policy ClusterFirstWithHostNet and host network",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{
+				HostNetwork: true,
+				DNSPolicy:   &hostNetDNSPolicy,
+			},
+		},
+	}, {
+		name: "pod template with a custom scheduler",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:     &v1beta1.TaskRef{Name: "mytask"},
+			PodTemplate: &v1beta1.PodTemplate{SchedulerName: "batch-scheduler.example.com"},
+		},
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"knative.dev/pkg/apis"
+)
+
+// ValidateEnabledAPIFields checks that the "enable-api-fields" feature flag of the config
+// attached to ctx is set to the version required by the field called name.
+func ValidateEnabledAPIFields(ctx context.Context, name, requiredVersion string) *apis.FieldError {
+	currentVersion := config.FromContextOrDefaults(ctx).FeatureFlags.EnableAPIFields
+	if currentVersion != requiredVersion {
+		return apis.ErrGeneric(fmt.Sprintf("%s requires \"enable-api-fields\" feature gate to be %q but it is %q", name, requiredVersion, currentVersion))
+	}
+	return nil
+}
+
+// ValidateEnabledAPIFields checks that the fields of ts gated by the "enable-api-fields"
+// feature flag are allowed by the config attached to ctx. It is part of ts.Validate, and is
+// also run by the reconciler for Tasks that were created with a different config.
+func (ts *TaskSpec) ValidateEnabledAPIFields(ctx context.Context) *apis.FieldError {
+	for i, s := range ts.Steps {
+		if len(s.EnvFromExternalSecrets) > 0 {
+			if err := ValidateEnabledAPIFields(ctx, "envFromExternalSecrets", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"envFromExternalSecrets"}
+				return err.ViaFieldIndex("steps", i)
+			}
+		}
+		if s.Hermetic {
+			if err := ValidateEnabledAPIFields(ctx, "hermetic", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"hermetic"}
+				return err.ViaFieldIndex("steps", i)
+			}
+		}
+		if s.GPU != nil {
+			if err := ValidateEnabledAPIFields(ctx, "gpu", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"gpu"}
+				return err.ViaFieldIndex("steps", i)
+			}
+		}
+		if s.ForEach != nil {
+			if err := ValidateEnabledAPIFields(ctx, "forEach", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"forEach"}
+				return err.ViaFieldIndex("steps", i)
+			}
+		}
+		if s.SeccompProfile != nil {
+			if err := ValidateEnabledAPIFields(ctx, "seccompProfile", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"seccompProfile"}
+				return err.ViaFieldIndex("steps", i)
+			}
+		}
+	}
+	for i, r := range ts.Results {
+		if r.JSONPath != "" {
+			if err := ValidateEnabledAPIFields(ctx, "jsonPath", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"jsonPath"}
+				return err.ViaFieldIndex("results", i)
+			}
+		}
+		if r.Default != nil {
+			if err := ValidateEnabledAPIFields(ctx, "default", config.AlphaAPIFields); err != nil {
+				err.Paths = []string{"default"}
+				return err.ViaFieldIndex("results", i)
+			}
+		}
+	}
+	if ts.InputValidation != nil {
+		if err := ValidateEnabledAPIFields(ctx, "inputValidation", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"inputValidation"}
+			return err
+		}
+	}
+	if len(ts.Platforms) > 0 {
+		if err := ValidateEnabledAPIFields(ctx, "platforms", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"platforms"}
+			return err
+		}
+	}
+	if len(ts.InitContainers) > 0 {
+		if err := ValidateEnabledAPIFields(ctx, "initContainers", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"initContainers"}
+			return err
+		}
+	}
+	if ts.Hooks != nil {
+		if err := ValidateEnabledAPIFields(ctx, "hooks", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"hooks"}
+			return err
+		}
+	}
+	return nil
+}
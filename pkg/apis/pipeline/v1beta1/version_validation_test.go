@@ -0,0 +1,642 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"knative.dev/pkg/apis"
+)
+
+// withEnabledAPIFields returns a context holding the default config with
+// the "enable-api-fields" feature flag set to version.
+func withEnabledAPIFields(ctx context.Context, version string) context.Context {
+	cfg := config.FromContextOrDefaults(ctx)
+	cfg.FeatureFlags.EnableAPIFields = version
+	return config.ToContext(ctx, cfg)
+}
+
+var alphaTaskSpec = &v1beta1.TaskSpec{
+	Steps: []v1beta1.Step{{
+		Container: corev1.Container{
+			Name:  "mystep",
+			Image: "myimage",
+		},
+		Hermetic: true,
+	}},
+}
+
+func TestValidateEnabledAPIFields(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		enabled         string
+		requiredVersion string
+		wantErr         *apis.FieldError
+	}{{
+		name:            "alpha field with alpha enabled",
+		enabled:         config.AlphaAPIFields,
+		requiredVersion: config.AlphaAPIFields,
+	}, {
+		name:            "alpha field with stable enabled",
+		enabled:         config.StableAPIFields,
+		requiredVersion: config.AlphaAPIFields,
+		wantErr:         apis.ErrGeneric(`myfield requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := withEnabledAPIFields(context.Background(), tc.enabled)
+			err := v1beta1.ValidateEnabledAPIFields(ctx, "myfield", tc.requiredVersion)
+			if d := cmp.Diff(tc.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("ValidateEnabledAPIFields() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskRun_ValidateEnabledAPIFields(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrname"},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec: alphaTaskSpec,
+		},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := tr.Validate(ctx); err != nil {
+		t.Errorf("TaskRun.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `hermetic requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[0].hermetic"},
+	}
+	if d := cmp.Diff(want.Error(), tr.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskRun.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRun_ValidateEnabledAPIFields(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipelinerunname"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineSpec: &v1beta1.PipelineSpec{
+				Tasks: []v1beta1.PipelineTask{{
+					Name:     "mytask",
+					TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: alphaTaskSpec},
+				}},
+			},
+		},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := pr.Validate(ctx); err != nil {
+		t.Errorf("PipelineRun.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	err := pr.Validate(ctx)
+	if err == nil {
+		t.Fatal("PipelineRun.Validate() with stable fields enabled: expected an error, got none")
+	}
+	want := `hermetic requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`
+	if d := cmp.Diff(want, err.Message); d != "" {
+		t.Errorf("PipelineRun.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_EnvFromExternalSecrets(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}, {
+			Container:              corev1.Container{Name: "myotherstep", Image: "myimage"},
+			EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{Name: "myexternalsecret"}},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `envFromExternalSecrets requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[1].envFromExternalSecrets"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_Hermetic(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+			Hermetic:  true,
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `hermetic requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[0].hermetic"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_GPU(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+			GPU:       &v1beta1.StepGPU{Vendor: v1beta1.GPUVendorNVIDIA, Count: 1},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `gpu requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[0].gpu"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_SeccompProfile(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container:      corev1.Container{Name: "mystep", Image: "myimage"},
+			SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `seccompProfile requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[0].seccompProfile"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_ForEach(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{{Name: "targets", Type: v1beta1.ParamTypeArray}},
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+			ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `forEach requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"steps[0].forEach"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_InitContainers(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		InitContainers: []corev1.Container{{Name: "setup", Image: "myimage"}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `initContainers requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"initContainers"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_Platforms(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `platforms requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"platforms"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_Hooks(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		Hooks: &v1beta1.TaskHooks{
+			PreStep:  []string{"refresh-credentials"},
+			PostStep: []string{"echo exiting"},
+		},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `hooks requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"hooks"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_InputValidation(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		InputValidation: &v1beta1.InputValidation{
+			ConfigMapRef: &v1beta1.InputValidationConfigMapRef{Name: "schemas"},
+		},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `inputValidation requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"inputValidation"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_ResultJSONPath(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		Results: []v1beta1.TaskResult{{Name: "digest", JSONPath: "{.image.digest}"}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `jsonPath requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"results[0].jsonPath"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskSpec_ValidateEnabledAPIFields_ResultDefault(t *testing.T) {
+	digest := "none"
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "mystep", Image: "myimage"},
+		}},
+		Results: []v1beta1.TaskResult{{Name: "digest", Default: &digest}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `default requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"results[0].default"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineSpec_ValidateEnabledAPIFields_OptionalWorkspaces(t *testing.T) {
+	ps := &v1beta1.PipelineSpec{
+		Workspaces: []v1beta1.PipelineWorkspaceDeclaration{{Name: "cache", Optional: true}},
+		Tasks: []v1beta1.PipelineTask{{
+			Name:       "restore-cache",
+			TaskRef:    &v1beta1.TaskRef{Name: "restore"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{Name: "cache", Workspace: "cache"}},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `optional workspaces requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.workspaces[0].optional"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineSpec_ValidateEnabledAPIFields_WhenExpressions(t *testing.T) {
+	ps := &v1beta1.PipelineSpec{
+		Workspaces: []v1beta1.PipelineWorkspaceDeclaration{{Name: "cache"}},
+		Tasks: []v1beta1.PipelineTask{{
+			Name:    "restore-cache",
+			TaskRef: &v1beta1.TaskRef{Name: "restore"},
+			WhenExpressions: v1beta1.WhenExpressions{{
+				Input:    "$(workspaces.cache.bound)",
+				Operator: selection.In,
+				Values:   []string{"true"},
+			}},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `when expressions requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.tasks[0].when"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineSpec_Validate_WhenScope(t *testing.T) {
+	when := v1beta1.WhenExpressions{{
+		Input:    "$(params.deploy)",
+		Operator: selection.In,
+		Values:   []string{"true"},
+	}}
+	tests := []struct {
+		name    string
+		task    v1beta1.PipelineTask
+		wantErr *apis.FieldError
+	}{{
+		name: "task scope",
+		task: v1beta1.PipelineTask{Name: "deploy", TaskRef: &v1beta1.TaskRef{Name: "deploy"}, WhenExpressions: when, WhenScope: v1beta1.WhenScopeTask},
+	}, {
+		name: "branch scope",
+		task: v1beta1.PipelineTask{Name: "deploy", TaskRef: &v1beta1.TaskRef{Name: "deploy"}, WhenExpressions: when, WhenScope: v1beta1.WhenScopeBranch},
+	}, {
+		name:    "invalid scope",
+		task:    v1beta1.PipelineTask{Name: "deploy", TaskRef: &v1beta1.TaskRef{Name: "deploy"}, WhenExpressions: when, WhenScope: "Pipeline"},
+		wantErr: apis.ErrInvalidValue("Pipeline should be Branch or Task", "spec.tasks[0].whenScope"),
+	}, {
+		name:    "scope without when expressions",
+		task:    v1beta1.PipelineTask{Name: "deploy", TaskRef: &v1beta1.TaskRef{Name: "deploy"}, WhenScope: v1beta1.WhenScopeTask},
+		wantErr: apis.ErrGeneric("whenScope requires when expressions", "spec.tasks[0].whenScope"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &v1beta1.PipelineSpec{
+				Params: []v1beta1.ParamSpec{{Name: "deploy", Type: v1beta1.ParamTypeString}},
+				Tasks:  []v1beta1.PipelineTask{tt.task},
+			}
+			ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+			err := ps.Validate(ctx)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("PipelineSpec.Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("PipelineSpec.Validate() did not return an error")
+			}
+			if d := cmp.Diff(tt.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("PipelineSpec.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineSpec_ValidateEnabledAPIFields_WorkspaceConditions(t *testing.T) {
+	ps := &v1beta1.PipelineSpec{
+		Params:     []v1beta1.ParamSpec{{Name: "cache-enabled", Type: v1beta1.ParamTypeString}},
+		Workspaces: []v1beta1.PipelineWorkspaceDeclaration{{Name: "cache"}},
+		Tasks: []v1beta1.PipelineTask{{
+			Name:    "build",
+			TaskRef: &v1beta1.TaskRef{Name: "build"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{
+				Name:      "cache",
+				Workspace: "cache",
+				Condition: `$(params.cache-enabled) == "true"`,
+			}},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `workspace binding conditions requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.tasks[0].workspaces[0].condition"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineSpec_ValidateEnabledAPIFields_Bundle(t *testing.T) {
+	ps := &v1beta1.PipelineSpec{
+		Tasks: []v1beta1.PipelineTask{{
+			Name:    "build",
+			TaskRef: &v1beta1.TaskRef{Name: "build", Bundle: "registry.example.com/tasks:v1"},
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `bundles requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.tasks[0].taskRef.bundle"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+
+	ps.Tasks[0].TaskRef.Bundle = "registry.example.com/tasks:not a tag"
+	ctx = withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err == nil {
+		t.Error("PipelineSpec.Validate() with an invalid bundle reference did not return an error")
+	}
+}
+
+func TestTaskRunSpec_ValidateEnabledAPIFields_StepOverrides(t *testing.T) {
+	ts := &v1beta1.TaskRunSpec{
+		TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+		StepOverrides: []v1beta1.TaskRunStepOverride{{
+			Name:            "build",
+			ImagePullPolicy: corev1.PullAlways,
+		}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskRunSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `stepOverrides requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.stepOverrides"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskRunSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskRunSpec_ValidateEnabledAPIFields_Debug(t *testing.T) {
+	ts := &v1beta1.TaskRunSpec{
+		TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+		Debug: &v1beta1.TaskRunDebug{
+			AddEphemeralContainer: &corev1.EphemeralContainer{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"},
+			},
+		},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ts.Validate(ctx); err != nil {
+		t.Errorf("TaskRunSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `debug requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.debug.addEphemeralContainer"},
+	}
+	if d := cmp.Diff(want.Error(), ts.Validate(ctx).Error()); d != "" {
+		t.Errorf("TaskRunSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_ValidateEnabledAPIFields_Concurrency(t *testing.T) {
+	ps := &v1beta1.PipelineRunSpec{
+		PipelineRef: &v1beta1.PipelineRef{Name: "mypipeline"},
+		Concurrency: &v1beta1.PipelineRunConcurrency{Key: "production"},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineRunSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `concurrency requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.concurrency"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunSpec_ValidateEnabledAPIFields_TriggerOnConfigMapChange(t *testing.T) {
+	ps := &v1beta1.PipelineRunSpec{
+		PipelineRef:              &v1beta1.PipelineRef{Name: "mypipeline"},
+		TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "settings"}},
+	}
+
+	ctx := withEnabledAPIFields(context.Background(), config.AlphaAPIFields)
+	if err := ps.Validate(ctx); err != nil {
+		t.Errorf("PipelineRunSpec.Validate() with alpha fields enabled = %v", err)
+	}
+
+	ctx = withEnabledAPIFields(context.Background(), config.StableAPIFields)
+	want := &apis.FieldError{
+		Message: `triggerOnConfigMapChange requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+		Paths:   []string{"spec.triggerOnConfigMapChange"},
+	}
+	if d := cmp.Diff(want.Error(), ps.Validate(ctx).Error()); d != "" {
+		t.Errorf("PipelineRunSpec.Validate() with stable fields enabled %s", diff.PrintWantGot(d))
+	}
+}
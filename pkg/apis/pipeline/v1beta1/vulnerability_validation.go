@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// VulnerabilityLookup fetches the objects needed to check the images of the
+// Steps of a TaskRun for known critical vulnerabilities.
+type VulnerabilityLookup interface {
+	// GetTaskSpec returns the spec of the referenced Task or ClusterTask, or
+	// nil if it doesn't exist (yet).
+	GetTaskSpec(ctx context.Context, namespace string, ref *TaskRef) (*TaskSpec, error)
+	// CriticalVulnerabilities returns the IDs of the critical vulnerabilities
+	// the scans of the namespace found in image.
+	CriticalVulnerabilities(ctx context.Context, namespace, image string) ([]string, error)
+}
+
+type vulnerabilityLookupKey struct{}
+
+// WithVulnerabilityLookup enables blocking the creation of TaskRuns whose
+// Steps use images with known critical vulnerabilities, using l to look them
+// up, when the "block-on-critical-vulns" feature flag is set.
+func WithVulnerabilityLookup(ctx context.Context, l VulnerabilityLookup) context.Context {
+	return context.WithValue(ctx, vulnerabilityLookupKey{}, l)
+}
+
+func getVulnerabilityLookup(ctx context.Context) VulnerabilityLookup {
+	l, _ := ctx.Value(vulnerabilityLookupKey{}).(VulnerabilityLookup)
+	return l
+}
+
+// validateStepImages checks that none of the images of the Steps of tr has
+// known critical vulnerabilities. Images set with variables can't be known
+// until the TaskRun runs and aren't checked.
+func (tr *TaskRun) validateStepImages(ctx context.Context, l VulnerabilityLookup) *apis.FieldError {
+	ts := tr.Spec.TaskSpec
+	if ts == nil {
+		var err error
+		if ts, err = l.GetTaskSpec(ctx, tr.Namespace, tr.Spec.TaskRef); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("failed to get Task %q to check the images of its steps for vulnerabilities: %v", tr.Spec.TaskRef.Name, err),
+				Paths:   []string{"spec.taskRef"},
+			}
+		}
+	}
+	if ts == nil {
+		return nil
+	}
+
+	var errs *apis.FieldError
+	for i, s := range ts.Steps {
+		image := s.Image
+		if image == "" && ts.StepTemplate != nil {
+			image = ts.StepTemplate.Image
+		}
+		if image == "" || strings.Contains(image, "$(") {
+			continue
+		}
+		path := "spec.taskRef"
+		if tr.Spec.TaskSpec != nil {
+			path = fmt.Sprintf("spec.taskSpec.steps[%d].image", i)
+		}
+		vulns, err := l.CriticalVulnerabilities(ctx, tr.Namespace, image)
+		if err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("failed to look up the vulnerabilities of image %q: %v", image, err),
+				Paths:   []string{path},
+			})
+			continue
+		}
+		if len(vulns) > 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("image %q of step %q has known critical vulnerabilities: %s", image, s.Name, strings.Join(vulns, ", ")),
+				Paths:   []string{path},
+			})
+		}
+	}
+	return errs
+}
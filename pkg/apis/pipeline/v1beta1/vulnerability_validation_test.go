@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+type fakeVulnerabilityLookup struct {
+	tasks map[string]*v1beta1.TaskSpec
+	vulns map[string][]string
+}
+
+func (l *fakeVulnerabilityLookup) GetTaskSpec(_ context.Context, _ string, ref *v1beta1.TaskRef) (*v1beta1.TaskSpec, error) {
+	return l.tasks[ref.Name], nil
+}
+
+func (l *fakeVulnerabilityLookup) CriticalVulnerabilities(_ context.Context, _, image string) ([]string, error) {
+	if image == "unreachable" {
+		return nil, errors.New("connection refused")
+	}
+	return l.vulns[image], nil
+}
+
+func withBlockOnCriticalVulns(ctx context.Context) context.Context {
+	cfg := config.FromContextOrDefaults(ctx)
+	cfg.FeatureFlags.BlockOnCriticalVulns = true
+	return config.ToContext(ctx, cfg)
+}
+
+func TestTaskRun_ValidateStepImages(t *testing.T) {
+	build := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{
+			{Container: corev1.Container{Name: "compile", Image: "golang:1.15"}},
+			{Container: corev1.Container{Name: "test", Image: "ubuntu"}},
+		},
+	}
+	lookup := &fakeVulnerabilityLookup{
+		tasks: map[string]*v1beta1.TaskSpec{"build": build},
+		vulns: map[string][]string{"ubuntu": {"CVE-2021-3156", "CVE-2021-3177"}},
+	}
+	taskRun := func(spec *v1beta1.TaskSpec) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+			Spec:       v1beta1.TaskRunSpec{TaskSpec: spec},
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		tr   *v1beta1.TaskRun
+		want *apis.FieldError
+	}{{
+		name: "referenced task",
+		tr: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "build"}},
+		},
+		want: &apis.FieldError{
+			Message: `image "ubuntu" of step "test" has known critical vulnerabilities: CVE-2021-3156, CVE-2021-3177`,
+			Paths:   []string{"spec.taskRef"},
+		},
+	}, {
+		name: "embedded task spec",
+		tr:   taskRun(build),
+		want: &apis.FieldError{
+			Message: `image "ubuntu" of step "test" has known critical vulnerabilities: CVE-2021-3156, CVE-2021-3177`,
+			Paths:   []string{"spec.taskSpec.steps[1].image"},
+		},
+	}, {
+		name: "image of step template",
+		tr: taskRun(&v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{Image: "ubuntu"},
+			Steps:        []v1beta1.Step{{Container: corev1.Container{Name: "test"}}},
+		}),
+		want: &apis.FieldError{
+			Message: `image "ubuntu" of step "test" has known critical vulnerabilities: CVE-2021-3156, CVE-2021-3177`,
+			Paths:   []string{"spec.taskSpec.steps[0].image"},
+		},
+	}, {
+		name: "no vulnerable image",
+		tr: taskRun(&v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Name: "compile", Image: "golang:1.15"}}},
+		}),
+	}, {
+		name: "image set with a param",
+		tr: taskRun(&v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "image", Type: v1beta1.ParamTypeString}},
+			Steps:  []v1beta1.Step{{Container: corev1.Container{Name: "test", Image: "$(params.image)"}}},
+		}),
+	}, {
+		name: "task not found",
+		tr: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+			Spec:       v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "missing"}},
+		},
+	}, {
+		name: "lookup failure",
+		tr: taskRun(&v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Name: "test", Image: "unreachable"}}},
+		}),
+		want: &apis.FieldError{
+			Message: `failed to look up the vulnerabilities of image "unreachable": connection refused`,
+			Paths:   []string{"spec.taskSpec.steps[0].image"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := withBlockOnCriticalVulns(context.Background())
+			ctx = apis.WithinCreate(v1beta1.WithVulnerabilityLookup(ctx, lookup))
+			err := tc.tr.Validate(ctx)
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("TaskRun.Validate() = %v", err)
+				}
+				return
+			}
+			if d := cmp.Diff(tc.want.Error(), err.Error()); d != "" {
+				t.Errorf("TaskRun.Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+
+	t.Run("not blocked without the feature flag", func(t *testing.T) {
+		ctx := apis.WithinCreate(v1beta1.WithVulnerabilityLookup(context.Background(), lookup))
+		if err := taskRun(build).Validate(ctx); err != nil {
+			t.Errorf("TaskRun.Validate() = %v", err)
+		}
+	})
+
+	t.Run("not blocked on update", func(t *testing.T) {
+		tr := taskRun(build)
+		ctx := withBlockOnCriticalVulns(context.Background())
+		ctx = apis.WithinUpdate(v1beta1.WithVulnerabilityLookup(ctx, lookup), tr)
+		if err := tr.Validate(ctx); err != nil {
+			t.Errorf("TaskRun.Validate() = %v", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/tektoncd/pipeline/pkg/substitution"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// WhenScope defines what is skipped when the when expressions of a PipelineTask
+// evaluate to false
+type WhenScope string
+
+const (
+	// WhenScopeBranch skips the PipelineTask and the PipelineTasks depending on it
+	WhenScopeBranch WhenScope = "Branch"
+	// WhenScopeTask skips only the PipelineTask: the PipelineTasks depending on it
+	// still run, using the default values of its results
+	WhenScopeTask WhenScope = "Task"
+)
+
+// WhenExpression allows a PipelineTask to declare expressions to be evaluated before the Task
+// is run to determine whether the Task should be executed or skipped
+type WhenExpression struct {
+	// Input is the string for guard checking which can be a static input or a variable
+	Input string `json:"input"`
+	// Operator that represents an Input's relationship to the values
+	Operator selection.Operator `json:"operator"`
+	// Values is an array of strings, which is compared against the input, for guard checking
+	Values []string `json:"values"`
+}
+
+func (we *WhenExpression) isTrue() bool {
+	in := false
+	for _, v := range we.Values {
+		if v == we.Input {
+			in = true
+			break
+		}
+	}
+	if we.Operator == selection.NotIn {
+		return !in
+	}
+	return in
+}
+
+func (we *WhenExpression) applyReplacements(replacements map[string]string) WhenExpression {
+	replacedValues := make([]string, 0, len(we.Values))
+	for _, v := range we.Values {
+		replacedValues = append(replacedValues, substitution.ApplyReplacements(v, replacements))
+	}
+	return WhenExpression{
+		Input:    substitution.ApplyReplacements(we.Input, replacements),
+		Operator: we.Operator,
+		Values:   replacedValues,
+	}
+}
+
+// WhenExpressions are used to specify whether a Task should be executed or skipped
+// All of them need to evaluate to True for a guarded Task to be executed.
+type WhenExpressions []WhenExpression
+
+// AllowsExecution evaluates an Input's relationship to an array of Values, based on the Operator,
+// to determine whether all the When Expressions are True. If they are all True, the guarded Task is
+// executed, otherwise it is skipped.
+func (wes WhenExpressions) AllowsExecution() bool {
+	for _, we := range wes {
+		if !we.isTrue() {
+			return false
+		}
+	}
+	return true
+}
+
+// ReplaceWhenExpressionsVariables interpolates variables, such as Parameters and the binding of
+// Workspaces, in the Input and Values of the WhenExpressions
+func (wes WhenExpressions) ReplaceWhenExpressionsVariables(replacements map[string]string) WhenExpressions {
+	if wes == nil {
+		return nil
+	}
+	replaced := make(WhenExpressions, 0, len(wes))
+	for _, we := range wes {
+		replaced = append(replaced, we.applyReplacements(replacements))
+	}
+	return replaced
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestAllowsExecution(t *testing.T) {
+	tests := []struct {
+		name            string
+		whenExpressions WhenExpressions
+		expected        bool
+	}{{
+		name: "in expression",
+		whenExpressions: WhenExpressions{{
+			Input:    "foo",
+			Operator: selection.In,
+			Values:   []string{"foo", "bar"},
+		}},
+		expected: true,
+	}, {
+		name: "notin expression",
+		whenExpressions: WhenExpressions{{
+			Input:    "foobar",
+			Operator: selection.NotIn,
+			Values:   []string{"foobar"},
+		}},
+		expected: false,
+	}, {
+		name: "multiple expressions - false",
+		whenExpressions: WhenExpressions{{
+			Input:    "foo",
+			Operator: selection.In,
+			Values:   []string{"foo", "bar"},
+		}, {
+			Input:    "foobar",
+			Operator: selection.NotIn,
+			Values:   []string{"foobar"},
+		}},
+		expected: false,
+	}, {
+		name: "multiple expressions - true",
+		whenExpressions: WhenExpressions{{
+			Input:    "foo",
+			Operator: selection.In,
+			Values:   []string{"foo", "bar"},
+		}, {
+			Input:    "foobar",
+			Operator: selection.NotIn,
+			Values:   []string{"foo"},
+		}},
+		expected: true,
+	}, {
+		name:     "no expressions",
+		expected: true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.whenExpressions.AllowsExecution(); got != tc.expected {
+				t.Errorf("AllowsExecution() = %t, want %t", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReplaceWhenExpressionsVariables(t *testing.T) {
+	whenExpressions := WhenExpressions{{
+		Input:    "$(workspaces.cache.bound)",
+		Operator: selection.In,
+		Values:   []string{"$(params.expected)"},
+	}}
+	replacements := map[string]string{
+		"workspaces.cache.bound": "true",
+		"params.expected":        "false",
+	}
+	expected := WhenExpressions{{
+		Input:    "true",
+		Operator: selection.In,
+		Values:   []string{"false"},
+	}}
+	got := whenExpressions.ReplaceWhenExpressionsVariables(replacements)
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("ReplaceWhenExpressionsVariables() %s", diff.PrintWantGot(d))
+	}
+	if whenExpressions[0].Input != "$(workspaces.cache.bound)" {
+		t.Errorf("Expected the original when expressions to be left unchanged, got %v", whenExpressions)
+	}
+}
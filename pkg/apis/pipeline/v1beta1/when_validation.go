@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+var validWhenOperators = []string{
+	string(selection.In),
+	string(selection.NotIn),
+}
+
+func (wes WhenExpressions) validate() *apis.FieldError {
+	for i, we := range wes {
+		if err := we.validate(); err != nil {
+			return err.ViaIndex(i)
+		}
+	}
+	return nil
+}
+
+func (we *WhenExpression) validate() *apis.FieldError {
+	if we.Input == "" {
+		return apis.ErrMissingField("input")
+	}
+	if !sets.NewString(validWhenOperators...).Has(string(we.Operator)) {
+		message := fmt.Sprintf("operator %q is not recognized. valid operators: %v", we.Operator, validWhenOperators)
+		return apis.ErrInvalidValue(message, "operator")
+	}
+	if len(we.Values) == 0 {
+		return apis.ErrMissingField("values")
+	}
+	return nil
+}
+
+// getVariables returns the Input and the Values of the WhenExpressions, which may reference variables
+func (wes WhenExpressions) getVariables() []string {
+	var vars []string
+	for _, we := range wes {
+		vars = append(vars, we.Input)
+		vars = append(vars, we.Values...)
+	}
+	return vars
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestWhenExpressions_Valid(t *testing.T) {
+	wes := WhenExpressions{{
+		Input:    "$(workspaces.cache.bound)",
+		Operator: selection.In,
+		Values:   []string{"true"},
+	}, {
+		Input:    "$(params.branch)",
+		Operator: selection.NotIn,
+		Values:   []string{"main", "release"},
+	}}
+	if err := wes.validate(); err != nil {
+		t.Errorf("WhenExpressions.validate() returned an error for valid when expressions: %v", err)
+	}
+}
+
+func TestWhenExpressions_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		wes  WhenExpressions
+	}{{
+		name: "missing input",
+		wes: WhenExpressions{{
+			Operator: selection.In,
+			Values:   []string{"true"},
+		}},
+	}, {
+		name: "invalid operator",
+		wes: WhenExpressions{{
+			Input:    "foo",
+			Operator: selection.Exists,
+			Values:   []string{"foo"},
+		}},
+	}, {
+		name: "missing values",
+		wes: WhenExpressions{{
+			Input:    "foo",
+			Operator: selection.In,
+		}},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.wes.validate(); err == nil {
+				t.Errorf("WhenExpressions.validate() did not return an error for invalid when expressions: %s", tc.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/substitution"
+	"knative.dev/pkg/apis"
+)
+
+// workspaceConditionRegex matches the conditions of workspace bindings: two operands,
+// each being a double-quoted string or a sequence of non-blank characters, compared
+// with == or !=.
+var workspaceConditionRegex = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*"|[^\s"=!]+)\s*(==|!=)\s*("(?:[^"\\]|\\.)*"|[^\s"=!]+)\s*$`)
+
+type workspaceCondition struct {
+	left     string
+	operator string
+	right    string
+}
+
+func parseWorkspaceCondition(condition string) (workspaceCondition, error) {
+	m := workspaceConditionRegex.FindStringSubmatch(condition)
+	if m == nil {
+		return workspaceCondition{}, fmt.Errorf("condition %q should compare two operands with == or !=", condition)
+	}
+	left, err := unquoteOperand(m[1])
+	if err != nil {
+		return workspaceCondition{}, fmt.Errorf("condition %q: invalid operand %s: %w", condition, m[1], err)
+	}
+	right, err := unquoteOperand(m[3])
+	if err != nil {
+		return workspaceCondition{}, fmt.Errorf("condition %q: invalid operand %s: %w", condition, m[3], err)
+	}
+	return workspaceCondition{left: left, operator: m[2], right: right}, nil
+}
+
+func unquoteOperand(operand string) (string, error) {
+	if strings.HasPrefix(operand, `"`) {
+		return strconv.Unquote(operand)
+	}
+	return operand, nil
+}
+
+func (c workspaceCondition) isTrue() bool {
+	return (c.left == c.right) == (c.operator == "==")
+}
+
+func (c workspaceCondition) String() string {
+	return fmt.Sprintf("%s %s %s", strconv.Quote(c.left), c.operator, strconv.Quote(c.right))
+}
+
+// IsConditionTrue returns true if the workspace should be bound, which is the case
+// when the binding has no Condition or its Condition is true.
+func (b WorkspacePipelineTaskBinding) IsConditionTrue() (bool, error) {
+	if b.Condition == "" {
+		return true, nil
+	}
+	c, err := parseWorkspaceCondition(b.Condition)
+	if err != nil {
+		return false, err
+	}
+	return c.isTrue(), nil
+}
+
+// ReplaceConditionVariables interpolates variables, such as Parameters, in the operands
+// of the Condition of the binding. The operands are quoted afterwards, so that values
+// containing blanks or quotes don't change the meaning of the Condition.
+func (b *WorkspacePipelineTaskBinding) ReplaceConditionVariables(replacements map[string]string) {
+	if b.Condition == "" {
+		return
+	}
+	c, err := parseWorkspaceCondition(b.Condition)
+	if err != nil {
+		// Invalid conditions are left as is, the error is reported when evaluating them
+		return
+	}
+	c.left = substitution.ApplyReplacements(c.left, replacements)
+	c.right = substitution.ApplyReplacements(c.right, replacements)
+	b.Condition = c.String()
+}
+
+// validateCondition checks that the Condition of the binding can be parsed and doesn't
+// reference tasks, whose results and status aren't known when their TaskRuns are created.
+func (b WorkspacePipelineTaskBinding) validateCondition() *apis.FieldError {
+	if _, err := parseWorkspaceCondition(b.Condition); err != nil {
+		return apis.ErrInvalidValue(err.Error(), "condition")
+	}
+	for _, expression := range validateString(b.Condition) {
+		if strings.HasPrefix(expression, ResultTaskPart+".") {
+			return apis.ErrInvalidValue(fmt.Sprintf("condition %q can't reference the results or the status of tasks", b.Condition), "condition")
+		}
+	}
+	return nil
+}
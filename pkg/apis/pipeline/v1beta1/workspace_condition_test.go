@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+)
+
+func TestWorkspacePipelineTaskBinding_IsConditionTrue(t *testing.T) {
+	for _, tc := range []struct {
+		condition string
+		want      bool
+	}{
+		{condition: "", want: true},
+		{condition: `"true" == "true"`, want: true},
+		{condition: `true == "true"`, want: true},
+		{condition: `true=="true"`, want: true},
+		{condition: ` "false" == true `, want: false},
+		{condition: `"false" != "true"`, want: true},
+		{condition: `"a b" == "a b"`, want: true},
+		{condition: `"say \"hi\"" != "hi"`, want: true},
+		{condition: `"" == ""`, want: true},
+	} {
+		t.Run(tc.condition, func(t *testing.T) {
+			got, err := WorkspacePipelineTaskBinding{Condition: tc.condition}.IsConditionTrue()
+			if err != nil {
+				t.Fatalf("IsConditionTrue() = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsConditionTrue() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWorkspacePipelineTaskBinding_IsConditionTrue_Invalid(t *testing.T) {
+	for _, condition := range []string{
+		"true",
+		`"true" = "true"`,
+		`"true" == `,
+		`a b == c`,
+		`"true" == "true" == "true"`,
+		`"unterminated == true`,
+	} {
+		t.Run(condition, func(t *testing.T) {
+			if _, err := (WorkspacePipelineTaskBinding{Condition: condition}).IsConditionTrue(); err == nil {
+				t.Errorf("IsConditionTrue() should fail for condition %q", condition)
+			}
+		})
+	}
+}
+
+func TestWorkspacePipelineTaskBinding_ReplaceConditionVariables(t *testing.T) {
+	replacements := map[string]string{
+		"params.cache-enabled": "true",
+		"params.label":         `with "quotes" == and blanks`,
+	}
+	for _, tc := range []struct {
+		condition string
+		want      string
+	}{{
+		condition: "",
+		want:      "",
+	}, {
+		condition: `$(params.cache-enabled) == "true"`,
+		want:      `"true" == "true"`,
+	}, {
+		condition: `"$(params.label)" != $(params.cache-enabled)`,
+		want:      `"with \"quotes\" == and blanks" != "true"`,
+	}, {
+		condition: "not a condition $(params.cache-enabled)",
+		want:      "not a condition $(params.cache-enabled)",
+	}} {
+		t.Run(tc.condition, func(t *testing.T) {
+			b := WorkspacePipelineTaskBinding{Condition: tc.condition}
+			b.ReplaceConditionVariables(replacements)
+			if b.Condition != tc.want {
+				t.Errorf("ReplaceConditionVariables() = %q, want %q", b.Condition, tc.want)
+			}
+		})
+	}
+}
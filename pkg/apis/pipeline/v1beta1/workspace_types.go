@@ -55,6 +55,16 @@ type WorkspaceBinding struct {
 	// for this binding (i.e. the volume will be mounted at this sub directory).
 	// +optional
 	SubPath string `json:"subPath,omitempty"`
+	// SubPathPerRun appends the name of the run to the SubPath, so that each
+	// TaskRun or PipelineRun sharing the volume uses its own directory of it.
+	// The TaskRuns of a PipelineRun all use the directory of the PipelineRun.
+	// +optional
+	SubPathPerRun bool `json:"subPathPerRun,omitempty"`
+	// ReadOnly dictates whether the volume is mounted read-only, regardless of
+	// the ReadOnly field of the matching WorkspaceDeclaration. By default this
+	// field is false and the volume is only read-only if the declaration is.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
 	// VolumeClaimTemplate is a template for a claim that will be created in the same namespace.
 	// The PipelineRun controller is responsible for creating a unique claim for each instance of PipelineRun.
 	// +optional
@@ -74,6 +84,15 @@ type WorkspaceBinding struct {
 	// Secret represents a secret that should populate this workspace.
 	// +optional
 	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+	// CSI represents ephemeral storage handled by a CSI driver, like the secrets
+	// of a secrets store, that should populate this workspace.
+	// +optional
+	CSI *corev1.CSIVolumeSource `json:"csi,omitempty"`
+	// CleanupAfterCompletion deletes the PersistentVolumeClaim backing this
+	// workspace once the TaskRun using it has succeeded, as long as no other
+	// TaskRun still references the claim.
+	// +optional
+	CleanupAfterCompletion bool `json:"cleanupAfterCompletion,omitempty"`
 }
 
 // WorkspacePipelineDeclaration creates a named slot in a Pipeline that a PipelineRun
@@ -91,6 +110,10 @@ type PipelineWorkspaceDeclaration struct {
 	// tasks are intended to have access to the data on the workspace.
 	// +optional
 	Description string `json:"description,omitempty"`
+	// Optional marks a workspace as not being required in PipelineRuns. The
+	// PipelineTasks using an optional workspace which isn't bound are skipped.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
 }
 
 // WorkspacePipelineTaskBinding describes how a workspace passed into the pipeline should be
@@ -104,4 +127,9 @@ type WorkspacePipelineTaskBinding struct {
 	// for this binding (i.e. the volume will be mounted at this sub directory).
 	// +optional
 	SubPath string `json:"subPath,omitempty"`
+	// Condition is optionally an expression comparing two operands with == or !=,
+	// e.g. $(params.cache-enabled) == "true". The workspace is only bound when it
+	// is true, otherwise the Task gets an emptyDir.
+	// +optional
+	Condition string `json:"condition,omitempty"`
 }
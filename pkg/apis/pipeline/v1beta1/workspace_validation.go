@@ -18,7 +18,11 @@ package v1beta1
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"knative.dev/pkg/apis"
 )
@@ -31,6 +35,7 @@ var allVolumeSourceFields []string = []string{
 	"emptydir",
 	"configmap",
 	"secret",
+	"csi",
 }
 
 // Validate looks at the Volume provided in wb and makes sure that it is valid.
@@ -41,14 +46,18 @@ func (b *WorkspaceBinding) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
 
-	numSources := b.numSources()
+	sources := b.sources()
 
-	if numSources > 1 {
-		return apis.ErrMultipleOneOf(allVolumeSourceFields...)
+	if len(sources) > 1 {
+		err := apis.ErrMultipleOneOf(sources...)
+		err.Details = fmt.Sprintf("workspace binding %q must use exactly one volume source", b.Name)
+		return err
 	}
 
-	if numSources == 0 {
-		return apis.ErrMissingOneOf(allVolumeSourceFields...)
+	if len(sources) == 0 {
+		err := apis.ErrMissingOneOf(allVolumeSourceFields...)
+		err.Details = fmt.Sprintf("workspace binding %q must use exactly one volume source", b.Name)
+		return err
 	}
 
 	// For a PersistentVolumeClaim to work, you must at least provide the name of the PVC to use.
@@ -66,27 +75,91 @@ func (b *WorkspaceBinding) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("secret.secretName")
 	}
 
+	// For a CSI volume to work, you must provide the name of the CSI driver handling it.
+	if b.CSI != nil && b.CSI.Driver == "" {
+		return apis.ErrMissingField("csi.driver")
+	}
+
+	if b.SubPathPerRun {
+		if err := ValidateEnabledAPIFields(ctx, "subPathPerRun", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"subPathPerRun"}
+			return err
+		}
+		// The other volume sources aren't shared by several runs.
+		if b.PersistentVolumeClaim == nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("workspace binding %q can only use a subPath per run when backed by a persistentVolumeClaim", b.Name),
+				Paths:   []string{"subPathPerRun"},
+			}
+		}
+	}
+
+	if b.CleanupAfterCompletion {
+		if err := ValidateEnabledAPIFields(ctx, "cleanupAfterCompletion", config.AlphaAPIFields); err != nil {
+			err.Paths = []string{"cleanupAfterCompletion"}
+			return err
+		}
+		// Only claims can be deleted once the TaskRun is done; the other
+		// volume sources are not owned by the workspace.
+		if b.PersistentVolumeClaim == nil && b.VolumeClaimTemplate == nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("workspace binding %q can only be cleaned up when backed by a persistentVolumeClaim or volumeClaimTemplate", b.Name),
+				Paths:   []string{"cleanupAfterCompletion"},
+			}
+		}
+	}
+
 	return nil
 }
 
-// numSources returns the total number of volume sources that this WorkspaceBinding
-// has been configured with.
-func (b *WorkspaceBinding) numSources() int {
-	n := 0
-	if b.VolumeClaimTemplate != nil {
-		n++
-	}
+// sources returns the field paths of the volume sources that this
+// WorkspaceBinding has been configured with.
+func (b *WorkspaceBinding) sources() []string {
+	var s []string
 	if b.PersistentVolumeClaim != nil {
-		n++
+		s = append(s, "persistentvolumeclaim")
+	}
+	if b.VolumeClaimTemplate != nil {
+		s = append(s, "volumeclaimtemplate")
 	}
 	if b.EmptyDir != nil {
-		n++
+		s = append(s, "emptydir")
 	}
 	if b.ConfigMap != nil {
-		n++
+		s = append(s, "configmap")
 	}
 	if b.Secret != nil {
-		n++
+		s = append(s, "secret")
+	}
+	if b.CSI != nil {
+		s = append(s, "csi")
+	}
+	return s
+}
+
+// ValidateWorkspaceBindingCollisions returns an error naming both bindings if
+// two of the workspace bindings of a run mount the same PersistentVolumeClaim
+// at the same subPath: the workspaces would then silently share their files.
+func ValidateWorkspaceBindingCollisions(wb []WorkspaceBinding) *apis.FieldError {
+	type claimPath struct{ claim, subPath string }
+	seen := map[claimPath]string{}
+	for _, b := range wb {
+		if b.PersistentVolumeClaim == nil {
+			continue
+		}
+		key := claimPath{claim: b.PersistentVolumeClaim.ClaimName, subPath: cleanSubPath(b.SubPath)}
+		if other, ok := seen[key]; ok {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("workspace bindings %q and %q both mount persistentVolumeClaim %q at subPath %q", other, b.Name, key.claim, key.subPath),
+				Paths:   []string{"workspaces"},
+			}
+		}
+		seen[key] = b.Name
 	}
-	return n
+	return nil
+}
+
+// cleanSubPath normalizes subPath so that e.g. "", "." and "/" compare equal.
+func cleanSubPath(subPath string) string {
+	return strings.Trim(filepath.ToSlash(filepath.Clean("/"+subPath)), "/")
 }
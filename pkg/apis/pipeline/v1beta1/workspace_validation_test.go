@@ -20,9 +20,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 )
 
 func TestWorkspaceBindingValidateValid(t *testing.T) {
@@ -79,6 +83,15 @@ func TestWorkspaceBindingValidateValid(t *testing.T) {
 				SecretName: "my-secret",
 			},
 		},
+	}, {
+		name: "Valid csi",
+		binding: &WorkspaceBinding{
+			Name: "beth",
+			CSI: &corev1.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+			},
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			if err := tc.binding.Validate(context.Background()); err != nil {
@@ -128,6 +141,19 @@ func TestWorkspaceBindingValidateInvalid(t *testing.T) {
 			Name:   "beth",
 			Secret: &corev1.SecretVolumeSource{},
 		},
+	}, {
+		name: "Provide csi without a driver",
+		binding: &WorkspaceBinding{
+			Name: "beth",
+			CSI:  &corev1.CSIVolumeSource{},
+		},
+	}, {
+		name: "Provided both csi and secret",
+		binding: &WorkspaceBinding{
+			Name:   "beth",
+			CSI:    &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io"},
+			Secret: &corev1.SecretVolumeSource{SecretName: "my-secret"},
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			if err := tc.binding.Validate(context.Background()); err == nil {
@@ -136,3 +162,195 @@ func TestWorkspaceBindingValidateInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkspaceBindingValidate_MultipleSources(t *testing.T) {
+	b := &WorkspaceBinding{
+		Name:                  "beth",
+		EmptyDir:              &corev1.EmptyDirVolumeSource{},
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+	}
+	want := &apis.FieldError{
+		Message: "expected exactly one, got both",
+		Paths:   []string{"persistentvolumeclaim", "emptydir"},
+		Details: `workspace binding "beth" must use exactly one volume source`,
+	}
+	if d := cmp.Diff(want.Error(), b.Validate(context.Background()).Error()); d != "" {
+		t.Errorf("Validate() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestWorkspaceBindingValidate_CleanupAfterCompletion(t *testing.T) {
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+	alpha := config.ToContext(context.Background(), cfg)
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		binding *WorkspaceBinding
+		wantErr *apis.FieldError
+	}{{
+		name: "pvc",
+		ctx:  alpha,
+		binding: &WorkspaceBinding{
+			Name:                   "beth",
+			PersistentVolumeClaim:  &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+			CleanupAfterCompletion: true,
+		},
+	}, {
+		name: "volumeClaimTemplate",
+		ctx:  alpha,
+		binding: &WorkspaceBinding{
+			Name:                   "beth",
+			VolumeClaimTemplate:    &corev1.PersistentVolumeClaim{},
+			CleanupAfterCompletion: true,
+		},
+	}, {
+		name: "emptyDir",
+		ctx:  alpha,
+		binding: &WorkspaceBinding{
+			Name:                   "beth",
+			EmptyDir:               &corev1.EmptyDirVolumeSource{},
+			CleanupAfterCompletion: true,
+		},
+		wantErr: &apis.FieldError{
+			Message: `workspace binding "beth" can only be cleaned up when backed by a persistentVolumeClaim or volumeClaimTemplate`,
+			Paths:   []string{"cleanupAfterCompletion"},
+		},
+	}, {
+		name: "not alpha",
+		ctx:  context.Background(),
+		binding: &WorkspaceBinding{
+			Name:                   "beth",
+			PersistentVolumeClaim:  &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+			CleanupAfterCompletion: true,
+		},
+		wantErr: &apis.FieldError{
+			Message: `cleanupAfterCompletion requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+			Paths:   []string{"cleanupAfterCompletion"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.wantErr.Error(), tc.binding.Validate(tc.ctx).Error()); d != "" {
+				t.Errorf("Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestWorkspaceBindingValidate_SubPathPerRun(t *testing.T) {
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+	alpha := config.ToContext(context.Background(), cfg)
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		binding *WorkspaceBinding
+		wantErr *apis.FieldError
+	}{{
+		name: "pvc",
+		ctx:  alpha,
+		binding: &WorkspaceBinding{
+			Name:                  "beth",
+			SubPath:               "$(context.pipelineRun.namespace)",
+			SubPathPerRun:         true,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+		},
+	}, {
+		name: "volumeClaimTemplate",
+		ctx:  alpha,
+		binding: &WorkspaceBinding{
+			Name:                "beth",
+			SubPathPerRun:       true,
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaim{},
+		},
+		wantErr: &apis.FieldError{
+			Message: `workspace binding "beth" can only use a subPath per run when backed by a persistentVolumeClaim`,
+			Paths:   []string{"subPathPerRun"},
+		},
+	}, {
+		name: "not alpha",
+		ctx:  context.Background(),
+		binding: &WorkspaceBinding{
+			Name:                  "beth",
+			SubPathPerRun:         true,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+		},
+		wantErr: &apis.FieldError{
+			Message: `subPathPerRun requires "enable-api-fields" feature gate to be "alpha" but it is "stable"`,
+			Paths:   []string{"subPathPerRun"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.wantErr.Error(), tc.binding.Validate(tc.ctx).Error()); d != "" {
+				t.Errorf("Validate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestValidateWorkspaceBindingCollisions(t *testing.T) {
+	pvc := func(name, claim, subPath string) WorkspaceBinding {
+		return WorkspaceBinding{
+			Name:                  name,
+			SubPath:               subPath,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		}
+	}
+	for _, tc := range []struct {
+		name     string
+		bindings []WorkspaceBinding
+		wantErr  string
+	}{{
+		name:     "different claims at the same subPath",
+		bindings: []WorkspaceBinding{pvc("a", "one", "src"), pvc("b", "two", "src")},
+	}, {
+		name:     "same claim at different subPaths",
+		bindings: []WorkspaceBinding{pvc("a", "shared", "src"), pvc("b", "shared", "cache")},
+	}, {
+		name:     "same claim at nested subPaths",
+		bindings: []WorkspaceBinding{pvc("a", "shared", "src"), pvc("b", "shared", "src/vendor")},
+	}, {
+		name:     "same claim at the same subPath",
+		bindings: []WorkspaceBinding{pvc("a", "shared", "src"), pvc("b", "shared", "src")},
+		wantErr:  `workspace bindings "a" and "b" both mount persistentVolumeClaim "shared" at subPath "src": workspaces`,
+	}, {
+		name:     "same claim at equivalent subPaths",
+		bindings: []WorkspaceBinding{pvc("a", "shared", "/src/"), pvc("b", "shared", "./src")},
+		wantErr:  `workspace bindings "a" and "b" both mount persistentVolumeClaim "shared" at subPath "src": workspaces`,
+	}, {
+		name:     "same claim at its root",
+		bindings: []WorkspaceBinding{pvc("a", "shared", ""), pvc("b", "shared", ".")},
+		wantErr:  `workspace bindings "a" and "b" both mount persistentVolumeClaim "shared" at subPath "": workspaces`,
+	}, {
+		name: "colliding bindings apart",
+		bindings: []WorkspaceBinding{
+			pvc("a", "shared", "src"),
+			{Name: "b", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			pvc("c", "shared", "src"),
+		},
+		wantErr: `workspace bindings "a" and "c" both mount persistentVolumeClaim "shared" at subPath "src": workspaces`,
+	}, {
+		name: "other volume sources",
+		bindings: []WorkspaceBinding{
+			{Name: "a", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			{Name: "b", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			{Name: "c", ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm"}}},
+			{Name: "d", ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm"}}},
+			{Name: "e", VolumeClaimTemplate: &corev1.PersistentVolumeClaim{}},
+			{Name: "f", VolumeClaimTemplate: &corev1.PersistentVolumeClaim{}},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateWorkspaceBindingCollisions(tc.bindings)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateWorkspaceBindingCollisions() = %v", err)
+				}
+				return
+			}
+			if d := cmp.Diff(tc.wantErr, err.Error()); d != "" {
+				t.Errorf("ValidateWorkspaceBindingCollisions() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
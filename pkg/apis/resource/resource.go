@@ -25,6 +25,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/cluster"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/git"
+	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/http"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/image"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/pullrequest"
 	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/storage"
@@ -47,6 +48,8 @@ func FromType(name string, r *resourcev1alpha1.PipelineResource, images pipeline
 		return pullrequest.NewResource(name, images.PRImage, r)
 	case resourcev1alpha1.PipelineResourceTypeCloudEvent:
 		return cloudevent.NewResource(name, r)
+	case resourcev1alpha1.PipelineResourceTypeHTTP:
+		return http.NewResource(name, images.ShellImage, r)
 	}
 	return nil, fmt.Errorf("%s is an invalid or unimplemented PipelineResource", r.Spec.Type)
 }
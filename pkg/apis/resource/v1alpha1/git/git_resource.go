@@ -18,6 +18,7 @@ package git
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -30,8 +31,13 @@ import (
 
 var (
 	gitSource = "git-source"
+
+	fullSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
 )
 
+// shortSHALength is the length of the abbreviated SHA of a commit.
+const shortSHALength = 7
+
 // Resource is an endpoint from which to get data which is required
 // by a Build/Task for context (e.g. a repo from which to build an image).
 type Resource struct {
@@ -126,7 +132,16 @@ func (s *Resource) GetURL() string {
 }
 
 // Replacements is used for template replacement on a GitResource inside of a Taskrun.
+// The commit, short-commit and committer-date of the checked out revision are only
+// known once it was fetched, so they are replaced by the commit when the revision is
+// already a full SHA, and are empty otherwise. The resolved values are written to the
+// resource results by git-init instead.
 func (s *Resource) Replacements() map[string]string {
+	var commit, shortCommit string
+	if isFullSHA(s.Revision) {
+		commit = s.Revision
+		shortCommit = s.Revision[:shortSHALength]
+	}
 	return map[string]string{
 		"name":       s.Name,
 		"type":       s.Type,
@@ -139,9 +154,18 @@ func (s *Resource) Replacements() map[string]string {
 		"httpProxy":  s.HTTPProxy,
 		"httpsProxy": s.HTTPSProxy,
 		"noProxy":    s.NOProxy,
+
+		"commit":         commit,
+		"short-commit":   shortCommit,
+		"committer-date": "",
 	}
 }
 
+// isFullSHA returns true if revision is the full SHA-1 of a commit.
+func isFullSHA(revision string) bool {
+	return fullSHARegexp.MatchString(revision)
+}
+
 // GetInputTaskModifier returns the TaskModifier to be used when this resource is an input.
 func (s *Resource) GetInputTaskModifier(_ *v1beta1.TaskSpec, path string) (v1beta1.TaskModifier, error) {
 	args := []string{
@@ -355,6 +355,10 @@ func TestGitResource_Replacements(t *testing.T) {
 		"httpProxy":  "http-proxy.git.com",
 		"httpsProxy": "https-proxy.git.com",
 		"noProxy":    "*",
+
+		"commit":         "",
+		"short-commit":   "",
+		"committer-date": "",
 	}
 
 	got := r.Replacements()
@@ -364,6 +368,35 @@ func TestGitResource_Replacements(t *testing.T) {
 	}
 }
 
+func TestGitResource_Replacements_Commit(t *testing.T) {
+	for _, tc := range []struct {
+		revision        string
+		wantCommit      string
+		wantShortCommit string
+	}{{
+		revision:        "8b1e0e2bd7fa9fd4ffb6cdd2e9e9d1d3c4f5a6b7",
+		wantCommit:      "8b1e0e2bd7fa9fd4ffb6cdd2e9e9d1d3c4f5a6b7",
+		wantShortCommit: "8b1e0e2",
+	}, {
+		revision: "8b1e0e2",
+	}, {
+		revision: "refs/heads/main",
+	}, {
+		revision: "",
+	}} {
+		t.Run(tc.revision, func(t *testing.T) {
+			r := &git.Resource{Name: "git-resource", Revision: tc.revision}
+			got := r.Replacements()
+			if got["commit"] != tc.wantCommit {
+				t.Errorf("Expected commit %q but got %q", tc.wantCommit, got["commit"])
+			}
+			if got["short-commit"] != tc.wantShortCommit {
+				t.Errorf("Expected short-commit %q but got %q", tc.wantShortCommit, got["short-commit"])
+			}
+		})
+	}
+}
+
 func TestGitResource_GetDownloadTaskModifier(t *testing.T) {
 	names.TestingSeed()
 
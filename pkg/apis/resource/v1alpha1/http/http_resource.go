@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resource "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	httpFetch      = "http-fetch"
+	authTokenField = "authToken"
+	// nolint: gosec
+	authTokenEnv  = "AUTH_TOKEN"
+	urlEnv        = "URL"
+	outputPathEnv = "OUTPUT_PATH"
+)
+
+// Resource is a file downloaded over HTTP(S) to the directory of the resource.
+type Resource struct {
+	Name string                        `json:"name"`
+	Type resource.PipelineResourceType `json:"type"`
+	// URL of the file to download.
+	URL string `json:"url"`
+	// OutputPath is the path of the downloaded file, relative to the directory
+	// of the resource. It defaults to the last element of the path of the URL.
+	OutputPath string `json:"outputPath"`
+	// Secrets holds a struct to indicate a field name and corresponding secret name to populate it.
+	Secrets []resource.SecretParam `json:"secrets"`
+
+	ShellImage string `json:"-"`
+}
+
+// NewResource creates a new HTTP resource to pass to a Task
+func NewResource(name, shellImage string, r *resource.PipelineResource) (*Resource, error) {
+	if r.Spec.Type != resource.PipelineResourceTypeHTTP {
+		return nil, fmt.Errorf("http.Resource: Cannot create an HTTP resource from a %s Pipeline Resource", r.Spec.Type)
+	}
+	httpResource := Resource{
+		Name:       name,
+		Type:       r.Spec.Type,
+		Secrets:    r.Spec.SecretParams,
+		ShellImage: shellImage,
+	}
+	for _, param := range r.Spec.Params {
+		switch {
+		case strings.EqualFold(param.Name, "URL"):
+			httpResource.URL = param.Value
+		case strings.EqualFold(param.Name, "OutputPath"):
+			httpResource.OutputPath = param.Value
+		}
+	}
+	if httpResource.URL == "" {
+		return nil, fmt.Errorf("http.Resource: Need URL to be specified in order to create an HTTP resource %s", name)
+	}
+	if httpResource.OutputPath == "" {
+		httpResource.OutputPath = resource.DefaultHTTPOutputPath(httpResource.URL)
+	}
+	if httpResource.OutputPath == "" {
+		return nil, fmt.Errorf("http.Resource: Need OutputPath to be specified for HTTP resource %s, as its URL has no path", name)
+	}
+	return &httpResource, nil
+}
+
+// GetName returns the name of the resource
+func (s Resource) GetName() string {
+	return s.Name
+}
+
+// GetType returns the type of the resource, in this case "http"
+func (s Resource) GetType() resource.PipelineResourceType {
+	return resource.PipelineResourceTypeHTTP
+}
+
+// Replacements is used for template replacement on an HTTPResource inside of a Taskrun.
+func (s *Resource) Replacements() map[string]string {
+	return map[string]string{
+		"name":       s.Name,
+		"type":       s.Type,
+		"url":        s.URL,
+		"outputPath": s.OutputPath,
+	}
+}
+
+// GetOutputTaskModifier returns a No-op TaskModifier.
+func (s *Resource) GetOutputTaskModifier(_ *v1beta1.TaskSpec, _ string) (v1beta1.TaskModifier, error) {
+	return &v1beta1.InternalTaskModifier{}, nil
+}
+
+// GetInputTaskModifier returns the TaskModifier to be used when this resource is an input.
+// It prepends a Step downloading the file to OutputPath in the directory of the resource,
+// sending the authToken secret as a bearer token when one is given.
+func (s *Resource) GetInputTaskModifier(_ *v1beta1.TaskSpec, path string) (v1beta1.TaskModifier, error) {
+	env := []corev1.EnvVar{
+		{Name: urlEnv, Value: s.URL},
+		{Name: outputPathEnv, Value: filepath.Join(path, s.OutputPath)},
+	}
+	header := ""
+	for _, sec := range s.Secrets {
+		if strings.EqualFold(sec.FieldName, authTokenField) {
+			env = append(env, corev1.EnvVar{
+				Name: authTokenEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: sec.SecretName,
+						},
+						Key: sec.SecretKey,
+					},
+				},
+			})
+			header = fmt.Sprintf(` --header "Authorization: Bearer ${%s}"`, authTokenEnv)
+		}
+	}
+	// The URL and the token are only read from the environment, so that they
+	// are never interpreted by the shell.
+	script := fmt.Sprintf(`#!/bin/sh
+set -e
+mkdir -p "$(dirname "${%[1]s}")"
+wget -q -O "${%[1]s}"%[2]s "${%[3]s}"
+`, outputPathEnv, header, urlEnv)
+
+	return &v1beta1.InternalTaskModifier{
+		StepsToPrepend: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:       names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(httpFetch + "-" + s.Name),
+				Image:      s.ShellImage,
+				Env:        env,
+				WorkingDir: pipeline.WorkspaceDir,
+			},
+			Script: script,
+		}},
+	}, nil
+}
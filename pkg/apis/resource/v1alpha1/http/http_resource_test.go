@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1/http"
+	"github.com/tektoncd/pipeline/test/diff"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewResource(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		resource *resourcev1alpha1.PipelineResource
+		want     *http.Resource
+	}{{
+		desc: "url and outputPath",
+		resource: tb.PipelineResource("http-resource", tb.PipelineResourceSpec(
+			resourcev1alpha1.PipelineResourceTypeHTTP,
+			tb.PipelineResourceSpecParam("URL", "https://example.com/releases/file.tgz"),
+			tb.PipelineResourceSpecParam("outputPath", "downloads/release.tgz"),
+		)),
+		want: &http.Resource{
+			Name:       "test-resource",
+			Type:       resourcev1alpha1.PipelineResourceTypeHTTP,
+			URL:        "https://example.com/releases/file.tgz",
+			OutputPath: "downloads/release.tgz",
+			ShellImage: "busybox",
+		},
+	}, {
+		desc: "outputPath defaults to the name of the file",
+		resource: tb.PipelineResource("http-resource", tb.PipelineResourceSpec(
+			resourcev1alpha1.PipelineResourceTypeHTTP,
+			tb.PipelineResourceSpecParam("url", "https://example.com/releases/file.tgz?version=1"),
+			tb.PipelineResourceSpecSecretParam("authToken", "http-token", "token"),
+		)),
+		want: &http.Resource{
+			Name:       "test-resource",
+			Type:       resourcev1alpha1.PipelineResourceTypeHTTP,
+			URL:        "https://example.com/releases/file.tgz?version=1",
+			OutputPath: "file.tgz",
+			Secrets: []resourcev1alpha1.SecretParam{{
+				FieldName:  "authToken",
+				SecretName: "http-token",
+				SecretKey:  "token",
+			}},
+			ShellImage: "busybox",
+		},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := http.NewResource("test-resource", "busybox", tc.resource)
+			if err != nil {
+				t.Fatalf("Unexpected error creating HTTP resource: %s", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Mismatch of HTTP resource %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestNewResource_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		resource *resourcev1alpha1.PipelineResource
+	}{{
+		desc:     "wrong resource type",
+		resource: tb.PipelineResource("git-resource", tb.PipelineResourceSpec(resourcev1alpha1.PipelineResourceTypeGit)),
+	}, {
+		desc:     "missing url",
+		resource: tb.PipelineResource("http-resource", tb.PipelineResourceSpec(resourcev1alpha1.PipelineResourceTypeHTTP)),
+	}, {
+		desc: "url without path",
+		resource: tb.PipelineResource("http-resource", tb.PipelineResourceSpec(
+			resourcev1alpha1.PipelineResourceTypeHTTP,
+			tb.PipelineResourceSpecParam("url", "https://example.com"),
+		)),
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := http.NewResource("test-resource", "busybox", tc.resource); err == nil {
+				t.Error("Expected error creating HTTP resource")
+			}
+		})
+	}
+}
+
+func TestResource_Replacements(t *testing.T) {
+	r := &http.Resource{
+		Name:       "http-resource",
+		Type:       resourcev1alpha1.PipelineResourceTypeHTTP,
+		URL:        "https://example.com/file.tgz",
+		OutputPath: "file.tgz",
+	}
+	want := map[string]string{
+		"name":       "http-resource",
+		"type":       "http",
+		"url":        "https://example.com/file.tgz",
+		"outputPath": "file.tgz",
+	}
+	if d := cmp.Diff(want, r.Replacements()); d != "" {
+		t.Errorf("Mismatch of HTTP resource replacements %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestResource_GetInputTaskModifier(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		secrets []resourcev1alpha1.SecretParam
+		want    []v1beta1.Step
+	}{{
+		desc: "without auth",
+		want: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:  "http-fetch-http-resource-9l9zj",
+				Image: "busybox",
+				Env: []corev1.EnvVar{
+					{Name: "URL", Value: "https://example.com/file.tgz"},
+					{Name: "OUTPUT_PATH", Value: "/workspace/http-resource/downloads/file.tgz"},
+				},
+				WorkingDir: pipeline.WorkspaceDir,
+			},
+			Script: `#!/bin/sh
+set -e
+mkdir -p "$(dirname "${OUTPUT_PATH}")"
+wget -q -O "${OUTPUT_PATH}" "${URL}"
+`,
+		}},
+	}, {
+		desc: "with a bearer token",
+		secrets: []resourcev1alpha1.SecretParam{{
+			FieldName:  "authToken",
+			SecretName: "http-token",
+			SecretKey:  "token",
+		}},
+		want: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:  "http-fetch-http-resource-9l9zj",
+				Image: "busybox",
+				Env: []corev1.EnvVar{
+					{Name: "URL", Value: "https://example.com/file.tgz"},
+					{Name: "OUTPUT_PATH", Value: "/workspace/http-resource/downloads/file.tgz"},
+					{Name: "AUTH_TOKEN", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "http-token"},
+							Key:                  "token",
+						},
+					}},
+				},
+				WorkingDir: pipeline.WorkspaceDir,
+			},
+			Script: `#!/bin/sh
+set -e
+mkdir -p "$(dirname "${OUTPUT_PATH}")"
+wget -q -O "${OUTPUT_PATH}" --header "Authorization: Bearer ${AUTH_TOKEN}" "${URL}"
+`,
+		}},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			names.TestingSeed()
+			r := &http.Resource{
+				Name:       "http-resource",
+				Type:       resourcev1alpha1.PipelineResourceTypeHTTP,
+				URL:        "https://example.com/file.tgz",
+				OutputPath: "downloads/file.tgz",
+				Secrets:    tc.secrets,
+				ShellImage: "busybox",
+			}
+			modifier, err := r.GetInputTaskModifier(&v1beta1.TaskSpec{}, "/workspace/http-resource")
+			if err != nil {
+				t.Fatalf("Unexpected error getting GetInputTaskModifier: %s", err)
+			}
+			if d := cmp.Diff(tc.want, modifier.GetStepsToPrepend()); d != "" {
+				t.Errorf("Mismatch of HTTP resource input steps %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
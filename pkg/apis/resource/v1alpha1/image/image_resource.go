@@ -31,6 +31,7 @@ type Resource struct {
 	Type           resourcev1alpha1.PipelineResourceType `json:"type"`
 	URL            string                                `json:"url"`
 	Digest         string                                `json:"digest"`
+	Verify         bool                                  `json:"verify,omitempty"`
 	OutputImageDir string
 }
 
@@ -50,6 +51,8 @@ func NewResource(name string, r *resourcev1alpha1.PipelineResource) (*Resource,
 			ir.URL = param.Value
 		case strings.EqualFold(param.Name, "Digest"):
 			ir.Digest = param.Value
+		case strings.EqualFold(param.Name, "Verify"):
+			ir.Verify = param.Value == "true"
 		}
 	}
 
@@ -42,6 +42,7 @@ func TestNewImageResource_Valid(t *testing.T) {
 		Type:   v1alpha1.PipelineResourceTypeImage,
 		URL:    "https://test.com/test/test",
 		Digest: "test",
+		Verify: true,
 	}
 
 	r := tb.PipelineResource(
@@ -50,6 +51,7 @@ func TestNewImageResource_Valid(t *testing.T) {
 			v1alpha1.PipelineResourceTypeImage,
 			tb.PipelineResourceSpecParam("URL", "https://test.com/test/test"),
 			tb.PipelineResourceSpecParam("Digest", "test"),
+			tb.PipelineResourceSpecParam("Verify", "true"),
 		),
 	)
 
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -118,6 +120,12 @@ func (rs *PipelineResourceSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if rs.Type == PipelineResourceTypeHTTP {
+		if err := validateHTTP(rs); err != nil {
+			return err
+		}
+	}
+
 	for _, allowedType := range AllResourceTypes {
 		if allowedType == rs.Type {
 			return nil
@@ -156,3 +164,55 @@ func validatePullRequest(s *PipelineResourceSpec) *apis.FieldError {
 	}
 	return nil
 }
+
+func validateHTTP(s *PipelineResourceSpec) *apis.FieldError {
+	var rawURL, outputPath string
+	for _, param := range s.Params {
+		switch {
+		case strings.EqualFold(param.Name, "URL"):
+			rawURL = param.Value
+		case strings.EqualFold(param.Name, "OutputPath"):
+			outputPath = param.Value
+		}
+	}
+	if rawURL == "" {
+		return apis.ErrMissingField("spec.params.url")
+	}
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fe := apis.ErrInvalidValue(rawURL, "spec.params.url")
+		fe.Details = "the URL must use the http or https scheme"
+		return fe
+	}
+	switch {
+	case outputPath == "" && DefaultHTTPOutputPath(rawURL) == "":
+		fe := apis.ErrMissingField("spec.params.outputPath")
+		fe.Details = "the URL has no path to name the downloaded file after"
+		return fe
+	case filepath.IsAbs(outputPath) || strings.HasPrefix(filepath.Clean(outputPath), ".."):
+		fe := apis.ErrInvalidValue(outputPath, "spec.params.outputPath")
+		fe.Details = "the output path must be relative to the directory of the resource"
+		return fe
+	}
+	for _, param := range s.SecretParams {
+		if param.FieldName != "authToken" {
+			return apis.ErrInvalidValue(fmt.Sprintf("invalid field name %q in secret parameter. Expected %q", param.FieldName, "authToken"), "spec.secrets.fieldName")
+		}
+	}
+	return nil
+}
+
+// DefaultHTTPOutputPath returns the path, relative to the directory of an HTTP resource,
+// of the file downloaded from u when the resource has no outputPath: the last element
+// of the path of u. It returns "" if u has no such element.
+func DefaultHTTPOutputPath(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	base := path.Base(parsed.Path)
+	if base == "." || base == "/" {
+		return ""
+	}
+	return base
+}
@@ -156,6 +156,80 @@ func TestResourceValidation_Invalid(t *testing.T) {
 			},
 			want: apis.ErrInvalidValue("invalid field name \"INVALID_FIELD_NAME\" in secret parameter. Expected \"authToken\"", "spec.secrets.fieldName"),
 		},
+		{
+			name: "http without url",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+				},
+			},
+			want: apis.ErrMissingField("spec.params.url"),
+		},
+		{
+			name: "http with ftp url",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+					Params: []v1alpha1.ResourceParam{{
+						Name: "url", Value: "ftp://example.com/file.tgz",
+					}},
+				},
+			},
+			want: &apis.FieldError{
+				Message: "invalid value: ftp://example.com/file.tgz",
+				Paths:   []string{"spec.params.url"},
+				Details: "the URL must use the http or https scheme",
+			},
+		},
+		{
+			name: "http url without path or outputPath",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+					Params: []v1alpha1.ResourceParam{{
+						Name: "url", Value: "https://example.com/",
+					}},
+				},
+			},
+			want: &apis.FieldError{
+				Message: "missing field(s)",
+				Paths:   []string{"spec.params.outputPath"},
+				Details: "the URL has no path to name the downloaded file after",
+			},
+		},
+		{
+			name: "http outputPath outside of the resource",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+					Params: []v1alpha1.ResourceParam{{
+						Name: "url", Value: "https://example.com/file.tgz",
+					}, {
+						Name: "outputPath", Value: "../file.tgz",
+					}},
+				},
+			},
+			want: &apis.FieldError{
+				Message: "invalid value: ../file.tgz",
+				Paths:   []string{"spec.params.outputPath"},
+				Details: "the output path must be relative to the directory of the resource",
+			},
+		},
+		{
+			name: "http with invalid secret",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+					Params: []v1alpha1.ResourceParam{{
+						Name: "url", Value: "https://example.com/file.tgz",
+					}},
+					SecretParams: []v1alpha1.SecretParam{{
+						FieldName: "password",
+					}},
+				},
+			},
+			want: apis.ErrInvalidValue("invalid field name \"password\" in secret parameter. Expected \"authToken\"", "spec.secrets.fieldName"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -214,6 +288,22 @@ func TestClusterResourceValidation_Valid(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "http with url and token",
+			res: &v1alpha1.PipelineResource{
+				Spec: v1alpha1.PipelineResourceSpec{
+					Type: v1alpha1.PipelineResourceTypeHTTP,
+					Params: []v1alpha1.ResourceParam{{
+						Name: "url", Value: "https://example.com/releases/file.tgz",
+					}, {
+						Name: "outputPath", Value: "downloads/file.tgz",
+					}},
+					SecretParams: []v1alpha1.SecretParam{{
+						FieldName: "authToken", SecretName: "http-token", SecretKey: "token",
+					}},
+				},
+			},
+		},
 		{
 			name: "specify pullrequest with no secrets",
 			res: &v1alpha1.PipelineResource{
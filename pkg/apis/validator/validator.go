@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validator defaults and validates Tekton resources with the rules of
+// the webhook, without a cluster, so that tools can check resources before
+// they are applied.
+package validator
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/contexts"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/webhook/resourcesemantics"
+)
+
+// Types are the resources the webhook defaults and validates, keyed by kind.
+var Types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+	// v1alpha1
+	v1alpha1.SchemeGroupVersion.WithKind("Pipeline"):         &v1alpha1.Pipeline{},
+	v1alpha1.SchemeGroupVersion.WithKind("Task"):             &v1alpha1.Task{},
+	v1alpha1.SchemeGroupVersion.WithKind("ClusterTask"):      &v1alpha1.ClusterTask{},
+	v1alpha1.SchemeGroupVersion.WithKind("TaskRun"):          &v1alpha1.TaskRun{},
+	v1alpha1.SchemeGroupVersion.WithKind("PipelineRun"):      &v1alpha1.PipelineRun{},
+	v1alpha1.SchemeGroupVersion.WithKind("Condition"):        &v1alpha1.Condition{},
+	v1alpha1.SchemeGroupVersion.WithKind("PipelineResource"): &v1alpha1.PipelineResource{},
+	v1alpha1.SchemeGroupVersion.WithKind("Run"):              &v1alpha1.Run{},
+	// v1beta1
+	v1beta1.SchemeGroupVersion.WithKind("Pipeline"):    &v1beta1.Pipeline{},
+	v1beta1.SchemeGroupVersion.WithKind("Task"):        &v1beta1.Task{},
+	v1beta1.SchemeGroupVersion.WithKind("ClusterTask"): &v1beta1.ClusterTask{},
+	v1beta1.SchemeGroupVersion.WithKind("TaskRun"):     &v1beta1.TaskRun{},
+	v1beta1.SchemeGroupVersion.WithKind("PipelineRun"): &v1beta1.PipelineRun{},
+}
+
+// Config is the configuration resources are defaulted and validated with,
+// which the webhook reads from its ConfigMaps. The configuration left nil is
+// the default one.
+type Config struct {
+	FeatureFlags *config.FeatureFlags
+	Defaults     *config.Defaults
+}
+
+// Context returns ctx carrying cfg, the way the webhook decorates the contexts
+// it defaults and validates resources with.
+func Context(ctx context.Context, cfg Config) context.Context {
+	c := *config.FromContextOrDefaults(ctx)
+	if cfg.FeatureFlags != nil {
+		c.FeatureFlags = cfg.FeatureFlags
+	}
+	if cfg.Defaults != nil {
+		c.Defaults = cfg.Defaults
+	}
+	return contexts.WithUpgradeViaDefaulting(config.ToContext(ctx, &c))
+}
+
+// Validate returns the error the webhook would reject the creation of obj with,
+// if any, once it set its defaults. obj itself isn't modified. The validations
+// needing a cluster, like the ones looking up the inputs of TaskRuns, are
+// skipped.
+func Validate(ctx context.Context, cfg Config, obj resourcesemantics.GenericCRD) *apis.FieldError {
+	ctx = apis.WithinCreate(apis.WithDryRun(Context(ctx, cfg)))
+	obj = obj.DeepCopyObject().(resourcesemantics.GenericCRD)
+	obj.SetDefaults(ctx)
+	return obj.Validate(ctx)
+}
+
+// ValidateTask returns the error the webhook would reject the creation of t with.
+func ValidateTask(ctx context.Context, cfg Config, t *v1beta1.Task) *apis.FieldError {
+	return Validate(ctx, cfg, t)
+}
+
+// ValidateClusterTask returns the error the webhook would reject the creation of t with.
+func ValidateClusterTask(ctx context.Context, cfg Config, t *v1beta1.ClusterTask) *apis.FieldError {
+	return Validate(ctx, cfg, t)
+}
+
+// ValidatePipeline returns the error the webhook would reject the creation of p with.
+func ValidatePipeline(ctx context.Context, cfg Config, p *v1beta1.Pipeline) *apis.FieldError {
+	return Validate(ctx, cfg, p)
+}
+
+// ValidateTaskRun returns the error the webhook would reject the creation of tr with.
+func ValidateTaskRun(ctx context.Context, cfg Config, tr *v1beta1.TaskRun) *apis.FieldError {
+	return Validate(ctx, cfg, tr)
+}
+
+// ValidatePipelineRun returns the error the webhook would reject the creation of pr with.
+func ValidatePipelineRun(ctx context.Context, cfg Config, pr *v1beta1.PipelineRun) *apis.FieldError {
+	return Validate(ctx, cfg, pr)
+}
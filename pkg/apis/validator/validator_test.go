@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/validator"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/webhook/resourcesemantics"
+	"sigs.k8s.io/yaml"
+)
+
+func TestValidatePipeline(t *testing.T) {
+	p := &v1beta1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "release"},
+		Spec: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{{Name: "version"}},
+			Tasks: []v1beta1.PipelineTask{{
+				Name:    "build",
+				TaskRef: &v1beta1.TaskRef{Name: "build"},
+				Params:  []v1beta1.Param{{Name: "version", Value: v1beta1.NewArrayOrString("$(params.version)")}},
+			}},
+			Finally: []v1beta1.PipelineTask{{
+				Name:          "notify",
+				TaskRef:       &v1beta1.TaskRef{Name: "notify"},
+				FailurePolicy: v1beta1.PipelineTaskFailurePolicyIgnore,
+			}},
+		},
+	}
+	want := p.DeepCopy()
+
+	if err := validator.ValidatePipeline(context.Background(), validator.Config{}, p); err == nil {
+		t.Error("Expected an error validating a failure policy without alpha fields enabled")
+	}
+
+	alpha := validator.Config{FeatureFlags: &config.FeatureFlags{EnableAPIFields: config.AlphaAPIFields}}
+	if err := validator.ValidatePipeline(context.Background(), alpha, p); err != nil {
+		t.Errorf("ValidatePipeline() = %v", err)
+	}
+
+	if d := cmp.Diff(want, p); d != "" {
+		t.Errorf("Expected the pipeline not to be defaulted %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestValidatePipelineRun_Defaults(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "release"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "release"},
+		},
+	}
+	if err := validator.ValidatePipelineRun(context.Background(), validator.Config{}, pr); err != nil {
+		t.Errorf("ValidatePipelineRun() = %v", err)
+	}
+	// The default timeout of the PipelineRun is set before it is validated
+	cfg := validator.Config{Defaults: &config.Defaults{DefaultTimeoutMinutes: -1}}
+	if err := validator.ValidatePipelineRun(context.Background(), cfg, pr); err == nil {
+		t.Error("Expected an error validating a PipelineRun with a negative default timeout")
+	}
+}
+
+// TestValidate_Examples validates the Tekton resources of the examples, which
+// the webhook must accept.
+func TestValidate_Examples(t *testing.T) {
+	// Some examples use alpha features
+	cfg := validator.Config{FeatureFlags: &config.FeatureFlags{EnableAPIFields: config.AlphaAPIFields}}
+	validated := 0
+	err := filepath.Walk("../../../examples", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for i, doc := range bytes.Split(b, []byte("\n---")) {
+			var tm metav1.TypeMeta
+			if err := yaml.Unmarshal(doc, &tm); err != nil {
+				t.Errorf("%s[%d]: %v", path, i, err)
+				continue
+			}
+			typ, ok := validator.Types[tm.GroupVersionKind()]
+			if !ok {
+				continue
+			}
+			obj := typ.DeepCopyObject().(resourcesemantics.GenericCRD)
+			// The webhook rejects the unknown fields
+			if err := yaml.UnmarshalStrict(doc, obj); err != nil {
+				t.Errorf("%s[%d]: %v", path, i, err)
+				continue
+			}
+			if err := validator.Validate(context.Background(), cfg, obj); err != nil {
+				t.Errorf("%s[%d]: %s is invalid: %v", path, i, tm.Kind, strings.TrimSpace(err.Error()))
+			}
+			validated++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error reading the examples: %v", err)
+	}
+	if validated == 0 {
+		t.Error("Expected to validate the resources of the examples")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package auditlog records the transitions of TaskRuns in append-only
+// ConfigMaps, and validates that the entries of these ConfigMaps are never
+// removed or modified.
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// LabelKey is the label of the ConfigMaps holding the audit log of a
+	// TaskRun, whose value is the name of the TaskRun
+	LabelKey = pipeline.GroupName + "/auditLog"
+	// DataKey is the key of the data of the ConfigMaps holding the audit log
+	// of a TaskRun, which holds one JSON Entry per line
+	DataKey = "audit.log"
+
+	// EventStarted is the event of the first transition of a TaskRun
+	EventStarted = "Started"
+	// EventSucceeded is the event of the transition of a TaskRun to success
+	EventSucceeded = "Succeeded"
+	// EventFailed is the event of the transition of a TaskRun to failure
+	EventFailed = "Failed"
+)
+
+// State is the state of the Succeeded condition of a TaskRun.
+type State struct {
+	Status corev1.ConditionStatus `json:"status,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// Entry is a transition of a TaskRun.
+type Entry struct {
+	Time     metav1.Time `json:"time"`
+	Previous State       `json:"previous"`
+	New      State       `json:"new"`
+	// Event is the reason of the Kubernetes event emitted for the transition
+	Event   string `json:"event"`
+	Message string `json:"message,omitempty"`
+}
+
+// ConfigMapName returns the name of the ConfigMap holding the audit log of
+// the TaskRun named taskRunName.
+func ConfigMapName(taskRunName string) string {
+	return "audit-" + taskRunName
+}
+
+// NewEntry returns the Entry recording the transition of a TaskRun from the
+// before to the after Succeeded condition.
+func NewEntry(now metav1.Time, before, after *apis.Condition) Entry {
+	e := Entry{Time: now}
+	if before != nil {
+		e.Previous = State{Status: before.Status, Reason: before.Reason}
+	}
+	if after != nil {
+		e.New = State{Status: after.Status, Reason: after.Reason}
+		e.Message = after.Message
+		// The same reasons as the Kubernetes events emitted for the TaskRun
+		switch {
+		case after.Status == corev1.ConditionTrue:
+			e.Event = EventSucceeded
+		case after.Status == corev1.ConditionFalse:
+			e.Event = EventFailed
+		case before == nil:
+			e.Event = EventStarted
+		default:
+			e.Event = after.Reason
+		}
+	}
+	return e
+}
+
+// Append adds e as the last line of the audit log held by cm.
+func Append(cm *corev1.ConfigMap, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to serialize audit log entry: %w", err)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[DataKey] += string(line) + "\n"
+	return nil
+}
+
+// ValidateUpdate checks that the update of an audit log ConfigMap from
+// oldCM to newCM only appends entries to its log.
+func ValidateUpdate(oldCM, newCM *corev1.ConfigMap) error {
+	if oldCM.Labels[LabelKey] == "" {
+		return nil
+	}
+	if newCM.Labels[LabelKey] != oldCM.Labels[LabelKey] {
+		return fmt.Errorf("the %q label of audit log %q can't be changed", LabelKey, oldCM.Name)
+	}
+	oldLog, newLog := oldCM.Data[DataKey], newCM.Data[DataKey]
+	if !strings.HasPrefix(newLog, oldLog) {
+		return fmt.Errorf("the entries of audit log %q can't be removed or modified, only appended", oldCM.Name)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+)
+
+var now = metav1.NewTime(time.Date(2021, time.April, 1, 10, 0, 0, 0, time.UTC))
+
+func TestNewEntry(t *testing.T) {
+	running := &apis.Condition{Status: corev1.ConditionUnknown, Reason: "Running", Message: "Not all Steps have completed executing"}
+	for _, tc := range []struct {
+		name   string
+		before *apis.Condition
+		after  *apis.Condition
+		want   Entry
+	}{{
+		name:  "started",
+		after: &apis.Condition{Status: corev1.ConditionUnknown, Reason: "Started"},
+		want: Entry{
+			Time:  now,
+			New:   State{Status: corev1.ConditionUnknown, Reason: "Started"},
+			Event: EventStarted,
+		},
+	}, {
+		name:   "running",
+		before: &apis.Condition{Status: corev1.ConditionUnknown, Reason: "Pending"},
+		after:  running,
+		want: Entry{
+			Time:     now,
+			Previous: State{Status: corev1.ConditionUnknown, Reason: "Pending"},
+			New:      State{Status: corev1.ConditionUnknown, Reason: "Running"},
+			Event:    "Running",
+			Message:  "Not all Steps have completed executing",
+		},
+	}, {
+		name:   "succeeded",
+		before: running,
+		after:  &apis.Condition{Status: corev1.ConditionTrue, Reason: "Succeeded", Message: "All Steps have completed executing"},
+		want: Entry{
+			Time:     now,
+			Previous: State{Status: corev1.ConditionUnknown, Reason: "Running"},
+			New:      State{Status: corev1.ConditionTrue, Reason: "Succeeded"},
+			Event:    EventSucceeded,
+			Message:  "All Steps have completed executing",
+		},
+	}, {
+		name:   "failed",
+		before: running,
+		after:  &apis.Condition{Status: corev1.ConditionFalse, Reason: "TaskRunTimeout"},
+		want: Entry{
+			Time:     now,
+			Previous: State{Status: corev1.ConditionUnknown, Reason: "Running"},
+			New:      State{Status: corev1.ConditionFalse, Reason: "TaskRunTimeout"},
+			Event:    EventFailed,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, NewEntry(now, tc.before, tc.after)); d != "" {
+				t.Errorf("NewEntry() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	cm := &corev1.ConfigMap{}
+	for _, e := range []Entry{{Time: now, Event: EventStarted}, {Time: now, Event: EventSucceeded}} {
+		if err := Append(cm, e); err != nil {
+			t.Fatalf("Append() = %v", err)
+		}
+	}
+	want := `{"time":"2021-04-01T10:00:00Z","previous":{},"new":{},"event":"Started"}
+{"time":"2021-04-01T10:00:00Z","previous":{},"new":{},"event":"Succeeded"}
+`
+	if d := cmp.Diff(want, cm.Data[DataKey]); d != "" {
+		t.Errorf("Append() %s", diff.PrintWantGot(d))
+	}
+}
+
+func auditLog(label, log string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit-build", Namespace: "foo"},
+		Data:       map[string]string{DataKey: log},
+	}
+	if label != "" {
+		cm.Labels = map[string]string{LabelKey: label}
+	}
+	return cm
+}
+
+func TestValidateUpdate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		oldCM   *corev1.ConfigMap
+		newCM   *corev1.ConfigMap
+		wantErr string
+	}{{
+		name:  "appended entry",
+		oldCM: auditLog("build", "1\n"),
+		newCM: auditLog("build", "1\n2\n"),
+	}, {
+		name:  "unchanged",
+		oldCM: auditLog("build", "1\n"),
+		newCM: auditLog("build", "1\n"),
+	}, {
+		name:  "not an audit log",
+		oldCM: auditLog("", "1\n2\n"),
+		newCM: auditLog("", "2\n"),
+	}, {
+		name:    "removed entry",
+		oldCM:   auditLog("build", "1\n2\n"),
+		newCM:   auditLog("build", "1\n"),
+		wantErr: `the entries of audit log "audit-build" can't be removed or modified, only appended`,
+	}, {
+		name:    "modified entry",
+		oldCM:   auditLog("build", "1\n2\n"),
+		newCM:   auditLog("build", "1\n3\n4\n"),
+		wantErr: `the entries of audit log "audit-build" can't be removed or modified, only appended`,
+	}, {
+		name:    "removed label",
+		oldCM:   auditLog("build", "1\n"),
+		newCM:   auditLog("", ""),
+		wantErr: `the "tekton.dev/auditLog" label of audit log "audit-build" can't be changed`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateUpdate(tc.oldCM, tc.newCM)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateUpdate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if d := cmp.Diff(tc.wantErr, err.Error()); d != "" {
+				t.Errorf("ValidateUpdate() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	raw := func(cm *corev1.ConfigMap) runtime.RawExtension {
+		b, err := json.Marshal(cm)
+		if err != nil {
+			t.Fatalf("Failed to marshal ConfigMap: %v", err)
+		}
+		return runtime.RawExtension{Raw: b}
+	}
+	kind := metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	deleted := metav1.Now()
+	kubeClient := fakekubeclientset.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bar", DeletionTimestamp: &deleted}},
+	)
+	for _, tc := range []struct {
+		name        string
+		request     *admissionv1.AdmissionRequest
+		wantAllowed bool
+	}{{
+		name: "appended entry",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Kind:      kind,
+			OldObject: raw(auditLog("build", "1\n")),
+			Object:    raw(auditLog("build", "1\n2\n")),
+		},
+		wantAllowed: true,
+	}, {
+		name: "removed entry",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Kind:      kind,
+			OldObject: raw(auditLog("build", "1\n2\n")),
+			Object:    raw(auditLog("build", "2\n")),
+		},
+	}, {
+		name: "creation",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Kind:      kind,
+			Object:    raw(auditLog("build", "1\n")),
+		},
+		wantAllowed: true,
+	}, {
+		name: "deletion",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			Kind:      kind,
+			Namespace: "foo",
+			Name:      "audit-build",
+			OldObject: raw(auditLog("build", "1\n")),
+		},
+	}, {
+		name: "deletion along with the namespace",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			Kind:      kind,
+			Namespace: "bar",
+			Name:      "audit-build",
+			OldObject: raw(auditLog("build", "1\n")),
+		},
+		wantAllowed: true,
+	}, {
+		name: "other kind",
+		request: &admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Secret"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := (&reconciler{client: kubeClient}).Admit(context.Background(), tc.request)
+			if resp.Allowed != tc.wantAllowed {
+				t.Errorf("Admit() allowed = %t, want %t: %v", resp.Allowed, tc.wantAllowed, resp.Result)
+			}
+		})
+	}
+}
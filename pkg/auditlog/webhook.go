@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	admissionlisters "k8s.io/client-go/listers/admissionregistration/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	vwhinformer "knative.dev/pkg/client/injection/kube/informers/admissionregistration/v1/validatingwebhookconfiguration"
+	"knative.dev/pkg/controller"
+	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
+	"knative.dev/pkg/kmp"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+// reconciler implements the AdmissionController rejecting the updates of
+// audit log ConfigMaps that don't only append entries, and their deletion
+// unless their namespace is being deleted. Like the
+// ConfigMap admission controller of knative, it keeps the rules and the
+// certificate of its ValidatingWebhookConfiguration up to date.
+type reconciler struct {
+	webhook.StatelessAdmissionImpl
+	pkgreconciler.LeaderAwareFuncs
+
+	key  types.NamespacedName
+	path string
+
+	client       kubernetes.Interface
+	vwhlister    admissionlisters.ValidatingWebhookConfigurationLister
+	secretlister corelisters.SecretLister
+
+	secretName string
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+var _ pkgreconciler.LeaderAware = (*reconciler)(nil)
+var _ webhook.AdmissionController = (*reconciler)(nil)
+var _ webhook.StatelessAdmissionController = (*reconciler)(nil)
+
+// NewAdmissionController constructs the controller of the
+// ValidatingWebhookConfiguration called name, served on path.
+func NewAdmissionController(ctx context.Context, name, path string) *controller.Impl {
+	vwhInformer := vwhinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+	options := webhook.GetOptions(ctx)
+
+	key := types.NamespacedName{Name: name}
+	wh := &reconciler{
+		LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+			// Enqueue the singleton whenever this reconciler becomes leader.
+			PromoteFunc: func(bkt pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+				enq(bkt, key)
+				return nil
+			},
+		},
+		key:          key,
+		path:         path,
+		secretName:   options.SecretName,
+		client:       kubeclient.Get(ctx),
+		vwhlister:    vwhInformer.Lister(),
+		secretlister: secretInformer.Lister(),
+	}
+
+	c := controller.NewImpl(wh, logging.FromContext(ctx), "AuditLogWebhook")
+
+	// Reconcile when the named ValidatingWebhookConfiguration or the
+	// certificates change, whatever is enqueued the named
+	// ValidatingWebhookConfiguration is reconciled.
+	vwhInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithName(name),
+		Handler:    controller.HandleAll(c.Enqueue),
+	})
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), wh.secretName),
+		Handler:    controller.HandleAll(c.Enqueue),
+	})
+	return c
+}
+
+// Reconcile implements controller.Reconciler
+func (ac *reconciler) Reconcile(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
+	if !ac.IsLeaderFor(ac.key) {
+		logger.Debugf("Skipping key %q, not the leader.", ac.key)
+		return nil
+	}
+
+	secret, err := ac.secretlister.Secrets(system.Namespace()).Get(ac.secretName)
+	if err != nil {
+		logger.Errorf("Error fetching secret: %v", err)
+		return err
+	}
+	caCert, ok := secret.Data[certresources.CACert]
+	if !ok {
+		return fmt.Errorf("secret %q is missing %q key", ac.secretName, certresources.CACert)
+	}
+	return ac.reconcileValidatingWebhook(ctx, caCert)
+}
+
+// Path implements AdmissionController
+func (ac *reconciler) Path() string {
+	return ac.path
+}
+
+// Admit implements AdmissionController
+func (ac *reconciler) Admit(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation != admissionv1.Update && request.Operation != admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	if request.Kind != metav1.GroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap")) {
+		return webhook.MakeErrorStatus("unhandled kind: %v", request.Kind)
+	}
+	if request.Operation == admissionv1.Delete {
+		return ac.admitDelete(request)
+	}
+
+	var oldCM, newCM corev1.ConfigMap
+	if err := json.Unmarshal(request.OldObject.Raw, &oldCM); err != nil {
+		return webhook.MakeErrorStatus("cannot decode incoming old object: %v", err)
+	}
+	if err := json.Unmarshal(request.Object.Raw, &newCM); err != nil {
+		return webhook.MakeErrorStatus("cannot decode incoming new object: %v", err)
+	}
+	if err := ValidateUpdate(&oldCM, &newCM); err != nil {
+		return webhook.MakeErrorStatus("validation failed: %v", err)
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// admitDelete only allows the deletion of an audit log along with its namespace,
+// so that the audit logs can't be removed while the namespace is in use.
+func (ac *reconciler) admitDelete(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	ns, err := ac.client.CoreV1().Namespaces().Get(request.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return webhook.MakeErrorStatus("cannot get the namespace of audit log %q: %v", request.Name, err)
+	}
+	if ns.DeletionTimestamp == nil {
+		return webhook.MakeErrorStatus("audit log %q can't be deleted unless its namespace is", request.Name)
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func (ac *reconciler) reconcileValidatingWebhook(ctx context.Context, caCert []byte) error {
+	logger := logging.FromContext(ctx)
+
+	ruleScope := admissionregistrationv1.NamespacedScope
+	rules := []admissionregistrationv1.RuleWithOperations{{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update, admissionregistrationv1.Delete},
+		Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"configmaps"},
+			Scope:       &ruleScope,
+		},
+	}}
+	// Only the audit logs are sent to the webhook.
+	objectSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      LabelKey,
+			Operator: metav1.LabelSelectorOpExists,
+		}},
+	}
+
+	configuredWebhook, err := ac.vwhlister.Get(ac.key.Name)
+	if err != nil {
+		return fmt.Errorf("error retrieving webhook: %w", err)
+	}
+	webhook := configuredWebhook.DeepCopy()
+	webhook.OwnerReferences = nil
+	for i, wh := range webhook.Webhooks {
+		if wh.Name != webhook.Name {
+			continue
+		}
+		webhook.Webhooks[i].Rules = rules
+		webhook.Webhooks[i].ObjectSelector = objectSelector
+		webhook.Webhooks[i].ClientConfig.CABundle = caCert
+		if webhook.Webhooks[i].ClientConfig.Service == nil {
+			return errors.New("missing service reference for webhook: " + wh.Name)
+		}
+		webhook.Webhooks[i].ClientConfig.Service.Path = ptr.String(ac.Path())
+	}
+
+	if ok, err := kmp.SafeEqual(configuredWebhook, webhook); err != nil {
+		return fmt.Errorf("error diffing webhooks: %w", err)
+	} else if !ok {
+		logger.Info("Updating webhook")
+		if _, err := ac.client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Update(webhook); err != nil {
+			return fmt.Errorf("failed to update webhook: %w", err)
+		}
+	} else {
+		logger.Info("Webhook is valid")
+	}
+	return nil
+}
@@ -56,6 +56,10 @@ func (c *FakeTektonV1alpha1) TaskRuns(namespace string) v1alpha1.TaskRunInterfac
 	return &FakeTaskRuns{c, namespace}
 }
 
+func (c *FakeTektonV1alpha1) VulnerabilitySummaries(namespace string) v1alpha1.VulnerabilitySummaryInterface {
+	return &FakeVulnerabilitySummaries{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeTektonV1alpha1) RESTClient() rest.Interface {
@@ -31,3 +31,5 @@ type RunExpansion interface{}
 type TaskExpansion interface{}
 
 type TaskRunExpansion interface{}
+
+type VulnerabilitySummaryExpansion interface{}
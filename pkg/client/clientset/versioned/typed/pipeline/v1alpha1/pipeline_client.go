@@ -33,6 +33,7 @@ type TektonV1alpha1Interface interface {
 	RunsGetter
 	TasksGetter
 	TaskRunsGetter
+	VulnerabilitySummariesGetter
 }
 
 // TektonV1alpha1Client is used to interact with features provided by the tekton.dev group.
@@ -68,6 +69,10 @@ func (c *TektonV1alpha1Client) TaskRuns(namespace string) TaskRunInterface {
 	return newTaskRuns(c, namespace)
 }
 
+func (c *TektonV1alpha1Client) VulnerabilitySummaries(namespace string) VulnerabilitySummaryInterface {
+	return newVulnerabilitySummaries(c, namespace)
+}
+
 // NewForConfig creates a new TektonV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*TektonV1alpha1Client, error) {
 	config := *c
@@ -68,6 +68,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Tekton().V1alpha1().Tasks().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("taskruns"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Tekton().V1alpha1().TaskRuns().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vulnerabilitysummaries"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Tekton().V1alpha1().VulnerabilitySummaries().Informer()}, nil
 
 		// Group=tekton.dev, Version=v1beta1
 	case v1beta1.SchemeGroupVersion.WithResource("clustertasks"):
@@ -38,6 +38,8 @@ type Interface interface {
 	Tasks() TaskInformer
 	// TaskRuns returns a TaskRunInformer.
 	TaskRuns() TaskRunInformer
+	// VulnerabilitySummaries returns a VulnerabilitySummaryInformer.
+	VulnerabilitySummaries() VulnerabilitySummaryInformer
 }
 
 type version struct {
@@ -85,3 +87,8 @@ func (v *version) Tasks() TaskInformer {
 func (v *version) TaskRuns() TaskRunInformer {
 	return &taskRunInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// VulnerabilitySummaries returns a VulnerabilitySummaryInformer.
+func (v *version) VulnerabilitySummaries() VulnerabilitySummaryInformer {
+	return &vulnerabilitySummaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -69,3 +69,11 @@ type TaskRunListerExpansion interface{}
 // TaskRunNamespaceListerExpansion allows custom methods to be added to
 // TaskRunNamespaceLister.
 type TaskRunNamespaceListerExpansion interface{}
+
+// VulnerabilitySummaryListerExpansion allows custom methods to be added to
+// VulnerabilitySummaryLister.
+type VulnerabilitySummaryListerExpansion interface{}
+
+// VulnerabilitySummaryNamespaceListerExpansion allows custom methods to be added to
+// VulnerabilitySummaryNamespaceLister.
+type VulnerabilitySummaryNamespaceListerExpansion interface{}
@@ -22,8 +22,10 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,8 +36,13 @@ import (
 const annotationPrefix = "tekton.dev/docker-"
 
 var config basicDocker
-var dockerConfig string
-var dockerCfg string
+var dockerConfig secretList
+var dockerCfg secretList
+
+// sources lists the secrets given to all the docker flags, in the order the
+// flags were parsed. Their entries are merged in that order, so that the
+// later secret wins when several secrets hold credentials for a registry.
+var sources []authSource
 
 // AddFlags adds CLI flags that dockercreds supports to a given flag.FlagSet.
 func AddFlags(flagSet *flag.FlagSet) {
@@ -43,16 +50,26 @@ func AddFlags(flagSet *flag.FlagSet) {
 }
 
 func flags(fs *flag.FlagSet) {
-	config = basicDocker{make(map[string]entry)}
+	sources = nil
+	config = basicDocker{Entries: make(map[string]entry), sources: &sources}
+	dockerConfig = secretList{sources: &sources, auths: authsFromDockerConfig}
+	dockerCfg = secretList{sources: &sources, auths: authsFromDockerCfg}
 	fs.Var(&config, "basic-docker", "List of secret=url pairs.")
-	fs.StringVar(&dockerConfig, "docker-config", "", "Docker config.json secret file.")
-	fs.StringVar(&dockerCfg, "docker-cfg", "", "Docker .dockercfg secret file.")
+	fs.Var(&dockerConfig, "docker-config", "Docker config.json secret file, can be given multiple times.")
+	fs.Var(&dockerCfg, "docker-cfg", "Docker .dockercfg secret file, can be given multiple times.")
+}
+
+// authSource is a secret providing docker auth entries keyed by registry.
+type authSource struct {
+	secret string
+	auths  func() (map[string]entry, error)
 }
 
 // As the flag is read, this status is populated.
 // basicDocker implements flag.Value
 type basicDocker struct {
 	Entries map[string]entry `json:"auths"`
+	sources *[]authSource
 }
 
 func (dc *basicDocker) String() string {
@@ -75,15 +92,45 @@ func (dc *basicDocker) Set(value string) error {
 	secret := parts[0]
 	url := parts[1]
 
-	if _, ok := dc.Entries[url]; ok {
-		return fmt.Errorf("multiple entries for url: %v", url)
-	}
-
 	e, err := newEntry(secret)
 	if err != nil {
 		return err
 	}
 	dc.Entries[url] = *e
+	if dc.sources != nil {
+		*dc.sources = append(*dc.sources, authSource{
+			secret: secret,
+			auths:  func() (map[string]entry, error) { return map[string]entry{url: *e}, nil },
+		})
+	}
+	return nil
+}
+
+// secretList implements flag.Value for the flags naming a kubernetes docker
+// registry secret, which are given once per secret.
+type secretList struct {
+	secrets []string
+	sources *[]authSource
+	auths   func(secret string) (map[string]entry, error)
+}
+
+func (sl *secretList) String() string {
+	if sl == nil {
+		// According to flag.Value this can happen.
+		return ""
+	}
+	return strings.Join(sl.secrets, ",")
+}
+
+func (sl *secretList) Set(secret string) error {
+	sl.secrets = append(sl.secrets, secret)
+	if sl.sources != nil {
+		auths := sl.auths
+		*sl.sources = append(*sl.sources, authSource{
+			secret: secret,
+			auths:  func() (map[string]entry, error) { return auths(secret) },
+		})
+	}
 	return nil
 }
 
@@ -153,31 +200,30 @@ func (*basicDockerBuilder) MatchingAnnotations(secret *corev1.Secret) []string {
 // of kubernetes docker registry secrets and tekton docker
 // secret entries and writes it to the given directory. If
 // no entries exist then nothing will be written to disk.
+// When several secrets hold credentials for the same registry,
+// the one given last wins.
 func (*basicDockerBuilder) Write(directory string) error {
 	dockerDir := filepath.Join(directory, ".docker")
 	basicDocker := filepath.Join(dockerDir, "config.json")
-	cf := configFile{Auth: config.Entries}
 	auth := map[string]entry{}
-	if dockerCfg != "" {
-		dockerConfigAuthMap, err := authsFromDockerCfg(dockerCfg)
+	authSecrets := map[string]string{}
+	for _, s := range sources {
+		entries, err := s.auths()
 		if err != nil {
 			return err
 		}
-		for k, v := range dockerConfigAuthMap {
-			auth[k] = v
+		registries := make([]string, 0, len(entries))
+		for k := range entries {
+			registries = append(registries, k)
 		}
-	}
-	if dockerConfig != "" {
-		dockerConfigAuthMap, err := authsFromDockerConfig(dockerConfig)
-		if err != nil {
-			return err
+		sort.Strings(registries)
+		for _, k := range registries {
+			if previous, ok := authSecrets[k]; ok {
+				log.Printf("docker credentials for %q from secret %q override the ones from secret %q", k, s.secret, previous)
+			}
+			auth[k] = entries[k]
+			authSecrets[k] = s.secret
 		}
-		for k, v := range dockerConfigAuthMap {
-			auth[k] = v
-		}
-	}
-	for k, v := range config.Entries {
-		auth[k] = v
 	}
 	if len(auth) == 0 {
 		return nil
@@ -186,7 +232,7 @@ func (*basicDockerBuilder) Write(directory string) error {
 		return err
 	}
 
-	cf.Auth = auth
+	cf := configFile{Auth: auth}
 	content, err := json.Marshal(cf)
 	if err != nil {
 		return err
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -128,7 +129,7 @@ func TestFlagHandlingMissingFiles(t *testing.T) {
 	}
 	// No username / password files yields an error.
 
-	cfg := basicDocker{make(map[string]entry)}
+	cfg := basicDocker{Entries: make(map[string]entry)}
 	if err := cfg.Set("not-found=https://us.gcr.io"); err == nil {
 		t.Error("Set(); got success, wanted error.")
 	}
@@ -136,35 +137,44 @@ func TestFlagHandlingMissingFiles(t *testing.T) {
 
 func TestFlagHandlingURLCollision(t *testing.T) {
 	credentials.VolumePath, _ = ioutil.TempDir("", "")
-	dir := credentials.VolumeName("foo")
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		t.Fatalf("os.MkdirAll(%s) = %v", dir, err)
-	}
-	if err := ioutil.WriteFile(filepath.Join(dir, corev1.BasicAuthUsernameKey), []byte("bar"), 0777); err != nil {
-		t.Fatalf("ioutil.WriteFile(username) = %v", err)
+	writeBasicAuthSecret(t, "foo", "bar", "baz")
+	writeBasicAuthSecret(t, "bar", "bleh", "belch")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs)
+	err := fs.Parse([]string{
+		"-basic-docker=foo=https://us.gcr.io",
+		"-basic-docker=bar=https://us.gcr.io",
+	})
+	if err != nil {
+		t.Fatalf("flag.CommandLine.Parse() = %v", err)
 	}
-	if err := ioutil.WriteFile(filepath.Join(dir, corev1.BasicAuthPasswordKey), []byte("baz"), 0777); err != nil {
-		t.Fatalf("ioutil.WriteFile(password) = %v", err)
+
+	if err := NewBuilder().Write(credentials.VolumePath); err != nil {
+		t.Fatalf("Write() = %v", err)
 	}
 
-	cfg := basicDocker{make(map[string]entry)}
-	if err := cfg.Set("foo=https://us.gcr.io"); err != nil {
-		t.Fatalf("First Set() = %v", err)
+	b, err := ioutil.ReadFile(filepath.Join(credentials.VolumePath, ".docker", "config.json"))
+	if err != nil {
+		t.Fatalf("ioutil.ReadFile(.docker/config.json) = %v", err)
 	}
-	if err := cfg.Set("bar=https://us.gcr.io"); err == nil {
-		t.Error("Second Set(); got success, wanted error.")
+
+	// The secret given last wins.
+	expected := `{"auths":{"https://us.gcr.io":{"username":"bleh","password":"belch","auth":"YmxlaDpiZWxjaA==","email":"not@val.id"}}}`
+	if string(b) != expected {
+		t.Errorf("got: %v, wanted: %v", string(b), expected)
 	}
 }
 
 func TestMalformedValueTooMany(t *testing.T) {
-	cfg := basicDocker{make(map[string]entry)}
+	cfg := basicDocker{Entries: make(map[string]entry)}
 	if err := cfg.Set("bar=baz=blah"); err == nil {
 		t.Error("Second Set(); got success, wanted error.")
 	}
 }
 
 func TestMalformedValueTooFew(t *testing.T) {
-	cfg := basicDocker{make(map[string]entry)}
+	cfg := basicDocker{Entries: make(map[string]entry)}
 	if err := cfg.Set("bar"); err == nil {
 		t.Error("Second Set(); got success, wanted error.")
 	}
@@ -304,3 +314,94 @@ func TestNoAuthProvided(t *testing.T) {
 		t.Errorf("expected does not exist error but received: %v", err)
 	}
 }
+
+func TestMultipleDockerRegistrySecrets(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		secrets  map[string]string
+		flags    []string
+		expected string
+	}{{
+		name: "disjoint registries",
+		secrets: map[string]string{
+			"config-a": `{"auths":{"https://a.registry/v1":{"auth":"fromconfiga"}}}`,
+			"config-b": `{"auths":{"https://b.registry/v1":{"auth":"fromconfigb"}}}`,
+			"cfg-c":    `{"https://c.registry/v1":{"auth":"fromcfgc"}}`,
+		},
+		flags:    []string{"-docker-config=config-a", "-docker-config=config-b", "-docker-cfg=cfg-c"},
+		expected: `{"auths":{"https://a.registry/v1":{"auth":"fromconfiga"},"https://b.registry/v1":{"auth":"fromconfigb"},"https://c.registry/v1":{"auth":"fromcfgc"}}}`,
+	}, {
+		name: "overlapping registries, later secret wins",
+		secrets: map[string]string{
+			"config-a": `{"auths":{"https://a.registry/v1":{"auth":"fromconfiga"},"https://shared.registry/v1":{"auth":"fromconfiga"}}}`,
+			"config-b": `{"auths":{"https://shared.registry/v1":{"auth":"fromconfigb"}}}`,
+		},
+		flags:    []string{"-docker-config=config-a", "-docker-config=config-b"},
+		expected: `{"auths":{"https://a.registry/v1":{"auth":"fromconfiga"},"https://shared.registry/v1":{"auth":"fromconfigb"}}}`,
+	}, {
+		name: "overlapping registries across secret types, later secret wins",
+		secrets: map[string]string{
+			"cfg-c":    `{"https://shared.registry/v1":{"auth":"fromcfgc"}}`,
+			"config-a": `{"auths":{"https://shared.registry/v1":{"auth":"fromconfiga"}}}`,
+		},
+		flags:    []string{"-docker-config=config-a", "-docker-cfg=cfg-c"},
+		expected: `{"auths":{"https://shared.registry/v1":{"auth":"fromcfgc"}}}`,
+	}, {
+		name: "docker config secret given after basic-auth secret wins",
+		secrets: map[string]string{
+			"config-a": `{"auths":{"https://us.gcr.io":{"auth":"fromconfiga"}}}`,
+		},
+		flags:    []string{"-basic-docker=foo=https://us.gcr.io", "-docker-config=config-a"},
+		expected: `{"auths":{"https://us.gcr.io":{"auth":"fromconfiga"}}}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			credentials.VolumePath, _ = ioutil.TempDir("", "")
+			writeBasicAuthSecret(t, "foo", "bar", "baz")
+			for name, content := range tc.secrets {
+				dir := credentials.VolumeName(name)
+				if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+					t.Fatalf("os.MkdirAll(%s) = %v", dir, err)
+				}
+				key := corev1.DockerConfigJsonKey
+				if strings.HasPrefix(name, "cfg-") {
+					key = corev1.DockerConfigKey
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(content), 0777); err != nil {
+					t.Fatalf("ioutil.WriteFile(%s) = %v", key, err)
+				}
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			AddFlags(fs)
+			if err := fs.Parse(tc.flags); err != nil {
+				t.Fatalf("flag.CommandLine.Parse() = %v", err)
+			}
+
+			if err := NewBuilder().Write(credentials.VolumePath); err != nil {
+				t.Fatalf("Write() = %v", err)
+			}
+
+			b, err := ioutil.ReadFile(filepath.Join(credentials.VolumePath, ".docker", "config.json"))
+			if err != nil {
+				t.Fatalf("ioutil.ReadFile(.docker/config.json) = %v", err)
+			}
+			if d := cmp.Diff(tc.expected, string(b)); d != "" {
+				t.Errorf("Unexpected docker config (-want, +got): %s", d)
+			}
+		})
+	}
+}
+
+func writeBasicAuthSecret(t *testing.T, name, username, password string) {
+	t.Helper()
+	dir := credentials.VolumeName(name)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("os.MkdirAll(%s) = %v", dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, corev1.BasicAuthUsernameKey), []byte(username), 0777); err != nil {
+		t.Fatalf("ioutil.WriteFile(username) = %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, corev1.BasicAuthPasswordKey), []byte(password), 0777); err != nil {
+		t.Fatalf("ioutil.WriteFile(password) = %v", err)
+	}
+}
@@ -14,6 +14,8 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package dag builds the graph of the Tasks of a Pipeline from their dependencies, and
+// resolves the order in which they run, without depending on the reconcilers running them.
 package dag
 
 import (
@@ -50,6 +52,20 @@ type Graph struct {
 	Nodes map[string]*Node
 }
 
+// CycleError is returned when the dependencies of the Tasks of a Graph form a
+// cycle. Path holds the names of the Tasks of the cycle, in the order in which
+// they depend on each other, the first one being repeated at the end.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	if len(e.Path) == 2 {
+		return fmt.Sprintf("cycle detected; task %q depends on itself", e.Path[0])
+	}
+	return "cycle detected: " + strings.Join(e.Path, " -> ")
+}
+
 // Returns an empty Pipeline Graph
 func newGraph() *Graph {
 	return &Graph{Nodes: map[string]*Node{}}
@@ -70,17 +86,17 @@ func (g *Graph) addPipelineTask(t Task) (*Node, error) {
 func Build(tasks Tasks) (*Graph, error) {
 	d := newGraph()
 
-	deps := map[string][]string{}
 	// Add all Tasks mentioned in the `PipelineSpec`
 	for _, pt := range tasks.Items() {
 		if _, err := d.addPipelineTask(pt); err != nil {
 			return nil, fmt.Errorf("task %s is already present in Graph, can't add it again: %w", pt.HashKey(), err)
 		}
-		deps[pt.HashKey()] = pt.Deps()
 	}
-	// Process all from and runAfter constraints to add task dependency
-	for pt, taskDeps := range deps {
-		for _, previousTask := range taskDeps {
+	// Process all from and runAfter constraints to add task dependency, in the
+	// order of the Tasks so that the same cycle is reported for the same Tasks
+	for _, t := range tasks.Items() {
+		pt := t.HashKey()
+		for _, previousTask := range t.Deps() {
 			if err := addLink(pt, previousTask, d.Nodes); err != nil {
 				return nil, fmt.Errorf("couldn't add link between %s and %s: %w", pt, previousTask, err)
 			}
@@ -123,40 +139,43 @@ func GetSchedulable(g *Graph, doneTasks ...string) (sets.String, error) {
 func linkPipelineTasks(prev *Node, next *Node) error {
 	// Check for self cycle
 	if prev.Task.HashKey() == next.Task.HashKey() {
-		return fmt.Errorf("cycle detected; task %q depends on itself", next.Task.HashKey())
+		return &CycleError{Path: []string{next.Task.HashKey(), next.Task.HashKey()}}
 	}
 	// Check if we are adding cycles.
 	visited := map[string]bool{prev.Task.HashKey(): true, next.Task.HashKey(): true}
 	path := []string{next.Task.HashKey(), prev.Task.HashKey()}
-	if err := visit(next.Task.HashKey(), prev.Prev, path, visited); err != nil {
-		return fmt.Errorf("cycle detected: %w", err)
+	if cycle := visit(next.Task.HashKey(), prev.Prev, path, visited); cycle != nil {
+		return &CycleError{Path: reversed(cycle)}
 	}
 	next.Prev = append(next.Prev, prev)
 	prev.Next = append(prev.Next, next)
 	return nil
 }
 
-func visit(currentName string, nodes []*Node, path []string, visited map[string]bool) error {
+// visit follows the prev pointers of nodes, returning the path leading back to
+// a visited node if there is one.
+func visit(currentName string, nodes []*Node, path []string, visited map[string]bool) []string {
 	for _, n := range nodes {
-		path = append(path, n.Task.HashKey())
+		// Copy the path so that the paths of the siblings of n don't share it.
+		nodePath := append(path[:len(path):len(path)], n.Task.HashKey())
 		if _, ok := visited[n.Task.HashKey()]; ok {
-			return errors.New(getVisitedPath(path))
+			return nodePath
 		}
 		visited[currentName+"."+n.Task.HashKey()] = true
-		if err := visit(n.Task.HashKey(), n.Prev, path, visited); err != nil {
-			return err
+		if cycle := visit(n.Task.HashKey(), n.Prev, nodePath, visited); cycle != nil {
+			return cycle
 		}
 	}
 	return nil
 }
 
-func getVisitedPath(path []string) string {
+func reversed(path []string) []string {
 	// Reverse the path since we traversed the Graph using prev pointers.
 	for i := len(path)/2 - 1; i >= 0; i-- {
 		opp := len(path) - 1 - i
 		path[i], path[opp] = path[opp], path[i]
 	}
-	return strings.Join(path, " -> ")
+	return path
 }
 
 func addLink(pt string, previousTask string, nodes map[string]*Node) error {
@@ -23,8 +23,8 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	"github.com/tektoncd/pipeline/pkg/list"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/test/diff"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -236,6 +236,32 @@ func TestBuild_Parallel(t *testing.T) {
 	assertSameDAG(t, expectedDAG, g)
 }
 
+func TestBuild_SameTaskRef(t *testing.T) {
+	staging := v1beta1.PipelineTask{Name: "deploy-staging", TaskRef: &v1beta1.TaskRef{Name: "deploy"}}
+	prod := v1beta1.PipelineTask{Name: "deploy-prod", TaskRef: &v1beta1.TaskRef{Name: "deploy"}, RunAfter: []string{"deploy-staging"}}
+
+	// This test makes sure the same Task can be used by several PipelineTasks,
+	// since nodes are keyed by the PipelineTask name
+	//    deploy-staging
+	//         |
+	//    deploy-prod
+	nodeStaging := &dag.Node{Task: staging}
+	nodeProd := &dag.Node{Task: prod}
+	nodeStaging.Next = []*dag.Node{nodeProd}
+	nodeProd.Prev = []*dag.Node{nodeStaging}
+	expectedDAG := &dag.Graph{
+		Nodes: map[string]*dag.Node{
+			"deploy-staging": nodeStaging,
+			"deploy-prod":    nodeProd,
+		},
+	}
+	g, err := dag.Build(v1beta1.PipelineTaskList([]v1beta1.PipelineTask{staging, prod}))
+	if err != nil {
+		t.Fatalf("didn't expect error creating valid Pipeline but got %v", err)
+	}
+	assertSameDAG(t, expectedDAG, g)
+}
+
 func TestBuild_JoinMultipleRoots(t *testing.T) {
 	a := v1alpha1.PipelineTask{Name: "a"}
 	b := v1alpha1.PipelineTask{Name: "b"}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dag
+
+import (
+	"sort"
+)
+
+// DependencyKind is the reason a Task depends on another one.
+type DependencyKind string
+
+const (
+	// RunAfterDependency is a dependency declared with runAfter.
+	RunAfterDependency DependencyKind = "runAfter"
+	// FromDependency is a dependency on the Task producing an input resource.
+	FromDependency DependencyKind = "from"
+	// ResultDependency is a dependency on the Task producing a result used by a param or an env.
+	ResultDependency DependencyKind = "result"
+	// WhenDependency is a dependency on the Task producing a result used by a when expression.
+	WhenDependency DependencyKind = "when"
+)
+
+// Dependency is a dependency of a Task on the Task named Task.
+type Dependency struct {
+	Task string
+	Kind DependencyKind
+}
+
+// DependencyLister is implemented by the Tasks that can tell the kind of each of their
+// dependencies. The dependencies of other Tasks are taken from Deps, without a kind.
+type DependencyLister interface {
+	Dependencies() []Dependency
+}
+
+// Edge is a dependency of the Task To on the Task From in a ResolvedGraph.
+type Edge struct {
+	From string         `json:"from"`
+	To   string         `json:"to"`
+	Kind DependencyKind `json:"kind,omitempty"`
+}
+
+// ResolvedGraph is the Graph of Tasks as it would be run, for example to visualize it.
+type ResolvedGraph struct {
+	// Levels holds the names of the Tasks, grouped by the length of the longest chain
+	// of dependencies leading to them: the Tasks of a level only depend on Tasks of
+	// the previous levels, and the first level holds the Tasks without dependencies.
+	Levels [][]string `json:"levels"`
+	// Edges holds the dependencies between the Tasks.
+	Edges []Edge `json:"edges"`
+}
+
+// graphTask makes the dependencies of a Task from all their kinds its Deps, so that
+// building a Graph of them detects the cycles going through any kind of dependency.
+type graphTask struct {
+	name string
+	deps []Dependency
+}
+
+func (t graphTask) HashKey() string { return t.name }
+
+func (t graphTask) Deps() []string {
+	deps := make([]string, 0, len(t.deps))
+	for _, d := range t.deps {
+		deps = append(deps, d.Task)
+	}
+	return deps
+}
+
+type graphTasks []Task
+
+func (l graphTasks) Items() []Task { return l }
+
+// BuildGraph resolves the Graph of tasks without running them, for example for
+// v1beta1.PipelineTaskList(pipelineSpec.Tasks). It returns an error if the tasks
+// don't form a valid Graph, e.g. if their dependencies contain a cycle.
+func BuildGraph(tasks Tasks) (*ResolvedGraph, error) {
+	items := graphTasks{}
+	for _, t := range tasks.Items() {
+		gt := graphTask{name: t.HashKey()}
+		if l, ok := t.(DependencyLister); ok {
+			gt.deps = l.Dependencies()
+		} else {
+			for _, d := range t.Deps() {
+				gt.deps = append(gt.deps, Dependency{Task: d})
+			}
+		}
+		items = append(items, gt)
+	}
+	g, err := Build(items)
+	if err != nil {
+		return nil, err
+	}
+
+	rg := &ResolvedGraph{Levels: Layers(g), Edges: []Edge{}}
+
+	seen := map[Edge]bool{}
+	for _, t := range items {
+		for _, d := range t.(graphTask).deps {
+			e := Edge{From: d.Task, To: t.HashKey(), Kind: d.Kind}
+			if !seen[e] {
+				seen[e] = true
+				rg.Edges = append(rg.Edges, e)
+			}
+		}
+	}
+	sort.Slice(rg.Edges, func(i, j int) bool {
+		a, b := rg.Edges[i], rg.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+	return rg, nil
+}
+
+// Layers returns the names of the Tasks of g grouped in topological layers, each Task being
+// in the layer following the longest chain of dependencies leading to it: the Tasks of a layer
+// only depend on Tasks of the previous layers, so they can run in parallel once those are done,
+// and the first layer holds the Tasks without dependencies. The names of each layer are sorted.
+func Layers(g *Graph) [][]string {
+	layers := [][]string{}
+	levels := map[string]int{}
+	for name, n := range g.Nodes {
+		level := nodeLevel(n, levels)
+		for len(layers) <= level {
+			layers = append(layers, []string{})
+		}
+		layers[level] = append(layers[level], name)
+	}
+	for _, layer := range layers {
+		sort.Strings(layer)
+	}
+	return layers
+}
+
+// nodeLevel returns the length of the longest chain of dependencies leading to n,
+// memoizing it in levels.
+func nodeLevel(n *Node, levels map[string]int) int {
+	if level, ok := levels[n.Task.HashKey()]; ok {
+		return level
+	}
+	level := 0
+	for _, prev := range n.Prev {
+		if l := nodeLevel(prev, levels) + 1; l > level {
+			level = l
+		}
+	}
+	levels[n.Task.HashKey()] = level
+	return level
+}
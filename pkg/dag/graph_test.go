@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dag_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/dag"
+	"github.com/tektoncd/pipeline/test/diff"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestBuildGraph_Diamond(t *testing.T) {
+	//     a
+	//    / \
+	//   b   c
+	//    \ /
+	//     d
+	//     |
+	//     e
+	tasks := []v1beta1.PipelineTask{{
+		Name: "a",
+	}, {
+		Name:     "b",
+		RunAfter: []string{"a"},
+	}, {
+		Name: "c",
+		Params: []v1beta1.Param{{
+			Name:  "foo",
+			Value: v1beta1.NewArrayOrString("$(tasks.a.results.bar)"),
+		}},
+	}, {
+		Name:     "d",
+		RunAfter: []string{"b"},
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "$(tasks.c.results.ok)",
+			Operator: selection.In,
+			Values:   []string{"true"},
+		}},
+	}, {
+		Name:     "e",
+		RunAfter: []string{"d"},
+		Params: []v1beta1.Param{{
+			Name:  "foo",
+			Value: v1beta1.NewArrayOrString("$(tasks.d.results.bar)"),
+		}},
+	}}
+
+	got, err := dag.BuildGraph(v1beta1.PipelineTaskList(tasks))
+	if err != nil {
+		t.Fatalf("didn't expect error building the graph but got %v", err)
+	}
+	want := &dag.ResolvedGraph{
+		Levels: [][]string{{"a"}, {"b", "c"}, {"d"}, {"e"}},
+		Edges: []dag.Edge{
+			{From: "a", To: "b", Kind: dag.RunAfterDependency},
+			{From: "a", To: "c", Kind: dag.ResultDependency},
+			{From: "b", To: "d", Kind: dag.RunAfterDependency},
+			{From: "c", To: "d", Kind: dag.WhenDependency},
+			{From: "d", To: "e", Kind: dag.ResultDependency},
+			{From: "d", To: "e", Kind: dag.RunAfterDependency},
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("unexpected graph %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestBuildGraph_Cycle(t *testing.T) {
+	// The cycle goes through a when expression.
+	tasks := []v1beta1.PipelineTask{{
+		Name: "a",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "$(tasks.c.results.ok)",
+			Operator: selection.In,
+			Values:   []string{"true"},
+		}},
+	}, {
+		Name:     "b",
+		RunAfter: []string{"a"},
+	}, {
+		Name:     "c",
+		RunAfter: []string{"b"},
+	}}
+
+	_, err := dag.BuildGraph(v1beta1.PipelineTaskList(tasks))
+	if err == nil || !strings.Contains(err.Error(), "cycle detected") {
+		t.Errorf("expected a cycle to be detected but got %v", err)
+	}
+}
+
+func TestBuildGraph_MissingTask(t *testing.T) {
+	tasks := []v1beta1.PipelineTask{{
+		Name:     "a",
+		RunAfter: []string{"missing"},
+	}}
+	if _, err := dag.BuildGraph(v1beta1.PipelineTaskList(tasks)); err == nil {
+		t.Error("expected an error for a dependency on a missing task but got none")
+	}
+}
+
+// TestLayers_Golden checks the layers of the Pipelines of testdata/layers against the
+// golden files next to them, which hold one layer per line.
+func TestLayers_Golden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "layers", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no Pipeline found in testdata/layers")
+	}
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var p v1beta1.Pipeline
+			if err := yaml.Unmarshal(b, &p); err != nil {
+				t.Fatalf("failed to parse %s: %v", f, err)
+			}
+			golden, err := ioutil.ReadFile(strings.TrimSuffix(f, ".yaml") + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g, err := dag.Build(v1beta1.PipelineTaskList(p.Spec.Tasks))
+			if err != nil {
+				t.Fatalf("failed to build the graph of %s: %v", f, err)
+			}
+			var got strings.Builder
+			for _, layer := range dag.Layers(g) {
+				got.WriteString(strings.Join(layer, " ") + "\n")
+			}
+			if d := cmp.Diff(string(golden), got.String()); d != "" {
+				t.Errorf("unexpected layers %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestLayers_Empty(t *testing.T) {
+	g, err := dag.Build(v1beta1.PipelineTaskList{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([][]string{}, dag.Layers(g)); d != "" {
+		t.Errorf("unexpected layers %s", diff.PrintWantGot(d))
+	}
+}
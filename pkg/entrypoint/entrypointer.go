@@ -17,10 +17,17 @@ limitations under the License.
 package entrypoint
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
@@ -34,6 +41,19 @@ const (
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
+// ResultsLogFile is the name of the file of the results directory the results
+// are written to when they are read from the logs of the containers.
+const ResultsLogFile = ".results.json"
+
+// BudgetFileSuffix is appended to the post file of a step run with a Timeout to
+// name the file the time left unused by the step is written to, so that it is
+// added to the Timeout of the next step.
+const BudgetFileSuffix = ".budget"
+
+// restartBackoff is the time waited before running a command again when
+// RestartOnFailure is set.
+var restartBackoff = time.Second
+
 // Entrypointer holds fields for running commands with redirected
 // entrypoints.
 type Entrypointer struct {
@@ -63,6 +83,35 @@ type Entrypointer struct {
 
 	// Results is the set of files that might contain task results
 	Results []string
+	// ResultJSONPaths maps the names of the results whose value is extracted
+	// from the JSON document written to their file to their JSONPath template.
+	ResultJSONPaths map[string]string
+	// ResultsDir is the directory holding the result files. It defaults to
+	// pipeline.DefaultResultPath.
+	ResultsDir string
+	// ResultsLogDelimiter, when set, makes the results be written to
+	// ResultsLogFile and printed to Stdout enclosed by it, instead of being
+	// written to the termination message.
+	ResultsLogDelimiter string
+	// Stdout is where the results are printed. It defaults to os.Stdout.
+	Stdout io.Writer
+
+	// RestartOnFailure indicates the command is run again every time it
+	// exits with a non-zero exit code.
+	RestartOnFailure bool
+
+	// Timeout, when not zero, is the time the command is allowed to run for,
+	// on top of the time left unused by the previous steps, found in the
+	// budget files of the WaitFiles.
+	Timeout time.Duration
+
+	// PreStepHook, when set, is the path of a shell script run before the
+	// command. The command isn't run if the script exits with a non-zero
+	// exit code.
+	PreStepHook string
+	// PostStepHook, when set, is the path of a shell script run after the
+	// command, whether it failed or not, with its exit code as argument.
+	PostStepHook string
 }
 
 // Waiter encapsulates waiting for files to exist.
@@ -73,7 +122,8 @@ type Waiter interface {
 
 // Runner encapsulates running commands.
 type Runner interface {
-	Run(args ...string) error
+	// Run runs the command, killing it when ctx is done.
+	Run(ctx context.Context, args ...string) error
 }
 
 // PostWriter encapsulates writing a file when complete.
@@ -113,53 +163,243 @@ func (e Entrypointer) Go() error {
 	if e.Entrypoint != "" {
 		e.Args = append([]string{e.Entrypoint}, e.Args...)
 	}
+	startedAt := time.Now()
 	output = append(output, v1beta1.PipelineResourceResult{
 		Key:   "StartedAt",
-		Value: time.Now().Format(timeFormat),
+		Value: startedAt.Format(timeFormat),
 	})
 
-	err := e.Runner.Run(e.Args...)
+	ctx := context.Background()
+	var timeout time.Duration
+	if e.Timeout > 0 {
+		timeout = e.Timeout + e.readBudget(logger)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	// Write the post file *no matter what*
-	e.WritePostFile(e.PostFile, err)
+	var err error
+	if e.PreStepHook != "" {
+		err = e.Runner.Run(ctx, "sh", e.PreStepHook)
+	}
+	if err == nil {
+		err = e.Runner.Run(ctx, e.Args...)
+		for e.RestartOnFailure && isExitError(err) && ctx.Err() == nil {
+			logger.Infof("Command exited with error, restarting: %s", err)
+			time.Sleep(restartBackoff)
+			err = e.Runner.Run(ctx, e.Args...)
+		}
+		if e.PostStepHook != "" {
+			// The exit code of the command is kept, whatever the hook returns.
+			if hErr := e.Runner.Run(ctx, "sh", e.PostStepHook, strconv.Itoa(exitCode(err))); hErr != nil {
+				logger.Warnf("Post step hook failed: %s", hErr)
+			}
+		}
+	} else {
+		logger.Infof("Pre step hook failed, not running the command: %s", err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:        v1beta1.StepTimeoutExceededKey,
+			Value:      timeout.String(),
+			ResultType: v1beta1.InternalTektonResultType,
+		})
+	}
 
 	// strings.Split(..) with an empty string returns an array that contains one element, an empty string.
 	// This creates an error when trying to open the result folder as a file.
 	if len(e.Results) >= 1 && e.Results[0] != "" {
-		if err := e.readResultsFromDisk(); err != nil {
-			logger.Fatalf("Error while handling results: %s", err)
+		if rErr := e.readResultsFromDisk(); rErr != nil {
+			var extractionErr ResultExtractionError
+			if !errors.As(rErr, &extractionErr) {
+				e.WritePostFile(e.PostFile, err)
+				logger.Fatalf("Error while handling results: %s", rErr)
+			}
+			// A result that can't be extracted fails the step, so the
+			// following steps are skipped.
+			output = append(output, v1beta1.PipelineResourceResult{
+				Key:        v1beta1.ResultExtractionErrorKey,
+				Value:      extractionErr.Error(),
+				ResultType: v1beta1.InternalTektonResultType,
+			})
+			if err == nil {
+				err = extractionErr
+			}
 		}
 	}
 
+	if e.Timeout > 0 && err == nil {
+		e.writeBudget(logger, timeout-time.Since(startedAt))
+	}
+
+	// Write the post file *no matter what*
+	e.WritePostFile(e.PostFile, err)
+
 	return err
 }
 
+// readBudget returns the time left unused by the previous steps, written to
+// the budget files of the WaitFiles.
+func (e Entrypointer) readBudget(logger *zap.SugaredLogger) time.Duration {
+	var budget time.Duration
+	for _, f := range e.WaitFiles {
+		if f == "" {
+			continue
+		}
+		content, err := ioutil.ReadFile(f + BudgetFileSuffix)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			logger.Warnf("Error reading the time left by the previous steps: %s", err)
+			continue
+		}
+		d, err := time.ParseDuration(string(content))
+		if err != nil {
+			logger.Warnf("Ignoring the invalid time left by the previous steps %q: %s", content, err)
+			continue
+		}
+		budget += d
+	}
+	return budget
+}
+
+// writeBudget writes the time left unused by the step to the budget file of
+// its PostFile, for the next step. It must be written before the PostFile.
+func (e Entrypointer) writeBudget(logger *zap.SugaredLogger, budget time.Duration) {
+	if e.PostFile == "" || budget <= 0 {
+		return
+	}
+	if err := ioutil.WriteFile(e.PostFile+BudgetFileSuffix, []byte(budget.String()), 0666); err != nil {
+		logger.Warnf("Error writing the time left for the next steps: %s", err)
+	}
+}
+
+// ResultExtractionError is returned when the value of a result can't be
+// extracted from its file with the JSONPath of the result.
+type ResultExtractionError struct {
+	Result string
+	Reason string
+}
+
+func (e ResultExtractionError) Error() string {
+	return fmt.Sprintf("failed to extract result %q: %s", e.Result, e.Reason)
+}
+
+// exitCode returns the exit code of a command which returned err, 1 if it
+// couldn't be run.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode()
+	default:
+		return 1
+	}
+}
+
+// isExitError returns true if the command was started and exited with a
+// non-zero exit code. Errors starting the command are not retried.
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+// readResultsFromDisk writes the results found in the results directory to the
+// termination message, or to the logs when ResultsLogDelimiter is set. The results that can be extracted are written even if
+// another one can't be, in which case a ResultExtractionError is returned.
 func (e Entrypointer) readResultsFromDisk() error {
+	resultsDir := e.ResultsDir
+	if resultsDir == "" {
+		resultsDir = pipeline.DefaultResultPath
+	}
 	output := []v1beta1.PipelineResourceResult{}
+	var extractionErr error
 	for _, resultFile := range e.Results {
 		if resultFile == "" {
 			continue
 		}
-		fileContents, err := ioutil.ReadFile(filepath.Join(pipeline.DefaultResultPath, resultFile))
+		fileContents, err := ioutil.ReadFile(filepath.Join(resultsDir, resultFile))
 		if os.IsNotExist(err) {
 			continue
 		} else if err != nil {
 			return err
 		}
+		value := string(fileContents)
+		if path, ok := e.ResultJSONPaths[resultFile]; ok {
+			if value, err = extractJSONPath(resultFile, path, fileContents); err != nil {
+				if extractionErr == nil {
+					extractionErr = err
+				}
+				continue
+			}
+		}
 		// if the file doesn't exist, ignore it
 		output = append(output, v1beta1.PipelineResourceResult{
 			Key:        resultFile,
-			Value:      string(fileContents),
+			Value:      value,
 			ResultType: v1beta1.TaskRunResultType,
 		})
 	}
-	// push output to termination path
+	// push output to termination path, or to the logs when they are read from
+	// there
 	if len(output) != 0 {
-		if err := termination.WriteMessage(e.TerminationPath, output); err != nil {
+		if e.ResultsLogDelimiter != "" {
+			stdout := e.Stdout
+			if stdout == nil {
+				stdout = os.Stdout
+			}
+			if err := termination.WriteResultsLog(stdout, filepath.Join(resultsDir, ResultsLogFile), e.ResultsLogDelimiter, output); err != nil {
+				return err
+			}
+		} else if err := termination.WriteMessage(e.TerminationPath, output); err != nil {
 			return err
 		}
 	}
-	return nil
+	return extractionErr
+}
+
+// extractJSONPath returns the value found at path in the JSON document content
+// written to the file of the result called name. Strings are returned as is, and
+// other values as JSON. When path matches several values, they are returned as a
+// JSON array.
+func extractJSONPath(name, path string, content []byte) (string, error) {
+	j, err := v1beta1.ParseResultJSONPath(name, path)
+	if err != nil {
+		return "", ResultExtractionError{Result: name, Reason: err.Error()}
+	}
+	var data interface{}
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+	if err := d.Decode(&data); err != nil {
+		return "", ResultExtractionError{Result: name, Reason: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	results, err := j.FindResults(data)
+	if err != nil {
+		return "", ResultExtractionError{Result: name, Reason: fmt.Sprintf("JSONPath %q: %v", path, err)}
+	}
+	var values []interface{}
+	for _, r := range results {
+		for _, v := range r {
+			values = append(values, v.Interface())
+		}
+	}
+	var value interface{} = values
+	switch len(values) {
+	case 0:
+		return "", ResultExtractionError{Result: name, Reason: fmt.Sprintf("JSONPath %q matched no value", path)}
+	case 1:
+		if s, ok := values[0].(string); ok {
+			return s, nil
+		}
+		value = values[0]
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", ResultExtractionError{Result: name, Reason: err.Error()}
+	}
+	return string(b), nil
 }
 
 // WritePostFile write the postfile
@@ -17,15 +17,25 @@ limitations under the License.
 package entrypoint
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/termination"
 	"github.com/tektoncd/pipeline/test/diff"
 )
 
@@ -205,6 +215,417 @@ func TestEntrypointer(t *testing.T) {
 	}
 }
 
+func TestEntrypointerRestartOnFailure(t *testing.T) {
+	defer func(b time.Duration) { restartBackoff = b }(restartBackoff)
+	restartBackoff = 0
+
+	for _, c := range []struct {
+		desc          string
+		failures      int
+		err           error
+		wantRuns      int
+		expectedError bool
+	}{{
+		desc:     "succeeds first time",
+		wantRuns: 1,
+	}, {
+		desc:     "restarted until it succeeds",
+		failures: 2,
+		err:      &exec.ExitError{},
+		wantRuns: 3,
+	}, {
+		desc:          "not restarted when the command can't be started",
+		failures:      2,
+		err:           errors.New("executable file not found"),
+		wantRuns:      1,
+		expectedError: true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fr := &fakeFlakyRunner{failures: c.failures, err: c.err}
+			err := Entrypointer{
+				Entrypoint:       "echo",
+				Waiter:           &fakeWaiter{},
+				Runner:           fr,
+				PostWriter:       &fakePostWriter{},
+				TerminationPath:  "termination",
+				RestartOnFailure: true,
+			}.Go()
+			if (err != nil) != c.expectedError {
+				t.Errorf("Entrypointer returned error %v, expected error: %t", err, c.expectedError)
+			}
+			if fr.runs != c.wantRuns {
+				t.Errorf("Ran command %d times, want %d", fr.runs, c.wantRuns)
+			}
+			if err := os.Remove("termination"); err != nil {
+				t.Errorf("Could not remove termination path: %s", err)
+			}
+		})
+	}
+}
+
+func TestEntrypointerHooks(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	for _, c := range []struct {
+		desc          string
+		errs          map[string]error
+		wantCommands  [][]string
+		expectedError bool
+	}{{
+		desc: "pre and post step hooks",
+		wantCommands: [][]string{
+			{"sh", "/tekton/scripts/pre-step-hook"},
+			{"echo", "hello"},
+			{"sh", "/tekton/scripts/post-step-hook", "0"},
+		},
+	}, {
+		desc: "pre step hook fails",
+		errs: map[string]error{"/tekton/scripts/pre-step-hook": exitErr},
+		wantCommands: [][]string{
+			{"sh", "/tekton/scripts/pre-step-hook"},
+		},
+		expectedError: true,
+	}, {
+		desc: "command fails",
+		errs: map[string]error{"hello": exitErr},
+		wantCommands: [][]string{
+			{"sh", "/tekton/scripts/pre-step-hook"},
+			{"echo", "hello"},
+			{"sh", "/tekton/scripts/post-step-hook", "3"},
+		},
+		expectedError: true,
+	}, {
+		desc: "post step hook fails",
+		errs: map[string]error{"/tekton/scripts/post-step-hook": exitErr},
+		wantCommands: [][]string{
+			{"sh", "/tekton/scripts/pre-step-hook"},
+			{"echo", "hello"},
+			{"sh", "/tekton/scripts/post-step-hook", "0"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fr := &fakeRecordingRunner{errs: c.errs}
+			err := Entrypointer{
+				Entrypoint:      "echo",
+				Args:            []string{"hello"},
+				Waiter:          &fakeWaiter{},
+				Runner:          fr,
+				PostWriter:      &fakePostWriter{},
+				TerminationPath: "termination",
+				PreStepHook:     "/tekton/scripts/pre-step-hook",
+				PostStepHook:    "/tekton/scripts/post-step-hook",
+			}.Go()
+			if (err != nil) != c.expectedError {
+				t.Errorf("Entrypointer returned error %v, expected error: %t", err, c.expectedError)
+			}
+			if d := cmp.Diff(c.wantCommands, fr.commands); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+			if err := os.Remove("termination"); err != nil {
+				t.Errorf("Could not remove termination path: %s", err)
+			}
+		})
+	}
+}
+
+func TestEntrypointerTimeout(t *testing.T) {
+	for _, c := range []struct {
+		desc         string
+		timeout      time.Duration
+		budget       string
+		wantTimeout  time.Duration
+		wantTimedOut bool
+	}{{
+		desc:        "no time left by the previous steps",
+		timeout:     time.Minute,
+		wantTimeout: time.Minute,
+	}, {
+		desc:        "time left by the previous steps",
+		timeout:     time.Minute,
+		budget:      "30s",
+		wantTimeout: 90 * time.Second,
+	}, {
+		desc:        "invalid time left by the previous steps",
+		timeout:     time.Minute,
+		budget:      "later",
+		wantTimeout: time.Minute,
+	}, {
+		desc:         "timed out",
+		timeout:      time.Millisecond,
+		wantTimeout:  time.Millisecond,
+		wantTimedOut: true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "tools")
+			if err != nil {
+				t.Fatalf("Could not create tools directory: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			waitFile, postFile := filepath.Join(dir, "0"), filepath.Join(dir, "1")
+			if c.budget != "" {
+				if err := ioutil.WriteFile(waitFile+BudgetFileSuffix, []byte(c.budget), 0666); err != nil {
+					t.Fatalf("Could not write budget file: %v", err)
+				}
+			}
+			terminationPath := filepath.Join(dir, "termination")
+
+			fr := &fakeDeadlineRunner{wait: c.wantTimedOut}
+			fpw := &fakePostWriter{}
+			start := time.Now()
+			err = Entrypointer{
+				Entrypoint:      "echo",
+				WaitFiles:       []string{waitFile},
+				Waiter:          &fakeWaiter{},
+				Runner:          fr,
+				PostWriter:      fpw,
+				PostFile:        postFile,
+				TerminationPath: terminationPath,
+				Timeout:         c.timeout,
+			}.Go()
+
+			if !fr.hasDeadline {
+				t.Fatal("Expected the command to be run with a deadline")
+			}
+			if got := fr.deadline.Sub(start); got < c.wantTimeout || got > c.wantTimeout+time.Second {
+				t.Errorf("Ran the command with a timeout of %s, want %s", got, c.wantTimeout)
+			}
+
+			budget, readErr := ioutil.ReadFile(postFile + BudgetFileSuffix)
+			if c.wantTimedOut {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("Expected the command to time out, got %v", err)
+				}
+				if !os.IsNotExist(readErr) {
+					t.Errorf("Expected no time left for the next steps, got %q", budget)
+				}
+				fileContents, err := ioutil.ReadFile(terminationPath)
+				if err != nil {
+					t.Fatalf("Could not read termination message: %v", err)
+				}
+				var got []v1beta1.PipelineResourceResult
+				if err := json.Unmarshal(fileContents, &got); err != nil {
+					t.Fatalf("Could not parse termination message: %v", err)
+				}
+				want := []v1beta1.PipelineResourceResult{{
+					Key: v1beta1.StepTimeoutExceededKey, Value: c.wantTimeout.String(), ResultType: v1beta1.InternalTektonResultType,
+				}}
+				if d := cmp.Diff(want, got, cmpopts.IgnoreSliceElements(func(r v1beta1.PipelineResourceResult) bool {
+					return r.Key == "StartedAt"
+				})); d != "" {
+					t.Errorf("Termination message diff %s", diff.PrintWantGot(d))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+			if readErr != nil {
+				t.Fatalf("Could not read the time left for the next steps: %v", readErr)
+			}
+			left, err := time.ParseDuration(string(budget))
+			if err != nil {
+				t.Fatalf("Could not parse the time left for the next steps: %v", err)
+			}
+			if left <= c.wantTimeout-time.Second || left > c.wantTimeout {
+				t.Errorf("Left %s for the next steps, want about %s", left, c.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestEntrypointerResults(t *testing.T) {
+	for _, c := range []struct {
+		desc      string
+		files     map[string]string
+		jsonPaths map[string]string
+		want      []v1beta1.PipelineResourceResult
+		wantErr   string
+	}{{
+		desc:  "plain result",
+		files: map[string]string{"foo": "bar"},
+		want: []v1beta1.PipelineResourceResult{{
+			Key: "foo", Value: "bar", ResultType: v1beta1.TaskRunResultType,
+		}},
+	}, {
+		desc:      "extracted string",
+		files:     map[string]string{"digest": `{"image": {"digest": "sha256:1234"}}`},
+		jsonPaths: map[string]string{"digest": "{.image.digest}"},
+		want: []v1beta1.PipelineResourceResult{{
+			Key: "digest", Value: "sha256:1234", ResultType: v1beta1.TaskRunResultType,
+		}},
+	}, {
+		desc:      "extracted number and object",
+		files:     map[string]string{"count": `{"count": 12345678901234567890}`, "labels": `{"meta": {"labels": {"a": "b"}}}`},
+		jsonPaths: map[string]string{"count": "{.count}", "labels": "{.meta.labels}"},
+		want: []v1beta1.PipelineResourceResult{{
+			Key: "count", Value: "12345678901234567890", ResultType: v1beta1.TaskRunResultType,
+		}, {
+			Key: "labels", Value: `{"a":"b"}`, ResultType: v1beta1.TaskRunResultType,
+		}},
+	}, {
+		desc:      "several values extracted as an array",
+		files:     map[string]string{"names": `{"items": [{"name": "a"}, {"name": "b"}]}`},
+		jsonPaths: map[string]string{"names": "{.items[*].name}"},
+		want: []v1beta1.PipelineResourceResult{{
+			Key: "names", Value: `["a","b"]`, ResultType: v1beta1.TaskRunResultType,
+		}},
+	}, {
+		desc:      "invalid JSON",
+		files:     map[string]string{"digest": "sha256:1234", "foo": "bar"},
+		jsonPaths: map[string]string{"digest": "{.image.digest}"},
+		want: []v1beta1.PipelineResourceResult{{
+			Key: "foo", Value: "bar", ResultType: v1beta1.TaskRunResultType,
+		}},
+		wantErr: `failed to extract result "digest": invalid JSON: invalid character 's' looking for beginning of value`,
+	}, {
+		desc:      "missing path",
+		files:     map[string]string{"digest": `{"image": {}}`},
+		jsonPaths: map[string]string{"digest": "{.image.digest}"},
+		wantErr:   `failed to extract result "digest": JSONPath "{.image.digest}": digest is not found`,
+	}, {
+		desc:      "empty match",
+		files:     map[string]string{"names": `{"items": []}`},
+		jsonPaths: map[string]string{"names": "{.items[*].name}"},
+		wantErr:   `failed to extract result "names": JSONPath "{.items[*].name}" matched no value`,
+	}, {
+		desc:      "path not enclosed in braces",
+		files:     map[string]string{"digest": `{"image": {"digest": "sha256:1234"}}`},
+		jsonPaths: map[string]string{"digest": ".image.digest"},
+		wantErr:   `failed to extract result "digest": JSONPath ".image.digest" must be enclosed in curly braces, e.g. "{.image.digest}"`,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			resultsDir, err := ioutil.TempDir("", "results")
+			if err != nil {
+				t.Fatalf("Could not create results directory: %v", err)
+			}
+			defer os.RemoveAll(resultsDir)
+			terminationPath := filepath.Join(resultsDir, "termination")
+
+			var results []string
+			for name, content := range c.files {
+				results = append(results, name)
+				if err := ioutil.WriteFile(filepath.Join(resultsDir, name), []byte(content), 0666); err != nil {
+					t.Fatalf("Could not write result %q: %v", name, err)
+				}
+			}
+			sort.Strings(results)
+
+			fpw := &fakePostWriter{}
+			err = Entrypointer{
+				Entrypoint:      "echo",
+				Waiter:          &fakeWaiter{},
+				Runner:          &fakeRunner{},
+				PostWriter:      fpw,
+				PostFile:        "writeme",
+				TerminationPath: terminationPath,
+				Results:         results,
+				ResultJSONPaths: c.jsonPaths,
+				ResultsDir:      resultsDir,
+			}.Go()
+
+			want := c.want
+			wantPostFile := "writeme"
+			if c.wantErr != "" {
+				var extractionErr ResultExtractionError
+				if !errors.As(err, &extractionErr) {
+					t.Fatalf("Expected a ResultExtractionError, got %v", err)
+				}
+				if d := cmp.Diff(c.wantErr, err.Error()); d != "" {
+					t.Errorf("Entrypointer error diff %s", diff.PrintWantGot(d))
+				}
+				want = append(want, v1beta1.PipelineResourceResult{
+					Key: v1beta1.ResultExtractionErrorKey, Value: c.wantErr, ResultType: v1beta1.InternalTektonResultType,
+				})
+				wantPostFile += ".err"
+			} else if err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+			if fpw.wrote == nil || *fpw.wrote != wantPostFile {
+				t.Errorf("Wrote post file %v, want %q", fpw.wrote, wantPostFile)
+			}
+
+			fileContents, err := ioutil.ReadFile(terminationPath)
+			if err != nil {
+				t.Fatalf("Could not read termination message: %v", err)
+			}
+			var got []v1beta1.PipelineResourceResult
+			if err := json.Unmarshal(fileContents, &got); err != nil {
+				t.Fatalf("Could not parse termination message: %v", err)
+			}
+			if d := cmp.Diff(want, got, cmpopts.IgnoreSliceElements(func(r v1beta1.PipelineResourceResult) bool {
+				return r.Key == "StartedAt"
+			}), cmpopts.EquateEmpty()); d != "" {
+				t.Errorf("Termination message diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestEntrypointerResultsLog(t *testing.T) {
+	resultsDir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Could not create results directory: %v", err)
+	}
+	defer os.RemoveAll(resultsDir)
+	terminationPath := filepath.Join(resultsDir, "termination")
+
+	// The result doesn't fit in the termination message.
+	value := strings.Repeat("a", termination.MaxContainerTerminationMessageLength)
+	if err := ioutil.WriteFile(filepath.Join(resultsDir, "foo"), []byte(value), 0666); err != nil {
+		t.Fatalf("Could not write result: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := (Entrypointer{
+		Entrypoint:          "echo",
+		Waiter:              &fakeWaiter{},
+		Runner:              &fakeRunner{},
+		PostWriter:          &fakePostWriter{},
+		TerminationPath:     terminationPath,
+		Results:             []string{"foo"},
+		ResultsDir:          resultsDir,
+		ResultsLogDelimiter: "delimiter",
+		Stdout:              &stdout,
+	}).Go(); err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+
+	want := []v1beta1.PipelineResourceResult{{
+		Key: "foo", Value: value, ResultType: v1beta1.TaskRunResultType,
+	}}
+	if d := cmp.Diff(want, termination.ParseResultsLog(stdout.Bytes(), "delimiter")); d != "" {
+		t.Errorf("Results log diff %s", diff.PrintWantGot(d))
+	}
+	if _, err := os.Stat(filepath.Join(resultsDir, ResultsLogFile)); err != nil {
+		t.Errorf("Expected the results to be written to %s: %v", ResultsLogFile, err)
+	}
+
+	fileContents, err := ioutil.ReadFile(terminationPath)
+	if err != nil {
+		t.Fatalf("Could not read termination message: %v", err)
+	}
+	var got []v1beta1.PipelineResourceResult
+	if err := json.Unmarshal(fileContents, &got); err != nil {
+		t.Fatalf("Could not parse termination message: %v", err)
+	}
+	for _, r := range got {
+		if r.Key != "StartedAt" {
+			t.Errorf("Expected no result in the termination message but got %v", r)
+		}
+	}
+
+	// Without a delimiter, the same result is above the max size of the
+	// termination message.
+	err = Entrypointer{
+		TerminationPath: filepath.Join(resultsDir, "other-termination"),
+		Results:         []string{"foo"},
+		ResultsDir:      resultsDir,
+	}.readResultsFromDisk()
+	var lengthErr termination.MessageLengthError
+	if !errors.As(err, &lengthErr) {
+		t.Errorf("Expected a MessageLengthError, got %v", err)
+	}
+}
+
 type fakeWaiter struct{ waited []string }
 
 func (f *fakeWaiter) Wait(file string, _ bool) error {
@@ -214,7 +635,7 @@ func (f *fakeWaiter) Wait(file string, _ bool) error {
 
 type fakeRunner struct{ args *[]string }
 
-func (f *fakeRunner) Run(args ...string) error {
+func (f *fakeRunner) Run(_ context.Context, args ...string) error {
 	f.args = &args
 	return nil
 }
@@ -230,9 +651,51 @@ func (f *fakeErrorWaiter) Wait(file string, expectContent bool) error {
 	return errors.New("waiter failed")
 }
 
+type fakeFlakyRunner struct {
+	failures, runs int
+	err            error
+}
+
+func (f *fakeFlakyRunner) Run(_ context.Context, args ...string) error {
+	f.runs++
+	if f.runs <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+// fakeRecordingRunner records the commands it runs, and fails those whose
+// second argument has an error in errs.
+type fakeRecordingRunner struct {
+	commands [][]string
+	errs     map[string]error
+}
+
+func (f *fakeRecordingRunner) Run(_ context.Context, args ...string) error {
+	f.commands = append(f.commands, args)
+	return f.errs[args[1]]
+}
+
 type fakeErrorRunner struct{ args *[]string }
 
-func (f *fakeErrorRunner) Run(args ...string) error {
+func (f *fakeErrorRunner) Run(_ context.Context, args ...string) error {
 	f.args = &args
 	return errors.New("runner failed")
 }
+
+// fakeDeadlineRunner records the deadline of the command, and waits for it to
+// pass when wait is set.
+type fakeDeadlineRunner struct {
+	wait        bool
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (f *fakeDeadlineRunner) Run(ctx context.Context, args ...string) error {
+	f.deadline, f.hasDeadline = ctx.Deadline()
+	if f.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
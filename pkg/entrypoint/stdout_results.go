@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ResultMarkerPrefix starts the lines of stdout setting a result, which read
+// "::set-result name=<result>::<value>".
+const ResultMarkerPrefix = "::set-result "
+
+// resultMarkerUnescaper decodes the characters escaped in the values of the
+// result markers, so that they fit on one line.
+var resultMarkerUnescaper = strings.NewReplacer("%0A", "\n", "%0D", "\r", "%25", "%")
+
+// StdoutResultsWriter copies the stdout of a command to W, and writes the value
+// of the results it sets with result markers to their file in ResultsDir, where
+// they are read from like the results written by the command itself. Markers
+// setting a result several times write its last value, and markers for results
+// which aren't declared are ignored.
+type StdoutResultsWriter struct {
+	W          io.Writer
+	ResultsDir string
+	Results    []string
+
+	// line holds the last line written to W, until it is complete.
+	line []byte
+}
+
+var _ io.Writer = (*StdoutResultsWriter)(nil)
+
+// Write copies p to W, and writes the results set by the lines it completes.
+func (w *StdoutResultsWriter) Write(p []byte) (int, error) {
+	n, err := w.W.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.setResult(string(w.line[:i])); err != nil {
+			return n, err
+		}
+		w.line = w.line[i+1:]
+	}
+	return n, nil
+}
+
+// Flush writes the result set by the last line, if the command exited without
+// completing it.
+func (w *StdoutResultsWriter) Flush() error {
+	line := string(w.line)
+	w.line = nil
+	return w.setResult(line)
+}
+
+// setResult writes the value of the result set by line, if it is a result marker.
+func (w *StdoutResultsWriter) setResult(line string) error {
+	name, value, ok := parseResultMarker(line)
+	if !ok || !w.isResult(name) {
+		return nil
+	}
+	return ioutil.WriteFile(filepath.Join(w.ResultsDir, name), []byte(value), 0666)
+}
+
+func (w *StdoutResultsWriter) isResult(name string) bool {
+	for _, r := range w.Results {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResultMarker returns the name and the unescaped value of the result set
+// by line, and false if line isn't a result marker.
+func parseResultMarker(line string) (string, string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasPrefix(line, ResultMarkerPrefix) {
+		return "", "", false
+	}
+	marker := strings.TrimPrefix(line, ResultMarkerPrefix)
+	i := strings.Index(marker, "::")
+	if i < 0 {
+		return "", "", false
+	}
+	property, value := marker[:i], marker[i+2:]
+	if !strings.HasPrefix(property, "name=") {
+		return "", "", false
+	}
+	name := strings.TrimPrefix(property, "name=")
+	if name == "" {
+		return "", "", false
+	}
+	return name, resultMarkerUnescaper.Replace(value), true
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestStdoutResultsWriter(t *testing.T) {
+	for _, c := range []struct {
+		desc string
+		// writes are the chunks the captured stdout is written in.
+		writes []string
+		want   map[string]string
+	}{{
+		desc:   "no markers",
+		writes: []string{"building...\n", "done\n"},
+		want:   map[string]string{},
+	}, {
+		desc: "several results",
+		writes: []string{
+			"building...\n",
+			"::set-result name=digest::sha256:abc\n",
+			"::set-result name=url::https://example.com/app\n",
+		},
+		want: map[string]string{"digest": "sha256:abc", "url": "https://example.com/app"},
+	}, {
+		desc:   "markers in one write",
+		writes: []string{"::set-result name=digest::sha256:abc\nlog\n::set-result name=url::https://example.com/app\n"},
+		want:   map[string]string{"digest": "sha256:abc", "url": "https://example.com/app"},
+	}, {
+		desc:   "marker split across writes",
+		writes: []string{"::set-res", "ult name=dig", "est::sha256", ":abc\n"},
+		want:   map[string]string{"digest": "sha256:abc"},
+	}, {
+		desc:   "result set twice",
+		writes: []string{"::set-result name=digest::sha256:abc\n", "::set-result name=digest::sha256:def\n"},
+		want:   map[string]string{"digest": "sha256:def"},
+	}, {
+		desc:   "escaped value",
+		writes: []string{"::set-result name=digest::line 1%0Aline 2%0D%0A100%25 :: done\n"},
+		want:   map[string]string{"digest": "line 1\nline 2\r\n100% :: done"},
+	}, {
+		desc:   "empty value",
+		writes: []string{"::set-result name=digest::\n"},
+		want:   map[string]string{"digest": ""},
+	}, {
+		desc:   "carriage return",
+		writes: []string{"::set-result name=digest::sha256:abc\r\n"},
+		want:   map[string]string{"digest": "sha256:abc"},
+	}, {
+		desc:   "last line without newline",
+		writes: []string{"log\n", "::set-result name=digest::sha256:abc"},
+		want:   map[string]string{"digest": "sha256:abc"},
+	}, {
+		desc: "not markers",
+		writes: []string{
+			"  ::set-result name=digest::indented\n",
+			"::set-result digest::no name\n",
+			"::set-result name=::empty name\n",
+			"::set-result name=digest no separator\n",
+			"::set-output name=digest::other command\n",
+		},
+		want: map[string]string{},
+	}, {
+		desc:   "undeclared result",
+		writes: []string{"::set-result name=other::value\n", "::set-result name=../digest::value\n"},
+		want:   map[string]string{},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			resultsDir := t.TempDir()
+			var stdout bytes.Buffer
+			w := &StdoutResultsWriter{
+				W:          &stdout,
+				ResultsDir: resultsDir,
+				Results:    []string{"digest", "url"},
+			}
+			var wantStdout string
+			for _, s := range c.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatalf("Write(%q) = %v", s, err)
+				}
+				if n != len(s) {
+					t.Errorf("Write(%q) wrote %d bytes, want %d", s, n, len(s))
+				}
+				wantStdout += s
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() = %v", err)
+			}
+
+			if d := cmp.Diff(wantStdout, stdout.String()); d != "" {
+				t.Errorf("Stdout %s", diff.PrintWantGot(d))
+			}
+			got := map[string]string{}
+			files, err := ioutil.ReadDir(resultsDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range files {
+				content, err := ioutil.ReadFile(filepath.Join(resultsDir, f.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[f.Name()] = string(content)
+			}
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("Results %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -171,6 +171,27 @@ func ShowCommit(logger *zap.SugaredLogger, revision, path string) (string, error
 	return strings.TrimSuffix(output, "\n"), nil
 }
 
+// Commit describes a commit of a git repository.
+type Commit struct {
+	SHA           string
+	ShortSHA      string
+	CommitterDate string
+}
+
+// ShowCommitInfo returns the SHA, abbreviated SHA and committer date, in strict ISO 8601
+// format, of revision.
+func ShowCommitInfo(logger *zap.SugaredLogger, revision, path string) (Commit, error) {
+	output, err := run(logger, path, "show", "-q", "--pretty=format:%H%n%h%n%cI", revision)
+	if err != nil {
+		return Commit{}, err
+	}
+	fields := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(fields) != 3 {
+		return Commit{}, fmt.Errorf("unexpected output showing commit %s: %q", revision, output)
+	}
+	return Commit{SHA: fields[0], ShortSHA: fields[1], CommitterDate: fields[2]}, nil
+}
+
 func ShowRef(logger *zap.SugaredLogger, revision, path string) (string, error) {
 	output, err := run(logger, path, "show", "-q", "--pretty=format:%D", revision)
 	if err != nil {
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestShowCommitInfo(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=tester", "-c", "user.email=tester@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		c.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2021-03-04T10:11:12+01:00")
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	logger := zaptest.NewLogger(t).Sugar()
+	sha, err := ShowCommit(logger, "HEAD", dir)
+	if err != nil {
+		t.Fatalf("ShowCommit: %v", err)
+	}
+
+	// Showing the full SHA must give the same information as showing the branch.
+	for _, revision := range []string{"HEAD", sha} {
+		commit, err := ShowCommitInfo(logger, revision, dir)
+		if err != nil {
+			t.Fatalf("ShowCommitInfo(%s): %v", revision, err)
+		}
+		if commit.SHA != sha {
+			t.Errorf("Expected SHA %s but got %s", sha, commit.SHA)
+		}
+		if !strings.HasPrefix(sha, commit.ShortSHA) || len(commit.ShortSHA) < 7 || len(commit.ShortSHA) >= len(sha) {
+			t.Errorf("Expected %s to be an abbreviation of %s", commit.ShortSHA, sha)
+		}
+		if want := "2021-03-04T10:11:12+01:00"; commit.CommitterDate != want {
+			t.Errorf("Expected committer date %s but got %s", want, commit.CommitterDate)
+		}
+	}
+}
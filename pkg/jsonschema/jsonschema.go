@@ -0,0 +1,254 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonschema implements the subset of JSON Schema needed to validate
+// the params of a TaskRun: the type, enum, string, array and object keywords.
+// Schemas using any other keyword are rejected when parsed rather than being
+// silently ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	// Annotations, accepted but not used for validation.
+	SchemaURI   string      `json:"$schema,omitempty"`
+	ID          string      `json:"$id,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+
+	Type  string        `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	Items       *Schema `json:"items,omitempty"`
+	MinItems    *int    `json:"minItems,omitempty"`
+	MaxItems    *int    `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Error describes a value that does not match its schema.
+type Error struct {
+	// Path is the location of the value, e.g. "flags[1]" for the second item
+	// of the array property "flags". It is empty for the root value.
+	Path string
+	// Message describes how the value fails the schema.
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Parse parses a JSON Schema document, returning an error if it is malformed
+// or uses keywords this package doesn't support.
+func Parse(data []byte) (*Schema, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	s := &Schema{}
+	if err := d.Decode(s); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	if err := s.compile(""); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Schema) compile(path string) error {
+	switch s.Type {
+	case "", "string", "array", "object", "boolean", "integer", "number", "null":
+	default:
+		return fmt.Errorf("%sunsupported type %q", prefix(path), s.Type)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%sinvalid pattern %q: %w", prefix(path), s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(join(path, "items")); err != nil {
+			return err
+		}
+	}
+	for name, p := range s.Properties {
+		if p == nil {
+			return fmt.Errorf("%sproperty %q has no schema", prefix(path), name)
+		}
+		if err := p.compile(join(path, "properties."+name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks value, as decoded by encoding/json, against the schema and
+// returns every mismatch found.
+func (s *Schema) Validate(value interface{}) []Error {
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) []Error {
+	if s.Type != "" && !hasType(value, s.Type) {
+		return []Error{{Path: path, Message: fmt.Sprintf("expected %s but got %s", s.Type, typeOf(value))}}
+	}
+
+	var errs []Error
+	if s.Enum != nil && !contains(s.Enum, value) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must be one of %s", encode(s.Enum))})
+	}
+	if s.Const != nil && !reflect.DeepEqual(s.Const, value) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must be %s", encode(s.Const))})
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must be at least %d characters long", *s.MinLength)})
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must be at most %d characters long", *s.MaxLength)})
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must match pattern %q", s.Pattern)})
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.MinItems)})
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+		}
+		if s.UniqueItems {
+			for i := range v {
+				if contains(v[:i], v[i]) {
+					errs = append(errs, Error{Path: path, Message: fmt.Sprintf("must have unique items, %s is repeated", encode(v[i]))})
+					break
+				}
+			}
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, Error{Path: join(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if p, ok := s.Properties[name]; ok {
+				errs = append(errs, p.validate(join(path, name), v[name])...)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				errs = append(errs, Error{Path: join(path, name), Message: "is not allowed"})
+			}
+		}
+	}
+	return errs
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	default:
+		return typeOf(value) == t
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func prefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, ".") + ": "
+}
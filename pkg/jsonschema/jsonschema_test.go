@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/jsonschema"
+)
+
+const schema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["environment"],
+  "additionalProperties": false,
+  "properties": {
+    "environment": {"type": "string", "enum": ["staging", "production"]},
+    "version": {"type": "string", "pattern": "^v[0-9]+\\.[0-9]+$", "maxLength": 8},
+    "flags": {"type": "array", "minItems": 1, "uniqueItems": true, "items": {"type": "string", "minLength": 3}}
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := jsonschema.Parse([]byte(schema))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	for _, tc := range []struct {
+		name  string
+		value string
+		want  []jsonschema.Error
+	}{{
+		name:  "valid",
+		value: `{"environment": "staging", "version": "v1.2", "flags": ["--debug"]}`,
+	}, {
+		name:  "missing required property",
+		value: `{"version": "v1.2"}`,
+		want:  []jsonschema.Error{{Path: "environment", Message: "is required"}},
+	}, {
+		name:  "not in enum",
+		value: `{"environment": "dev"}`,
+		want:  []jsonschema.Error{{Path: "environment", Message: `must be one of ["staging","production"]`}},
+	}, {
+		name:  "pattern and length",
+		value: `{"environment": "staging", "version": "version-1.2"}`,
+		want: []jsonschema.Error{
+			{Path: "version", Message: "must be at most 8 characters long"},
+			{Path: "version", Message: `must match pattern "^v[0-9]+\\.[0-9]+$"`},
+		},
+	}, {
+		name:  "array items",
+		value: `{"environment": "staging", "flags": ["-v", "--all", "--all"]}`,
+		want: []jsonschema.Error{
+			{Path: "flags", Message: `must have unique items, "--all" is repeated`},
+			{Path: "flags[0]", Message: "must be at least 3 characters long"},
+		},
+	}, {
+		name:  "empty array",
+		value: `{"environment": "staging", "flags": []}`,
+		want:  []jsonschema.Error{{Path: "flags", Message: "must have at least 1 items"}},
+	}, {
+		name:  "wrong type",
+		value: `{"environment": "staging", "flags": "--debug"}`,
+		want:  []jsonschema.Error{{Path: "flags", Message: "expected array but got string"}},
+	}, {
+		name:  "additional property",
+		value: `{"environment": "staging", "extra": "x"}`,
+		want:  []jsonschema.Error{{Path: "extra", Message: "is not allowed"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tc.value), &v); err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tc.want, s.Validate(v)); d != "" {
+				t.Errorf("Validate() diff %s", d)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		schema string
+	}{{
+		name:   "malformed",
+		schema: `{"type": `,
+	}, {
+		name:   "unsupported keyword",
+		schema: `{"type": "object", "oneOf": [{"type": "string"}]}`,
+	}, {
+		name:   "unsupported type",
+		schema: `{"type": "tuple"}`,
+	}, {
+		name:   "invalid nested pattern",
+		schema: `{"properties": {"name": {"pattern": "("}}}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := jsonschema.Parse([]byte(tc.schema)); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+}
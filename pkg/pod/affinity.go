@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/workspace"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// ReasonInjectedAffinityWithCustomScheduler indicates that the Pod of a TaskRun
+	// scheduled by a custom scheduler is given the affinity of an Affinity Assistant,
+	// which the scheduler may not handle.
+	ReasonInjectedAffinityWithCustomScheduler = "InjectedAffinityWithCustomScheduler"
+)
+
+// usesCustomScheduler returns true if podTemplate schedules the Pod with another
+// scheduler than the default one.
+func usesCustomScheduler(podTemplate v1beta1.PodTemplate) bool {
+	return podTemplate.SchedulerName != "" && podTemplate.SchedulerName != corev1.DefaultSchedulerName
+}
+
+// podAffinity returns the affinity of the Pod of taskRun. Using node affinity on
+// taskRuns sharing PVC workspace, with an Affinity Assistant is mutually exclusive
+// with other affinity on taskRun pods. If other affinity is wanted, that should be
+// added on the Affinity Assistant pod unless assistant is disabled. When Affinity
+// Assistant is disabled, an affinityAssistantName is not set.
+//
+// The affinity of the Affinity Assistant isn't injected either into a Pod scheduled
+// by a custom scheduler when "disable-affinity-with-custom-scheduler" is set, and a
+// warning is emitted when it is injected anyway.
+func podAffinity(ctx context.Context, taskRun *v1beta1.TaskRun, podTemplate v1beta1.PodTemplate) *corev1.Affinity {
+	affinityAssistantName := taskRun.Annotations[workspace.AnnotationAffinityAssistantName]
+	if affinityAssistantName == "" {
+		return podTemplate.Affinity
+	}
+	if usesCustomScheduler(podTemplate) {
+		if config.FromContextOrDefaults(ctx).FeatureFlags.DisableAffinityWithCustomScheduler {
+			return podTemplate.Affinity
+		}
+		if recorder := controller.GetEventRecorder(ctx); recorder != nil {
+			recorder.Eventf(taskRun, corev1.EventTypeWarning, ReasonInjectedAffinityWithCustomScheduler,
+				"The Pod is scheduled by %q with the affinity of the Affinity Assistant %s, set %q to stop injecting it",
+				podTemplate.SchedulerName, affinityAssistantName, "disable-affinity-with-custom-scheduler")
+		}
+	}
+	return nodeAffinityUsingAffinityAssistant(affinityAssistantName)
+}
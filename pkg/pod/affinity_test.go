@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/pkg/workspace"
+	"github.com/tektoncd/pipeline/test/diff"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestPodBuild_AffinityWithScheduler(t *testing.T) {
+	templateAffinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "pool",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"batch"},
+					}},
+				}},
+			},
+		},
+	}
+	for _, c := range []struct {
+		desc              string
+		schedulerName     string
+		affinityAssistant string
+		featureFlags      map[string]string
+		wantAffinity      *corev1.Affinity
+		wantWarning       bool
+	}{{
+		desc:          "custom scheduler without affinity assistant",
+		schedulerName: "volcano",
+		wantAffinity:  templateAffinity,
+	}, {
+		desc:              "default scheduler with affinity assistant",
+		schedulerName:     corev1.DefaultSchedulerName,
+		affinityAssistant: "affinity-assistant-abc",
+		featureFlags:      map[string]string{"disable-affinity-with-custom-scheduler": "true"},
+		wantAffinity:      nodeAffinityUsingAffinityAssistant("affinity-assistant-abc"),
+	}, {
+		desc:              "custom scheduler with affinity assistant",
+		schedulerName:     "volcano",
+		affinityAssistant: "affinity-assistant-abc",
+		wantAffinity:      nodeAffinityUsingAffinityAssistant("affinity-assistant-abc"),
+		wantWarning:       true,
+	}, {
+		desc:              "custom scheduler with affinity assistant and injection disabled",
+		schedulerName:     "volcano",
+		affinityAssistant: "affinity-assistant-abc",
+		featureFlags:      map[string]string{"disable-affinity-with-custom-scheduler": "true"},
+		wantAffinity:      templateAffinity,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			names.TestingSeed()
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data:       c.featureFlags,
+				},
+			)
+			recorder := record.NewFakeRecorder(10)
+			ctx := controller.WithEventRecorder(store.ToContext(context.Background()), recorder)
+			kubeclient := fakek8s.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+			)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "taskrun-name",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+				Spec: v1beta1.TaskRunSpec{
+					PodTemplate: &v1beta1.PodTemplate{
+						SchedulerName: c.schedulerName,
+						Affinity:      templateAffinity,
+					},
+				},
+			}
+			if c.affinityAssistant != "" {
+				tr.Annotations[workspace.AnnotationAffinityAssistantName] = c.affinityAssistant
+			}
+			ts := v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{Container: corev1.Container{
+					Name:    "name",
+					Image:   "image",
+					Command: []string{"cmd"}, // avoid entrypoint lookup.
+				}}},
+			}
+			builder := Builder{
+				Images:          images,
+				KubeClient:      kubeclient,
+				EntrypointCache: fakeCache{},
+			}
+			got, err := builder.Build(ctx, tr, ts)
+			if err != nil {
+				t.Fatalf("builder.Build: %v", err)
+			}
+
+			if got.Spec.SchedulerName != c.schedulerName {
+				t.Errorf("Expected the pod to be scheduled by %q but got %q", c.schedulerName, got.Spec.SchedulerName)
+			}
+			if d := cmp.Diff(c.wantAffinity, got.Spec.Affinity); d != "" {
+				t.Errorf("Unexpected affinity %s", diff.PrintWantGot(d))
+			}
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			gotWarning := len(events) == 1 && strings.HasPrefix(events[0], "Warning "+ReasonInjectedAffinityWithCustomScheduler)
+			if gotWarning != c.wantWarning || (!c.wantWarning && len(events) > 0) {
+				t.Errorf("Expected a warning about the injected affinity: %t, got events %v", c.wantWarning, events)
+			}
+		})
+	}
+}
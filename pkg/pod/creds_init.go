@@ -17,9 +17,13 @@ limitations under the License.
 package pod
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/credentials"
 	"github.com/tektoncd/pipeline/pkg/credentials/dockercreds"
 	"github.com/tektoncd/pipeline/pkg/credentials/gitcreds"
@@ -27,9 +31,20 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/controller"
 )
 
-const credsInitHomeMountPrefix = "tekton-creds-init-home"
+const (
+	credsInitHomeMountPrefix = "tekton-creds-init-home"
+
+	// DisableCredsInitAnnotation is the TaskRun annotation disabling the initialization
+	// of the credentials of its service account when set to "true".
+	DisableCredsInitAnnotation = "tekton.dev/disable-creds-init"
+
+	// ReasonCredsInitDisabled is the reason of the warning event emitted when a TaskRun
+	// disables the initialization of the credentials its service account has.
+	ReasonCredsInitDisabled = "CredsInitDisabled"
+)
 
 // credsInit reads secrets available to the given service account and
 // searches for annotations matching a specific format (documented in
@@ -43,24 +58,56 @@ const credsInitHomeMountPrefix = "tekton-creds-init-home"
 // caller. If no matching annotated secrets are found, nil lists with a
 // nil error are returned.
 func credsInit(serviceAccountName, namespace string, kubeclient kubernetes.Interface) ([]string, []corev1.Volume, []corev1.VolumeMount, error) {
+	secrets, args, err := annotatedSecrets(serviceAccountName, namespace, kubeclient)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(args) == 0 {
+		// There are no creds to initialize.
+		return nil, nil, nil, nil
+	}
+
+	var volumeMounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+	for _, secret := range secrets {
+		name := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("tekton-internal-secret-volume-%s", secret))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: credentials.VolumeName(secret),
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secret,
+				},
+			},
+		})
+	}
+	return args, volumes, volumeMounts, nil
+}
+
+// annotatedSecrets returns the names of the secrets of the given service account
+// with annotations matching a credentials builder, along with the entrypointer
+// arguments initializing them.
+func annotatedSecrets(serviceAccountName, namespace string, kubeclient kubernetes.Interface) ([]string, []string, error) {
 	if serviceAccountName == "" {
 		serviceAccountName = "default"
 	}
 
 	sa, err := kubeclient.CoreV1().ServiceAccounts(namespace).Get(serviceAccountName, metav1.GetOptions{})
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	builders := []credentials.Builder{dockercreds.NewBuilder(), gitcreds.NewBuilder()}
 
-	var volumeMounts []corev1.VolumeMount
-	var volumes []corev1.Volume
+	var secrets []string
 	args := []string{}
 	for _, secretEntry := range sa.Secrets {
 		secret, err := kubeclient.CoreV1().Secrets(namespace).Get(secretEntry.Name, metav1.GetOptions{})
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 
 		matched := false
@@ -72,28 +119,41 @@ func credsInit(serviceAccountName, namespace string, kubeclient kubernetes.Inter
 		}
 
 		if matched {
-			name := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("tekton-internal-secret-volume-%s", secret.Name))
-			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      name,
-				MountPath: credentials.VolumeName(secret.Name),
-			})
-			volumes = append(volumes, corev1.Volume{
-				Name: name,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: secret.Name,
-					},
-				},
-			})
+			secrets = append(secrets, secret.Name)
 		}
 	}
+	return secrets, args, nil
+}
 
-	if len(args) == 0 {
-		// There are no creds to initialize.
-		return nil, nil, nil, nil
+// credsInitDisabled returns whether the "disable-creds-init" feature flag or the
+// DisableCredsInitAnnotation of taskRun disable the initialization of credentials.
+// perRun is true only if the annotation alone disables it.
+func credsInitDisabled(ctx context.Context, taskRun *v1beta1.TaskRun) (disabled bool, perRun bool) {
+	if config.FromContextOrDefaults(ctx).FeatureFlags.DisableCredsInit {
+		return true, false
+	}
+	if taskRun.Annotations[DisableCredsInitAnnotation] == "true" {
+		return true, true
 	}
+	return false, false
+}
 
-	return args, volumes, volumeMounts, nil
+// warnIgnoredCredentials emits a warning event for taskRun if its service account has
+// secrets annotated for Tekton, whose credentials aren't initialized.
+func warnIgnoredCredentials(ctx context.Context, taskRun *v1beta1.TaskRun, kubeclient kubernetes.Interface) error {
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil
+	}
+	secrets, _, err := annotatedSecrets(taskRun.Spec.ServiceAccountName, taskRun.Namespace, kubeclient)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		recorder.Eventf(taskRun, corev1.EventTypeWarning, ReasonCredsInitDisabled,
+			"Credentials of secrets %s are not initialized because of the %q annotation", strings.Join(secrets, ", "), DisableCredsInitAnnotation)
+	}
+	return nil
 }
 
 // getCredsInitVolume returns a Volume and VolumeMount for /tekton/creds. Each call
@@ -17,15 +17,20 @@ limitations under the License.
 package pod
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
 )
 
 const (
@@ -169,3 +174,124 @@ func TestCredsInit(t *testing.T) {
 		})
 	}
 }
+
+func TestCredsInitDisabled(t *testing.T) {
+	for _, c := range []struct {
+		desc            string
+		featureFlag     bool
+		annotations     map[string]string
+		wantDisabled    bool
+		wantDisabledRun bool
+	}{{
+		desc: "enabled by default",
+	}, {
+		desc:         "disabled by feature flag",
+		featureFlag:  true,
+		wantDisabled: true,
+	}, {
+		desc:            "disabled by annotation",
+		annotations:     map[string]string{DisableCredsInitAnnotation: "true"},
+		wantDisabled:    true,
+		wantDisabledRun: true,
+	}, {
+		desc:        "enabled by annotation",
+		annotations: map[string]string{DisableCredsInitAnnotation: "false"},
+	}, {
+		desc:         "disabled by feature flag and annotation",
+		featureFlag:  true,
+		annotations:  map[string]string{DisableCredsInitAnnotation: "true"},
+		wantDisabled: true,
+	}, {
+		desc:         "disabled by feature flag but not by annotation",
+		featureFlag:  true,
+		annotations:  map[string]string{DisableCredsInitAnnotation: "false"},
+		wantDisabled: true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.DisableCredsInit = c.featureFlag
+			ctx := config.ToContext(context.Background(), cfg)
+			tr := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Annotations: c.annotations}}
+
+			disabled, disabledRun := credsInitDisabled(ctx, tr)
+			if disabled != c.wantDisabled || disabledRun != c.wantDisabledRun {
+				t.Errorf("credsInitDisabled() = (%t, %t), want (%t, %t)", disabled, disabledRun, c.wantDisabled, c.wantDisabledRun)
+			}
+		})
+	}
+}
+
+func TestWarnIgnoredCredentials(t *testing.T) {
+	for _, c := range []struct {
+		desc       string
+		objs       []runtime.Object
+		wantEvents []string
+	}{{
+		desc: "service account has no annotated secrets",
+		objs: []runtime.Object{
+			&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+				Secrets: []corev1.ObjectReference{{
+					Name: "my-creds",
+				}},
+			},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-creds", Namespace: namespace}},
+		},
+	}, {
+		desc: "service account has annotated secrets",
+		objs: []runtime.Object{
+			&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+				Secrets: []corev1.ObjectReference{{
+					Name: "my-creds",
+				}, {
+					Name: "my-other-creds",
+				}, {
+					Name: "not-creds",
+				}},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-creds",
+					Namespace:   namespace,
+					Annotations: map[string]string{"tekton.dev/git-0": "github.com"},
+				},
+				Type: "kubernetes.io/basic-auth",
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-other-creds",
+					Namespace:   namespace,
+					Annotations: map[string]string{"tekton.dev/docker-0": "https://us.gcr.io"},
+				},
+				Type: "kubernetes.io/basic-auth",
+			},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "not-creds", Namespace: namespace}},
+		},
+		wantEvents: []string{
+			`Warning CredsInitDisabled Credentials of secrets my-creds, my-other-creds are not initialized because of the "tekton.dev/disable-creds-init" annotation`,
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			kubeclient := fakek8s.NewSimpleClientset(c.objs...)
+			recorder := record.NewFakeRecorder(10)
+			ctx := controller.WithEventRecorder(context.Background(), recorder)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "taskrun", Namespace: namespace},
+				Spec:       v1beta1.TaskRunSpec{ServiceAccountName: serviceAccountName},
+			}
+
+			if err := warnIgnoredCredentials(ctx, tr, kubeclient); err != nil {
+				t.Fatalf("warnIgnoredCredentials: %v", err)
+			}
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if d := cmp.Diff(c.wantEvents, events); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// SkipDigestPinningAnnotation is the TaskRun (or Task) annotation disabling the pinning
+	// of the images of its steps to their digest when set to "true". The pinning of a single
+	// step is disabled by suffixing it with "." and the name of the step.
+	SkipDigestPinningAnnotation = "tekton.dev/skip-digest-pinning"
+
+	// DigestCacheConfigMapName is the name of the ConfigMap caching the digests the images
+	// of steps were pinned to.
+	DigestCacheConfigMapName = "image-digest-cache"
+
+	// digestCacheTTL is how long a digest is used for an image before it is resolved again.
+	digestCacheTTL = 5 * time.Minute
+)
+
+// DigestCache caches the digests that image references were resolved to.
+type DigestCache interface {
+	// Get returns the digest reference image was resolved to, if it is cached.
+	Get(image string) (string, bool)
+	// Set caches the digest reference image was resolved to.
+	Set(image, digest string) error
+}
+
+// shouldPinImageDigests returns a bool indicating whether the images of steps
+// should be pinned to their digest, based on the "enable-image-digest-pinning"
+// feature flag.
+func shouldPinImageDigests(ctx context.Context) bool {
+	return config.FromContextOrDefaults(ctx).FeatureFlags.EnableImageDigestPinning
+}
+
+// pinImageDigests replaces the image of each step that isn't specified by digest with
+// the digest its tag currently points to, unless taskRun opts out of it.
+func pinImageDigests(ctx context.Context, cache EntrypointCache, digests DigestCache, taskRun *v1beta1.TaskRun, steps []corev1.Container) ([]corev1.Container, error) {
+	logger := logging.FromContext(ctx)
+	if taskRun.Annotations[SkipDigestPinningAnnotation] == "true" {
+		return steps, nil
+	}
+	for i, s := range steps {
+		if taskRun.Annotations[SkipDigestPinningAnnotation+"."+s.Name] == "true" {
+			continue
+		}
+		ref, err := name.ParseReference(s.Image, name.WeakValidation)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ref.(name.Digest); ok {
+			// Already pinned.
+			continue
+		}
+		if digests != nil {
+			if digest, found := digests.Get(ref.Name()); found {
+				steps[i].Image = digest
+				continue
+			}
+		}
+
+		img, err := cache.Get(ref, taskRun.Namespace, taskRun.Spec.ServiceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		d, err := img.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("error getting image digest: %v", err)
+		}
+		digest := ref.Context().String() + "@" + d.String()
+		if digests != nil {
+			if err := digests.Set(ref.Name(), digest); err != nil {
+				logger.Warnf("Failed to cache digest %s of image %s: %v", digest, ref.Name(), err)
+			}
+		}
+		steps[i].Image = digest
+	}
+	return steps, nil
+}
+
+// NewDigestCache returns a DigestCache storing digests in the DigestCacheConfigMapName
+// ConfigMap of namespace for 5 minutes.
+func NewDigestCache(kubeclient kubernetes.Interface, namespace string) DigestCache {
+	return &configMapDigestCache{
+		kubeclient: kubeclient,
+		namespace:  namespace,
+		now:        time.Now,
+	}
+}
+
+type configMapDigestCache struct {
+	kubeclient kubernetes.Interface
+	namespace  string
+	now        func() time.Time
+}
+
+// cachedDigest is the value of an entry of the ConfigMap, keyed by the hash of Image.
+type cachedDigest struct {
+	Image    string      `json:"image"`
+	Digest   string      `json:"digest"`
+	Resolved metav1.Time `json:"resolved"`
+}
+
+func digestCacheKey(image string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(image)))
+}
+
+func (c *configMapDigestCache) expired(value string) (cachedDigest, bool) {
+	var cd cachedDigest
+	if err := json.Unmarshal([]byte(value), &cd); err != nil {
+		return cd, true
+	}
+	return cd, c.now().Sub(cd.Resolved.Time) > digestCacheTTL
+}
+
+func (c *configMapDigestCache) Get(image string) (string, bool) {
+	cm, err := c.kubeclient.CoreV1().ConfigMaps(c.namespace).Get(DigestCacheConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return "", false
+	}
+	value, ok := cm.Data[digestCacheKey(image)]
+	if !ok {
+		return "", false
+	}
+	cd, expired := c.expired(value)
+	if expired || cd.Image != image {
+		return "", false
+	}
+	return cd.Digest, true
+}
+
+func (c *configMapDigestCache) Set(image, digest string) error {
+	configMaps := c.kubeclient.CoreV1().ConfigMaps(c.namespace)
+	cm, err := configMaps.Get(DigestCacheConfigMapName, metav1.GetOptions{})
+	create := apierrors.IsNotFound(err)
+	switch {
+	case create:
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: DigestCacheConfigMapName, Namespace: c.namespace}}
+	case err != nil:
+		return err
+	}
+
+	// Drop the expired entries while adding the new one, so the ConfigMap doesn't grow forever.
+	data := map[string]string{}
+	for k, v := range cm.Data {
+		if _, expired := c.expired(v); !expired {
+			data[k] = v
+		}
+	}
+	value, err := json.Marshal(cachedDigest{Image: image, Digest: digest, Resolved: metav1.NewTime(c.now())})
+	if err != nil {
+		return err
+	}
+	data[digestCacheKey(image)] = string(value)
+	cm.Data = data
+
+	if create {
+		_, err = configMaps.Create(cm)
+	} else {
+		_, err = configMaps.Update(cm)
+	}
+	return err
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeDigests is a DigestCache backed by a map.
+type fakeDigests map[string]string
+
+func (f fakeDigests) Get(image string) (string, bool) {
+	d, ok := f[image]
+	return d, ok
+}
+
+func (f fakeDigests) Set(image, digest string) error {
+	f[image] = digest
+	return nil
+}
+
+func TestPinImageDigests(t *testing.T) {
+	img, err := random.Image(1, 1)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	dig, err := img.Digest()
+	if err != nil {
+		t.Fatalf("image.Digest: %v", err)
+	}
+	pinned := "gcr.io/my/image@" + dig.String()
+
+	steps := func() []corev1.Container {
+		return []corev1.Container{{
+			Name:    "tagged",
+			Image:   "gcr.io/my/image:latest",
+			Command: []string{"my", "command"},
+		}, {
+			Name:    "pinned",
+			Image:   "gcr.io/other/image@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			Command: []string{"my", "command"},
+		}, {
+			Name:    "untagged",
+			Image:   "gcr.io/my/image",
+			Command: []string{"my", "command"},
+		}}
+	}
+
+	for _, c := range []struct {
+		desc        string
+		annotations map[string]string
+		want        []string
+	}{{
+		desc: "pinned",
+		want: []string{pinned, "gcr.io/other/image@sha256:0000000000000000000000000000000000000000000000000000000000000000", pinned},
+	}, {
+		desc:        "skipped for the TaskRun",
+		annotations: map[string]string{SkipDigestPinningAnnotation: "true"},
+		want:        []string{"gcr.io/my/image:latest", "gcr.io/other/image@sha256:0000000000000000000000000000000000000000000000000000000000000000", "gcr.io/my/image"},
+	}, {
+		desc:        "skipped for a step",
+		annotations: map[string]string{SkipDigestPinningAnnotation + ".untagged": "true"},
+		want:        []string{pinned, "gcr.io/other/image@sha256:0000000000000000000000000000000000000000000000000000000000000000", "gcr.io/my/image"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			cache := fakeCache{"gcr.io/my/image:latest": &data{img: img}}
+			digests := fakeDigests{}
+			tr := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Annotations: c.annotations}}
+
+			got, err := pinImageDigests(context.Background(), cache, digests, tr, steps())
+			if err != nil {
+				t.Fatalf("pinImageDigests: %v", err)
+			}
+			var images []string
+			for _, s := range got {
+				images = append(images, s.Image)
+			}
+			if d := cmp.Diff(c.want, images); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPinImageDigests_Cached(t *testing.T) {
+	// The registry doesn't know about the image, so it must be pinned from the cache.
+	digests := fakeDigests{"gcr.io/my/image:latest": "gcr.io/my/image@sha256:1111111111111111111111111111111111111111111111111111111111111111"}
+	tr := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace"}}
+
+	got, err := pinImageDigests(context.Background(), fakeCache{}, digests, tr, []corev1.Container{{
+		Name:  "step",
+		Image: "gcr.io/my/image",
+	}})
+	if err != nil {
+		t.Fatalf("pinImageDigests: %v", err)
+	}
+	if want := digests["gcr.io/my/image:latest"]; got[0].Image != want {
+		t.Errorf("Image = %q, want %q", got[0].Image, want)
+	}
+}
+
+func TestConfigMapDigestCache(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	kubeclient := fakek8s.NewSimpleClientset()
+	cache := &configMapDigestCache{
+		kubeclient: kubeclient,
+		namespace:  "tekton-pipelines",
+		now:        func() time.Time { return now },
+	}
+
+	if _, found := cache.Get("gcr.io/my/image:latest"); found {
+		t.Fatal("Expected digest not to be cached without a ConfigMap")
+	}
+	if err := cache.Set("gcr.io/my/image:latest", "gcr.io/my/image@sha256:abc"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := cache.Set("gcr.io/other/image:latest", "gcr.io/other/image@sha256:def"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, found := cache.Get("gcr.io/my/image:latest"); !found || got != "gcr.io/my/image@sha256:abc" {
+		t.Errorf("Get() = %q, %t, want %q, true", got, found, "gcr.io/my/image@sha256:abc")
+	}
+
+	// Once the TTL passed, digests are resolved again and dropped from the ConfigMap.
+	now = now.Add(digestCacheTTL + time.Second)
+	if _, found := cache.Get("gcr.io/my/image:latest"); found {
+		t.Error("Expected expired digest not to be returned")
+	}
+	if err := cache.Set("gcr.io/other/image:latest", "gcr.io/other/image@sha256:fed"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	cm, err := kubeclient.CoreV1().ConfigMaps("tekton-pipelines").Get(DigestCacheConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get ConfigMap: %v", err)
+	}
+	if len(cm.Data) != 1 {
+		t.Errorf("Expected expired entries to be pruned, got %v", cm.Data)
+	}
+	if got, found := cache.Get("gcr.io/other/image:latest"); !found || got != "gcr.io/other/image@sha256:fed" {
+		t.Errorf("Get() = %q, %t, want %q, true", got, found, "gcr.io/other/image@sha256:fed")
+	}
+}
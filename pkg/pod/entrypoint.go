@@ -17,11 +17,14 @@ limitations under the License.
 package pod
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -143,11 +146,93 @@ func orderContainers(entrypointImage string, extraEntrypointArgs []string, steps
 	return initContainer, steps, nil
 }
 
+// isolateHermeticSteps makes the entrypoint binary run the hermetic steps
+// without network. It must be called after orderContainers.
+func isolateHermeticSteps(steps []v1beta1.Step, stepContainers []corev1.Container) {
+	for i, s := range steps {
+		if s.Hermetic {
+			stepContainers[i].Args = append([]string{"-hermetic"}, stepContainers[i].Args...)
+		}
+	}
+}
+
+// distributeTimeout makes the entrypoint binary stop each step once it has run
+// for its share of the timeout of the TaskRun, plus the time left unused by the
+// previous steps, when the TaskRun distributes its timeout among its steps. It
+// must be called after orderContainers.
+func distributeTimeout(taskRun *v1beta1.TaskRun, stepContainers []corev1.Container) {
+	timeout := taskRun.GetTimeout()
+	if !taskRun.Spec.DistributeTimeout || timeout == config.NoTimeoutDuration || len(stepContainers) == 0 {
+		return
+	}
+	share := timeout / time.Duration(len(stepContainers))
+	for i := range stepContainers {
+		stepContainers[i].Args = append([]string{"-timeout", share.String()}, stepContainers[i].Args...)
+	}
+}
+
+// wrapSidecarsWithRestart returns the specified sidecars, modified so that the
+// ones declaring restartPolicy OnFailure are run by the entrypoint binary and
+// restarted when they exit with a non-zero exit code. All containers in the
+// Pod share its restartPolicy, which is always Never.
+//
+// Sidecars to restart must have Command specified; if the user didn't specify
+// a command, we must have fetched the image's ENTRYPOINT before calling this
+// method, using entrypoint_lookup.go.
+func wrapSidecarsWithRestart(sidecars []v1beta1.Sidecar, sidecarContainers []corev1.Container) ([]corev1.Container, error) {
+	for i, s := range sidecarContainers {
+		if sidecars[i].RestartPolicy != corev1.RestartPolicyOnFailure {
+			continue
+		}
+		cmd, args := s.Command, s.Args
+		if len(cmd) == 0 {
+			return nil, fmt.Errorf("Sidecar %d did not specify command", i)
+		}
+		if len(cmd) > 1 {
+			args = append(cmd[1:], args...)
+			cmd = []string{cmd[0]}
+		}
+		argsForEntrypoint := []string{"-restart_on_failure", "-entrypoint", cmd[0], "--"}
+		argsForEntrypoint = append(argsForEntrypoint, args...)
+
+		sidecarContainers[i].Command = []string{entrypointBinary}
+		sidecarContainers[i].Args = argsForEntrypoint
+		sidecarContainers[i].VolumeMounts = append(sidecarContainers[i].VolumeMounts, toolsMount)
+	}
+	return sidecarContainers, nil
+}
+
+// hasMixedSidecarRestartPolicies returns true if some sidecars are restarted on
+// failure while others are not.
+func hasMixedSidecarRestartPolicies(sidecars []v1beta1.Sidecar) bool {
+	var onFailure, always bool
+	for _, s := range sidecars {
+		if s.RestartPolicy == corev1.RestartPolicyOnFailure {
+			onFailure = true
+		} else {
+			always = true
+		}
+	}
+	return onFailure && always
+}
+
 func resultArgument(steps []corev1.Container, results []v1beta1.TaskResult) []string {
 	if len(results) == 0 {
 		return nil
 	}
-	return []string{"-results", collectResultsName(results)}
+	args := []string{"-results", collectResultsName(results)}
+	jsonPaths := map[string]string{}
+	for _, r := range results {
+		if r.JSONPath != "" {
+			jsonPaths[r.Name] = r.JSONPath
+		}
+	}
+	if len(jsonPaths) != 0 {
+		// Keys of the marshalled map are sorted, so the arguments are stable.
+		b, _ := json.Marshal(jsonPaths)
+		args = append(args, "-result_json_paths", string(b))
+	}
+	return args
 }
 
 func collectResultsName(results []v1beta1.TaskResult) string {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -217,6 +218,141 @@ func TestEntryPointResultsSingleStep(t *testing.T) {
 		t.Errorf("Diff %s", diff.PrintWantGot(d))
 	}
 }
+func TestEntryPointResultsJSONPath(t *testing.T) {
+	results := []v1beta1.TaskResult{{
+		Name:     "digest",
+		JSONPath: "{.image.digest}",
+	}, {
+		Name: "url",
+	}, {
+		Name:     "count",
+		JSONPath: "{.count}",
+	}}
+
+	steps := []corev1.Container{{
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-results", "digest,url,count",
+			"-result_json_paths", `{"count":"{.count}","digest":"{.image.digest}"}`,
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, results)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestIsolateHermeticSteps(t *testing.T) {
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Image: "step-1", Command: []string{"cmd"}},
+		Hermetic:  true,
+	}, {
+		Container: corev1.Container{Image: "step-2", Command: []string{"cmd"}},
+	}}
+	stepContainers := []corev1.Container{steps[0].Container, steps[1].Container}
+	want := []corev1.Container{{
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-hermetic",
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Image:   "step-2",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/tools/0",
+			"-post_file", "/tekton/tools/1",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, stepContainers, nil)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	isolateHermeticSteps(steps, got)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestDistributeTimeout(t *testing.T) {
+	for _, c := range []struct {
+		desc     string
+		spec     v1beta1.TaskRunSpec
+		wantArgs []string
+	}{{
+		desc: "timeout distributed among the steps",
+		spec: v1beta1.TaskRunSpec{
+			Timeout:           &metav1.Duration{Duration: time.Hour},
+			DistributeTimeout: true,
+		},
+		wantArgs: []string{"-timeout", "20m0s"},
+	}, {
+		desc: "default timeout distributed among the steps",
+		spec: v1beta1.TaskRunSpec{
+			DistributeTimeout: true,
+		},
+		wantArgs: []string{"-timeout", "20m0s"},
+	}, {
+		desc: "timeout not distributed",
+		spec: v1beta1.TaskRunSpec{
+			Timeout: &metav1.Duration{Duration: time.Hour},
+		},
+	}, {
+		desc: "no timeout",
+		spec: v1beta1.TaskRunSpec{
+			Timeout:           &metav1.Duration{Duration: 0},
+			DistributeTimeout: true,
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			stepContainers := []corev1.Container{
+				{Image: "step-1", Command: []string{"cmd"}},
+				{Image: "step-2", Command: []string{"cmd"}},
+				{Image: "step-3", Command: []string{"cmd"}},
+			}
+			_, want, err := orderContainers(images.EntrypointImage, []string{}, stepContainers, nil)
+			if err != nil {
+				t.Fatalf("orderContainers: %v", err)
+			}
+			got := make([]corev1.Container, len(want))
+			for i := range want {
+				want[i].DeepCopyInto(&got[i])
+				want[i].Args = append(c.wantArgs, want[i].Args...)
+			}
+			distributeTimeout(&v1beta1.TaskRun{Spec: c.spec}, got)
+			if d := cmp.Diff(want, got); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestEntryPointSingleResultsSingleStep(t *testing.T) {
 	results := []v1alpha1.TaskResult{{
 		Name:        "sum",
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"knative.dev/pkg/logging"
+)
+
+const externalSecretsGroup = "external-secrets.io"
+
+// ErrExternalSecretsNotInstalled is returned by an ExternalSecretResolver when the
+// External Secrets Operator isn't installed in the cluster.
+var ErrExternalSecretsNotInstalled = errors.New("the External Secrets Operator is not installed")
+
+// ExternalSecretResolver resolves the External Secrets Operator ExternalSecrets
+// referenced by Steps to the Secrets they are synced to.
+type ExternalSecretResolver interface {
+	// SecretName returns the name of the Secret backing the ExternalSecret called
+	// name in namespace.
+	SecretName(namespace, name string) (string, error)
+}
+
+// NewExternalSecretResolver returns an ExternalSecretResolver reading ExternalSecrets
+// through the REST client of d.
+func NewExternalSecretResolver(d discovery.DiscoveryInterface) ExternalSecretResolver {
+	return &externalSecretResolver{discovery: d}
+}
+
+type externalSecretResolver struct {
+	discovery discovery.DiscoveryInterface
+}
+
+// externalSecret holds the fields of an ExternalSecret naming its Secret.
+type externalSecret struct {
+	Spec struct {
+		Target struct {
+			Name string `json:"name"`
+		} `json:"target"`
+	} `json:"spec"`
+	Status struct {
+		Binding struct {
+			Name string `json:"name"`
+		} `json:"binding"`
+	} `json:"status"`
+}
+
+// SecretName returns the Secret bound in the status of the ExternalSecret. If the
+// ExternalSecret wasn't synced yet, the Secret it targets is returned instead.
+func (r *externalSecretResolver) SecretName(namespace, name string) (string, error) {
+	groups, err := r.discovery.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	var version string
+	for _, g := range groups.Groups {
+		if g.Name == externalSecretsGroup {
+			version = g.PreferredVersion.Version
+		}
+	}
+	if version == "" {
+		return "", ErrExternalSecretsNotInstalled
+	}
+
+	body, err := r.discovery.RESTClient().Get().
+		AbsPath("/apis", externalSecretsGroup, version, "namespaces", namespace, "externalsecrets", name).
+		DoRaw()
+	if err != nil {
+		return "", err
+	}
+	var es externalSecret
+	if err := json.Unmarshal(body, &es); err != nil {
+		return "", fmt.Errorf("failed to decode ExternalSecret %q: %w", name, err)
+	}
+	switch {
+	case es.Status.Binding.Name != "":
+		return es.Status.Binding.Name, nil
+	case es.Spec.Target.Name != "":
+		return es.Spec.Target.Name, nil
+	default:
+		return name, nil
+	}
+}
+
+// resolveExternalSecrets returns a copy of steps where the ExternalSecrets of each
+// Step are rewritten to a secretRef in its envFrom. If the External Secrets Operator
+// isn't installed, the name of each ExternalSecret is used as the name of its Secret.
+func resolveExternalSecrets(ctx context.Context, r ExternalSecretResolver, namespace string, steps []v1beta1.Step) ([]v1beta1.Step, error) {
+	logger := logging.FromContext(ctx)
+	resolved := make([]v1beta1.Step, len(steps))
+	for i, s := range steps {
+		if len(s.EnvFromExternalSecrets) == 0 {
+			resolved[i] = s
+			continue
+		}
+		s.EnvFrom = append([]corev1.EnvFromSource{}, s.EnvFrom...)
+		for _, es := range s.EnvFromExternalSecrets {
+			secretName, err := externalSecretName(r, namespace, es.Name)
+			switch {
+			case errors.Is(err, ErrExternalSecretsNotInstalled):
+				logger.Warnf("Using ExternalSecret %q of step %q as a Secret: %v", es.Name, s.Name, err)
+				secretName = es.Name
+			case apierrors.IsNotFound(err) && es.Optional != nil && *es.Optional:
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("failed to resolve ExternalSecret %q of step %q: %w", es.Name, s.Name, err)
+			}
+			s.EnvFrom = append(s.EnvFrom, corev1.EnvFromSource{
+				Prefix: es.Prefix,
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Optional:             es.Optional,
+				},
+			})
+		}
+		s.EnvFromExternalSecrets = nil
+		resolved[i] = s
+	}
+	return resolved, nil
+}
+
+func externalSecretName(r ExternalSecretResolver, namespace, name string) (string, error) {
+	if r == nil {
+		return "", ErrExternalSecretsNotInstalled
+	}
+	return r.SecretName(namespace, name)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// fakeExternalSecrets maps the name of ExternalSecrets to the name of their Secret.
+type fakeExternalSecrets map[string]string
+
+func (f fakeExternalSecrets) SecretName(_, name string) (string, error) {
+	if secret, ok := f[name]; ok {
+		return secret, nil
+	}
+	return "", apierrors.NewNotFound(schema.GroupResource{Group: externalSecretsGroup, Resource: "externalsecrets"}, name)
+}
+
+// newExternalSecretsServer returns an API server serving the given ExternalSecrets of
+// namespace, and the external-secrets.io API group if installed.
+func newExternalSecretsServer(t *testing.T, installed bool, namespace string, externalSecrets map[string]string) *httptest.Server {
+	t.Helper()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Errorf("Failed to encode response: %v", err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, metav1.APIVersions{Versions: []string{"v1"}})
+	})
+	mux.HandleFunc("/apis", func(w http.ResponseWriter, r *http.Request) {
+		groups := metav1.APIGroupList{}
+		if installed {
+			version := metav1.GroupVersionForDiscovery{GroupVersion: "external-secrets.io/v1beta1", Version: "v1beta1"}
+			groups.Groups = append(groups.Groups, metav1.APIGroup{
+				Name:             externalSecretsGroup,
+				Versions:         []metav1.GroupVersionForDiscovery{version},
+				PreferredVersion: version,
+			})
+		}
+		writeJSON(w, groups)
+	})
+	for name, body := range externalSecrets {
+		body := body
+		mux.HandleFunc("/apis/external-secrets.io/v1beta1/namespaces/"+namespace+"/externalsecrets/"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestExternalSecretResolver(t *testing.T) {
+	externalSecrets := map[string]string{
+		"synced":   `{"spec": {"target": {"name": "target"}}, "status": {"binding": {"name": "bound"}}}`,
+		"unsynced": `{"spec": {"target": {"name": "target"}}}`,
+		"default":  `{"spec": {}}`,
+	}
+	for _, c := range []struct {
+		desc string
+		name string
+		want string
+	}{{
+		desc: "secret bound in status",
+		name: "synced",
+		want: "bound",
+	}, {
+		desc: "target secret of unsynced ExternalSecret",
+		name: "unsynced",
+		want: "target",
+	}, {
+		desc: "default target secret",
+		name: "default",
+		want: "default",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			server := newExternalSecretsServer(t, true, namespace, externalSecrets)
+			defer server.Close()
+			r := NewExternalSecretResolver(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}))
+
+			got, err := r.SecretName(namespace, c.name)
+			if err != nil {
+				t.Fatalf("SecretName: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("SecretName() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestExternalSecretResolver_Errors(t *testing.T) {
+	t.Run("not installed", func(t *testing.T) {
+		server := newExternalSecretsServer(t, false, namespace, nil)
+		defer server.Close()
+		r := NewExternalSecretResolver(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}))
+
+		if _, err := r.SecretName(namespace, "missing"); !errors.Is(err, ErrExternalSecretsNotInstalled) {
+			t.Errorf("SecretName() = %v, want %v", err, ErrExternalSecretsNotInstalled)
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		server := newExternalSecretsServer(t, true, namespace, nil)
+		defer server.Close()
+		r := NewExternalSecretResolver(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}))
+
+		if _, err := r.SecretName(namespace, "missing"); !apierrors.IsNotFound(err) {
+			t.Errorf("SecretName() = %v, want a NotFound error", err)
+		}
+	})
+}
+
+func TestResolveExternalSecrets(t *testing.T) {
+	optional := true
+	steps := []v1beta1.Step{{Container: corev1.Container{
+		Name: "without-external-secrets",
+	}}, {
+		Container: corev1.Container{
+			Name: "with-external-secrets",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+			}},
+		},
+		EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{
+			Name:   "credentials",
+			Prefix: "CREDS_",
+		}, {
+			Name:     "missing",
+			Optional: &optional,
+		}},
+	}}
+
+	for _, c := range []struct {
+		desc     string
+		resolver ExternalSecretResolver
+		want     []corev1.EnvFromSource
+	}{{
+		desc:     "resolved to the synced secrets",
+		resolver: fakeExternalSecrets{"credentials": "synced-credentials"},
+		want: []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+		}, {
+			Prefix:    "CREDS_",
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "synced-credentials"}},
+		}},
+	}, {
+		desc:     "passed through without the External Secrets Operator",
+		resolver: nil,
+		want: []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+		}, {
+			Prefix:    "CREDS_",
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}},
+		}, {
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Optional: &optional},
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := resolveExternalSecrets(context.Background(), c.resolver, namespace, steps)
+			if err != nil {
+				t.Fatalf("resolveExternalSecrets: %v", err)
+			}
+			if d := cmp.Diff(steps[0], got[0]); d != "" {
+				t.Errorf("Step without ExternalSecrets changed %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.want, got[1].EnvFrom); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+			if got[1].EnvFromExternalSecrets != nil {
+				t.Errorf("Expected ExternalSecrets to be rewritten, got %v", got[1].EnvFromExternalSecrets)
+			}
+			if len(steps[1].EnvFrom) != 1 || len(steps[1].EnvFromExternalSecrets) != 2 {
+				t.Errorf("Expected steps to be left unmodified, got %v", steps[1])
+			}
+		})
+	}
+}
+
+func TestResolveExternalSecrets_Missing(t *testing.T) {
+	steps := []v1beta1.Step{{
+		Container:              corev1.Container{Name: "step"},
+		EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{Name: "missing"}},
+	}}
+	if _, err := resolveExternalSecrets(context.Background(), fakeExternalSecrets{}, namespace, steps); err == nil {
+		t.Error("Expected an error resolving a missing ExternalSecret but got none")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	resultsVolumeName = "tekton-internal-results"
+	// forEachResultsSubPath is the directory of the results volume under which
+	// each iteration of a step with a forEach writes its results, in
+	// <step name>/<index>, so that they don't overwrite each other.
+	forEachResultsSubPath = "for-each"
+)
+
+// expandForEachSteps replaces each step with a forEach by one step per element
+// of its array parameter, named <step name>-<index>, in which $(forEach.item)
+// and $(forEach.index) are replaced by the element and its index. It also
+// returns the index of the step of the Task each of the steps comes from.
+func expandForEachSteps(taskRun *v1beta1.TaskRun, taskSpec v1beta1.TaskSpec, steps []v1beta1.Step) ([]v1beta1.Step, []int, error) {
+	expanded := make([]v1beta1.Step, 0, len(steps))
+	origins := make([]int, 0, len(steps))
+	for i, s := range steps {
+		if s.ForEach == nil {
+			expanded = append(expanded, s)
+			origins = append(origins, i)
+			continue
+		}
+		items, err := forEachItems(taskRun, taskSpec, s.ForEach.ParamName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("step %q: %w", s.Name, err)
+		}
+		for j, item := range items {
+			iteration := *s.DeepCopy()
+			iteration.Name = fmt.Sprintf("%s-%d", s.Name, j)
+			v1beta1.ApplyStepReplacements(&iteration, map[string]string{
+				"forEach.item":  item,
+				"forEach.index": strconv.Itoa(j),
+			}, map[string][]string{})
+			iteration.VolumeMounts = append(iteration.VolumeMounts, corev1.VolumeMount{
+				Name:      resultsVolumeName,
+				MountPath: ResultsDir,
+				SubPath:   path.Join(forEachResultsSubPath, s.Name, strconv.Itoa(j)),
+			})
+			expanded = append(expanded, iteration)
+			origins = append(origins, i)
+		}
+	}
+	return expanded, origins, nil
+}
+
+// forEachItems returns the elements of the array parameter name, as set by the
+// TaskRun or defaulted by the Task.
+func forEachItems(taskRun *v1beta1.TaskRun, taskSpec v1beta1.TaskSpec, name string) ([]string, error) {
+	for _, p := range taskRun.Spec.Params {
+		if p.Name == name {
+			if p.Value.Type != v1beta1.ParamTypeArray {
+				return nil, fmt.Errorf("forEach parameter %q is not an array", name)
+			}
+			return p.Value.ArrayVal, nil
+		}
+	}
+	for _, p := range taskSpec.Params {
+		if p.Name == name && p.Default != nil {
+			if p.Default.Type != v1beta1.ParamTypeArray {
+				return nil, fmt.Errorf("forEach parameter %q is not an array", name)
+			}
+			return p.Default.ArrayVal, nil
+		}
+	}
+	return nil, fmt.Errorf("forEach parameter %q has no value", name)
+}
+
+// runForEachIterationsInParallel makes the iterations of each step with a
+// forEach, which orderContainers chained like any other steps, all wait for the
+// steps before them instead, and the step after them wait for all of them.
+// origins are the indices of the steps of the Task the containers come from.
+// It must be called after orderContainers.
+func runForEachIterationsInParallel(origins []int, stepContainers []corev1.Container) {
+	first := 0
+	var previous []string
+	for i := range stepContainers {
+		if i > 0 && origins[i] != origins[i-1] {
+			previous = previous[:0]
+			for j := first; j < i; j++ {
+				previous = append(previous, filepath.Join(mountPoint, strconv.Itoa(j)))
+			}
+			first = i
+		}
+		if i == 0 {
+			continue
+		}
+		args := stepContainers[i].Args
+		if first == 0 {
+			// Iterations of the first step wait for the Downward volume file,
+			// as the first container does.
+			stepContainers[i].Args = append([]string{
+				"-wait_file", filepath.Join(downwardMountPoint, downwardMountReadyFile),
+				"-wait_file_content",
+			}, args[2:]...)
+			stepContainers[i].VolumeMounts = append(stepContainers[i].VolumeMounts, downwardMount)
+			continue
+		}
+		args[1] = strings.Join(previous, ",")
+	}
+}
+
+// ForEachIteration returns the name of the step with a forEach the container
+// runs an iteration of, along with the index of the iteration. ok is false if
+// the container doesn't run an iteration of a step.
+func ForEachIteration(c corev1.Container) (step string, index int, ok bool) {
+	for _, vm := range c.VolumeMounts {
+		if vm.Name != resultsVolumeName || !strings.HasPrefix(vm.SubPath, forEachResultsSubPath+"/") {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(vm.SubPath, forEachResultsSubPath+"/"), "/")
+		if len(parts) != 2 {
+			return "", 0, false
+		}
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return "", 0, false
+		}
+		return parts[0], index, true
+	}
+	return "", 0, false
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestExpandForEachSteps(t *testing.T) {
+	taskSpec := v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{{
+			Name:    "targets",
+			Type:    v1beta1.ParamTypeArray,
+			Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"default"}},
+		}},
+	}
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "build", Image: "builder"},
+	}, {
+		Container: corev1.Container{Name: "test", Image: "tester", Args: []string{"--target", "$(forEach.item)"}},
+		Script:    "echo $(forEach.index)",
+		ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+	}, {
+		Container: corev1.Container{Name: "publish", Image: "publisher"},
+	}}
+	taskRun := &v1beta1.TaskRun{
+		Spec: v1beta1.TaskRunSpec{
+			Params: []v1beta1.Param{{Name: "targets", Value: v1beta1.NewArrayOrString("linux", "darwin")}},
+		},
+	}
+
+	got, gotOrigins, err := expandForEachSteps(taskRun, taskSpec, steps)
+	if err != nil {
+		t.Fatalf("expandForEachSteps: %v", err)
+	}
+	want := []v1beta1.Step{steps[0], {
+		Container: corev1.Container{
+			Name:  "test-0",
+			Image: "tester",
+			Args:  []string{"--target", "linux"},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "tekton-internal-results",
+				MountPath: "/tekton/results",
+				SubPath:   "for-each/test/0",
+			}},
+		},
+		Script:  "echo 0",
+		ForEach: &v1beta1.StepForEach{ParamName: "targets"},
+	}, {
+		Container: corev1.Container{
+			Name:  "test-1",
+			Image: "tester",
+			Args:  []string{"--target", "darwin"},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "tekton-internal-results",
+				MountPath: "/tekton/results",
+				SubPath:   "for-each/test/1",
+			}},
+		},
+		Script:  "echo 1",
+		ForEach: &v1beta1.StepForEach{ParamName: "targets"},
+	}, steps[2]}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]int{0, 1, 1, 2}, gotOrigins); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+
+	// Without a value set by the TaskRun, the default of the param is used.
+	got, _, err = expandForEachSteps(&v1beta1.TaskRun{}, taskSpec, steps)
+	if err != nil {
+		t.Fatalf("expandForEachSteps: %v", err)
+	}
+	if len(got) != 3 || got[1].Name != "test-0" || got[1].Args[1] != "default" {
+		t.Errorf("expandForEachSteps() = %v, want a single iteration over the default", got)
+	}
+}
+
+func TestExpandForEachSteps_Error(t *testing.T) {
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "test", Image: "tester"},
+		ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+	}}
+	for _, c := range []struct {
+		desc    string
+		params  []v1beta1.Param
+		wantErr string
+	}{{
+		desc:    "no value",
+		wantErr: `step "test": forEach parameter "targets" has no value`,
+	}, {
+		desc:    "string value",
+		params:  []v1beta1.Param{{Name: "targets", Value: v1beta1.NewArrayOrString("linux")}},
+		wantErr: `step "test": forEach parameter "targets" is not an array`,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			taskRun := &v1beta1.TaskRun{Spec: v1beta1.TaskRunSpec{Params: c.params}}
+			_, _, err := expandForEachSteps(taskRun, v1beta1.TaskSpec{}, steps)
+			if err == nil || err.Error() != c.wantErr {
+				t.Errorf("expandForEachSteps() = %v, want %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunForEachIterationsInParallel(t *testing.T) {
+	for _, c := range []struct {
+		desc     string
+		origins  []int
+		wantArgs [][]string
+	}{{
+		desc:    "no forEach",
+		origins: []int{0, 1, 2},
+		wantArgs: [][]string{
+			{"-wait_file", "/tekton/downward/ready", "-wait_file_content", "-post_file", "/tekton/tools/0"},
+			{"-wait_file", "/tekton/tools/0", "-post_file", "/tekton/tools/1"},
+			{"-wait_file", "/tekton/tools/1", "-post_file", "/tekton/tools/2"},
+		},
+	}, {
+		desc:    "forEach in the middle",
+		origins: []int{0, 1, 1, 1, 2},
+		wantArgs: [][]string{
+			{"-wait_file", "/tekton/downward/ready", "-wait_file_content", "-post_file", "/tekton/tools/0"},
+			{"-wait_file", "/tekton/tools/0", "-post_file", "/tekton/tools/1"},
+			{"-wait_file", "/tekton/tools/0", "-post_file", "/tekton/tools/2"},
+			{"-wait_file", "/tekton/tools/0", "-post_file", "/tekton/tools/3"},
+			{"-wait_file", "/tekton/tools/1,/tekton/tools/2,/tekton/tools/3", "-post_file", "/tekton/tools/4"},
+		},
+	}, {
+		desc:    "forEach first",
+		origins: []int{0, 0, 1},
+		wantArgs: [][]string{
+			{"-wait_file", "/tekton/downward/ready", "-wait_file_content", "-post_file", "/tekton/tools/0"},
+			{"-wait_file", "/tekton/downward/ready", "-wait_file_content", "-post_file", "/tekton/tools/1"},
+			{"-wait_file", "/tekton/tools/0,/tekton/tools/1", "-post_file", "/tekton/tools/2"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			var stepContainers []corev1.Container
+			for range c.origins {
+				stepContainers = append(stepContainers, corev1.Container{Image: "image", Command: []string{"cmd"}})
+			}
+			_, stepContainers, err := orderContainers(images.EntrypointImage, nil, stepContainers, nil)
+			if err != nil {
+				t.Fatalf("orderContainers: %v", err)
+			}
+			runForEachIterationsInParallel(c.origins, stepContainers)
+			for i, sc := range stepContainers {
+				// Drop the arguments following the post file, which are left as is.
+				if d := cmp.Diff(c.wantArgs[i], sc.Args[:len(c.wantArgs[i])]); d != "" {
+					t.Errorf("step %d: Diff %s", i, diff.PrintWantGot(d))
+				}
+				mountsDownward := false
+				for _, vm := range sc.VolumeMounts {
+					mountsDownward = mountsDownward || vm == downwardMount
+				}
+				if wantDownward := c.origins[i] == 0; mountsDownward != wantDownward {
+					t.Errorf("step %d mounts the downward volume: %t, want %t", i, mountsDownward, wantDownward)
+				}
+			}
+		})
+	}
+}
+
+func TestForEachIteration(t *testing.T) {
+	for _, c := range []struct {
+		desc      string
+		mounts    []corev1.VolumeMount
+		wantStep  string
+		wantIndex int
+		wantOK    bool
+	}{{
+		desc:   "no mount",
+		wantOK: false,
+	}, {
+		desc:   "results mount",
+		mounts: []corev1.VolumeMount{{Name: "tekton-internal-results", MountPath: "/tekton/results"}},
+		wantOK: false,
+	}, {
+		desc:      "iteration results mount",
+		mounts:    []corev1.VolumeMount{{Name: "tekton-internal-results", MountPath: "/tekton/results", SubPath: "for-each/test/3"}},
+		wantStep:  "test",
+		wantIndex: 3,
+		wantOK:    true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			step, index, ok := ForEachIteration(corev1.Container{VolumeMounts: c.mounts})
+			if step != c.wantStep || index != c.wantIndex || ok != c.wantOK {
+				t.Errorf("ForEachIteration() = %q, %d, %t, want %q, %d, %t", step, index, ok, c.wantStep, c.wantIndex, c.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// gpuTypeNodeLabel is the label of the nodes matched against the GPUType of
+// the pod template.
+const gpuTypeNodeLabel = "accelerator"
+
+// requestGPUs adds the GPUs requested by the steps to the limits of their
+// containers. GPUs are extended resources which can't be overcommitted, so
+// Kubernetes only accepts them as limits, and sets the requests to match.
+func requestGPUs(steps []v1beta1.Step, stepContainers []corev1.Container) {
+	for i, s := range steps {
+		if s.GPU == nil {
+			continue
+		}
+		// Copy the limits, which may be shared with the step template.
+		limits := corev1.ResourceList{}
+		for name, qty := range stepContainers[i].Resources.Limits {
+			limits[name] = qty
+		}
+		limits[s.GPU.ResourceName()] = *resource.NewQuantity(s.GPU.Count, resource.DecimalSI)
+		stepContainers[i].Resources.Limits = limits
+	}
+}
+
+// gpuNodeSelector returns the node selector of the pod template, which also
+// selects the nodes with the GPU type of the template if it has one. The
+// template isn't modified.
+func gpuNodeSelector(podTemplate v1beta1.PodTemplate) map[string]string {
+	if podTemplate.GPUType == "" {
+		return podTemplate.NodeSelector
+	}
+	selector := map[string]string{}
+	for k, v := range podTemplate.NodeSelector {
+		selector[k] = v
+	}
+	selector[gpuTypeNodeLabel] = podTemplate.GPUType
+	return selector
+}
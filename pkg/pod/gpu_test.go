@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRequestGPUs(t *testing.T) {
+	templateLimits := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "train", Resources: corev1.ResourceRequirements{Limits: templateLimits}},
+		GPU:       &v1beta1.StepGPU{Count: 2},
+	}, {
+		Container: corev1.Container{Name: "evaluate", Resources: corev1.ResourceRequirements{Limits: templateLimits}},
+	}, {
+		Container: corev1.Container{Name: "export"},
+		GPU:       &v1beta1.StepGPU{Vendor: v1beta1.GPUVendorAMD, Count: 1},
+	}}
+	stepContainers := []corev1.Container{steps[0].Container, steps[1].Container, steps[2].Container}
+	want := []corev1.Container{{
+		Name: "train",
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+			"nvidia.com/gpu":      resource.MustParse("2"),
+		}},
+	}, {
+		Name:      "evaluate",
+		Resources: corev1.ResourceRequirements{Limits: templateLimits},
+	}, {
+		Name: "export",
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+			"amd.com/gpu": resource.MustParse("1"),
+		}},
+	}}
+	requestGPUs(steps, stepContainers)
+	if d := cmp.Diff(want, stepContainers, resourceQuantityCmp); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+	if _, ok := templateLimits["nvidia.com/gpu"]; ok {
+		t.Error("requestGPUs() modified the limits shared by the steps")
+	}
+}
+
+func TestGPUNodeSelector(t *testing.T) {
+	for _, c := range []struct {
+		desc        string
+		podTemplate v1beta1.PodTemplate
+		want        map[string]string
+	}{{
+		desc:        "no gpu type",
+		podTemplate: v1beta1.PodTemplate{NodeSelector: map[string]string{"disk": "ssd"}},
+		want:        map[string]string{"disk": "ssd"},
+	}, {
+		desc:        "gpu type",
+		podTemplate: v1beta1.PodTemplate{GPUType: "nvidia-tesla-t4"},
+		want:        map[string]string{"accelerator": "nvidia-tesla-t4"},
+	}, {
+		desc: "gpu type and node selector",
+		podTemplate: v1beta1.PodTemplate{
+			NodeSelector: map[string]string{"disk": "ssd"},
+			GPUType:      "nvidia-tesla-t4",
+		},
+		want: map[string]string{"disk": "ssd", "accelerator": "nvidia-tesla-t4"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			nodeSelector := c.podTemplate.NodeSelector
+			got := gpuNodeSelector(c.podTemplate)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+			if _, ok := nodeSelector[gpuTypeNodeLabel]; ok {
+				t.Error("gpuNodeSelector() modified the node selector of the pod template")
+			}
+		})
+	}
+}
+
+func TestPodBuild_GPU(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+	)
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name", Namespace: "default"},
+		Spec: v1beta1.TaskRunSpec{
+			PodTemplate: &v1beta1.PodTemplate{GPUType: "nvidia-tesla-t4"},
+		},
+	}
+	ts := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:    "train",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			},
+			GPU: &v1beta1.StepGPU{Count: 1},
+		}},
+	}
+	builder := Builder{
+		Images:          images,
+		KubeClient:      kubeclient,
+		EntrypointCache: fakeCache{},
+	}
+	got, err := builder.Build(context.Background(), tr, ts)
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	if d := cmp.Diff(map[string]string{"accelerator": "nvidia-tesla-t4"}, got.Spec.NodeSelector); d != "" {
+		t.Errorf("NodeSelector %s", diff.PrintWantGot(d))
+	}
+	wantLimits := corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+	if d := cmp.Diff(wantLimits, got.Spec.Containers[0].Resources.Limits, resourceQuantityCmp); d != "" {
+		t.Errorf("Limits %s", diff.PrintWantGot(d))
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	preStepHookFile  = filepath.Join(scriptsDir, "pre-step-hook")
+	postStepHookFile = filepath.Join(scriptsDir, "post-step-hook")
+)
+
+// placeHooks adds the writing of the hooks of the Task to files of the scripts
+// volume to the place-scripts init container, which is created if the steps
+// and sidecars have no script. It returns the init container, which is nil if
+// there are neither scripts nor hooks.
+func placeHooks(shellImage string, scriptsInit *corev1.Container, hooks *v1beta1.TaskHooks) *corev1.Container {
+	if hooks == nil || len(hooks.PreStep) == 0 && len(hooks.PostStep) == 0 {
+		return scriptsInit
+	}
+	if scriptsInit == nil {
+		scriptsInit = &corev1.Container{
+			Name:         "place-scripts",
+			Image:        shellImage,
+			Command:      []string{"sh"},
+			Args:         []string{"-c", ""},
+			VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+		}
+	}
+	var postStep []string
+	if len(hooks.PostStep) > 0 {
+		// The entrypoint binary passes the exit code of the step as argument.
+		postStep = append([]string{"code=$1"}, hooks.PostStep...)
+	}
+	for _, h := range []struct {
+		file  string
+		lines []string
+	}{{preStepHookFile, hooks.PreStep}, {postStepHookFile, postStep}} {
+		if len(h.lines) == 0 {
+			continue
+		}
+		heredoc := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("hook-heredoc-randomly-generated")
+		scriptsInit.Args[1] += fmt.Sprintf(`cat > %s << '%s'
+%s
+%s
+`, h.file, heredoc, strings.Join(h.lines, "\n"), heredoc)
+	}
+	return scriptsInit
+}
+
+// runStepHooks makes the entrypoint binary run the hooks of the Task before
+// and after the command of each step, in the container of the step. It must be
+// called after orderContainers.
+func runStepHooks(hooks *v1beta1.TaskHooks, stepContainers []corev1.Container) {
+	if hooks == nil || len(hooks.PreStep) == 0 && len(hooks.PostStep) == 0 {
+		return
+	}
+	var hookArgs []string
+	if len(hooks.PreStep) > 0 {
+		hookArgs = append(hookArgs, "-pre_step_hook", preStepHookFile)
+	}
+	if len(hooks.PostStep) > 0 {
+		hookArgs = append(hookArgs, "-post_step_hook", postStepHookFile)
+	}
+	for i, c := range stepContainers {
+		stepContainers[i].Args = append(append([]string{}, hookArgs...), c.Args...)
+		if !mountsScripts(c) {
+			stepContainers[i].VolumeMounts = append(stepContainers[i].VolumeMounts, scriptsVolumeMount)
+		}
+	}
+}
+
+func mountsScripts(c corev1.Container) bool {
+	for _, vm := range c.VolumeMounts {
+		if vm.Name == scriptsVolumeName {
+			return true
+		}
+	}
+	return false
+}
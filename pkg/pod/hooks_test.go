@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPlaceHooks(t *testing.T) {
+	names.TestingSeed()
+
+	if got := placeHooks(images.ShellImage, nil, &v1beta1.TaskHooks{}); got != nil {
+		t.Errorf("placeHooks() without hooks = %v, want nil", got)
+	}
+
+	got := placeHooks(images.ShellImage, nil, &v1beta1.TaskHooks{
+		PreStep:  []string{"refresh-credentials", "echo refreshed"},
+		PostStep: []string{"audit"},
+	})
+	want := &corev1.Container{
+		Name:    "place-scripts",
+		Image:   images.ShellImage,
+		Command: []string{"sh"},
+		Args: []string{"-c", `cat > /tekton/scripts/pre-step-hook << 'hook-heredoc-randomly-generated-9l9zj'
+refresh-credentials
+echo refreshed
+hook-heredoc-randomly-generated-9l9zj
+cat > /tekton/scripts/post-step-hook << 'hook-heredoc-randomly-generated-mz4c7'
+code=$1
+audit
+hook-heredoc-randomly-generated-mz4c7
+`},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+
+	// The hooks are appended to the scripts of the steps.
+	scriptsInit := &corev1.Container{Name: "place-scripts", Args: []string{"-c", "place-step-scripts\n"}}
+	got = placeHooks(images.ShellImage, scriptsInit, &v1beta1.TaskHooks{PostStep: []string{"audit"}})
+	wantArgs := []string{"-c", `place-step-scripts
+cat > /tekton/scripts/post-step-hook << 'hook-heredoc-randomly-generated-mssqb'
+code=$1
+audit
+hook-heredoc-randomly-generated-mssqb
+`}
+	if d := cmp.Diff(wantArgs, got.Args); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestRunStepHooks(t *testing.T) {
+	for _, c := range []struct {
+		desc         string
+		hooks        *v1beta1.TaskHooks
+		wantHookArgs []string
+	}{{
+		desc:         "pre and post step hooks",
+		hooks:        &v1beta1.TaskHooks{PreStep: []string{"refresh-credentials"}, PostStep: []string{"audit"}},
+		wantHookArgs: []string{"-pre_step_hook", "/tekton/scripts/pre-step-hook", "-post_step_hook", "/tekton/scripts/post-step-hook"},
+	}, {
+		desc:         "pre step hooks",
+		hooks:        &v1beta1.TaskHooks{PreStep: []string{"refresh-credentials"}},
+		wantHookArgs: []string{"-pre_step_hook", "/tekton/scripts/pre-step-hook"},
+	}, {
+		desc:         "post step hooks",
+		hooks:        &v1beta1.TaskHooks{PostStep: []string{"audit"}},
+		wantHookArgs: []string{"-post_step_hook", "/tekton/scripts/post-step-hook"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			stepContainers := []corev1.Container{{
+				Name:    "build",
+				Command: []string{entrypointBinary},
+				Args:    []string{"-post_file", "/tekton/tools/0", "-entrypoint", "make", "--", "all"},
+			}, {
+				Name:         "script",
+				Command:      []string{entrypointBinary},
+				Args:         []string{"-post_file", "/tekton/tools/1", "-entrypoint", "/tekton/scripts/script-1-9l9zj", "--"},
+				VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+			}}
+			runStepHooks(c.hooks, stepContainers)
+			want := []corev1.Container{{
+				Name:         "build",
+				Command:      []string{entrypointBinary},
+				Args:         append(c.wantHookArgs, "-post_file", "/tekton/tools/0", "-entrypoint", "make", "--", "all"),
+				VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+			}, {
+				Name:         "script",
+				Command:      []string{entrypointBinary},
+				Args:         append(c.wantHookArgs, "-post_file", "/tekton/tools/1", "-entrypoint", "/tekton/scripts/script-1-9l9zj", "--"),
+				VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+			}}
+			if d := cmp.Diff(want, stepContainers); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestRunStepHooks_NoHooks(t *testing.T) {
+	stepContainers := []corev1.Container{{Name: "build", Command: []string{entrypointBinary}, Args: []string{"-entrypoint", "make", "--"}}}
+	want := []corev1.Container{{Name: "build", Command: []string{entrypointBinary}, Args: []string{"-entrypoint", "make", "--"}}}
+	runStepHooks(nil, stepContainers)
+	if d := cmp.Diff(want, stepContainers); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
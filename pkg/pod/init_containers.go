@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reservedInitContainerNames are the names of the init containers Tekton may add
+// to the Pod of a TaskRun.
+var reservedInitContainerNames = []string{"place-scripts", "working-dir-initializer", "place-tools"}
+
+// prependInitContainers returns the init containers declared by the Task followed by
+// the init containers added by Tekton. An error is returned if the name of an init
+// container of the Task is reserved by Tekton, or collides with the name of another
+// container of the Pod.
+func prependInitContainers(taskInitContainers, initContainers, containers []corev1.Container) ([]corev1.Container, error) {
+	if len(taskInitContainers) == 0 {
+		return initContainers, nil
+	}
+	taken := map[string]bool{}
+	for _, n := range reservedInitContainerNames {
+		taken[n] = true
+	}
+	for _, c := range initContainers {
+		taken[c.Name] = true
+	}
+	for _, c := range containers {
+		taken[c.Name] = true
+	}
+	for _, c := range taskInitContainers {
+		if taken[c.Name] {
+			return nil, fmt.Errorf("init container name %q is reserved by Tekton", c.Name)
+		}
+		if strings.HasPrefix(c.Name, stepPrefix) || strings.HasPrefix(c.Name, sidecarPrefix) {
+			return nil, fmt.Errorf("init container name %q cannot start with %q or %q", c.Name, stepPrefix, sidecarPrefix)
+		}
+	}
+	return append(append([]corev1.Container{}, taskInitContainers...), initContainers...), nil
+}
+
+// applyInitContainerResources sets the resource requirements of the init containers
+// added by Tekton to the default-init-container-resources of the config-defaults
+// ConfigMap, if any, so that they aren't given the defaults of a LimitRange.
+func applyInitContainerResources(ctx context.Context, initContainers []corev1.Container) {
+	resources := config.FromContextOrDefaults(ctx).Defaults.DefaultInitContainerResources
+	if resources == nil {
+		return
+	}
+	for i := range initContainers {
+		initContainers[i].Resources = *resources.DeepCopy()
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// internalMetadataPrefix is the prefix of the names of the labels and annotations
+// that are never propagated.
+const internalMetadataPrefix = "tekton-internal-"
+
+// nonPropagatedMetadataKeys are the labels and annotations that are never propagated,
+// since they only describe the resource they are set on.
+var nonPropagatedMetadataKeys = map[string]bool{
+	corev1.LastAppliedConfigAnnotation: true,
+}
+
+// IsMetadataPropagated returns true if the label or annotation key of a run should be
+// propagated to the resources it creates. Keys of the denylist are never propagated.
+// When the "default-propagated-metadata-prefixes" of the config-defaults ConfigMap are
+// set, only the keys starting with one of them, and Tekton's own keys, are propagated.
+func IsMetadataPropagated(ctx context.Context, key string) bool {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		name = key[i+1:]
+	}
+	if nonPropagatedMetadataKeys[key] || strings.HasPrefix(name, internalMetadataPrefix) {
+		return false
+	}
+
+	prefixes := config.FromContextOrDefaults(ctx).Defaults.DefaultPropagatedMetadataPrefixes
+	if len(prefixes) == 0 || isTektonMetadataKey(key) {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PropagatedMetadata returns a copy of the labels or annotations of a run holding the
+// keys that should be propagated to the resources it creates.
+func PropagatedMetadata(ctx context.Context, metadata map[string]string) map[string]string {
+	propagated := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if IsMetadataPropagated(ctx, k) {
+			propagated[k] = v
+		}
+	}
+	return propagated
+}
+
+// isTektonMetadataKey returns true if key is prefixed with Tekton's domain, or one
+// of its subdomains.
+func isTektonMetadataKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	return domain == pipeline.GroupName || strings.HasSuffix(domain, "."+pipeline.GroupName)
+}
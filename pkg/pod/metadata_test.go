@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func withPropagatedMetadataPrefixes(ctx context.Context, prefixes ...string) context.Context {
+	cfg := config.FromContextOrDefaults(ctx)
+	defaults := *cfg.Defaults
+	defaults.DefaultPropagatedMetadataPrefixes = prefixes
+	cfg.Defaults = &defaults
+	return config.ToContext(ctx, cfg)
+}
+
+func TestPropagatedMetadata(t *testing.T) {
+	metadata := map[string]string{
+		"app":                              "foo",
+		"example.com/team":                 "bar",
+		"other.com/team":                   "baz",
+		"tekton.dev/pipeline":              "pipeline",
+		"triggers.tekton.dev/trigger":      "trigger",
+		"example.com/tekton-internal-x":    "internal",
+		"tekton-internal-y":                "internal",
+		corev1.LastAppliedConfigAnnotation: "{}",
+	}
+
+	for _, tc := range []struct {
+		desc string
+		ctx  context.Context
+		want map[string]string
+	}{{
+		desc: "no prefixes",
+		ctx:  context.Background(),
+		want: map[string]string{
+			"app":                         "foo",
+			"example.com/team":            "bar",
+			"other.com/team":              "baz",
+			"tekton.dev/pipeline":         "pipeline",
+			"triggers.tekton.dev/trigger": "trigger",
+		},
+	}, {
+		desc: "restricted to prefixes",
+		ctx:  withPropagatedMetadataPrefixes(context.Background(), "example.com/", "ap"),
+		want: map[string]string{
+			"app":                         "foo",
+			"example.com/team":            "bar",
+			"tekton.dev/pipeline":         "pipeline",
+			"triggers.tekton.dev/trigger": "trigger",
+		},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := PropagatedMetadata(tc.ctx, metadata)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPropagatedMetadata_DoesNotMutate(t *testing.T) {
+	metadata := map[string]string{
+		"app":                              "foo",
+		corev1.LastAppliedConfigAnnotation: "{}",
+	}
+	got := PropagatedMetadata(context.Background(), metadata)
+	got["extra"] = "value"
+	if len(metadata) != 2 {
+		t.Errorf("Expected the metadata to be left untouched, got %v", metadata)
+	}
+}
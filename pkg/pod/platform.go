@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+// PlatformAnnotation is the annotation holding the platform a Pod of a TaskRun
+// runs on, when its Task lists platforms.
+const PlatformAnnotation = pipeline.GroupName + "/platform"
+
+// PlatformPodName returns the name of the Pod running the current attempt of
+// taskRun on platform. It doesn't change during the attempt, so that the Pod of
+// each platform can be found again.
+func PlatformPodName(taskRun *v1beta1.TaskRun, platform string) string {
+	suffix := "-" + strings.Replace(platform, "/", "-", -1) + "-pod"
+	if attempt := len(taskRun.Status.RetriesStatus); attempt > 0 {
+		suffix += fmt.Sprintf("-retry%d", attempt)
+	}
+	return kmeta.ChildName(taskRun.Name, suffix)
+}
+
+// setPlatform names pod after its platform, and schedules it on a node of that
+// platform.
+func setPlatform(pod *corev1.Pod, taskRun *v1beta1.TaskRun, platform string) {
+	osName, arch := platform, ""
+	if i := strings.Index(platform, "/"); i >= 0 {
+		osName, arch = platform[:i], platform[i+1:]
+	}
+	pod.Name = PlatformPodName(taskRun, platform)
+	pod.Annotations[PlatformAnnotation] = platform
+
+	nodeSelector := make(map[string]string, len(pod.Spec.NodeSelector)+2)
+	for k, v := range pod.Spec.NodeSelector {
+		nodeSelector[k] = v
+	}
+	nodeSelector[corev1.LabelOSStable] = osName
+	nodeSelector[corev1.LabelArchStable] = arch
+	pod.Spec.NodeSelector = nodeSelector
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodBuild_Platform(t *testing.T) {
+	names.TestingSeed()
+	kubeclient := fakek8s.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+	)
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "taskrun-name",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			PodTemplate: &v1beta1.PodTemplate{
+				NodeSelector: map[string]string{"disktype": "ssd"},
+			},
+		},
+	}
+	ts := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{
+			Name:    "build",
+			Image:   "image",
+			Command: []string{"cmd"}, // avoid entrypoint lookup.
+		}}},
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+	builder := Builder{
+		Images:          images,
+		KubeClient:      kubeclient,
+		EntrypointCache: fakeCache{},
+		Platform:        "linux/arm64",
+	}
+	got, err := builder.Build(context.Background(), tr, ts)
+	if err != nil {
+		t.Fatalf("builder.Build: %v", err)
+	}
+
+	if want := PlatformPodName(tr, "linux/arm64"); got.Name != want {
+		t.Errorf("Expected the pod to be named %q but got %q", want, got.Name)
+	}
+	if want := "taskrun-name-linux-arm64-pod"; got.Name != want {
+		t.Errorf("Expected the pod to be named %q but got %q", want, got.Name)
+	}
+	if platform := got.Annotations[PlatformAnnotation]; platform != "linux/arm64" {
+		t.Errorf("Expected the pod to be annotated with platform linux/arm64 but got %q", platform)
+	}
+	wantNodeSelector := map[string]string{
+		"disktype":           "ssd",
+		"kubernetes.io/os":   "linux",
+		"kubernetes.io/arch": "arm64",
+	}
+	if d := cmp.Diff(wantNodeSelector, got.Spec.NodeSelector); d != "" {
+		t.Errorf("Unexpected node selector %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(map[string]string{"disktype": "ssd"}, tr.Spec.PodTemplate.NodeSelector); d != "" {
+		t.Errorf("Expected the pod template of the TaskRun to be left untouched %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPlatformPodName(t *testing.T) {
+	tr := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"}}
+	if got, want := PlatformPodName(tr, "linux/s390x"), "taskrun-name-linux-s390x-pod"; got != want {
+		t.Errorf("Expected %q but got %q", want, got)
+	}
+	tr.Status.RetriesStatus = []v1beta1.TaskRunStatus{{}, {}}
+	if got, want := PlatformPodName(tr, "linux/s390x"), "taskrun-name-linux-s390x-pod-retry2"; got != want {
+		t.Errorf("Expected %q but got %q", want, got)
+	}
+}
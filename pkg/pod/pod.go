@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -31,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
 )
 
 const (
@@ -81,6 +83,11 @@ type Builder struct {
 	KubeClient      kubernetes.Interface
 	EntrypointCache EntrypointCache
 	OverrideHomeEnv bool
+	ExternalSecrets ExternalSecretResolver
+	DigestCache     DigestCache
+	// Platform is the "os/arch" platform the Pod runs on, for Tasks listing
+	// platforms. It is empty otherwise.
+	Platform string
 }
 
 // Build creates a Pod using the configuration options set on b and the TaskRun
@@ -105,17 +112,44 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 
 	// Create Volumes and VolumeMounts for any credentials found in annotated
 	// Secrets, along with any arguments needed by Step entrypoints to process
-	// those secrets.
-	credEntrypointArgs, credVolumes, credVolumeMounts, err := credsInit(taskRun.Spec.ServiceAccountName, taskRun.Namespace, b.KubeClient)
+	// those secrets, unless credentials initialization is disabled globally
+	// or for this TaskRun.
+	var credEntrypointArgs []string
+	disableCredsInit, disableCredsInitPerRun := credsInitDisabled(ctx, taskRun)
+	if disableCredsInitPerRun {
+		if err := warnIgnoredCredentials(ctx, taskRun, b.KubeClient); err != nil {
+			return nil, err
+		}
+	}
+	if !disableCredsInit {
+		args, credVolumes, credVolumeMounts, err := credsInit(taskRun.Spec.ServiceAccountName, taskRun.Namespace, b.KubeClient)
+		if err != nil {
+			return nil, err
+		}
+		credEntrypointArgs = args
+		volumes = append(volumes, credVolumes...)
+		volumeMounts = append(volumeMounts, credVolumeMounts...)
+	}
+
+	// Rewrite the ExternalSecrets of steps to the Secrets they are synced to.
+	steps, err := resolveExternalSecrets(ctx, b.ExternalSecrets, taskRun.Namespace, taskSpec.Steps)
 	if err != nil {
 		return nil, err
 	}
-	volumes = append(volumes, credVolumes...)
-	volumeMounts = append(volumeMounts, credVolumeMounts...)
 
 	// Merge step template with steps.
 	// TODO(#1605): Move MergeSteps to pkg/pod
-	steps, err := v1beta1.MergeStepsWithStepTemplate(taskSpec.StepTemplate, taskSpec.Steps)
+	steps, err = v1beta1.MergeStepsWithStepTemplate(taskSpec.StepTemplate, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the step overrides of the TaskRun, which take precedence over
+	// both the steps and the step template.
+	steps = applyStepOverrides(steps, taskRun.Spec.StepOverrides)
+
+	// Expand the steps with a forEach into one step per iteration.
+	steps, stepOrigins, err := expandForEachSteps(taskRun, taskSpec, steps)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +157,8 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 	// Convert any steps with Script to command+args.
 	// If any are found, append an init container to initialize scripts.
 	scriptsInit, stepContainers, sidecarContainers := convertScripts(b.Images.ShellImage, steps, taskSpec.Sidecars)
+	// Place the hooks of the Task along with the scripts.
+	scriptsInit = placeHooks(b.Images.ShellImage, scriptsInit, taskSpec.Hooks)
 	if scriptsInit != nil {
 		initContainers = append(initContainers, *scriptsInit)
 		volumes = append(volumes, scriptsVolume)
@@ -139,12 +175,54 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		return nil, err
 	}
 
+	// Pin the images of steps that specify a command to their digest as well.
+	if shouldPinImageDigests(ctx) {
+		stepContainers, err = pinImageDigests(ctx, b.EntrypointCache, b.DigestCache, taskRun, stepContainers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve entrypoint for sidecars restarted on failure, which are wrapped
+	// with the entrypoint binary.
+	if hasMixedSidecarRestartPolicies(taskSpec.Sidecars) {
+		logging.FromContext(ctx).Warnf("TaskRun %q declares sidecars with both %s and %s restartPolicy", taskRun.Name, corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure)
+	}
+	for i, sc := range taskSpec.Sidecars {
+		if sc.RestartPolicy != corev1.RestartPolicyOnFailure {
+			continue
+		}
+		if _, err := resolveEntrypoints(b.EntrypointCache, taskRun.Namespace, taskRun.Spec.ServiceAccountName, sidecarContainers[i:i+1]); err != nil {
+			return nil, err
+		}
+	}
+	if sidecarContainers, err = wrapSidecarsWithRestart(taskSpec.Sidecars, sidecarContainers); err != nil {
+		return nil, err
+	}
+
+	// Have the results printed to the logs of the steps, enclosed by a
+	// delimiter unique to this Pod, when they are read from there.
+	entrypointArgs := credEntrypointArgs
+	if len(taskSpec.Results) > 0 && shouldReadResultsFromLogs(ctx) {
+		entrypointArgs = append(entrypointArgs, resultsLogDelimiterFlag, uuid.New().String())
+	}
+	// Have the result markers printed to the stdout of the steps set their
+	// results, when they are allowed to.
+	if len(taskSpec.Results) > 0 && shouldSetResultsFromStdout(ctx) {
+		entrypointArgs = append(entrypointArgs, stdoutResultsFlag)
+	}
+
 	// Rewrite steps with entrypoint binary. Append the entrypoint init
 	// container to place the entrypoint binary.
-	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, credEntrypointArgs, stepContainers, taskSpec.Results)
+	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, entrypointArgs, stepContainers, taskSpec.Results)
 	if err != nil {
 		return nil, err
 	}
+	runForEachIterationsInParallel(stepOrigins, stepContainers)
+	isolateHermeticSteps(steps, stepContainers)
+	requestGPUs(steps, stepContainers)
+	distributeTimeout(taskRun, stepContainers)
+	runStepHooks(taskSpec.Hooks, stepContainers)
 	initContainers = append(initContainers, entrypointInit)
 	volumes = append(volumes, toolsVolume, downwardVolume)
 
@@ -170,9 +248,11 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		// Mount /tekton/creds with a fresh volume for each Step. It needs to
 		// be world-writeable and empty so creds can be initialized in there. Cant
 		// guarantee what UID container runs with.
-		v, vm := getCredsInitVolume()
-		volumes = append(volumes, v)
-		s.VolumeMounts = append(s.VolumeMounts, vm)
+		if !disableCredsInit {
+			v, vm := getCredsInitVolume()
+			volumes = append(volumes, v)
+			s.VolumeMounts = append(s.VolumeMounts, vm)
+		}
 
 		requestedVolumeMounts := map[string]bool{}
 		for _, vm := range s.VolumeMounts {
@@ -220,16 +300,7 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		return nil, err
 	}
 
-	// Using node affinity on taskRuns sharing PVC workspace, with an Affinity Assistant
-	// is mutually exclusive with other affinity on taskRun pods. If other
-	// affinity is wanted, that should be added on the Affinity Assistant pod unless
-	// assistant is disabled. When Affinity Assistant is disabled, an affinityAssistantName is not set.
-	var affinity *corev1.Affinity
-	if affinityAssistantName := taskRun.Annotations[workspace.AnnotationAffinityAssistantName]; affinityAssistantName != "" {
-		affinity = nodeAffinityUsingAffinityAssistant(affinityAssistantName)
-	} else {
-		affinity = podTemplate.Affinity
-	}
+	affinity := podAffinity(ctx, taskRun, podTemplate)
 
 	mergedPodContainers := stepContainers
 
@@ -239,6 +310,14 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		mergedPodContainers = append(mergedPodContainers, sc)
 	}
 
+	// Run the init containers of the Task ahead of our own, which get the
+	// default resource requirements of Tekton's init containers.
+	applyInitContainerResources(ctx, initContainers)
+	initContainers, err = prependInitContainers(taskSpec.InitContainers, initContainers, mergedPodContainers)
+	if err != nil {
+		return nil, err
+	}
+
 	var dnsPolicy corev1.DNSPolicy
 	if podTemplate.DNSPolicy != nil {
 		dnsPolicy = *podTemplate.DNSPolicy
@@ -249,14 +328,15 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		priorityClassName = *podTemplate.PriorityClassName
 	}
 
-	podAnnotations := taskRun.Annotations
+	podAnnotations := PropagatedMetadata(ctx, taskRun.Annotations)
 	podAnnotations[ReleaseAnnotation] = ReleaseAnnotationValue
 
 	if shouldAddReadyAnnotationOnPodCreate(ctx, taskSpec.Sidecars) {
 		podAnnotations[readyAnnotation] = readyAnnotationValue
 	}
+	addSeccompProfileAnnotations(steps, stepContainers, podAnnotations)
 
-	return &corev1.Pod{
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			// We execute the build's pod in the same namespace as where the build was
 			// created so that it can access colocated resources.
@@ -271,7 +351,7 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 				*metav1.NewControllerRef(taskRun, groupVersionKind),
 			},
 			Annotations: podAnnotations,
-			Labels:      MakeLabels(taskRun),
+			Labels:      MakeLabels(ctx, taskRun),
 		},
 		Spec: corev1.PodSpec{
 			RestartPolicy:                corev1.RestartPolicyNever,
@@ -279,7 +359,7 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 			Containers:                   mergedPodContainers,
 			ServiceAccountName:           taskRun.Spec.ServiceAccountName,
 			Volumes:                      volumes,
-			NodeSelector:                 podTemplate.NodeSelector,
+			NodeSelector:                 gpuNodeSelector(podTemplate),
 			Tolerations:                  podTemplate.Tolerations,
 			Affinity:                     affinity,
 			SecurityContext:              podTemplate.SecurityContext,
@@ -293,19 +373,18 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 			PriorityClassName:            priorityClassName,
 			ImagePullSecrets:             podTemplate.ImagePullSecrets,
 		},
-	}, nil
+	}
+	if b.Platform != "" {
+		setPlatform(pod, taskRun, b.Platform)
+	}
+	return pod, nil
 }
 
 // MakeLabels constructs the labels we will propagate from TaskRuns to Pods.
-func MakeLabels(s *v1beta1.TaskRun) map[string]string {
-	labels := make(map[string]string, len(s.ObjectMeta.Labels)+1)
-	// NB: Set this *before* passing through TaskRun labels. If the TaskRun
-	// has a managed-by label, it should override this default.
-
-	// Copy through the TaskRun's labels to the underlying Pod's.
-	for k, v := range s.ObjectMeta.Labels {
-		labels[k] = v
-	}
+func MakeLabels(ctx context.Context, s *v1beta1.TaskRun) map[string]string {
+	// Copy through the TaskRun's labels to the underlying Pod's, except the
+	// ones which shouldn't be propagated.
+	labels := PropagatedMetadata(ctx, s.ObjectMeta.Labels)
 
 	// NB: Set this *after* passing through TaskRun Labels. If the TaskRun
 	// specifies this label, it should be overridden by this value.
@@ -362,6 +441,27 @@ func getLimitRangeMinimum(namespace string, kubeclient kubernetes.Interface) (co
 	return min, nil
 }
 
+// applyStepOverrides returns a copy of steps with the fields set by the
+// override with the same name replaced.
+func applyStepOverrides(steps []v1beta1.Step, overrides []v1beta1.TaskRunStepOverride) []v1beta1.Step {
+	if len(overrides) == 0 {
+		return steps
+	}
+	overridden := make([]v1beta1.Step, len(steps))
+	copy(overridden, steps)
+	for _, o := range overrides {
+		for i := range overridden {
+			if overridden[i].Name != o.Name {
+				continue
+			}
+			if o.ImagePullPolicy != "" {
+				overridden[i].ImagePullPolicy = o.ImagePullPolicy
+			}
+		}
+	}
+	return overridden
+}
+
 // ShouldOverrideHomeEnv returns a bool indicating whether a Pod should have its
 // $HOME environment variable overwritten with /tekton/home or if it should be
 // left unmodified. The default behaviour is to overwrite the $HOME variable
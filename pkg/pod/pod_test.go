@@ -52,6 +52,7 @@ var (
 	featureInjectedSidecar                   = "running-in-environment-with-injected-sidecars"
 	featureFlagDisableHomeEnvKey             = "disable-home-env-overwrite"
 	featureFlagDisableWorkingDirKey          = "disable-working-directory-overwrite"
+	featureFlagDisableCredsInitKey           = "disable-creds-init"
 	featureFlagSetReadyAnnotationOnPodCreate = "enable-ready-annotation-on-pod-create"
 )
 
@@ -73,8 +74,48 @@ func TestPodBuild(t *testing.T) {
 	runtimeClassName := "gvisor"
 	automountServiceAccountToken := false
 	dnsPolicy := corev1.DNSNone
+	hostNetDNSPolicy := corev1.DNSClusterFirstWithHostNet
+	ndots := "2"
 	enableServiceLinks := false
 	priorityClassName := "system-cluster-critical"
+	runAsNonRoot, runAsRoot := true, false
+	runAsUser := int64(1000)
+	customTokenVolume := corev1.Volume{
+		Name: "custom-token",
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+			Sources: []corev1.VolumeProjection{{
+				ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Audience: "sigstore", Path: "token"},
+			}},
+		}},
+	}
+	credsInitDisabledPodSpec := &corev1.PodSpec{
+		ServiceAccountName: "service-account",
+		RestartPolicy:      corev1.RestartPolicyNever,
+		InitContainers:     []corev1.Container{placeToolsInit},
+		Containers: []corev1.Container{{
+			Name:    "step-name",
+			Image:   "image",
+			Command: []string{"/tekton/tools/entrypoint"},
+			Args: []string{
+				"-wait_file",
+				"/tekton/downward/ready",
+				"-wait_file_content",
+				"-post_file",
+				"/tekton/tools/0",
+				"-termination_path",
+				"/tekton/termination",
+				"-entrypoint",
+				"cmd",
+				"--",
+			},
+			Env:                    implicitEnvVars,
+			VolumeMounts:           append([]corev1.VolumeMount{toolsMount, downwardMount}, implicitVolumeMounts...),
+			WorkingDir:             pipeline.WorkspaceDir,
+			Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+			TerminationMessagePath: "/tekton/termination",
+		}},
+		Volumes: append(append([]corev1.Volume{}, implicitVolumes...), toolsVolume, downwardVolume),
+	}
 
 	for _, c := range []struct {
 		desc            string
@@ -126,6 +167,56 @@ func TestPodBuild(t *testing.T) {
 				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
 			}),
 		},
+	}, {
+		desc: "annotations not propagated",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-name",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
+		trAnnotation: map[string]string{
+			"owner":                            "team",
+			"example.com/tekton-internal-key":  "secret",
+			corev1.LastAppliedConfigAnnotation: "{}",
+		},
+		wantAnnotations: map[string]string{
+			"owner": "team",
+		},
 	}, {
 		desc: "simple with running-in-environment-with-injected-sidecar set to false",
 		ts: v1beta1.TaskSpec{
@@ -174,6 +265,58 @@ func TestPodBuild(t *testing.T) {
 		wantAnnotations: map[string]string{
 			readyAnnotation: readyAnnotationValue,
 		},
+	}, {
+		desc: "with envFrom ExternalSecrets",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:    "name",
+					Image:   "image",
+					Command: []string{"cmd"}, // avoid entrypoint lookup.
+				},
+				EnvFromExternalSecrets: []v1beta1.ExternalSecretEnvSource{{
+					Name:   "credentials",
+					Prefix: "CREDS_",
+				}},
+			}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-name",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				EnvFrom: []corev1.EnvFromSource{{
+					Prefix:    "CREDS_",
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "synced-credentials"}},
+				}},
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
 	}, {
 		desc: "with service account",
 		ts: v1beta1.TaskSpec{
@@ -227,6 +370,57 @@ func TestPodBuild(t *testing.T) {
 				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
 			}),
 		},
+	}, {
+		desc: "with service account and creds-init disabled by feature flag",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			ServiceAccountName: "service-account",
+		},
+		featureFlags: map[string]string{
+			featureFlagDisableCredsInitKey: "true",
+		},
+		want: credsInitDisabledPodSpec,
+	}, {
+		desc: "with service account and creds-init disabled by annotation",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			ServiceAccountName: "service-account",
+		},
+		trAnnotation: map[string]string{
+			DisableCredsInitAnnotation: "true",
+		},
+		want: credsInitDisabledPodSpec,
+	}, {
+		desc: "with service account and creds-init disabled by feature flag but not by annotation",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			ServiceAccountName: "service-account",
+		},
+		featureFlags: map[string]string{
+			featureFlagDisableCredsInitKey: "true",
+		},
+		trAnnotation: map[string]string{
+			DisableCredsInitAnnotation: "false",
+		},
+		want: credsInitDisabledPodSpec,
 	}, {
 		desc: "with-pod-template",
 		ts: v1beta1.TaskSpec{
@@ -302,6 +496,65 @@ func TestPodBuild(t *testing.T) {
 			EnableServiceLinks: &enableServiceLinks,
 			PriorityClassName:  priorityClassName,
 		},
+	}, {
+		desc: "with-pod-template-dns",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			PodTemplate: &v1beta1.PodTemplate{
+				HostNetwork: true,
+				DNSPolicy:   &hostNetDNSPolicy,
+				DNSConfig: &corev1.PodDNSConfig{
+					Searches: []string{"builds.example.com"},
+					Options:  []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+				},
+			},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-name",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{
+					toolsMount,
+					downwardMount,
+					{Name: "tekton-creds-init-home-9l9zj", MountPath: "/tekton/creds"},
+				}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+			HostNetwork: true,
+			DNSPolicy:   hostNetDNSPolicy,
+			DNSConfig: &corev1.PodDNSConfig{
+				Searches: []string{"builds.example.com"},
+				Options:  []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+			},
+		},
 	}, {
 		desc: "very long step name",
 		ts: v1beta1.TaskSpec{
@@ -494,6 +747,132 @@ func TestPodBuild(t *testing.T) {
 				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
 			}),
 		},
+	}, {
+		desc: "init containers",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "name",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+			InitContainers: []corev1.Container{{
+				Name:    "first",
+				Image:   "first-image",
+				Command: []string{"setup"},
+			}, {
+				Name:  "second",
+				Image: "second-image",
+			}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:    "first",
+				Image:   "first-image",
+				Command: []string{"setup"},
+			}, {
+				Name:  "second",
+				Image: "second-image",
+			}, placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-name",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
+	}, {
+		desc: "sidecar container restarted on failure",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "primary-name",
+				Image:   "primary-image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{
+					Name:    "sc-name",
+					Image:   "sidecar-image",
+					Command: []string{"sidecar-cmd", "serve"}, // avoid entrypoint lookup.
+					Args:    []string{"--port", "4566"},
+				},
+				RestartPolicy: corev1.RestartPolicyOnFailure,
+			}},
+		},
+		wantAnnotations: map[string]string{},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-primary-name",
+				Image:   "primary-image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}, {
+				Name:    "sidecar-sc-name",
+				Image:   "sidecar-image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-restart_on_failure",
+					"-entrypoint",
+					"sidecar-cmd",
+					"--",
+					"serve",
+					"--port",
+					"4566",
+				},
+				VolumeMounts: []corev1.VolumeMount{toolsMount},
+				Resources: corev1.ResourceRequirements{
+					Requests: nil,
+				},
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
 	}, {
 		desc: "sidecar container with script",
 		ts: v1beta1.TaskSpec{
@@ -865,6 +1244,179 @@ script-heredoc-randomly-generated-78c5n
 				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
 			}),
 		},
+	}, {
+		desc: "stepTemplate securityContext merged into steps",
+		ts: v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot: &runAsNonRoot,
+					RunAsUser:    &runAsUser,
+				},
+			},
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:    "inherits",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}, {Container: corev1.Container{
+				Name:    "overrides",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot: &runAsRoot,
+				},
+			}}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-inherits",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot: &runAsNonRoot,
+					RunAsUser:    &runAsUser,
+				},
+			}, {
+				Name:    "step-overrides",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/tools/0",
+					"-post_file",
+					"/tekton/tools/1",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, {
+					Name:      "tekton-creds-init-home-mz4c7",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot: &runAsRoot,
+					RunAsUser:    &runAsUser,
+				},
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}, corev1.Volume{
+				Name:         "tekton-creds-init-home-mz4c7",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
+	}, {
+		desc: "step overrides",
+		ts: v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{
+				ImagePullPolicy: corev1.PullIfNotPresent,
+			},
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:            "overridden",
+				Image:           "image",
+				Command:         []string{"cmd"}, // avoid entrypoint lookup.
+				ImagePullPolicy: corev1.PullNever,
+			}}, {Container: corev1.Container{
+				Name:    "inherits",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			StepOverrides: []v1beta1.TaskRunStepOverride{{
+				Name:            "overridden",
+				ImagePullPolicy: corev1.PullAlways,
+			}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Containers: []corev1.Container{{
+				Name:    "step-overridden",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+				ImagePullPolicy:        corev1.PullAlways,
+			}, {
+				Name:    "step-inherits",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/tools/0",
+					"-post_file",
+					"/tekton/tools/1",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{toolsMount, {
+					Name:      "tekton-creds-init-home-mz4c7",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+				ImagePullPolicy:        corev1.PullIfNotPresent,
+			}},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}, corev1.Volume{
+				Name:         "tekton-creds-init-home-mz4c7",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}),
+		},
 	}, {
 		desc: "using another scheduler",
 		ts: v1beta1.TaskSpec{
@@ -1020,6 +1572,54 @@ script-heredoc-randomly-generated-78c5n
 				TerminationMessagePath: "/tekton/termination",
 			}},
 		},
+	}, {
+		desc: "projected service account token in pod template",
+		ts: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:         "sign",
+				Image:        "image",
+				Command:      []string{"cmd"}, // avoid entrypoint lookup.
+				VolumeMounts: []corev1.VolumeMount{{Name: "custom-token", MountPath: "/var/run/secrets/custom"}},
+			}}},
+		},
+		trs: v1beta1.TaskRunSpec{
+			PodTemplate: &v1beta1.PodTemplate{
+				Volumes: []corev1.Volume{customTokenVolume},
+			},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{placeToolsInit},
+			Volumes: append(implicitVolumes, toolsVolume, downwardVolume, corev1.Volume{
+				Name:         "tekton-creds-init-home-9l9zj",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+			}, customTokenVolume),
+			Containers: []corev1.Container{{
+				Name:    "step-sign",
+				Image:   "image",
+				Command: []string{"/tekton/tools/entrypoint"},
+				Args: []string{
+					"-wait_file",
+					"/tekton/downward/ready",
+					"-wait_file_content",
+					"-post_file",
+					"/tekton/tools/0",
+					"-termination_path",
+					"/tekton/termination",
+					"-entrypoint",
+					"cmd",
+					"--",
+				},
+				Env: implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{{Name: "custom-token", MountPath: "/var/run/secrets/custom"}, toolsMount, downwardMount, {
+					Name:      "tekton-creds-init-home-9l9zj",
+					MountPath: "/tekton/creds",
+				}}, implicitVolumeMounts...),
+				WorkingDir:             pipeline.WorkspaceDir,
+				Resources:              corev1.ResourceRequirements{Requests: allZeroQty()},
+				TerminationMessagePath: "/tekton/termination",
+			}},
+		},
 	}, {
 		desc: "with a propagated Affinity Assistant name - expect proper affinity",
 		ts: v1beta1.TaskSpec{
@@ -1145,6 +1745,7 @@ script-heredoc-randomly-generated-78c5n
 				KubeClient:      kubeclient,
 				EntrypointCache: entrypointCache,
 				OverrideHomeEnv: true,
+				ExternalSecrets: fakeExternalSecrets{"credentials": "synced-credentials"},
 			}
 			got, err := builder.Build(store.ToContext(context.Background()), tr, c.ts)
 			if err != nil {
@@ -1168,6 +1769,111 @@ script-heredoc-randomly-generated-78c5n
 	}
 }
 
+func TestPodBuild_InitContainerNameCollision(t *testing.T) {
+	for _, c := range []struct {
+		desc string
+		name string
+	}{{
+		desc: "reserved by Tekton",
+		name: "place-tools",
+	}, {
+		desc: "step prefix",
+		name: "step-name",
+	}, {
+		desc: "sidecar prefix",
+		name: "sidecar-name",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			names.TestingSeed()
+			kubeclient := fakek8s.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+			)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "taskrun-name",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+			}
+			ts := v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{Container: corev1.Container{
+					Name:    "name",
+					Image:   "image",
+					Command: []string{"cmd"}, // avoid entrypoint lookup.
+				}}},
+				InitContainers: []corev1.Container{{
+					Name:  c.name,
+					Image: "init-image",
+				}},
+			}
+			builder := Builder{
+				Images:          images,
+				KubeClient:      kubeclient,
+				EntrypointCache: fakeCache{},
+			}
+			if _, err := builder.Build(context.Background(), tr, ts); err == nil {
+				t.Errorf("Expected an error building a Pod with init container %q but got none", c.name)
+			}
+		})
+	}
+}
+
+func TestPodBuild_InitContainerResources(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+	)
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name", Namespace: "default"},
+	}
+	stepResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+	}
+	ts := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:      "name",
+				Image:     "image",
+				Resources: stepResources,
+			},
+			Script: "echo hello",
+		}},
+		InitContainers: []corev1.Container{{
+			Name:  "task-init",
+			Image: "init-image",
+		}},
+	}
+	initResources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("16Mi"),
+		},
+	}
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Defaults.DefaultInitContainerResources = initResources
+	builder := Builder{
+		Images:          images,
+		KubeClient:      kubeclient,
+		EntrypointCache: fakeCache{},
+	}
+	got, err := builder.Build(config.ToContext(context.Background(), cfg), tr, ts)
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	for _, c := range got.Spec.InitContainers {
+		want := *initResources
+		if c.Name == "task-init" {
+			// The init containers of the Task are left untouched.
+			want = corev1.ResourceRequirements{}
+		}
+		if d := cmp.Diff(want, c.Resources, resourceQuantityCmp); d != "" {
+			t.Errorf("Resources of init container %s %s", c.Name, diff.PrintWantGot(d))
+		}
+	}
+	if d := cmp.Diff(stepResources.Requests, got.Spec.Containers[0].Resources.Requests, resourceQuantityCmp); d != "" {
+		t.Errorf("Requests of step %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestMakeLabels(t *testing.T) {
 	taskRunName := "task-run-name"
 	want := map[string]string{
@@ -1175,7 +1881,7 @@ func TestMakeLabels(t *testing.T) {
 		"foo":           "bar",
 		"hello":         "world",
 	}
-	got := MakeLabels(&v1beta1.TaskRun{
+	got := MakeLabels(context.Background(), &v1beta1.TaskRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: taskRunName,
 			Labels: map[string]string{
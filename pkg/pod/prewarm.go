@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+// nodeNameField is the field of Nodes node affinities can match the name of a
+// node with.
+const nodeNameField = "metadata.name"
+
+// prewarmResources returns the requests and limits of the containers of the Pods
+// pulling images. They only copy or run the entrypoint binary, so that minimal
+// requests keep the Pods from counting against the ResourceQuota of the namespace
+// for more than they use, and the limits let them be created where a
+// ResourceQuota requires them.
+func prewarmResources() corev1.ResourceRequirements {
+	minimal := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("32Mi"),
+	}
+	return corev1.ResourceRequirements{Requests: minimal, Limits: minimal.DeepCopy()}
+}
+
+// PrewarmPodName returns the name of the Pod pulling the images of the Steps of
+// taskRun on the node it targets.
+func PrewarmPodName(taskRun *v1beta1.TaskRun) string {
+	return kmeta.ChildName(taskRun.Name, "-prewarm")
+}
+
+// TargetNode returns the node the Pod template of taskRun schedules its Pod on,
+// or an empty string if it may run on more than one node. The node is targeted
+// either by its hostname label in the node selector, or by a required node
+// affinity matching the hostname label or the name of a single node.
+func TargetNode(taskRun *v1beta1.TaskRun) string {
+	podTemplate := taskRun.Spec.PodTemplate
+	if podTemplate == nil {
+		return ""
+	}
+	if node := podTemplate.NodeSelector[corev1.LabelHostname]; node != "" {
+		return node
+	}
+	if podTemplate.Affinity == nil || podTemplate.Affinity.NodeAffinity == nil {
+		return ""
+	}
+	required := podTemplate.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) != 1 {
+		return ""
+	}
+	term := required.NodeSelectorTerms[0]
+	for _, r := range term.MatchExpressions {
+		if r.Key == corev1.LabelHostname && r.Operator == corev1.NodeSelectorOpIn && len(r.Values) == 1 {
+			return r.Values[0]
+		}
+	}
+	for _, r := range term.MatchFields {
+		if r.Key == nodeNameField && r.Operator == corev1.NodeSelectorOpIn && len(r.Values) == 1 {
+			return r.Values[0]
+		}
+	}
+	return ""
+}
+
+// MakePrewarmPod returns a Pod pulling images on node, ahead of the Pod of
+// taskRun. It runs one container per image, which only runs the entrypoint
+// binary without any command, so that it exits as soon as its image is pulled.
+// Images which still reference variables are skipped. The Pod is bound to node
+// directly, so its containers declare minimal requests and limits rather than
+// none, as the scheduler doesn't check it fits on the node.
+func MakePrewarmPod(images pipeline.Images, taskRun *v1beta1.TaskRun, node string, stepImages []string) *corev1.Pod {
+	podTemplate := v1beta1.PodTemplate{}
+	if taskRun.Spec.PodTemplate != nil {
+		podTemplate = *taskRun.Spec.PodTemplate
+	}
+
+	var containers []corev1.Container
+	seen := map[string]bool{}
+	for _, image := range stepImages {
+		if image == "" || seen[image] || strings.Contains(image, "$(") {
+			continue
+		}
+		seen[image] = true
+		containers = append(containers, corev1.Container{
+			Name:  fmt.Sprintf("prewarm-%d", len(containers)),
+			Image: image,
+			// The entrypoint binary can't write its termination message to /tekton,
+			// which only exists in the Pods of TaskRuns.
+			Command:      []string{entrypointBinary, "-termination_path", corev1.TerminationMessagePathDefault},
+			VolumeMounts: []corev1.VolumeMount{toolsMount},
+			Resources:    prewarmResources(),
+		})
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: taskRun.Namespace,
+			Name:      PrewarmPodName(taskRun),
+			// The Pod is deleted along with the TaskRun, if it wasn't deleted before.
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(taskRun, groupVersionKind),
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:         "place-tools",
+				Image:        images.EntrypointImage,
+				Command:      []string{"cp", "/ko-app/entrypoint", entrypointBinary},
+				VolumeMounts: []corev1.VolumeMount{toolsMount},
+				Resources:    prewarmResources(),
+			}},
+			Containers:         containers,
+			Volumes:            []corev1.Volume{toolsVolume},
+			ServiceAccountName: taskRun.Spec.ServiceAccountName,
+			ImagePullSecrets:   podTemplate.ImagePullSecrets,
+			// Binding the Pod to the node directly bypasses the scheduler, which may
+			// otherwise not schedule it before the Pod of the TaskRun.
+			NodeName:    node,
+			Tolerations: podTemplate.Tolerations,
+		},
+	}
+}
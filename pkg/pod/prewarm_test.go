@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTargetNode(t *testing.T) {
+	nodeAffinity := func(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+	for _, tc := range []struct {
+		name        string
+		podTemplate *v1beta1.PodTemplate
+		want        string
+	}{{
+		name: "no pod template",
+	}, {
+		name:        "hostname node selector",
+		podTemplate: &v1beta1.PodTemplate{NodeSelector: map[string]string{corev1.LabelHostname: "node-1", "disktype": "ssd"}},
+		want:        "node-1",
+	}, {
+		name:        "other node selector",
+		podTemplate: &v1beta1.PodTemplate{NodeSelector: map[string]string{"disktype": "ssd"}},
+	}, {
+		name: "hostname node affinity",
+		podTemplate: &v1beta1.PodTemplate{Affinity: nodeAffinity(corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-2"}}},
+		})},
+		want: "node-2",
+	}, {
+		name: "node name affinity",
+		podTemplate: &v1beta1.PodTemplate{Affinity: nodeAffinity(corev1.NodeSelectorTerm{
+			MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-3"}}},
+		})},
+		want: "node-3",
+	}, {
+		name: "affinity to several nodes",
+		podTemplate: &v1beta1.PodTemplate{Affinity: nodeAffinity(corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1", "node-2"}}},
+		})},
+	}, {
+		name: "alternative node selector terms",
+		podTemplate: &v1beta1.PodTemplate{Affinity: nodeAffinity(corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+		}, corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-2"}}},
+		})},
+	}, {
+		name: "anti-affinity",
+		podTemplate: &v1beta1.PodTemplate{Affinity: nodeAffinity(corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"node-1"}}},
+		})},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &v1beta1.TaskRun{Spec: v1beta1.TaskRunSpec{PodTemplate: tc.podTemplate}}
+			if got := TargetNode(tr); got != tc.want {
+				t.Errorf("TargetNode() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakePrewarmPod(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Spec: v1beta1.TaskRunSpec{
+			ServiceAccountName: "builder",
+			PodTemplate: &v1beta1.PodTemplate{
+				NodeSelector:     map[string]string{corev1.LabelHostname: "node-1"},
+				Tolerations:      []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+			},
+		},
+	}
+	got := MakePrewarmPod(images, tr, "node-1", []string{"golang", "$(params.image)", "alpine", "golang", ""})
+
+	command := []string{"/tekton/tools/entrypoint", "-termination_path", "/dev/termination-log"}
+	minimal := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("32Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("32Mi")},
+	}
+	want := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            "build-prewarm",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(tr, groupVersionKind)},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:         "place-tools",
+				Image:        images.EntrypointImage,
+				Command:      []string{"cp", "/ko-app/entrypoint", "/tekton/tools/entrypoint"},
+				VolumeMounts: []corev1.VolumeMount{toolsMount},
+				Resources:    minimal,
+			}},
+			Containers: []corev1.Container{
+				{Name: "prewarm-0", Image: "golang", Command: command, VolumeMounts: []corev1.VolumeMount{toolsMount}, Resources: minimal},
+				{Name: "prewarm-1", Image: "alpine", Command: command, VolumeMounts: []corev1.VolumeMount{toolsMount}, Resources: minimal},
+			},
+			Volumes:            []corev1.Volume{toolsVolume},
+			ServiceAccountName: "builder",
+			ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry"}},
+			NodeName:           "node-1",
+			Tolerations:        []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+
+	if pod := MakePrewarmPod(images, tr, "node-1", []string{"$(params.image)"}); pod != nil {
+		t.Errorf("Expected no pod without any image to pull, got %v", pod)
+	}
+}
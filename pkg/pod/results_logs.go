@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// resultsLogDelimiterFlag is the entrypoint flag making a step print its
+// results to its logs enclosed by the given delimiter.
+const resultsLogDelimiterFlag = "-results_log_delimiter"
+
+// stdoutResultsFlag is the entrypoint flag making a step set the results it
+// prints result markers for to its stdout.
+const stdoutResultsFlag = "-stdout_results"
+
+// shouldSetResultsFromStdout returns true if the steps can set results by
+// printing result markers to their stdout.
+func shouldSetResultsFromStdout(ctx context.Context) bool {
+	return config.FromContextOrDefaults(ctx).FeatureFlags.EnableStdoutResults
+}
+
+// shouldReadResultsFromLogs returns true if the results of the steps are read
+// from their logs instead of their termination message.
+func shouldReadResultsFromLogs(ctx context.Context) bool {
+	cfg := config.FromContextOrDefaults(ctx)
+	return cfg.FeatureFlags.ResultsFrom == config.ContainerLogsResultsFrom
+}
+
+// ResultsLogDelimiter returns the delimiter enclosing the results the step
+// container prints to its logs, or an empty string if its results are written
+// to its termination message.
+func ResultsLogDelimiter(c corev1.Container) string {
+	for i, arg := range c.Args {
+		if arg == "--" {
+			break
+		}
+		if arg == resultsLogDelimiterFlag && i+1 < len(c.Args) {
+			return c.Args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestPodBuild_ResultsLogDelimiter(t *testing.T) {
+	for _, c := range []struct {
+		desc          string
+		resultsFrom   string
+		results       []v1beta1.TaskResult
+		wantDelimiter bool
+	}{{
+		desc:        "results from termination message",
+		resultsFrom: config.TerminationMessageResultsFrom,
+		results:     []v1beta1.TaskResult{{Name: "foo"}},
+	}, {
+		desc:          "results from container logs",
+		resultsFrom:   config.ContainerLogsResultsFrom,
+		results:       []v1beta1.TaskResult{{Name: "foo"}},
+		wantDelimiter: true,
+	}, {
+		desc:        "no results",
+		resultsFrom: config.ContainerLogsResultsFrom,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			names.TestingSeed()
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data:       map[string]string{"results-from": c.resultsFrom},
+				},
+			)
+			kubeclient := fakek8s.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+			)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "taskrun-name",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+			}
+			ts := v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{Container: corev1.Container{
+					Name:    "first",
+					Image:   "image",
+					Command: []string{"cmd"}, // avoid entrypoint lookup.
+				}}, {Container: corev1.Container{
+					Name:    "second",
+					Image:   "image",
+					Command: []string{"cmd"},
+					Args:    []string{"-results_log_delimiter", "user-arg"},
+				}}},
+				Results: c.results,
+			}
+			builder := Builder{
+				Images:          images,
+				KubeClient:      kubeclient,
+				EntrypointCache: fakeCache{},
+			}
+			got, err := builder.Build(store.ToContext(context.Background()), tr, ts)
+			if err != nil {
+				t.Fatalf("builder.Build: %v", err)
+			}
+
+			first, second := ResultsLogDelimiter(got.Spec.Containers[0]), ResultsLogDelimiter(got.Spec.Containers[1])
+			if !c.wantDelimiter {
+				if first != "" || second != "" {
+					t.Errorf("Expected no results log delimiter but got %q and %q", first, second)
+				}
+				return
+			}
+			if first == "" {
+				t.Fatal("Expected a results log delimiter but got none")
+			}
+			if second != first {
+				t.Errorf("Expected the steps to share the results log delimiter %q but got %q", first, second)
+			}
+		})
+	}
+}
+
+func TestPodBuild_StdoutResults(t *testing.T) {
+	for _, c := range []struct {
+		desc     string
+		enabled  string
+		results  []v1beta1.TaskResult
+		wantFlag bool
+	}{{
+		desc:    "disabled",
+		enabled: "false",
+		results: []v1beta1.TaskResult{{Name: "foo"}},
+	}, {
+		desc:     "enabled",
+		enabled:  "true",
+		results:  []v1beta1.TaskResult{{Name: "foo"}},
+		wantFlag: true,
+	}, {
+		desc:    "no results",
+		enabled: "true",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			names.TestingSeed()
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data:       map[string]string{"enable-stdout-results": c.enabled},
+				},
+			)
+			kubeclient := fakek8s.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+			)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "taskrun-name",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+			}
+			ts := v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{Container: corev1.Container{
+					Name:    "name",
+					Image:   "image",
+					Command: []string{"cmd"}, // avoid entrypoint lookup.
+				}}},
+				Results: c.results,
+			}
+			builder := Builder{
+				Images:          images,
+				KubeClient:      kubeclient,
+				EntrypointCache: fakeCache{},
+			}
+			got, err := builder.Build(store.ToContext(context.Background()), tr, ts)
+			if err != nil {
+				t.Fatalf("builder.Build: %v", err)
+			}
+
+			gotFlag := false
+			for _, arg := range got.Spec.Containers[0].Args {
+				if arg == "--" {
+					break
+				}
+				if arg == stdoutResultsFlag {
+					gotFlag = true
+				}
+			}
+			if gotFlag != c.wantFlag {
+				t.Errorf("Expected the step to have the %s flag to be %t but got args %v", stdoutResultsFlag, c.wantFlag, got.Spec.Containers[0].Args)
+			}
+		})
+	}
+}
@@ -66,8 +66,8 @@ func convertScripts(shellImage string, steps []v1beta1.Step, sidecars []v1beta1.
 	sideCarSteps := []v1beta1.Step{}
 	for _, step := range sidecars {
 		sidecarStep := v1beta1.Step{
-			step.Container,
-			step.Script,
+			Container: step.Container,
+			Script:    step.Script,
 		}
 		sideCarSteps = append(sideCarSteps, sidecarStep)
 	}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// seccompContainerAnnotationPrefix is the prefix of the Pod annotations setting
+// the seccomp profile of a container, which is followed by its name. The
+// kubelet reads them as long as the seccompProfile field of containers isn't
+// available in all Kubernetes versions supported.
+const seccompContainerAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+
+// addSeccompProfileAnnotations adds the annotations setting the seccomp
+// profiles of the steps to those of the Pod, for their containers.
+func addSeccompProfileAnnotations(steps []v1beta1.Step, stepContainers []corev1.Container, annotations map[string]string) {
+	for i, s := range steps {
+		if s.SeccompProfile == nil {
+			continue
+		}
+		annotations[seccompContainerAnnotationPrefix+stepContainers[i].Name] = seccompAnnotationValue(*s.SeccompProfile)
+	}
+}
+
+// seccompAnnotationValue returns the value of the seccomp annotation of a
+// container for profile.
+func seccompAnnotationValue(profile v1beta1.SeccompProfile) string {
+	switch profile.Type {
+	case v1beta1.SeccompProfileTypeLocalhost:
+		return "localhost/" + *profile.LocalhostProfile
+	case v1beta1.SeccompProfileTypeUnconfined:
+		return "unconfined"
+	default:
+		return "runtime/default"
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAddSeccompProfileAnnotations(t *testing.T) {
+	profile := "profiles/audit.json"
+	steps := []v1beta1.Step{{
+		SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault},
+	}, {
+		SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile},
+	}, {
+		SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeUnconfined},
+	}, {}}
+	stepContainers := []corev1.Container{{Name: "step-build"}, {Name: "step-audit"}, {Name: "step-debug"}, {Name: "step-push"}}
+	annotations := map[string]string{ReleaseAnnotation: ReleaseAnnotationValue}
+	want := map[string]string{
+		ReleaseAnnotation: ReleaseAnnotationValue,
+		"container.seccomp.security.alpha.kubernetes.io/step-build": "runtime/default",
+		"container.seccomp.security.alpha.kubernetes.io/step-audit": "localhost/profiles/audit.json",
+		"container.seccomp.security.alpha.kubernetes.io/step-debug": "unconfined",
+	}
+	addSeccompProfileAnnotations(steps, stepContainers, annotations)
+	if d := cmp.Diff(want, annotations); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPodBuild_SeccompProfile(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}},
+	)
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name", Namespace: "default"},
+	}
+	ts := v1beta1.TaskSpec{
+		StepTemplate: &corev1.Container{Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:    "build",
+				Image:   "image",
+				Command: []string{"cmd"}, // avoid entrypoint lookup.
+			},
+			SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault},
+		}, {
+			Container: corev1.Container{
+				Image:   "image",
+				Command: []string{"cmd"},
+			},
+		}},
+	}
+	builder := Builder{
+		Images:          images,
+		KubeClient:      kubeclient,
+		EntrypointCache: fakeCache{},
+	}
+	got, err := builder.Build(context.Background(), tr, ts)
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	if v := got.Annotations["container.seccomp.security.alpha.kubernetes.io/step-build"]; v != "runtime/default" {
+		t.Errorf("Expected the seccomp profile of step-build to be runtime/default, got %q", v)
+	}
+	if v, ok := got.Annotations["container.seccomp.security.alpha.kubernetes.io/step-unnamed-1"]; ok {
+		t.Errorf("Expected no seccomp profile for step-unnamed-1, got %q", v)
+	}
+}
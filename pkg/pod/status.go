@@ -71,6 +71,10 @@ const (
 
 const oomKilled = "OOMKilled"
 
+// evictionReasons are the reasons of the status of a Pod terminated because of its node,
+// rather than because of its containers.
+var evictionReasons = []string{"Evicted", "NodeLost"}
+
 // SidecarsReady returns true if all of the Pod's sidecars are Ready or
 // Terminated.
 func SidecarsReady(podStatus corev1.PodStatus) bool {
@@ -104,10 +108,43 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 		MarkStatusRunning(trs, v1beta1.TaskRunReasonRunning.String(), "Not all Steps in the Task have finished executing")
 	}
 
+	// The states recorded for the steps of this Pod fill in what its containers
+	// no longer report. Those of a previous attempt, recorded for another Pod,
+	// are never reused.
+	previous := map[string]v1beta1.StepState{}
+	if trs.PodName == pod.Name {
+		for _, s := range trs.Steps {
+			previous[s.ContainerName] = s
+		}
+	}
+
 	trs.PodName = pod.Name
 	trs.Steps = []v1beta1.StepState{}
 	trs.Sidecars = []v1beta1.SidecarState{}
 
+	// Map steps and sidecars to their container from the Pod spec, so the mapping
+	// is available before the containers report any status.
+	stepIndex := map[string]int{}
+	sidecarIndex := map[string]int{}
+	for _, c := range pod.Spec.Containers {
+		if IsContainerStep(c.Name) {
+			prev := previous[c.Name]
+			stepIndex[c.Name] = len(trs.Steps)
+			trs.Steps = append(trs.Steps, v1beta1.StepState{
+				ContainerState: *prev.ContainerState.DeepCopy(),
+				Name:           trimStepPrefix(c.Name),
+				ContainerName:  c.Name,
+				ImageID:        prev.ImageID,
+			})
+		} else if isContainerSidecar(c.Name) {
+			sidecarIndex[c.Name] = len(trs.Sidecars)
+			trs.Sidecars = append(trs.Sidecars, v1beta1.SidecarState{
+				Name:          TrimSidecarPrefix(c.Name),
+				ContainerName: c.Name,
+			})
+		}
+	}
+
 	for _, s := range pod.Status.ContainerStatuses {
 		if IsContainerStep(s.Name) {
 			if s.State.Terminated != nil && len(s.State.Terminated.Message) != 0 {
@@ -120,24 +157,39 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 					s.State.Terminated.Message = message
 				}
 			}
-			trs.Steps = append(trs.Steps, v1beta1.StepState{
+			state := v1beta1.StepState{
 				ContainerState: *s.State.DeepCopy(),
 				Name:           trimStepPrefix(s.Name),
 				ContainerName:  s.Name,
 				ImageID:        s.ImageID,
-			})
+			}
+			if state.ImageID == "" {
+				// The container may stop reporting its image, e.g. once the
+				// Pod is evicted.
+				state.ImageID = previous[s.Name].ImageID
+			}
+			if i, ok := stepIndex[s.Name]; ok {
+				trs.Steps[i] = state
+			} else {
+				trs.Steps = append(trs.Steps, state)
+			}
 		} else if isContainerSidecar(s.Name) {
-			trs.Sidecars = append(trs.Sidecars, v1beta1.SidecarState{
+			state := v1beta1.SidecarState{
 				ContainerState: *s.State.DeepCopy(),
 				Name:           TrimSidecarPrefix(s.Name),
 				ContainerName:  s.Name,
 				ImageID:        s.ImageID,
-			})
+			}
+			if i, ok := sidecarIndex[s.Name]; ok {
+				trs.Sidecars[i] = state
+			} else {
+				trs.Sidecars = append(trs.Sidecars, state)
+			}
 		}
 	}
 
 	// Complete if we did not find a step that is not complete, or the pod is in a definitely complete phase
-	complete := areStepsComplete(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+	complete := areStepsComplete(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || IsPodEvicted(pod)
 
 	if complete {
 		updateCompletedTaskRun(trs, pod)
@@ -183,10 +235,21 @@ func removeStartInfoFromTerminationMessage(s corev1.ContainerStatus) (string, *m
 }
 
 func updateCompletedTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
-	if DidTaskRunFail(pod) {
+	switch {
+	case IsPodEvicted(pod) && !areStepsComplete(pod):
+		markStatusEvicted(trs, pod)
+	case DidTaskRunFail(pod):
+		if msg, ok := getResultExtractionError(pod); ok {
+			markStatusResultExtractionFailed(trs, msg)
+			break
+		}
+		if msg, ok := getStepTimeoutExceeded(pod); ok {
+			markStatusStepTimedOut(trs, msg)
+			break
+		}
 		msg := getFailureMessage(pod)
 		MarkStatusFailure(trs, msg)
-	} else {
+	default:
 		MarkStatusSuccess(trs)
 	}
 
@@ -197,7 +260,7 @@ func updateCompletedTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
 func updateIncompleteTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
 	switch pod.Status.Phase {
 	case corev1.PodRunning:
-		MarkStatusRunning(trs, v1beta1.TaskRunReasonRunning.String(), "Not all Steps in the Task have finished executing")
+		MarkStatusRunning(trs, v1beta1.TaskRunReasonRunning.String(), stepsProgressMessage(trs.Steps))
 	case corev1.PodPending:
 		var reason, msg string
 		switch {
@@ -215,6 +278,26 @@ func updateIncompleteTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
 	}
 }
 
+// stepsProgressMessage returns the message of the condition of a running
+// TaskRun, which counts its steps by state, the steps which haven't started
+// nor finished yet being waiting.
+func stepsProgressMessage(steps []v1beta1.StepState) string {
+	completed, failed, running := 0, 0, 0
+	for _, s := range steps {
+		switch {
+		case s.Terminated != nil:
+			completed++
+			if s.Terminated.ExitCode != 0 {
+				failed++
+			}
+		case s.Running != nil:
+			running++
+		}
+	}
+	return fmt.Sprintf("Not all Steps in the Task have finished executing: Steps Completed: %d (Failed: %d), Running: %d, Waiting: %d",
+		completed, failed, running, len(steps)-completed-running)
+}
+
 // DidTaskRunFail check the status of pod to decide if related taskrun is failed
 func DidTaskRunFail(pod *corev1.Pod) bool {
 	f := pod.Status.Phase == corev1.PodFailed
@@ -261,6 +344,49 @@ func SortContainerStatuses(podInstance *corev1.Pod) {
 
 }
 
+// getResultExtractionError returns the reason written to the termination message of
+// a step by the entrypoint when the value of a result couldn't be extracted with its
+// JSONPath.
+func getResultExtractionError(pod *corev1.Pod) (string, bool) {
+	step, value, ok := getInternalTektonResult(pod, v1beta1.ResultExtractionErrorKey)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%q failed: %s", step, value), true
+}
+
+// getStepTimeoutExceeded returns the reason written to the termination message of
+// a step by the entrypoint when the step was stopped after running for its share
+// of the timeout of the TaskRun.
+func getStepTimeoutExceeded(pod *corev1.Pod) (string, bool) {
+	step, value, ok := getInternalTektonResult(pod, v1beta1.StepTimeoutExceededKey)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%q exceeded its timeout of %s", step, value), true
+}
+
+// getInternalTektonResult returns the name of the first step whose termination
+// message holds the InternalTektonResultType value with the given key, and that
+// value.
+func getInternalTektonResult(pod *corev1.Pod, key string) (string, string, bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if !IsContainerStep(s.Name) || s.State.Terminated == nil || s.State.Terminated.Message == "" {
+			continue
+		}
+		results, err := termination.ParseMessage(s.State.Terminated.Message)
+		if err != nil {
+			continue
+		}
+		for _, r := range results {
+			if r.ResultType == v1beta1.InternalTektonResultType && r.Key == key {
+				return s.Name, r.Value, true
+			}
+		}
+	}
+	return "", "", false
+}
+
 func getFailureMessage(pod *corev1.Pod) string {
 	SortContainerStatuses(pod)
 	// First, try to surface an error about the actual build step that failed.
@@ -303,6 +429,17 @@ func IsPodExceedingNodeResources(pod *corev1.Pod) bool {
 	return false
 }
 
+// IsPodEvicted returns true if the Pod's status indicates it was evicted from its
+// node, or its node was lost.
+func IsPodEvicted(pod *corev1.Pod) bool {
+	for _, r := range evictionReasons {
+		if pod.Status.Reason == r {
+			return true
+		}
+	}
+	return false
+}
+
 // IsPodHitConfigError returns true if the Pod's status undicates there are config error raised
 func IsPodHitConfigError(pod *corev1.Pod) bool {
 	for _, containerStatus := range pod.Status.ContainerStatuses {
@@ -360,6 +497,54 @@ func MarkStatusFailure(trs *v1beta1.TaskRunStatus, message string) {
 	})
 }
 
+// markStatusResultExtractionFailed sets taskrun status to failure because the
+// value of a result couldn't be extracted with its JSONPath.
+func markStatusResultExtractionFailed(trs *v1beta1.TaskRunStatus, message string) {
+	trs.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1beta1.TaskRunReasonResultExtractionFailed.String(),
+		Message: message,
+	})
+}
+
+// markStatusStepTimedOut sets taskrun status to failure because a step ran for
+// longer than its share of the timeout of the TaskRun.
+func markStatusStepTimedOut(trs *v1beta1.TaskRunStatus, message string) {
+	trs.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1beta1.TaskRunReasonStepTimedOut.String(),
+		Message: message,
+	})
+}
+
+// markStatusEvicted sets taskrun status to failure because its pod was evicted. The
+// steps which didn't finish are terminated with the eviction reason, since their
+// containers won't report their status anymore.
+func markStatusEvicted(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
+	trs.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1beta1.TaskRunReasonEvicted.String(),
+		Message: fmt.Sprintf("TaskRun Pod %q was evicted from node %q (%s): %s", pod.Name, pod.Spec.NodeName, pod.Status.Reason, pod.Status.Message),
+	})
+	for i, s := range trs.Steps {
+		if s.Terminated != nil {
+			continue
+		}
+		var startedAt metav1.Time
+		if s.Running != nil {
+			startedAt = s.Running.StartedAt
+		}
+		trs.Steps[i].ContainerState = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode:  1,
+			StartedAt: startedAt,
+			Reason:    pod.Status.Reason,
+		}}
+	}
+}
+
 // MarkStatusSuccess sets taskrun status to success
 func MarkStatusSuccess(trs *v1beta1.TaskRunStatus) {
 	trs.SetCondition(&apis.Condition{
@@ -371,65 +556,37 @@ func MarkStatusSuccess(trs *v1beta1.TaskRunStatus) {
 }
 
 // sortTaskRunStepOrder sorts the StepStates in the same order as the original
-// TaskSpec steps.
+// TaskSpec steps, the iterations of a step with a forEach taking its place. The
+// states of containers which aren't steps of the TaskSpec are kept last, in
+// their original order.
 func sortTaskRunStepOrder(taskRunSteps []v1beta1.StepState, taskSpecSteps []v1beta1.Step) []v1beta1.StepState {
-	trt := &stepStateSorter{
-		taskRunSteps: taskRunSteps,
-	}
-	trt.mapForSort = trt.constructTaskStepsSorter(taskSpecSteps)
-	sort.Sort(trt)
-	return trt.taskRunSteps
-}
-
-// stepStateSorter implements a sorting mechanism to align the order of the steps in TaskRun
-// with the spec steps in Task.
-type stepStateSorter struct {
-	taskRunSteps []v1beta1.StepState
-	mapForSort   map[string]int
-}
-
-// constructTaskStepsSorter constructs a map matching the names of
-// the steps to their indices for a task.
-func (trt *stepStateSorter) constructTaskStepsSorter(taskSpecSteps []v1beta1.Step) map[string]int {
-	sorter := make(map[string]int)
+	order := make(map[string]int, len(taskSpecSteps))
+	forEachOrder := map[string]int{}
 	for index, step := range taskSpecSteps {
 		stepName := step.Name
 		if stepName == "" {
 			stepName = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("unnamed-%d", index))
 		}
-		sorter[stepName] = index
-	}
-	return sorter
-}
-
-// changeIndex sorts the steps of the task run, based on the
-// order of the steps in the task. Instead of changing the element with the one next to it,
-// we directly swap it with the desired index.
-func (trt *stepStateSorter) changeIndex(index int) {
-	// Check if the current index is equal to the desired index. If they are equal, do not swap; if they
-	// are not equal, swap index j with the desired index.
-	desiredIndex, exist := trt.mapForSort[trt.taskRunSteps[index].Name]
-	if exist && index != desiredIndex {
-		trt.taskRunSteps[desiredIndex], trt.taskRunSteps[index] = trt.taskRunSteps[index], trt.taskRunSteps[desiredIndex]
+		order[stepName] = index
+		if step.ForEach != nil {
+			forEachOrder[stepName] = index
+		}
 	}
-}
-
-func (trt *stepStateSorter) Len() int { return len(trt.taskRunSteps) }
-
-func (trt *stepStateSorter) Swap(i, j int) {
-	trt.changeIndex(j)
-	// The index j is unable to reach the last index.
-	// When i reaches the end of the array, we need to check whether the last one needs a swap.
-	if i == trt.Len()-1 {
-		trt.changeIndex(i)
+	position := func(s v1beta1.StepState) int {
+		if index, ok := order[s.Name]; ok {
+			return index
+		}
+		if i := strings.LastIndex(s.Name, "-"); i > 0 {
+			if index, ok := forEachOrder[s.Name[:i]]; ok {
+				return index
+			}
+		}
+		return len(taskSpecSteps)
 	}
-}
-
-func (trt *stepStateSorter) Less(i, j int) bool {
-	// Since the logic is complicated, we move it into the Swap function to decide whether
-	// and how to change the index. We set it to true here in order to iterate all the
-	// elements of the array in the Swap function.
-	return true
+	sort.SliceStable(taskRunSteps, func(i, j int) bool {
+		return position(taskRunSteps[i]) < position(taskRunSteps[j])
+	})
+	return taskRunSteps
 }
 
 func isOOMKilled(s corev1.ContainerStatus) bool {
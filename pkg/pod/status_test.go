@@ -40,8 +40,13 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		Reason:  v1beta1.TaskRunReasonRunning.String(),
 		Message: "Not all Steps in the Task have finished executing",
 	}
+	conditionStepRunning := conditionRunning
+	conditionStepRunning.Message = "Not all Steps in the Task have finished executing: Steps Completed: 0 (Failed: 0), Running: 1, Waiting: 0"
+	conditionStepWaiting := conditionRunning
+	conditionStepWaiting.Message = "Not all Steps in the Task have finished executing: Steps Completed: 0 (Failed: 0), Running: 0, Waiting: 1"
 	for _, c := range []struct {
 		desc      string
+		podSpec   corev1.PodSpec
 		podStatus corev1.PodStatus
 		taskSpec  v1beta1.TaskSpec
 		want      v1beta1.TaskRunStatus
@@ -179,7 +184,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		},
 		want: v1beta1.TaskRunStatus{
 			Status: duckv1beta1.Status{
-				Conditions: []apis.Condition{conditionRunning},
+				Conditions: []apis.Condition{conditionStepRunning},
 			},
 			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
 				Steps: []v1beta1.StepState{{
@@ -235,6 +240,86 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc: "failure-result-extraction",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-digest",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"ResultExtractionError","value":"failed to extract result \"digest\": invalid JSON: unexpected EOF","type":"InternalTektonResult"}]`,
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonResultExtractionFailed.String(),
+					Message: `"step-digest" failed: failed to extract result "digest": invalid JSON: unexpected EOF`,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Message:  `[{"key":"ResultExtractionError","value":"failed to extract result \"digest\": invalid JSON: unexpected EOF","type":"InternalTektonResult"}]`,
+						}},
+					Name:          "digest",
+					ContainerName: "step-digest",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "failure-step-timeout",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-build",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"StepTimeoutExceeded","value":"25m0s","type":"InternalTektonResult"}]`,
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonStepTimedOut.String(),
+					Message: `"step-build" exceeded its timeout of 25m0s`,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Message:  `[{"key":"StepTimeoutExceeded","value":"25m0s","type":"InternalTektonResult"}]`,
+						}},
+					Name:          "build",
+					ContainerName: "step-build",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
 	}, {
 		desc: "failure-message",
 		podStatus: corev1.PodStatus{
@@ -257,6 +342,96 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc:    "evicted",
+		podSpec: corev1.PodSpec{NodeName: "node"},
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "Evicted",
+			Message: "The node was low on resource: memory.",
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-first",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{},
+				},
+			}, {
+				Name: "step-second",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: metav1.Time{Time: time.Date(2010, 1, 1, 1, 1, 1, 1, time.UTC)}},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonEvicted.String(),
+					Message: `TaskRun Pod "pod" was evicted from node "node" (Evicted): The node was low on resource: memory.`,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{},
+					},
+					Name:          "first",
+					ContainerName: "step-first",
+				}, {
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:  1,
+							StartedAt: metav1.Time{Time: time.Date(2010, 1, 1, 1, 1, 1, 1, time.UTC)},
+							Reason:    "Evicted",
+						},
+					},
+					Name:          "second",
+					ContainerName: "step-second",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc:    "node-lost",
+		podSpec: corev1.PodSpec{NodeName: "node"},
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodRunning,
+			Reason:  "NodeLost",
+			Message: "Node node which was running pod pod is unresponsive",
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-first",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonEvicted.String(),
+					Message: `TaskRun Pod "pod" was evicted from node "node" (NodeLost): Node node which was running pod pod is unresponsive`,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Reason:   "NodeLost",
+						},
+					},
+					Name:          "first",
+					ContainerName: "step-first",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
 	}, {
 		desc: "failed with OOM",
 		podStatus: corev1.PodStatus{
@@ -415,6 +590,56 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 			},
 		},
+	}, {
+		desc: "pending-containers-mapped-from-spec",
+		podSpec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "place-tools"}},
+			Containers: []corev1.Container{{
+				Name: "step-first",
+			}, {
+				Name: "step-second",
+			}, {
+				Name: "sidecar-sc",
+			}},
+		},
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "step-first",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+			}},
+		},
+		taskSpec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name: "first",
+			}}, {Container: corev1.Container{
+				Name: "second",
+			}}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionUnknown,
+					Reason:  "Pending",
+					Message: "Pending",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+					Name:           "first",
+					ContainerName:  "step-first",
+				}, {
+					Name:          "second",
+					ContainerName: "step-second",
+				}},
+				Sidecars: []v1beta1.SidecarState{{
+					Name:          "sc",
+					ContainerName: "sidecar-sc",
+				}},
+			},
+		},
 	}, {
 		desc: "pending-not-enough-node-resources",
 		podStatus: corev1.PodStatus{
@@ -484,7 +709,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		},
 		want: v1beta1.TaskRunStatus{
 			Status: duckv1beta1.Status{
-				Conditions: []apis.Condition{conditionRunning},
+				Conditions: []apis.Condition{conditionStepRunning},
 			},
 			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
 				Steps: []v1beta1.StepState{{
@@ -530,7 +755,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		},
 		want: v1beta1.TaskRunStatus{
 			Status: duckv1beta1.Status{
-				Conditions: []apis.Condition{conditionRunning},
+				Conditions: []apis.Condition{conditionStepWaiting},
 			},
 			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
 				Steps: []v1beta1.StepState{{
@@ -580,7 +805,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		},
 		want: v1beta1.TaskRunStatus{
 			Status: duckv1beta1.Status{
-				Conditions: []apis.Condition{conditionRunning},
+				Conditions: []apis.Condition{conditionStepRunning},
 			},
 			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
 				Steps: []v1beta1.StepState{{
@@ -701,6 +926,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 					Namespace:         "foo",
 					CreationTimestamp: now,
 				},
+				Spec:   c.podSpec,
 				Status: c.podStatus,
 			}
 			startTime := time.Date(2010, 1, 1, 1, 1, 1, 1, time.UTC)
@@ -739,6 +965,106 @@ func TestMakeTaskRunStatus(t *testing.T) {
 	}
 }
 
+func TestMakeTaskRunStatusStepStates(t *testing.T) {
+	start := metav1.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	end := metav1.Date(2020, time.October, 1, 12, 1, 0, 0, time.UTC)
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{StartedAt: start, FinishedAt: end}}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: start}}
+	taskSpec := v1beta1.TaskSpec{Steps: []v1beta1.Step{
+		{Container: corev1.Container{Name: "first"}},
+		{Container: corev1.Container{Name: "second"}},
+		{Container: corev1.Container{Name: "third"}},
+	}}
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "step-third"}, {Name: "step-first"}, {Name: "step-second"},
+	}}
+	for _, c := range []struct {
+		desc      string
+		podName   string
+		previous  []v1beta1.StepState
+		podStatus corev1.PodStatus
+		want      []v1beta1.StepState
+	}{{
+		desc:    "out of order container statuses",
+		podName: "pod",
+		podStatus: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "step-second", ImageID: "second-image", State: running},
+			{Name: "step-third", ImageID: "third-image"},
+			{Name: "step-first", ImageID: "first-image", State: terminated},
+		}},
+		want: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: terminated},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image", ContainerState: running},
+			{Name: "third", ContainerName: "step-third", ImageID: "third-image"},
+		},
+	}, {
+		desc:    "missing image ids of the same pod",
+		podName: "pod",
+		previous: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: running},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image"},
+		},
+		podStatus: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "step-second"},
+			{Name: "step-first", State: terminated},
+		}},
+		want: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: terminated},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image"},
+			{Name: "third", ContainerName: "step-third"},
+		},
+	}, {
+		desc:    "containers of the same pod no longer reporting",
+		podName: "pod",
+		previous: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: terminated},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image", ContainerState: running},
+		},
+		podStatus: corev1.PodStatus{Phase: corev1.PodRunning},
+		want: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: terminated},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image", ContainerState: running},
+			{Name: "third", ContainerName: "step-third"},
+		},
+	}, {
+		desc:    "states of a previous attempt",
+		podName: "previous-attempt-pod",
+		previous: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "first-image", ContainerState: terminated},
+			{Name: "second", ContainerName: "step-second", ImageID: "second-image", ContainerState: terminated},
+		},
+		podStatus: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "step-first", ImageID: "new-first-image", State: running},
+		}},
+		want: []v1beta1.StepState{
+			{Name: "first", ContainerName: "step-first", ImageID: "new-first-image", ContainerState: running},
+			{Name: "second", ContainerName: "step-second"},
+			{Name: "third", ContainerName: "step-third"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "foo"},
+				Spec:       podSpec,
+				Status:     c.podStatus,
+			}
+			tr := v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "task-run", Namespace: "foo"},
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName: c.podName,
+					Steps:   c.previous,
+				}},
+			}
+
+			logger, _ := logging.NewLogger("", "status")
+			got := MakeTaskRunStatus(logger, tr, pod, taskSpec)
+			if d := cmp.Diff(c.want, got.Steps); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestSidecarsReady(t *testing.T) {
 	for _, c := range []struct {
 		desc     string
@@ -884,6 +1210,27 @@ func TestSortTaskRunStepOrder(t *testing.T) {
 	}
 }
 
+func TestSortTaskRunStepOrder_ForEach(t *testing.T) {
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "build"},
+	}, {
+		Container: corev1.Container{Name: "test"},
+		ForEach:   &v1beta1.StepForEach{ParamName: "targets"},
+	}, {
+		Container: corev1.Container{Name: "publish"},
+	}}
+	stepStates := []v1beta1.StepState{{Name: "publish"}, {Name: "test-0"}, {Name: "build"}, {Name: "test-1"}}
+
+	var gotNames []string
+	for _, g := range sortTaskRunStepOrder(stepStates, steps) {
+		gotNames = append(gotNames, g.Name)
+	}
+	want := []string{"build", "test-0", "test-1", "publish"}
+	if d := cmp.Diff(want, gotNames); d != "" {
+		t.Errorf("Unexpected step order %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestSortContainerStatuses(t *testing.T) {
 	samplePod := corev1.Pod{
 		Status: corev1.PodStatus{
@@ -971,3 +1318,37 @@ func TestMarkStatusSuccess(t *testing.T) {
 		t.Errorf("Unexpected status: %s", diff.PrintWantGot(d))
 	}
 }
+
+func TestStepsProgressMessage(t *testing.T) {
+	terminated := func(exitCode int32) v1beta1.StepState {
+		return v1beta1.StepState{ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}}
+	}
+	running := v1beta1.StepState{ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+	waiting := v1beta1.StepState{ContainerState: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}}
+	for _, c := range []struct {
+		desc  string
+		steps []v1beta1.StepState
+		want  string
+	}{{
+		desc: "no steps",
+		want: "Not all Steps in the Task have finished executing: Steps Completed: 0 (Failed: 0), Running: 0, Waiting: 0",
+	}, {
+		desc:  "not started",
+		steps: []v1beta1.StepState{waiting, {}},
+		want:  "Not all Steps in the Task have finished executing: Steps Completed: 0 (Failed: 0), Running: 0, Waiting: 2",
+	}, {
+		desc:  "in progress",
+		steps: []v1beta1.StepState{terminated(0), terminated(0), running, waiting},
+		want:  "Not all Steps in the Task have finished executing: Steps Completed: 2 (Failed: 0), Running: 1, Waiting: 1",
+	}, {
+		desc:  "with failure",
+		steps: []v1beta1.StepState{terminated(0), terminated(1), terminated(0), waiting},
+		want:  "Not all Steps in the Task have finished executing: Steps Completed: 3 (Failed: 1), Running: 0, Waiting: 1",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := stepsProgressMessage(c.steps); got != c.want {
+				t.Errorf("stepsProgressMessage() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
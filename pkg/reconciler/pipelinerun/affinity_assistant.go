@@ -55,10 +55,10 @@ func (c *Reconciler) createAffinityAssistants(ctx context.Context, wb []v1alpha1
 		if w.PersistentVolumeClaim != nil || w.VolumeClaimTemplate != nil {
 			affinityAssistantName := getAffinityAssistantName(w.Name, pr.Name)
 			_, err := c.KubeClientSet.AppsV1().StatefulSets(namespace).Get(affinityAssistantName, metav1.GetOptions{})
-			claimName := getClaimName(w, pr.GetOwnerReference())
+			claimName := getClaimName(w, claimOwnerReference(pr))
 			switch {
 			case apierrors.IsNotFound(err):
-				affinityAssistantStatefulSet := affinityAssistantStatefulSet(affinityAssistantName, pr, claimName, c.Images.NopImage)
+				affinityAssistantStatefulSet := affinityAssistantStatefulSet(ctx, affinityAssistantName, pr, claimName, c.Images.NopImage)
 				_, err := c.KubeClientSet.AppsV1().StatefulSets(namespace).Create(affinityAssistantStatefulSet)
 				if err != nil {
 					errs = append(errs, fmt.Errorf("failed to create StatefulSet %s: %s", affinityAssistantName, err))
@@ -85,6 +85,10 @@ func getClaimName(w v1beta1.WorkspaceBinding, ownerReference metav1.OwnerReferen
 }
 
 func (c *Reconciler) cleanupAffinityAssistants(pr *v1beta1.PipelineRun) error {
+	if pr.Spec.IsolatedNamespace {
+		// The StatefulSets are deleted with the isolated namespace.
+		return nil
+	}
 	var errs []error
 	for _, w := range pr.Spec.Workspaces {
 		if w.PersistentVolumeClaim != nil || w.VolumeClaimTemplate != nil {
@@ -118,7 +122,7 @@ func getStatefulSetLabels(pr *v1beta1.PipelineRun, affinityAssistantName string)
 	return labels
 }
 
-func affinityAssistantStatefulSet(name string, pr *v1beta1.PipelineRun, claimName string, affinityAssistantImage string) *appsv1.StatefulSet {
+func affinityAssistantStatefulSet(ctx context.Context, name string, pr *v1beta1.PipelineRun, claimName string, affinityAssistantImage string) *appsv1.StatefulSet {
 	// We want a singleton pod
 	replicas := int32(1)
 
@@ -153,6 +157,10 @@ func affinityAssistantStatefulSet(name string, pr *v1beta1.PipelineRun, claimNam
 			},
 		},
 	}}
+	// The resources of the containers running the nop image can be overridden in config-defaults.
+	if resources := config.FromContextOrDefaults(ctx).Defaults.DefaultNopContainerResources; resources != nil {
+		containers[0].Resources = *resources.DeepCopy()
+	}
 
 	// use podAntiAffinity to repel other affinity assistants
 	repelOtherAffinityAssistantsPodAffinityTerm := corev1.WeightedPodAffinityTerm{
@@ -175,7 +183,7 @@ func affinityAssistantStatefulSet(name string, pr *v1beta1.PipelineRun, claimNam
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            name,
 			Labels:          getStatefulSetLabels(pr, name),
-			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
+			OwnerReferences: ownerReferences(pr),
 		},
 		Spec: appsv1.StatefulSetSpec{
 			Replicas: &replicas,
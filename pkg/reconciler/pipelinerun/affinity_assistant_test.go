@@ -25,7 +25,9 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/system"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakek8s "k8s.io/client-go/kubernetes/fake"
 	logtesting "knative.dev/pkg/logging/testing"
@@ -99,7 +101,7 @@ func TestThatCustomTolerationsAndNodeSelectorArePropagatedToAffinityAssistant(t
 		},
 	}
 
-	stsWithTolerationsAndNodeSelector := affinityAssistantStatefulSet("test-assistant", prWithCustomPodTemplate, "mypvc", "nginx")
+	stsWithTolerationsAndNodeSelector := affinityAssistantStatefulSet(context.Background(), "test-assistant", prWithCustomPodTemplate, "mypvc", "nginx")
 
 	if len(stsWithTolerationsAndNodeSelector.Spec.Template.Spec.Tolerations) != 1 {
 		t.Errorf("expected Tolerations in the StatefulSet")
@@ -119,7 +121,7 @@ func TestThatTheAffinityAssistantIsWithoutNodeSelectorAndTolerations(t *testing.
 		Spec: v1beta1.PipelineRunSpec{},
 	}
 
-	stsWithoutTolerationsAndNodeSelector := affinityAssistantStatefulSet("test-assistant", prWithoutCustomPodTemplate, "mypvc", "nginx")
+	stsWithoutTolerationsAndNodeSelector := affinityAssistantStatefulSet(context.Background(), "test-assistant", prWithoutCustomPodTemplate, "mypvc", "nginx")
 
 	if len(stsWithoutTolerationsAndNodeSelector.Spec.Template.Spec.Tolerations) != 0 {
 		t.Errorf("unexpected Tolerations in the StatefulSet")
@@ -130,6 +132,28 @@ func TestThatTheAffinityAssistantIsWithoutNodeSelectorAndTolerations(t *testing.
 	}
 }
 
+func TestThatTheAffinityAssistantUsesTheDefaultNopContainerResources(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		TypeMeta:   metav1.TypeMeta{Kind: "PipelineRun"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun"},
+	}
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("16Mi"),
+		},
+	}
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Defaults.DefaultNopContainerResources = resources
+	ctx := config.ToContext(context.Background(), cfg)
+
+	sts := affinityAssistantStatefulSet(ctx, "test-assistant", pr, "mypvc", "nginx")
+
+	if got := sts.Spec.Template.Spec.Containers[0].Resources; !equality.Semantic.DeepEqual(got, *resources) {
+		t.Errorf("expected the resources %v in the StatefulSet but got %v", *resources, got)
+	}
+}
+
 // TestThatAffinityAssistantNameIsNoLongerThan53 tests that the Affinity Assistant Name
 // is no longer than 53 chars. This is a limitation with StatefulSet.
 // See https://github.com/kubernetes/kubernetes/issues/64023
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// bundleCache holds the Tasks resolved from bundles for each PipelineRun, so that
+// bundles are fetched once per PipelineRun instead of on every reconcile.
+type bundleCache struct {
+	mu sync.Mutex
+	// tasks maps the UID of a PipelineRun to its Tasks, indexed by bundle, kind and name.
+	tasks map[types.UID]map[string]v1beta1.TaskInterface
+}
+
+func newBundleCache() *bundleCache {
+	return &bundleCache{tasks: map[types.UID]map[string]v1beta1.TaskInterface{}}
+}
+
+func bundleTaskKey(ref *v1beta1.TaskRef) string {
+	return fmt.Sprintf("%s/%s/%s", ref.Bundle, ref.Kind, ref.Name)
+}
+
+func (b *bundleCache) get(pr *v1beta1.PipelineRun, ref *v1beta1.TaskRef) (v1beta1.TaskInterface, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	task, ok := b.tasks[pr.UID][bundleTaskKey(ref)]
+	return task, ok
+}
+
+func (b *bundleCache) add(pr *v1beta1.PipelineRun, ref *v1beta1.TaskRef, task v1beta1.TaskInterface) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tasks[pr.UID] == nil {
+		b.tasks[pr.UID] = map[string]v1beta1.TaskInterface{}
+	}
+	b.tasks[pr.UID][bundleTaskKey(ref)] = task
+}
+
+// release forgets the Tasks resolved for pr, once it is done or deleted.
+func (b *bundleCache) release(pr *v1beta1.PipelineRun) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tasks, pr.UID)
+}
+
+// getBundleTaskFunc returns the function retrieving the Tasks pr references from bundles. The
+// bundles are fetched with the image pull secrets of the service account of pr, and the Tasks
+// resolved from them are cached until pr is done or deleted.
+func (c *Reconciler) getBundleTaskFunc(pr *v1beta1.PipelineRun) resources.GetBundleTask {
+	return func(ref *v1beta1.TaskRef) (v1beta1.TaskInterface, error) {
+		if task, ok := c.bundles.get(pr, ref); ok {
+			return task, nil
+		}
+		kc, err := k8schain.New(c.KubeClientSet, k8schain.Options{
+			Namespace:          pr.Namespace,
+			ServiceAccountName: pr.Spec.ServiceAccountName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating k8schain: %w", err)
+		}
+		resolver := &resources.BundleTaskRefResolver{Keychain: kc}
+		task, err := resolver.GetTask(ref)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving bundle %s: %w", ref.Bundle, err)
+		}
+		c.bundles.add(pr, ref, task)
+		return task, nil
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestFinalizeKindReleasesBundles(t *testing.T) {
+	// TestFinalizeKindReleasesBundles verifies that the Tasks resolved from bundles for a
+	// PipelineRun are forgotten when it is deleted before it is done.
+	c := &Reconciler{
+		bundles:     newBundleCache(),
+		checkpoints: newCheckpointTracker(clock.NewFakeClock(time.Now())),
+	}
+	pr := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo", UID: "build-uid"}}
+	other := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "foo", UID: "test-uid"}}
+	ref := &v1beta1.TaskRef{Name: "build", Kind: v1beta1.NamespacedTaskKind, Bundle: "gcr.io/foo/bundle:latest"}
+	task := &v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "build"}}
+	c.bundles.add(pr, ref, task)
+	c.bundles.add(other, ref, task)
+
+	if err := c.FinalizeKind(context.Background(), pr); err != nil {
+		t.Fatalf("Error finalizing: %v", err)
+	}
+	if _, ok := c.bundles.get(pr, ref); ok {
+		t.Error("Expected the Tasks of the deleted PipelineRun to be released")
+	}
+	if _, ok := c.bundles.get(other, ref); !ok {
+		t.Error("Expected the Tasks of other PipelineRuns to be kept")
+	}
+}
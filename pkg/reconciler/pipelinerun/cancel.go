@@ -25,6 +25,7 @@ import (
 	"go.uber.org/zap"
 	jsonpatch "gomodules.xyz/jsonpatch/v2"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	corev1 "k8s.io/api/core/v1"
@@ -33,13 +34,13 @@ import (
 	"knative.dev/pkg/apis"
 )
 
-// cancelPipelineRun marks the PipelineRun as cancelled and any resolved TaskRun(s) too.
+// cancelPipelineRun marks the PipelineRun as cancelled and any resolved TaskRun(s) and Run(s) too.
 func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface) error {
 	errs := []string{}
 
 	// Use Patch to update the TaskRuns since the TaskRun controller may be operating on the
 	// TaskRuns at the same time and trying to update the entire object may cause a race
-	b, err := getCancelPatch()
+	b, err := getCancelPatch(v1beta1.TaskRunSpecStatusCancelled)
 	if err != nil {
 		return fmt.Errorf("couldn't make patch to update TaskRun cancellation: %v", err)
 	}
@@ -49,11 +50,12 @@ func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clien
 	for taskRunName := range pr.Status.TaskRuns {
 		logger.Infof("cancelling TaskRun %s", taskRunName)
 
-		if _, err := clientSet.TektonV1beta1().TaskRuns(pr.Namespace).Patch(taskRunName, types.JSONPatchType, b, ""); err != nil {
+		if _, err := clientSet.TektonV1beta1().TaskRuns(runNamespace(pr)).Patch(taskRunName, types.JSONPatchType, b, ""); err != nil {
 			errs = append(errs, fmt.Errorf("Failed to patch TaskRun `%s` with cancellation: %s", taskRunName, err).Error())
 			continue
 		}
 	}
+	errs = append(errs, cancelRuns(logger, pr, clientSet)...)
 	// If we successfully cancelled all the TaskRuns, we can consider the PipelineRun cancelled.
 	if len(errs) == 0 {
 		pr.Status.SetCondition(&apis.Condition{
@@ -78,11 +80,29 @@ func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clien
 	return nil
 }
 
-func getCancelPatch() ([]byte, error) {
+// cancelRuns patches the Runs in the status of the PipelineRun for the controllers of
+// their custom task to cancel them, and returns the errors patching them.
+func cancelRuns(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface) []string {
+	b, err := getCancelPatch(v1alpha1.RunSpecStatusCancelled)
+	if err != nil {
+		return []string{fmt.Errorf("couldn't make patch to update Run cancellation: %v", err).Error()}
+	}
+	errs := []string{}
+	for runName := range pr.Status.Runs {
+		logger.Infof("cancelling Run %s", runName)
+
+		if _, err := clientSet.TektonV1alpha1().Runs(runNamespace(pr)).Patch(runName, types.JSONPatchType, b, ""); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to patch Run `%s` with cancellation: %s", runName, err).Error())
+		}
+	}
+	return errs
+}
+
+func getCancelPatch(status interface{}) ([]byte, error) {
 	patches := []jsonpatch.JsonPatchOperation{{
 		Operation: "add",
 		Path:      "/spec/status",
-		Value:     v1beta1.TaskRunSpecStatusCancelled,
+		Value:     status,
 	}}
 	patchBytes, err := json.Marshal(patches)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/test"
@@ -34,6 +35,7 @@ func TestCancelPipelineRun(t *testing.T) {
 		name        string
 		pipelineRun *v1beta1.PipelineRun
 		taskRuns    []*v1beta1.TaskRun
+		runs        []*v1alpha1.Run
 	}{{
 		name: "no-resolved-taskrun",
 		pipelineRun: &v1beta1.PipelineRun{
@@ -67,6 +69,20 @@ func TestCancelPipelineRun(t *testing.T) {
 					"t2", &v1beta1.PipelineRunTaskRunStatus{PipelineTaskName: "task-2"})),
 		),
 		taskRuns: []*v1beta1.TaskRun{tb.TaskRun("t1", tb.TaskRunNamespace("foo")), tb.TaskRun("t2", tb.TaskRunNamespace("foo"))},
+	}, {
+		name: "taskrun-and-run",
+		pipelineRun: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run-cancelled", Namespace: "foo"},
+			Spec: v1beta1.PipelineRunSpec{
+				Status: v1beta1.PipelineRunSpecStatusCancelled,
+			},
+			Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{"t1": {PipelineTaskName: "task-1"}},
+				Runs:     map[string]*v1beta1.PipelineRunRunStatus{"r1": {PipelineTaskName: "custom-task-1"}},
+			}},
+		},
+		taskRuns: []*v1beta1.TaskRun{tb.TaskRun("t1", tb.TaskRunNamespace("foo"))},
+		runs:     []*v1alpha1.Run{{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "foo"}}},
 	}}
 	for _, tc := range testCases {
 		tc := tc
@@ -74,6 +90,7 @@ func TestCancelPipelineRun(t *testing.T) {
 			d := test.Data{
 				PipelineRuns: []*v1beta1.PipelineRun{tc.pipelineRun},
 				TaskRuns:     tc.taskRuns,
+				Runs:         tc.runs,
 			}
 			ctx, _ := ttesting.SetupFakeContext(t)
 			ctx, cancel := context.WithCancel(ctx)
@@ -97,6 +114,18 @@ func TestCancelPipelineRun(t *testing.T) {
 					t.Errorf("expected task %q to be marked as cancelled, was %q", tr.Name, tr.Spec.Status)
 				}
 			}
+			runs, err := c.Pipeline.TektonV1alpha1().Runs("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs.Items) != len(tc.runs) {
+				t.Errorf("expected %d runs, got %d", len(tc.runs), len(runs.Items))
+			}
+			for _, r := range runs.Items {
+				if r.Spec.Status != v1alpha1.RunSpecStatusCancelled {
+					t.Errorf("expected run %q to be marked as cancelled, was %q", r.Name, r.Spec.Status)
+				}
+			}
 		})
 	}
 }
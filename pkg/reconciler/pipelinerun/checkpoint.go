@@ -0,0 +1,281 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// checkpointSuffix is appended to the name of a PipelineRun to name the
+	// ConfigMap holding its checkpoint.
+	checkpointSuffix = "-checkpoint"
+	// checkpointKey is the key of the checkpoint in the data of its ConfigMap.
+	checkpointKey = "checkpoint.json"
+)
+
+// pipelineRunCheckpoint is the execution state of a PipelineRun, saved so that
+// its status can be restored after the controller restarts.
+type pipelineRunCheckpoint struct {
+	// Time is when the checkpoint was saved.
+	Time metav1.Time `json:"time"`
+	// TaskRuns maps the names of the TaskRuns of the PipelineRun to their state.
+	TaskRuns map[string]checkpointTaskRun `json:"taskRuns,omitempty"`
+	// Counters counts the TaskRuns of the PipelineRun in each state.
+	Counters checkpointCounters `json:"counters"`
+}
+
+// checkpointTaskRun is the state of a TaskRun of a PipelineRun.
+type checkpointTaskRun struct {
+	PipelineTaskName string `json:"pipelineTaskName"`
+	// Reason is the reason of the Succeeded condition of the TaskRun, if any.
+	Reason string `json:"reason,omitempty"`
+	// ConditionChecks maps the names of the condition check TaskRuns of the
+	// TaskRun to the names of their conditions.
+	ConditionChecks map[string]string `json:"conditionChecks,omitempty"`
+}
+
+// checkpointCounters counts the TaskRuns and the skipped tasks of a PipelineRun.
+type checkpointCounters struct {
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Incomplete int `json:"incomplete"`
+	Skipped    int `json:"skipped"`
+}
+
+// checkpointState is what the controller knows about the checkpoint of a PipelineRun.
+type checkpointState struct {
+	savedAt  time.Time
+	taskRuns sets.String
+}
+
+// checkpointTracker remembers the PipelineRuns reconciled since the controller
+// started, and when their checkpoint was last saved.
+type checkpointTracker struct {
+	clock clock.PassiveClock
+
+	mu     sync.Mutex
+	states map[types.UID]*checkpointState
+}
+
+func newCheckpointTracker(clock clock.PassiveClock) *checkpointTracker {
+	return &checkpointTracker{
+		clock:  clock,
+		states: map[types.UID]*checkpointState{},
+	}
+}
+
+// firstReconcile returns true the first time it is called for pr since the
+// controller started.
+func (t *checkpointTracker) firstReconcile(pr *v1beta1.PipelineRun) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.states[pr.UID]; ok {
+		return false
+	}
+	t.states[pr.UID] = &checkpointState{}
+	return true
+}
+
+// due returns true if the checkpoint of pr should be saved, because the
+// interval of pr has elapsed since it was last saved or because the TaskRuns
+// of pr changed since then.
+func (t *checkpointTracker) due(pr *v1beta1.PipelineRun, taskRuns sets.String) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.states[pr.UID]
+	if !ok || state.savedAt.IsZero() {
+		return true
+	}
+	return t.clock.Since(state.savedAt) >= pr.Spec.CheckpointInterval.Duration || !state.taskRuns.Equal(taskRuns)
+}
+
+// saved records that the checkpoint of pr listing taskRuns was saved at now.
+func (t *checkpointTracker) saved(pr *v1beta1.PipelineRun, taskRuns sets.String, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states[pr.UID] = &checkpointState{savedAt: now, taskRuns: taskRuns}
+}
+
+// release forgets pr, once it is done.
+func (t *checkpointTracker) release(pr *v1beta1.PipelineRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.states, pr.UID)
+}
+
+// checkpointName returns the name of the ConfigMap holding the checkpoint of pr.
+func checkpointName(pr *v1beta1.PipelineRun) string {
+	return kmeta.ChildName(pr.Name, checkpointSuffix)
+}
+
+// makeCheckpoint returns the checkpoint of the status of pr at now.
+func makeCheckpoint(pr *v1beta1.PipelineRun, now time.Time) *pipelineRunCheckpoint {
+	cp := &pipelineRunCheckpoint{
+		Time:     metav1.NewTime(now),
+		TaskRuns: make(map[string]checkpointTaskRun, len(pr.Status.TaskRuns)),
+		Counters: checkpointCounters{Skipped: len(pr.Status.SkippedTasks)},
+	}
+	for name, trs := range pr.Status.TaskRuns {
+		ctr := checkpointTaskRun{PipelineTaskName: trs.PipelineTaskName}
+		var c *apis.Condition
+		if trs.Status != nil {
+			c = trs.Status.GetCondition(apis.ConditionSucceeded)
+		}
+		switch {
+		case c == nil:
+			cp.Counters.Incomplete++
+		case c.IsTrue():
+			cp.Counters.Succeeded++
+		case c.IsFalse():
+			cp.Counters.Failed++
+		default:
+			cp.Counters.Incomplete++
+		}
+		if c != nil {
+			ctr.Reason = c.Reason
+		}
+		for ccName, cc := range trs.ConditionChecks {
+			if ctr.ConditionChecks == nil {
+				ctr.ConditionChecks = map[string]string{}
+			}
+			ctr.ConditionChecks[ccName] = cc.ConditionName
+		}
+		cp.TaskRuns[name] = ctr
+	}
+	return cp
+}
+
+// saveCheckpoint saves the checkpoint of pr in its ConfigMap, if pr has a
+// checkpoint interval and the checkpoint is due.
+func (c *Reconciler) saveCheckpoint(pr *v1beta1.PipelineRun) error {
+	if pr.Spec.CheckpointInterval == nil {
+		return nil
+	}
+	taskRuns := sets.NewString()
+	for name := range pr.Status.TaskRuns {
+		taskRuns.Insert(name)
+	}
+	if !c.checkpoints.due(pr, taskRuns) {
+		return nil
+	}
+
+	now := c.checkpoints.clock.Now()
+	data, err := json.Marshal(makeCheckpoint(pr, now))
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            checkpointName(pr),
+			Namespace:       pr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
+		},
+		Data: map[string]string{checkpointKey: string(data)},
+	}
+	configMaps := c.KubeClientSet.CoreV1().ConfigMaps(pr.Namespace)
+	if _, err := configMaps.Create(cm); k8serrors.IsAlreadyExists(err) {
+		existing, err := configMaps.Get(cm.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Data = cm.Data
+		if _, err := configMaps.Update(existing); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	c.checkpoints.saved(pr, taskRuns, now)
+	return nil
+}
+
+// restoreCheckpoint adds the TaskRuns of the checkpoint of pr missing from its
+// status when pr is reconciled for the first time since the controller started.
+// The status still has to be synced with the TaskRuns and Runs listed from the
+// informers, as those created since the checkpoint was saved aren't in it.
+func (c *Reconciler) restoreCheckpoint(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	logger := logging.FromContext(ctx)
+	if pr.Spec.CheckpointInterval == nil || !c.checkpoints.firstReconcile(pr) {
+		return nil
+	}
+	cm, err := c.KubeClientSet.CoreV1().ConfigMaps(pr.Namespace).Get(checkpointName(pr), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(cm, pr) {
+		logger.Warnf("Ignoring the checkpoint %s of PipelineRun %s which it doesn't own", cm.Name, pr.Name)
+		return nil
+	}
+	cp := &pipelineRunCheckpoint{}
+	if err := json.Unmarshal([]byte(cm.Data[checkpointKey]), cp); err != nil {
+		logger.Warnf("Ignoring the invalid checkpoint %s of PipelineRun %s: %v", cm.Name, pr.Name, err)
+		return nil
+	}
+
+	if pr.Status.TaskRuns == nil {
+		pr.Status.TaskRuns = make(map[string]*v1beta1.PipelineRunTaskRunStatus, len(cp.TaskRuns))
+	}
+	for name, ctr := range cp.TaskRuns {
+		trs, ok := pr.Status.TaskRuns[name]
+		if !ok {
+			trs = &v1beta1.PipelineRunTaskRunStatus{PipelineTaskName: ctr.PipelineTaskName}
+			tr, err := c.taskRunLister.TaskRuns(runNamespace(pr)).Get(name)
+			switch {
+			case k8serrors.IsNotFound(err):
+				// The TaskRun of a task whose conditions are still being checked
+				// is only created once they pass.
+				if len(ctr.ConditionChecks) == 0 {
+					continue
+				}
+			case err != nil:
+				return err
+			default:
+				trs.Status = &tr.Status
+			}
+			pr.Status.TaskRuns[name] = trs
+		}
+		for ccName, conditionName := range ctr.ConditionChecks {
+			if trs.ConditionChecks == nil {
+				trs.ConditionChecks = map[string]*v1beta1.PipelineRunConditionCheckStatus{}
+			}
+			if _, ok := trs.ConditionChecks[ccName]; !ok {
+				trs.ConditionChecks[ccName] = &v1beta1.PipelineRunConditionCheckStatus{ConditionName: conditionName}
+			}
+		}
+	}
+	logger.Infof("Restored the checkpoint of PipelineRun %s saved at %s: %d TaskRuns succeeded, %d failed, %d incomplete and %d tasks skipped",
+		pr.Name, cp.Time, cp.Counters.Succeeded, cp.Counters.Failed, cp.Counters.Incomplete, cp.Counters.Skipped)
+	c.checkpoints.saved(pr, sets.StringKeySet(cp.TaskRuns), cp.Time.Time)
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCheckpointTracker(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := newCheckpointTracker(fakeClock)
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo", UID: "build-uid"},
+		Spec:       v1beta1.PipelineRunSpec{CheckpointInterval: &metav1.Duration{Duration: time.Minute}},
+	}
+	taskRuns := sets.NewString("build-task-1")
+
+	if !tracker.firstReconcile(pr) {
+		t.Error("Expected the first reconcile of the PipelineRun to be reported")
+	}
+	if tracker.firstReconcile(pr) {
+		t.Error("Expected the first reconcile of the PipelineRun to be reported once")
+	}
+	if !tracker.due(pr, taskRuns) {
+		t.Error("Expected a checkpoint which was never saved to be due")
+	}
+
+	tracker.saved(pr, taskRuns, fakeClock.Now())
+	fakeClock.Step(30 * time.Second)
+	if tracker.due(pr, taskRuns) {
+		t.Error("Expected the checkpoint not to be due before the interval elapsed")
+	}
+	if !tracker.due(pr, sets.NewString("build-task-1", "build-task-2")) {
+		t.Error("Expected the checkpoint to be due once a TaskRun was added")
+	}
+	fakeClock.Step(30 * time.Second)
+	if !tracker.due(pr, taskRuns) {
+		t.Error("Expected the checkpoint to be due once the interval elapsed")
+	}
+
+	tracker.release(pr)
+	if !tracker.firstReconcile(pr) {
+		t.Error("Expected a released PipelineRun to be forgotten")
+	}
+}
+
+func TestCheckpointName(t *testing.T) {
+	pr := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"}}
+	if got := checkpointName(pr); got != "build-checkpoint" {
+		t.Errorf("Expected the checkpoint of the PipelineRun to be named build-checkpoint, got %q", got)
+	}
+	pr.Name = strings.Repeat("a", 253)
+	if got := checkpointName(pr); len(got) > 253 {
+		t.Errorf("Expected the checkpoint of a long PipelineRun name to be a valid name, got %q", got)
+	}
+}
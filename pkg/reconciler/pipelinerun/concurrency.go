@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// applyConcurrencyPolicy enforces the concurrency policy of pr, which hasn't started yet,
+// against the PipelineRuns of its namespace sharing its concurrency key. It returns true
+// if pr is queued and must not start until they are done.
+func (c *Reconciler) applyConcurrencyPolicy(ctx context.Context, pr *v1beta1.PipelineRun) (bool, error) {
+	logger := logging.FromContext(ctx)
+	key := pr.ConcurrencyKey()
+	active, err := c.activePipelineRuns(pr.Namespace, key)
+	if err != nil {
+		return false, err
+	}
+
+	if pr.Spec.Concurrency.Policy == v1beta1.ConcurrencyPolicyCancelPrevious {
+		b, err := getPipelineRunCancelPatch()
+		if err != nil {
+			return false, err
+		}
+		for _, other := range active {
+			if other.Name == pr.Name || !createdBefore(other, pr) || other.IsCancelled() {
+				continue
+			}
+			logger.Infof("Cancelling PipelineRun %s with concurrency key %q", other.Name, key)
+			if _, err := c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Patch(other.Name, types.JSONPatchType, b, ""); err != nil {
+				return false, fmt.Errorf("failed to cancel PipelineRun %s with concurrency key %q: %w", other.Name, key, err)
+			}
+		}
+		return false, nil
+	}
+
+	// PipelineRuns are started in the order they were created, so pr waits for the
+	// PipelineRuns that already started and for the older ones still queued.
+	for _, other := range active {
+		if other.Name == pr.Name {
+			continue
+		}
+		if other.HasStarted() || createdBefore(other, pr) {
+			pr.Status.MarkRunning(v1beta1.PipelineRunReasonQueued.String(),
+				"Waiting for PipelineRun %s with concurrency key %q to be done", other.Name, key)
+			return true, nil
+		}
+	}
+	if c := pr.Status.GetCondition(apis.ConditionSucceeded); c != nil && c.Reason == v1beta1.PipelineRunReasonQueued.String() {
+		pr.Status.MarkRunning(v1beta1.PipelineRunReasonStarted.String(), "")
+	}
+	return false, nil
+}
+
+// activePipelineRuns returns the PipelineRuns of namespace with the concurrency key
+// which aren't done yet, oldest first.
+func (c *Reconciler) activePipelineRuns(namespace, key string) ([]*v1beta1.PipelineRun, error) {
+	prs, err := c.pipelineRunLister.PipelineRuns(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var active []*v1beta1.PipelineRun
+	for _, pr := range prs {
+		if pr.ConcurrencyKey() == key && !pr.IsDone() {
+			active = append(active, pr)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return createdBefore(active[i], active[j]) })
+	return active, nil
+}
+
+// createdBefore returns true if pr was created before other, using the name of the
+// PipelineRuns to order the ones created at the same time.
+func createdBefore(pr, other *v1beta1.PipelineRun) bool {
+	if pr.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return pr.Name < other.Name
+	}
+	return pr.CreationTimestamp.Before(&other.CreationTimestamp)
+}
+
+// enqueueQueuedPipelineRuns returns the event handler enqueuing the PipelineRuns
+// queued behind a PipelineRun once it is done or deleted.
+func (c *Reconciler) enqueueQueuedPipelineRuns(enqueue func(interface{})) cache.ResourceEventHandler {
+	enqueueQueued := func(obj interface{}) {
+		pr, ok := obj.(*v1beta1.PipelineRun)
+		if !ok || pr.ConcurrencyKey() == "" {
+			return
+		}
+		active, err := c.activePipelineRuns(pr.Namespace, pr.ConcurrencyKey())
+		if err != nil {
+			return
+		}
+		for _, queued := range active {
+			if !queued.HasStarted() {
+				enqueue(queued)
+			}
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: controller.PassNew(func(obj interface{}) {
+			if pr, ok := obj.(*v1beta1.PipelineRun); ok && pr.IsDone() {
+				enqueueQueued(pr)
+			}
+		}),
+		DeleteFunc: enqueueQueued,
+	}
+}
+
+func getPipelineRunCancelPatch() ([]byte, error) {
+	patches := []jsonpatch.JsonPatchOperation{{
+		Operation: "add",
+		Path:      "/spec/status",
+		Value:     v1beta1.PipelineRunSpecStatusCancelled,
+	}}
+	patchBytes, err := json.Marshal(patches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch bytes in order to cancel: %v", err)
+	}
+	return patchBytes, nil
+}
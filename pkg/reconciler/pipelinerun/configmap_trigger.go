@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// configMapTriggersAnnotation records, on a PipelineRun triggered by
+	// ConfigMap changes, the hash of the ConfigMaps it last saw and how many
+	// PipelineRuns their changes created.
+	configMapTriggersAnnotation = "pipeline.tekton.dev/configmap-triggers"
+	// TriggeredFromAnnotation names, on a PipelineRun created because of a
+	// ConfigMap change, the PipelineRun it was created from.
+	TriggeredFromAnnotation = "pipeline.tekton.dev/triggered-from"
+
+	// configMapTriggerIndex indexes PipelineRuns by the ConfigMaps they trigger
+	// on, as "<namespace>/<name>".
+	configMapTriggerIndex = "configMapTrigger"
+	// configMapSyncTimeout is how long a reconcile waits for the ConfigMaps to
+	// be synced after they start being watched.
+	configMapSyncTimeout = 5 * time.Second
+)
+
+// configMapWatch watches the ConfigMaps of the cluster from the first time a
+// PipelineRun triggering on ConfigMap changes is reconciled, so that the
+// controller doesn't cache every ConfigMap unless the feature is used.
+type configMapWatch struct {
+	kubeclient kubernetes.Interface
+	stopCh     <-chan struct{}
+	// handler is called on the changes of the ConfigMaps once they are watched.
+	handler cache.ResourceEventHandler
+
+	once   sync.Once
+	lister corev1listers.ConfigMapLister
+	synced cache.InformerSynced
+}
+
+func newConfigMapWatch(ctx context.Context, kubeclient kubernetes.Interface) *configMapWatch {
+	return &configMapWatch{
+		kubeclient: kubeclient,
+		stopCh:     ctx.Done(),
+	}
+}
+
+// configMaps starts watching the ConfigMaps the first time it is called, and
+// returns their lister once they are synced.
+func (w *configMapWatch) configMaps(ctx context.Context) (corev1listers.ConfigMapLister, error) {
+	w.once.Do(func() {
+		factory := informers.NewSharedInformerFactory(w.kubeclient, 0)
+		informer := factory.Core().V1().ConfigMaps()
+		informer.Informer().AddEventHandler(w.handler)
+		w.lister = informer.Lister()
+		w.synced = informer.Informer().HasSynced
+		factory.Start(w.stopCh)
+	})
+	ctx, cancel := context.WithTimeout(ctx, configMapSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), w.synced) {
+		return nil, errors.New("timed out waiting for the ConfigMaps to be synced")
+	}
+	return w.lister, nil
+}
+
+// configMapTriggers is the content of configMapTriggersAnnotation.
+type configMapTriggers struct {
+	Count  int               `json:"count"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// reconcileConfigMapTriggers creates a new PipelineRun from pr when one of the
+// ConfigMaps it triggers on changed since it was last reconciled. The hashes
+// of the ConfigMaps are recorded in the annotations of pr, which are written
+// back at the end of the reconcile. The first time a ConfigMap is seen only
+// its hash is recorded.
+func (c *Reconciler) reconcileConfigMapTriggers(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	if len(pr.Spec.TriggerOnConfigMapChange) == 0 {
+		return nil
+	}
+	logger := logging.FromContext(ctx)
+	configMaps, err := c.configMaps.configMaps(ctx)
+	if err != nil {
+		return err
+	}
+
+	recorded := configMapTriggers{}
+	if v, ok := pr.Annotations[configMapTriggersAnnotation]; ok {
+		if err := json.Unmarshal([]byte(v), &recorded); err != nil {
+			logger.Warnf("Ignoring invalid %s annotation of PipelineRun %s: %v", configMapTriggersAnnotation, pr.Name, err)
+		}
+	}
+	current := configMapTriggers{Count: recorded.Count, Hashes: map[string]string{}}
+	changed := false
+	for _, ref := range pr.Spec.TriggerOnConfigMapChange {
+		cm, err := configMaps.ConfigMaps(pr.Namespace).Get(ref.Name)
+		if k8serrors.IsNotFound(err) {
+			// Keep the last hash seen, so that recreating the ConfigMap with
+			// other data is a change.
+			if h, ok := recorded.Hashes[ref.Name]; ok {
+				current.Hashes[ref.Name] = h
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get ConfigMap %q triggering PipelineRun %s: %w", ref.Name, pr.Name, err)
+		}
+		h := hashConfigMap(cm)
+		if prev, ok := recorded.Hashes[ref.Name]; ok && prev != h {
+			changed = true
+		}
+		current.Hashes[ref.Name] = h
+	}
+
+	if changed {
+		current.Count++
+		// The name of the new PipelineRun only depends on the count, so that
+		// it is created once even if recording the count fails.
+		triggered := newTriggeredPipelineRun(pr, current.Count)
+		if _, err := c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Create(triggered); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create PipelineRun %s triggered by a ConfigMap change: %w", triggered.Name, err)
+		}
+		logger.Infof("Created PipelineRun %s from %s after a change of its ConfigMaps", triggered.Name, pr.Name)
+	}
+
+	if reflect.DeepEqual(recorded, current) {
+		return nil
+	}
+	b, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = map[string]string{}
+	}
+	pr.Annotations[configMapTriggersAnnotation] = string(b)
+	return nil
+}
+
+// newTriggeredPipelineRun returns the count-th PipelineRun created from pr
+// because of a change of its ConfigMaps. It runs the same spec, without being
+// cancelled nor triggered by ConfigMap changes itself.
+func newTriggeredPipelineRun(pr *v1beta1.PipelineRun, count int) *v1beta1.PipelineRun {
+	annotations := map[string]string{}
+	for k, v := range pr.Annotations {
+		if k != configMapTriggersAnnotation && k != corev1.LastAppliedConfigAnnotation {
+			annotations[k] = v
+		}
+	}
+	annotations[TriggeredFromAnnotation] = pr.Name
+	spec := pr.Spec.DeepCopy()
+	spec.Status = ""
+	spec.TriggerOnConfigMapChange = nil
+	return &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        kmeta.ChildName(pr.Name, fmt.Sprintf("-retrigger-%d", count)),
+			Namespace:   pr.Namespace,
+			Labels:      kmeta.CopyMap(pr.Labels),
+			Annotations: annotations,
+		},
+		Spec: *spec,
+	}
+}
+
+// hashConfigMap returns a hash of the data of cm.
+func hashConfigMap(cm *corev1.ConfigMap) string {
+	h := sha256.New()
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "data:%q=%q\n", k, cm.Data[k])
+	}
+	keys = keys[:0]
+	for k := range cm.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "binaryData:%q=%q\n", k, cm.BinaryData[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// indexConfigMapTriggers returns the keys of the ConfigMaps the PipelineRun obj
+// triggers on, for configMapTriggerIndex.
+func indexConfigMapTriggers(obj interface{}) ([]string, error) {
+	pr, ok := obj.(*v1beta1.PipelineRun)
+	if !ok {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(pr.Spec.TriggerOnConfigMapChange))
+	for _, ref := range pr.Spec.TriggerOnConfigMapChange {
+		keys = append(keys, pr.Namespace+"/"+ref.Name)
+	}
+	return keys, nil
+}
+
+// enqueueConfigMapTriggeredPipelineRuns enqueues the PipelineRuns triggered
+// by a ConfigMap when it is created or its data changes. They are looked up in
+// indexer by configMapTriggerIndex.
+func enqueueConfigMapTriggeredPipelineRuns(indexer cache.Indexer, enqueue func(interface{})) cache.ResourceEventHandler {
+	enqueueTriggered := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		prs, err := indexer.ByIndex(configMapTriggerIndex, cm.Namespace+"/"+cm.Name)
+		if err != nil {
+			return
+		}
+		for _, pr := range prs {
+			enqueue(pr)
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueueTriggered,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCM, _ := oldObj.(*corev1.ConfigMap)
+			newCM, ok := newObj.(*corev1.ConfigMap)
+			if !ok || (oldCM != nil && hashConfigMap(oldCM) == hashConfigMap(newCM)) {
+				return
+			}
+			enqueueTriggered(newCM)
+		},
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestHashConfigMap(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Labels: map[string]string{"app": "deploy"}},
+		Data:       map[string]string{"a": "1", "b": "2"},
+	}
+	relabelled := cm.DeepCopy()
+	relabelled.Labels["app"] = "other"
+	if hashConfigMap(cm) != hashConfigMap(relabelled) {
+		t.Error("Expected the hash of a ConfigMap not to depend on its metadata")
+	}
+	for _, changed := range []*corev1.ConfigMap{
+		{Data: map[string]string{"a": "1", "b": "3"}},
+		{Data: map[string]string{"a": "1"}},
+		{Data: map[string]string{"a": "1", "b=": "2"}},
+		{Data: map[string]string{"a": "1"}, BinaryData: map[string][]byte{"b": []byte("2")}},
+	} {
+		if hashConfigMap(cm) == hashConfigMap(changed) {
+			t.Errorf("Expected the hash of %v to differ from the hash of %v", changed.Data, cm.Data)
+		}
+	}
+}
+
+func TestEnqueueConfigMapTriggeredPipelineRuns(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{configMapTriggerIndex: indexConfigMapTriggers})
+	for _, pr := range []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: "triggered", Namespace: "foo"},
+		Spec:       v1beta1.PipelineRunSpec{TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "other"}, {Name: "settings"}}},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "not-triggered", Namespace: "foo"},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "bar"},
+		Spec:       v1beta1.PipelineRunSpec{TriggerOnConfigMapChange: []corev1.ObjectReference{{Name: "settings"}}},
+	}} {
+		if err := indexer.Add(pr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var enqueued []string
+	handler := enqueueConfigMapTriggeredPipelineRuns(indexer, func(obj interface{}) {
+		enqueued = append(enqueued, obj.(*v1beta1.PipelineRun).Name)
+	})
+
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "foo", ResourceVersion: "1"},
+		Data:       map[string]string{"replicas": "1"},
+	}
+	relabelled := settings.DeepCopy()
+	relabelled.ResourceVersion = "2"
+	relabelled.Labels = map[string]string{"app": "deploy"}
+	changed := relabelled.DeepCopy()
+	changed.ResourceVersion = "3"
+	changed.Data["replicas"] = "3"
+
+	handler.OnAdd(settings)
+	handler.OnUpdate(settings, relabelled)
+	handler.OnUpdate(relabelled, changed)
+	handler.OnDelete(changed)
+	if d := cmp.Diff([]string{"triggered", "triggered"}, enqueued); d != "" {
+		t.Errorf("Enqueued PipelineRuns %s", diff.PrintWantGot(d))
+	}
+}
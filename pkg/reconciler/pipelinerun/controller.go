@@ -24,6 +24,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
 	conditioninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/condition"
+	runinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/run"
 	clustertaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/clustertask"
 	pipelineinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipeline"
 	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipelinerun"
@@ -32,8 +33,11 @@ import (
 	pipelinerunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1beta1/pipelinerun"
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/resource/injection/informers/resource/v1alpha1/pipelineresource"
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
+	"github.com/tektoncd/pipeline/pkg/reconciler/server"
+	"github.com/tektoncd/pipeline/pkg/reconciler/ttl"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/cache"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
@@ -43,12 +47,13 @@ import (
 )
 
 // NewController instantiates a new controller.Impl from knative.dev/pkg/controller
-func NewController(namespace string, images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+func NewController(namespace string, images pipeline.Images, clock clock.Clock) func(context.Context, configmap.Watcher) *controller.Impl {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		logger := logging.FromContext(ctx)
 		kubeclientset := kubeclient.Get(ctx)
 		pipelineclientset := pipelineclient.Get(ctx)
 		taskRunInformer := taskruninformer.Get(ctx)
+		runInformer := runinformer.Get(ctx)
 		taskInformer := taskinformer.Get(ctx)
 		clusterTaskInformer := clustertaskinformer.Get(ctx)
 		pipelineRunInformer := pipelineruninformer.Get(ctx)
@@ -56,6 +61,7 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		resourceInformer := resourceinformer.Get(ctx)
 		conditionInformer := conditioninformer.Get(ctx)
 		timeoutHandler := timeout.NewHandler(ctx.Done(), logger)
+		ttlHandler := ttl.NewHandler(clock)
 		metrics, err := NewRecorder()
 		if err != nil {
 			logger.Errorf("Failed to create pipelinerun metrics recorder %v", err)
@@ -70,11 +76,18 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			taskLister:        taskInformer.Lister(),
 			clusterTaskLister: clusterTaskInformer.Lister(),
 			taskRunLister:     taskRunInformer.Lister(),
+			runLister:         runInformer.Lister(),
 			resourceLister:    resourceInformer.Lister(),
 			conditionLister:   conditionInformer.Lister(),
+			configMaps:        newConfigMapWatch(ctx, kubeclientset),
+			resourceQuotas:    newResourceQuotaWatch(ctx, kubeclientset),
 			timeoutHandler:    timeoutHandler,
+			ttlHandler:        ttlHandler,
+			bundles:           newBundleCache(),
 			cloudEventClient:  cloudeventclient.Get(ctx),
 			metrics:           metrics,
+			stats:             newPipelineStats(statsRunsPerPipeline, statsPipelines, kubeclientset),
+			checkpoints:       newCheckpointTracker(clock),
 			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
 		}
 		impl := pipelinerunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
@@ -88,6 +101,8 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 
 		timeoutHandler.SetPipelineRunCallbackFunc(impl.Enqueue)
 		timeoutHandler.CheckTimeouts(namespace, kubeclientset, pipelineclientset)
+		ttlHandler.SetEnqueueAfterFunc(impl.EnqueueAfter)
+		c.enqueueAfter = impl.EnqueueAfter
 
 		logger.Info("Setting up event handlers")
 		pipelineRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -95,13 +110,25 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			UpdateFunc: controller.PassNew(impl.Enqueue),
 			DeleteFunc: impl.Enqueue,
 		})
+		pipelineRunInformer.Informer().AddEventHandler(c.enqueueQueuedPipelineRuns(impl.Enqueue))
+		if err := pipelineRunInformer.Informer().AddIndexers(cache.Indexers{configMapTriggerIndex: indexConfigMapTriggers}); err != nil {
+			logger.Errorf("Failed to index PipelineRuns by the ConfigMaps they trigger on: %v", err)
+		}
+		c.configMaps.handler = enqueueConfigMapTriggeredPipelineRuns(pipelineRunInformer.Informer().GetIndexer(), impl.Enqueue)
 
 		c.tracker = tracker.New(impl.EnqueueKey, 30*time.Minute)
 		taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 			UpdateFunc: controller.PassNew(impl.EnqueueControllerOf),
 		})
+		taskRunInformer.Informer().AddEventHandler(enqueueIsolatedPipelineRuns(impl.EnqueueKey))
+		runInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: controller.PassNew(impl.EnqueueControllerOf),
+		})
 
 		go metrics.ReportRunningPipelineRuns(ctx, pipelineRunInformer.Lister())
+		if s := server.FromContext(ctx); s != nil {
+			s.Handle(statsPath, c.stats)
+		}
 
 		return impl
 	}
@@ -0,0 +1,361 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonCouldntCreateIsolatedNamespace indicates that a PipelineRun running in an
+	// isolated namespace couldn't create the namespace, or copy what its TaskRuns need into it.
+	ReasonCouldntCreateIsolatedNamespace = "CouldntCreateIsolatedNamespace"
+
+	// isolatedNamespaceSuffix is appended to the name of a PipelineRun to name
+	// its isolated namespace.
+	isolatedNamespaceSuffix = "-ns"
+	// isolatedFromNamespaceAnnotation is set on an isolated namespace, and on the
+	// TaskRuns running in it, to the namespace of their PipelineRun.
+	isolatedFromNamespaceAnnotation = pipeline.GroupName + "/isolated-from-namespace"
+	// isolatedPipelineRunUIDAnnotation is set on an isolated namespace to the UID of
+	// the PipelineRun it was created for, so that a namespace which happens to have
+	// the same name is neither used nor deleted.
+	isolatedPipelineRunUIDAnnotation = pipeline.GroupName + "/isolated-pipelinerun-uid"
+)
+
+// runNamespace returns the namespace the TaskRuns of pr run in.
+func runNamespace(pr *v1beta1.PipelineRun) string {
+	if !pr.Spec.IsolatedNamespace {
+		return pr.Namespace
+	}
+	return kmeta.ChildName(pr.Name, isolatedNamespaceSuffix)
+}
+
+// ownerReferences returns the owner references of the objects created for pr
+// in the namespace its TaskRuns run in. An owner must be in the namespace of
+// the objects it owns, so the objects of an isolated namespace are only
+// removed with the namespace.
+func ownerReferences(pr *v1beta1.PipelineRun) []metav1.OwnerReference {
+	if pr.Spec.IsolatedNamespace {
+		return nil
+	}
+	return []metav1.OwnerReference{pr.GetOwnerReference()}
+}
+
+// claimOwnerReference returns the owner reference of the claims created from the
+// volumeClaimTemplates of pr, which they are named after. The claims of an isolated
+// namespace are owned by the namespace, the UID of which is only known once created.
+func claimOwnerReference(pr *v1beta1.PipelineRun) metav1.OwnerReference {
+	if !pr.Spec.IsolatedNamespace {
+		return pr.GetOwnerReference()
+	}
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       runNamespace(pr),
+	}
+}
+
+// createIsolatedNamespace creates the isolated namespace of pr, and copies into it
+// the ServiceAccounts, Secrets and ConfigMaps its TaskRuns use.
+func (c *Reconciler) createIsolatedNamespace(ctx context.Context, pr *v1beta1.PipelineRun) (*corev1.Namespace, error) {
+	logger := logging.FromContext(ctx)
+	name := runNamespace(pr)
+
+	ns, err := c.KubeClientSet.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					pipeline.GroupName + pipeline.PipelineRunLabelKey: pr.Name,
+				},
+				Annotations: map[string]string{
+					isolatedFromNamespaceAnnotation:  pr.Namespace,
+					isolatedPipelineRunUIDAnnotation: string(pr.UID),
+				},
+			},
+		}
+		if ns, err = c.KubeClientSet.CoreV1().Namespaces().Create(ns); err != nil {
+			return nil, fmt.Errorf("failed to create namespace %s: %w", name, err)
+		}
+		logger.Infof("Created namespace %s for PipelineRun %s/%s", name, pr.Namespace, pr.Name)
+	case err != nil:
+		return nil, fmt.Errorf("failed to retrieve namespace %s: %w", name, err)
+	case ns.Annotations[isolatedPipelineRunUIDAnnotation] != string(pr.UID):
+		return nil, fmt.Errorf("namespace %s already exists and wasn't created for this PipelineRun", name)
+	}
+
+	secrets := sets.NewString()
+	configMaps := sets.NewString()
+	for _, sa := range isolatedServiceAccountNames(pr) {
+		saSecrets, err := c.copyServiceAccount(pr, sa)
+		if err != nil {
+			return nil, err
+		}
+		secrets.Insert(saSecrets...)
+	}
+	for _, wb := range pr.Spec.Workspaces {
+		switch {
+		case wb.Secret != nil:
+			secrets.Insert(wb.Secret.SecretName)
+		case wb.ConfigMap != nil:
+			configMaps.Insert(wb.ConfigMap.Name)
+		}
+	}
+	for _, podTemplate := range isolatedPodTemplates(pr) {
+		for _, s := range podTemplate.ImagePullSecrets {
+			secrets.Insert(s.Name)
+		}
+	}
+	for _, s := range secrets.List() {
+		if _, err := c.copySecret(pr, s); err != nil {
+			return nil, err
+		}
+	}
+	for _, cm := range configMaps.List() {
+		if err := c.copyConfigMap(pr, cm); err != nil {
+			return nil, err
+		}
+	}
+	return ns, nil
+}
+
+// deleteIsolatedNamespace deletes the isolated namespace of pr, with everything
+// that was created in it.
+func (c *Reconciler) deleteIsolatedNamespace(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	if !pr.Spec.IsolatedNamespace {
+		return nil
+	}
+	name := runNamespace(pr)
+	ns, err := c.KubeClientSet.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to retrieve namespace %s: %w", name, err)
+	case ns.Annotations[isolatedPipelineRunUIDAnnotation] != string(pr.UID) || ns.DeletionTimestamp != nil:
+		return nil
+	}
+	logging.FromContext(ctx).Infof("Deleting namespace %s of PipelineRun %s/%s", name, pr.Namespace, pr.Name)
+	err = c.KubeClientSet.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &ns.UID},
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// copyServiceAccount copies the ServiceAccount name into the isolated namespace of pr,
+// and returns the names of the Secrets it references which have to be copied with it.
+// The Secrets holding the tokens of the ServiceAccount are bound to the original and
+// are not copied, the copy is given its own token.
+func (c *Reconciler) copyServiceAccount(pr *v1beta1.PipelineRun, name string) ([]string, error) {
+	sa, err := c.KubeClientSet.CoreV1().ServiceAccounts(pr.Namespace).Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		// The TaskRuns using it fail the same way they would have in the namespace of pr.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ServiceAccount %s: %w", name, err)
+	}
+
+	var secretNames []string
+	cp := &corev1.ServiceAccount{
+		ObjectMeta:                   copiedObjectMeta(sa.ObjectMeta, runNamespace(pr)),
+		ImagePullSecrets:             sa.ImagePullSecrets,
+		AutomountServiceAccountToken: sa.AutomountServiceAccountToken,
+	}
+	for _, ref := range sa.Secrets {
+		copied, err := c.copySecret(pr, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if copied {
+			cp.Secrets = append(cp.Secrets, corev1.ObjectReference{Name: ref.Name})
+		}
+	}
+	for _, ref := range sa.ImagePullSecrets {
+		secretNames = append(secretNames, ref.Name)
+	}
+
+	_, err = c.KubeClientSet.CoreV1().ServiceAccounts(cp.Namespace).Create(cp)
+	if k8serrors.IsAlreadyExists(err) {
+		// The default ServiceAccount is created with every namespace, or the copy
+		// was created by an earlier reconcile.
+		return secretNames, c.mergeServiceAccount(cp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy ServiceAccount %s: %w", name, err)
+	}
+	return secretNames, nil
+}
+
+// mergeServiceAccount adds the Secrets of the copy cp to the ServiceAccount which
+// already exists in the isolated namespace.
+func (c *Reconciler) mergeServiceAccount(cp *corev1.ServiceAccount) error {
+	sa, err := c.KubeClientSet.CoreV1().ServiceAccounts(cp.Namespace).Get(cp.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve ServiceAccount %s: %w", cp.Name, err)
+	}
+	sa = sa.DeepCopy()
+	changed := false
+	secrets := sets.NewString()
+	for _, ref := range sa.Secrets {
+		secrets.Insert(ref.Name)
+	}
+	for _, ref := range cp.Secrets {
+		if !secrets.Has(ref.Name) {
+			sa.Secrets = append(sa.Secrets, ref)
+			changed = true
+		}
+	}
+	pullSecrets := sets.NewString()
+	for _, ref := range sa.ImagePullSecrets {
+		pullSecrets.Insert(ref.Name)
+	}
+	for _, ref := range cp.ImagePullSecrets {
+		if !pullSecrets.Has(ref.Name) {
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, ref)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if _, err := c.KubeClientSet.CoreV1().ServiceAccounts(sa.Namespace).Update(sa); err != nil {
+		return fmt.Errorf("failed to update ServiceAccount %s: %w", sa.Name, err)
+	}
+	return nil
+}
+
+// copySecret copies the Secret name into the isolated namespace of pr, and returns
+// whether it was copied. ServiceAccount tokens and missing Secrets aren't copied.
+func (c *Reconciler) copySecret(pr *v1beta1.PipelineRun, name string) (bool, error) {
+	s, err := c.KubeClientSet.CoreV1().Secrets(pr.Namespace).Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve Secret %s: %w", name, err)
+	}
+	if s.Type == corev1.SecretTypeServiceAccountToken {
+		return false, nil
+	}
+	cp := &corev1.Secret{
+		ObjectMeta: copiedObjectMeta(s.ObjectMeta, runNamespace(pr)),
+		Type:       s.Type,
+		Data:       s.Data,
+	}
+	if _, err := c.KubeClientSet.CoreV1().Secrets(cp.Namespace).Create(cp); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("failed to copy Secret %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// copyConfigMap copies the ConfigMap name into the isolated namespace of pr.
+func (c *Reconciler) copyConfigMap(pr *v1beta1.PipelineRun, name string) error {
+	cm, err := c.KubeClientSet.CoreV1().ConfigMaps(pr.Namespace).Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve ConfigMap %s: %w", name, err)
+	}
+	cp := &corev1.ConfigMap{
+		ObjectMeta: copiedObjectMeta(cm.ObjectMeta, runNamespace(pr)),
+		Data:       cm.Data,
+		BinaryData: cm.BinaryData,
+	}
+	if _, err := c.KubeClientSet.CoreV1().ConfigMaps(cp.Namespace).Create(cp); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to copy ConfigMap %s: %w", name, err)
+	}
+	return nil
+}
+
+// copiedObjectMeta returns the metadata of a copy of an object into namespace.
+func copiedObjectMeta(meta metav1.ObjectMeta, namespace string) metav1.ObjectMeta {
+	annotations := kmeta.CopyMap(meta.Annotations)
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   namespace,
+		Labels:      kmeta.CopyMap(meta.Labels),
+		Annotations: annotations,
+	}
+}
+
+// isolatedServiceAccountNames returns the names of the ServiceAccounts the TaskRuns of pr run as.
+func isolatedServiceAccountNames(pr *v1beta1.PipelineRun) []string {
+	names := sets.NewString(pr.Spec.ServiceAccountName)
+	for _, sa := range pr.Spec.ServiceAccountNames {
+		names.Insert(sa.ServiceAccountName)
+	}
+	for _, trs := range pr.Spec.TaskRunSpecs {
+		names.Insert(trs.TaskServiceAccountName)
+	}
+	names.Delete("")
+	return names.List()
+}
+
+// isolatedPodTemplates returns the pod templates the TaskRuns of pr run with.
+func isolatedPodTemplates(pr *v1beta1.PipelineRun) []*v1beta1.PodTemplate {
+	var templates []*v1beta1.PodTemplate
+	if pr.Spec.PodTemplate != nil {
+		templates = append(templates, pr.Spec.PodTemplate)
+	}
+	for _, trs := range pr.Spec.TaskRunSpecs {
+		if trs.TaskPodTemplate != nil {
+			templates = append(templates, trs.TaskPodTemplate)
+		}
+	}
+	return templates
+}
+
+// enqueueIsolatedPipelineRuns enqueues the PipelineRun of a TaskRun running in an
+// isolated namespace when the TaskRun changes. Such TaskRuns aren't owned by their
+// PipelineRun, which is found through their labels and annotations instead.
+func enqueueIsolatedPipelineRuns(enqueueKey func(types.NamespacedName)) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) {
+			tr, ok := obj.(*v1beta1.TaskRun)
+			if !ok {
+				return
+			}
+			namespace, isolated := tr.Annotations[isolatedFromNamespaceAnnotation]
+			name := tr.Labels[pipeline.GroupName+pipeline.PipelineRunLabelKey]
+			if !isolated || name == "" {
+				return
+			}
+			enqueueKey(types.NamespacedName{Namespace: namespace, Name: name})
+		},
+	}
+}
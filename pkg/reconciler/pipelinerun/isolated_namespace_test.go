@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRunNamespace(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pr       *v1beta1.PipelineRun
+		expected string
+	}{{
+		name: "not isolated",
+		pr: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		},
+		expected: "foo",
+	}, {
+		name: "isolated",
+		pr: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+			Spec:       v1beta1.PipelineRunSpec{IsolatedNamespace: true},
+		},
+		expected: "build-ns",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := runNamespace(tc.pr); got != tc.expected {
+				t.Errorf("Expected the TaskRuns to run in %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	long := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 70), Namespace: "foo"},
+		Spec:       v1beta1.PipelineRunSpec{IsolatedNamespace: true},
+	}
+	if got := runNamespace(long); len(got) > 63 {
+		t.Errorf("Expected the isolated namespace of a long PipelineRun name to be a valid namespace name, got %q", got)
+	}
+}
+
+func TestEnqueueIsolatedPipelineRuns(t *testing.T) {
+	var enqueued []types.NamespacedName
+	handler := enqueueIsolatedPipelineRuns(func(key types.NamespacedName) {
+		enqueued = append(enqueued, key)
+	})
+	for _, tr := range []*v1beta1.TaskRun{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "build-task-1",
+			Namespace:   "build-ns",
+			Labels:      map[string]string{pipeline.GroupName + pipeline.PipelineRunLabelKey: "build"},
+			Annotations: map[string]string{isolatedFromNamespaceAnnotation: "foo"},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "owned-task-1",
+			Namespace: "foo",
+			Labels:    map[string]string{pipeline.GroupName + pipeline.PipelineRunLabelKey: "owned"},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standalone",
+			Namespace:   "build-ns",
+			Annotations: map[string]string{isolatedFromNamespaceAnnotation: "foo"},
+		},
+	}} {
+		handler.OnUpdate(tr, tr)
+	}
+	want := []types.NamespacedName{{Namespace: "foo", Name: "build"}}
+	if d := cmp.Diff(want, enqueued); d != "" {
+		t.Errorf("Enqueued PipelineRuns %s", diff.PrintWantGot(d))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -29,6 +30,7 @@ import (
 	"go.opencensus.io/tag"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 )
@@ -47,6 +49,19 @@ var (
 	runningPRsCount = stats.Float64("running_pipelineruns_count",
 		"Number of pipelineruns executing currently",
 		stats.UnitDimensionless)
+
+	runningPRs = stats.Float64("running_pipelineruns",
+		"Number of pipelineruns executing currently, by namespace",
+		stats.UnitDimensionless)
+)
+
+const (
+	// maxRunningNamespaces bounds the number of namespaces the running PipelineRuns
+	// are reported for, to keep the cardinality of the metric bounded.
+	maxRunningNamespaces = 100
+	// otherNamespaces is the namespace the running PipelineRuns of the namespaces
+	// beyond maxRunningNamespaces are reported for.
+	otherNamespaces = "other"
 )
 
 // Recorder holds keys for Tekton metrics
@@ -58,6 +73,10 @@ type Recorder struct {
 	namespace   tag.Key
 	status      tag.Key
 
+	// running holds the keys of the running PipelineRuns, by reported namespace
+	running   map[string]sets.String
+	runningMu sync.Mutex
+
 	ReportingPeriod time.Duration
 }
 
@@ -66,6 +85,7 @@ type Recorder struct {
 func NewRecorder() (*Recorder, error) {
 	r := &Recorder{
 		initialized: true,
+		running:     map[string]sets.String{},
 
 		// Default to 30s intervals.
 		ReportingPeriod: 30 * time.Second,
@@ -113,6 +133,12 @@ func NewRecorder() (*Recorder, error) {
 			Measure:     runningPRsCount,
 			Aggregation: view.LastValue(),
 		},
+		&view.View{
+			Description: runningPRs.Description(),
+			Measure:     runningPRs,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{r.namespace},
+		},
 	)
 
 	if err != nil {
@@ -175,22 +201,87 @@ func (r *Recorder) RunningPipelineRuns(lister listers.PipelineRunLister) error {
 		return fmt.Errorf("failed to list pipelineruns while generating metrics : %v", err)
 	}
 
-	var runningPRs int
+	var runningCount int
+	// The running PipelineRuns are counted again by namespace, since the ones deleted
+	// while running aren't reconciled anymore.
+	running := map[string]sets.String{}
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	for ns := range r.running {
+		running[ns] = sets.NewString()
+	}
 	for _, pr := range prs {
 		if !pr.IsDone() {
-			runningPRs++
+			runningCount++
+			ns := runningNamespace(running, pr.Namespace)
+			if running[ns] == nil {
+				running[ns] = sets.NewString()
+			}
+			running[ns].Insert(pr.Namespace + "/" + pr.Name)
 		}
 	}
+	r.running = running
 
 	ctx, err := tag.New(context.Background())
 	if err != nil {
 		return err
 	}
-	metrics.Record(ctx, runningPRsCount.M(float64(runningPRs)))
+	metrics.Record(ctx, runningPRsCount.M(float64(runningCount)))
+
+	for ns, keys := range running {
+		if err := r.recordRunningInNamespace(ns, keys.Len()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordRunningPipelineRun updates the number of PipelineRuns running in the namespace
+// of pr when it starts or stops running.
+func (r *Recorder) RecordRunningPipelineRun(pr *v1beta1.PipelineRun) error {
+	if !r.initialized {
+		return fmt.Errorf("ignoring the metrics recording for %s , failed to initialize the metrics recorder", pr.Name)
+	}
+
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	ns := runningNamespace(r.running, pr.Namespace)
+	key := pr.Namespace + "/" + pr.Name
+	switch {
+	case !pr.IsDone() && !r.running[ns].Has(key):
+		if r.running[ns] == nil {
+			r.running[ns] = sets.NewString()
+		}
+		r.running[ns].Insert(key)
+	case pr.IsDone() && r.running[ns].Has(key):
+		r.running[ns].Delete(key)
+	default:
+		return nil
+	}
+	return r.recordRunningInNamespace(ns, r.running[ns].Len())
+}
 
+func (r *Recorder) recordRunningInNamespace(ns string, count int) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespace, ns),
+	)
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, runningPRs.M(float64(count)))
 	return nil
 }
 
+// runningNamespace returns the namespace the PipelineRuns running in ns are reported for,
+// which is otherNamespaces once maxRunningNamespaces namespaces are reported.
+func runningNamespace(running map[string]sets.String, ns string) string {
+	if _, ok := running[ns]; ok || len(running) < maxRunningNamespaces {
+		return ns
+	}
+	return otherNamespaces
+}
+
 // ReportRunningPipelineRuns invokes RunningPipelineRuns on our configured PeriodSeconds
 // until the context is cancelled.
 func (r *Recorder) ReportRunningPipelineRuns(ctx context.Context, lister listers.PipelineRunLister) {
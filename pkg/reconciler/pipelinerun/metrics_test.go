@@ -17,13 +17,17 @@ limitations under the License.
 package pipelinerun
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelineinformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1beta1"
 	fakepipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipelinerun/fake"
+	"github.com/tektoncd/pipeline/test/diff"
+	"go.opencensus.io/stats/view"
 	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/metrics/metricstest"
@@ -129,12 +133,55 @@ func TestRecordRunningPipelineRunsCount(t *testing.T) {
 	metrics, err := NewRecorder()
 	assertErrIsNil(err, "Recorder initialization failed", t)
 
+	// A PipelineRun deleted while running is only counted until the running PipelineRuns are listed
+	err = metrics.RecordRunningPipelineRun(tb.PipelineRun("deleted", tb.PipelineRunNamespace("ns-deleted")))
+	assertErrIsNil(err, "RecordRunningPipelineRun recording expected to return nil but got error", t)
+
 	err = metrics.RunningPipelineRuns(informer.Lister())
 	assertErrIsNil(err, "RunningPrsCount recording expected to return nil but got error", t)
 	metricstest.CheckLastValueData(t, "running_pipelineruns_count", map[string]string{}, 1)
+	checkRunningByNamespace(t, "running_pipelineruns", map[string]float64{"ns": 1, "ns-deleted": 0})
 
 }
 
+func TestRecordRunningPipelineRunByNamespace(t *testing.T) {
+	unregisterMetrics()
+	pipelineRun := func(name, ns string, status corev1.ConditionStatus) *v1beta1.PipelineRun {
+		return tb.PipelineRun(name, tb.PipelineRunNamespace(ns),
+			tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: status,
+			})))
+	}
+
+	metrics, err := NewRecorder()
+	assertErrIsNil(err, "Recorder initialization failed", t)
+
+	for _, pipelineRun := range []*v1beta1.PipelineRun{
+		pipelineRun("pipelinerun-1", "ns-1", corev1.ConditionUnknown),
+		pipelineRun("pipelinerun-2", "ns-1", corev1.ConditionUnknown),
+		pipelineRun("pipelinerun-1", "ns-2", corev1.ConditionUnknown),
+		// reconciled again while running
+		pipelineRun("pipelinerun-1", "ns-2", corev1.ConditionUnknown),
+		pipelineRun("pipelinerun-1", "ns-1", corev1.ConditionTrue),
+		// done before it was seen running
+		pipelineRun("pipelinerun-3", "ns-2", corev1.ConditionFalse),
+	} {
+		if err := metrics.RecordRunningPipelineRun(pipelineRun); err != nil {
+			t.Fatalf("RecordRunningPipelineRun: %v", err)
+		}
+	}
+	checkRunningByNamespace(t, "running_pipelineruns", map[string]float64{"ns-1": 1, "ns-2": 1})
+
+	// The namespaces beyond maxRunningNamespaces are reported together
+	for i := 0; i < maxRunningNamespaces+1; i++ {
+		if err := metrics.RecordRunningPipelineRun(pipelineRun("pipelinerun", fmt.Sprintf("bulk-%d", i), corev1.ConditionUnknown)); err != nil {
+			t.Fatalf("RecordRunningPipelineRun: %v", err)
+		}
+	}
+	checkRunningByNamespace(t, "running_pipelineruns", map[string]float64{"bulk-0": 1, otherNamespaces: 3})
+}
+
 func addPipelineRun(informer pipelineinformers.PipelineRunInformer, run, pipeline, ns string, status corev1.ConditionStatus, t *testing.T) {
 	t.Helper()
 
@@ -166,6 +213,26 @@ func assertErrIsNil(err error, message string, t *testing.T) {
 	}
 }
 
+// checkRunningByNamespace checks the last values of the metric name for the given namespaces.
+func checkRunningByNamespace(t *testing.T, name string, want map[string]float64) {
+	t.Helper()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatalf("Failed to retrieve the data of %s: %v", name, err)
+	}
+	got := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if _, ok := want[tag.Value]; ok && tag.Key.Name() == "namespace" {
+				got[tag.Value] = row.Data.(*view.LastValueData).Value
+			}
+		}
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected values of %s %s", name, diff.PrintWantGot(d))
+	}
+}
+
 func unregisterMetrics() {
-	metricstest.Unregister("pipelinerun_duration_seconds", "pipelinerun_count", "running_pipelineruns_count")
+	metricstest.Unregister("pipelinerun_duration_seconds", "pipelinerun_count", "running_pipelineruns_count", "running_pipelineruns")
 }
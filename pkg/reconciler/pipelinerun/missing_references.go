@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+)
+
+// shouldWaitForReferences returns true if pr should wait for the Tasks and Conditions
+// it references to be created rather than fail, which is the case when the
+// "missing-reference-policy" feature flag is "wait", the references don't exist rather
+// than failing to be retrieved, and pr started less than "missing-reference-timeout" ago.
+func shouldWaitForReferences(ctx context.Context, pr *v1beta1.PipelineRun, err *resources.ReferencesNotFoundError) bool {
+	cfg := config.FromContextOrDefaults(ctx)
+	if cfg.FeatureFlags.MissingReferencePolicy != config.WaitMissingReferencePolicy || !err.AllNotFound() {
+		return false
+	}
+	if pr.Status.StartTime == nil {
+		return true
+	}
+	return time.Since(pr.Status.StartTime.Time) < cfg.FeatureFlags.MissingReferenceTimeout
+}
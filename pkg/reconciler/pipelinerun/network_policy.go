@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonInvalidNetworkPolicies indicates that the network policies of a PipelineRun
+	// reference PipelineTasks which don't exist in its Pipeline.
+	ReasonInvalidNetworkPolicies = "InvalidNetworkPolicies"
+	// ReasonCouldntCreateNetworkPolicy indicates that the NetworkPolicies translating
+	// the network policies of a PipelineRun couldn't be created.
+	ReasonCouldntCreateNetworkPolicy = "CouldntCreateNetworkPolicy"
+)
+
+// networkPolicyName returns the name of the NetworkPolicy created for the rule of pr.
+func networkPolicyName(pr *v1beta1.PipelineRun, rule v1beta1.NetworkPolicyRule) string {
+	return kmeta.ChildName(pr.Name, "-"+rule.Name)
+}
+
+// pipelineTaskPodSelector selects the Pods of the TaskRuns of the PipelineTask name
+// of pr, through the labels getTaskrunLabels gives the TaskRuns, which propagate
+// them to their Pods.
+func pipelineTaskPodSelector(pr *v1beta1.PipelineRun, name string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			pipeline.GroupName + pipeline.PipelineRunLabelKey:  pr.Name,
+			pipeline.GroupName + pipeline.PipelineTaskLabelKey: name,
+		},
+	}
+}
+
+// networkPolicy returns the NetworkPolicy allowing the Pods of the TaskRuns of the
+// PipelineTasks rule.From to send traffic to the Pods of the TaskRuns of rule.To.
+func networkPolicy(pr *v1beta1.PipelineRun, rule v1beta1.NetworkPolicyRule) *networkingv1.NetworkPolicy {
+	var peers []networkingv1.NetworkPolicyPeer
+	for _, from := range rule.From {
+		selector := pipelineTaskPodSelector(pr, from)
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &selector})
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName(pr, rule),
+			Namespace: runNamespace(pr),
+			Labels: map[string]string{
+				pipeline.GroupName + pipeline.PipelineRunLabelKey: pr.Name,
+			},
+			OwnerReferences: ownerReferences(pr),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: pipelineTaskPodSelector(pr, rule.To),
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: rule.Ports,
+				From:  peers,
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// createNetworkPolicies creates a NetworkPolicy for each of the network policies of pr,
+// in the namespace its TaskRuns run in.
+func (c *Reconciler) createNetworkPolicies(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	logger := logging.FromContext(ctx)
+
+	var errs []error
+	for _, rule := range pr.Spec.NetworkPolicies {
+		np := networkPolicy(pr, rule)
+		_, err := c.KubeClientSet.NetworkingV1().NetworkPolicies(np.Namespace).Get(np.Name, metav1.GetOptions{})
+		switch {
+		case k8serrors.IsNotFound(err):
+			if _, err := c.KubeClientSet.NetworkingV1().NetworkPolicies(np.Namespace).Create(np); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create NetworkPolicy %s: %w", np.Name, err))
+				continue
+			}
+			logger.Infof("Created NetworkPolicy %s in namespace %s", np.Name, np.Namespace)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to retrieve NetworkPolicy %s: %w", np.Name, err))
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// cleanupNetworkPolicies deletes the NetworkPolicies created for the network policies
+// of pr once it is done.
+func (c *Reconciler) cleanupNetworkPolicies(pr *v1beta1.PipelineRun) error {
+	if pr.Spec.IsolatedNamespace {
+		// The NetworkPolicies are deleted with the isolated namespace.
+		return nil
+	}
+	var errs []error
+	for _, rule := range pr.Spec.NetworkPolicies {
+		name := networkPolicyName(pr, rule)
+		if err := c.KubeClientSet.NetworkingV1().NetworkPolicies(pr.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete NetworkPolicy %s: %w", name, err))
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+)
+
+// withNodePool returns a copy of podTemplate selecting the nodes of the node pool
+// of the PipelineTask of rprt, or podTemplate itself if the PipelineTask has no
+// node pool. The node labels selected by podTemplate, which can be set for the
+// PipelineTask in the taskRunSpecs of pr, take precedence over the node pool.
+func withNodePool(pr *v1beta1.PipelineRun, rprt *resources.ResolvedPipelineRunTask, podTemplate *v1beta1.PodTemplate) *v1beta1.PodTemplate {
+	var tags []string
+	if rprt.ResolvedTaskResources != nil && rprt.ResolvedTaskResources.TaskSpec != nil {
+		tags = rprt.ResolvedTaskResources.TaskSpec.Tags
+	}
+	np := pr.GetNodePool(rprt.PipelineTask.Name, tags)
+	if np == nil {
+		return podTemplate
+	}
+
+	// The pod template may be shared with the other PipelineTasks.
+	podTemplate = podTemplate.DeepCopy()
+	if podTemplate == nil {
+		podTemplate = &v1beta1.PodTemplate{}
+	}
+	if podTemplate.NodeSelector == nil {
+		podTemplate.NodeSelector = make(map[string]string, len(np.NodeSelector))
+	}
+	for key, value := range np.NodeSelector {
+		if _, ok := podTemplate.NodeSelector[key]; !ok {
+			podTemplate.NodeSelector[key] = value
+		}
+	}
+	return podTemplate
+}
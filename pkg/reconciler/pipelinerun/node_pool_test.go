@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestWithNodePool(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			NodePools: []v1beta1.NodePoolSpec{{
+				Name:         "build",
+				Tags:         []string{"build"},
+				NodeSelector: map[string]string{"example.com/pool": "build", "kubernetes.io/arch": "amd64"},
+			}, {
+				Name:          "deploy",
+				PipelineTasks: []string{"deploy"},
+				NodeSelector:  map[string]string{"example.com/pool": "deploy"},
+			}},
+		},
+	}
+	rprt := func(name string, tags ...string) *resources.ResolvedPipelineRunTask {
+		return &resources.ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{Name: name},
+			ResolvedTaskResources: &taskrunresources.ResolvedTaskResources{
+				TaskSpec: &v1beta1.TaskSpec{Tags: tags},
+			},
+		}
+	}
+	shared := &v1beta1.PodTemplate{SchedulerName: "custom", NodeSelector: map[string]string{"kubernetes.io/arch": "arm64"}}
+
+	for _, tc := range []struct {
+		name        string
+		rprt        *resources.ResolvedPipelineRunTask
+		podTemplate *v1beta1.PodTemplate
+		want        *v1beta1.PodTemplate
+	}{{
+		name: "matched by name without pod template",
+		rprt: rprt("deploy"),
+		want: &v1beta1.PodTemplate{NodeSelector: map[string]string{"example.com/pool": "deploy"}},
+	}, {
+		name:        "matched by tag with pod template",
+		rprt:        rprt("compile", "build"),
+		podTemplate: shared,
+		want: &v1beta1.PodTemplate{
+			SchedulerName: "custom",
+			NodeSelector:  map[string]string{"example.com/pool": "build", "kubernetes.io/arch": "arm64"},
+		},
+	}, {
+		name:        "no node pool",
+		rprt:        rprt("test", "test"),
+		podTemplate: shared,
+		want:        shared,
+	}, {
+		name: "no node pool without pod template",
+		rprt: rprt("test"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := withNodePool(pr, tc.rprt, tc.podTemplate)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("withNodePool() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+
+	// The pod template shared by the PipelineTasks isn't modified.
+	if d := cmp.Diff(map[string]string{"kubernetes.io/arch": "arm64"}, shared.NodeSelector); d != "" {
+		t.Errorf("withNodePool() modified the pod template %s", diff.PrintWantGot(d))
+	}
+}
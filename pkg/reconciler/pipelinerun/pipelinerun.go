@@ -38,11 +38,13 @@ import (
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	resourcelisters "github.com/tektoncd/pipeline/pkg/client/resource/listers/resource/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/contexts"
+	"github.com/tektoncd/pipeline/pkg/dag"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"github.com/tektoncd/pipeline/pkg/reconciler/ttl"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"github.com/tektoncd/pipeline/pkg/workspace"
@@ -98,6 +100,16 @@ const (
 	// ReasonCouldntCancel indicates that a PipelineRun was cancelled but attempting to update
 	// all of the running TaskRuns as cancelled failed.
 	ReasonCouldntCancel = "PipelineRunCouldntCancel"
+	// ReasonResultAliasUsed indicates that a PipelineRun references a Task result by one
+	// of its aliases instead of its name.
+	ReasonResultAliasUsed = "ResultAliasUsed"
+	// ReasonWaitingForReferences indicates that a PipelineRun waits for the Tasks or
+	// Conditions it references to be created.
+	ReasonWaitingForReferences = "WaitingForReferences"
+	// ReasonQuotaExceeded indicates that TaskRuns of a PipelineRun wait for enough
+	// of the ResourceQuotas of their namespace to be left to be created, or that
+	// the PipelineRun failed because they request more than the ResourceQuotas allow.
+	ReasonQuotaExceeded = "QuotaExceeded"
 )
 
 // Reconciler implements controller.Reconciler for Configuration resources.
@@ -110,20 +122,32 @@ type Reconciler struct {
 	pipelineRunLister listers.PipelineRunLister
 	pipelineLister    listers.PipelineLister
 	taskRunLister     listers.TaskRunLister
+	runLister         listersv1alpha1.RunLister
 	taskLister        listers.TaskLister
 	clusterTaskLister listers.ClusterTaskLister
 	resourceLister    resourcelisters.PipelineResourceLister
 	conditionLister   listersv1alpha1.ConditionLister
+	configMaps        *configMapWatch
+	resourceQuotas    *resourceQuotaWatch
 	cloudEventClient  cloudevent.CEClient
 	tracker           tracker.Interface
 	timeoutHandler    *timeout.Handler
+	ttlHandler        *ttl.Handler
+	bundles           *bundleCache
 	metrics           *Recorder
+	stats             *pipelineStats
+	checkpoints       *checkpointTracker
 	pvcHandler        volumeclaim.PvcHandler
+	// enqueueAfter reconciles a PipelineRun again after a delay, for its TaskRuns
+	// waiting for ResourceQuota to be created.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 var (
 	// Check that our Reconciler implements pipelinerunreconciler.Interface
 	_ pipelinerunreconciler.Interface = (*Reconciler)(nil)
+	// Check that our Reconciler implements pipelinerunreconciler.Finalizer
+	_ pipelinerunreconciler.Finalizer = (*Reconciler)(nil)
 )
 
 // ReconcileKind compares the actual state with the desired, and attempts to
@@ -136,6 +160,25 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 	// Read the initial condition
 	before := pr.Status.GetCondition(apis.ConditionSucceeded)
 
+	// The running PipelineRuns are counted by namespace with the status pr has once reconciled
+	defer func() {
+		if err := c.metrics.RecordRunningPipelineRun(pr); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+	}()
+
+	if !pr.HasStarted() && !pr.IsDone() && !pr.IsCancelled() && pr.ConcurrencyKey() != "" {
+		queued, err := c.applyConcurrencyPolicy(ctx, pr)
+		if err != nil {
+			logger.Errorf("Failed to apply the concurrency policy of PipelineRun %s: %v", pr.Name, err)
+			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
+		}
+		if queued {
+			// The PipelineRun is enqueued again once the PipelineRuns it waits for are done.
+			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, nil)
+		}
+	}
+
 	if !pr.HasStarted() {
 		pr.Status.InitializeConditions()
 		// In case node time was not synchronized, when controller has been scheduled to other nodes.
@@ -157,6 +200,11 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 		before = pr.Status.GetCondition(apis.ConditionSucceeded)
 	}
 
+	if err := c.reconcileConfigMapTriggers(ctx, pr); err != nil {
+		logger.Errorf("Failed to trigger a new PipelineRun from %s: %v", pr.Name, err)
+		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
+	}
+
 	if pr.IsDone() {
 		// We may be reading a version of the object that was stored at an older version
 		// and may not have had all of the assumed default specified.
@@ -171,18 +219,32 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 			logger.Errorf("Failed to delete StatefulSet for PipelineRun %s: %v", pr.Name, err)
 			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 		}
+		if err := c.cleanupNetworkPolicies(pr); err != nil {
+			logger.Errorf("Failed to delete NetworkPolicies for PipelineRun %s: %v", pr.Name, err)
+			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
+		}
 		c.timeoutHandler.Release(pr)
+		c.bundles.release(pr)
+		c.checkpoints.release(pr)
 		if err := c.updateTaskRunsStatusDirectly(pr); err != nil {
 			logger.Errorf("Failed to update TaskRun status for PipelineRun %s: %v", pr.Name, err)
 			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 		}
+		if err := c.deleteIsolatedNamespace(ctx, pr); err != nil {
+			logger.Errorf("Failed to delete isolated namespace for PipelineRun %s: %v", pr.Name, err)
+			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
+		}
 		go func(metrics *Recorder) {
 			err := metrics.DurationAndCount(pr)
 			if err != nil {
 				logger.Warnf("Failed to log the metrics : %v", err)
 			}
 		}(c.metrics)
-		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, nil)
+		c.stats.record(pr)
+		// The PipelineRun is only deleted once done with, and is otherwise enqueued
+		// again for when its TTL passes.
+		err := c.deleteIfExpired(ctx, pr)
+		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 	}
 
 	if pr.IsCancelled() {
@@ -196,8 +258,12 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 	}
 
-	// Make sure that the PipelineRun status is in sync with the actual TaskRuns
-	err := c.updatePipelineRunStatusFromInformer(ctx, pr)
+	// Make sure that the PipelineRun status is in sync with the actual TaskRuns,
+	// including those restored from its checkpoint after a restart.
+	err := c.restoreCheckpoint(ctx, pr)
+	if err == nil {
+		err = c.updatePipelineRunStatusFromInformer(ctx, pr)
+	}
 	if err != nil {
 		// This should not fail. Return the error so we can re-try later.
 		logger.Errorf("Error while syncing the pipelinerun status: %v", err.Error())
@@ -209,10 +275,26 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 	if err = c.reconcile(ctx, pr); err != nil {
 		logger.Errorf("Reconcile error: %v", err.Error())
 	}
+	if err := c.saveCheckpoint(pr); err != nil {
+		logger.Warnf("Failed to save the checkpoint of PipelineRun %s: %v", pr.Name, err)
+	}
 
 	return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 }
 
+// FinalizeKind cleans up what a deleted PipelineRun doesn't own, such as its isolated
+// namespace and the state the reconciler holds for it, in case it was deleted before
+// it was done.
+func (c *Reconciler) FinalizeKind(ctx context.Context, pr *v1beta1.PipelineRun) pkgreconciler.Event {
+	c.bundles.release(pr)
+	c.checkpoints.release(pr)
+	if err := c.deleteIsolatedNamespace(ctx, pr); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to delete isolated namespace for PipelineRun %s: %v", pr.Name, err)
+		return err
+	}
+	return nil
+}
+
 func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, pr *v1beta1.PipelineRun, beforeCondition *apis.Condition, previousError error) error {
 	logger := logging.FromContext(ctx)
 
@@ -231,6 +313,25 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, pr *v1
 	return merr
 }
 
+// deleteIfExpired deletes the finished PipelineRun once its TTL after finishing has
+// passed.
+func (c *Reconciler) deleteIfExpired(ctx context.Context, pr *v1beta1.PipelineRun) error {
+	logger := logging.FromContext(ctx)
+	deleted, err := c.ttlHandler.Handle(ctx, pr, pr.Status.CompletionTime, func() error {
+		return c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Delete(pr.Name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pr.UID},
+		})
+	})
+	if err != nil {
+		logger.Errorf("Failed to apply the TTL of PipelineRun %s: %v", pr.Name, err)
+		return err
+	}
+	if deleted {
+		logger.Infof("Deleted PipelineRun %s after its TTL", pr.Name)
+	}
+	return nil
+}
+
 func (c *Reconciler) updatePipelineResults(ctx context.Context, pr *v1beta1.PipelineRun) {
 	logger := logging.FromContext(ctx)
 
@@ -248,9 +349,27 @@ func (c *Reconciler) updatePipelineResults(ctx context.Context, pr *v1beta1.Pipe
 		return
 	}
 	resolvedResultRefs := resources.ResolvePipelineResultRefs(pr.Status, pipelineSpec.Results)
+	if len(pr.Status.PipelineResults) == 0 {
+		// The results are resolved again on every reconcile of the done PipelineRun,
+		// only warn about the aliases they use the first time.
+		emitResultAliasEvents(ctx, pr, resolvedResultRefs)
+	}
 	pr.Status.PipelineResults = getPipelineRunResults(pipelineSpec, resolvedResultRefs)
 }
 
+// emitResultAliasEvents emits a warning event for each of the resolved result references
+// which uses an alias of the result, so that it can be updated to its current name.
+func emitResultAliasEvents(ctx context.Context, pr *v1beta1.PipelineRun, resolvedResultRefs resources.ResolvedResultRefs) {
+	recorder := controller.GetEventRecorder(ctx)
+	for _, ref := range resolvedResultRefs {
+		if ref.AliasOf == "" {
+			continue
+		}
+		recorder.Eventf(pr, corev1.EventTypeWarning, ReasonResultAliasUsed,
+			"Result %q of pipeline task %q is referenced by its alias %q", ref.AliasOf, ref.ResultReference.PipelineTask, ref.ResultReference.Result)
+	}
+}
+
 func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) error {
 	logger := logging.FromContext(ctx)
 	// We may be reading a version of the object that was stored at an older version
@@ -276,22 +395,28 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		logger.Errorf("Failed to store PipelineSpec on PipelineRun.Status for pipelinerun %s: %v", pr.Name, err)
 	}
 
-	// Propagate labels from Pipeline to PipelineRun.
+	// Propagate labels from Pipeline to PipelineRun. Labels of the PipelineRun
+	// take precedence over the ones of the Pipeline.
 	if pr.ObjectMeta.Labels == nil {
 		pr.ObjectMeta.Labels = make(map[string]string, len(pipelineMeta.Labels)+1)
 	}
-	for key, value := range pipelineMeta.Labels {
-		pr.ObjectMeta.Labels[key] = value
+	for key, value := range podconvert.PropagatedMetadata(ctx, pipelineMeta.Labels) {
+		if _, ok := pr.ObjectMeta.Labels[key]; !ok {
+			pr.ObjectMeta.Labels[key] = value
+		}
 	}
 	//给响应的给当前的pipeline-run 打上label，例如："tekton.dev/pipeline=witlin-test"
 	pr.ObjectMeta.Labels[pipeline.GroupName+pipeline.PipelineLabelKey] = pipelineMeta.Name
 
-	// Propagate annotations from Pipeline to PipelineRun.
+	// Propagate annotations from Pipeline to PipelineRun. Annotations of the
+	// PipelineRun take precedence over the ones of the Pipeline.
 	if pr.ObjectMeta.Annotations == nil {
 		pr.ObjectMeta.Annotations = make(map[string]string, len(pipelineMeta.Annotations))
 	}
-	for key, value := range pipelineMeta.Annotations {
-		pr.ObjectMeta.Annotations[key] = value
+	for key, value := range podconvert.PropagatedMetadata(ctx, pipelineMeta.Annotations) {
+		if _, ok := pr.ObjectMeta.Annotations[key]; !ok {
+			pr.ObjectMeta.Annotations[key] = value
+		}
 	}
 
 	//构建dag任务
@@ -308,7 +433,7 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	// if a task in PipelineRunState is final task or not
 	// the finally section is optional and might not exist
 	// dfinally holds an empty Graph in the absence of finally clause
-	dfinally, err := dag.Build(v1beta1.PipelineTaskList(pipelineSpec.Finally))
+	dfinally, err := dag.Build(v1beta1.FinalTaskList(pipelineSpec.Finally))
 	if err != nil {
 		// This Run has failed, so we need to mark it as failed and stop reconciling it
 		pr.Status.MarkFailed(ReasonInvalidGraph,
@@ -385,9 +510,26 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		return controller.NewPermanentError(err)
 	}
 
+	// Ensure that the network policies reference tasks of the Pipeline.
+	if err := resources.ValidateNetworkPolicies(pipelineSpec, pr); err != nil {
+		pr.Status.MarkFailed(ReasonInvalidNetworkPolicies,
+			"PipelineRun %s/%s doesn't define networkPolicies correctly: %s",
+			pr.Namespace, pr.Name, err)
+		return controller.NewPermanentError(err)
+	}
+
 	// Apply parameter substitution from the PipelineRun
 	pipelineSpec = resources.ApplyParameters(pipelineSpec, pr)
 	pipelineSpec = resources.ApplyContexts(pipelineSpec, pipelineMeta.Name, pr)
+	pipelineSpec = resources.ApplyWorkspaces(pipelineSpec, pr)
+
+	// The names of the Tasks are only known once the params they reference are substituted.
+	if err := resources.ValidateTaskRefNames(pipelineSpec); err != nil {
+		pr.Status.MarkFailed(ReasonFailedValidation,
+			"Pipeline %s/%s can't be Run; it references Tasks with invalid names: %s",
+			pipelineMeta.Namespace, pipelineMeta.Name, err)
+		return controller.NewPermanentError(err)
+	}
 
 	// pipelineState holds a list of pipeline tasks after resolving conditions and pipeline resources
 	// pipelineState also holds a taskRun for each pipeline task after the taskRun is created
@@ -398,11 +540,15 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 			return c.taskLister.Tasks(pr.Namespace).Get(name)
 		},
 		func(name string) (*v1beta1.TaskRun, error) {
-			return c.taskRunLister.TaskRuns(pr.Namespace).Get(name)
+			return c.taskRunLister.TaskRuns(runNamespace(pr)).Get(name)
+		},
+		func(name string) (*v1alpha1.Run, error) {
+			return c.runLister.Runs(runNamespace(pr)).Get(name)
 		},
 		func(name string) (v1beta1.TaskInterface, error) {
 			return c.clusterTaskLister.Get(name)
 		},
+		c.getBundleTaskFunc(pr),
 		func(name string) (*v1alpha1.Condition, error) {
 			return c.conditionLister.Conditions(pr.Namespace).Get(name)
 		},
@@ -412,14 +558,23 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	if err != nil {
 		// This Run has failed, so we need to mark it as failed and stop reconciling it
 		switch err := err.(type) {
-		case *resources.TaskNotFoundError:
-			pr.Status.MarkFailed(ReasonCouldntGetTask,
-				"Pipeline %s/%s can't be Run; it contains Tasks that don't exist: %s",
-				pipelineMeta.Namespace, pipelineMeta.Name, err)
-		case *resources.ConditionNotFoundError:
-			pr.Status.MarkFailed(ReasonCouldntGetCondition,
-				"PipelineRun %s/%s can't be Run; it contains Conditions that don't exist:  %s",
-				pipelineMeta.Namespace, pr.Name, err)
+		case *resources.ReferencesNotFoundError:
+			if shouldWaitForReferences(ctx, pr, err) {
+				pr.Status.MarkRunning(ReasonWaitingForReferences,
+					"PipelineRun %s/%s is waiting for the references of Pipeline %s to be created: %s",
+					pr.Namespace, pr.Name, pipelineMeta.Name, err)
+				// The PipelineRun is reconciled again with a backoff, as for any transient error.
+				return err
+			}
+			if len(err.Tasks) > 0 {
+				pr.Status.MarkFailed(ReasonCouldntGetTask,
+					"Pipeline %s/%s can't be Run; it references Tasks or Conditions that don't exist: %s",
+					pipelineMeta.Namespace, pipelineMeta.Name, err)
+			} else {
+				pr.Status.MarkFailed(ReasonCouldntGetCondition,
+					"PipelineRun %s/%s can't be Run; it contains Conditions that don't exist:  %s",
+					pipelineMeta.Namespace, pr.Name, err)
+			}
 		default:
 			pr.Status.MarkFailed(ReasonFailedValidation,
 				"PipelineRun %s/%s can't be Run; couldn't resolve all references: %s",
@@ -429,6 +584,10 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	}
 
 	for _, rprt := range pipelineState {
+		if rprt.CustomTask {
+			// The params of custom tasks are validated by their controllers.
+			continue
+		}
 		err := taskrun.ValidateResolvedTaskResources(rprt.PipelineTask.Params, rprt.ResolvedTaskResources)
 		if err != nil {
 			logger.Errorf("Failed to validate pipelinerun %q with error %v", pr.Name, err)
@@ -437,10 +596,43 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		}
 	}
 
+	// Task workspaces declared read-only can't be bound to a volume other Tasks may write to.
+	if err := resources.ValidateReadOnlyWorkspaces(pipelineState, pr); err != nil {
+		pr.Status.MarkFailed(ReasonInvalidWorkspaceBinding,
+			"PipelineRun %s/%s doesn't bind Pipeline %s/%s's Workspaces correctly: %s",
+			pr.Namespace, pr.Name, pr.Namespace, pipelineMeta.Name, err)
+		return controller.NewPermanentError(err)
+	}
+
+	// the when expressions using the results of the tasks are evaluated once the tasks producing
+	// them are done
+	resources.ApplyWhenExpressionsResults(pipelineState)
+
 	if pipelineState.IsBeforeFirstTaskRun() {
+		claimOwner := claimOwnerReference(pr)
+		if pr.Spec.IsolatedNamespace {
+			ns, err := c.createIsolatedNamespace(ctx, pr)
+			if err != nil {
+				logger.Errorf("Failed to create isolated namespace for PipelineRun %s: %v", pr.Name, err)
+				pr.Status.MarkFailed(ReasonCouldntCreateIsolatedNamespace,
+					"Failed to create isolated namespace for PipelineRun %s/%s correctly: %s",
+					pr.Namespace, pr.Name, err)
+				return controller.NewPermanentError(err)
+			}
+			claimOwner.UID = ns.UID
+		}
+
+		if err := c.createNetworkPolicies(ctx, pr); err != nil {
+			logger.Errorf("Failed to create NetworkPolicies for PipelineRun %s: %v", pr.Name, err)
+			pr.Status.MarkFailed(ReasonCouldntCreateNetworkPolicy,
+				"Failed to create NetworkPolicies for PipelineRun %s/%s correctly: %s",
+				pr.Namespace, pr.Name, err)
+			return controller.NewPermanentError(err)
+		}
+
 		if pr.HasVolumeClaimTemplate() {
 			// create workspace PVC from template
-			if err = c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(pr.Spec.Workspaces, pr.GetOwnerReference(), pr.Namespace); err != nil {
+			if err = c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(pr.Spec.Workspaces, claimOwner, runNamespace(pr)); err != nil {
 				logger.Errorf("Failed to create PVC for PipelineRun %s: %v", pr.Name, err)
 				pr.Status.MarkFailed(volumeclaim.ReasonCouldntCreateWorkspacePVC,
 					"Failed to create PVC for PipelineRun %s/%s Workspaces correctly: %s",
@@ -451,7 +643,7 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 
 		if !c.isAffinityAssistantDisabled(ctx) {
 			// create Affinity Assistant (StatefulSet) so that taskRun pods that share workspace PVC achieve Node Affinity
-			if err = c.createAffinityAssistants(ctx, pr.Spec.Workspaces, pr, pr.Namespace); err != nil {
+			if err = c.createAffinityAssistants(ctx, pr.Spec.Workspaces, pr, runNamespace(pr)); err != nil {
 				logger.Errorf("Failed to create affinity assistant StatefulSet for PipelineRun %s: %v", pr.Name, err)
 				pr.Status.MarkFailed(ReasonCouldntCreateAffinityAssistantStatefulSet,
 					"Failed to create StatefulSet for PipelineRun %s/%s correctly: %s",
@@ -467,9 +659,14 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		return controller.NewPermanentError(err)
 	}
 
+	// The TaskRuns which don't fit in the quota left are created once the PipelineRun
+	// is reconciled again, after its status is updated with the ones created.
+	var quotaErr *quotaExceededError
 	if err := c.runNextSchedulableTask(ctx, pr, d, dfinally, pipelineState, as); err != nil {
-
-		return err
+		var ok bool
+		if quotaErr, ok = err.(*quotaExceededError); !ok {
+			return err
+		}
 	}
 
 	after := resources.GetPipelineConditionStatus(pr, pipelineState, logger, d, dfinally)
@@ -479,12 +676,29 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	case corev1.ConditionFalse:
 		pr.Status.MarkFailed(after.Reason, after.Message)
 	case corev1.ConditionUnknown:
-		pr.Status.MarkRunning(after.Reason, after.Message)
+		if quotaErr != nil {
+			pr.Status.MarkRunning(ReasonQuotaExceeded, "%s: %s", after.Message, quotaErr)
+		} else {
+			pr.Status.MarkRunning(after.Reason, after.Message)
+		}
 	}
 	// Read the condition the way it was set by the Mark* helpers
 	after = pr.Status.GetCondition(apis.ConditionSucceeded)
 	pr.Status.TaskRuns = getTaskRunsStatus(pr, pipelineState)
+	pr.Status.Runs = getRunsStatus(pr, pipelineState)
+	pr.Status.SkippedTasks = append(pipelineState.GetSkippedTasks(d), pipelineState.GetSkippedFinalTasks(d, dfinally)...)
 	logger.Infof("PipelineRun %s status is being set to %s", pr.Name, after)
+	if after.Reason == v1beta1.PipelineRunReasonTimedOut.String() {
+		// The TaskRuns time out on their own, but the controllers of custom tasks are
+		// told to stop their Runs.
+		if errs := cancelRuns(logger, pr, c.PipelineClientSet); len(errs) > 0 {
+			return fmt.Errorf("error(s) from cancelling Run(s) of timed out PipelineRun %s: %s", pr.Name, strings.Join(errs, "\n"))
+		}
+	}
+	if quotaErr != nil {
+		logger.Infof("PipelineRun %s waits for ResourceQuota to create TaskRuns: %s", pr.Name, quotaErr)
+		c.enqueueAfter(pr, quotaRetryDelay)
+	}
 	return nil
 }
 
@@ -512,8 +726,15 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 		nextRprts = pipelineState.GetNextTasks(candidateTasks)
 	}
 
-	// GetFinalTasks only returns tasks when a DAG is complete
-	nextRprts = append(nextRprts, pipelineState.GetFinalTasks(d, dfinally)...)
+	// the when expressions of the final tasks are evaluated once the DAG is complete, with
+	// the results and the execution status of the DAG tasks
+	resources.ApplyFinalTaskWhenExpressions(pipelineState, d, dfinally)
+
+	// GetFinalTasks only returns tasks when a DAG is complete, so the execution status
+	// of the DAG tasks can be applied to them
+	finalRprts := pipelineState.GetFinalTasks(d, dfinally)
+	resources.ApplyPipelineTaskStateContext(finalRprts, pipelineState.GetPipelineTaskStatus(d))
+	nextRprts = append(nextRprts, finalRprts...)
 
 	resolvedResultRefs, err := resources.ResolveResultRefs(pipelineState, nextRprts)
 	if err != nil {
@@ -521,6 +742,7 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 		pr.Status.MarkFailed(ReasonFailedValidation, err.Error())
 		return controller.NewPermanentError(err)
 	}
+	emitResultAliasEvents(ctx, pr, resolvedResultRefs)
 	resources.ApplyTaskResults(nextRprts, resolvedResultRefs)
 
 	//在pipeline-run这里增加一个状态，在这里需要check一下该状态是否pause，是--->不创建这个task，否----->创建。
@@ -529,13 +751,44 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 		return nil
 	}
 
+	var (
+		quotas   *quotaTracker
+		quotaErr *quotaExceededError
+	)
 	for _, rprt := range nextRprts {
 		if rprt == nil {
 			continue
 		}
 
-		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
+		if rprt.CustomTask {
+			rprt.Run, err = c.createRun(ctx, rprt, pr)
+			if err != nil {
+				recorder.Eventf(pr, corev1.EventTypeWarning, "RunCreationFailed", "Failed to create Run %q: %v", rprt.RunName, err)
+				return fmt.Errorf("error creating Run called %s for PipelineTask %s from PipelineRun %s: %w", rprt.RunName, rprt.PipelineTask.Name, pr.Name, err)
+			}
+			continue
+		}
 
+		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
+			if quotas == nil {
+				if quotas, err = c.newQuotaTracker(ctx, runNamespace(pr)); err != nil {
+					return err
+				}
+			}
+			requests := taskRunRequests(rprt.ResolvedTaskResources.TaskSpec)
+			if exceeded := quotas.neverFits(requests); exceeded != "" {
+				err := fmt.Errorf("TaskRun %q requests more than %s", rprt.TaskRunName, exceeded)
+				pr.Status.MarkFailed(ReasonQuotaExceeded, err.Error())
+				return controller.NewPermanentError(err)
+			}
+			if exceeded := quotas.reserve(requests); exceeded != "" {
+				recorder.Eventf(pr, corev1.EventTypeWarning, ReasonQuotaExceeded, "Not creating TaskRun %q yet, it requests more than is left of %s", rprt.TaskRunName, exceeded)
+				if quotaErr == nil {
+					quotaErr = &quotaExceededError{}
+				}
+				quotaErr.taskRunNames = append(quotaErr.taskRunNames, rprt.TaskRunName)
+				continue
+			}
 			rprt.TaskRun, err = c.createTaskRun(ctx, rprt, pr, as.StorageBasePath(pr))
 			if err != nil {
 				recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunName, err)
@@ -543,7 +796,7 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 			}
 		} else if !rprt.ResolvedConditionChecks.HasStarted() {
 			for _, rcc := range rprt.ResolvedConditionChecks {
-				rcc.ConditionCheck, err = c.makeConditionCheckContainer(rprt, rcc, pr)
+				rcc.ConditionCheck, err = c.makeConditionCheckContainer(ctx, rprt, rcc, pr)
 				if err != nil {
 					recorder.Eventf(pr, corev1.EventTypeWarning, "ConditionCheckCreationFailed", "Failed to create TaskRun %q: %v", rcc.ConditionCheckName, err)
 					return fmt.Errorf("error creating ConditionCheck container called %s for PipelineTask %s from PipelineRun %s: %w", rcc.ConditionCheckName, rprt.PipelineTask.Name, pr.Name, err)
@@ -551,6 +804,9 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 			}
 		}
 	}
+	if quotaErr != nil {
+		return quotaErr
+	}
 	return nil
 }
 
@@ -594,6 +850,7 @@ func getTaskRunsStatus(pr *v1beta1.PipelineRun, state []*resources.ResolvedPipel
 
 		if rprt.TaskRun != nil {
 			prtrs.Status = &rprt.TaskRun.Status
+			prtrs.NonFatal = rprt.IsNonFatalFailure()
 		}
 
 		if len(rprt.ResolvedConditionChecks) > 0 {
@@ -624,11 +881,29 @@ func getTaskRunsStatus(pr *v1beta1.PipelineRun, state []*resources.ResolvedPipel
 	return status
 }
 
+func getRunsStatus(pr *v1beta1.PipelineRun, state []*resources.ResolvedPipelineRunTask) map[string]*v1beta1.PipelineRunRunStatus {
+	status := make(map[string]*v1beta1.PipelineRunRunStatus)
+	for _, rprt := range state {
+		if rprt.Run == nil {
+			continue
+		}
+		prrs := pr.Status.Runs[rprt.RunName]
+		if prrs == nil {
+			prrs = &v1beta1.PipelineRunRunStatus{
+				PipelineTaskName: rprt.PipelineTask.Name,
+			}
+		}
+		prrs.Status = &rprt.Run.Status
+		status[rprt.RunName] = prrs
+	}
+	return status
+}
+
 func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1beta1.PipelineRun) error {
 	for taskRunName := range pr.Status.TaskRuns {
 		// TODO(dibyom): Add conditionCheck statuses here
 		prtrs := pr.Status.TaskRuns[taskRunName]
-		tr, err := c.taskRunLister.TaskRuns(pr.Namespace).Get(taskRunName)
+		tr, err := c.taskRunLister.TaskRuns(runNamespace(pr)).Get(taskRunName)
 		if err != nil {
 			// If the TaskRun isn't found, it just means it won't be run
 			if !errors.IsNotFound(err) {
@@ -638,13 +913,23 @@ func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1beta1.PipelineRun) error
 			prtrs.Status = &tr.Status
 		}
 	}
+	for runName, prrs := range pr.Status.Runs {
+		run, err := c.runLister.Runs(runNamespace(pr)).Get(runName)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("error retrieving Run %s: %w", runName, err)
+			}
+		} else {
+			prrs.Status = &run.Status
+		}
+	}
 	return nil
 }
 
 func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun, storageBasePath string) (*v1beta1.TaskRun, error) {
 	logger := logging.FromContext(ctx)
 
-	tr, _ := c.taskRunLister.TaskRuns(pr.Namespace).Get(rprt.TaskRunName)
+	tr, _ := c.taskRunLister.TaskRuns(runNamespace(pr)).Get(rprt.TaskRunName)
 	if tr != nil {
 		//is a retry
 		addRetryHistory(tr)
@@ -653,17 +938,18 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 			Type:   apis.ConditionSucceeded,
 			Status: corev1.ConditionUnknown,
 		})
-		return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).UpdateStatus(tr)
+		return c.PipelineClientSet.TektonV1beta1().TaskRuns(runNamespace(pr)).UpdateStatus(tr)
 	}
 
 	serviceAccountName, podTemplate := pr.GetTaskRunSpecs(rprt.PipelineTask.Name)
+	podTemplate = withNodePool(pr, rprt, podTemplate)
 	tr = &v1beta1.TaskRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            rprt.TaskRunName,
-			Namespace:       pr.Namespace,
-			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
-			Labels:          combineTaskRunAndTaskSpecLabels(pr, rprt.PipelineTask),
-			Annotations:     combineTaskRunAndTaskSpecAnnotations(pr, rprt.PipelineTask),
+			Namespace:       runNamespace(pr),
+			OwnerReferences: ownerReferences(pr),
+			Labels:          combineTaskRunAndTaskSpecLabels(ctx, pr, rprt.PipelineTask),
+			Annotations:     combineTaskRunAndTaskSpecAnnotations(ctx, pr, rprt.PipelineTask),
 		},
 		Spec: v1beta1.TaskRunSpec{
 			Params:             rprt.PipelineTask.Params,
@@ -672,7 +958,13 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 			PodTemplate:        podTemplate,
 		}}
 
-	if rprt.ResolvedTaskResources.TaskName != "" {
+	if rprt.PipelineTask.TaskRef != nil && rprt.PipelineTask.TaskRef.Bundle != "" {
+		// Tasks from bundles aren't installed in the cluster, so the TaskRun embeds their spec
+		tr.Spec.TaskSpec = rprt.ResolvedTaskResources.TaskSpec
+	} else if pr.Spec.IsolatedNamespace && rprt.ResolvedTaskResources.TaskName != "" && rprt.ResolvedTaskResources.Kind != v1beta1.ClusterTaskKind {
+		// Tasks aren't installed in the isolated namespace, so the TaskRun embeds their spec
+		tr.Spec.TaskSpec = rprt.ResolvedTaskResources.TaskSpec
+	} else if rprt.ResolvedTaskResources.TaskName != "" {
 		tr.Spec.TaskRef = &v1beta1.TaskRef{
 			Name: rprt.ResolvedTaskResources.TaskName,
 			Kind: rprt.ResolvedTaskResources.Kind,
@@ -683,16 +975,28 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 
 	var pipelinePVCWorkspaceName string
 	pipelineRunWorkspaces := make(map[string]v1beta1.WorkspaceBinding)
-	for _, binding := range pr.Spec.Workspaces {
+	for _, binding := range resources.ApplyWorkspaceSubPaths(pr.Status.PipelineSpec, pr.Labels[pipeline.GroupName+pipeline.PipelineLabelKey], pr) {
 		pipelineRunWorkspaces[binding.Name] = binding
 	}
 	for _, ws := range rprt.PipelineTask.Workspaces {
 		taskWorkspaceName, pipelineTaskSubPath, pipelineWorkspaceName := ws.Name, ws.SubPath, ws.Workspace
+		bind, err := ws.IsConditionTrue()
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition of workspace %q of pipeline task %q: %w", taskWorkspaceName, rprt.PipelineTask.Name, err)
+		}
+		if !bind {
+			// The workspaces of Tasks are required, so the Task gets an emptyDir instead
+			tr.Spec.Workspaces = append(tr.Spec.Workspaces, v1beta1.WorkspaceBinding{
+				Name:     taskWorkspaceName,
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			})
+			continue
+		}
 		if b, hasBinding := pipelineRunWorkspaces[pipelineWorkspaceName]; hasBinding {
 			if b.PersistentVolumeClaim != nil || b.VolumeClaimTemplate != nil {
 				pipelinePVCWorkspaceName = pipelineWorkspaceName
 			}
-			tr.Spec.Workspaces = append(tr.Spec.Workspaces, taskWorkspaceByWorkspaceVolumeSource(b, taskWorkspaceName, pipelineTaskSubPath, pr.GetOwnerReference()))
+			tr.Spec.Workspaces = append(tr.Spec.Workspaces, taskWorkspaceByWorkspaceVolumeSource(b, taskWorkspaceName, pipelineTaskSubPath, claimOwnerReference(pr)))
 		} else {
 			return nil, fmt.Errorf("expected workspace %q to be provided by pipelinerun for pipeline task %q", pipelineWorkspaceName, rprt.PipelineTask.Name)
 		}
@@ -704,7 +1008,31 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 
 	resources.WrapSteps(&tr.Spec, rprt.PipelineTask, rprt.ResolvedTaskResources.Inputs, rprt.ResolvedTaskResources.Outputs, storageBasePath)
 	logger.Infof("Creating a new TaskRun object %s", rprt.TaskRunName)
-	return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Create(tr)
+	return c.PipelineClientSet.TektonV1beta1().TaskRuns(runNamespace(pr)).Create(tr)
+}
+
+// createRun creates the Run of the custom task of rprt, which is run by the
+// controller of the custom task. This controller also handles the timeout and
+// the retries of the PipelineTask, which are passed in the spec of the Run.
+func (c *Reconciler) createRun(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun) (*v1alpha1.Run, error) {
+	logger := logging.FromContext(ctx)
+	r := &v1alpha1.Run{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            rprt.RunName,
+			Namespace:       runNamespace(pr),
+			OwnerReferences: ownerReferences(pr),
+			Labels:          getTaskrunLabels(ctx, pr, rprt.PipelineTask.Name),
+			Annotations:     getTaskrunAnnotations(ctx, pr),
+		},
+		Spec: v1alpha1.RunSpec{
+			Ref:     rprt.PipelineTask.TaskRef,
+			Params:  rprt.PipelineTask.Params,
+			Timeout: getTaskRunTimeout(pr, rprt),
+			Retries: rprt.PipelineTask.Retries,
+		},
+	}
+	logger.Infof("Creating a new Run object %s", rprt.RunName)
+	return c.PipelineClientSet.TektonV1alpha1().Runs(runNamespace(pr)).Create(r)
 }
 
 // taskWorkspaceByWorkspaceVolumeSource is returning the WorkspaceBinding with the TaskRun specified name.
@@ -719,7 +1047,8 @@ func taskWorkspaceByWorkspaceVolumeSource(wb v1beta1.WorkspaceBinding, taskWorks
 
 	// apply template
 	binding := v1beta1.WorkspaceBinding{
-		SubPath: combinedSubPath(wb.SubPath, pipelineTaskSubPath),
+		SubPath:  combinedSubPath(wb.SubPath, pipelineTaskSubPath),
+		ReadOnly: wb.ReadOnly,
 		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 			ClaimName: volumeclaim.GetPersistentVolumeClaimName(wb.VolumeClaimTemplate, wb, owner),
 		},
@@ -748,24 +1077,23 @@ func addRetryHistory(tr *v1beta1.TaskRun) {
 func clearStatus(tr *v1beta1.TaskRun) {
 	tr.Status.StartTime = nil
 	tr.Status.CompletionTime = nil
-	tr.Status.PodName = ""
+	// The status of the previous attempt is only kept in its retry status.
+	taskrun.ClearAttemptStatus(tr)
 }
 
-func getTaskrunAnnotations(pr *v1beta1.PipelineRun) map[string]string {
+func getTaskrunAnnotations(ctx context.Context, pr *v1beta1.PipelineRun) map[string]string {
 	// Propagate annotations from PipelineRun to TaskRun.
-	annotations := make(map[string]string, len(pr.ObjectMeta.Annotations)+1)
-	for key, val := range pr.ObjectMeta.Annotations {
-		annotations[key] = val
+	annotations := podconvert.PropagatedMetadata(ctx, pr.ObjectMeta.Annotations)
+	if pr.Spec.IsolatedNamespace {
+		// The PipelineRun can't own the TaskRuns of another namespace, they are found through this annotation.
+		annotations[isolatedFromNamespaceAnnotation] = pr.Namespace
 	}
 	return annotations
 }
 
-func getTaskrunLabels(pr *v1beta1.PipelineRun, pipelineTaskName string) map[string]string {
+func getTaskrunLabels(ctx context.Context, pr *v1beta1.PipelineRun, pipelineTaskName string) map[string]string {
 	// Propagate labels from PipelineRun to TaskRun.
-	labels := make(map[string]string, len(pr.ObjectMeta.Labels)+1)
-	for key, val := range pr.ObjectMeta.Labels {
-		labels[key] = val
-	}
+	labels := podconvert.PropagatedMetadata(ctx, pr.ObjectMeta.Labels)
 	labels[pipeline.GroupName+pipeline.PipelineRunLabelKey] = pr.Name
 	if pipelineTaskName != "" {
 		labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey] = pipelineTaskName
@@ -773,9 +1101,9 @@ func getTaskrunLabels(pr *v1beta1.PipelineRun, pipelineTaskName string) map[stri
 	return labels
 }
 
-func combineTaskRunAndTaskSpecLabels(pr *v1beta1.PipelineRun, pipelineTask *v1beta1.PipelineTask) map[string]string {
+func combineTaskRunAndTaskSpecLabels(ctx context.Context, pr *v1beta1.PipelineRun, pipelineTask *v1beta1.PipelineTask) map[string]string {
 	var tsLabels map[string]string
-	trLabels := getTaskrunLabels(pr, pipelineTask.Name)
+	trLabels := getTaskrunLabels(ctx, pr, pipelineTask.Name)
 
 	if pipelineTask.TaskSpec != nil {
 		tsLabels = pipelineTask.TaskSpecMetadata().Labels
@@ -795,9 +1123,9 @@ func combineTaskRunAndTaskSpecLabels(pr *v1beta1.PipelineRun, pipelineTask *v1be
 	return labels
 }
 
-func combineTaskRunAndTaskSpecAnnotations(pr *v1beta1.PipelineRun, pipelineTask *v1beta1.PipelineTask) map[string]string {
+func combineTaskRunAndTaskSpecAnnotations(ctx context.Context, pr *v1beta1.PipelineRun, pipelineTask *v1beta1.PipelineTask) map[string]string {
 	var tsAnnotations map[string]string
-	trAnnotations := getTaskrunAnnotations(pr)
+	trAnnotations := getTaskrunAnnotations(ctx, pr)
 
 	if pipelineTask.TaskSpec != nil {
 		tsAnnotations = pipelineTask.TaskSpecMetadata().Annotations
@@ -873,8 +1201,8 @@ func (c *Reconciler) updateLabelsAndAnnotations(pr *v1beta1.PipelineRun) (*v1bet
 	return newPr, nil
 }
 
-func (c *Reconciler) makeConditionCheckContainer(rprt *resources.ResolvedPipelineRunTask, rcc *resources.ResolvedConditionCheck, pr *v1beta1.PipelineRun) (*v1beta1.ConditionCheck, error) {
-	labels := getTaskrunLabels(pr, rprt.PipelineTask.Name)
+func (c *Reconciler) makeConditionCheckContainer(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, rcc *resources.ResolvedConditionCheck, pr *v1beta1.PipelineRun) (*v1beta1.ConditionCheck, error) {
+	labels := getTaskrunLabels(ctx, pr, rprt.PipelineTask.Name)
 	labels[pipeline.GroupName+pipeline.ConditionCheckKey] = rcc.ConditionCheckName
 	labels[pipeline.GroupName+pipeline.ConditionNameKey] = rcc.Condition.Name
 
@@ -883,7 +1211,7 @@ func (c *Reconciler) makeConditionCheckContainer(rprt *resources.ResolvedPipelin
 	}
 
 	// Propagate annotations from PipelineRun to TaskRun.
-	annotations := getTaskrunAnnotations(pr)
+	annotations := getTaskrunAnnotations(ctx, pr)
 
 	for key, value := range rcc.Condition.ObjectMeta.Annotations {
 		annotations[key] = value
@@ -894,11 +1222,12 @@ func (c *Reconciler) makeConditionCheckContainer(rprt *resources.ResolvedPipelin
 		return nil, fmt.Errorf("failed to get TaskSpec from Condition: %w", err)
 	}
 	serviceAccountName, podTemplate := pr.GetTaskRunSpecs(rprt.PipelineTask.Name)
+	podTemplate = withNodePool(pr, rprt, podTemplate)
 	tr := &v1beta1.TaskRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            rcc.ConditionCheckName,
-			Namespace:       pr.Namespace,
-			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
+			Namespace:       runNamespace(pr),
+			OwnerReferences: ownerReferences(pr),
 			Labels:          labels,
 			Annotations:     annotations,
 		},
@@ -913,7 +1242,7 @@ func (c *Reconciler) makeConditionCheckContainer(rprt *resources.ResolvedPipelin
 			PodTemplate: podTemplate,
 		}}
 
-	cctr, err := c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Create(tr)
+	cctr, err := c.PipelineClientSet.TektonV1beta1().TaskRuns(runNamespace(pr)).Create(tr)
 	cc := v1beta1.ConditionCheck(*cctr)
 	return &cc, err
 }
@@ -929,16 +1258,45 @@ func storePipelineSpec(ctx context.Context, pr *v1beta1.PipelineRun, ps *v1beta1
 func (c *Reconciler) updatePipelineRunStatusFromInformer(ctx context.Context, pr *v1beta1.PipelineRun) error {
 	logger := logging.FromContext(ctx)
 
-	pipelineRunLabels := getTaskrunLabels(pr, "")
-	taskRuns, err := c.taskRunLister.TaskRuns(pr.Namespace).List(labels.SelectorFromSet(pipelineRunLabels))
+	// Only select on the PipelineRun label, since the other labels of the
+	// PipelineRun may not have been propagated to its TaskRuns.
+	pipelineRunLabels := map[string]string{pipeline.GroupName + pipeline.PipelineRunLabelKey: pr.Name}
+	taskRuns, err := c.taskRunLister.TaskRuns(runNamespace(pr)).List(labels.SelectorFromSet(pipelineRunLabels))
 	if err != nil {
 		logger.Errorf("could not list TaskRuns %#v", err)
 		return err
 	}
 	pr.Status = updatePipelineRunStatusFromTaskRuns(logger, pr.Name, pr.Status, taskRuns)
+	runs, err := c.runLister.Runs(runNamespace(pr)).List(labels.SelectorFromSet(pipelineRunLabels))
+	if err != nil {
+		logger.Errorf("could not list Runs %#v", err)
+		return err
+	}
+	pr.Status = updatePipelineRunStatusFromRuns(pr.Status, runs)
 	return nil
 }
 
+// updatePipelineRunStatusFromRuns adds the Runs missing from prStatus, which
+// were created but couldn't be recorded in the status of the PipelineRun.
+func updatePipelineRunStatusFromRuns(prStatus v1beta1.PipelineRunStatus, runs []*v1alpha1.Run) v1beta1.PipelineRunStatus {
+	// If no Run was found, nothing to be done. We never remove runs from the status
+	if len(runs) == 0 {
+		return prStatus
+	}
+	if prStatus.Runs == nil {
+		prStatus.Runs = make(map[string]*v1beta1.PipelineRunRunStatus)
+	}
+	for _, run := range runs {
+		if _, ok := prStatus.Runs[run.Name]; !ok {
+			prStatus.Runs[run.Name] = &v1beta1.PipelineRunRunStatus{
+				PipelineTaskName: run.GetLabels()[pipeline.GroupName+pipeline.PipelineTaskLabelKey],
+				Status:           &run.Status,
+			}
+		}
+	}
+	return prStatus
+}
+
 func updatePipelineRunStatusFromTaskRuns(logger *zap.SugaredLogger, prName string, prStatus v1beta1.PipelineRunStatus, trs []*v1beta1.TaskRun) v1beta1.PipelineRunStatus {
 	// If no TaskRun was found, nothing to be done. We never remove taskruns from the status
 	if trs == nil || len(trs) == 0 {
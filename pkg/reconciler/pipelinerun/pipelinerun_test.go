@@ -18,7 +18,10 @@ package pipelinerun
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
@@ -26,6 +29,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	tbv1alpha1 "github.com/tektoncd/pipeline/internal/builder/v1alpha1"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
@@ -33,6 +37,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
@@ -45,11 +50,19 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/configmap"
@@ -129,14 +142,19 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 // getPipelineRunController returns an instance of the PipelineRun controller/reconciler that has been seeded with
 // d, where d represents the state of the system (existing resources) needed for the test.
 func getPipelineRunController(t *testing.T, d test.Data) (test.Assets, func()) {
+	return getPipelineRunControllerWithClock(t, d, clock.RealClock{})
+}
+
+func getPipelineRunControllerWithClock(t *testing.T, d test.Data, clock clock.Clock) (test.Assets, func()) {
 	//unregisterMetrics()
 	ctx, _ := ttesting.SetupFakeContext(t)
 	ctx, cancel := context.WithCancel(ctx)
 	ensureConfigurationConfigMapsExist(&d)
+	d.PipelineRuns = withFinalizer(d.PipelineRuns)
 	c, informers := test.SeedTestData(t, ctx, d)
 	configMapWatcher := configmap.NewInformedWatcher(c.Kube, system.GetNamespace())
 
-	ctl := NewController(namespace, images)(ctx, configMapWatcher)
+	ctl := NewController(namespace, images, clock)(ctx, configMapWatcher)
 
 	if la, ok := ctl.Reconciler.(reconciler.LeaderAware); ok {
 		la.Promote(reconciler.UniversalBucket(), func(reconciler.Bucket, types.NamespacedName) {})
@@ -154,6 +172,23 @@ func getPipelineRunController(t *testing.T, d test.Data) (test.Assets, func()) {
 	}, cancel
 }
 
+// pipelineRunFinalizer is the finalizer the reconciler adds to the PipelineRuns it reconciles.
+const pipelineRunFinalizer = "pipelineruns.tekton.dev"
+
+// withFinalizer returns copies of prs which already have the finalizer of the reconciler,
+// so that the tests aren't concerned with it being added.
+func withFinalizer(prs []*v1beta1.PipelineRun) []*v1beta1.PipelineRun {
+	var copies []*v1beta1.PipelineRun
+	for _, pr := range prs {
+		pr = pr.DeepCopy()
+		if pr.DeletionTimestamp == nil && !sets.NewString(pr.Finalizers...).Has(pipelineRunFinalizer) {
+			pr.Finalizers = append(pr.Finalizers, pipelineRunFinalizer)
+		}
+		copies = append(copies, pr)
+	}
+	return copies
+}
+
 // conditionCheckFromTaskRun converts takes a pointer to a TaskRun and wraps it into a ConditionCheck
 func conditionCheckFromTaskRun(tr *v1beta1.TaskRun) *v1beta1.ConditionCheck {
 	cc := v1beta1.ConditionCheck(*tr)
@@ -741,6 +776,97 @@ func TestReconcile_InvalidPipelineRuns(t *testing.T) {
 	}
 }
 
+// TestReconcile_MissingReferences runs "Reconcile" on a PipelineRun whose Pipeline references
+// Tasks and Conditions which don't exist. It verifies that all of them are reported at once and
+// that no TaskRun is created, both when the PipelineRun fails right away and when it waits for
+// them to be created, per the "missing-reference-policy" feature flag.
+func TestReconcile_MissingReferences(t *testing.T) {
+	ts := []*v1beta1.Task{tb.Task("a-task-that-exists", tb.TaskNamespace("foo"))}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("pipeline-missing-references", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("task-1", "missing-task-1"),
+		tb.PipelineTask("task-2", "a-task-that-exists", tb.PipelineTaskCondition("missing-condition")),
+		tb.PipelineTask("task-3", "missing-task-3"),
+	))}
+	wantMessage := `Couldn't retrieve Task "missing-task-1": task.tekton.dev "missing-task-1" not found; ` +
+		`Couldn't retrieve Task "missing-task-3": task.tekton.dev "missing-task-3" not found; ` +
+		`Couldn't retrieve Condition "missing-condition": condition.tekton.dev "missing-condition" not found`
+
+	for _, tc := range []struct {
+		name           string
+		policy         string
+		startedAgo     time.Duration
+		wantStatus     corev1.ConditionStatus
+		wantReason     string
+		permanentError bool
+	}{{
+		name:           "fail policy",
+		policy:         config.FailMissingReferencePolicy,
+		wantStatus:     corev1.ConditionFalse,
+		wantReason:     ReasonCouldntGetTask,
+		permanentError: true,
+	}, {
+		name:       "wait policy",
+		policy:     config.WaitMissingReferencePolicy,
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: ReasonWaitingForReferences,
+	}, {
+		name:           "wait policy after the timeout",
+		policy:         config.WaitMissingReferencePolicy,
+		startedAgo:     10 * time.Minute,
+		wantStatus:     corev1.ConditionFalse,
+		wantReason:     ReasonCouldntGetTask,
+		permanentError: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("pipelinerun-missing-references", tb.PipelineRunNamespace("foo"), tb.PipelineRunSpec("pipeline-missing-references"))
+			if tc.startedAgo != 0 {
+				pr.Status.StartTime = &metav1.Time{Time: time.Now().Add(-tc.startedAgo)}
+			}
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{pr},
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"missing-reference-policy":  tc.policy,
+						"missing-reference-timeout": "5m",
+					},
+				}},
+			}
+			testAssets, cancel := getPipelineRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			err := c.Reconciler.Reconcile(context.Background(), "foo/"+pr.Name)
+			if err == nil {
+				t.Fatalf("Expected an error to be returned by Reconcile, got nil instead")
+			}
+			if controller.IsPermanentError(err) != tc.permanentError {
+				t.Fatalf("Expected the error to be permanent: %v but got: %v", tc.permanentError, err)
+			}
+
+			reconciledRun, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get(pr.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Somehow had error getting reconciled run out of fake client: %s", err)
+			}
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if condition == nil || condition.Status != tc.wantStatus || condition.Reason != tc.wantReason {
+				t.Fatalf("Expected the PipelineRun to be %s with reason %s but got %v", tc.wantStatus, tc.wantReason, condition)
+			}
+			if !strings.HasSuffix(condition.Message, wantMessage) {
+				t.Errorf("Expected the condition message to list all the missing references but got %q", condition.Message)
+			}
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					t.Errorf("Expected no TaskRun to be created but got %v", a)
+				}
+			}
+		})
+	}
+}
+
 func TestReconcile_InvalidPipelineRunNames(t *testing.T) {
 	// TestReconcile_InvalidPipelineRunNames runs "Reconcile" on several PipelineRuns that have invalid names.
 	// It verifies that reconcile fails, how it fails and which events are triggered.
@@ -1164,14 +1290,257 @@ func TestReconcileOnPausedPipelineRun(t *testing.T) {
 	defer prt.Cancel()
 
 	wantEvents := []string{
-		"Normal Started",
 		"Normal PipelineRunPause Pause PipelineRun \"test-pipeline-run-paused\"",
+		"Normal Started",
 	}
-	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-paused", wantEvents, false)
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-paused", wantEvents, false)
 
-	// This PipelineRun should still be complete and false, and the status should reflect that
-	if !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
-		t.Errorf("Expected PipelineRun status to be complete and false, but was %v", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	// This PipelineRun should still be running but paused, and the status should reflect that
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonPause.String() {
+		t.Errorf("Expected PipelineRun status to be unknown and paused, but was %v", condition)
+	}
+	// No TaskRun should be created while the PipelineRun is paused
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+			t.Errorf("Expected no TaskRun to be created for a paused PipelineRun, but got %v", a)
+		}
+	}
+}
+
+func TestReconcileWithContinueOnFailure(t *testing.T) {
+	// TestReconcileWithContinueOnFailure runs "Reconcile" on a PipelineRun whose first TaskRun failed
+	// while its PipelineTask allows to continue on failure. It checks that the dependent PipelineTask
+	// is started, that the PipelineRun keeps running and that the failure is marked as non-fatal.
+	taskRunName := "test-pipeline-run-continue-on-failure-hello-world-1"
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-continue-on-failure",
+		tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+			tb.PipelineRunTaskRunsStatus(taskRunName, &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+				Status:           &v1beta1.TaskRunStatus{},
+			}),
+		),
+	)}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.ContinueOnFailure()),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun(taskRunName,
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-continue-on-failure"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-continue-on-failure"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}),
+			),
+		),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-continue-on-failure", []string{}, false)
+
+	created := []string{}
+	for _, action := range clients.Pipeline.Actions() {
+		if action != nil && action.Matches("create", "taskruns") {
+			tr := action.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+			created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+		}
+	}
+	if d := cmp.Diff([]string{"hello-world-2"}, created); d != "" {
+		t.Errorf("Expected the dependent TaskRun to be created %s", diff.PrintWantGot(d))
+	}
+
+	if !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+		t.Errorf("Expected PipelineRun to still be running, but was %v", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+
+	trStatus, ok := reconciledRun.Status.TaskRuns[taskRunName]
+	if !ok {
+		t.Fatalf("Expected PipelineRun status to include TaskRun %s", taskRunName)
+	}
+	if !trStatus.NonFatal {
+		t.Errorf("Expected the failure of TaskRun %s to be marked as non-fatal", taskRunName)
+	}
+}
+
+func TestReconcileWithIgnoredFinalTaskFailure(t *testing.T) {
+	// TestReconcileWithIgnoredFinalTaskFailure runs "Reconcile" on PipelineRuns whose final task
+	// ignoring its failure failed. It checks that the PipelineRun only fails when its DAG task did,
+	// and that the failure of the final task is recorded as non-fatal.
+	for _, tc := range []struct {
+		name          string
+		dagStatus     corev1.ConditionStatus
+		wantStatus    corev1.ConditionStatus
+		wantReason    string
+		wantCondition string
+	}{{
+		name:          "dag task succeeded",
+		dagStatus:     corev1.ConditionTrue,
+		wantStatus:    corev1.ConditionTrue,
+		wantReason:    v1beta1.PipelineRunReasonCompleted.String(),
+		wantCondition: "Tasks Completed: 2 (Failed: 0, Failed (non-fatal): 1, Cancelled 0), Skipped: 0",
+	}, {
+		name:          "dag task failed",
+		dagStatus:     corev1.ConditionFalse,
+		wantStatus:    corev1.ConditionFalse,
+		wantReason:    v1beta1.PipelineRunReasonFailed.String(),
+		wantCondition: "Tasks Completed: 2 (Failed: 1, Failed (non-fatal): 1, Cancelled 0), Skipped: 0",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prName := "test-pipeline-run-ignored-final-failure"
+			taskRun := func(name, pipelineTask string, status corev1.ConditionStatus) *v1beta1.TaskRun {
+				return tb.TaskRun(name,
+					tb.TaskRunNamespace("foo"),
+					tb.TaskRunOwnerReference("PipelineRun", prName),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, prName),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, pipelineTask),
+					tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+					tb.TaskRunStatus(
+						tb.StatusCondition(apis.Condition{
+							Type:   apis.ConditionSucceeded,
+							Status: status,
+						}),
+					),
+				)
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun(prName,
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+					tb.PipelineRunTaskRunsStatus(prName+"-hello-world", &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "hello-world",
+						Status:           &v1beta1.TaskRunStatus{},
+					}),
+					tb.PipelineRunTaskRunsStatus(prName+"-notify", &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "notify",
+						Status:           &v1beta1.TaskRunStatus{},
+					}),
+				),
+			)}
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world", "hello-world"),
+				tb.FinalPipelineTask("notify", "hello-world", tb.FailurePolicy(v1beta1.PipelineTaskFailurePolicyIgnore)),
+			))}
+			ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+			trs := []*v1beta1.TaskRun{
+				taskRun(prName+"-hello-world", "hello-world", tc.dagStatus),
+				taskRun(prName+"-notify", "notify", corev1.ConditionFalse),
+			}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+				ConfigMaps:   alphaFeatureFlags(),
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, _ := prt.reconcileRun("foo", prName, []string{}, false)
+
+			want := &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  tc.wantStatus,
+				Reason:  tc.wantReason,
+				Message: tc.wantCondition,
+			}
+			if d := cmp.Diff(want, reconciledRun.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+				t.Errorf("Unexpected condition of the PipelineRun %s", diff.PrintWantGot(d))
+			}
+			if !reconciledRun.Status.TaskRuns[prName+"-notify"].NonFatal {
+				t.Error("Expected the failure of the final task to be marked as non-fatal")
+			}
+		})
+	}
+}
+
+func TestReconcileWithCompleteAllFailurePolicy(t *testing.T) {
+	// TestReconcileWithCompleteAllFailurePolicy runs "Reconcile" on a PipelineRun with the CompleteAll
+	// failure policy whose first TaskRun failed. It checks that the independent PipelineTask is started,
+	// that the dependent PipelineTask is skipped and that the PipelineRun keeps running.
+	taskRunName := "test-pipeline-run-complete-all-hello-world-1"
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-complete-all",
+		tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunFailurePolicy(v1beta1.PipelineRunFailurePolicyCompleteAll)),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+			tb.PipelineRunTaskRunsStatus(taskRunName, &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+				Status:           &v1beta1.TaskRunStatus{},
+			}),
+		),
+	)}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		tb.PipelineTask("hello-world-3", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun(taskRunName,
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-complete-all"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-complete-all"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}),
+			),
+		),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-complete-all", []string{}, false)
+
+	created := []string{}
+	for _, action := range clients.Pipeline.Actions() {
+		if action != nil && action.Matches("create", "taskruns") {
+			tr := action.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+			created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+		}
+	}
+	if d := cmp.Diff([]string{"hello-world-3"}, created); d != "" {
+		t.Errorf("Expected the independent TaskRun to be created %s", diff.PrintWantGot(d))
+	}
+
+	if c := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); !c.IsUnknown() || c.Reason != v1beta1.PipelineRunReasonRunning.String() {
+		t.Errorf("Expected PipelineRun to still be running, but was %v", c)
+	}
+
+	wantSkipped := []v1beta1.SkippedTask{{Name: "hello-world-2", Reason: v1beta1.ParentTasksFailedSkip}}
+	if d := cmp.Diff(wantSkipped, reconciledRun.Status.SkippedTasks); d != "" {
+		t.Errorf("Expected the dependent PipelineTask to be skipped %s", diff.PrintWantGot(d))
 	}
 }
 
@@ -1516,79 +1885,94 @@ func TestReconcileWithDifferentServiceAccounts(t *testing.T) {
 	}
 }
 
-func TestReconcileWithTimeoutAndRetry(t *testing.T) {
-	// TestReconcileWithTimeoutAndRetry runs "Reconcile" against pipelines with retries and timeout settings,
-	// and status that represents different number of retries already performed.
-	// It verifies the reconciled status and events generated
+func TestReconcileWithRetryKeepsTaskResults(t *testing.T) {
+	// TestReconcileWithRetryKeepsTaskResults runs "Reconcile" against a pipeline task which is retried after
+	// a failed attempt which wrote results, and after its retry succeeded. It verifies that the results of the
+	// failed attempt are kept in its retry status only, while the results of the TaskRun are the ones of the
+	// successful attempt.
+	failedAttemptResults := []v1beta1.TaskRunResult{{Name: "result", Value: "failed-attempt"}}
+	successfulAttemptResults := []v1beta1.TaskRunResult{{Name: "result", Value: "successful-attempt"}}
+	failedAttempt := v1beta1.TaskRunStatus{
+		Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+			}},
+		},
+		TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			PodName:        "my-pod-name",
+			TaskRunResults: failedAttemptResults,
+			Steps: []v1beta1.StepState{{
+				Name:          "step",
+				ContainerName: "step-step",
+				ImageID:       "docker-pullable://busybox@sha256:1234",
+				ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   1,
+					StartedAt:  metav1.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC),
+					FinishedAt: metav1.Date(2020, time.October, 1, 12, 1, 0, 0, time.UTC),
+				}},
+			}},
+		},
+	}
 
 	tcs := []struct {
 		name               string
-		retries            int
+		taskRunStatus      v1beta1.TaskRunStatus
+		wantPodName        string
+		wantResults        []v1beta1.TaskRunResult
+		wantRetryResults   []v1beta1.TaskRunResult
 		conditionSucceeded corev1.ConditionStatus
-		wantEvents         []string
-	}{
-		{
-			name:               "One try has to be done",
-			retries:            1,
-			conditionSucceeded: corev1.ConditionFalse,
-			wantEvents: []string{
-				"Warning Failed PipelineRun \"test-pipeline-retry-run-with-timeout\" failed to finish within",
+	}{{
+		name:               "failed attempt is retried",
+		taskRunStatus:      failedAttempt,
+		wantPodName:        "",
+		wantResults:        nil,
+		wantRetryResults:   failedAttemptResults,
+		conditionSucceeded: corev1.ConditionUnknown,
+	}, {
+		name: "retry succeeded",
+		taskRunStatus: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
 			},
-		},
-		{
-			name:               "No more retries are needed",
-			retries:            2,
-			conditionSucceeded: corev1.ConditionUnknown,
-			wantEvents: []string{
-				"Warning Failed PipelineRun \"test-pipeline-retry-run-with-timeout\" failed to finish within",
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				PodName:        "my-pod-name-retry",
+				TaskRunResults: successfulAttemptResults,
+				RetriesStatus:  []v1beta1.TaskRunStatus{failedAttempt},
 			},
 		},
-	}
+		wantPodName:        "my-pod-name-retry",
+		wantResults:        successfulAttemptResults,
+		wantRetryResults:   failedAttemptResults,
+		conditionSucceeded: corev1.ConditionTrue,
+	}}
 
 	for _, tc := range tcs {
-
 		t.Run(tc.name, func(t *testing.T) {
 			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-retry", tb.PipelineNamespace("foo"), tb.PipelineSpec(
-				tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(tc.retries)),
+				tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(1)),
 			))}
-			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run-with-timeout", tb.PipelineRunNamespace("foo"),
-				tb.PipelineRunSpec("test-pipeline-retry",
-					tb.PipelineRunServiceAccountName("test-sa"),
-					tb.PipelineRunTimeout(12*time.Hour),
-				),
-				tb.PipelineRunStatus(
-					tb.PipelineRunStartTime(time.Now().AddDate(0, 0, -1))),
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline-retry", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
 			)}
-
 			ts := []*v1beta1.Task{
 				tb.Task("hello-world", tb.TaskNamespace("foo")),
 			}
 			trs := []*v1beta1.TaskRun{
-				tb.TaskRun("hello-world-1",
-					tb.TaskRunNamespace("foo"),
-					tb.TaskRunStatus(
-						tb.PodName("my-pod-name"),
-						tb.StatusCondition(apis.Condition{
-							Type:   apis.ConditionSucceeded,
-							Status: corev1.ConditionFalse,
-						}),
-						tb.Retry(v1beta1.TaskRunStatus{
-							Status: duckv1beta1.Status{
-								Conditions: []apis.Condition{{
-									Type:   apis.ConditionSucceeded,
-									Status: corev1.ConditionFalse,
-								}},
-							},
-						}),
-					)),
+				tb.TaskRun("hello-world-1", tb.TaskRunNamespace("foo")),
 			}
+			trs[0].Status = tc.taskRunStatus
 
-			prtrs := &v1beta1.PipelineRunTaskRunStatus{
-				PipelineTaskName: "hello-world-1",
-				Status:           &trs[0].Status,
+			prs[0].Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+				"hello-world-1": {
+					PipelineTaskName: "hello-world-1",
+					Status:           &trs[0].Status,
+				},
 			}
-			prs[0].Status.TaskRuns = make(map[string]*v1beta1.PipelineRunTaskRunStatus)
-			prs[0].Status.TaskRuns["hello-world-1"] = prtrs
 
 			d := test.Data{
 				PipelineRuns: prs,
@@ -1599,33 +1983,1231 @@ func TestReconcileWithTimeoutAndRetry(t *testing.T) {
 			prt := NewPipelineRunTest(d, t)
 			defer prt.Cancel()
 
-			reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-retry-run-with-timeout", []string{}, false)
+			reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-retry-run", []string{}, false)
 
-			if len(reconciledRun.Status.TaskRuns["hello-world-1"].Status.RetriesStatus) != tc.retries {
-				t.Fatalf(" %d retry expected but %d ", tc.retries, len(reconciledRun.Status.TaskRuns["hello-world-1"].Status.RetriesStatus))
+			status := reconciledRun.Status.TaskRuns["hello-world-1"].Status
+			if d := cmp.Diff(tc.wantResults, status.TaskRunResults); d != "" {
+				t.Errorf("Unexpected TaskRun results %s", diff.PrintWantGot(d))
 			}
-
-			if status := reconciledRun.Status.TaskRuns["hello-world-1"].Status.GetCondition(apis.ConditionSucceeded).Status; status != tc.conditionSucceeded {
-				t.Fatalf("Succeeded expected to be %s but is %s", tc.conditionSucceeded, status)
+			if len(status.RetriesStatus) != 1 {
+				t.Fatalf("1 retry expected but %d found", len(status.RetriesStatus))
+			}
+			if d := cmp.Diff(tc.wantRetryResults, status.RetriesStatus[0].TaskRunResults); d != "" {
+				t.Errorf("Unexpected TaskRun results of the failed attempt %s", diff.PrintWantGot(d))
+			}
+			if status.PodName != tc.wantPodName {
+				t.Errorf("Expected TaskRun podName to be %q but was %q", tc.wantPodName, status.PodName)
+			}
+			if tc.wantPodName == "" && len(status.Steps) != 0 {
+				t.Errorf("Expected the steps of the failed attempt to be cleared but got %v", status.Steps)
+			}
+			if status.RetriesStatus[0].PodName != "my-pod-name" {
+				t.Errorf("Expected the failed attempt to keep podName %q but was %q", "my-pod-name", status.RetriesStatus[0].PodName)
+			}
+			if d := cmp.Diff(failedAttempt.Steps, status.RetriesStatus[0].Steps); d != "" {
+				t.Errorf("Expected the failed attempt to keep its steps %s", diff.PrintWantGot(d))
+			}
+			if c := reconciledRun.Status.GetCondition(apis.ConditionSucceeded).Status; c != tc.conditionSucceeded {
+				t.Errorf("PipelineRun Succeeded expected to be %s but is %s", tc.conditionSucceeded, c)
 			}
 		})
 	}
 }
 
-func TestReconcilePropagateAnnotations(t *testing.T) {
-	names.TestingSeed()
-
-	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
-		tb.PipelineTask("hello-world-1", "hello-world"),
+func TestReconcileWithRetryOnEviction(t *testing.T) {
+	// TestReconcileWithRetryOnEviction runs "Reconcile" against a pipeline task whose TaskRun failed
+	// because its pod was evicted. It verifies that the TaskRun is retried like any other failure.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-retry", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(1)),
 	))}
-	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-annotations", tb.PipelineRunNamespace("foo"),
-		tb.PipelineRunAnnotation("PipelineRunAnnotation", "PipelineRunValue"),
-		tb.PipelineRunSpec("test-pipeline",
-			tb.PipelineRunServiceAccountName("test-sa"),
-		),
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline-retry", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
 	)}
+	ts := []*v1beta1.Task{
+		tb.Task("hello-world", tb.TaskNamespace("foo")),
+	}
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun("hello-world-1", tb.TaskRunNamespace("foo")),
+	}
+	trs[0].Status = v1beta1.TaskRunStatus{
+		Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: v1beta1.TaskRunReasonEvicted.String(),
+			}},
+		},
+		TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			PodName: "my-evicted-pod",
+		},
+	}
+	prs[0].Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"hello-world-1": {
+			PipelineTaskName: "hello-world-1",
+			Status:           &trs[0].Status,
+		},
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-retry-run", []string{}, false)
+
+	status := reconciledRun.Status.TaskRuns["hello-world-1"].Status
+	if len(status.RetriesStatus) != 1 {
+		t.Fatalf("1 retry expected but %d found", len(status.RetriesStatus))
+	}
+	if r := status.RetriesStatus[0].GetCondition(apis.ConditionSucceeded).Reason; r != v1beta1.TaskRunReasonEvicted.String() {
+		t.Errorf("Expected the retried attempt to have failed with reason %s but got %s", v1beta1.TaskRunReasonEvicted, r)
+	}
+	if c := status.GetCondition(apis.ConditionSucceeded).Status; c != corev1.ConditionUnknown {
+		t.Errorf("Expected the retried TaskRun to be running but its Succeeded condition is %s", c)
+	}
+	if c := reconciledRun.Status.GetCondition(apis.ConditionSucceeded).Status; c != corev1.ConditionUnknown {
+		t.Errorf("PipelineRun Succeeded expected to be %s but is %s", corev1.ConditionUnknown, c)
+	}
+}
+
+func TestReconcileWithConcurrencyQueue(t *testing.T) {
+	// TestReconcileWithConcurrencyQueue runs "Reconcile" on a PipelineRun sharing its concurrency key
+	// with an older PipelineRun. It verifies that the PipelineRun is queued while the older one is
+	// active, and started once it is done.
+	created := time.Now().Add(-time.Minute)
+	tcs := []struct {
+		name         string
+		activeStatus tb.PipelineRunOp
+		wantReason   string
+		wantStarted  bool
+	}{{
+		name:         "queued while the previous PipelineRun runs",
+		activeStatus: tb.PipelineRunStatus(tb.PipelineRunStartTime(created)),
+		wantReason:   v1beta1.PipelineRunReasonQueued.String(),
+	}, {
+		name: "started once the previous PipelineRun is done",
+		activeStatus: tb.PipelineRunStatus(tb.PipelineRunStartTime(created),
+			tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+			}),
+		),
+		wantReason:  v1beta1.PipelineRunReasonRunning.String(),
+		wantStarted: true,
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			previous := tb.PipelineRun("test-pipeline-run-previous", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunConcurrency("production", v1beta1.ConcurrencyPolicyQueue)),
+				tc.activeStatus,
+			)
+			previous.CreationTimestamp = metav1.NewTime(created)
+			queued := tb.PipelineRun("test-pipeline-run-queued", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunConcurrency("production", v1beta1.ConcurrencyPolicyQueue)),
+			)
+			queued.CreationTimestamp = metav1.NewTime(created.Add(time.Second))
+			unrelated := tb.PipelineRun("test-pipeline-run-unrelated", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunConcurrency("staging", v1beta1.ConcurrencyPolicyQueue)),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(created)),
+			)
+			unrelated.CreationTimestamp = metav1.NewTime(created)
+
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{previous, queued, unrelated},
+				Pipelines: []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+					tb.PipelineTask("hello-world-1", "hello-world"),
+				))},
+				Tasks: []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-queued", []string{}, false)
+
+			if reason := reconciledRun.Status.GetCondition(apis.ConditionSucceeded).Reason; reason != tc.wantReason {
+				t.Errorf("Expected PipelineRun reason to be %s but was %s", tc.wantReason, reason)
+			}
+			if reconciledRun.HasStarted() != tc.wantStarted {
+				t.Errorf("Expected PipelineRun started to be %t but was %t", tc.wantStarted, reconciledRun.HasStarted())
+			}
+			var createdTaskRun bool
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					createdTaskRun = true
+				}
+			}
+			if createdTaskRun != tc.wantStarted {
+				t.Errorf("Expected TaskRun to be created to be %t but was %t", tc.wantStarted, createdTaskRun)
+			}
+		})
+	}
+}
+
+func TestReconcileWithConcurrencyCancelPrevious(t *testing.T) {
+	// TestReconcileWithConcurrencyCancelPrevious runs "Reconcile" on a PipelineRun sharing its concurrency
+	// key with an older, running PipelineRun. It verifies that the older PipelineRun is cancelled and
+	// the PipelineRun is started.
+	created := time.Now().Add(-time.Minute)
+	previous := tb.PipelineRun("test-pipeline-run-previous", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunConcurrency("production", v1beta1.ConcurrencyPolicyCancelPrevious)),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(created)),
+	)
+	previous.CreationTimestamp = metav1.NewTime(created)
+	latest := tb.PipelineRun("test-pipeline-run-latest", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunConcurrency("production", v1beta1.ConcurrencyPolicyCancelPrevious)),
+	)
+	latest.CreationTimestamp = metav1.NewTime(created.Add(time.Second))
+
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{previous, latest},
+		Pipelines: []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+			tb.PipelineTask("hello-world-1", "hello-world"),
+		))},
+		Tasks: []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-latest", []string{}, false)
+
+	if !reconciledRun.HasStarted() {
+		t.Errorf("Expected PipelineRun to be started")
+	}
+	cancelled, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get("test-pipeline-run-previous", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting previous PipelineRun: %v", err)
+	}
+	if !cancelled.IsCancelled() {
+		t.Errorf("Expected previous PipelineRun to be cancelled, but its spec status is %q", cancelled.Spec.Status)
+	}
+}
+
+func TestReconcileWithTimeoutAndRetry(t *testing.T) {
+	// TestReconcileWithTimeoutAndRetry runs "Reconcile" against pipelines with retries and timeout settings,
+	// and status that represents different number of retries already performed.
+	// It verifies the reconciled status and events generated
+
+	tcs := []struct {
+		name               string
+		retries            int
+		conditionSucceeded corev1.ConditionStatus
+		wantEvents         []string
+	}{
+		{
+			name:               "One try has to be done",
+			retries:            1,
+			conditionSucceeded: corev1.ConditionFalse,
+			wantEvents: []string{
+				"Warning Failed PipelineRun \"test-pipeline-retry-run-with-timeout\" failed to finish within",
+			},
+		},
+		{
+			name:               "No more retries are needed",
+			retries:            2,
+			conditionSucceeded: corev1.ConditionUnknown,
+			wantEvents: []string{
+				"Warning Failed PipelineRun \"test-pipeline-retry-run-with-timeout\" failed to finish within",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-retry", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(tc.retries)),
+			))}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run-with-timeout", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline-retry",
+					tb.PipelineRunServiceAccountName("test-sa"),
+					tb.PipelineRunTimeout(12*time.Hour),
+				),
+				tb.PipelineRunStatus(
+					tb.PipelineRunStartTime(time.Now().AddDate(0, 0, -1))),
+			)}
+
+			ts := []*v1beta1.Task{
+				tb.Task("hello-world", tb.TaskNamespace("foo")),
+			}
+			trs := []*v1beta1.TaskRun{
+				tb.TaskRun("hello-world-1",
+					tb.TaskRunNamespace("foo"),
+					tb.TaskRunStatus(
+						tb.PodName("my-pod-name"),
+						tb.StatusCondition(apis.Condition{
+							Type:   apis.ConditionSucceeded,
+							Status: corev1.ConditionFalse,
+						}),
+						tb.Retry(v1beta1.TaskRunStatus{
+							Status: duckv1beta1.Status{
+								Conditions: []apis.Condition{{
+									Type:   apis.ConditionSucceeded,
+									Status: corev1.ConditionFalse,
+								}},
+							},
+						}),
+					)),
+			}
+
+			prtrs := &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+				Status:           &trs[0].Status,
+			}
+			prs[0].Status.TaskRuns = make(map[string]*v1beta1.PipelineRunTaskRunStatus)
+			prs[0].Status.TaskRuns["hello-world-1"] = prtrs
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-retry-run-with-timeout", []string{}, false)
+
+			if len(reconciledRun.Status.TaskRuns["hello-world-1"].Status.RetriesStatus) != tc.retries {
+				t.Fatalf(" %d retry expected but %d ", tc.retries, len(reconciledRun.Status.TaskRuns["hello-world-1"].Status.RetriesStatus))
+			}
+
+			if status := reconciledRun.Status.TaskRuns["hello-world-1"].Status.GetCondition(apis.ConditionSucceeded).Status; status != tc.conditionSucceeded {
+				t.Fatalf("Succeeded expected to be %s but is %s", tc.conditionSucceeded, status)
+			}
+		})
+	}
+}
+
+func TestReconcilePropagateAnnotations(t *testing.T) {
+	names.TestingSeed()
+
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-annotations", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunAnnotation("PipelineRunAnnotation", "PipelineRunValue"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa"),
+		),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-with-annotations", []string{}, false)
+
+	actions := clients.Pipeline.Actions()
+	if len(actions) < 2 {
+		t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
+	}
+
+	// Check that the expected TaskRun was created
+	actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+	if actual == nil {
+		t.Errorf("Expected a TaskRun to be created, but it wasn't.")
+	}
+	expectedTaskRun := tb.TaskRun("test-pipeline-run-with-annotations-hello-world-1-9l9zj",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-with-annotations",
+			tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+			tb.Controller, tb.BlockOwnerDeletion,
+		),
+		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-with-annotations"),
+		tb.TaskRunAnnotation("PipelineRunAnnotation", "PipelineRunValue"),
+		tb.TaskRunSpec(
+			tb.TaskRunTaskRef("hello-world"),
+			tb.TaskRunServiceAccountName("test-sa"),
+		),
+	)
+
+	if d := cmp.Diff(actual, expectedTaskRun); d != "" {
+		t.Errorf("expected to see TaskRun %v created. Diff %s", expectedTaskRun, diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcilePropagateMetadataPolicy(t *testing.T) {
+	// TestReconcilePropagateMetadataPolicy runs "Reconcile" on a PipelineRun whose labels and annotations
+	// conflict with the ones of its Pipeline. It verifies that the values of the PipelineRun take precedence,
+	// that denied keys aren't propagated to its TaskRuns, and that the propagation is restricted to the
+	// prefixes of the config-defaults ConfigMap when they are set.
+	for _, tc := range []struct {
+		name            string
+		prefixes        string
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{{
+		name: "all keys",
+		wantLabels: map[string]string{
+			"team":                    "run-team",
+			"example.com/cost":        "pipeline-cost",
+			"tekton.dev/pipeline":     "test-pipeline",
+			"tekton.dev/pipelineRun":  "test-pipeline-run-with-metadata",
+			"tekton.dev/pipelineTask": "hello-world-1",
+		},
+		wantAnnotations: map[string]string{
+			"owner": "run-owner",
+		},
+	}, {
+		name:     "restricted to prefixes",
+		prefixes: "example.com/",
+		wantLabels: map[string]string{
+			"example.com/cost":        "pipeline-cost",
+			"tekton.dev/pipeline":     "test-pipeline",
+			"tekton.dev/pipelineRun":  "test-pipeline-run-with-metadata",
+			"tekton.dev/pipelineTask": "hello-world-1",
+		},
+		wantAnnotations: map[string]string{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world"),
+			))}
+			ps[0].Labels = map[string]string{
+				"team":             "pipeline-team",
+				"example.com/cost": "pipeline-cost",
+			}
+			ps[0].Annotations = map[string]string{
+				"owner":                            "pipeline-owner",
+				corev1.LastAppliedConfigAnnotation: "{}",
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-metadata", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunLabel("team", "run-team"),
+				tb.PipelineRunAnnotation("owner", "run-owner"),
+				tb.PipelineRunAnnotation(corev1.LastAppliedConfigAnnotation, "{}"),
+				tb.PipelineRunAnnotation("example.com/tekton-internal-token", "secret"),
+				tb.PipelineRunSpec("test-pipeline",
+					tb.PipelineRunServiceAccountName("test-sa"),
+				),
+			)}
+			ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+			cms := []*corev1.ConfigMap{{
+				ObjectMeta: metav1.ObjectMeta{Name: config.GetDefaultsConfigName(), Namespace: system.GetNamespace()},
+				Data: map[string]string{
+					"default-propagated-metadata-prefixes": tc.prefixes,
+				},
+			}}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-with-metadata", []string{}, false)
+			if got := reconciledRun.Labels["team"]; got != "run-team" {
+				t.Errorf("Expected the PipelineRun label to take precedence over the Pipeline one, got %q", got)
+			}
+			if got := reconciledRun.Annotations["owner"]; got != "run-owner" {
+				t.Errorf("Expected the PipelineRun annotation to take precedence over the Pipeline one, got %q", got)
+			}
+
+			actions := clients.Pipeline.Actions()
+			if len(actions) < 2 {
+				t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
+			}
+			actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+			if d := cmp.Diff(tc.wantLabels, actual.Labels); d != "" {
+				t.Errorf("TaskRun labels %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantAnnotations, actual.Annotations); d != "" {
+				t.Errorf("TaskRun annotations %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+// delayRecordingQueue records the items added to the queue after a delay instead of
+// waiting for the delay to pass.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[interface{}]time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays[item] = duration
+}
+
+func TestReconcileConfigMapTrigger(t *testing.T) {
+	// TestReconcileConfigMapTrigger runs "Reconcile" on a finished PipelineRun triggered by
+	// changes to a ConfigMap. It verifies that a new PipelineRun running the same spec is
+	// created only when the ConfigMap changed since the hash recorded on the PipelineRun,
+	// and that the hash is recorded the first time the ConfigMap is seen.
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "foo"},
+		Data:       map[string]string{"replicas": "3"},
+	}
+	oldSettings := settings.DeepCopy()
+	oldSettings.Data["replicas"] = "1"
+	recorded := func(count int, cm *corev1.ConfigMap) string {
+		return fmt.Sprintf(`{"count":%d,"hashes":{"settings":"%s"}}`, count, hashConfigMap(cm))
+	}
+	for _, tc := range []struct {
+		name          string
+		annotation    string
+		wantTriggered string
+		wantRecorded  string
+	}{{
+		name:         "first seen",
+		wantRecorded: recorded(0, settings),
+	}, {
+		name:         "unchanged",
+		annotation:   recorded(0, settings),
+		wantRecorded: recorded(0, settings),
+	}, {
+		name:          "changed",
+		annotation:    recorded(0, oldSettings),
+		wantTriggered: "test-pipeline-run-retrigger-1",
+		wantRecorded:  recorded(1, settings),
+	}, {
+		name:          "changed again",
+		annotation:    recorded(1, oldSettings),
+		wantTriggered: "test-pipeline-run-retrigger-2",
+		wantRecorded:  recorded(2, settings),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world"),
+			))}
+			ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+			ops := []tb.PipelineRunOp{
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunLabel("app", "deploy"),
+				tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+					spec.TriggerOnConfigMapChange = []corev1.ObjectReference{{Name: "settings"}}
+				}),
+				tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+					Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+				})),
+			}
+			if tc.annotation != "" {
+				ops = append(ops, tb.PipelineRunAnnotation(configMapTriggersAnnotation, tc.annotation))
+			}
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", ops...)},
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   []*corev1.ConfigMap{settings},
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, false)
+			if d := cmp.Diff(tc.wantRecorded, reconciledRun.Annotations[configMapTriggersAnnotation]); d != "" {
+				t.Errorf("Recorded ConfigMap triggers %s", diff.PrintWantGot(d))
+			}
+
+			prs, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var triggered []v1beta1.PipelineRun
+			for _, pr := range prs.Items {
+				if pr.Name != "test-pipeline-run" {
+					triggered = append(triggered, pr)
+				}
+			}
+			if tc.wantTriggered == "" {
+				if len(triggered) != 0 {
+					t.Errorf("Expected no PipelineRun to be triggered, got %d", len(triggered))
+				}
+				return
+			}
+			if len(triggered) != 1 {
+				t.Fatalf("Expected one PipelineRun to be triggered, got %d", len(triggered))
+			}
+			got := triggered[0]
+			if got.Name != tc.wantTriggered {
+				t.Errorf("Expected the triggered PipelineRun to be named %s, got %s", tc.wantTriggered, got.Name)
+			}
+			if got.Annotations[TriggeredFromAnnotation] != "test-pipeline-run" {
+				t.Errorf("Expected the triggered PipelineRun to be annotated with its origin, got %v", got.Annotations)
+			}
+			if _, ok := got.Annotations[configMapTriggersAnnotation]; ok {
+				t.Errorf("Expected the triggered PipelineRun not to record ConfigMap triggers, got %v", got.Annotations)
+			}
+			if got.Labels["app"] != "deploy" {
+				t.Errorf("Expected the labels of the PipelineRun to be copied, got %v", got.Labels)
+			}
+			wantSpec := reconciledRun.Spec.DeepCopy()
+			wantSpec.TriggerOnConfigMapChange = nil
+			if d := cmp.Diff(*wantSpec, got.Spec); d != "" {
+				t.Errorf("Triggered PipelineRun spec %s", diff.PrintWantGot(d))
+			}
+			if got.HasStarted() {
+				t.Errorf("Expected the triggered PipelineRun not to have a status, got %v", got.Status)
+			}
+		})
+	}
+}
+
+func TestReconcileWithIsolatedNamespace(t *testing.T) {
+	// TestReconcileWithIsolatedNamespace runs "Reconcile" on a PipelineRun running in an
+	// isolated namespace. It verifies that the namespace is created with copies of the
+	// ServiceAccount, Secrets and ConfigMaps of the PipelineRun, and that the TaskRuns are
+	// created in it, without being owned by the PipelineRun.
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.PipelineTaskWorkspaceBinding("settings", "settings", "")),
+		tb.PipelineWorkspaceDeclaration("settings"),
+	))}
+	pr := tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("builder"), func(spec *v1beta1.PipelineRunSpec) {
+			spec.IsolatedNamespace = true
+			spec.Workspaces = []v1beta1.WorkspaceBinding{{
+				Name:      "settings",
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+			}}
+		}),
+	)
+	pr.UID = "test-pipeline-run-uid"
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.TaskWorkspace("settings", "", "", true),
+		tb.Step("foo", tb.StepName("simple-step")),
+	))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "foo"},
+		Data:       map[string]string{"replicas": "3"},
+	}}
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		Pipelines:    ps,
+		Tasks:        ts,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	kube := prt.TestAssets.Clients.Kube
+	for _, s := range []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-creds", Namespace: "foo"},
+		Type:       corev1.SecretTypeBasicAuth,
+		Data:       map[string][]byte{"username": []byte("builder")},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "builder-token-abcde", Namespace: "foo"},
+		Type:       corev1.SecretTypeServiceAccountToken,
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "foo"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+	}} {
+		if _, err := kube.CoreV1().Secrets(s.Namespace).Create(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "foo"},
+		Secrets:          []corev1.ObjectReference{{Name: "git-creds"}, {Name: "builder-token-abcde"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal Running Tasks Completed: 0",
+	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", wantEvents, false)
+	if !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+		t.Errorf("Expected PipelineRun to be running, but condition status is %s", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+
+	ns, err := clients.Kube.CoreV1().Namespaces().Get("test-pipeline-run-ns", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the isolated namespace to be created: %v", err)
+	}
+	wantAnnotations := map[string]string{
+		isolatedFromNamespaceAnnotation:  "foo",
+		isolatedPipelineRunUIDAnnotation: "test-pipeline-run-uid",
+	}
+	if d := cmp.Diff(wantAnnotations, ns.Annotations); d != "" {
+		t.Errorf("Isolated namespace annotations %s", diff.PrintWantGot(d))
+	}
+
+	sa, err := clients.Kube.CoreV1().ServiceAccounts("test-pipeline-run-ns").Get("builder", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the ServiceAccount to be copied: %v", err)
+	}
+	if d := cmp.Diff([]corev1.ObjectReference{{Name: "git-creds"}}, sa.Secrets); d != "" {
+		t.Errorf("Copied ServiceAccount secrets %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]corev1.LocalObjectReference{{Name: "registry"}}, sa.ImagePullSecrets); d != "" {
+		t.Errorf("Copied ServiceAccount image pull secrets %s", diff.PrintWantGot(d))
+	}
+	for _, s := range []string{"git-creds", "registry"} {
+		if _, err := clients.Kube.CoreV1().Secrets("test-pipeline-run-ns").Get(s, metav1.GetOptions{}); err != nil {
+			t.Errorf("Expected Secret %s to be copied: %v", s, err)
+		}
+	}
+	if _, err := clients.Kube.CoreV1().Secrets("test-pipeline-run-ns").Get("builder-token-abcde", metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("Expected the ServiceAccount token not to be copied, got %v", err)
+	}
+	cm, err := clients.Kube.CoreV1().ConfigMaps("test-pipeline-run-ns").Get("settings", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the ConfigMap of the workspace to be copied: %v", err)
+	}
+	if d := cmp.Diff(map[string]string{"replicas": "3"}, cm.Data); d != "" {
+		t.Errorf("Copied ConfigMap data %s", diff.PrintWantGot(d))
+	}
+
+	tr, err := clients.Pipeline.TektonV1beta1().TaskRuns("test-pipeline-run-ns").Get("test-pipeline-run-hello-world-1-9l9zj", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the TaskRun to be created in the isolated namespace: %v", err)
+	}
+	if len(tr.OwnerReferences) != 0 {
+		t.Errorf("Expected the TaskRun not to be owned by the PipelineRun of another namespace, got %v", tr.OwnerReferences)
+	}
+	if tr.Annotations[isolatedFromNamespaceAnnotation] != "foo" {
+		t.Errorf("Expected the TaskRun to be annotated with the namespace of its PipelineRun, got %v", tr.Annotations)
+	}
+	if tr.Spec.TaskRef != nil || tr.Spec.TaskSpec == nil {
+		t.Errorf("Expected the TaskRun to embed the spec of the Task, got ref %v", tr.Spec.TaskRef)
+	}
+	if tr.Spec.ServiceAccountName != "builder" {
+		t.Errorf("Expected the TaskRun to run as builder, got %q", tr.Spec.ServiceAccountName)
+	}
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskRuns.Items) != 0 {
+		t.Errorf("Expected no TaskRun in the namespace of the PipelineRun, got %d", len(taskRuns.Items))
+	}
+}
+
+func TestReconcileWithCheckpoint(t *testing.T) {
+	// TestReconcileWithCheckpoint runs "Reconcile" on a PipelineRun with a checkpoint interval.
+	// It verifies that the checkpoint of the PipelineRun is saved in a ConfigMap it owns.
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+			spec.CheckpointInterval = &metav1.Duration{Duration: time.Minute}
+		}),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		ConfigMaps:   alphaFeatureFlags(),
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", nil, false)
+
+	cm, err := clients.Kube.CoreV1().ConfigMaps("foo").Get("test-pipeline-run-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the checkpoint of the PipelineRun to be saved: %v", err)
+	}
+	if !metav1.IsControlledBy(cm, reconciledRun) {
+		t.Errorf("Expected the checkpoint to be owned by the PipelineRun, got %v", cm.OwnerReferences)
+	}
+	cp := &pipelineRunCheckpoint{}
+	if err := json.Unmarshal([]byte(cm.Data[checkpointKey]), cp); err != nil {
+		t.Fatalf("Expected a valid checkpoint: %v", err)
+	}
+	wantTaskRuns := map[string]checkpointTaskRun{
+		"test-pipeline-run-hello-world-1-9l9zj": {PipelineTaskName: "hello-world-1"},
+	}
+	if d := cmp.Diff(wantTaskRuns, cp.TaskRuns); d != "" {
+		t.Errorf("Checkpoint TaskRuns %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(checkpointCounters{Incomplete: 1}, cp.Counters); d != "" {
+		t.Errorf("Checkpoint counters %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcileRestoresCheckpoint(t *testing.T) {
+	// TestReconcileRestoresCheckpoint runs "Reconcile" on a PipelineRun with a checkpoint for the
+	// first time since the controller started. The TaskRun of the checkpoint isn't labelled with
+	// the PipelineRun, so that it can only be found through the checkpoint, while the TaskRun
+	// created after the checkpoint was saved is only labelled. It verifies that both TaskRuns are
+	// in the status, and that the next task runs instead of the first ones again.
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		tb.PipelineTask("hello-world-3", "hello-world"),
+	))}
+	pr := tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+			spec.CheckpointInterval = &metav1.Duration{Duration: time.Minute}
+		}),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()), tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown,
+			Reason: v1beta1.PipelineRunReasonRunning.String(),
+		})),
+	)
+	pr.UID = "test-pipeline-run-uid"
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	trs := []*v1beta1.TaskRun{tb.TaskRun("test-pipeline-run-hello-world-1-abcde", tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+		tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+		})),
+	), tb.TaskRun("test-pipeline-run-hello-world-3-fghij", tb.TaskRunNamespace("foo"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-3"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+	)}
+	checkpoint, err := json.Marshal(&pipelineRunCheckpoint{
+		TaskRuns: map[string]checkpointTaskRun{
+			"test-pipeline-run-hello-world-1-abcde": {PipelineTaskName: "hello-world-1"},
+		},
+		Counters: checkpointCounters{Incomplete: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cms := append(alphaFeatureFlags(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-pipeline-run-checkpoint",
+			Namespace:       "foo",
+			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
+		},
+		Data: map[string]string{checkpointKey: string(checkpoint)},
+	})
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", nil, false)
+
+	restored, ok := reconciledRun.Status.TaskRuns["test-pipeline-run-hello-world-1-abcde"]
+	if !ok {
+		t.Fatalf("Expected the TaskRun of the checkpoint to be restored, got %v", reconciledRun.Status.TaskRuns)
+	}
+	if restored.PipelineTaskName != "hello-world-1" || !restored.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		t.Errorf("Expected the restored TaskRun to have succeeded for hello-world-1, got %v", restored)
+	}
+	if synced, ok := reconciledRun.Status.TaskRuns["test-pipeline-run-hello-world-3-fghij"]; !ok || synced.PipelineTaskName != "hello-world-3" {
+		t.Errorf("Expected the TaskRun created after the checkpoint to be in the status, got %v", reconciledRun.Status.TaskRuns)
+	}
+	actions := clients.Pipeline.Actions()
+	var created []string
+	for _, a := range actions {
+		if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+			created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun).Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+		}
+	}
+	if d := cmp.Diff([]string{"hello-world-2"}, created); d != "" {
+		t.Errorf("Created TaskRuns for the pipeline tasks %s", diff.PrintWantGot(d))
+	}
+
+	cm, err := clients.Kube.CoreV1().ConfigMaps("foo").Get("test-pipeline-run-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := &pipelineRunCheckpoint{}
+	if err := json.Unmarshal([]byte(cm.Data[checkpointKey]), cp); err != nil {
+		t.Fatalf("Expected a valid checkpoint: %v", err)
+	}
+	if d := cmp.Diff(checkpointCounters{Succeeded: 1, Incomplete: 2}, cp.Counters); d != "" {
+		t.Errorf("Expected the checkpoint to be saved again with the new TaskRun %s", diff.PrintWantGot(d))
+	}
+}
+
+func alphaFeatureFlags() []*corev1.ConfigMap {
+	return []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+}
+
+func TestReconcileWithIsolatedNamespace_Conflict(t *testing.T) {
+	// TestReconcileWithIsolatedNamespace_Conflict runs "Reconcile" on a PipelineRun running in
+	// an isolated namespace, the name of which is already taken by a namespace which wasn't
+	// created for it. It verifies that the PipelineRun fails instead of using that namespace.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	pr := tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+			spec.IsolatedNamespace = true
+		}),
+	)
+	pr.UID = "test-pipeline-run-uid"
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		Pipelines:    ps,
+		Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+		Namespaces: []*corev1.Namespace{{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run-ns"},
+		}},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, true)
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsFalse() || condition.Reason != ReasonCouldntCreateIsolatedNamespace {
+		t.Errorf("Expected PipelineRun to fail with reason %s, got %v", ReasonCouldntCreateIsolatedNamespace, condition)
+	}
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("test-pipeline-run-ns").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskRuns.Items) != 0 {
+		t.Errorf("Expected no TaskRun in the existing namespace, got %d", len(taskRuns.Items))
+	}
+}
+
+func TestReconcileDeletesIsolatedNamespace(t *testing.T) {
+	// TestReconcileDeletesIsolatedNamespace runs "Reconcile" on a PipelineRun which ran in
+	// an isolated namespace, once finished, once its TTL has passed or once deleted before
+	// it finished. It verifies that the namespace is deleted, unless it wasn't created for
+	// the PipelineRun.
+	finished := tb.PipelineRunStatus(
+		tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+			Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+		}),
+		tb.PipelineRunStartTime(time.Now().Add(-2*time.Minute)),
+		tb.PipelineRunCompletionTime(time.Now().Add(-time.Minute)),
+	)
+	running := tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()))
+	for _, tc := range []struct {
+		name        string
+		uid         types.UID
+		status      tb.PipelineRunOp
+		ttl         string
+		deleting    bool
+		wantDeleted bool
+	}{{
+		name:        "created for the PipelineRun",
+		uid:         "test-pipeline-run-uid",
+		status:      finished,
+		wantDeleted: true,
+	}, {
+		name:   "created for another PipelineRun",
+		uid:    "other-uid",
+		status: finished,
+	}, {
+		name:        "ttl passed",
+		uid:         "test-pipeline-run-uid",
+		status:      finished,
+		ttl:         "0",
+		wantDeleted: true,
+	}, {
+		name:   "running",
+		uid:    "test-pipeline-run-uid",
+		status: running,
+	}, {
+		name:        "deleted while running",
+		uid:         "test-pipeline-run-uid",
+		status:      running,
+		deleting:    true,
+		wantDeleted: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world"),
+			))}
+			ops := []tb.PipelineRunOp{
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+					spec.IsolatedNamespace = true
+				}),
+				tc.status,
+			}
+			if tc.ttl != "" {
+				ops = append(ops, tb.PipelineRunAnnotation("tekton.dev/ttl-seconds-after-finished", tc.ttl))
+			}
+			pr := tb.PipelineRun("test-pipeline-run", ops...)
+			pr.UID = "test-pipeline-run-uid"
+			if tc.deleting {
+				pr.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				pr.Finalizers = []string{pipelineRunFinalizer}
+			}
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{pr},
+				Pipelines:    ps,
+				Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+				Namespaces: []*corev1.Namespace{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-pipeline-run-ns",
+						Annotations: map[string]string{isolatedPipelineRunUIDAnnotation: string(tc.uid)},
+					},
+				}},
+			}
+			testAssets, cancel := getPipelineRunController(t, d)
+			defer cancel()
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run"); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+			_, err := testAssets.Clients.Kube.CoreV1().Namespaces().Get("test-pipeline-run-ns", metav1.GetOptions{})
+			if deleted := k8serrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("Expected the isolated namespace to be deleted: %t, got error %v", tc.wantDeleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileWithNetworkPolicies(t *testing.T) {
+	// TestReconcileWithNetworkPolicies runs "Reconcile" on PipelineRuns declaring network
+	// policies. It verifies that a NetworkPolicy selecting the Pods of the TaskRuns by their
+	// labels is created for each of them, and that the PipelineRun fails when they reference
+	// tasks which aren't in its Pipeline.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("server", "hello-world"),
+		tb.PipelineTask("test", "hello-world"),
+	))}
 	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+	port := intstr.FromInt(8080)
+
+	for _, tc := range []struct {
+		name       string
+		from       string
+		wantPolicy bool
+	}{{
+		name:       "valid",
+		from:       "test",
+		wantPolicy: true,
+	}, {
+		name: "unknown task",
+		from: "load-test",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+					spec.NetworkPolicies = []v1beta1.NetworkPolicyRule{{
+						Name:  "to-server",
+						To:    "server",
+						From:  []string{tc.from},
+						Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+					}}
+				}),
+			)
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{pr},
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, !tc.wantPolicy)
+			policies, err := clients.Kube.NetworkingV1().NetworkPolicies("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.wantPolicy {
+				condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+				if !condition.IsFalse() || condition.Reason != ReasonInvalidNetworkPolicies {
+					t.Errorf("Expected PipelineRun to fail with reason %s, got %v", ReasonInvalidNetworkPolicies, condition)
+				}
+				if len(policies.Items) != 0 {
+					t.Errorf("Expected no NetworkPolicy to be created, got %d", len(policies.Items))
+				}
+				return
+			}
+
+			expected := networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test-pipeline-run-to-server",
+					Namespace:       "foo",
+					Labels:          map[string]string{pipeline.GroupName + pipeline.PipelineRunLabelKey: "test-pipeline-run"},
+					OwnerReferences: []metav1.OwnerReference{reconciledRun.GetOwnerReference()},
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{
+						pipeline.GroupName + pipeline.PipelineRunLabelKey:  "test-pipeline-run",
+						pipeline.GroupName + pipeline.PipelineTaskLabelKey: "server",
+					}},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+						From: []networkingv1.NetworkPolicyPeer{{
+							PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+								pipeline.GroupName + pipeline.PipelineRunLabelKey:  "test-pipeline-run",
+								pipeline.GroupName + pipeline.PipelineTaskLabelKey: "test",
+							}},
+						}},
+					}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				},
+			}
+			if d := cmp.Diff([]networkingv1.NetworkPolicy{expected}, policies.Items); d != "" {
+				t.Errorf("Unexpected NetworkPolicies %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileDeletesNetworkPolicies(t *testing.T) {
+	// TestReconcileDeletesNetworkPolicies runs "Reconcile" on a finished PipelineRun which
+	// declared network policies. It verifies that their NetworkPolicies are deleted.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("server", "hello-world"),
+		tb.PipelineTask("test", "hello-world"),
+	))}
+	pr := tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", func(spec *v1beta1.PipelineRunSpec) {
+			spec.NetworkPolicies = []v1beta1.NetworkPolicyRule{{
+				Name: "to-server",
+				To:   "server",
+				From: []string{"test"},
+			}}
+		}),
+		tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+			Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+		})),
+	)
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		Pipelines:    ps,
+		Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	np := networkPolicy(pr, pr.Spec.NetworkPolicies[0])
+	if _, err := prt.TestAssets.Clients.Kube.NetworkingV1().NetworkPolicies("foo").Create(np); err != nil {
+		t.Fatal(err)
+	}
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, false)
+	_, err := clients.Kube.NetworkingV1().NetworkPolicies("foo").Get(np.Name, metav1.GetOptions{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("Expected the NetworkPolicy %s to be deleted, got error %v", np.Name, err)
+	}
+}
+
+func TestReconcileWithResourceQuota(t *testing.T) {
+	// TestReconcileWithResourceQuota runs "Reconcile" on a PipelineRun whose TaskRuns request
+	// more CPU than is left in the ResourceQuota of their namespace. It verifies that only the
+	// TaskRuns which fit are created, and that the PipelineRun is reconciled again later.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("build", "build-task"),
+		tb.PipelineTask("lint", "build-task"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("build-task", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.Step("myimage", tb.StepName("compile"), tb.StepResources(tb.Requests(tb.CPU("1"), tb.Memory("1Gi")))),
+		tb.Step("myimage", tb.StepName("test"), tb.StepResources(tb.Requests(tb.CPU("500m")))),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline"),
+	)}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "foo"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4"), corev1.ResourceRequestsMemory: resource.MustParse("8Gi")},
+			Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2500m"), corev1.ResourceRequestsMemory: resource.MustParse("1Gi")},
+		},
+	}
+	clients := prt.TestAssets.Clients
+	if _, err := clients.Kube.CoreV1().ResourceQuotas("foo").Create(quota); err != nil {
+		t.Fatal(err)
+	}
+	queue := &delayRecordingQueue{
+		RateLimitingInterface: prt.TestAssets.Controller.WorkQueue,
+		delays:                map[interface{}]time.Duration{},
+	}
+	prt.TestAssets.Controller.WorkQueue = queue
+
+	if err := prt.TestAssets.Controller.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run"); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	wantEvents := []string{
+		"Normal Started",
+		`Warning QuotaExceeded Not creating TaskRun "test-pipeline-run-lint-[a-z0-9]+" yet, it requests more than is left of requests.cpu of ResourceQuota compute \(requested 1, available 500m\)`,
+		"Normal QuotaExceeded Tasks Completed: 0",
+	}
+	if err := checkEvents(t, prt.TestAssets.Recorder, "test-pipeline-run", wantEvents); err != nil {
+		t.Error(err)
+	}
+	key := types.NamespacedName{Namespace: "foo", Name: "test-pipeline-run"}
+	if delay, ok := queue.delays[key]; !ok || delay != quotaRetryDelay {
+		t.Errorf("Expected the PipelineRun to be reconciled again after %s, got %v", quotaRetryDelay, queue.delays)
+	}
+
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskRuns.Items) != 1 || taskRuns.Items[0].Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey] != "build" {
+		t.Errorf("Expected only the TaskRun of the build task to be created, got %v", taskRuns.Items)
+	}
+	reconciledRun, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get("test-pipeline-run", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); !condition.IsUnknown() || condition.Reason != ReasonQuotaExceeded {
+		t.Errorf("Expected PipelineRun to be running with reason %s, got %v", ReasonQuotaExceeded, condition)
+	}
+	if len(reconciledRun.Status.TaskRuns) != 1 {
+		t.Errorf("Expected the status of the PipelineRun to list 1 TaskRun, got %v", reconciledRun.Status.TaskRuns)
+	}
+}
 
+func TestReconcileExceedingResourceQuota(t *testing.T) {
+	// TestReconcileExceedingResourceQuota runs "Reconcile" on a PipelineRun whose TaskRuns
+	// request more CPU than the hard limit of the ResourceQuota of their namespace. It
+	// verifies that the PipelineRun fails, as they could never be created.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("build", "build-task"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("build-task", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.Step("myimage", tb.StepName("compile"), tb.StepResources(tb.Requests(tb.CPU("8")))),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline"),
+	)}
 	d := test.Data{
 		PipelineRuns: prs,
 		Pipelines:    ps,
@@ -1634,36 +3216,129 @@ func TestReconcilePropagateAnnotations(t *testing.T) {
 	prt := NewPipelineRunTest(d, t)
 	defer prt.Cancel()
 
-	_, clients := prt.reconcileRun("foo", "test-pipeline-run-with-annotations", []string{}, false)
-
-	actions := clients.Pipeline.Actions()
-	if len(actions) < 2 {
-		t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "foo"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+		},
+	}
+	if _, err := prt.TestAssets.Clients.Kube.CoreV1().ResourceQuotas("foo").Create(quota); err != nil {
+		t.Fatal(err)
 	}
 
-	// Check that the expected TaskRun was created
-	actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
-	if actual == nil {
-		t.Errorf("Expected a TaskRun to be created, but it wasn't.")
+	wantEvents := []string{
+		"Normal Started",
+		`Warning Failed TaskRun "test-pipeline-run-build-[a-z0-9]+" requests more than requests.cpu of ResourceQuota compute \(requested 8, hard limit 4\)`,
+		"Warning InternalError 1 error occurred",
 	}
-	expectedTaskRun := tb.TaskRun("test-pipeline-run-with-annotations-hello-world-1-9l9zj",
-		tb.TaskRunNamespace("foo"),
-		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-with-annotations",
-			tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
-			tb.Controller, tb.BlockOwnerDeletion,
-		),
-		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
-		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
-		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-with-annotations"),
-		tb.TaskRunAnnotation("PipelineRunAnnotation", "PipelineRunValue"),
-		tb.TaskRunSpec(
-			tb.TaskRunTaskRef("hello-world"),
-			tb.TaskRunServiceAccountName("test-sa"),
-		),
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", wantEvents, true)
+	if condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); !condition.IsFalse() || condition.Reason != ReasonQuotaExceeded {
+		t.Errorf("Expected PipelineRun to fail with reason %s, got %v", ReasonQuotaExceeded, condition)
+	}
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskRuns.Items) != 0 {
+		t.Errorf("Expected no TaskRun to be created, got %v", taskRuns.Items)
+	}
+}
+
+func TestReconcileTTLAfterFinished(t *testing.T) {
+	// TestReconcileTTLAfterFinished runs "Reconcile" on PipelineRuns with a TTL after finishing
+	// and a fake clock. It verifies that finished PipelineRuns are deleted once their TTL has
+	// passed, that they are enqueued again for when it will have otherwise, and that running
+	// PipelineRuns are never deleted.
+	now := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	finished := tb.PipelineRunStatus(
+		tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+			Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+		}),
+		tb.PipelineRunStartTime(now.Add(-2*time.Minute)),
+		tb.PipelineRunCompletionTime(now.Add(-time.Minute)),
 	)
+	for _, tc := range []struct {
+		name         string
+		ttl          string
+		defaultTTL   string
+		status       tb.PipelineRunOp
+		wantDeleted  bool
+		wantEnqueued time.Duration
+	}{{
+		name:   "no ttl",
+		status: finished,
+	}, {
+		name:         "ttl not passed",
+		ttl:          "300",
+		status:       finished,
+		wantEnqueued: 4 * time.Minute,
+	}, {
+		name:        "ttl passed",
+		ttl:         "60",
+		status:      finished,
+		wantDeleted: true,
+	}, {
+		name:        "default ttl passed",
+		defaultTTL:  "30",
+		status:      finished,
+		wantDeleted: true,
+	}, {
+		name:         "annotation overrides the default ttl",
+		ttl:          "120",
+		defaultTTL:   "30",
+		status:       finished,
+		wantEnqueued: time.Minute,
+	}, {
+		name:   "running",
+		ttl:    "0",
+		status: tb.PipelineRunStatus(tb.PipelineRunStartTime(now.Add(-time.Minute))),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world"),
+			))}
+			ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+			ops := []tb.PipelineRunOp{tb.PipelineRunNamespace("foo"), tb.PipelineRunSpec("test-pipeline"), tc.status}
+			if tc.ttl != "" {
+				ops = append(ops, tb.PipelineRunAnnotation("tekton.dev/ttl-seconds-after-finished", tc.ttl))
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-ttl", ops...)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+			}
+			if tc.defaultTTL != "" {
+				d.ConfigMaps = []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetDefaultsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"default-ttl-seconds-after-finished": tc.defaultTTL,
+					},
+				}}
+			}
+			testAssets, cancel := getPipelineRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			queue := &delayRecordingQueue{
+				RateLimitingInterface: testAssets.Controller.WorkQueue,
+				delays:                map[interface{}]time.Duration{},
+			}
+			testAssets.Controller.WorkQueue = queue
 
-	if d := cmp.Diff(actual, expectedTaskRun); d != "" {
-		t.Errorf("expected to see TaskRun %v created. Diff %s", expectedTaskRun, diff.PrintWantGot(d))
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-ttl"); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			_, err := testAssets.Clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get("test-pipeline-run-ttl", metav1.GetOptions{})
+			if deleted := k8serrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("Expected the PipelineRun to be deleted: %t, got error %v", tc.wantDeleted, err)
+			}
+			key := types.NamespacedName{Namespace: "foo", Name: "test-pipeline-run-ttl"}
+			if enqueued := queue.delays[key]; enqueued != tc.wantEnqueued {
+				t.Errorf("Expected the PipelineRun to be enqueued after %v, got %v", tc.wantEnqueued, enqueued)
+			}
+		})
 	}
 }
 
@@ -1918,6 +3593,397 @@ func TestReconcileWithConditionChecks(t *testing.T) {
 	}
 }
 
+func TestReconcileWithBundle(t *testing.T) {
+	// TestReconcileWithBundle runs "Reconcile" on a PipelineRun whose Pipeline references a Task
+	// stored in a bundle, which isn't installed in the cluster. It verifies that the TaskRun embeds
+	// the spec of the Task from the bundle, and that the bundle isn't fetched again on the next
+	// reconcile.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundledTask := tb.Task("bundled-task", tb.TaskType(), tb.TaskSpec(
+		tb.Step("busybox", tb.StepName("bundled-step")),
+	))
+	ref, err := test.CreateImage(u.Host+"/bundles/tasks", bundledTask)
+	if err != nil {
+		t.Fatalf("Failed to push the bundle: %v", err)
+	}
+
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-bundle", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("", tb.PipelineRunPipelineSpec(
+			tb.PipelineTask("hello-world-1", "bundled-task", tb.PipelineTaskRefBundle(ref)),
+		)),
+	)}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+	d := test.Data{
+		PipelineRuns: prs,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+	if _, err := prt.TestAssets.Clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-with-bundle", []string{}, false)
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list the TaskRuns: %v", err)
+	}
+	if len(taskRuns.Items) != 1 {
+		t.Fatalf("Expected a TaskRun to be created, got %d", len(taskRuns.Items))
+	}
+	tr := taskRuns.Items[0]
+	if tr.Spec.TaskRef != nil {
+		t.Errorf("Expected the TaskRun not to reference the Task from the bundle, got %v", tr.Spec.TaskRef)
+	}
+	if tr.Spec.TaskSpec == nil || len(tr.Spec.TaskSpec.Steps) != 1 || tr.Spec.TaskSpec.Steps[0].Name != "bundled-step" {
+		t.Errorf("Expected the TaskRun to embed the spec of the Task from the bundle, got %v", tr.Spec.TaskSpec)
+	}
+
+	// The Task resolved from the bundle is cached for the lifetime of the PipelineRun.
+	s.Close()
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-with-bundle", []string{}, false)
+	if reconciledRun.IsDone() {
+		t.Errorf("Expected the PipelineRun to keep running without fetching the bundle again, got %v", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+}
+
+func TestReconcileWithUnboundOptionalWorkspace(t *testing.T) {
+	// TestReconcileWithUnboundOptionalWorkspace runs "Reconcile" on a PipelineRun which doesn't bind an
+	// optional workspace of its Pipeline. It verifies that the tasks using the workspace, and the tasks
+	// depending on them, are skipped and recorded in the status, while the other tasks are run.
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineWorkspaceDeclaration("cache"),
+		tb.PipelineTask("restore-cache", "use-cache", tb.PipelineTaskWorkspaceBinding("cache", "cache", "")),
+		tb.PipelineTask("build", "hello-world", tb.RunAfter("restore-cache")),
+		tb.PipelineTask("lint", "hello-world"),
+		tb.FinalPipelineTask("save-cache", "use-cache", tb.PipelineTaskWorkspaceBinding("cache", "cache", "")),
+	))}
+	ps[0].Spec.Workspaces[0].Optional = true
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-without-cache", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+	)}
+	ts := []*v1beta1.Task{
+		tb.Task("hello-world", tb.TaskNamespace("foo")),
+		tb.Task("use-cache", tb.TaskNamespace("foo"), tb.TaskSpec(
+			tb.TaskWorkspace("cache", "", "", false),
+		)),
+	}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-without-cache", []string{}, false)
+
+	var created []string
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+			created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun).Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+		}
+	}
+	if d := cmp.Diff([]string{"lint"}, created); d != "" {
+		t.Errorf("Unexpected TaskRuns created %s", diff.PrintWantGot(d))
+	}
+
+	expectedSkippedTasks := []v1beta1.SkippedTask{{
+		Name:   "restore-cache",
+		Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+	}, {
+		Name:   "build",
+		Reason: v1beta1.ParentTasksSkip,
+	}, {
+		Name:   "save-cache",
+		Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+	}}
+	if d := cmp.Diff(expectedSkippedTasks, reconciledRun.Status.SkippedTasks); d != "" {
+		t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+	}
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition.Reason != v1beta1.PipelineRunReasonRunning.String() {
+		t.Errorf("Expected PipelineRun to keep running, got reason %s", condition.Reason)
+	}
+}
+
+func TestReconcileWithWhenExpressionsOnWorkspaceBinding(t *testing.T) {
+	// TestReconcileWithWhenExpressionsOnWorkspaceBinding runs "Reconcile" on PipelineRuns which bind,
+	// or don't bind, an optional workspace of their Pipeline. It verifies that the tasks guarded by a
+	// when expression on $(workspaces.cache.bound) are run or skipped accordingly.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineWorkspaceDeclaration("cache"),
+		tb.PipelineTask("with-cache", "hello-world",
+			tb.PipelineTaskWhenExpression("$(workspaces.cache.bound)", selection.In, "true")),
+		tb.PipelineTask("without-cache", "hello-world",
+			tb.PipelineTaskWhenExpression("$(workspaces.cache.bound)", selection.NotIn, "true")),
+	))}
+	ps[0].Spec.Workspaces[0].Optional = true
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+
+	for _, tc := range []struct {
+		name        string
+		bound       bool
+		wantCreated string
+		wantSkipped string
+	}{{
+		name:        "bound",
+		bound:       true,
+		wantCreated: "with-cache",
+		wantSkipped: "without-cache",
+	}, {
+		name:        "unbound",
+		wantCreated: "without-cache",
+		wantSkipped: "with-cache",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			prSpecOps := []tb.PipelineRunSpecOp{tb.PipelineRunServiceAccountName("test-sa")}
+			if tc.bound {
+				prSpecOps = append(prSpecOps, tb.PipelineRunWorkspaceBindingEmptyDir("cache"))
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-when", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", prSpecOps...),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-when", []string{}, false)
+
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun).Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+				}
+			}
+			if d := cmp.Diff([]string{tc.wantCreated}, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created %s", diff.PrintWantGot(d))
+			}
+
+			expectedSkippedTasks := []v1beta1.SkippedTask{{
+				Name:   tc.wantSkipped,
+				Reason: v1beta1.WhenExpressionsSkip,
+			}}
+			if d := cmp.Diff(expectedSkippedTasks, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskWorkspaceByWorkspaceVolumeSource_CSI(t *testing.T) {
+	// TestTaskWorkspaceByWorkspaceVolumeSource_CSI verifies that the CSI volume bound to a workspace of
+	// a PipelineRun is passed to its TaskRuns untouched, and isn't shared with the PipelineRun.
+	readOnly := true
+	wb := v1beta1.WorkspaceBinding{
+		Name: "secrets",
+		CSI: &corev1.CSIVolumeSource{
+			Driver:           "secrets-store.csi.k8s.io",
+			ReadOnly:         &readOnly,
+			VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+		},
+	}
+	want := v1beta1.WorkspaceBinding{
+		Name:    "credentials",
+		SubPath: "db",
+		CSI: &corev1.CSIVolumeSource{
+			Driver:           "secrets-store.csi.k8s.io",
+			ReadOnly:         &readOnly,
+			VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+		},
+	}
+	got := taskWorkspaceByWorkspaceVolumeSource(wb, "credentials", "db", metav1.OwnerReference{})
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected workspace binding of the TaskRun %s", diff.PrintWantGot(d))
+	}
+	got.CSI.VolumeAttributes["secretProviderClass"] = "other"
+	if wb.CSI.VolumeAttributes["secretProviderClass"] != "vault-database" {
+		t.Error("Expected the CSI volume of the TaskRun not to be shared with the PipelineRun")
+	}
+}
+
+func TestReconcileWithWorkspaceBindingCondition(t *testing.T) {
+	// TestReconcileWithWorkspaceBindingCondition runs "Reconcile" on PipelineRuns of a Pipeline binding
+	// its cache workspace to a task only when the cache-enabled param is true. It verifies that the
+	// TaskRun gets the workspace of the PipelineRun when the condition is true and an emptyDir otherwise,
+	// even if the PipelineRun doesn't bind the optional workspace.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineParamSpec("cache-enabled", v1beta1.ParamTypeString, tb.ParamSpecDefault("false")),
+		tb.PipelineWorkspaceDeclaration("cache"),
+		tb.PipelineTask("build", "build",
+			tb.PipelineTaskWorkspaceBindingCondition("cache", "cache", `$(params.cache-enabled) == "true"`)),
+	))}
+	ps[0].Spec.Workspaces[0].Optional = true
+	ts := []*v1beta1.Task{tb.Task("build", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.TaskWorkspace("cache", "", "", false),
+		tb.Step("builder"),
+	))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields":          config.AlphaAPIFields,
+			"disable-affinity-assistant": "true",
+		},
+	}}
+	cacheBinding := v1beta1.WorkspaceBinding{
+		Name:                  "cache",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache-claim"},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		cacheEnabled  string
+		bound         bool
+		wantWorkspace v1beta1.WorkspaceBinding
+	}{{
+		name:          "condition true",
+		cacheEnabled:  "true",
+		bound:         true,
+		wantWorkspace: cacheBinding,
+	}, {
+		name:          "condition false",
+		cacheEnabled:  "false",
+		bound:         true,
+		wantWorkspace: v1beta1.WorkspaceBinding{Name: "cache", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}, {
+		name:          "condition false and workspace unbound",
+		cacheEnabled:  "false",
+		wantWorkspace: v1beta1.WorkspaceBinding{Name: "cache", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			pr := tb.PipelineRun("test-pipeline-run-cache", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline",
+					tb.PipelineRunServiceAccountName("test-sa"),
+					tb.PipelineRunParam("cache-enabled", tc.cacheEnabled),
+				),
+			)
+			if tc.bound {
+				pr.Spec.Workspaces = []v1beta1.WorkspaceBinding{cacheBinding}
+			}
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{pr},
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-cache", []string{}, false)
+
+			var created []*v1beta1.TaskRun
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun))
+				}
+			}
+			if len(created) != 1 {
+				t.Fatalf("Expected the TaskRun of the build task to be created, got %d TaskRuns", len(created))
+			}
+			if d := cmp.Diff([]v1beta1.WorkspaceBinding{tc.wantWorkspace}, created[0].Spec.Workspaces); d != "" {
+				t.Errorf("Unexpected workspaces of the TaskRun %s", diff.PrintWantGot(d))
+			}
+			if len(reconciledRun.Status.SkippedTasks) != 0 {
+				t.Errorf("Expected no skipped tasks, got %v", reconciledRun.Status.SkippedTasks)
+			}
+		})
+	}
+}
+
+func TestReconcileWithWorkspaceSubPathPerRun(t *testing.T) {
+	// TestReconcileWithWorkspaceSubPathPerRun runs "Reconcile" on a PipelineRun binding a shared claim
+	// with a subPath referencing its params and context, and with subPathPerRun. It verifies that its
+	// TaskRuns get the resolved subPath, followed by the name of the PipelineRun and the subPath of the
+	// PipelineTask, and that subPathPerRun isn't passed to them.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineParamSpec("team", v1beta1.ParamTypeString, tb.ParamSpecDefault("platform")),
+		tb.PipelineWorkspaceDeclaration("source"),
+		tb.PipelineTask("build", "build", tb.PipelineTaskWorkspaceBinding("src", "source", "build")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("build", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.TaskWorkspace("src", "", "", false),
+		tb.Step("builder"),
+	))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields":          config.AlphaAPIFields,
+			"disable-affinity-assistant": "true",
+		},
+	}}
+	pr := tb.PipelineRun("test-pipeline-run-shared", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+	)
+	pr.Spec.Workspaces = []v1beta1.WorkspaceBinding{{
+		Name:                  "source",
+		SubPath:               "$(params.team)/$(context.pipeline.name)",
+		SubPathPerRun:         true,
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared-claim"},
+	}}
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		Pipelines:    ps,
+		Tasks:        ts,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-shared", []string{}, false)
+
+	var created []*v1beta1.TaskRun
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+			created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun))
+		}
+	}
+	if len(created) != 1 {
+		t.Fatalf("Expected the TaskRun of the build task to be created, got %d TaskRuns", len(created))
+	}
+	want := []v1beta1.WorkspaceBinding{{
+		Name:                  "src",
+		SubPath:               "platform/test-pipeline/test-pipeline-run-shared/build",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared-claim"},
+	}}
+	if d := cmp.Diff(want, created[0].Spec.Workspaces); d != "" {
+		t.Errorf("Unexpected workspaces of the TaskRun %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestReconcileWithFailingConditionChecks(t *testing.T) {
 	// TestReconcileWithFailingConditionChecks runs "Reconcile" on a PipelineRun that has a task with
 	// multiple conditions, some that fails. It verifies that reconcile is successful, taskruns are
@@ -2341,12 +4407,74 @@ func TestReconcileWithVolumeClaimTemplateWorkspaceUsingSubPaths(t *testing.T) {
 		t.Fatalf("did not see a taskRun with workspace using empty pipelineTask subPath and a subPath from pipelineRun")
 	}
 
-	if !hasSeenWorkspaceWithRunSubPathAndPipelineTaskSubPath1 {
-		t.Fatalf("did not see a taskRun with workspace using pipelineTaks subPath1 and a subPath from pipelineRun")
-	}
+	if !hasSeenWorkspaceWithRunSubPathAndPipelineTaskSubPath1 {
+		t.Fatalf("did not see a taskRun with workspace using pipelineTaks subPath1 and a subPath from pipelineRun")
+	}
+
+	if !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+		t.Errorf("Expected PipelineRun to be running, but condition status is %s", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+}
+
+func TestReconcileWithReadOnlyWorkspace(t *testing.T) {
+	workspaceName := "ws1"
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("write", "writer", tb.PipelineTaskWorkspaceBinding("output", workspaceName, "")),
+		tb.PipelineTask("read", "reader", tb.PipelineTaskWorkspaceBinding("input", workspaceName, "")),
+		tb.PipelineWorkspaceDeclaration(workspaceName),
+	))}
+	ts := []*v1beta1.Task{
+		tb.Task("writer", tb.TaskNamespace("foo"), tb.TaskSpec(tb.TaskWorkspace("output", "", "", false))),
+		tb.Task("reader", tb.TaskNamespace("foo"), tb.TaskSpec(tb.TaskWorkspace("input", "", "", true))),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		readOnly bool
+	}{{
+		name: "writable binding",
+	}, {
+		name:     "read-only binding",
+		readOnly: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline",
+					tb.PipelineRunWorkspaceBindingVolumeClaimTemplate(workspaceName, "myclaim", ""))),
+			}
+			prs[0].Spec.Workspaces[0].ReadOnly = tc.readOnly
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			// The reader can't be bound to a volume the writer writes to.
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, !tc.readOnly)
+			if !tc.readOnly {
+				if condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); !condition.IsFalse() || condition.Reason != ReasonInvalidWorkspaceBinding {
+					t.Errorf("Expected PipelineRun to fail with reason %s, got %v", ReasonInvalidWorkspaceBinding, condition)
+				}
+				return
+			}
 
-	if !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
-		t.Errorf("Expected PipelineRun to be running, but condition status is %s", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+			taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error when listing TaskRuns: %v", err)
+			}
+			if len(taskRuns.Items) != 2 {
+				t.Fatalf("unexpected number of taskRuns found, expected 2, but found %d", len(taskRuns.Items))
+			}
+			for _, tr := range taskRuns.Items {
+				for _, ws := range tr.Spec.Workspaces {
+					if !ws.ReadOnly {
+						t.Errorf("expected workspace %q of taskRun %s to be read-only", ws.Name, tr.Name)
+					}
+				}
+			}
+		})
 	}
 }
 
@@ -2442,6 +4570,235 @@ func TestReconcileWithTaskResults(t *testing.T) {
 	}
 }
 
+// fakeCustomTaskController stands in for the controller of a custom task: it
+// completes the Runs created by the PipelineRun controller.
+type fakeCustomTaskController struct {
+	t       *testing.T
+	clients test.Clients
+}
+
+// complete sets the Succeeded condition of the Run name to status, with results.
+func (c fakeCustomTaskController) complete(namespace, name string, status corev1.ConditionStatus, results ...runv1alpha1.RunResult) {
+	c.t.Helper()
+	run, err := c.clients.Pipeline.TektonV1alpha1().Runs(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		c.t.Fatalf("Failed to get Run %s: %v", name, err)
+	}
+	run.Status.InitializeConditions()
+	run.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: status,
+		Reason: "Completed",
+	})
+	run.Status.Results = results
+	if _, err := c.clients.Pipeline.TektonV1alpha1().Runs(namespace).UpdateStatus(run); err != nil {
+		c.t.Fatalf("Failed to update the status of Run %s: %v", name, err)
+	}
+}
+
+// TestReconcileWithCustomTask runs "Reconcile" on a PipelineRun whose Pipeline references a
+// custom task. It verifies that a Run is created for the custom task, that the results of the
+// Run are passed to the tasks depending on it once it succeeds, and that its failure fails the
+// PipelineRun.
+func TestReconcileWithCustomTask(t *testing.T) {
+	ps := []*v1beta1.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{
+				Name: "wait",
+				TaskRef: &v1beta1.TaskRef{
+					APIVersion: "example.dev/v0",
+					Kind:       "Wait",
+				},
+				Params:  []v1beta1.Param{{Name: "duration", Value: v1beta1.NewArrayOrString("1m")}},
+				Retries: 2,
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+			}, {
+				Name:    "b-task",
+				TaskRef: &v1beta1.TaskRef{Name: "b-task"},
+				Params:  []v1beta1.Param{{Name: "bParam", Value: v1beta1.NewArrayOrString("$(tasks.wait.results.waited)")}},
+			}},
+		},
+	}}
+	ts := []*v1beta1.Task{
+		tb.Task("b-task", tb.TaskNamespace("foo"),
+			tb.TaskSpec(
+				tb.TaskParam("bParam", v1beta1.ParamTypeString),
+			),
+		),
+	}
+
+	for _, tc := range []struct {
+		name          string
+		status        corev1.ConditionStatus
+		wantCondition corev1.ConditionStatus
+		wantTaskRun   bool
+	}{{
+		name:          "succeeded",
+		status:        corev1.ConditionTrue,
+		wantCondition: corev1.ConditionUnknown,
+		wantTaskRun:   true,
+	}, {
+		name:          "failed",
+		status:        corev1.ConditionFalse,
+		wantCondition: corev1.ConditionFalse,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline"),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				ConfigMaps:   alphaFeatureFlags(),
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run", []string{}, false)
+
+			runName := "test-pipeline-run-wait-9l9zj"
+			run, err := clients.Pipeline.TektonV1alpha1().Runs("foo").Get(runName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the Run %s to be created: %v", runName, err)
+			}
+			wantSpec := v1alpha1.RunSpec{
+				Ref:     &v1beta1.TaskRef{APIVersion: "example.dev/v0", Kind: "Wait"},
+				Params:  []v1beta1.Param{{Name: "duration", Value: v1beta1.NewArrayOrString("1m")}},
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+				Retries: 2,
+			}
+			if d := cmp.Diff(wantSpec, run.Spec); d != "" {
+				t.Errorf("Run spec %s", diff.PrintWantGot(d))
+			}
+			wantLabels := map[string]string{
+				"tekton.dev/pipeline":     "test-pipeline",
+				"tekton.dev/pipelineRun":  "test-pipeline-run",
+				"tekton.dev/pipelineTask": "wait",
+			}
+			if d := cmp.Diff(wantLabels, run.Labels); d != "" {
+				t.Errorf("Run labels %s", diff.PrintWantGot(d))
+			}
+			if len(run.OwnerReferences) != 1 || run.OwnerReferences[0].Name != "test-pipeline-run" {
+				t.Errorf("Expected the Run to be owned by the PipelineRun, got %v", run.OwnerReferences)
+			}
+			if prrs := reconciledRun.Status.Runs[runName]; prrs == nil || prrs.PipelineTaskName != "wait" {
+				t.Errorf("Expected the Run %s of the PipelineTask wait in the PipelineRun status, got %v", runName, reconciledRun.Status.Runs)
+			}
+			taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Failure to list TaskRun's %s", err)
+			}
+			if len(taskRuns.Items) != 0 {
+				t.Fatalf("Expected no TaskRun before the Run completes, got %d", len(taskRuns.Items))
+			}
+
+			fakeCustomTaskController{t: t, clients: clients}.complete("foo", runName, tc.status, runv1alpha1.RunResult{Name: "waited", Value: "1m"})
+
+			reconciledRun, clients = prt.reconcileRun("foo", "test-pipeline-run", []string{}, false)
+			if c := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); c.Status != tc.wantCondition {
+				t.Errorf("Expected the PipelineRun condition to be %s, got %v", tc.wantCondition, c)
+			}
+			taskRuns, err = clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{
+				LabelSelector: "tekton.dev/pipelineTask=b-task,tekton.dev/pipelineRun=test-pipeline-run",
+			})
+			if err != nil {
+				t.Fatalf("Failure to list TaskRun's %s", err)
+			}
+			if !tc.wantTaskRun {
+				if len(taskRuns.Items) != 0 {
+					t.Errorf("Expected no TaskRun after the Run failed, got %d", len(taskRuns.Items))
+				}
+				return
+			}
+			if len(taskRuns.Items) != 1 {
+				t.Fatalf("Expected 1 TaskRuns got %d", len(taskRuns.Items))
+			}
+			wantParams := []v1beta1.Param{{Name: "bParam", Value: v1beta1.NewArrayOrString("1m")}}
+			if d := cmp.Diff(wantParams, taskRuns.Items[0].Spec.Params); d != "" {
+				t.Errorf("TaskRun params %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+// TestReconcileWithTaskResultAlias verifies that a result referenced by one of its aliases
+// resolves to the value of the result, and that a warning event is emitted.
+func TestReconcileWithTaskResultAlias(t *testing.T) {
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("a-task", "a-task"),
+		tb.PipelineTask("b-task", "b-task",
+			tb.PipelineTaskParam("bParam", "$(tasks.a-task.results.oldResult)"),
+		),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-alias", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline"),
+	)}
+	ts := []*v1beta1.Task{
+		tb.Task("a-task", tb.TaskNamespace("foo")),
+		tb.Task("b-task", tb.TaskNamespace("foo"),
+			tb.TaskSpec(
+				tb.TaskParam("bParam", v1beta1.ParamTypeString),
+			),
+		),
+	}
+	tr := tb.TaskRun("test-pipeline-run-alias-a-task-xxyyy",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-alias",
+			tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+			tb.Controller, tb.BlockOwnerDeletion,
+		),
+		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-alias"),
+		tb.TaskRunLabel("tekton.dev/pipelineTask", "a-task"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("a-task")),
+		tb.TaskRunStatus(
+			tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			}),
+			tb.TaskRunResult("aResult", "aResultValue"),
+		),
+	)
+	tr.Status.TaskSpec = &v1beta1.TaskSpec{
+		Results: []v1beta1.TaskResult{{Name: "aResult", Aliases: []string{"oldResult"}}},
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     []*v1beta1.TaskRun{tr},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		`Warning ResultAliasUsed Result "aResult" of pipeline task "a-task" is referenced by its alias "oldResult"`,
+		"Normal Running Tasks Completed: 1",
+	}
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-alias", wantEvents, false)
+
+	actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{
+		LabelSelector: "tekton.dev/pipelineTask=b-task,tekton.dev/pipelineRun=test-pipeline-run-alias",
+		Limit:         1,
+	})
+	if err != nil {
+		t.Fatalf("Failure to list TaskRun's %s", err)
+	}
+	if len(actual.Items) != 1 {
+		t.Fatalf("Expected 1 TaskRuns got %d", len(actual.Items))
+	}
+	wantParams := []v1beta1.Param{{Name: "bParam", Value: v1beta1.NewArrayOrString("aResultValue")}}
+	if d := cmp.Diff(wantParams, actual.Items[0].Spec.Params); d != "" {
+		t.Errorf("expected the alias to resolve to the value of the result %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestReconcileWithTaskResultsEmbeddedNoneStarted(t *testing.T) {
 	names.TestingSeed()
 	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-different-service-accs", tb.PipelineRunNamespace("foo"),
@@ -2513,6 +4870,352 @@ func TestReconcileWithTaskResultsEmbeddedNoneStarted(t *testing.T) {
 	}
 }
 
+func TestReconcilePipeline_FinalTasksUseExecutionStatusAndResults(t *testing.T) {
+	// TestReconcilePipeline_FinalTasksUseExecutionStatusAndResults runs "Reconcile" on PipelineRuns
+	// whose DAG tasks are done. It checks that the final tasks get the execution status of the
+	// DAG tasks and their results, and that the final task using a result which wasn't produced
+	// is skipped.
+	taskRun := func(prName, pipelineTask string, status corev1.ConditionStatus, results ...v1beta1.TaskRunResult) *v1beta1.TaskRun {
+		tr := tb.TaskRun(prName+"-"+pipelineTask,
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, pipelineTask),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: status,
+				}),
+			),
+		)
+		tr.Status.TaskRunResults = results
+		return tr
+	}
+	commit := v1beta1.TaskRunResult{Name: "commit", Value: "abc123"}
+
+	for _, tc := range []struct {
+		name             string
+		dagTask1Status   corev1.ConditionStatus
+		dagTask2Status   corev1.ConditionStatus
+		wantParams       map[string][]v1beta1.Param
+		wantSkippedTasks []v1beta1.SkippedTask
+	}{{
+		name:           "succeeded",
+		dagTask1Status: corev1.ConditionTrue,
+		dagTask2Status: corev1.ConditionTrue,
+		wantParams: map[string][]v1beta1.Param{
+			"final-status": {
+				{Name: "dag-task-1-status", Value: v1beta1.NewArrayOrString("Succeeded")},
+				{Name: "aggregate-status", Value: v1beta1.NewArrayOrString("Succeeded")},
+			},
+			"final-result": {
+				{Name: "commit", Value: v1beta1.NewArrayOrString("abc123")},
+			},
+		},
+	}, {
+		name:           "completed",
+		dagTask1Status: corev1.ConditionTrue,
+		dagTask2Status: corev1.ConditionFalse,
+		wantParams: map[string][]v1beta1.Param{
+			"final-status": {
+				{Name: "dag-task-1-status", Value: v1beta1.NewArrayOrString("Succeeded")},
+				{Name: "aggregate-status", Value: v1beta1.NewArrayOrString("Completed")},
+			},
+			"final-result": {
+				{Name: "commit", Value: v1beta1.NewArrayOrString("abc123")},
+			},
+		},
+	}, {
+		name:           "failed",
+		dagTask1Status: corev1.ConditionFalse,
+		dagTask2Status: corev1.ConditionTrue,
+		wantParams: map[string][]v1beta1.Param{
+			"final-status": {
+				{Name: "dag-task-1-status", Value: v1beta1.NewArrayOrString("Failed")},
+				{Name: "aggregate-status", Value: v1beta1.NewArrayOrString("Failed")},
+			},
+		},
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:   "final-result",
+			Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prName := "test-pipeline-run-final-tasks-" + tc.name
+			var dagTask1Results []v1beta1.TaskRunResult
+			if tc.dagTask1Status == corev1.ConditionTrue {
+				dagTask1Results = append(dagTask1Results, commit)
+			}
+			trs := []*v1beta1.TaskRun{
+				taskRun(prName, "dag-task-1", tc.dagTask1Status, dagTask1Results...),
+				taskRun(prName, "dag-task-2", tc.dagTask2Status),
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun(prName,
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+					tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "dag-task-1",
+						Status:           &trs[0].Status,
+					}),
+					tb.PipelineRunTaskRunsStatus(trs[1].Name, &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "dag-task-2",
+						Status:           &trs[1].Status,
+					}),
+				),
+			)}
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("dag-task-1", "hello-world"),
+				tb.PipelineTask("dag-task-2", "hello-world", tb.ContinueOnFailure()),
+				tb.FinalPipelineTask("final-status", "report",
+					tb.PipelineTaskParam("dag-task-1-status", "$(tasks.dag-task-1.status)"),
+					tb.PipelineTaskParam("aggregate-status", "$(tasks.status)"),
+				),
+				tb.FinalPipelineTask("final-result", "report",
+					tb.PipelineTaskParam("commit", "$(tasks.dag-task-1.results.commit)"),
+				),
+			))}
+			ts := []*v1beta1.Task{
+				tb.Task("hello-world", tb.TaskNamespace("foo")),
+				tb.Task("report", tb.TaskNamespace("foo"), tb.TaskSpec(
+					tb.TaskParam("dag-task-1-status", v1beta1.ParamTypeString, tb.ParamSpecDefault("")),
+					tb.TaskParam("aggregate-status", v1beta1.ParamTypeString, tb.ParamSpecDefault("")),
+					tb.TaskParam("commit", v1beta1.ParamTypeString, tb.ParamSpecDefault("")),
+				)),
+			}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", prName, []string{}, false)
+
+			params := map[string][]v1beta1.Param{}
+			for _, action := range clients.Pipeline.Actions() {
+				if action != nil && action.Matches("create", "taskruns") {
+					tr := action.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+					params[tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey]] = tr.Spec.Params
+				}
+			}
+			if d := cmp.Diff(tc.wantParams, params); d != "" {
+				t.Errorf("Expected the final TaskRuns to be created with the params %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantSkippedTasks, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Expected the skipped tasks to match %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcilePipeline_FinalTasksWithWhenExpressions(t *testing.T) {
+	// TestReconcilePipeline_FinalTasksWithWhenExpressions runs "Reconcile" on PipelineRuns whose DAG
+	// tasks are done. It checks that the when expressions of the final tasks are evaluated with the
+	// execution status and the results of the DAG tasks, so that one final task is run while the
+	// other one is skipped.
+	taskRun := func(prName string, status corev1.ConditionStatus, results ...v1beta1.TaskRunResult) *v1beta1.TaskRun {
+		tr := tb.TaskRun(prName+"-dag-task",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "dag-task"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: status,
+				}),
+			),
+		)
+		tr.Status.TaskRunResults = results
+		return tr
+	}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("dag-task", "hello-world"),
+		tb.FinalPipelineTask("notify-on-failure", "hello-world",
+			tb.PipelineTaskWhenExpression("$(tasks.status)", selection.In, "Failed")),
+		tb.FinalPipelineTask("publish", "hello-world",
+			tb.PipelineTaskWhenExpression("$(tasks.dag-task.status)", selection.In, "Succeeded"),
+			tb.PipelineTaskWhenExpression("$(tasks.dag-task.results.commit)", selection.NotIn, "")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+
+	for _, tc := range []struct {
+		name             string
+		dagTaskStatus    corev1.ConditionStatus
+		dagTaskResults   []v1beta1.TaskRunResult
+		wantCreated      string
+		wantSkippedTasks []v1beta1.SkippedTask
+	}{{
+		name:           "succeeded",
+		dagTaskStatus:  corev1.ConditionTrue,
+		dagTaskResults: []v1beta1.TaskRunResult{{Name: "commit", Value: "abc123"}},
+		wantCreated:    "publish",
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:   "notify-on-failure",
+			Reason: v1beta1.FinallyWhenExpressionsSkip,
+		}},
+	}, {
+		name:          "failed",
+		dagTaskStatus: corev1.ConditionFalse,
+		wantCreated:   "notify-on-failure",
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:   "publish",
+			Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prName := "test-pipeline-run-final-when-" + tc.name
+			trs := []*v1beta1.TaskRun{taskRun(prName, tc.dagTaskStatus, tc.dagTaskResults...)}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun(prName,
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+					tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "dag-task",
+						Status:           &trs[0].Status,
+					}),
+				),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", prName, []string{}, false)
+
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun).Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+				}
+			}
+			if d := cmp.Diff([]string{tc.wantCreated}, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantSkippedTasks, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileWithWhenExpressionsUsingTaskResults(t *testing.T) {
+	// TestReconcileWithWhenExpressionsUsingTaskResults runs "Reconcile" on PipelineRuns whose tasks
+	// are guarded by when expressions using the result of another task in their values. It checks
+	// that they aren't evaluated until the task producing the result is done, and then that one
+	// of the guarded tasks is run while the other one is skipped.
+	taskRun := func(prName string, status corev1.ConditionStatus, results ...v1beta1.TaskRunResult) *v1beta1.TaskRun {
+		tr := tb.TaskRun(prName+"-dag-task",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, prName),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "dag-task"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: status,
+				}),
+			),
+		)
+		tr.Status.TaskRunResults = results
+		return tr
+	}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("dag-task", "hello-world"),
+		tb.PipelineTask("deploy", "hello-world",
+			tb.PipelineTaskWhenExpression("abc123", selection.In, "$(tasks.dag-task.results.commit)")),
+		tb.PipelineTask("rollback", "hello-world",
+			tb.PipelineTaskWhenExpression("abc123", selection.NotIn, "$(tasks.dag-task.results.commit)")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	cms := []*corev1.ConfigMap{{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+		Data: map[string]string{
+			"enable-api-fields": config.AlphaAPIFields,
+		},
+	}}
+
+	for _, tc := range []struct {
+		name             string
+		dagTaskStatus    corev1.ConditionStatus
+		dagTaskResults   []v1beta1.TaskRunResult
+		wantCreated      []string
+		wantSkippedTasks []v1beta1.SkippedTask
+	}{{
+		name:          "running",
+		dagTaskStatus: corev1.ConditionUnknown,
+	}, {
+		name:           "succeeded",
+		dagTaskStatus:  corev1.ConditionTrue,
+		dagTaskResults: []v1beta1.TaskRunResult{{Name: "commit", Value: "abc123"}},
+		wantCreated:    []string{"deploy"},
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:   "rollback",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prName := "test-pipeline-run-when-results-" + tc.name
+			trs := []*v1beta1.TaskRun{taskRun(prName, tc.dagTaskStatus, tc.dagTaskResults...)}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun(prName,
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now()),
+					tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "dag-task",
+						Status:           &trs[0].Status,
+					}),
+				),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+				ConfigMaps:   cms,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", prName, []string{}, false)
+
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+					created = append(created, a.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun).Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+				}
+			}
+			if d := cmp.Diff(tc.wantCreated, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantSkippedTasks, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestReconcileWithPipelineResults(t *testing.T) {
 	names.TestingSeed()
 	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
@@ -2587,7 +5290,8 @@ func TestReconcileWithPipelineResults(t *testing.T) {
 
 	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", []string{}, false)
 
-	if d := cmp.Diff(&reconciledRun, &prs[0], ignoreResourceVersion); d != "" {
+	wantRun := withFinalizer(prs)[0]
+	if d := cmp.Diff(&reconciledRun, &wantRun, ignoreResourceVersion); d != "" {
 		t.Errorf("expected to see pipeline run results created. Diff %s", diff.PrintWantGot(d))
 	}
 }
@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// quotaRetryDelay is how long a PipelineRun whose TaskRuns don't fit in the
+	// quota left waits before being reconciled again.
+	quotaRetryDelay = 30 * time.Second
+	// resourceQuotaSyncTimeout is how long a reconcile waits for the
+	// ResourceQuotas to be synced after they start being watched.
+	resourceQuotaSyncTimeout = 5 * time.Second
+)
+
+// quotaResources maps the resources limited by ResourceQuotas to the requests of
+// containers they limit.
+var quotaResources = map[corev1.ResourceName]corev1.ResourceName{
+	corev1.ResourceCPU:            corev1.ResourceCPU,
+	corev1.ResourceRequestsCPU:    corev1.ResourceCPU,
+	corev1.ResourceMemory:         corev1.ResourceMemory,
+	corev1.ResourceRequestsMemory: corev1.ResourceMemory,
+}
+
+// quotaExceededError is returned by runNextSchedulableTask when TaskRuns of a
+// PipelineRun weren't created because their requests exceed the quota left in
+// their namespace. The PipelineRun is then reconciled again after quotaRetryDelay,
+// until other Pods release enough quota.
+type quotaExceededError struct {
+	taskRunNames []string
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("not enough resource quota left to create TaskRuns %s", strings.Join(e.taskRunNames, ", "))
+}
+
+// namespaceQuota is the CPU and memory limited by a ResourceQuota, and left in it.
+type namespaceQuota struct {
+	name      string
+	hard      corev1.ResourceList
+	available corev1.ResourceList
+}
+
+// quotaTracker tracks the CPU and memory left in the ResourceQuotas of a namespace
+// while the TaskRuns of a PipelineRun are created, as the usage of the quotas is
+// only updated once their Pods are created.
+type quotaTracker struct {
+	quotas []namespaceQuota
+}
+
+// resourceQuotaWatch watches the ResourceQuotas of the cluster from the first
+// time TaskRuns of a PipelineRun are created.
+type resourceQuotaWatch struct {
+	kubeclient kubernetes.Interface
+	stopCh     <-chan struct{}
+
+	once   sync.Once
+	lister corev1listers.ResourceQuotaLister
+	synced cache.InformerSynced
+}
+
+func newResourceQuotaWatch(ctx context.Context, kubeclient kubernetes.Interface) *resourceQuotaWatch {
+	return &resourceQuotaWatch{
+		kubeclient: kubeclient,
+		stopCh:     ctx.Done(),
+	}
+}
+
+// resourceQuotas starts watching the ResourceQuotas the first time it is
+// called, and returns their lister once they are synced.
+func (w *resourceQuotaWatch) resourceQuotas(ctx context.Context) (corev1listers.ResourceQuotaLister, error) {
+	w.once.Do(func() {
+		factory := informers.NewSharedInformerFactory(w.kubeclient, 0)
+		informer := factory.Core().V1().ResourceQuotas()
+		w.lister = informer.Lister()
+		w.synced = informer.Informer().HasSynced
+		factory.Start(w.stopCh)
+	})
+	ctx, cancel := context.WithTimeout(ctx, resourceQuotaSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), w.synced) {
+		return nil, errors.New("timed out waiting for the ResourceQuotas to be synced")
+	}
+	return w.lister, nil
+}
+
+// newQuotaTracker returns a quotaTracker for the ResourceQuotas of namespace
+// limiting the CPU or memory requests of Pods.
+func (c *Reconciler) newQuotaTracker(ctx context.Context, namespace string) (*quotaTracker, error) {
+	lister, err := c.resourceQuotas.resourceQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := lister.ResourceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the ResourceQuotas of namespace %s: %w", namespace, err)
+	}
+	// Sort the ResourceQuotas by name for the exceeded quotas to be listed in a
+	// stable order.
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	t := &quotaTracker{}
+	for _, q := range list {
+		hard := corev1.ResourceList{}
+		available := corev1.ResourceList{}
+		for name := range quotaResources {
+			h, ok := q.Status.Hard[name]
+			if !ok {
+				continue
+			}
+			hard[name] = h.DeepCopy()
+			left := h.DeepCopy()
+			if used, ok := q.Status.Used[name]; ok {
+				left.Sub(used)
+			}
+			available[name] = left
+		}
+		if len(available) > 0 {
+			t.quotas = append(t.quotas, namespaceQuota{name: q.Name, hard: hard, available: available})
+		}
+	}
+	return t, nil
+}
+
+// neverFits returns the description of the quotas whose hard limit is less than
+// requests, which can then never be created, or an empty string if there are none.
+func (t *quotaTracker) neverFits(requests corev1.ResourceList) string {
+	var exceeded []string
+	for _, q := range t.quotas {
+		for name, hard := range q.hard {
+			if req, ok := requests[quotaResources[name]]; ok && req.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s of ResourceQuota %s (requested %s, hard limit %s)", name, q.name, req.String(), hard.String()))
+			}
+		}
+	}
+	sort.Strings(exceeded)
+	return strings.Join(exceeded, ", ")
+}
+
+// reserve deducts requests from the quota left, and returns an empty string if
+// there is enough quota left. Otherwise nothing is deducted, and it returns the
+// description of the quotas exceeded.
+func (t *quotaTracker) reserve(requests corev1.ResourceList) string {
+	var exceeded []string
+	for _, q := range t.quotas {
+		for name, left := range q.available {
+			if req, ok := requests[quotaResources[name]]; ok && req.Cmp(left) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s of ResourceQuota %s (requested %s, available %s)", name, q.name, req.String(), left.String()))
+			}
+		}
+	}
+	if len(exceeded) > 0 {
+		sort.Strings(exceeded)
+		return strings.Join(exceeded, ", ")
+	}
+	for _, q := range t.quotas {
+		for name, left := range q.available {
+			if req, ok := requests[quotaResources[name]]; ok {
+				left.Sub(req)
+				q.available[name] = left
+			}
+		}
+	}
+	return ""
+}
+
+// taskRunRequests returns the CPU and memory the Pod of a TaskRun running ts
+// requests. The Steps run one after the other, so the Pod only requests the
+// most any Step requests, while the Sidecars run alongside the Steps.
+func taskRunRequests(ts *v1beta1.TaskSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	if ts == nil {
+		return requests
+	}
+	for _, s := range ts.Steps {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			req, ok := s.Resources.Requests[name]
+			if !ok && ts.StepTemplate != nil {
+				req, ok = ts.StepTemplate.Resources.Requests[name]
+			}
+			if ok && req.Cmp(requests[name]) > 0 {
+				requests[name] = req.DeepCopy()
+			}
+		}
+	}
+	for _, s := range ts.Sidecars {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if req, ok := s.Resources.Requests[name]; ok {
+				total := requests[name]
+				total.Add(req)
+				requests[name] = total
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestTaskRunRequests(t *testing.T) {
+	requests := func(cpu, memory string) corev1.ResourceRequirements {
+		r := corev1.ResourceRequirements{Requests: corev1.ResourceList{}}
+		if cpu != "" {
+			r.Requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			r.Requests[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return r
+	}
+	for _, tc := range []struct {
+		name       string
+		ts         *v1beta1.TaskSpec
+		wantCPU    string
+		wantMemory string
+	}{{
+		name: "no task spec",
+	}, {
+		name: "most any step requests",
+		ts: &v1beta1.TaskSpec{Steps: []v1beta1.Step{
+			{Container: corev1.Container{Resources: requests("1", "256Mi")}},
+			{Container: corev1.Container{Resources: requests("250m", "1Gi")}},
+			{Container: corev1.Container{}},
+		}},
+		wantCPU:    "1",
+		wantMemory: "1Gi",
+	}, {
+		name: "step template",
+		ts: &v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{Resources: requests("2", "")},
+			Steps: []v1beta1.Step{
+				{Container: corev1.Container{Resources: requests("", "512Mi")}},
+				{Container: corev1.Container{Resources: requests("500m", "")}},
+			},
+		},
+		wantCPU:    "2",
+		wantMemory: "512Mi",
+	}, {
+		name: "sidecars run alongside the steps",
+		ts: &v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Resources: requests("1", "")}}},
+			Sidecars: []v1beta1.Sidecar{
+				{Container: corev1.Container{Resources: requests("500m", "128Mi")}},
+				{Container: corev1.Container{Resources: requests("", "128Mi")}},
+			},
+		},
+		wantCPU:    "1500m",
+		wantMemory: "256Mi",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := taskRunRequests(tc.ts)
+			for name, want := range map[corev1.ResourceName]string{corev1.ResourceCPU: tc.wantCPU, corev1.ResourceMemory: tc.wantMemory} {
+				q, ok := got[name]
+				if want == "" {
+					if ok {
+						t.Errorf("Expected no %s request, got %s", name, q.String())
+					}
+					continue
+				}
+				if !ok || q.Cmp(resource.MustParse(want)) != 0 {
+					t.Errorf("Expected a %s request of %s, got %s", name, want, q.String())
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaTrackerReserve(t *testing.T) {
+	tracker := &quotaTracker{quotas: []namespaceQuota{{
+		name: "compute",
+		available: corev1.ResourceList{
+			corev1.ResourceRequestsCPU: resource.MustParse("2"),
+			corev1.ResourceMemory:      resource.MustParse("1Gi"),
+		},
+	}}}
+	cpu := func(q string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(q)}
+	}
+
+	if exceeded := tracker.reserve(cpu("1500m")); exceeded != "" {
+		t.Fatalf("Expected 1500m CPU to fit in the quota, got %s", exceeded)
+	}
+	want := "requests.cpu of ResourceQuota compute (requested 1, available 500m)"
+	if exceeded := tracker.reserve(cpu("1")); exceeded != want {
+		t.Errorf("Expected %q, got %q", want, exceeded)
+	}
+	if exceeded := tracker.reserve(cpu("500m")); exceeded != "" {
+		t.Errorf("Expected 500m CPU to fit in the quota left, got %s", exceeded)
+	}
+	if exceeded := tracker.reserve(corev1.ResourceList{}); exceeded != "" {
+		t.Errorf("Expected no requests to fit in the quota, got %s", exceeded)
+	}
+}
+
+func TestQuotaTrackerNeverFits(t *testing.T) {
+	tracker := &quotaTracker{quotas: []namespaceQuota{{
+		name: "compute",
+		hard: corev1.ResourceList{
+			corev1.ResourceRequestsCPU: resource.MustParse("4"),
+		},
+		available: corev1.ResourceList{
+			corev1.ResourceRequestsCPU: resource.MustParse("1"),
+		},
+	}}}
+	cpu := func(q string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(q)}
+	}
+
+	if exceeded := tracker.neverFits(cpu("2")); exceeded != "" {
+		t.Errorf("Expected 2 CPU to fit once the quota is freed, got %s", exceeded)
+	}
+	want := "requests.cpu of ResourceQuota compute (requested 8, hard limit 4)"
+	if exceeded := tracker.neverFits(cpu("8")); exceeded != want {
+		t.Errorf("Expected %q, got %q", want, exceeded)
+	}
+}
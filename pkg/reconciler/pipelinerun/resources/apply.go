@@ -18,14 +18,24 @@ package resources
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/dag"
+	"github.com/tektoncd/pipeline/pkg/substitution"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // ApplyParameters applies the params from a PipelineRun.Params to a PipelineSpec.
 func ApplyParameters(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) *v1beta1.PipelineSpec {
 	// This assumes that the PipelineRun inputs have been validated against what the Pipeline requests.
+	stringReplacements, arrayReplacements := paramReplacements(p, pr)
+	return ApplyReplacements(p, stringReplacements, arrayReplacements)
+}
 
+// paramReplacements returns the values of the params of pr, or of their defaults in p.
+func paramReplacements(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) (map[string]string, map[string][]string) {
 	// stringReplacements is used for standard single-string stringReplacements, while arrayReplacements contains arrays
 	// that need to be further processed.
 	stringReplacements := map[string]string{}
@@ -46,23 +56,56 @@ func ApplyParameters(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) *v1beta1.
 		if p.Value.Type == v1beta1.ParamTypeString {
 			stringReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.StringVal
 		} else {
-			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.ArrayVal
+			name := fmt.Sprintf("params.%s", p.Name)
+			if p.Append {
+				// The supplied values are appended to the default ones
+				arrayReplacements[name] = append(append([]string{}, arrayReplacements[name]...), p.Value.ArrayVal...)
+			} else {
+				arrayReplacements[name] = p.Value.ArrayVal
+			}
 		}
 	}
-
-	return ApplyReplacements(p, stringReplacements, arrayReplacements)
+	return stringReplacements, arrayReplacements
 }
 
 // ApplyContexts applies the substitution from $(context.(pipelineRun|pipeline).*) with the specified values.
 // Currently supports only name substitution. Uses "" as a default if name is not specified.
 func ApplyContexts(spec *v1beta1.PipelineSpec, pipelineName string, pr *v1beta1.PipelineRun) *v1beta1.PipelineSpec {
-	replacements := map[string]string{
+	return ApplyReplacements(spec, contextReplacements(pipelineName, pr), map[string][]string{})
+}
+
+func contextReplacements(pipelineName string, pr *v1beta1.PipelineRun) map[string]string {
+	return map[string]string{
 		"context.pipelineRun.name":      pr.Name,
 		"context.pipeline.name":         pipelineName,
 		"context.pipelineRun.namespace": pr.Namespace,
 		"context.pipelineRun.uid":       string(pr.ObjectMeta.UID),
 	}
-	return ApplyReplacements(spec, replacements, map[string][]string{})
+}
+
+// ApplyWorkspaceSubPaths returns the workspace bindings of pr with the substitution of its
+// params, with their defaults in p, and of $(context.(pipelineRun|pipeline).*) applied to
+// their subPath. The name of pr is appended to the subPath of the bindings setting
+// subPathPerRun, so that all the TaskRuns of pr use the same directory.
+func ApplyWorkspaceSubPaths(p *v1beta1.PipelineSpec, pipelineName string, pr *v1beta1.PipelineRun) []v1beta1.WorkspaceBinding {
+	if p == nil {
+		p = &v1beta1.PipelineSpec{}
+	}
+	replacements, _ := paramReplacements(p, pr)
+	for k, v := range contextReplacements(pipelineName, pr) {
+		replacements[k] = v
+	}
+	wb := make([]v1beta1.WorkspaceBinding, 0, len(pr.Spec.Workspaces))
+	for _, b := range pr.Spec.Workspaces {
+		b := *b.DeepCopy()
+		b.SubPath = substitution.ApplyReplacements(b.SubPath, replacements)
+		if b.SubPathPerRun {
+			b.SubPath = filepath.Join(b.SubPath, pr.Name)
+			b.SubPathPerRun = false
+		}
+		wb = append(wb, b)
+	}
+	return wb
 }
 
 // ApplyTaskResults applies the ResolvedResultRef to each PipelineTask.Params in targets
@@ -81,14 +124,110 @@ func ApplyTaskResults(targets PipelineRunState, resolvedResultRefs ResolvedResul
 			pipelineTaskCondition.Params = replaceParamValues(pipelineTaskCondition.Params, stringReplacements, nil)
 			resolvedConditionCheck.PipelineTaskCondition = pipelineTaskCondition
 		}
-		if resolvedPipelineRunTask.PipelineTask != nil {
-			pipelineTask := resolvedPipelineRunTask.PipelineTask.DeepCopy()
-			pipelineTask.Params = replaceParamValues(pipelineTask.Params, stringReplacements, nil)
+		replacePipelineTaskValues(resolvedPipelineRunTask, stringReplacements)
+	}
+}
+
+// ApplyPipelineTaskStateContext replaces the variables resolving to the execution status of the
+// pipeline tasks, $(tasks.<pipelineTask>.status) and $(tasks.status), in the params and the env
+// values of the embedded steps of the targets.
+func ApplyPipelineTaskStateContext(targets PipelineRunState, replacements map[string]string) {
+	for _, resolvedPipelineRunTask := range targets {
+		replacePipelineTaskValues(resolvedPipelineRunTask, replacements)
+	}
+}
+
+// ApplyFinalTaskWhenExpressions replaces the results and the execution status of the tasks of the graph d in
+// the when expressions of the final tasks of the graph dfinally which haven't run, once the tasks of d are
+// done, so that they can be evaluated. The when expressions using results which the tasks of d didn't produce
+// are left as is, since these final tasks are skipped.
+func ApplyFinalTaskWhenExpressions(state PipelineRunState, d *dag.Graph, dfinally *dag.Graph) {
+	if !state.checkTasksDone(d) {
+		return
+	}
+	statusReplacements := state.GetPipelineTaskStatus(d)
+	for _, resolvedPipelineRunTask := range state {
+		pipelineTask := resolvedPipelineRunTask.PipelineTask
+		if !isTaskInGraph(pipelineTask.Name, dfinally) || resolvedPipelineRunTask.isCreated() || len(pipelineTask.WhenExpressions) == 0 {
+			continue
+		}
+		resolvedResultRefs, err := convertWhenExpressions(pipelineTask.WhenExpressions, state, pipelineTask.Name)
+		if err != nil {
+			continue
+		}
+		replacements := map[string]string{}
+		for k, v := range statusReplacements {
+			replacements[k] = v
+		}
+		for _, resolvedResultRef := range resolvedResultRefs {
+			replaceTarget := fmt.Sprintf("%s.%s.%s.%s", v1beta1.ResultTaskPart, resolvedResultRef.ResultReference.PipelineTask, v1beta1.ResultResultPart, resolvedResultRef.ResultReference.Result)
+			replacements[replaceTarget] = resolvedResultRef.Value.StringVal
+		}
+		pipelineTask = pipelineTask.DeepCopy()
+		pipelineTask.WhenExpressions = pipelineTask.WhenExpressions.ReplaceWhenExpressionsVariables(replacements)
+		resolvedPipelineRunTask.PipelineTask = pipelineTask
+	}
+}
+
+// ApplyWhenExpressionsResults replaces the results of the tasks in the when expressions of the
+// PipelineTasks which haven't run, once the tasks producing them are done or skipped by their when
+// expressions with the Task scope, so that they can be evaluated. The when expressions using results
+// which aren't available yet are left as is; the PipelineTasks using them aren't evaluated until then.
+func ApplyWhenExpressionsResults(state PipelineRunState) {
+	// The when expressions of a task skipped alone can use the results of another one, so the
+	// replacement is repeated until none of the when expressions can be evaluated anymore.
+	stateMap := state.ToMap()
+	applied := sets.NewString()
+	for replaced := true; replaced; {
+		replaced = false
+		for _, resolvedPipelineRunTask := range state {
+			pipelineTask := resolvedPipelineRunTask.PipelineTask
+			if applied.Has(pipelineTask.Name) || resolvedPipelineRunTask.isCreated() || !resolvedPipelineRunTask.awaitsResults() {
+				continue
+			}
+			producersDone := true
+			for _, ref := range pipelineTaskWhenExpressionsResultRefs(pipelineTask) {
+				producer, ok := stateMap[ref.PipelineTask]
+				if !ok || !producer.IsDone() && !producer.skippedAlone() {
+					producersDone = false
+				}
+			}
+			if !producersDone {
+				continue
+			}
+			resolvedResultRefs, err := convertWhenExpressions(pipelineTask.WhenExpressions, state, pipelineTask.Name)
+			if err != nil {
+				continue
+			}
+			replacements := map[string]string{}
+			for _, resolvedResultRef := range resolvedResultRefs {
+				replaceTarget := fmt.Sprintf("%s.%s.%s.%s", v1beta1.ResultTaskPart, resolvedResultRef.ResultReference.PipelineTask, v1beta1.ResultResultPart, resolvedResultRef.ResultReference.Result)
+				replacements[replaceTarget] = resolvedResultRef.Value.StringVal
+			}
+			pipelineTask = pipelineTask.DeepCopy()
+			pipelineTask.WhenExpressions = pipelineTask.WhenExpressions.ReplaceWhenExpressionsVariables(replacements)
 			resolvedPipelineRunTask.PipelineTask = pipelineTask
+			applied.Insert(pipelineTask.Name)
+			replaced = true
 		}
 	}
 }
 
+func replacePipelineTaskValues(resolvedPipelineRunTask *ResolvedPipelineRunTask, stringReplacements map[string]string) {
+	if resolvedPipelineRunTask.PipelineTask != nil {
+		pipelineTask := resolvedPipelineRunTask.PipelineTask.DeepCopy()
+		pipelineTask.Params = replaceParamValues(pipelineTask.Params, stringReplacements, nil)
+		replaceStepEnvValues(pipelineTask.EmbeddedSteps(), stringReplacements)
+		resolvedPipelineRunTask.PipelineTask = pipelineTask
+	}
+	// the TaskRun is created from the resolved spec, so the embedded step envs need to be replaced there too
+	if rtr := resolvedPipelineRunTask.ResolvedTaskResources; rtr != nil && rtr.TaskName == "" && rtr.TaskSpec != nil {
+		taskSpec := rtr.TaskSpec.DeepCopy()
+		replaceStepEnvValues(taskSpec.Steps, stringReplacements)
+		rtr.TaskSpec = taskSpec
+	}
+}
+
 // ApplyReplacements replaces placeholders for declared parameters with the specified replacements.
 func ApplyReplacements(p *v1beta1.PipelineSpec, replacements map[string]string, arrayReplacements map[string][]string) *v1beta1.PipelineSpec {
 	p = p.DeepCopy()
@@ -102,11 +241,52 @@ func ApplyReplacements(p *v1beta1.PipelineSpec, replacements map[string]string,
 			c := tasks[i].Conditions[j]
 			c.Params = replaceParamValues(c.Params, replacements, arrayReplacements)
 		}
+		for j := range tasks[i].Workspaces {
+			tasks[i].Workspaces[j].ReplaceConditionVariables(replacements)
+		}
+		if tasks[i].TaskRef != nil {
+			tasks[i].TaskRef.Name = substitution.ApplyReplacements(tasks[i].TaskRef.Name, replacements)
+		}
 	}
 
+	replaceWhenExpressionsVariables(p, replacements)
+
 	return p
 }
 
+// ApplyWorkspaces replaces the $(workspaces.<name>.bound) variables in the when expressions of the
+// PipelineTasks with "true" if the workspace is bound by the PipelineRun and "false" otherwise.
+func ApplyWorkspaces(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) *v1beta1.PipelineSpec {
+	p = p.DeepCopy()
+	bound := sets.NewString()
+	for _, ws := range pr.Spec.Workspaces {
+		bound.Insert(ws.Name)
+	}
+	replacements := map[string]string{}
+	for _, ws := range p.Workspaces {
+		replacements[fmt.Sprintf("workspaces.%s.bound", ws.Name)] = strconv.FormatBool(bound.Has(ws.Name))
+	}
+	replaceWhenExpressionsVariables(p, replacements)
+	return p
+}
+
+func replaceWhenExpressionsVariables(p *v1beta1.PipelineSpec, replacements map[string]string) {
+	for i := range p.Tasks {
+		p.Tasks[i].WhenExpressions = p.Tasks[i].WhenExpressions.ReplaceWhenExpressionsVariables(replacements)
+	}
+	for i := range p.Finally {
+		p.Finally[i].WhenExpressions = p.Finally[i].WhenExpressions.ReplaceWhenExpressionsVariables(replacements)
+	}
+}
+
+func replaceStepEnvValues(steps []v1beta1.Step, stringReplacements map[string]string) {
+	for i := range steps {
+		for j := range steps[i].Env {
+			steps[i].Env[j].Value = substitution.ApplyReplacements(steps[i].Env[j].Value, stringReplacements)
+		}
+	}
+}
+
 func replaceParamValues(params []v1beta1.Param, stringReplacements map[string]string, arrayReplacements map[string][]string) []v1beta1.Param {
 	for i := range params {
 		params[i].Value.ApplyReplacements(stringReplacements, arrayReplacements)
@@ -21,13 +21,18 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 )
 
 func TestApplyParameters(t *testing.T) {
@@ -134,6 +139,34 @@ func TestApplyParameters(t *testing.T) {
 					tb.PipelineTaskParam("first-task-third-param", "static value"),
 					tb.PipelineTaskParam("first-task-fourth-param", "first", "fourth-value", "array"),
 				))),
+	}, {
+		name: "array parameter appended to the default",
+		original: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("first-param", v1beta1.ParamTypeArray, tb.ParamSpecDefault("default", "value")),
+				tb.PipelineParamSpec("second-param", v1beta1.ParamTypeArray),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskParam("first-task-first-param", "first", "$(params.first-param)"),
+					tb.PipelineTaskParam("first-task-second-param", "first", "$(params.second-param)"),
+				))),
+		run: func() *v1beta1.PipelineRun {
+			pr := tb.PipelineRun("test-pipeline-run",
+				tb.PipelineRunSpec("test-pipeline",
+					tb.PipelineRunParam("first-param", "supplied", "array"),
+					tb.PipelineRunParam("second-param", "second-value", "array")))
+			// Without a default, the supplied values are the whole value.
+			pr.Spec.Params[0].Append = true
+			pr.Spec.Params[1].Append = true
+			return pr
+		}(),
+		expected: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("first-param", v1beta1.ParamTypeArray, tb.ParamSpecDefault("default", "value")),
+				tb.PipelineParamSpec("second-param", v1beta1.ParamTypeArray),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskParam("first-task-first-param", "first", "default", "value", "supplied", "array"),
+					tb.PipelineTaskParam("first-task-second-param", "first", "second-value", "array"),
+				))),
 	}, {
 		name: "parameter evaluation with final tasks",
 		original: tb.Pipeline("test-pipeline",
@@ -155,6 +188,42 @@ func TestApplyParameters(t *testing.T) {
 					tb.PipelineTaskParam("final-task-first-param", "default-value"),
 					tb.PipelineTaskParam("final-task-second-param", "second-value"),
 				))),
+	}, {
+		name: "parameter in workspace binding condition",
+		original: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("cache-enabled", v1beta1.ParamTypeString, tb.ParamSpecDefault("false")),
+				tb.PipelineWorkspaceDeclaration("cache"),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskWorkspaceBindingCondition("cache", "cache", `$(params.cache-enabled) == "true"`),
+				))),
+		run: tb.PipelineRun("test-pipeline-run",
+			tb.PipelineRunSpec("test-pipeline",
+				tb.PipelineRunParam("cache-enabled", "true"))),
+		expected: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("cache-enabled", v1beta1.ParamTypeString, tb.ParamSpecDefault("false")),
+				tb.PipelineWorkspaceDeclaration("cache"),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskWorkspaceBindingCondition("cache", "cache", `"true" == "true"`),
+				))),
+	}, {
+		name: "parameter in task reference name",
+		original: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("language", v1beta1.ParamTypeString, tb.ParamSpecDefault("go")),
+				tb.PipelineTask("build", "build-$(params.language)"),
+				tb.FinalPipelineTask("report", "report-$(params.language)"),
+			)),
+		run: tb.PipelineRun("test-pipeline-run",
+			tb.PipelineRunSpec("test-pipeline",
+				tb.PipelineRunParam("language", "python"))),
+		expected: tb.Pipeline("test-pipeline",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("language", v1beta1.ParamTypeString, tb.ParamSpecDefault("go")),
+				tb.PipelineTask("build", "build-python"),
+				tb.FinalPipelineTask("report", "report-python"),
+			)),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -166,6 +235,88 @@ func TestApplyParameters(t *testing.T) {
 	}
 }
 
+func TestApplyWorkspaces(t *testing.T) {
+	original := tb.Pipeline("test-pipeline",
+		tb.PipelineSpec(
+			tb.PipelineWorkspaceDeclaration("cache", "source"),
+			tb.PipelineTask("restore-cache", "restore",
+				tb.PipelineTaskWhenExpression("$(workspaces.cache.bound)", selection.In, "true"),
+			),
+			tb.PipelineTask("fetch", "git-clone",
+				tb.PipelineTaskWhenExpression("$(workspaces.source.bound)", selection.NotIn, "$(workspaces.cache.bound)"),
+				tb.PipelineTaskParam("unchanged", "$(workspaces.source.bound)"),
+			),
+		))
+	run := tb.PipelineRun("test-pipeline-run",
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunWorkspaceBindingEmptyDir("source")))
+	expected := tb.Pipeline("test-pipeline",
+		tb.PipelineSpec(
+			tb.PipelineWorkspaceDeclaration("cache", "source"),
+			tb.PipelineTask("restore-cache", "restore",
+				tb.PipelineTaskWhenExpression("false", selection.In, "true"),
+			),
+			tb.PipelineTask("fetch", "git-clone",
+				tb.PipelineTaskWhenExpression("true", selection.NotIn, "false"),
+				tb.PipelineTaskParam("unchanged", "$(workspaces.source.bound)"),
+			),
+		))
+	got := ApplyWorkspaces(&original.Spec, run)
+	if d := cmp.Diff(&expected.Spec, got); d != "" {
+		t.Errorf("ApplyWorkspaces() got diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestApplyWorkspaceSubPaths(t *testing.T) {
+	ps := &v1beta1.PipelineSpec{
+		Params: []v1beta1.ParamSpec{{Name: "branch", Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "main"}}},
+	}
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-1", Namespace: "ci"},
+		Spec: v1beta1.PipelineRunSpec{
+			Params: []v1beta1.Param{{Name: "project", Value: v1beta1.NewArrayOrString("tekton")}},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:                  "source",
+				SubPath:               "$(params.project)/$(context.pipelineRun.name)",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "cache",
+				SubPath:               "$(context.pipeline.name)/$(params.branch)",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "output",
+				SubPath:               "$(context.pipelineRun.namespace)",
+				SubPathPerRun:         true,
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:     "scratch",
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+	}
+
+	want := []v1beta1.WorkspaceBinding{{
+		Name:                  "source",
+		SubPath:               "tekton/release-1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:                  "cache",
+		SubPath:               "release/main",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:                  "output",
+		SubPath:               "ci/release-1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:     "scratch",
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}}
+	got := ApplyWorkspaceSubPaths(ps, "release", pr)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ApplyWorkspaceSubPaths() got diff %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestApplyTaskResults_MinimalExpression(t *testing.T) {
 	type args struct {
 		targets            PipelineRunState
@@ -406,6 +557,117 @@ func TestApplyTaskResults_Conditions(t *testing.T) {
 	}
 }
 
+func TestApplyTaskResults_StepEnv(t *testing.T) {
+	resolvedResultRefs := ResolvedResultRefs{{
+		Value: v1beta1.ArrayOrString{
+			Type:      v1beta1.ParamTypeString,
+			StringVal: "aResultValue",
+		},
+		ResultReference: v1beta1.ResultRef{
+			PipelineTask: "aTask",
+			Result:       "aResult",
+		},
+		FromTaskRun: "aTaskRun",
+	}}
+	taskSpec := func(value string) *v1beta1.TaskSpec {
+		return &v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:  "step",
+				Image: "busybox",
+				Env:   []corev1.EnvVar{{Name: "A_RESULT", Value: value}},
+			}}},
+		}
+	}
+	targets := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:     "bTask",
+			TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: taskSpec("Result value --> $(tasks.aTask.results.aResult)")},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: taskSpec("Result value --> $(tasks.aTask.results.aResult)"),
+		},
+	}}
+	want := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:     "bTask",
+			TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: taskSpec("Result value --> aResultValue")},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: taskSpec("Result value --> aResultValue"),
+		},
+	}}
+	ApplyTaskResults(targets, resolvedResultRefs)
+	if d := cmp.Diff(want, targets); d != "" {
+		t.Fatalf("ApplyTaskResults() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestApplyWhenExpressionsResults(t *testing.T) {
+	producer := func(status corev1.ConditionStatus) *ResolvedPipelineRunTask {
+		return &ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{Name: "aTask", TaskRef: &v1beta1.TaskRef{Name: "aTask"}},
+			TaskRunName:  "aTaskRun",
+			TaskRun: &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "aTaskRun"},
+				Status: v1beta1.TaskRunStatus{
+					Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+						Type:   apis.ConditionSucceeded,
+						Status: status,
+					}}},
+					TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+						TaskRunResults: []v1beta1.TaskRunResult{{Name: "aResult", Value: "aResultValue"}},
+					},
+				},
+			},
+		}
+	}
+	consumer := func(input string, values ...string) *ResolvedPipelineRunTask {
+		return &ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "bTask",
+				TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    input,
+					Operator: selection.In,
+					Values:   values,
+				}},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name  string
+		state PipelineRunState
+		want  *ResolvedPipelineRunTask
+	}{{
+		name:  "result in values",
+		state: PipelineRunState{producer(corev1.ConditionTrue), consumer("aResultValue", "$(tasks.aTask.results.aResult)", "other")},
+		want:  consumer("aResultValue", "aResultValue", "other"),
+	}, {
+		name:  "result in input",
+		state: PipelineRunState{producer(corev1.ConditionTrue), consumer("$(tasks.aTask.results.aResult)", "aResultValue")},
+		want:  consumer("aResultValue", "aResultValue"),
+	}, {
+		name:  "embedded result",
+		state: PipelineRunState{producer(corev1.ConditionTrue), consumer("v1", "v$(tasks.aTask.results.aResult)")},
+		want:  consumer("v1", "vaResultValue"),
+	}, {
+		name:  "producer still running",
+		state: PipelineRunState{producer(corev1.ConditionUnknown), consumer("aResultValue", "$(tasks.aTask.results.aResult)")},
+		want:  consumer("aResultValue", "$(tasks.aTask.results.aResult)"),
+	}, {
+		name:  "missing result",
+		state: PipelineRunState{producer(corev1.ConditionTrue), consumer("aResultValue", "$(tasks.aTask.results.missing)")},
+		want:  consumer("aResultValue", "$(tasks.aTask.results.missing)"),
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ApplyWhenExpressionsResults(tt.state)
+			if d := cmp.Diff(tt.want, tt.state[1]); d != "" {
+				t.Fatalf("ApplyWhenExpressionsResults() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestContext(t *testing.T) {
 	for _, tc := range []struct {
 		description string
@@ -21,19 +21,22 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/contexts"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	"github.com/tektoncd/pipeline/pkg/list"
 	"github.com/tektoncd/pipeline/pkg/names"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 )
 
@@ -41,6 +44,10 @@ const (
 	// ReasonConditionCheckFailed indicates that the reason for the failure status is that the
 	// condition check associated to the pipeline task evaluated to false
 	ReasonConditionCheckFailed = "ConditionCheckFailed"
+
+	// PipelineTaskStateNone is the execution status of a pipeline task which wasn't run, and the
+	// aggregate execution status of the tasks of a Pipeline which aren't all done
+	PipelineTaskStateNone = "None"
 )
 
 // TaskNotFoundError indicates that the resolution failed because a referenced Task couldn't be retrieved
@@ -63,15 +70,73 @@ func (e *ConditionNotFoundError) Error() string {
 	return fmt.Sprintf("Couldn't retrieve Condition %q: %s", e.Name, e.Msg)
 }
 
+// ReferencesNotFoundError indicates that the resolution failed because some of the Tasks,
+// ClusterTasks or Conditions referenced by the PipelineTasks couldn't be retrieved. It lists
+// all of them, rather than only the first one.
+type ReferencesNotFoundError struct {
+	Tasks      []*TaskNotFoundError
+	Conditions []*ConditionNotFoundError
+	// retrievalFailed is true if some of the references couldn't be retrieved for another
+	// reason than not existing.
+	retrievalFailed bool
+}
+
+func (e *ReferencesNotFoundError) Error() string {
+	var msgs []string
+	for _, t := range e.Tasks {
+		msgs = append(msgs, t.Error())
+	}
+	for _, c := range e.Conditions {
+		msgs = append(msgs, c.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllNotFound returns true if all the references couldn't be retrieved because they don't
+// exist, in which case they may still be created.
+func (e *ReferencesNotFoundError) AllNotFound() bool {
+	return !e.retrievalFailed
+}
+
+func (e *ReferencesNotFoundError) addTask(name string, err error) {
+	e.Tasks = append(e.Tasks, &TaskNotFoundError{Name: name, Msg: err.Error()})
+	e.retrievalFailed = e.retrievalFailed || !errors.IsNotFound(err)
+}
+
+func (e *ReferencesNotFoundError) addConditions(pt v1beta1.PipelineTask, getCondition GetCondition) {
+	for _, ptc := range pt.Conditions {
+		if _, err := getCondition(ptc.ConditionRef); err != nil {
+			e.Conditions = append(e.Conditions, &ConditionNotFoundError{Name: ptc.ConditionRef, Msg: err.Error()})
+			e.retrievalFailed = e.retrievalFailed || !errors.IsNotFound(err)
+		}
+	}
+}
+
+func (e *ReferencesNotFoundError) empty() bool {
+	return len(e.Tasks) == 0 && len(e.Conditions) == 0
+}
+
 // ResolvedPipelineRunTask contains a Task and its associated TaskRun, if it
 // exists. TaskRun can be nil to represent there being no TaskRun.
+// The PipelineTasks referencing custom tasks are run through a Run instead,
+// which can similarly be nil.
 type ResolvedPipelineRunTask struct {
 	TaskRunName           string
 	TaskRun               *v1beta1.TaskRun
+	CustomTask            bool
+	RunName               string
+	Run                   *v1alpha1.Run
 	PipelineTask          *v1beta1.PipelineTask
 	ResolvedTaskResources *resources.ResolvedTaskResources
 	// ConditionChecks ~~TaskRuns but for evaling conditions
 	ResolvedConditionChecks TaskConditionCheckState // Could also be a TaskRun or maybe just a Pod?
+	// UnboundWorkspaces are the optional Pipeline workspaces used by the PipelineTask
+	// which aren't bound by the PipelineRun
+	UnboundWorkspaces []string
+	// CompleteAll is true when the PipelineRun keeps scheduling the PipelineTasks
+	// whose parents succeeded after the PipelineTask failed, with the CompleteAll
+	// failure policy
+	CompleteAll bool
 }
 
 // PipelineRunState is a slice of ResolvedPipelineRunTasks the represents the current execution
@@ -79,6 +144,10 @@ type ResolvedPipelineRunTask struct {
 type PipelineRunState []*ResolvedPipelineRunTask
 
 func (t ResolvedPipelineRunTask) IsDone() bool {
+	if t.CustomTask {
+		// The retries of Runs are handled by the custom task controllers.
+		return t.Run != nil && t.Run.IsDone()
+	}
 	if t.TaskRun == nil || t.PipelineTask == nil {
 		return false
 	}
@@ -91,6 +160,9 @@ func (t ResolvedPipelineRunTask) IsDone() bool {
 
 // IsSuccessful returns true only if the taskrun itself has completed successfully
 func (t ResolvedPipelineRunTask) IsSuccessful() bool {
+	if t.CustomTask {
+		return t.Run != nil && t.Run.IsSuccessful()
+	}
 	if t.TaskRun == nil {
 		return false
 	}
@@ -104,6 +176,9 @@ func (t ResolvedPipelineRunTask) IsSuccessful() bool {
 
 // IsFailure returns true only if the taskrun itself has failed
 func (t ResolvedPipelineRunTask) IsFailure() bool {
+	if t.CustomTask {
+		return t.Run != nil && t.Run.Status.GetCondition(apis.ConditionSucceeded).IsFalse()
+	}
 	if t.TaskRun == nil {
 		return false
 	}
@@ -113,8 +188,22 @@ func (t ResolvedPipelineRunTask) IsFailure() bool {
 	return c.IsFalse() && retriesDone >= retries
 }
 
+// IsNonFatalFailure returns true only if the taskrun itself has failed but its
+// PipelineTask allows the PipelineRun to continue on failure, or is a final task
+// whose failure is ignored
+func (t ResolvedPipelineRunTask) IsNonFatalFailure() bool {
+	return t.IsFailure() && (t.PipelineTask.ContinueOnFailure || t.PipelineTask.FailurePolicy == v1beta1.PipelineTaskFailurePolicyIgnore)
+}
+
 // IsCancelled returns true only if the taskrun itself has cancelled
 func (t ResolvedPipelineRunTask) IsCancelled() bool {
+	if t.CustomTask {
+		if t.Run == nil {
+			return false
+		}
+		c := t.Run.Status.GetCondition(apis.ConditionSucceeded)
+		return c != nil && c.IsFalse() && c.Reason == v1alpha1.RunReasonCancelled
+	}
 	if t.TaskRun == nil {
 		return false
 	}
@@ -129,6 +218,9 @@ func (t ResolvedPipelineRunTask) IsCancelled() bool {
 
 // IsStarted returns true only if the PipelineRunTask itself has a TaskRun associated
 func (t ResolvedPipelineRunTask) IsStarted() bool {
+	if t.CustomTask {
+		return t.Run != nil && t.Run.Status.GetCondition(apis.ConditionSucceeded) != nil
+	}
 	if t.TaskRun == nil {
 		return false
 	}
@@ -143,39 +235,251 @@ func (t ResolvedPipelineRunTask) IsStarted() bool {
 
 // IsSkipped returns true if a PipelineTask will not be run because
 // (1) its Condition Checks failed or
-// (2) one of the parent task's conditions failed or
-// (3) Pipeline is in stopping state (one of the PipelineTasks failed)
+// (2) its When Expressions evaluated to false or
+// (3) it uses an optional workspace which isn't bound or
+// (4) one of the parent task's conditions failed or
+// (5) Pipeline is in stopping state (one of the PipelineTasks failed) or
+// (6) it uses a result without default of a parent task skipped by its When
+// Expressions with the Task scope, or which failed with continueOnFailure or
+// (7) one of the parent tasks failed while the PipelineRun completes the other tasks
 // Note that this means IsSkipped returns false if a conditionCheck is in progress
 func (t ResolvedPipelineRunTask) IsSkipped(state PipelineRunState, d *dag.Graph) bool {
+	return t.SkippingReason(state, d) != ""
+}
+
+// SkippingReason returns the reason why a PipelineTask will not be run, as
+// described by IsSkipped, or an empty reason if it isn't skipped.
+func (t ResolvedPipelineRunTask) SkippingReason(state PipelineRunState, d *dag.Graph) v1beta1.SkippingReason {
 	// it already has TaskRun associated with it - PipelineTask not skipped
 	if t.IsStarted() {
-		return false
+		return ""
 	}
 
 	// Check if conditionChecks have failed, if so task is skipped
 	if len(t.ResolvedConditionChecks) > 0 {
 		if t.ResolvedConditionChecks.IsDone() && !t.ResolvedConditionChecks.IsSuccess() {
-			return true
+			return v1beta1.ConditionCheckSkip
 		}
 	}
 
+	// Skip the PipelineTask if one of its when expressions evaluated to false, unless they are
+	// still waiting for the results or the execution status of the tasks to be resolved
+	if !t.PipelineTask.WhenExpressions.AllowsExecution() && !t.awaitsExecutionStatus() && !t.awaitsResults() {
+		return v1beta1.WhenExpressionsSkip
+	}
+
+	// Skip the PipelineTask if one of the optional workspaces it uses isn't bound
+	if len(t.UnboundWorkspaces) > 0 {
+		return v1beta1.MissingResultsOrWorkspaceSkip
+	}
+
 	// Skip the PipelineTask if pipeline is in stopping state
 	if isTaskInGraph(t.PipelineTask.Name, d) && state.IsStopping(d) {
-		return true
+		return v1beta1.StoppingSkip
 	}
 
+	if !isTaskInGraph(t.PipelineTask.Name, d) {
+		return ""
+	}
 	stateMap := state.ToMap()
 	// Recursively look at parent tasks to see if they have been skipped,
-	// if any of the parents have been skipped, skip as well
-	node := d.Nodes[t.PipelineTask.Name]
-	if isTaskInGraph(t.PipelineTask.Name, d) {
-		for _, p := range node.Prev {
-			if stateMap[p.Task.HashKey()].IsSkipped(state, d) {
+	// if any of the parents have been skipped along with its dependents,
+	// skip as well
+	for _, p := range d.Nodes[t.PipelineTask.Name].Prev {
+		parent := stateMap[p.Task.HashKey()]
+		if parent.skipsDependents(state, d) {
+			return v1beta1.ParentTasksSkip
+		}
+		// The PipelineRun isn't stopping because the parent failed, so its
+		// dependents have to be skipped for the PipelineRun to complete
+		if parent.IsFailure() && !parent.IsNonFatalFailure() {
+			return v1beta1.ParentTasksFailedSkip
+		}
+	}
+	// The parents skipped alone, or which failed with continueOnFailure, only
+	// provide the default values of their results
+	for _, ref := range pipelineTaskResultRefs(t.PipelineTask) {
+		parent, ok := stateMap[ref.PipelineTask]
+		if !ok || !(parent.IsSkipped(state, d) || parent.IsNonFatalFailure()) {
+			continue
+		}
+		if _, ok := parent.resultDefault(ref.Result); !ok {
+			return v1beta1.MissingResultsOrWorkspaceSkip
+		}
+	}
+	return ""
+}
+
+// skipsDependents returns true if the PipelineTasks depending on t are skipped
+// because t is skipped, which is the case unless t is only skipped by its When
+// Expressions with the Task scope.
+func (t ResolvedPipelineRunTask) skipsDependents(state PipelineRunState, d *dag.Graph) bool {
+	switch t.SkippingReason(state, d) {
+	case "":
+		return false
+	case v1beta1.WhenExpressionsSkip:
+		if t.PipelineTask.WhenScope != v1beta1.WhenScopeTask {
+			return true
+		}
+		// The When Expressions are evaluated before looking at the parents, which
+		// may have skipped the branch of t anyway.
+		stateMap := state.ToMap()
+		for _, p := range d.Nodes[t.PipelineTask.Name].Prev {
+			if stateMap[p.Task.HashKey()].skipsDependents(state, d) {
 				return true
 			}
 		}
+		return false
+	default:
+		return true
 	}
-	return false
+}
+
+// awaitsExecutionStatus returns true if the when expressions of t still use the execution status
+// of the tasks, which only final tasks can use and which is only replaced once the tasks are done.
+func (t ResolvedPipelineRunTask) awaitsExecutionStatus() bool {
+	expressions, ok := v1beta1.GetVarSubstitutionExpressionsForWhenExpressions(t.PipelineTask.WhenExpressions)
+	return ok && v1beta1.UsesExecutionStatus(expressions)
+}
+
+// awaitsResults returns true if the when expressions of t still use the results of the tasks,
+// which are only replaced once the tasks producing them are done.
+func (t ResolvedPipelineRunTask) awaitsResults() bool {
+	expressions, ok := v1beta1.GetVarSubstitutionExpressionsForWhenExpressions(t.PipelineTask.WhenExpressions)
+	return ok && v1beta1.LooksLikeContainsResultRefs(expressions)
+}
+
+// skippedAlone returns true if t wasn't run because its When Expressions with the
+// Task scope evaluated to false.
+func (t ResolvedPipelineRunTask) skippedAlone() bool {
+	return !t.isCreated() && t.PipelineTask.WhenScope == v1beta1.WhenScopeTask && !t.PipelineTask.WhenExpressions.AllowsExecution() && !t.awaitsResults()
+}
+
+// isCreated returns true if the TaskRun of t, or its Run for a custom task, has
+// been created.
+func (t ResolvedPipelineRunTask) isCreated() bool {
+	return t.TaskRun != nil || t.Run != nil
+}
+
+// resultDefault returns the default value of the result of the Task of t called
+// name, or having name as an alias.
+func (t ResolvedPipelineRunTask) resultDefault(name string) (string, bool) {
+	if t.ResolvedTaskResources == nil || t.ResolvedTaskResources.TaskSpec == nil {
+		return "", false
+	}
+	for _, r := range t.ResolvedTaskResources.TaskSpec.Results {
+		if r.Default == nil {
+			continue
+		}
+		if r.Name == name {
+			return *r.Default, true
+		}
+		for _, alias := range r.Aliases {
+			if alias == name {
+				return *r.Default, true
+			}
+		}
+	}
+	return "", false
+}
+
+// GetSkippedTasks returns the PipelineTasks of the specified graph which were
+// skipped, with the reason why they were skipped.
+func (state PipelineRunState) GetSkippedTasks(d *dag.Graph) []v1beta1.SkippedTask {
+	var skipped []v1beta1.SkippedTask
+	for _, t := range state {
+		if !isTaskInGraph(t.PipelineTask.Name, d) {
+			continue
+		}
+		if reason := t.SkippingReason(state, d); reason != "" {
+			skipped = append(skipped, v1beta1.SkippedTask{
+				Name:   t.PipelineTask.Name,
+				Reason: reason,
+			})
+		}
+	}
+	return skipped
+}
+
+// GetSkippedFinalTasks returns the final tasks of the graph dfinally which were skipped, with the
+// reason why they were skipped, including the final tasks using results which the tasks of the
+// graph d didn't produce. The final tasks skipped by their when expressions are only returned
+// once the tasks of d are done, as their when expressions are evaluated then.
+func (state PipelineRunState) GetSkippedFinalTasks(d *dag.Graph, dfinally *dag.Graph) []v1beta1.SkippedTask {
+	var skipped []v1beta1.SkippedTask
+	for _, t := range state {
+		if !isTaskInGraph(t.PipelineTask.Name, dfinally) {
+			continue
+		}
+		reason := t.SkippingReason(state, dfinally)
+		switch {
+		case (reason == "" || reason == v1beta1.WhenExpressionsSkip) && state.missesFinalTaskResults(t, d, dfinally):
+			reason = v1beta1.MissingResultsOrWorkspaceSkip
+		case reason == v1beta1.WhenExpressionsSkip:
+			if !state.checkTasksDone(d) {
+				continue
+			}
+			reason = v1beta1.FinallyWhenExpressionsSkip
+		}
+		if reason != "" {
+			skipped = append(skipped, v1beta1.SkippedTask{
+				Name:   t.PipelineTask.Name,
+				Reason: reason,
+			})
+		}
+	}
+	return skipped
+}
+
+// missesFinalTaskResults returns true if t is a final task of the graph dfinally which won't be run
+// because it uses results which the tasks of the graph d didn't produce, because they failed or
+// were skipped.
+func (state PipelineRunState) missesFinalTaskResults(t *ResolvedPipelineRunTask, d *dag.Graph, dfinally *dag.Graph) bool {
+	if !isTaskInGraph(t.PipelineTask.Name, dfinally) || t.isCreated() || !state.checkTasksDone(d) {
+		return false
+	}
+	_, err := convertParamsToResultRefs(state, t)
+	return err != nil
+}
+
+// GetPipelineTaskStatus returns the values of the variables resolving to the execution status of the
+// tasks of the graph d, $(tasks.<pipelineTask>.status), and to their aggregate execution status,
+// $(tasks.status), keyed by variable. They are only meaningful once the tasks of d are done.
+func (state PipelineRunState) GetPipelineTaskStatus(d *dag.Graph) map[string]string {
+	replacements := map[string]string{}
+	var failed, completed, incomplete bool
+	for _, t := range state {
+		if !isTaskInGraph(t.PipelineTask.Name, d) {
+			continue
+		}
+		status := PipelineTaskStateNone
+		switch {
+		case t.IsSuccessful():
+			status = v1beta1.TaskRunReasonSuccessful.String()
+		case t.IsNonFatalFailure():
+			status = v1beta1.TaskRunReasonFailed.String()
+			completed = true
+		case t.IsFailure():
+			status = v1beta1.TaskRunReasonFailed.String()
+			failed = true
+		case t.IsSkipped(state, d):
+			completed = true
+		default:
+			incomplete = true
+		}
+		replacements[fmt.Sprintf("%s.%s.%s", v1beta1.ResultTaskPart, t.PipelineTask.Name, v1beta1.PipelineTaskStatusSuffix)] = status
+	}
+	switch {
+	case failed:
+		replacements[v1beta1.PipelineTasksAggregateStatus] = v1beta1.PipelineRunReasonFailed.String()
+	case incomplete:
+		replacements[v1beta1.PipelineTasksAggregateStatus] = PipelineTaskStateNone
+	case completed:
+		replacements[v1beta1.PipelineTasksAggregateStatus] = v1beta1.PipelineRunReasonCompleted.String()
+	default:
+		replacements[v1beta1.PipelineTasksAggregateStatus] = v1beta1.PipelineRunReasonSuccessful.String()
+	}
+	return replacements
 }
 
 // ToMap returns a map that maps pipeline task name to the resolved pipeline run task
@@ -201,7 +505,7 @@ func (state PipelineRunState) IsDone() bool {
 // IsBeforeFirstTaskRun returns true if the PipelineRun has not yet started its first TaskRun
 func (state PipelineRunState) IsBeforeFirstTaskRun() bool {
 	for _, t := range state {
-		if t.TaskRun != nil {
+		if t.isCreated() {
 			return false
 		}
 	}
@@ -209,14 +513,15 @@ func (state PipelineRunState) IsBeforeFirstTaskRun() bool {
 }
 
 // IsStopping returns true if the PipelineRun won't be scheduling any new Task because
-// at least one task already failed or was cancelled in the specified dag
+// at least one task already failed (and isn't allowed to continue on failure, nor
+// completes the other tasks) or was cancelled in the specified dag
 func (state PipelineRunState) IsStopping(d *dag.Graph) bool {
 	for _, t := range state {
 		if isTaskInGraph(t.PipelineTask.Name, d) {
 			if t.IsCancelled() {
 				return true
 			}
-			if t.IsFailure() {
+			if t.IsFailure() && !t.IsNonFatalFailure() && !t.CompleteAll {
 				return true
 			}
 		}
@@ -230,7 +535,7 @@ func (state PipelineRunState) IsStopping(d *dag.Graph) bool {
 func (state PipelineRunState) GetNextTasks(candidateTasks sets.String) []*ResolvedPipelineRunTask {
 	tasks := []*ResolvedPipelineRunTask{}
 	for _, t := range state {
-		if _, ok := candidateTasks[t.PipelineTask.Name]; ok && t.TaskRun == nil {
+		if _, ok := candidateTasks[t.PipelineTask.Name]; ok && !t.isCreated() {
 			tasks = append(tasks, t)
 		}
 		if _, ok := candidateTasks[t.PipelineTask.Name]; ok && t.TaskRun != nil {
@@ -248,12 +553,15 @@ func (state PipelineRunState) GetNextTasks(candidateTasks sets.String) []*Resolv
 }
 
 // SuccessfulOrSkippedDAGTasks returns a list of the names of all of the PipelineTasks in state
-// which have successfully completed or skipped
+// which have successfully completed or skipped. Tasks which failed but are allowed to continue
+// on failure are considered successful, so that the tasks depending on them are scheduled.
+// Tasks which failed while the PipelineRun completes all tasks are returned as well, as the
+// tasks depending on them are skipped.
 func (state PipelineRunState) SuccessfulOrSkippedDAGTasks(d *dag.Graph) []string {
 	tasks := []string{}
 	for _, t := range state {
 		if isTaskInGraph(t.PipelineTask.Name, d) {
-			if t.IsSuccessful() || t.IsSkipped(state, d) {
+			if t.IsSuccessful() || t.IsNonFatalFailure() || (t.IsFailure() && t.CompleteAll) || t.IsSkipped(state, d) {
 				tasks = append(tasks, t.PipelineTask.Name)
 			}
 		}
@@ -266,7 +574,7 @@ func (state PipelineRunState) SuccessfulOrSkippedDAGTasks(d *dag.Graph) []string
 func (state PipelineRunState) checkTasksDone(d *dag.Graph) bool {
 	for _, t := range state {
 		if isTaskInGraph(t.PipelineTask.Name, d) {
-			if t.TaskRun == nil {
+			if !t.isCreated() {
 				// this task might have skipped if taskRun is nil
 				// continue and ignore if this task was skipped
 				// skipped task is considered part of done
@@ -285,7 +593,8 @@ func (state PipelineRunState) checkTasksDone(d *dag.Graph) bool {
 
 // GetFinalTasks returns a list of final tasks without any taskRun associated with it
 // GetFinalTasks returns final tasks only when all DAG tasks have finished executing successfully or skipped or
-// any one DAG task resulted in failure
+// any one DAG task resulted in failure. The final tasks using results which the DAG tasks didn't produce,
+// and the ones whose when expressions evaluated to false, are not returned.
 func (state PipelineRunState) GetFinalTasks(d *dag.Graph, dfinally *dag.Graph) []*ResolvedPipelineRunTask {
 	tasks := []*ResolvedPipelineRunTask{}
 	finalCandidates := sets.NewString()
@@ -294,7 +603,8 @@ func (state PipelineRunState) GetFinalTasks(d *dag.Graph, dfinally *dag.Graph) [
 	if state.checkTasksDone(d) {
 		// return list of tasks with all final tasks
 		for _, t := range state {
-			if isTaskInGraph(t.PipelineTask.Name, dfinally) && !t.IsSuccessful() {
+			if isTaskInGraph(t.PipelineTask.Name, dfinally) && !t.IsSuccessful() && len(t.UnboundWorkspaces) == 0 &&
+				t.PipelineTask.WhenExpressions.AllowsExecution() && !state.missesFinalTaskResults(t, d, dfinally) {
 				finalCandidates.Insert(t.PipelineTask.Name)
 			}
 		}
@@ -314,6 +624,9 @@ func isTaskInGraph(pipelineTaskName string, d *dag.Graph) bool {
 // GetTaskRun is a function that will retrieve the TaskRun name.
 type GetTaskRun func(name string) (*v1beta1.TaskRun, error)
 
+// GetRun is a function that will retrieve the Run name.
+type GetRun func(name string) (*v1alpha1.Run, error)
+
 // GetResourcesFromBindings will retrieve all Resources bound in PipelineRun pr and return a map
 // from the declared name of the PipelineResource (which is how the PipelineResource will
 // be referred to in the PipelineRun) to the PipelineResource, obtained via getResource.
@@ -367,6 +680,9 @@ func ValidateWorkspaceBindings(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun)
 	}
 
 	for _, ws := range p.Workspaces {
+		if ws.Optional {
+			continue
+		}
 		if _, ok := pipelineRunWorkspaces[ws.Name]; !ok {
 			return fmt.Errorf("pipeline expects workspace with name %q be provided by pipelinerun", ws.Name)
 		}
@@ -374,6 +690,34 @@ func ValidateWorkspaceBindings(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun)
 	return nil
 }
 
+// ValidateReadOnlyWorkspaces validates that the Workspaces the Tasks of state declare
+// read-only are bound by the PipelineRun to read-only volumes. ConfigMaps and Secrets
+// are always mounted read-only, so they don't need to set readOnly.
+func ValidateReadOnlyWorkspaces(state PipelineRunState, pr *v1beta1.PipelineRun) error {
+	pipelineRunWorkspaces := make(map[string]v1beta1.WorkspaceBinding)
+	for _, binding := range pr.Spec.Workspaces {
+		pipelineRunWorkspaces[binding.Name] = binding
+	}
+
+	for _, rprt := range state {
+		if rprt.CustomTask || rprt.ResolvedTaskResources == nil || rprt.ResolvedTaskResources.TaskSpec == nil {
+			continue
+		}
+		readOnly := map[string]bool{}
+		for _, w := range rprt.ResolvedTaskResources.TaskSpec.Workspaces {
+			readOnly[w.Name] = w.ReadOnly
+		}
+		for _, ws := range rprt.PipelineTask.Workspaces {
+			b, ok := pipelineRunWorkspaces[ws.Workspace]
+			if !ok || !readOnly[ws.Name] || b.ReadOnly || b.ConfigMap != nil || b.Secret != nil {
+				continue
+			}
+			return fmt.Errorf("workspace %q of pipeline task %q is read-only, but workspace %q isn't bound with readOnly", ws.Name, rprt.PipelineTask.Name, ws.Workspace)
+		}
+	}
+	return nil
+}
+
 // ValidateTaskRunSpecs that the TaskRunSpecs defined by a PipelineRun are correct.
 func ValidateTaskRunSpecs(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) error {
 	pipelineTasks := make(map[string]string)
@@ -389,6 +733,38 @@ func ValidateTaskRunSpecs(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) erro
 	return nil
 }
 
+// ValidateNetworkPolicies validates that the network policies of a PipelineRun reference
+// PipelineTasks of its Pipeline.
+func ValidateNetworkPolicies(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) error {
+	pipelineTasks := sets.NewString()
+	for _, task := range append(p.Tasks, p.Finally...) {
+		pipelineTasks.Insert(task.Name)
+	}
+
+	for _, rule := range pr.Spec.NetworkPolicies {
+		for _, name := range append([]string{rule.To}, rule.From...) {
+			if !pipelineTasks.Has(name) {
+				return fmt.Errorf("PipelineRun's network policy %q references the task %q, which does not exist in Pipeline", rule.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTaskRefNames validates that the Tasks referenced by the PipelineTasks of p
+// have valid names, once the params their names may reference are substituted.
+func ValidateTaskRefNames(p *v1beta1.PipelineSpec) error {
+	for _, task := range append(append([]v1beta1.PipelineTask{}, p.Tasks...), p.Finally...) {
+		if task.TaskRef == nil || task.TaskRef.Name == "" || task.IsCustomTask() {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(task.TaskRef.Name); len(errs) != 0 {
+			return fmt.Errorf("pipeline task %q references Task %q, which isn't a valid name: %s", task.Name, task.TaskRef.Name, strings.Join(errs, ","))
+		}
+	}
+	return nil
+}
+
 // ValidateServiceaccountMapping validates that the ServiceAccountNames defined by a PipelineRun are not correct.
 func ValidateServiceaccountMapping(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) error {
 	pipelineTasks := make(map[string]string)
@@ -405,27 +781,68 @@ func ValidateServiceaccountMapping(p *v1beta1.PipelineSpec, pr *v1beta1.Pipeline
 }
 
 // ResolvePipelineRun retrieves all Tasks instances which are reference by tasks, getting
-// instances from getTask. If it is unable to retrieve an instance of a referenced Task, it
-// will return an error, otherwise it returns a list of all of the Tasks retrieved.
+// instances from getTask, or from getBundleTask for the ones stored in bundles. If it is
+// unable to retrieve an instance of a referenced Task, it will return an error, otherwise
+// it returns a list of all of the Tasks retrieved.
 // It will retrieve the Resources needed for the TaskRun using the mapping of providedResources.
+// The tasks referencing custom tasks aren't retrieved, only their Runs are, from getRun.
 func ResolvePipelineRun(
 	ctx context.Context,
 	pipelineRun v1beta1.PipelineRun,
 	getTask resources.GetTask,
 	getTaskRun resources.GetTaskRun,
+	getRun GetRun,
 	getClusterTask resources.GetClusterTask,
+	getBundleTask resources.GetBundleTask,
 	getCondition GetCondition,
 	tasks []v1beta1.PipelineTask,
 	providedResources map[string]*resourcev1alpha1.PipelineResource,
 ) (PipelineRunState, error) {
 
+	boundWorkspaces := sets.NewString()
+	for _, ws := range pipelineRun.Spec.Workspaces {
+		boundWorkspaces.Insert(ws.Name)
+	}
+	completeAll := pipelineRun.Spec.FailurePolicy == v1beta1.PipelineRunFailurePolicyCompleteAll
+
+	// The Tasks and Conditions which can't be retrieved are all reported at once,
+	// the PipelineTasks are no longer resolved once one of them is found.
+	missing := &ReferencesNotFoundError{}
 	state := []*ResolvedPipelineRunTask{}
 	for i := range tasks {
 		pt := tasks[i]
 
+		if pt.IsCustomTask() {
+			rprt := ResolvedPipelineRunTask{
+				PipelineTask: &pt,
+				CustomTask:   true,
+				RunName:      GetRunName(pipelineRun.Status.Runs, pt.Name, pipelineRun.Name),
+				CompleteAll:  completeAll,
+			}
+			run, err := getRun(rprt.RunName)
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("error retrieving Run %s: %w", rprt.RunName, err)
+			}
+			if run != nil {
+				rprt.Run = run
+			}
+			state = append(state, &rprt)
+			continue
+		}
+
 		rprt := ResolvedPipelineRunTask{
 			PipelineTask: &pt,
 			TaskRunName:  GetTaskRunName(pipelineRun.Status.TaskRuns, pt.Name, pipelineRun.Name),
+			CompleteAll:  completeAll,
+		}
+		for _, ws := range pt.Workspaces {
+			// The workspace isn't needed when the condition of its binding is false
+			if bind, err := ws.IsConditionTrue(); err == nil && !bind {
+				continue
+			}
+			if !boundWorkspaces.Has(ws.Workspace) {
+				rprt.UnboundWorkspaces = append(rprt.UnboundWorkspaces, ws.Workspace)
+			}
 		}
 
 		// Find the Task that this PipelineTask is using
@@ -438,17 +855,23 @@ func ResolvePipelineRun(
 		)
 
 		if pt.TaskRef != nil {
-			if pt.TaskRef.Kind == v1beta1.ClusterTaskKind {
+			if pt.TaskRef.Bundle != "" {
+				t, err = getBundleTask(pt.TaskRef)
+			} else if pt.TaskRef.Kind == v1beta1.ClusterTaskKind {
 				t, err = getClusterTask(pt.TaskRef.Name)
 			} else {
 				t, err = getTask(pt.TaskRef.Name)
 			}
 			if err != nil {
-				return nil, &TaskNotFoundError{
-					Name: pt.TaskRef.Name,
-					Msg:  err.Error(),
-				}
+				missing.addTask(pt.TaskRef.Name, err)
 			}
+		}
+		missing.addConditions(pt, getCondition)
+		if !missing.empty() {
+			continue
+		}
+
+		if pt.TaskRef != nil {
 			spec = t.TaskSpec()
 			taskName = t.TaskMetadata().Name
 			kind = pt.TaskRef.Kind
@@ -485,6 +908,9 @@ func ResolvePipelineRun(
 		// Add this task to the state of the PipelineRun
 		state = append(state, &rprt)
 	}
+	if !missing.empty() {
+		return nil, missing
+	}
 	return state, nil
 }
 
@@ -513,13 +939,24 @@ func GetTaskRunName(taskRunsStatus map[string]*v1beta1.PipelineRunTaskRunStatus,
 	return names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", prName, ptName))
 }
 
+// GetRunName should return a unique name for a `Run` if one has not already been defined, and the existing one otherwise.
+func GetRunName(runsStatus map[string]*v1beta1.PipelineRunRunStatus, ptName, prName string) string {
+	for k, v := range runsStatus {
+		if v.PipelineTaskName == ptName {
+			return k
+		}
+	}
+
+	return names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", prName, ptName))
+}
+
 // GetPipelineConditionStatus will return the Condition that the PipelineRun prName should be
 // updated with, based on the status of the TaskRuns in state.
 func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState, logger *zap.SugaredLogger, dag *dag.Graph, dfinally *dag.Graph) *apis.Condition {
 	// We have 4 different states here:
 	// 1. Timed out -> Failed
-	// 2. All tasks are done and at least one has failed or has been cancelled -> Failed
-	// 3. All tasks are done or are skipped (i.e. condition check failed).-> Success
+	// 2. All tasks are done and at least one has failed (without continueOnFailure or an ignored failure policy) or has been cancelled -> Failed
+	// 3. All tasks are done, are skipped (i.e. condition check failed) or failed with continueOnFailure or an ignored failure policy -> Success
 	// 4. A Task or Condition is running right now or there are things left to run -> Running
 	// 5. Running -> Pause.
 	if pr.IsTimedOut() {
@@ -535,6 +972,7 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 	withStatusTasks := []string{}
 	skipTasks := int(0)
 	failedTasks := int(0)
+	nonFatalFailedTasks := int(0)
 	cancelledTasks := int(0)
 	reason := v1beta1.PipelineRunReasonSuccessful.String()
 
@@ -547,7 +985,7 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 	// according to the following logic:
 	//
 	// - All successful: ReasonSucceeded
-	// - Some successful, some skipped: ReasonCompleted
+	// - Some successful, some skipped or failed with continueOnFailure: ReasonCompleted
 	// - Some cancelled, none failed: ReasonCancelled
 	// - At least one failed: ReasonFailed
 	for _, rprt := range state {
@@ -555,7 +993,7 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 		switch {
 		case rprt.IsSuccessful():
 			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
-		case rprt.IsSkipped(state, dag):
+		case rprt.IsSkipped(state, dag) || state.missesFinalTaskResults(rprt, dag, dfinally):
 			skipTasks++
 			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
 			// At least one is skipped and no failure yet, mark as completed
@@ -568,6 +1006,13 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 			if reason != v1beta1.PipelineRunReasonFailed.String() {
 				reason = v1beta1.PipelineRunReasonCancelled.String()
 			}
+		case rprt.IsNonFatalFailure():
+			nonFatalFailedTasks++
+			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
+			// At least one failed with continueOnFailure and no failure yet, mark as completed
+			if reason == v1beta1.PipelineRunReasonSuccessful.String() {
+				reason = v1beta1.PipelineRunReasonCompleted.String()
+			}
 		case rprt.IsFailure():
 			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
 			failedTasks++
@@ -585,8 +1030,8 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 			Type:   apis.ConditionSucceeded,
 			Status: status,
 			Reason: reason,
-			Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Skipped: %d",
-				len(allTasks)-skipTasks, failedTasks, cancelledTasks, skipTasks),
+			Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d%s, Cancelled %d), Skipped: %d",
+				len(allTasks)-skipTasks, failedTasks, nonFatalFailuresMessage(nonFatalFailedTasks), cancelledTasks, skipTasks),
 		}
 	}
 
@@ -594,7 +1039,9 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 	// transition pipeline into stopping state when one of the tasks(dag/final) cancelled or one of the dag tasks failed
 	// for a pipeline with final tasks, single dag task failure does not transition to interim stopping state
 	// pipeline stays in running state until all final tasks are done before transitioning to failed state
-	if cancelledTasks > 0 || (failedTasks > 0 && state.checkTasksDone(dfinally)) {
+	// a pipeline completing all tasks stays in running state until they are all done
+	completeAll := pr.Spec.FailurePolicy == v1beta1.PipelineRunFailurePolicyCompleteAll
+	if cancelledTasks > 0 || (failedTasks > 0 && !completeAll && state.checkTasksDone(dfinally)) {
 		reason = v1beta1.PipelineRunReasonStopping.String()
 	} else if pr.IsPause() {
 		reason = v1beta1.PipelineRunReasonPause.String()
@@ -605,9 +1052,18 @@ func GetPipelineConditionStatus(pr *v1beta1.PipelineRun, state PipelineRunState,
 		Type:   apis.ConditionSucceeded,
 		Status: corev1.ConditionUnknown,
 		Reason: reason,
-		Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Incomplete: %d, Skipped: %d",
-			len(withStatusTasks)-skipTasks, failedTasks, cancelledTasks, len(allTasks)-len(withStatusTasks), skipTasks),
+		Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d%s, Cancelled %d), Incomplete: %d, Skipped: %d",
+			len(withStatusTasks)-skipTasks, failedTasks, nonFatalFailuresMessage(nonFatalFailedTasks), cancelledTasks, len(allTasks)-len(withStatusTasks), skipTasks),
+	}
+}
+
+// nonFatalFailuresMessage returns the part of the PipelineRun condition message counting the
+// tasks which failed with continueOnFailure, if any
+func nonFatalFailuresMessage(nonFatalFailedTasks int) string {
+	if nonFatalFailedTasks == 0 {
+		return ""
 	}
+	return fmt.Sprintf(", Failed (non-fatal): %d", nonFatalFailedTasks)
 }
 
 func resolveConditionChecks(pt *v1beta1.PipelineTask, taskRunStatus map[string]*v1beta1.PipelineRunTaskRunStatus, taskRunName string, getTaskRun resources.GetTaskRun, getCondition GetCondition, providedResources map[string]*resourcev1alpha1.PipelineResource) ([]*ResolvedConditionCheck, error) {
@@ -30,7 +30,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
@@ -38,8 +38,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 )
 
@@ -80,6 +82,28 @@ var pts = []v1beta1.PipelineTask{{
 	RunAfter: []string{"mytask8"},
 }}
 
+var continueOnFailurePts = []v1beta1.PipelineTask{{
+	Name:              "mytask10",
+	TaskRef:           &v1beta1.TaskRef{Name: "task"},
+	ContinueOnFailure: true,
+}, {
+	Name:     "mytask11",
+	TaskRef:  &v1beta1.TaskRef{Name: "taskWithNonFatalParent"},
+	RunAfter: []string{"mytask10"},
+}}
+
+var completeAllPts = []v1beta1.PipelineTask{{
+	Name:    "mytask12",
+	TaskRef: &v1beta1.TaskRef{Name: "task"},
+}, {
+	Name:     "mytask13",
+	TaskRef:  &v1beta1.TaskRef{Name: "taskWithFailedParent"},
+	RunAfter: []string{"mytask12"},
+}, {
+	Name:    "mytask14",
+	TaskRef: &v1beta1.TaskRef{Name: "task"},
+}}
+
 var p = &v1beta1.Pipeline{
 	ObjectMeta: metav1.ObjectMeta{
 		Namespace: "namespace",
@@ -112,6 +136,14 @@ var clustertask = &v1beta1.ClusterTask{
 	},
 }
 
+func getBundleTask(ref *v1beta1.TaskRef) (v1beta1.TaskInterface, error) {
+	return nil, fmt.Errorf("unexpected bundle %s", ref.Bundle)
+}
+
+func getRun(name string) (*v1alpha1.Run, error) {
+	return nil, fmt.Errorf("unexpected Run %s", name)
+}
+
 var trs = []v1beta1.TaskRun{{
 	ObjectMeta: metav1.ObjectMeta{
 		Namespace: "namespace",
@@ -286,6 +318,56 @@ var allFinishedState = PipelineRunState{{
 	},
 }}
 
+var oneNonFatalFailedState = PipelineRunState{{
+	PipelineTask: &continueOnFailurePts[0],
+	TaskRunName:  "pipelinerun-mytask10",
+	TaskRun:      makeFailed(trs[0]),
+	ResolvedTaskResources: &resources.ResolvedTaskResources{
+		TaskSpec: &task.Spec,
+	},
+}, {
+	PipelineTask: &continueOnFailurePts[1],
+	TaskRunName:  "pipelinerun-mytask11",
+	TaskRun:      nil,
+	ResolvedTaskResources: &resources.ResolvedTaskResources{
+		TaskSpec: &task.Spec,
+	},
+}}
+var allFinishedOneNonFatalFailedState = PipelineRunState{{
+	PipelineTask: &continueOnFailurePts[0],
+	TaskRunName:  "pipelinerun-mytask10",
+	TaskRun:      makeFailed(trs[0]),
+	ResolvedTaskResources: &resources.ResolvedTaskResources{
+		TaskSpec: &task.Spec,
+	},
+}, {
+	PipelineTask: &continueOnFailurePts[1],
+	TaskRunName:  "pipelinerun-mytask11",
+	TaskRun:      makeSucceeded(trs[1]),
+	ResolvedTaskResources: &resources.ResolvedTaskResources{
+		TaskSpec: &task.Spec,
+	},
+}}
+
+// completeAllState returns the state of completeAllPts in a PipelineRun with
+// the CompleteAll failure policy, whose first task failed and whose independent
+// task has the TaskRun independent.
+func completeAllState(independent *v1beta1.TaskRun) PipelineRunState {
+	state := PipelineRunState{}
+	for i, tr := range []*v1beta1.TaskRun{makeFailed(trs[0]), nil, independent} {
+		state = append(state, &ResolvedPipelineRunTask{
+			PipelineTask: &completeAllPts[i],
+			TaskRunName:  "pipelinerun-" + completeAllPts[i].Name,
+			TaskRun:      tr,
+			ResolvedTaskResources: &resources.ResolvedTaskResources{
+				TaskSpec: &task.Spec,
+			},
+			CompleteAll: true,
+		})
+	}
+	return state
+}
+
 var successTaskConditionCheckState = TaskConditionCheckState{{
 	ConditionCheckName: "myconditionCheck",
 	Condition:          &condition,
@@ -891,6 +973,51 @@ func TestIsDone(t *testing.T) {
 	}
 }
 
+func TestIsCancelled_CustomTask(t *testing.T) {
+	runWithReason := func(reason string) *v1alpha1.Run {
+		return &v1alpha1.Run{
+			ObjectMeta: metav1.ObjectMeta{Name: "run"},
+			Status: v1alpha1.RunStatus{
+				Status: duckv1.Status{
+					Conditions: []apis.Condition{{
+						Type:   apis.ConditionSucceeded,
+						Status: corev1.ConditionFalse,
+						Reason: reason,
+					}},
+				},
+			},
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		run  *v1alpha1.Run
+		want bool
+	}{{
+		name: "no run",
+		run:  nil,
+		want: false,
+	}, {
+		name: "run failed",
+		run:  runWithReason("Failed"),
+		want: false,
+	}, {
+		name: "run cancelled",
+		run:  runWithReason(v1alpha1.RunReasonCancelled),
+		want: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rprt := ResolvedPipelineRunTask{
+				PipelineTask: &v1beta1.PipelineTask{Name: "custom"},
+				CustomTask:   true,
+				Run:          tc.run,
+			}
+			if got := rprt.IsCancelled(); got != tc.want {
+				t.Errorf("expected IsCancelled: %t but got %t", tc.want, got)
+			}
+		})
+	}
+}
+
 func TestIsSkipped(t *testing.T) {
 
 	tcs := []struct {
@@ -1138,18 +1265,623 @@ func TestIsSkipped(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			dag, err := DagFromState(tc.state)
+			dag, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Could not get a dag from the TC state %#v: %v", tc.state, err)
+			}
+			stateMap := tc.state.ToMap()
+			rprt := stateMap[tc.taskName]
+			if rprt == nil {
+				t.Fatalf("Could not get task %s from the state: %v", tc.taskName, tc.state)
+			}
+			isSkipped := rprt.IsSkipped(tc.state, dag)
+			if d := cmp.Diff(isSkipped, tc.expected); d != "" {
+				t.Errorf("Didn't get expected isSkipped %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineRunState_GetSkippedTasks(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:       "uses-cache",
+			TaskRef:    &v1beta1.TaskRef{Name: "task"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{Name: "cache", Workspace: "cache"}},
+		},
+		TaskRunName:       "pipelinerun-uses-cache",
+		UnboundWorkspaces: []string{"cache"},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:     "after-cache",
+			TaskRef:  &v1beta1.TaskRef{Name: "task"},
+			RunAfter: []string{"uses-cache"},
+		},
+		TaskRunName: "pipelinerun-after-cache",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &pts[0],
+		TaskRunName:  "pipelinerun-conditionaltask",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+		ResolvedConditionChecks: failedTaskConditionCheckState,
+	}, {
+		PipelineTask: &pts[1],
+		TaskRunName:  "pipelinerun-mytask2",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := DagFromState(state)
+	if err != nil {
+		t.Fatalf("Could not get a dag from the state %#v: %v", state, err)
+	}
+	expected := []v1beta1.SkippedTask{{
+		Name:   "uses-cache",
+		Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+	}, {
+		Name:   "after-cache",
+		Reason: v1beta1.ParentTasksSkip,
+	}, {
+		Name:   "mytask1",
+		Reason: v1beta1.ConditionCheckSkip,
+	}}
+	if d := cmp.Diff(expected, state.GetSkippedTasks(d)); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_GetSkippedTasks_WhenScope(t *testing.T) {
+	falseWhen := v1beta1.WhenExpressions{{Input: "foo", Operator: selection.In, Values: []string{"bar"}}}
+	defaultVersion := "v0"
+	versionTask := &v1beta1.TaskSpec{
+		Steps:   []v1beta1.Step{{Container: corev1.Container{Name: "step1"}}},
+		Results: []v1beta1.TaskResult{{Name: "version", Aliases: []string{"tag"}}},
+	}
+	versionTaskWithDefault := versionTask.DeepCopy()
+	versionTaskWithDefault.Results[0].Default = &defaultVersion
+
+	for _, tc := range []struct {
+		name          string
+		rootWhen      v1beta1.WhenExpressions
+		scope         v1beta1.WhenScope
+		taskSpec      *v1beta1.TaskSpec
+		resultName    string
+		wantSkipped   []v1beta1.SkippedTask
+		wantResultRef *ResolvedResultRef
+	}{{
+		name:       "branch scope",
+		scope:      v1beta1.WhenScopeBranch,
+		taskSpec:   versionTaskWithDefault,
+		resultName: "version",
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "guarded",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}, {
+			Name:   "consumer",
+			Reason: v1beta1.ParentTasksSkip,
+		}, {
+			Name:   "after",
+			Reason: v1beta1.ParentTasksSkip,
+		}},
+	}, {
+		name:       "task scope",
+		scope:      v1beta1.WhenScopeTask,
+		taskSpec:   versionTaskWithDefault,
+		resultName: "version",
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "guarded",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}},
+		wantResultRef: &ResolvedResultRef{
+			Value:           v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "v0"},
+			ResultReference: v1beta1.ResultRef{PipelineTask: "guarded", Result: "version"},
+		},
+	}, {
+		name:       "task scope with a result referenced by its alias",
+		scope:      v1beta1.WhenScopeTask,
+		taskSpec:   versionTaskWithDefault,
+		resultName: "tag",
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "guarded",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}},
+		wantResultRef: &ResolvedResultRef{
+			Value:           v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "v0"},
+			ResultReference: v1beta1.ResultRef{PipelineTask: "guarded", Result: "tag"},
+		},
+	}, {
+		name:       "task scope with a result without default",
+		scope:      v1beta1.WhenScopeTask,
+		taskSpec:   versionTask,
+		resultName: "version",
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "guarded",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}, {
+			Name:   "consumer",
+			Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+		}},
+	}, {
+		name:       "task scope in a skipped branch",
+		rootWhen:   falseWhen,
+		scope:      v1beta1.WhenScopeTask,
+		taskSpec:   versionTaskWithDefault,
+		resultName: "version",
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "root",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}, {
+			Name:   "guarded",
+			Reason: v1beta1.WhenExpressionsSkip,
+		}, {
+			Name:   "consumer",
+			Reason: v1beta1.ParentTasksSkip,
+		}, {
+			Name:   "after",
+			Reason: v1beta1.ParentTasksSkip,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			state := PipelineRunState{{
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:            "root",
+					TaskRef:         &v1beta1.TaskRef{Name: "task"},
+					WhenExpressions: tc.rootWhen,
+				},
+				TaskRunName:           "pipelinerun-root",
+				TaskRun:               makeSucceeded(trs[0]),
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: &task.Spec},
+			}, {
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:            "guarded",
+					TaskRef:         &v1beta1.TaskRef{Name: "version"},
+					RunAfter:        []string{"root"},
+					WhenExpressions: falseWhen,
+					WhenScope:       tc.scope,
+				},
+				TaskRunName:           "pipelinerun-guarded",
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: tc.taskSpec},
+			}, {
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:    "consumer",
+					TaskRef: &v1beta1.TaskRef{Name: "task"},
+					Params: []v1beta1.Param{{
+						Name:  "version",
+						Value: v1beta1.NewArrayOrString(fmt.Sprintf("$(tasks.guarded.results.%s)", tc.resultName)),
+					}},
+				},
+				TaskRunName:           "pipelinerun-consumer",
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: &task.Spec},
+			}, {
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:     "after",
+					TaskRef:  &v1beta1.TaskRef{Name: "task"},
+					RunAfter: []string{"guarded"},
+				},
+				TaskRunName:           "pipelinerun-after",
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: &task.Spec},
+			}}
+			if tc.rootWhen != nil {
+				state[0].TaskRun = nil
+			}
+			d, err := DagFromState(state)
+			if err != nil {
+				t.Fatalf("Could not get a dag from the state %#v: %v", state, err)
+			}
+			if d := cmp.Diff(tc.wantSkipped, state.GetSkippedTasks(d)); d != "" {
+				t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+			}
+			if tc.wantResultRef == nil {
+				return
+			}
+			got, err := ResolveResultRefs(state, PipelineRunState{state[2]})
+			if err != nil {
+				t.Fatalf("ResolveResultRefs: %v", err)
+			}
+			if d := cmp.Diff(ResolvedResultRefs{tc.wantResultRef}, got); d != "" {
+				t.Errorf("Didn't get expected resolved result refs %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineRunState_GetSkippedTasks_ContinueOnFailure(t *testing.T) {
+	defaultVersion := "v0"
+	versionTask := &v1beta1.TaskSpec{
+		Steps:   []v1beta1.Step{{Container: corev1.Container{Name: "step1"}}},
+		Results: []v1beta1.TaskResult{{Name: "version"}},
+	}
+	versionTaskWithDefault := versionTask.DeepCopy()
+	versionTaskWithDefault.Results[0].Default = &defaultVersion
+
+	for _, tc := range []struct {
+		name          string
+		taskSpec      *v1beta1.TaskSpec
+		wantSkipped   []v1beta1.SkippedTask
+		wantResultRef *ResolvedResultRef
+	}{{
+		name:     "result with default",
+		taskSpec: versionTaskWithDefault,
+		wantResultRef: &ResolvedResultRef{
+			Value:           v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "v0"},
+			ResultReference: v1beta1.ResultRef{PipelineTask: "lint", Result: "version"},
+		},
+	}, {
+		name:     "result without default",
+		taskSpec: versionTask,
+		wantSkipped: []v1beta1.SkippedTask{{
+			Name:   "consumer",
+			Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+		}, {
+			Name:   "after-consumer",
+			Reason: v1beta1.ParentTasksSkip,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The failed TaskRun reported a result, which isn't used
+			failed := makeFailed(trs[0])
+			failed.Status.TaskRunResults = []v1beta1.TaskRunResult{{Name: "version", Value: "broken"}}
+			state := PipelineRunState{{
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:              "lint",
+					TaskRef:           &v1beta1.TaskRef{Name: "version"},
+					ContinueOnFailure: true,
+				},
+				TaskRunName:           "pipelinerun-lint",
+				TaskRun:               failed,
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: tc.taskSpec},
+			}, {
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:    "consumer",
+					TaskRef: &v1beta1.TaskRef{Name: "task"},
+					Params: []v1beta1.Param{{
+						Name:  "version",
+						Value: v1beta1.NewArrayOrString("$(tasks.lint.results.version)"),
+					}},
+				},
+				TaskRunName:           "pipelinerun-consumer",
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: &task.Spec},
+			}, {
+				PipelineTask: &v1beta1.PipelineTask{
+					Name:     "after-consumer",
+					TaskRef:  &v1beta1.TaskRef{Name: "task"},
+					RunAfter: []string{"consumer"},
+				},
+				TaskRunName:           "pipelinerun-after-consumer",
+				ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: &task.Spec},
+			}}
+			d, err := DagFromState(state)
+			if err != nil {
+				t.Fatalf("Could not get a dag from the state %#v: %v", state, err)
+			}
+			if d := cmp.Diff(tc.wantSkipped, state.GetSkippedTasks(d)); d != "" {
+				t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+			}
+			if tc.wantResultRef == nil {
+				return
+			}
+			got, err := ResolveResultRefs(state, PipelineRunState{state[1]})
+			if err != nil {
+				t.Fatalf("ResolveResultRefs: %v", err)
+			}
+			if d := cmp.Diff(ResolvedResultRefs{tc.wantResultRef}, got); d != "" {
+				t.Errorf("Didn't get expected resolved result refs %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineRunState_GetFinalTasks_UnboundWorkspace(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: &pts[0],
+		TaskRunName:  "pipelinerun-mytask1",
+		TaskRun:      makeSucceeded(trs[0]),
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:       "cleanup-cache",
+			TaskRef:    &v1beta1.TaskRef{Name: "task"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{Name: "cache", Workspace: "cache"}},
+		},
+		TaskRunName:       "pipelinerun-cleanup-cache",
+		UnboundWorkspaces: []string{"cache"},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &pts[1],
+		TaskRunName:  "pipelinerun-mytask2",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList{pts[0]})
+	if err != nil {
+		t.Fatalf("Could not build the dag: %v", err)
+	}
+	dfinally, err := dag.Build(v1beta1.PipelineTaskList{*state[1].PipelineTask, pts[1]})
+	if err != nil {
+		t.Fatalf("Could not build the finally dag: %v", err)
+	}
+	var got []string
+	for _, rprt := range state.GetFinalTasks(d, dfinally) {
+		got = append(got, rprt.PipelineTask.Name)
+	}
+	if d := cmp.Diff([]string{"mytask2"}, got); d != "" {
+		t.Errorf("Didn't get expected final tasks %s", diff.PrintWantGot(d))
+	}
+	expectedSkipped := []v1beta1.SkippedTask{{
+		Name:   "cleanup-cache",
+		Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+	}}
+	if d := cmp.Diff(expectedSkipped, state.GetSkippedTasks(dfinally)); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_WhenExpressionsResults(t *testing.T) {
+	guardedTask := func(name string, operator selection.Operator) *v1beta1.PipelineTask {
+		return &v1beta1.PipelineTask{
+			Name:    name,
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			WhenExpressions: v1beta1.WhenExpressions{{
+				Input:    "abc123",
+				Operator: operator,
+				Values:   []string{"$(tasks.mytask1.results.commit)"},
+			}},
+		}
+	}
+	state := PipelineRunState{{
+		PipelineTask: &pts[0],
+		TaskRunName:  "pipelinerun-mytask1",
+		TaskRun:      makeStarted(trs[0]),
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: guardedTask("deploy", selection.In),
+		TaskRunName:  "pipelinerun-deploy",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: guardedTask("rollback", selection.NotIn),
+		TaskRunName:  "pipelinerun-rollback",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList{pts[0], *state[1].PipelineTask, *state[2].PipelineTask})
+	if err != nil {
+		t.Fatalf("Could not build the dag: %v", err)
+	}
+
+	// While the task producing the result is running, the when expressions aren't evaluated
+	ApplyWhenExpressionsResults(state)
+	if got := state.GetSkippedTasks(d); len(got) != 0 {
+		t.Errorf("Expected no skipped tasks while the task producing the result is running, got %v", got)
+	}
+
+	succeeded := makeSucceeded(trs[0])
+	succeeded.Status.TaskRunResults = []v1beta1.TaskRunResult{{Name: "commit", Value: "abc123"}}
+	state[0].TaskRun = succeeded
+	ApplyWhenExpressionsResults(state)
+	expectedSkipped := []v1beta1.SkippedTask{{
+		Name:   "rollback",
+		Reason: v1beta1.WhenExpressionsSkip,
+	}}
+	if d := cmp.Diff(expectedSkipped, state.GetSkippedTasks(d)); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]string{"mytask1", "rollback"}, state.SuccessfulOrSkippedDAGTasks(d)); d != "" {
+		t.Errorf("Didn't get expected successful or skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_GetFinalTasks_MissingResults(t *testing.T) {
+	succeeded := makeSucceeded(trs[1])
+	succeeded.Status.TaskRunResults = []v1beta1.TaskRunResult{{Name: "commit", Value: "abc123"}}
+	finalTask := func(name, resultRef string) *v1beta1.PipelineTask {
+		return &v1beta1.PipelineTask{
+			Name:    name,
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			Params: []v1beta1.Param{{
+				Name:  "commit",
+				Value: v1beta1.NewArrayOrString(resultRef),
+			}},
+		}
+	}
+	state := PipelineRunState{{
+		PipelineTask: &pts[0],
+		TaskRunName:  "pipelinerun-mytask1",
+		TaskRun:      makeFailed(trs[0]),
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &pts[1],
+		TaskRunName:  "pipelinerun-mytask2",
+		TaskRun:      succeeded,
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: finalTask("report-failed", "$(tasks.mytask1.results.commit)"),
+		TaskRunName:  "pipelinerun-report-failed",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: finalTask("report-succeeded", "$(tasks.mytask2.results.commit)"),
+		TaskRunName:  "pipelinerun-report-succeeded",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList{pts[0], pts[1]})
+	if err != nil {
+		t.Fatalf("Could not build the dag: %v", err)
+	}
+	dfinally, err := dag.Build(v1beta1.FinalTaskList{*state[2].PipelineTask, *state[3].PipelineTask})
+	if err != nil {
+		t.Fatalf("Could not build the finally dag: %v", err)
+	}
+	var got []string
+	for _, rprt := range state.GetFinalTasks(d, dfinally) {
+		got = append(got, rprt.PipelineTask.Name)
+	}
+	if d := cmp.Diff([]string{"report-succeeded"}, got); d != "" {
+		t.Errorf("Didn't get expected final tasks %s", diff.PrintWantGot(d))
+	}
+	expectedSkipped := []v1beta1.SkippedTask{{
+		Name:   "report-failed",
+		Reason: v1beta1.MissingResultsOrWorkspaceSkip,
+	}}
+	if d := cmp.Diff(expectedSkipped, state.GetSkippedFinalTasks(d, dfinally)); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+
+	// Once the other final task is done, the PipelineRun is done as well
+	state[3].TaskRun = makeSucceeded(trs[1])
+	c := GetPipelineConditionStatus(&v1beta1.PipelineRun{}, state, zap.NewNop().Sugar(), d, dfinally)
+	if c.Status != corev1.ConditionFalse {
+		t.Errorf("Expected the PipelineRun to be done and failed, got %v", c)
+	}
+	if d := cmp.Diff(getExpectedMessage(corev1.ConditionFalse, 2, 0, 1, 1, 0), c.Message); d != "" {
+		t.Errorf("Didn't get expected condition message %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_GetFinalTasks_WhenExpressions(t *testing.T) {
+	finalTask := func(name, status string) *v1beta1.PipelineTask {
+		return &v1beta1.PipelineTask{
+			Name:    name,
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			WhenExpressions: v1beta1.WhenExpressions{{
+				Input:    "$(tasks.status)",
+				Operator: selection.In,
+				Values:   []string{status},
+			}},
+		}
+	}
+	state := PipelineRunState{{
+		PipelineTask: &pts[0],
+		TaskRunName:  "pipelinerun-mytask1",
+		TaskRun:      makeStarted(trs[0]),
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: finalTask("notify-on-failure", "Failed"),
+		TaskRunName:  "pipelinerun-notify-on-failure",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: finalTask("notify-on-success", "Succeeded"),
+		TaskRunName:  "pipelinerun-notify-on-success",
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList{pts[0]})
+	if err != nil {
+		t.Fatalf("Could not build the dag: %v", err)
+	}
+	dfinally, err := dag.Build(v1beta1.FinalTaskList{*state[1].PipelineTask, *state[2].PipelineTask})
+	if err != nil {
+		t.Fatalf("Could not build the finally dag: %v", err)
+	}
+
+	// While the DAG task is running, the when expressions of the final tasks aren't evaluated
+	ApplyFinalTaskWhenExpressions(state, d, dfinally)
+	if got := state.GetFinalTasks(d, dfinally); len(got) != 0 {
+		t.Errorf("Expected no final tasks while the DAG task is running, got %v", got)
+	}
+	if got := state.GetSkippedFinalTasks(d, dfinally); len(got) != 0 {
+		t.Errorf("Expected no skipped final tasks while the DAG task is running, got %v", got)
+	}
+
+	state[0].TaskRun = makeFailed(trs[0])
+	ApplyFinalTaskWhenExpressions(state, d, dfinally)
+	var got []string
+	for _, rprt := range state.GetFinalTasks(d, dfinally) {
+		got = append(got, rprt.PipelineTask.Name)
+	}
+	if d := cmp.Diff([]string{"notify-on-failure"}, got); d != "" {
+		t.Errorf("Didn't get expected final tasks %s", diff.PrintWantGot(d))
+	}
+	expectedSkipped := []v1beta1.SkippedTask{{
+		Name:   "notify-on-success",
+		Reason: v1beta1.FinallyWhenExpressionsSkip,
+	}}
+	if d := cmp.Diff(expectedSkipped, state.GetSkippedFinalTasks(d, dfinally)); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_GetPipelineTaskStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		state PipelineRunState
+		want  map[string]string
+	}{{
+		name:  "succeeded",
+		state: allFinishedState,
+		want: map[string]string{
+			"tasks.mytask1.status": v1beta1.TaskRunReasonSuccessful.String(),
+			"tasks.mytask2.status": v1beta1.TaskRunReasonSuccessful.String(),
+			"tasks.status":         v1beta1.PipelineRunReasonSuccessful.String(),
+		},
+	}, {
+		name:  "failed",
+		state: conditionCheckFailedWithOthersFailedState,
+		want: map[string]string{
+			"tasks.mytask1.status": v1beta1.TaskRunReasonFailed.String(),
+			"tasks.mytask6.status": PipelineTaskStateNone,
+			"tasks.status":         v1beta1.PipelineRunReasonFailed.String(),
+		},
+	}, {
+		name:  "completed with a skipped task",
+		state: conditionCheckFailedWithOthersPassedState,
+		want: map[string]string{
+			"tasks.mytask1.status": v1beta1.TaskRunReasonSuccessful.String(),
+			"tasks.mytask6.status": PipelineTaskStateNone,
+			"tasks.status":         v1beta1.PipelineRunReasonCompleted.String(),
+		},
+	}, {
+		name:  "completed with a non-fatal failure",
+		state: allFinishedOneNonFatalFailedState,
+		want: map[string]string{
+			"tasks.mytask10.status": v1beta1.TaskRunReasonFailed.String(),
+			"tasks.mytask11.status": v1beta1.TaskRunReasonSuccessful.String(),
+			"tasks.status":          v1beta1.PipelineRunReasonCompleted.String(),
+		},
+	}, {
+		name:  "none",
+		state: oneStartedState,
+		want: map[string]string{
+			"tasks.mytask1.status": PipelineTaskStateNone,
+			"tasks.mytask2.status": PipelineTaskStateNone,
+			"tasks.status":         PipelineTaskStateNone,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := DagFromState(tc.state)
 			if err != nil {
-				t.Fatalf("Could not get a dag from the TC state %#v: %v", tc.state, err)
-			}
-			stateMap := tc.state.ToMap()
-			rprt := stateMap[tc.taskName]
-			if rprt == nil {
-				t.Fatalf("Could not get task %s from the state: %v", tc.taskName, tc.state)
+				t.Fatalf("Unexpected error while building DAG for state %v: %v", tc.state, err)
 			}
-			isSkipped := rprt.IsSkipped(tc.state, dag)
-			if d := cmp.Diff(isSkipped, tc.expected); d != "" {
-				t.Errorf("Didn't get expected isSkipped %s", diff.PrintWantGot(d))
+			if d := cmp.Diff(tc.want, tc.state.GetPipelineTaskStatus(d)); d != "" {
+				t.Errorf("Didn't get expected execution status %s", diff.PrintWantGot(d))
 			}
 		})
 	}
@@ -1180,6 +1912,10 @@ func TestPipelineRunState_SuccessfulOrSkippedDAGTasks(t *testing.T) {
 		name:          "all-finished",
 		state:         allFinishedState,
 		expectedNames: []string{pts[0].Name, pts[1].Name},
+	}, {
+		name:          "one-task-failed-with-continue-on-failure",
+		state:         oneNonFatalFailedState,
+		expectedNames: []string{continueOnFailurePts[0].Name},
 	}, {
 		name:          "conditional task not skipped as the condition execution was successful",
 		state:         conditionCheckSuccessNoTaskStartedState,
@@ -1483,6 +2219,180 @@ func TestGetPipelineConditionStatus(t *testing.T) {
 	}
 }
 
+func TestGetPipelineConditionStatus_ContinueOnFailure(t *testing.T) {
+	tcs := []struct {
+		name            string
+		state           PipelineRunState
+		expectStopping  bool
+		expectCondition *apis.Condition
+	}{{
+		name:           "task failed with continue on failure, dependent task not started",
+		state:          oneNonFatalFailedState,
+		expectStopping: false,
+		expectCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  v1beta1.PipelineRunReasonRunning.String(),
+			Message: "Tasks Completed: 1 (Failed: 0, Failed (non-fatal): 1, Cancelled 0), Incomplete: 1, Skipped: 0",
+		},
+	}, {
+		name:           "task failed with continue on failure, dependent task succeeded",
+		state:          allFinishedOneNonFatalFailedState,
+		expectStopping: false,
+		expectCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionTrue,
+			Reason:  v1beta1.PipelineRunReasonCompleted.String(),
+			Message: "Tasks Completed: 2 (Failed: 0, Failed (non-fatal): 1, Cancelled 0), Skipped: 0",
+		},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("somepipelinerun")
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for state %v: %v", tc.state, err)
+			}
+			if stopping := tc.state.IsStopping(d); stopping != tc.expectStopping {
+				t.Errorf("Expected IsStopping to be %t but got %t", tc.expectStopping, stopping)
+			}
+			c := GetPipelineConditionStatus(pr, tc.state, zap.NewNop().Sugar(), d, &dag.Graph{})
+			if d := cmp.Diff(tc.expectCondition, c); d != "" {
+				t.Fatalf("Mismatch in condition %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestGetPipelineConditionStatus_CompleteAll(t *testing.T) {
+	tcs := []struct {
+		name            string
+		state           PipelineRunState
+		expectNext      []string
+		expectCondition *apis.Condition
+	}{{
+		name:       "task failed, independent task not started",
+		state:      completeAllState(nil),
+		expectNext: []string{completeAllPts[2].Name},
+		expectCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  v1beta1.PipelineRunReasonRunning.String(),
+			Message: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Incomplete: 1, Skipped: 1",
+		},
+	}, {
+		name:       "task failed, independent task running",
+		state:      completeAllState(makeStarted(trs[1])),
+		expectNext: []string{},
+		expectCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  v1beta1.PipelineRunReasonRunning.String(),
+			Message: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Incomplete: 1, Skipped: 1",
+		},
+	}, {
+		name:       "task failed, independent task succeeded",
+		state:      completeAllState(makeSucceeded(trs[1])),
+		expectNext: []string{},
+		expectCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  v1beta1.PipelineRunReasonFailed.String(),
+			Message: "Tasks Completed: 2 (Failed: 1, Cancelled 0), Skipped: 1",
+		},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("somepipelinerun", tb.PipelineRunSpec("somepipeline",
+				tb.PipelineRunFailurePolicy(v1beta1.PipelineRunFailurePolicyCompleteAll)))
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for state %v: %v", tc.state, err)
+			}
+			if tc.state.IsStopping(d) {
+				t.Error("Expected the PipelineRun completing all tasks not to be stopping")
+			}
+			candidates, err := dag.GetSchedulable(d, tc.state.SuccessfulOrSkippedDAGTasks(d)...)
+			if err != nil {
+				t.Fatalf("Unexpected error getting the schedulable tasks: %v", err)
+			}
+			next := []string{}
+			for _, rprt := range tc.state.GetNextTasks(candidates) {
+				next = append(next, rprt.PipelineTask.Name)
+			}
+			if d := cmp.Diff(tc.expectNext, next); d != "" {
+				t.Errorf("Mismatch in next tasks %s", diff.PrintWantGot(d))
+			}
+			wantSkipped := []v1beta1.SkippedTask{{Name: completeAllPts[1].Name, Reason: v1beta1.ParentTasksFailedSkip}}
+			if d := cmp.Diff(wantSkipped, tc.state.GetSkippedTasks(d)); d != "" {
+				t.Errorf("Mismatch in skipped tasks %s", diff.PrintWantGot(d))
+			}
+			c := GetPipelineConditionStatus(pr, tc.state, zap.NewNop().Sugar(), d, &dag.Graph{})
+			if d := cmp.Diff(tc.expectCondition, c); d != "" {
+				t.Fatalf("Mismatch in condition %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+// TestGetPipelineConditionStatus_Message checks the exact progress summary of
+// the condition for several states of the DAG.
+func TestGetPipelineConditionStatus_Message(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		state       PipelineRunState
+		wantReason  string
+		wantMessage string
+	}{{
+		name:        "none started",
+		state:       noneStartedState,
+		wantReason:  v1beta1.PipelineRunReasonRunning.String(),
+		wantMessage: "Tasks Completed: 0 (Failed: 0, Cancelled 0), Incomplete: 2, Skipped: 0",
+	}, {
+		name:        "one finished",
+		state:       oneFinishedState,
+		wantReason:  v1beta1.PipelineRunReasonRunning.String(),
+		wantMessage: "Tasks Completed: 1 (Failed: 0, Cancelled 0), Incomplete: 1, Skipped: 0",
+	}, {
+		name:        "one failed",
+		state:       oneFailedState,
+		wantReason:  v1beta1.PipelineRunReasonFailed.String(),
+		wantMessage: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 1",
+	}, {
+		name:        "grandparent not run",
+		state:       taskWithGrandParentsOneNotRunState,
+		wantReason:  v1beta1.PipelineRunReasonRunning.String(),
+		wantMessage: "Tasks Completed: 1 (Failed: 0, Cancelled 0), Incomplete: 3, Skipped: 0",
+	}, {
+		name:        "all finished",
+		state:       allFinishedState,
+		wantReason:  v1beta1.PipelineRunReasonSuccessful.String(),
+		wantMessage: "Tasks Completed: 2 (Failed: 0, Cancelled 0), Skipped: 0",
+	}, {
+		name:        "grandparent skipped",
+		state:       taskWithGrandParentSkippedState,
+		wantReason:  v1beta1.PipelineRunReasonCompleted.String(),
+		wantMessage: "Tasks Completed: 1 (Failed: 0, Cancelled 0), Skipped: 3",
+	}, {
+		name:        "grandparent failed",
+		state:       taskWithGrandParentsOneFailedState,
+		wantReason:  v1beta1.PipelineRunReasonFailed.String(),
+		wantMessage: "Tasks Completed: 2 (Failed: 1, Cancelled 0), Skipped: 2",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("somepipelinerun")
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for state %v: %v", tc.state, err)
+			}
+			c := GetPipelineConditionStatus(pr, tc.state, zap.NewNop().Sugar(), d, &dag.Graph{})
+			if c.Reason != tc.wantReason || c.Message != tc.wantMessage {
+				t.Errorf("GetPipelineConditionStatus() = %q, %q, want %q, %q", c.Reason, c.Message, tc.wantReason, tc.wantMessage)
+			}
+		})
+	}
+}
+
 func TestGetPipelineConditionStatus_WithFinalTasks(t *testing.T) {
 
 	// pipeline state with one DAG successful, one final task failed
@@ -1733,7 +2643,7 @@ func TestResolvePipelineRun(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -1790,6 +2700,121 @@ func TestResolvePipelineRun(t *testing.T) {
 	}
 }
 
+func TestResolvePipelineRun_UnboundWorkspaces(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Workspaces: []v1beta1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: "source"},
+			{Name: "cache", Workspace: "cache"},
+		},
+	}, {
+		Name:       "mytask2",
+		TaskRef:    &v1beta1.TaskRef{Name: "task"},
+		Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{Name: "source", Workspace: "source"}},
+	}}
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return task, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			Workspaces: []v1beta1.WorkspaceBinding{{Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		},
+	}
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if err != nil {
+		t.Fatalf("Error getting tasks for fake pipeline: %s", err)
+	}
+	if d := cmp.Diff([]string{"cache"}, pipelineState[0].UnboundWorkspaces); d != "" {
+		t.Errorf("Unexpected unbound workspaces of mytask1 %s", diff.PrintWantGot(d))
+	}
+	if len(pipelineState[1].UnboundWorkspaces) != 0 {
+		t.Errorf("Expected no unbound workspaces for mytask2, got %v", pipelineState[1].UnboundWorkspaces)
+	}
+}
+
+func TestResolvePipelineRun_CustomTask(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{APIVersion: "example.dev/v0", Kind: "Example", Name: "myexample"},
+	}, {
+		Name:    "mytask2",
+		TaskRef: &v1beta1.TaskRef{APIVersion: "example.dev/v0", Kind: "Example", Name: "myexample"},
+	}}
+	run := &v1alpha1.Run{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mytask1"},
+		Status: v1alpha1.RunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}},
+			},
+		},
+	}
+	getTask := func(name string) (v1beta1.TaskInterface, error) {
+		return nil, fmt.Errorf("unexpected Task %s", name)
+	}
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
+	getRun := func(name string) (*v1alpha1.Run, error) {
+		if name == run.Name {
+			return run, nil
+		}
+		return nil, kerrors.NewNotFound(v1alpha1.Resource("run"), name)
+	}
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+		Status: v1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				Runs: map[string]*v1beta1.PipelineRunRunStatus{
+					"pipelinerun-mytask1": {PipelineTaskName: "mytask1"},
+				},
+			},
+		},
+	}
+	names.TestingSeed()
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if err != nil {
+		t.Fatalf("Error getting tasks for fake pipeline: %s", err)
+	}
+	expectedState := PipelineRunState{{
+		PipelineTask: &pts[0],
+		CustomTask:   true,
+		RunName:      "pipelinerun-mytask1",
+		Run:          run,
+	}, {
+		PipelineTask: &pts[1],
+		CustomTask:   true,
+		RunName:      "pipelinerun-mytask2-9l9zj",
+	}}
+	if d := cmp.Diff(expectedState, pipelineState); d != "" {
+		t.Fatalf("Expected to get current pipeline state %v, but actual differed %s", expectedState, diff.PrintWantGot(d))
+	}
+
+	// The Run of mytask1 failed, and the custom task controller handles the retries.
+	pipelineState[0].PipelineTask.Retries = 1
+	if !pipelineState[0].IsDone() || !pipelineState[0].IsFailure() || pipelineState[0].IsSuccessful() {
+		t.Errorf("Expected mytask1 to be done and failed")
+	}
+	if pipelineState.IsBeforeFirstTaskRun() {
+		t.Errorf("Expected the pipeline state to be after its first Run")
+	}
+	if pipelineState[1].IsStarted() || pipelineState[1].IsDone() {
+		t.Errorf("Expected mytask2 not to be started")
+	}
+	if d := cmp.Diff([]*ResolvedPipelineRunTask{pipelineState[1]}, pipelineState.GetNextTasks(sets.NewString("mytask1", "mytask2"))); d != "" {
+		t.Errorf("GetNextTasks() %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestResolvePipelineRun_PipelineTaskHasNoResources(t *testing.T) {
 	pts := []v1beta1.PipelineTask{{
 		Name:    "mytask1",
@@ -1812,7 +2837,7 @@ func TestResolvePipelineRun_PipelineTaskHasNoResources(t *testing.T) {
 			Name: "pipelinerun",
 		},
 	}
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 	if err != nil {
 		t.Fatalf("Did not expect error when resolving PipelineRun without Resources: %v", err)
 	}
@@ -1833,6 +2858,50 @@ func TestResolvePipelineRun_PipelineTaskHasNoResources(t *testing.T) {
 	}
 }
 
+func TestResolvePipelineRun_Bundle(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{Name: "task", Bundle: "registry.example.com/tasks:v1"},
+	}, {
+		Name:    "mytask2",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+	}}
+	bundleTask := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "task"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name: "bundled-step",
+			}}},
+		},
+	}
+
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return task, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getBundleTask := func(ref *v1beta1.TaskRef) (v1beta1.TaskInterface, error) {
+		if ref.Bundle != "registry.example.com/tasks:v1" || ref.Name != "task" {
+			return nil, fmt.Errorf("unexpected bundle reference %v", ref)
+		}
+		return bundleTask, nil
+	}
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+	}
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if err != nil {
+		t.Fatalf("Did not expect error when resolving PipelineRun with a bundle: %v", err)
+	}
+	if d := cmp.Diff(&bundleTask.Spec, pipelineState[0].ResolvedTaskResources.TaskSpec); d != "" {
+		t.Errorf("Expected the Task to be resolved from its bundle %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(&task.Spec, pipelineState[1].ResolvedTaskResources.TaskSpec); d != "" {
+		t.Errorf("Expected the Task to be resolved from the cluster %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestResolvePipelineRun_TaskDoesntExist(t *testing.T) {
 	pts := []v1beta1.PipelineTask{{
 		Name:    "mytask1",
@@ -1859,17 +2928,82 @@ func TestResolvePipelineRun_TaskDoesntExist(t *testing.T) {
 			Name: "pipelinerun",
 		},
 	}
-	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 	switch err := err.(type) {
 	case nil:
 		t.Fatalf("Expected error getting non-existent Tasks for Pipeline %s but got none", p.Name)
-	case *TaskNotFoundError:
-		// expected error
+	case *ReferencesNotFoundError:
+		if len(err.Tasks) != 1 || err.Tasks[0].Name != "task" || !err.AllNotFound() {
+			t.Fatalf("Expected Task %q to be reported as not found but got %v", "task", err)
+		}
 	default:
 		t.Fatalf("Expected specific error type returned by func for non-existent Task for Pipeline %s but got %s", p.Name, err)
 	}
 }
 
+func TestResolvePipelineRun_ReferencesDontExist(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{Name: "missing-task"},
+	}, {
+		Name:    "mytask2",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Conditions: []v1beta1.PipelineTaskCondition{{
+			ConditionRef: "missing-condition",
+		}},
+	}, {
+		Name:    "mytask3",
+		TaskRef: &v1beta1.TaskRef{Name: "missing-clustertask", Kind: v1beta1.ClusterTaskKind},
+	}}
+	getTask := func(name string) (v1beta1.TaskInterface, error) {
+		if name == "task" {
+			return task, nil
+		}
+		return nil, kerrors.NewNotFound(v1beta1.Resource("task"), name)
+	}
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) {
+		return nil, kerrors.NewNotFound(v1beta1.Resource("clustertask"), name)
+	}
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) {
+		return nil, kerrors.NewNotFound(v1beta1.Resource("taskrun"), name)
+	}
+	getCondition := func(name string) (*v1alpha1.Condition, error) {
+		return nil, kerrors.NewNotFound(v1beta1.Resource("condition"), name)
+	}
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+	}
+
+	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	var missing *ReferencesNotFoundError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a ReferencesNotFoundError but got %v", err)
+	}
+	want := `Couldn't retrieve Task "missing-task": task.tekton.dev "missing-task" not found; ` +
+		`Couldn't retrieve Task "missing-clustertask": clustertask.tekton.dev "missing-clustertask" not found; ` +
+		`Couldn't retrieve Condition "missing-condition": condition.tekton.dev "missing-condition" not found`
+	if d := cmp.Diff(want, missing.Error()); d != "" {
+		t.Errorf("Unexpected error message %s", diff.PrintWantGot(d))
+	}
+	if !missing.AllNotFound() {
+		t.Errorf("Expected all the references to be reported as not found")
+	}
+
+	// References failing to be retrieved for another reason are reported as well.
+	getClusterTask = func(name string) (v1beta1.TaskInterface, error) {
+		return nil, errors.New("connection refused")
+	}
+	_, err = ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a ReferencesNotFoundError but got %v", err)
+	}
+	if len(missing.Tasks) != 2 || missing.AllNotFound() {
+		t.Errorf("Expected the ClusterTask to be reported as failing to be retrieved but got %v", missing)
+	}
+}
+
 func TestResolvePipelineRun_ResourceBindingsDontExist(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1905,7 +3039,7 @@ func TestResolvePipelineRun_ResourceBindingsDontExist(t *testing.T) {
 					Name: "pipelinerun",
 				},
 			}
-			_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, tt.p.Spec.Tasks, providedResources)
+			_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, tt.p.Spec.Tasks, providedResources)
 			if err == nil {
 				t.Fatalf("Expected error when bindings are in incorrect state for Pipeline %s but got none", p.Name)
 			}
@@ -1955,7 +3089,7 @@ func TestResolvePipelineRun_withExistingTaskRuns(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -2008,7 +3142,7 @@ func TestResolvedPipelineRun_PipelineTaskHasOptionalResources(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -2111,7 +3245,7 @@ func TestResolveConditionChecks(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, getCondition, pts, providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 			if err != nil {
 				t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 			}
@@ -2204,7 +3338,7 @@ func TestResolveConditionChecks_MultipleConditions(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, getCondition, pts, providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 			if err != nil {
 				t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 			}
@@ -2248,13 +3382,15 @@ func TestResolveConditionChecks_ConditionDoesNotExist(t *testing.T) {
 		},
 	}
 
-	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 
 	switch err := err.(type) {
 	case nil:
 		t.Fatalf("Expected error getting non-existent Conditions but got none")
-	case *ConditionNotFoundError:
-		// expected error
+	case *ReferencesNotFoundError:
+		if len(err.Conditions) != 1 || err.Conditions[0].Name != "does-not-exist" || !err.AllNotFound() {
+			t.Fatalf("Expected Condition %q to be reported as not found but got %v", "does-not-exist", err)
+		}
 	default:
 		t.Fatalf("Expected specific error type returned by func for non-existent Condition got %s", err)
 	}
@@ -2317,7 +3453,7 @@ func TestResolveConditionCheck_UseExistingConditionCheckName(t *testing.T) {
 		},
 	}
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, providedResources)
 	if err != nil {
 		t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 	}
@@ -2393,7 +3529,7 @@ func TestResolvedConditionCheck_WithResources(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, tc.providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getRun, getClusterTask, getBundleTask, getCondition, pts, tc.providedResources)
 
 			if tc.wantErr {
 				if err == nil {
@@ -2471,6 +3607,54 @@ func TestValidateWorkspaceBindings(t *testing.T) {
 	}
 }
 
+func TestValidateWorkspaceBindings_Optional(t *testing.T) {
+	p := tb.Pipeline("pipelines", tb.PipelineSpec(
+		tb.PipelineWorkspaceDeclaration("foo"),
+	))
+	p.Spec.Workspaces[0].Optional = true
+	pr := tb.PipelineRun("pipelinerun", tb.PipelineRunSpec("pipeline"))
+	if err := ValidateWorkspaceBindings(&p.Spec, pr); err != nil {
+		t.Fatalf("Expected optional workspace `foo` not to be required but got error: %v", err)
+	}
+}
+
+func TestValidateReadOnlyWorkspaces(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:       "read",
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{Name: "input", Workspace: "source"}},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &v1beta1.TaskSpec{Workspaces: []v1beta1.WorkspaceDeclaration{{Name: "input", ReadOnly: true}}},
+		},
+	}}
+	for _, tc := range []struct {
+		name    string
+		binding v1beta1.WorkspaceBinding
+		wantErr bool
+	}{{
+		name:    "writable volume",
+		binding: v1beta1.WorkspaceBinding{Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		wantErr: true,
+	}, {
+		name:    "read-only volume",
+		binding: v1beta1.WorkspaceBinding{Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{}, ReadOnly: true},
+	}, {
+		name:    "configmap",
+		binding: v1beta1.WorkspaceBinding{Name: "source", ConfigMap: &corev1.ConfigMapVolumeSource{}},
+	}, {
+		name:    "other workspace",
+		binding: v1beta1.WorkspaceBinding{Name: "cache", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &v1beta1.PipelineRun{Spec: v1beta1.PipelineRunSpec{Workspaces: []v1beta1.WorkspaceBinding{tc.binding}}}
+			if err := ValidateReadOnlyWorkspaces(state, pr); (err != nil) != tc.wantErr {
+				t.Errorf("Expected an error: %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestValidateServiceaccountMapping(t *testing.T) {
 	p := tb.Pipeline("pipelines", tb.PipelineSpec(
 		tb.PipelineTask("mytask1", "task",
@@ -2484,6 +3668,72 @@ func TestValidateServiceaccountMapping(t *testing.T) {
 	}
 }
 
+func TestValidateNetworkPolicies(t *testing.T) {
+	p := tb.Pipeline("pipelines", tb.PipelineSpec(
+		tb.PipelineTask("server", "task"),
+		tb.PipelineTask("test", "task"),
+		tb.FinalPipelineTask("report", "task"),
+	))
+	for _, tc := range []struct {
+		name    string
+		rule    v1beta1.NetworkPolicyRule
+		wantErr bool
+	}{{
+		name: "tasks and final tasks",
+		rule: v1beta1.NetworkPolicyRule{Name: "to-server", To: "server", From: []string{"test", "report"}},
+	}, {
+		name:    "unknown to",
+		rule:    v1beta1.NetworkPolicyRule{Name: "to-server", To: "servre", From: []string{"test"}},
+		wantErr: true,
+	}, {
+		name:    "unknown from",
+		rule:    v1beta1.NetworkPolicyRule{Name: "to-server", To: "server", From: []string{"test", "load-test"}},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("pipelinerun", tb.PipelineRunSpec("pipeline", func(spec *v1beta1.PipelineRunSpec) {
+				spec.NetworkPolicies = []v1beta1.NetworkPolicyRule{tc.rule}
+			}))
+			if err := ValidateNetworkPolicies(&p.Spec, pr); (err != nil) != tc.wantErr {
+				t.Errorf("Expected an error: %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTaskRefNames(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		p       *v1beta1.Pipeline
+		wantErr bool
+	}{{
+		name: "valid names",
+		p: tb.Pipeline("pipeline", tb.PipelineSpec(
+			tb.PipelineTask("build", "build-go"),
+			tb.FinalPipelineTask("report", "report"),
+		)),
+	}, {
+		name: "invalid task name",
+		p: tb.Pipeline("pipeline", tb.PipelineSpec(
+			tb.PipelineTask("build", "build-Go_1.16"),
+		)),
+		wantErr: true,
+	}, {
+		name: "invalid final task name",
+		p: tb.Pipeline("pipeline", tb.PipelineSpec(
+			tb.PipelineTask("build", "build-go"),
+			tb.FinalPipelineTask("report", "report/go"),
+		)),
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateTaskRefNames(&tc.p.Spec); (err != nil) != tc.wantErr {
+				t.Errorf("Expected an error: %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestIsBeforeFirstTaskRun_WithNotStartedTask(t *testing.T) {
 	if !noneStartedState.IsBeforeFirstTaskRun() {
 		t.Fatalf("Expected state to be before first taskrun")
@@ -19,9 +19,13 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/substitution"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // GetPipeline is a function used to retrieve Pipelines.
@@ -35,8 +39,12 @@ func GetPipelineData(ctx context.Context, pipelineRun *v1beta1.PipelineRun, getP
 	pipelineSpec := v1beta1.PipelineSpec{}
 	switch {
 	case pipelineRun.Spec.PipelineRef != nil && pipelineRun.Spec.PipelineRef.Name != "":
+		name, err := pipelineRefName(ctx, pipelineRun)
+		if err != nil {
+			return nil, nil, err
+		}
 		// Get related pipeline for pipelinerun
-		t, err := getPipeline(pipelineRun.Spec.PipelineRef.Name)
+		t, err := getPipeline(name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error when listing pipelines for pipelineRun %s: %w", pipelineRun.Name, err)
 		}
@@ -50,3 +58,21 @@ func GetPipelineData(ctx context.Context, pipelineRun *v1beta1.PipelineRun, getP
 	}
 	return &pipelineMeta, &pipelineSpec, nil
 }
+
+// pipelineRefName returns the name of the Pipeline pipelineRun references, with
+// the params of pipelineRun substituted when the alpha API fields are enabled.
+func pipelineRefName(ctx context.Context, pipelineRun *v1beta1.PipelineRun) (string, error) {
+	name := pipelineRun.Spec.PipelineRef.Name
+	if config.FromContextOrDefaults(ctx).FeatureFlags.EnableAPIFields != config.AlphaAPIFields {
+		return name, nil
+	}
+	stringReplacements, _ := paramReplacements(&v1beta1.PipelineSpec{}, pipelineRun)
+	resolved := substitution.ApplyReplacements(name, stringReplacements)
+	if resolved == name {
+		return name, nil
+	}
+	if errs := validation.IsDNS1123Subdomain(resolved); len(errs) != 0 {
+		return "", fmt.Errorf("pipelineRun %s references Pipeline %q, resolved from %q, which isn't a valid name: %s", pipelineRun.Name, resolved, name, strings.Join(errs, ","))
+	}
+	return resolved, nil
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -127,3 +128,63 @@ func TestGetPipelineSpec_Error(t *testing.T) {
 		t.Fatalf("Expected error when unable to find referenced Pipeline but got none")
 	}
 }
+
+func TestGetPipelineSpec_RefNameParams(t *testing.T) {
+	pipeline := &v1beta1.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "deploy-staging"}}
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypipelinerun"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "deploy-$(params.env)"},
+			Params:      []v1beta1.Param{{Name: "env", Value: v1beta1.NewArrayOrString("staging")}},
+		},
+	}
+	alpha := config.FromContextOrDefaults(context.Background())
+	alpha.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+	alphaCtx := config.ToContext(context.Background(), alpha)
+
+	for _, tc := range []struct {
+		desc     string
+		ctx      context.Context
+		env      string
+		wantName string
+		wantErr  bool
+	}{{
+		desc:     "params substituted",
+		ctx:      alphaCtx,
+		env:      "staging",
+		wantName: "deploy-staging",
+	}, {
+		desc:     "params not substituted without alpha fields",
+		ctx:      context.Background(),
+		env:      "staging",
+		wantName: "deploy-$(params.env)",
+	}, {
+		desc:    "invalid name",
+		ctx:     alphaCtx,
+		env:     "Staging/EU",
+		wantErr: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			pr := pr.DeepCopy()
+			pr.Spec.Params[0].Value = v1beta1.NewArrayOrString(tc.env)
+			var gotName string
+			gt := func(n string) (v1beta1.PipelineInterface, error) {
+				gotName = n
+				return pipeline, nil
+			}
+			_, _, err := GetPipelineData(tc.ctx, pr, gt)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error getting a Pipeline with an invalid name")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect error getting pipeline spec but got: %s", err)
+			}
+			if gotName != tc.wantName {
+				t.Errorf("Expected the Pipeline %q to be fetched, got %q", tc.wantName, gotName)
+			}
+		})
+	}
+}
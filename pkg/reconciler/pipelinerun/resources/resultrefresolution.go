@@ -21,6 +21,7 @@ import (
 	"sort"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	"knative.dev/pkg/apis"
 )
 
@@ -34,6 +35,9 @@ type ResolvedResultRef struct {
 	Value           v1beta1.ArrayOrString
 	ResultReference v1beta1.ResultRef
 	FromTaskRun     string
+	FromRun         string
+	// AliasOf is the name of the result when it was referenced by one of its aliases.
+	AliasOf string
 }
 
 // ResolveResultRefs resolves any ResultReference that are found in the target ResolvedPipelineRunTask
@@ -139,6 +143,38 @@ func removeDup(refs ResolvedResultRefs) ResolvedResultRefs {
 	return deduped
 }
 
+// pipelineTaskResultRefs returns the references to the results of other
+// PipelineTasks made by the params of pt, of its conditions, by its when
+// expressions and by the env values of its embedded steps.
+func pipelineTaskResultRefs(pt *v1beta1.PipelineTask) []*v1beta1.ResultRef {
+	var expressions []string
+	addParams := func(params []v1beta1.Param) {
+		for _, param := range params {
+			if e, ok := v1beta1.GetVarSubstitutionExpressionsForParam(param); ok {
+				expressions = append(expressions, e...)
+			}
+		}
+	}
+	for _, condition := range pt.Conditions {
+		addParams(condition.Params)
+	}
+	addParams(pt.Params)
+	if e, ok := v1beta1.GetVarSubstitutionExpressionsForWhenExpressions(pt.WhenExpressions); ok {
+		expressions = append(expressions, e...)
+	}
+	if e, ok := v1beta1.GetVarSubstitutionExpressionsForStepEnvs(pt.EmbeddedSteps()); ok {
+		expressions = append(expressions, e...)
+	}
+	return v1beta1.NewResultRefs(expressions)
+}
+
+// pipelineTaskWhenExpressionsResultRefs returns the references to the results of
+// other PipelineTasks made by the when expressions of pt.
+func pipelineTaskWhenExpressionsResultRefs(pt *v1beta1.PipelineTask) []*v1beta1.ResultRef {
+	expressions, _ := v1beta1.GetVarSubstitutionExpressionsForWhenExpressions(pt.WhenExpressions)
+	return v1beta1.NewResultRefs(expressions)
+}
+
 // convertParamsToResultRefs converts all params of the resolved pipeline run task
 func convertParamsToResultRefs(pipelineRunState PipelineRunState, target *ResolvedPipelineRunTask) (ResolvedResultRefs, error) {
 	var resolvedParams ResolvedResultRefs
@@ -156,9 +192,45 @@ func convertParamsToResultRefs(pipelineRunState PipelineRunState, target *Resolv
 	}
 	resolvedParams = append(resolvedParams, taskParamsRefs...)
 
+	whenRefs, err := convertWhenExpressions(target.PipelineTask.WhenExpressions, pipelineRunState, target.PipelineTask.Name)
+	if err != nil {
+		return nil, err
+	}
+	resolvedParams = append(resolvedParams, whenRefs...)
+
+	envRefs, err := convertStepEnvs(target.PipelineTask.EmbeddedSteps(), pipelineRunState, target.PipelineTask.Name)
+	if err != nil {
+		return nil, err
+	}
+	resolvedParams = append(resolvedParams, envRefs...)
+
 	return resolvedParams, nil
 }
 
+func convertStepEnvs(steps []v1beta1.Step, pipelineRunState PipelineRunState, name string) (ResolvedResultRefs, error) {
+	expressions, ok := v1beta1.GetVarSubstitutionExpressionsForStepEnvs(steps)
+	if !ok {
+		return nil, nil
+	}
+	resolvedResultRefs, err := extractResultRefs(expressions, pipelineRunState)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find result referenced by step env in %q: %w", name, err)
+	}
+	return resolvedResultRefs, nil
+}
+
+func convertWhenExpressions(wes v1beta1.WhenExpressions, pipelineRunState PipelineRunState, name string) (ResolvedResultRefs, error) {
+	expressions, ok := v1beta1.GetVarSubstitutionExpressionsForWhenExpressions(wes)
+	if !ok {
+		return nil, nil
+	}
+	resolvedResultRefs, err := extractResultRefs(expressions, pipelineRunState)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find result referenced by when expressions in %q: %w", name, err)
+	}
+	return resolvedResultRefs, nil
+}
+
 func convertParams(params []v1beta1.Param, pipelineRunState PipelineRunState, name string) (ResolvedResultRefs, error) {
 	var resolvedParams ResolvedResultRefs
 	for _, param := range params {
@@ -183,11 +255,28 @@ func convertPipelineResultToResultRefs(pipelineStatus v1beta1.PipelineRunStatus,
 }
 
 func resolveResultRef(pipelineState PipelineRunState, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	// A PipelineTask skipped by its when expressions with the Task scope, or which
+	// failed with continueOnFailure, provides the default values of its results to
+	// the PipelineTasks depending on it.
+	if referenced := pipelineState.ToMap()[resultRef.PipelineTask]; referenced != nil && (referenced.skippedAlone() || referenced.IsNonFatalFailure()) {
+		if value, ok := referenced.resultDefault(resultRef.Result); ok {
+			return &ResolvedResultRef{
+				Value: v1beta1.ArrayOrString{
+					Type:      v1beta1.ParamTypeString,
+					StringVal: value,
+				},
+				ResultReference: *resultRef,
+			}, nil
+		}
+	}
+	if referenced := pipelineState.ToMap()[resultRef.PipelineTask]; referenced != nil && referenced.CustomTask {
+		return resolveResultRefFromRun(referenced, resultRef)
+	}
 	referencedTaskRun, err := getReferencedTaskRun(pipelineState, resultRef)
 	if err != nil {
 		return nil, err
 	}
-	result, err := findTaskResultForParam(referencedTaskRun, resultRef)
+	result, aliasOf, err := findTaskResult(&referencedTaskRun.Status, resultRef)
 	if err != nil {
 		return nil, err
 	}
@@ -198,16 +287,50 @@ func resolveResultRef(pipelineState PipelineRunState, resultRef *v1beta1.ResultR
 		},
 		FromTaskRun:     referencedTaskRun.Name,
 		ResultReference: *resultRef,
+		AliasOf:         aliasOf,
+	}, nil
+}
+
+// resolveResultRefFromRun resolves resultRef from the results of the Run of the custom task t.
+func resolveResultRefFromRun(t *ResolvedPipelineRunTask, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	if t.Run == nil || t.IsFailure() {
+		return nil, fmt.Errorf("could not find successful run for task %q", t.PipelineTask.Name)
+	}
+	result, err := findRunResult(&t.Run.Status, resultRef)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolvedResultRef{
+		Value: v1beta1.ArrayOrString{
+			Type:      v1beta1.ParamTypeString,
+			StringVal: result.Value,
+		},
+		FromRun:         t.Run.Name,
+		ResultReference: *resultRef,
 	}, nil
 }
 
 func resolveResultRefForPipelineResult(pipelineStatus v1beta1.PipelineRunStatus, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	if runStatus, runName, ok := getRunStatus(pipelineStatus, resultRef.PipelineTask); ok {
+		result, err := findRunResult(runStatus, resultRef)
+		if err != nil {
+			return nil, err
+		}
+		return &ResolvedResultRef{
+			Value: v1beta1.ArrayOrString{
+				Type:      v1beta1.ParamTypeString,
+				StringVal: result.Value,
+			},
+			FromRun:         runName,
+			ResultReference: *resultRef,
+		}, nil
+	}
 	taskRunStatus, taskRunName, err := getTaskRunStatus(pipelineStatus, resultRef.PipelineTask)
 
 	if err != nil {
 		return nil, err
 	}
-	result, err := findTaskResultForPipelineResult(taskRunStatus, resultRef)
+	result, aliasOf, err := findTaskResult(taskRunStatus, resultRef)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +341,7 @@ func resolveResultRefForPipelineResult(pipelineStatus v1beta1.PipelineRunStatus,
 		},
 		FromTaskRun:     taskRunName,
 		ResultReference: *resultRef,
+		AliasOf:         aliasOf,
 	}, nil
 }
 
@@ -247,22 +371,46 @@ func getTaskRunStatus(pipelineStatus v1beta1.PipelineRunStatus, pipelineTaskName
 	return nil, "", fmt.Errorf("could not find task run status for task %q referenced by result", pipelineTaskName)
 }
 
-func findTaskResultForPipelineResult(taskStatus *v1beta1.TaskRunStatus, reference *v1beta1.ResultRef) (*v1beta1.TaskRunResult, error) {
-	results := taskStatus.TaskRunStatusFields.TaskRunResults
-	for _, result := range results {
-		if result.Name == reference.Result {
-			return &result, nil
+// getRunStatus returns the status of the Run of the custom task pipelineTaskName and
+// the name of the Run, if pipelineStatus has one.
+func getRunStatus(pipelineStatus v1beta1.PipelineRunStatus, pipelineTaskName string) (*runv1alpha1.RunStatus, string, bool) {
+	for key, run := range pipelineStatus.PipelineRunStatusFields.Runs {
+		if run.PipelineTaskName == pipelineTaskName && run.Status != nil {
+			return run.Status, key, true
 		}
 	}
-	return nil, fmt.Errorf("Could not find result with name %s for task run %s", reference.Result, reference.PipelineTask)
+	return nil, "", false
 }
 
-func findTaskResultForParam(taskRun *v1beta1.TaskRun, reference *v1beta1.ResultRef) (*v1beta1.TaskRunResult, error) {
-	results := taskRun.Status.TaskRunStatusFields.TaskRunResults
-	for _, result := range results {
+// findRunResult returns the result of the Run with the given status which is
+// referenced by reference.
+func findRunResult(runStatus *runv1alpha1.RunStatus, reference *v1beta1.ResultRef) (*runv1alpha1.RunResult, error) {
+	for _, result := range runStatus.Results {
 		if result.Name == reference.Result {
 			return &result, nil
 		}
 	}
-	return nil, fmt.Errorf("Could not find result with name %s for task run %s", reference.Result, reference.PipelineTask)
+	return nil, fmt.Errorf("Could not find result with name %s for run %s", reference.Result, reference.PipelineTask)
+}
+
+// findTaskResult returns the result of the TaskRun with the given status which is
+// referenced by reference. When the result is referenced by one of the aliases declared
+// in the TaskSpec of the TaskRun, the name of the result is returned as well.
+func findTaskResult(taskStatus *v1beta1.TaskRunStatus, reference *v1beta1.ResultRef) (*v1beta1.TaskRunResult, string, error) {
+	name, aliasOf := reference.Result, ""
+	if taskStatus.TaskSpec != nil {
+		for _, r := range taskStatus.TaskSpec.Results {
+			for _, alias := range r.Aliases {
+				if alias == reference.Result {
+					name, aliasOf = r.Name, r.Name
+				}
+			}
+		}
+	}
+	for _, result := range taskStatus.TaskRunStatusFields.TaskRunResults {
+		if result.Name == name {
+			return &result, aliasOf, nil
+		}
+	}
+	return nil, "", fmt.Errorf("Could not find result with name %s for task run %s", reference.Result, reference.PipelineTask)
 }
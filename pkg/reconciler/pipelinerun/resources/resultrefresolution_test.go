@@ -8,10 +8,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 )
 
@@ -323,6 +327,28 @@ func TestResolveResultRefs(t *testing.T) {
 					},
 				},
 			},
+		}, {
+			PipelineTask: &v1beta1.PipelineTask{
+				Name: "cTask",
+				TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: &v1beta1.TaskSpec{
+					Steps: []v1beta1.Step{{Container: corev1.Container{
+						Name:  "step",
+						Image: "busybox",
+						Env:   []corev1.EnvVar{{Name: "A_RESULT", Value: "$(tasks.aTask.results.aResult)"}},
+					}}},
+				}},
+			},
+		}, {
+			PipelineTask: &v1beta1.PipelineTask{
+				Name: "dTask",
+				TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: &v1beta1.TaskSpec{
+					Steps: []v1beta1.Step{{Container: corev1.Container{
+						Name:  "step",
+						Image: "busybox",
+						Env:   []corev1.EnvVar{{Name: "MISSING", Value: "$(tasks.aTask.results.missingResult)"}},
+					}}},
+				}},
+			},
 		},
 	}
 
@@ -332,6 +358,40 @@ func TestResolveResultRefs(t *testing.T) {
 		want    ResolvedResultRefs
 		wantErr bool
 	}{
+		{
+			name: "Test successful result references resolution in step env",
+			args: args{
+				pipelineRunState: pipelineRunState,
+				targets: PipelineRunState{
+					pipelineRunState[2],
+				},
+			},
+			want: ResolvedResultRefs{
+				{
+					Value: v1beta1.ArrayOrString{
+						Type:      v1beta1.ParamTypeString,
+						StringVal: "aResultValue",
+					},
+					ResultReference: v1beta1.ResultRef{
+						PipelineTask: "aTask",
+						Result:       "aResult",
+					},
+					FromTaskRun: "aTaskRun",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Test unsuccessful result references resolution in step env with missing result",
+			args: args{
+				pipelineRunState: pipelineRunState,
+				targets: PipelineRunState{
+					pipelineRunState[3],
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "Test successful result references resolution",
 			args: args{
@@ -384,6 +444,111 @@ func TestResolveResultRefs(t *testing.T) {
 	}
 }
 
+func TestResolveResultRefs_Alias(t *testing.T) {
+	taskRun := tb.TaskRun("aTaskRun", tb.TaskRunStatus(
+		tb.TaskRunResult("aResult", "aResultValue"),
+	))
+	taskRun.Status.TaskSpec = &v1beta1.TaskSpec{
+		Results: []v1beta1.TaskResult{{Name: "aResult", Aliases: []string{"oldResult"}}},
+	}
+	pipelineRunState := PipelineRunState{{
+		TaskRunName: "aTaskRun",
+		TaskRun:     taskRun,
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "aTask",
+			TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "bTask",
+			TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+			Params: []v1beta1.Param{{
+				Name:  "bParam",
+				Value: v1beta1.NewArrayOrString("$(tasks.aTask.results.oldResult)"),
+			}},
+		},
+	}}
+
+	got, err := ResolveResultRefs(pipelineRunState, PipelineRunState{pipelineRunState[1]})
+	if err != nil {
+		t.Fatalf("ResolveResultRefs() = %v", err)
+	}
+	want := ResolvedResultRefs{{
+		Value:           v1beta1.NewArrayOrString("aResultValue"),
+		ResultReference: v1beta1.ResultRef{PipelineTask: "aTask", Result: "oldResult"},
+		FromTaskRun:     "aTaskRun",
+		AliasOf:         "aResult",
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ResolveResultRefs() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestResolveResultRefs_Run(t *testing.T) {
+	run := &v1alpha1.Run{
+		ObjectMeta: metav1.ObjectMeta{Name: "aRun"},
+		Status: v1alpha1.RunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+			RunStatusFields: v1alpha1.RunStatusFields{
+				Results: []runv1alpha1.RunResult{{Name: "aResult", Value: "aResultValue"}},
+			},
+		},
+	}
+	pipelineRunState := PipelineRunState{{
+		CustomTask: true,
+		RunName:    "aRun",
+		Run:        run,
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "aTask",
+			TaskRef: &v1beta1.TaskRef{APIVersion: "example.dev/v0", Kind: "Example"},
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "bTask",
+			TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+			Params: []v1beta1.Param{{
+				Name:  "bParam",
+				Value: v1beta1.NewArrayOrString("$(tasks.aTask.results.aResult)"),
+			}},
+		},
+	}}
+
+	got, err := ResolveResultRefs(pipelineRunState, PipelineRunState{pipelineRunState[1]})
+	if err != nil {
+		t.Fatalf("ResolveResultRefs() = %v", err)
+	}
+	want := ResolvedResultRefs{{
+		Value:           v1beta1.NewArrayOrString("aResultValue"),
+		ResultReference: v1beta1.ResultRef{PipelineTask: "aTask", Result: "aResult"},
+		FromRun:         "aRun",
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ResolveResultRefs() %s", diff.PrintWantGot(d))
+	}
+
+	run.Status.Conditions[0].Status = corev1.ConditionFalse
+	if _, err := ResolveResultRefs(pipelineRunState, PipelineRunState{pipelineRunState[1]}); err == nil {
+		t.Error("ResolveResultRefs() did not return an error for a result of a failed Run")
+	}
+
+	status := v1beta1.PipelineRunStatus{
+		PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			Runs: map[string]*v1beta1.PipelineRunRunStatus{
+				"aRun": {PipelineTaskName: "aTask", Status: &run.Status},
+			},
+		},
+	}
+	got = ResolvePipelineResultRefs(status, []v1beta1.PipelineResult{{Name: "from-a", Value: "$(tasks.aTask.results.aResult)"}})
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ResolvePipelineResultRefs() %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestResolvePipelineResultRefs(t *testing.T) {
 	type args struct {
 		status          v1beta1.PipelineRunStatus
@@ -0,0 +1,260 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"container/list"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/server"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// statsRunsPerPipeline is the number of completed PipelineRuns of each Pipeline
+	// kept to compute its stats.
+	statsRunsPerPipeline = 100
+
+	// statsPipelines is the number of Pipelines whose stats are kept. The stats of
+	// the Pipeline whose PipelineRuns were the least recently recorded are dropped
+	// to keep the stats of a new one.
+	statsPipelines = 1000
+
+	// statsPath is the path the stats of Pipelines are served on.
+	statsPath = "/stats"
+)
+
+// pipelineKey identifies the Pipeline of a PipelineRun.
+type pipelineKey struct {
+	namespace string
+	pipeline  string
+}
+
+// runDurations holds how long a completed PipelineRun and each of its pipeline tasks took.
+type runDurations struct {
+	name     string
+	pipeline time.Duration
+	tasks    map[string]time.Duration
+}
+
+// runRing is a fixed-size ring buffer of the durations of the last completed PipelineRuns.
+type runRing struct {
+	key  pipelineKey
+	runs []runDurations
+	next int
+}
+
+// pipelineStats keeps the durations of the last completed PipelineRuns of each Pipeline,
+// for up to maxPipelines Pipelines.
+type pipelineStats struct {
+	size         int
+	maxPipelines int
+	kubeClient   kubernetes.Interface
+
+	mu    sync.Mutex
+	rings map[pipelineKey]*list.Element
+	// lru holds the *runRing of each Pipeline, from the most to the least recently recorded.
+	lru *list.List
+}
+
+func newPipelineStats(size, maxPipelines int, kubeClient kubernetes.Interface) *pipelineStats {
+	return &pipelineStats{
+		size:         size,
+		maxPipelines: maxPipelines,
+		kubeClient:   kubeClient,
+		rings:        map[pipelineKey]*list.Element{},
+		lru:          list.New(),
+	}
+}
+
+// durationPercentiles holds the percentiles of durations, in seconds.
+type durationPercentiles struct {
+	Runs int     `json:"runs"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+}
+
+// pipelineStatsResponse is the JSON document describing the stats of a Pipeline.
+type pipelineStatsResponse struct {
+	Namespace string                         `json:"namespace"`
+	Pipeline  string                         `json:"pipeline"`
+	Duration  durationPercentiles            `json:"duration"`
+	Tasks     map[string]durationPercentiles `json:"tasks"`
+}
+
+// pipelineName returns the name of the Pipeline of pr, consistently with its metrics.
+func pipelineName(pr *v1beta1.PipelineRun) string {
+	if pr.Spec.PipelineRef != nil && pr.Spec.PipelineRef.Name != "" {
+		return pr.Spec.PipelineRef.Name
+	}
+	return "anonymous"
+}
+
+// record adds the durations of pr and of its pipeline tasks to the stats of its Pipeline,
+// unless pr isn't complete or was already recorded.
+func (s *pipelineStats) record(pr *v1beta1.PipelineRun) {
+	if s == nil || !pr.IsDone() || pr.Status.StartTime == nil || pr.Status.CompletionTime == nil {
+		return
+	}
+	run := runDurations{
+		name:     pr.Name,
+		pipeline: pr.Status.CompletionTime.Sub(pr.Status.StartTime.Time),
+		tasks:    map[string]time.Duration{},
+	}
+	for _, tr := range pr.Status.TaskRuns {
+		if tr.Status == nil || tr.Status.StartTime == nil || tr.Status.CompletionTime == nil {
+			continue
+		}
+		run.tasks[tr.PipelineTaskName] = tr.Status.CompletionTime.Sub(tr.Status.StartTime.Time)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := pipelineKey{namespace: pr.Namespace, pipeline: pipelineName(pr)}
+	var ring *runRing
+	if e, ok := s.rings[key]; ok {
+		s.lru.MoveToFront(e)
+		ring = e.Value.(*runRing)
+	} else {
+		if s.lru.Len() >= s.maxPipelines {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.rings, oldest.Value.(*runRing).key)
+		}
+		ring = &runRing{key: key}
+		s.rings[key] = s.lru.PushFront(ring)
+	}
+	// Done PipelineRuns are reconciled again on resyncs, they must only be counted once.
+	for _, r := range ring.runs {
+		if r.name == run.name {
+			return
+		}
+	}
+	if len(ring.runs) < s.size {
+		ring.runs = append(ring.runs, run)
+		return
+	}
+	ring.runs[ring.next] = run
+	ring.next = (ring.next + 1) % s.size
+}
+
+// lastRuns returns up to the last n runs recorded for key, or all of them if n is 0.
+func (s *pipelineStats) lastRuns(key pipelineKey, n int) []runDurations {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.rings[key]
+	if !ok {
+		return nil
+	}
+	ring := e.Value.(*runRing)
+	// The oldest run is at ring.next once the ring is full.
+	runs := append(append([]runDurations{}, ring.runs[ring.next:]...), ring.runs[:ring.next]...)
+	if n > 0 && n < len(runs) {
+		runs = runs[len(runs)-n:]
+	}
+	return runs
+}
+
+// percentiles returns the 50th, 95th and 99th percentiles of durations using the
+// nearest-rank method.
+func percentiles(durations []time.Duration) durationPercentiles {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(durations)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return durations[i].Seconds()
+	}
+	return durationPercentiles{
+		Runs: len(durations),
+		P50:  rank(50),
+		P95:  rank(95),
+		P99:  rank(99),
+	}
+}
+
+// ServeHTTP returns the stats of the Pipeline given by the "namespace" and "pipeline"
+// query parameters, computed over its last "last" completed PipelineRuns if set. The
+// bearer token of r must be allowed to list the PipelineRuns of the namespace.
+func (s *pipelineStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	key := pipelineKey{namespace: query.Get("namespace"), pipeline: query.Get("pipeline")}
+	if key.namespace == "" || key.pipeline == "" {
+		http.Error(w, "the namespace and pipeline query parameters are required", http.StatusBadRequest)
+		return
+	}
+	if code, err := server.Authorize(s.kubeClient, r, authorizationv1.ResourceAttributes{
+		Namespace: key.namespace,
+		Verb:      "list",
+		Group:     pipeline.GroupName,
+		Resource:  "pipelineruns",
+	}); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	var last int
+	if l := query.Get("last"); l != "" {
+		var err error
+		if last, err = strconv.Atoi(l); err != nil || last < 1 {
+			http.Error(w, "the last query parameter must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	runs := s.lastRuns(key, last)
+	if len(runs) == 0 {
+		http.Error(w, "no completed PipelineRun recorded for this Pipeline", http.StatusNotFound)
+		return
+	}
+	var durations []time.Duration
+	taskDurations := map[string][]time.Duration{}
+	for _, run := range runs {
+		durations = append(durations, run.pipeline)
+		for task, d := range run.tasks {
+			taskDurations[task] = append(taskDurations[task], d)
+		}
+	}
+	resp := pipelineStatsResponse{
+		Namespace: key.namespace,
+		Pipeline:  key.pipeline,
+		Duration:  percentiles(durations),
+		Tasks:     map[string]durationPercentiles{},
+	}
+	for task, d := range taskDurations {
+		resp.Tasks[task] = percentiles(d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
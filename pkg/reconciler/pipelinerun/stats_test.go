@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+// statsKubeClient returns a kube client authenticating the "alice" token, which may
+// list the PipelineRuns of the foo namespace.
+func statsKubeClient() kubernetes.Interface {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "alice" {
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "alice"}}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Namespace == "foo" && attrs.Verb == "list" && attrs.Resource == "pipelineruns"
+		return true, review, nil
+	})
+	return kubeClient
+}
+
+// completedPipelineRun returns a successful PipelineRun of pipeline which took
+// seconds, and whose "build" task took half of it.
+func completedPipelineRun(name, pipeline string, seconds int) *v1beta1.PipelineRun {
+	start := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Duration(seconds) * time.Second)
+	buildEnd := start.Add(time.Duration(seconds) * time.Second / 2)
+	return &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: pipeline},
+		},
+		Status: v1beta1.PipelineRunStatus{
+			Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			}}},
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				StartTime:      &metav1.Time{Time: start},
+				CompletionTime: &metav1.Time{Time: end},
+				TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{
+					name + "-build": {
+						PipelineTaskName: "build",
+						Status: &v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+							StartTime:      &metav1.Time{Time: start},
+							CompletionTime: &metav1.Time{Time: buildEnd},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getStats(t *testing.T, s *pipelineStats, query string) (int, pipelineStatsResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "https://controller"+statsPath+"?"+query, nil)
+	req.Header.Set("Authorization", "Bearer alice")
+	s.ServeHTTP(rec, req)
+	var resp pipelineStatsResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode stats: %v", err)
+		}
+	}
+	return rec.Code, resp
+}
+
+func TestPipelineStats(t *testing.T) {
+	s := newPipelineStats(statsRunsPerPipeline, statsPipelines, statsKubeClient())
+	for i := 1; i <= 100; i++ {
+		s.record(completedPipelineRun(fmt.Sprintf("run-%d", i), "deploy", i*10))
+	}
+	// Recording a PipelineRun again, as happens on resyncs, doesn't count it twice.
+	s.record(completedPipelineRun("run-1", "deploy", 10))
+	// Running PipelineRuns and other Pipelines aren't part of the stats.
+	running := completedPipelineRun("running", "deploy", 1000)
+	running.Status.Conditions[0].Status = corev1.ConditionUnknown
+	s.record(running)
+	s.record(completedPipelineRun("other", "other", 1000))
+
+	code, got := getStats(t, s, "namespace=foo&pipeline=deploy")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	want := pipelineStatsResponse{
+		Namespace: "foo",
+		Pipeline:  "deploy",
+		Duration:  durationPercentiles{Runs: 100, P50: 500, P95: 950, P99: 990},
+		Tasks: map[string]durationPercentiles{
+			"build": {Runs: 100, P50: 250, P95: 475, P99: 495},
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+
+	code, got = getStats(t, s, "namespace=foo&pipeline=deploy&last=10")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	if d := cmp.Diff(durationPercentiles{Runs: 10, P50: 950, P95: 1000, P99: 1000}, got.Duration); d != "" {
+		t.Errorf("Diff of the last 10 runs %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineStats_RingBuffer(t *testing.T) {
+	s := newPipelineStats(3, statsPipelines, statsKubeClient())
+	for i := 1; i <= 5; i++ {
+		s.record(completedPipelineRun(fmt.Sprintf("run-%d", i), "deploy", i))
+	}
+
+	var got []string
+	for _, r := range s.lastRuns(pipelineKey{namespace: "foo", pipeline: "deploy"}, 0) {
+		got = append(got, r.name)
+	}
+	if d := cmp.Diff([]string{"run-3", "run-4", "run-5"}, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineStats_LeastRecentlyRecorded(t *testing.T) {
+	s := newPipelineStats(statsRunsPerPipeline, 2, statsKubeClient())
+	s.record(completedPipelineRun("deploy-1", "deploy", 10))
+	s.record(completedPipelineRun("build-1", "build", 10))
+	// deploy is now the most recently recorded Pipeline, so build is dropped for test
+	s.record(completedPipelineRun("deploy-2", "deploy", 10))
+	s.record(completedPipelineRun("test-1", "test", 10))
+
+	for pipeline, want := range map[string]int{"deploy": 2, "build": 0, "test": 1} {
+		if got := len(s.lastRuns(pipelineKey{namespace: "foo", pipeline: pipeline}, 0)); got != want {
+			t.Errorf("Expected %d runs of %s, got %d", want, pipeline, got)
+		}
+	}
+	if len(s.rings) != 2 || s.lru.Len() != 2 {
+		t.Errorf("Expected the stats of 2 Pipelines, got %d and %d", len(s.rings), s.lru.Len())
+	}
+}
+
+func TestPipelineStats_Errors(t *testing.T) {
+	s := newPipelineStats(statsRunsPerPipeline, statsPipelines, statsKubeClient())
+	s.record(completedPipelineRun("run", "deploy", 10))
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{{
+		query: "pipeline=deploy",
+		want:  http.StatusBadRequest,
+	}, {
+		query: "namespace=foo&pipeline=deploy&last=none",
+		want:  http.StatusBadRequest,
+	}, {
+		query: "namespace=foo&pipeline=unknown",
+		want:  http.StatusNotFound,
+	}, {
+		query: "namespace=bar&pipeline=deploy",
+		want:  http.StatusForbidden,
+	}} {
+		t.Run(tc.query, func(t *testing.T) {
+			if code, _ := getStats(t, s, tc.query); code != tc.want {
+				t.Errorf("Expected status %d, got %d", tc.want, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server serves the HTTP endpoints of the controller next to its
+// metrics, such as the stats of Pipelines and the logs of TaskRuns. They are
+// only served over TLS, as their callers authenticate with bearer tokens.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// PortEnvKey is the environment variable holding the port the endpoints of
+	// the controller are served on. They aren't served if it isn't set.
+	PortEnvKey = "CONTROLLER_HTTPS_PORT"
+
+	// TLSDirEnvKey is the environment variable holding the directory of the
+	// tls.crt and tls.key files of the certificate the endpoints are served with.
+	// They aren't served if the certificate isn't there.
+	TLSDirEnvKey = "CONTROLLER_HTTPS_TLS_DIR"
+)
+
+// Server holds the endpoints of the controller.
+type Server struct {
+	mux *http.ServeMux
+}
+
+// New returns a Server without endpoints.
+func New() *Server {
+	return &Server{mux: http.NewServeMux()}
+}
+
+// Handle registers the handler of the endpoints matching pattern, as
+// http.ServeMux does.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve serves the endpoints on the port and with the certificate set by the
+// environment of the controller, until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	port, dir := os.Getenv(PortEnvKey), os.Getenv(TLSDirEnvKey)
+	if port == "" || dir == "" {
+		return
+	}
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if _, err := os.Stat(certFile); err != nil {
+		logger.Infof("Not serving the endpoints of the controller without a certificate: %v", err)
+		return
+	}
+	server := &http.Server{Addr: ":" + port, Handler: s}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	logger.Infof("Serving the endpoints of the controller on port %s", port)
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
+		logger.Errorf("Failed to serve the endpoints of the controller: %v", err)
+	}
+}
+
+type serverKey struct{}
+
+// WithServer returns a copy of ctx holding s, which the controllers register
+// their endpoints on.
+func WithServer(ctx context.Context, s *Server) context.Context {
+	return context.WithValue(ctx, serverKey{}, s)
+}
+
+// FromContext returns the Server held by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Server {
+	s, _ := ctx.Value(serverKey{}).(*Server)
+	return s
+}
+
+// Authorize returns an error along with its HTTP status code unless the bearer
+// token of r authenticates a user allowed to access the resource described by
+// attrs. Bearer tokens sent in cleartext are refused.
+func Authorize(kubeClient kubernetes.Interface, r *http.Request, attrs authorizationv1.ResourceAttributes) (int, error) {
+	header := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == "" || token == header {
+		return http.StatusUnauthorized, errors.New("a bearer token is required")
+	}
+	if r.TLS == nil {
+		return http.StatusForbidden, errors.New("bearer tokens are only accepted over TLS")
+	}
+	review, err := kubeClient.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !review.Status.Authenticated {
+		return http.StatusUnauthorized, errors.New("the bearer token isn't valid")
+	}
+	user := review.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	access, err := kubeClient.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attrs,
+			User:               user.Username,
+			UID:                user.UID,
+			Groups:             user.Groups,
+			Extra:              extra,
+		},
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !access.Status.Allowed {
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+		return http.StatusForbidden, fmt.Errorf("%s isn't allowed to %s %s in namespace %s", user.Username, attrs.Verb, resource, attrs.Namespace)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	// The "alice" token may list the pipelineruns of the foo namespace, the
+	// "bob" token may not.
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "alice", "bob":
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: review.Spec.Token}}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "alice" && attrs.Namespace == "foo" &&
+			attrs.Verb == "list" && attrs.Resource == "pipelineruns"
+		return true, review, nil
+	})
+
+	attrs := authorizationv1.ResourceAttributes{Namespace: "foo", Verb: "list", Group: "tekton.dev", Resource: "pipelineruns"}
+	for _, tc := range []struct {
+		name     string
+		url      string
+		header   string
+		wantCode int
+	}{{
+		name:     "allowed",
+		url:      "https://controller/stats",
+		header:   "Bearer alice",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "no token",
+		url:      "https://controller/stats",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "not a bearer token",
+		url:      "https://controller/stats",
+		header:   "Basic alice",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "cleartext",
+		url:      "http://controller/stats",
+		header:   "Bearer alice",
+		wantCode: http.StatusForbidden,
+	}, {
+		name:     "invalid token",
+		url:      "https://controller/stats",
+		header:   "Bearer mallory",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "not allowed",
+		url:      "https://controller/stats",
+		header:   "Bearer bob",
+		wantCode: http.StatusForbidden,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			code, err := Authorize(kubeClient, r, attrs)
+			if code != tc.wantCode {
+				t.Errorf("Expected status %d, got %d: %v", tc.wantCode, code, err)
+			}
+			if (err == nil) != (tc.wantCode == http.StatusOK) {
+				t.Errorf("Unexpected error %v for status %d", err, code)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if s := FromContext(context.Background()); s != nil {
+		t.Errorf("Expected no server, got %v", s)
+	}
+	s := New()
+	if got := FromContext(WithServer(context.Background(), s)); got != s {
+		t.Errorf("Expected the server of the context, got %v", got)
+	}
+}
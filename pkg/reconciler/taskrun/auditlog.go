@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/auditlog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// ReasonAuditLogFailed indicates that a transition of a TaskRun couldn't be
+// recorded in its audit log
+const ReasonAuditLogFailed = "AuditLogFailed"
+
+// recordAuditLog appends the transition of tr from the before to the after
+// Succeeded condition to its audit log ConfigMap, which is created on the first
+// transition. The ConfigMap isn't owned by tr, so that the log outlives it.
+// An existing ConfigMap which isn't labelled as the audit log of tr isn't
+// appended to. Failing to record the transition doesn't affect tr.
+func (c *Reconciler) recordAuditLog(ctx context.Context, tr *v1beta1.TaskRun, before, after *apis.Condition) {
+	if !tr.Spec.AuditLog || after == nil || equality.Semantic.DeepEqual(before, after) {
+		return
+	}
+	entry := auditlog.NewEntry(metav1.NewTime(c.clock.Now()), before, after)
+	name := auditlog.ConfigMapName(tr.Name)
+	configMaps := c.KubeClientSet.CoreV1().ConfigMaps(tr.Namespace)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: tr.Namespace,
+					Labels:    map[string]string{auditlog.LabelKey: tr.Name},
+				},
+			}
+			if err := auditlog.Append(cm, entry); err != nil {
+				return err
+			}
+			_, err = configMaps.Create(cm)
+			if errors.IsAlreadyExists(err) {
+				// Retry with the ConfigMap created concurrently
+				return errors.NewConflict(corev1.Resource("configmaps"), name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		// The webhook only guards the ConfigMaps labelled as audit logs
+		if cm.Labels[auditlog.LabelKey] != tr.Name {
+			return fmt.Errorf("configmap %q exists without the %s: %s label", name, auditlog.LabelKey, tr.Name)
+		}
+		if err := auditlog.Append(cm, entry); err != nil {
+			return err
+		}
+		_, err = configMaps.Update(cm)
+		return err
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Failed to record the transition of taskrun %q in audit log %q: %v", tr.Name, name, err)
+		controller.GetEventRecorder(ctx).Eventf(tr, corev1.EventTypeWarning, ReasonAuditLogFailed,
+			"Failed to record the transition in audit log %q: %v", name, err)
+	}
+}
@@ -30,8 +30,12 @@ import (
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/resource/injection/informers/resource/v1alpha1/pipelineresource"
 	"github.com/tektoncd/pipeline/pkg/pod"
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
+	"github.com/tektoncd/pipeline/pkg/reconciler/server"
+	"github.com/tektoncd/pipeline/pkg/reconciler/ttl"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
+	"github.com/tektoncd/pipeline/pkg/system"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/cache"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
@@ -42,7 +46,7 @@ import (
 )
 
 // NewController instantiates a new controller.Impl from knative.dev/pkg/controller
-func NewController(namespace string, images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+func NewController(namespace string, images pipeline.Images, clock clock.Clock) func(context.Context, configmap.Watcher) *controller.Impl {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		logger := logging.FromContext(ctx)
 		kubeclientset := kubeclient.Get(ctx)
@@ -53,6 +57,7 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		podInformer := podinformer.Get(ctx)
 		resourceInformer := resourceinformer.Get(ctx)
 		timeoutHandler := timeout.NewHandler(ctx.Done(), logger)
+		ttlHandler := ttl.NewHandler(clock)
 		metrics, err := NewRecorder()
 		if err != nil {
 			logger.Errorf("Failed to create taskrun metrics recorder %v", err)
@@ -71,11 +76,17 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			taskLister:        taskInformer.Lister(),
 			clusterTaskLister: clusterTaskInformer.Lister(),
 			resourceLister:    resourceInformer.Lister(),
+			podLister:         podInformer.Lister(),
 			timeoutHandler:    timeoutHandler,
+			ttlHandler:        ttlHandler,
+			clock:             clock,
 			cloudEventClient:  cloudeventclient.Get(ctx),
 			metrics:           metrics,
 			entrypointCache:   entrypointCache,
+			digestCache:       pod.NewDigestCache(kubeclientset, system.GetNamespace()),
 			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
+			podMetrics:        &metricsServerSource{client: kubeclientset.CoreV1().RESTClient()},
+			podLogs:           &kubeLogsSource{client: kubeclientset},
 		}
 		impl := taskrunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			configStore := config.NewStore(logger.Named("config-store"))
@@ -89,6 +100,8 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 
 		timeoutHandler.SetTaskRunCallbackFunc(impl.Enqueue)
 		timeoutHandler.CheckTimeouts(namespace, kubeclientset, pipelineclientset)
+		ttlHandler.SetEnqueueAfterFunc(impl.EnqueueAfter)
+		c.enqueueAfter = impl.EnqueueAfter
 
 		logger.Info("Setting up event handlers")
 		taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -104,6 +117,9 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		})
 
 		go metrics.ReportRunningTaskRuns(ctx, taskRunInformer.Lister())
+		if s := server.FromContext(ctx); s != nil {
+			s.Handle(logsPath, &logServer{kubeClient: kubeclientset, taskRunLister: taskRunInformer.Lister(), logs: &kubeLogsSource{client: kubeclientset}})
+		}
 
 		return impl
 	}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonEphemeralContainerAdded indicates that the ephemeral container of the
+	// debug configuration of a TaskRun was injected into its Pod
+	ReasonEphemeralContainerAdded = "EphemeralContainerAdded"
+	// ReasonEphemeralContainerFailed indicates that the ephemeral container of the
+	// debug configuration of a TaskRun couldn't be injected into its Pod
+	ReasonEphemeralContainerFailed = "EphemeralContainerFailed"
+)
+
+// addDebugContainer injects the ephemeral container of the debug configuration
+// of tr into its Pod while it is running, unless the Pod already has an
+// ephemeral container with its name. Failing to inject it doesn't affect tr, it
+// is attempted again the next time tr is reconciled.
+func (c *Reconciler) addDebugContainer(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod) {
+	if tr.Spec.Debug == nil || tr.Spec.Debug.AddEphemeralContainer == nil || tr.IsDone() || pod.Status.Phase != corev1.PodRunning {
+		return
+	}
+	container := tr.Spec.Debug.AddEphemeralContainer
+	for _, ec := range pod.Spec.EphemeralContainers {
+		if ec.Name == container.Name {
+			return
+		}
+	}
+
+	// The ephemeral containers of the Pod are replaced by the given ones, which
+	// must include the existing ones.
+	ecs := &corev1.EphemeralContainers{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			ResourceVersion: pod.ResourceVersion,
+		},
+		EphemeralContainers: append(append([]corev1.EphemeralContainer{}, pod.Spec.EphemeralContainers...), *container.DeepCopy()),
+	}
+	recorder := controller.GetEventRecorder(ctx)
+	if _, err := c.KubeClientSet.CoreV1().Pods(pod.Namespace).UpdateEphemeralContainers(pod.Name, ecs); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to add ephemeral container %q to pod %q of taskrun %q: %v", container.Name, pod.Name, tr.Name, err)
+		recorder.Eventf(tr, corev1.EventTypeWarning, ReasonEphemeralContainerFailed,
+			"Failed to add ephemeral container %q to pod %q: %v", container.Name, pod.Name, err)
+		return
+	}
+	recorder.Eventf(tr, corev1.EventTypeNormal, ReasonEphemeralContainerAdded,
+		"Added ephemeral container %q to pod %q", container.Name, pod.Name)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonPodEvicted indicates that the Pod of a TaskRun was evicted and that
+	// the TaskRun is run again in a new Pod
+	ReasonPodEvicted = "PodEvicted"
+
+	// maxEvictionRetries is the number of times a TaskRun is run again in a new
+	// Pod after its Pod was evicted, when the evicted-pod-policy is "retry"
+	maxEvictionRetries = 3
+
+	// podEvictionsAnnotation counts the times a TaskRun was run again after its Pod
+	// was evicted. They aren't recorded in its retriesStatus so that they don't count
+	// against the retries of its PipelineTask.
+	podEvictionsAnnotation = pipeline.GroupName + "/pod-evictions"
+)
+
+// retryEvictedPod clears the status of the attempt of tr whose Pod was evicted, so that
+// a new Pod is created for tr, and counts the eviction in the annotations of tr. This is
+// only done if the evicted-pod-policy is "retry" and tr wasn't already run again
+// maxEvictionRetries times, otherwise tr fails as evicted. It returns true if tr
+// will be run again.
+func (c *Reconciler) retryEvictedPod(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod) bool {
+	if config.FromContextOrDefaults(ctx).FeatureFlags.EvictedPodPolicy != config.RetryEvictedPodPolicy || !podconvert.IsPodEvicted(pod) {
+		return false
+	}
+	evictions := podEvictions(tr)
+	if evictions >= maxEvictionRetries {
+		return false
+	}
+
+	if tr.Annotations == nil {
+		tr.Annotations = map[string]string{}
+	}
+	tr.Annotations[podEvictionsAnnotation] = strconv.Itoa(evictions + 1)
+	ClearAttemptStatus(tr)
+
+	logging.FromContext(ctx).Infof("Pod %q of taskrun %q was evicted, running it again in a new pod", pod.Name, tr.Name)
+	controller.GetEventRecorder(ctx).Eventf(tr, corev1.EventTypeWarning, ReasonPodEvicted,
+		"Pod %q was evicted from node %q (%s), running the TaskRun again", pod.Name, pod.Spec.NodeName, pod.Status.Reason)
+	return true
+}
+
+// podEvictions returns the number of times tr was run again after its Pod was evicted.
+func podEvictions(tr *v1beta1.TaskRun) int {
+	n, err := strconv.Atoi(tr.Annotations[podEvictionsAnnotation])
+	if err != nil {
+		return 0
+	}
+	return n
+}
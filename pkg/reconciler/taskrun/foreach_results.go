@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// forEachResults collects the results written by the iterations of the steps
+// with a forEach, which are merged into a JSON array per result.
+type forEachResults struct {
+	// iterations maps the containers running an iteration to its index.
+	iterations map[string]int
+	// values maps the name of the results to their value per iteration.
+	values map[string]map[int]string
+}
+
+func newForEachResults(pod *corev1.Pod) *forEachResults {
+	r := &forEachResults{
+		iterations: map[string]int{},
+		values:     map[string]map[int]string{},
+	}
+	for _, c := range pod.Spec.Containers {
+		if _, index, ok := podconvert.ForEachIteration(c); ok {
+			r.iterations[c.Name] = index
+		}
+	}
+	return r
+}
+
+// add records the results written by the container if it runs an iteration,
+// and returns false otherwise.
+func (r *forEachResults) add(container string, results []v1beta1.TaskRunResult) bool {
+	index, ok := r.iterations[container]
+	if !ok {
+		return false
+	}
+	for _, res := range results {
+		if r.values[res.Name] == nil {
+			r.values[res.Name] = map[int]string{}
+		}
+		r.values[res.Name][index] = res.Value
+	}
+	return true
+}
+
+// merged returns each result written by the iterations, its value being the
+// JSON array of the values written by the iterations, in the order of their
+// index.
+func (r *forEachResults) merged() []v1beta1.TaskRunResult {
+	var results []v1beta1.TaskRunResult
+	for name, byIndex := range r.values {
+		indices := make([]int, 0, len(byIndex))
+		for index := range byIndex {
+			indices = append(indices, index)
+		}
+		sort.Ints(indices)
+		values := make([]string, 0, len(indices))
+		for _, index := range indices {
+			values = append(values, byIndex[index])
+		}
+		b, _ := json.Marshal(values)
+		results = append(results, v1beta1.TaskRunResult{Name: name, Value: string(b)})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonPullingImages indicates that the Pod of a TaskRun isn't created yet
+	// because the images of its Steps are being pulled on the node it targets
+	ReasonPullingImages = "PullingImages"
+
+	// prewarmTimeout is how long the Pod of a TaskRun waits for the images of its
+	// Steps to be pulled, before it is created anyway
+	prewarmTimeout = 2 * time.Minute
+)
+
+// prewarmImages pulls the images of the Steps of tr on the node its Pod template
+// targets before its Pod is created, when the enable-image-pre-warm feature flag
+// is set. It creates a Pod pulling the images on that node, and returns true
+// until they are pulled or prewarmTimeout has passed since it was created. Failing
+// to create that Pod doesn't prevent the Pod of tr from being created.
+func (c *Reconciler) prewarmImages(ctx context.Context, tr *v1beta1.TaskRun, taskSpec *v1beta1.TaskSpec) (bool, error) {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableImagePreWarm {
+		return false, nil
+	}
+	node := podconvert.TargetNode(tr)
+	if node == "" {
+		return false, nil
+	}
+
+	logger := logging.FromContext(ctx)
+	name := podconvert.PrewarmPodName(tr)
+	pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		ts := resources.ApplyParameters(taskSpec.DeepCopy(), tr, taskSpec.Params...)
+		var images []string
+		for _, s := range ts.Steps {
+			images = append(images, s.Image)
+		}
+		pod := podconvert.MakePrewarmPod(c.Images, tr, node, images)
+		if pod == nil {
+			return false, nil
+		}
+		if _, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Create(pod); err != nil {
+			logger.Warnf("Failed to create the pod pulling the images of taskrun %q on node %s: %v", tr.Name, node, err)
+			return false, nil
+		}
+	case err != nil:
+		logger.Errorf("Error getting pod %q: %v", name, err)
+		return false, err
+	default:
+		if imagesPulled(pod) {
+			return false, nil
+		}
+		if waited := c.clock.Since(pod.CreationTimestamp.Time); waited < prewarmTimeout {
+			c.enqueueAfter(tr, prewarmTimeout-waited)
+		} else {
+			logger.Warnf("Timed out pulling the images of taskrun %q on node %s", tr.Name, node)
+			return false, nil
+		}
+	}
+
+	podconvert.MarkStatusRunning(&tr.Status, ReasonPullingImages, fmt.Sprintf("Pulling the images of the steps on node %s", node))
+	return true, nil
+}
+
+// imagesPulled returns true once no container of pod is waiting for its image
+// to be pulled anymore. Containers whose image couldn't be pulled count as
+// pulled, the Pod of the TaskRun reports the error.
+func imagesPulled(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return false
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && (cs.State.Waiting.Reason == "ContainerCreating" || cs.State.Waiting.Reason == "PodInitializing") {
+			return false
+		}
+	}
+	return true
+}
+
+// deletePrewarmPod deletes the Pod pulling the images of the Steps of tr, once pod,
+// the Pod of tr, has started running. pod is nil when tr is stopped before its Pod
+// was created. The Pod is only deleted while the pod lister still lists it, so that
+// it isn't deleted again on every later reconcile.
+func (c *Reconciler) deletePrewarmPod(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod) error {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableImagePreWarm || podconvert.TargetNode(tr) == "" {
+		return nil
+	}
+	if pod != nil && (pod.Status.Phase == corev1.PodPending || pod.Status.Phase == corev1.PodUnknown || pod.Status.Phase == "") {
+		return nil
+	}
+	name := podconvert.PrewarmPodName(tr)
+	if _, err := c.podLister.Pods(tr.Namespace).Get(name); k8serrors.IsNotFound(err) {
+		return nil
+	}
+	err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/server"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
+)
+
+// logsPath is the path prefix the logs of steps are served on, followed by
+// {namespace}/{taskrunName}/{stepName}.
+const logsPath = "/logs/"
+
+// podLogsStreamer streams the logs of a container of a Pod.
+type podLogsStreamer interface {
+	StreamContainerLogs(namespace, name, container string, follow bool) (io.ReadCloser, error)
+}
+
+func (s *kubeLogsSource) StreamContainerLogs(namespace, name, container string, follow bool) (io.ReadCloser, error) {
+	return s.client.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    follow,
+	}).Stream()
+}
+
+// logServer streams the logs of the steps of TaskRuns to the callers allowed to
+// get the logs of their Pods.
+type logServer struct {
+	kubeClient    kubernetes.Interface
+	taskRunLister listers.TaskRunLister
+	logs          podLogsStreamer
+}
+
+// ServeHTTP streams the logs of the step of the TaskRun named by the path of r,
+// and keeps streaming them while the step runs when the "follow" query parameter
+// is "true". The bearer token of r must be allowed to get the pods/log of the
+// Pod of the TaskRun.
+func (s *logServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, logsPath), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		http.Error(w, "the path must be "+logsPath+"{namespace}/{taskrunName}/{stepName}", http.StatusNotFound)
+		return
+	}
+	namespace, name, step := parts[0], parts[1], parts[2]
+
+	// The access is reviewed for the Pod of the TaskRun, so that callers only allowed
+	// to get the logs of some Pods can get them. Until the TaskRun has a Pod, it is
+	// reviewed for all the Pods of the namespace, so that callers can't tell whether
+	// a TaskRun exists without being allowed to get its logs.
+	tr, err := s.taskRunLister.TaskRuns(namespace).Get(name)
+	var podName string
+	if err == nil {
+		podName = tr.Status.PodName
+	}
+	if code, err := server.Authorize(s.kubeClient, r, authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        "get",
+		Resource:    "pods",
+		Subresource: "log",
+		Name:        podName,
+	}); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	if k8serrors.IsNotFound(err) {
+		http.Error(w, "taskrun not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var container string
+	for _, st := range tr.Status.Steps {
+		if st.Name == step {
+			container = st.ContainerName
+		}
+	}
+	if tr.Status.PodName == "" || container == "" {
+		http.Error(w, "step not found in the pod of the taskrun", http.StatusNotFound)
+		return
+	}
+
+	stream, err := s.logs.StreamContainerLogs(namespace, tr.Status.PodName, container, r.URL.Query().Get("follow") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer stream.Close()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.Copy(flushWriter{w}, stream); err != nil {
+		logging.FromContext(r.Context()).Warnf("Failed to stream the logs of step %q of taskrun %s/%s: %v", step, namespace, name, err)
+	}
+}
+
+// flushWriter flushes every write, so that the logs are sent as they are read.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
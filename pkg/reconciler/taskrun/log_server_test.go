@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeLogsStreamer struct {
+	logs map[string]string
+}
+
+func (f *fakeLogsStreamer) StreamContainerLogs(namespace, name, container string, follow bool) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(f.logs[namespace+"/"+name+"/"+container])), nil
+}
+
+func TestLogServer(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			PodName: "build-pod",
+			Steps:   []v1beta1.StepState{{Name: "compile", ContainerName: "step-compile"}},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The "alice" token may get the logs of the Pods of the foo namespace, the
+	// "carol" token only those of the build-pod Pod, and the "bob" token may not.
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "alice", "bob", "carol":
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: review.Spec.Token}}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		allowed := review.Spec.User == "alice" || (review.Spec.User == "carol" && attrs.Name == "build-pod")
+		review.Status.Allowed = allowed && attrs.Namespace == "foo" &&
+			attrs.Verb == "get" && attrs.Resource == "pods" && attrs.Subresource == "log"
+		return true, review, nil
+	})
+
+	s := &logServer{
+		kubeClient:    kubeClient,
+		taskRunLister: listers.NewTaskRunLister(indexer),
+		logs:          &fakeLogsStreamer{logs: map[string]string{"foo/build-pod/step-compile": "compiling\n"}},
+	}
+	for _, tc := range []struct {
+		name      string
+		method    string
+		path      string
+		token     string
+		cleartext bool
+		wantCode  int
+		wantBody  string
+	}{{
+		name:     "logs of a step",
+		path:     "/logs/foo/build/compile",
+		token:    "alice",
+		wantCode: http.StatusOK,
+		wantBody: "compiling\n",
+	}, {
+		name:     "following the logs of a step",
+		path:     "/logs/foo/build/compile?follow=true",
+		token:    "alice",
+		wantCode: http.StatusOK,
+		wantBody: "compiling\n",
+	}, {
+		name:     "not a GET",
+		method:   http.MethodPost,
+		path:     "/logs/foo/build/compile",
+		token:    "alice",
+		wantCode: http.StatusMethodNotAllowed,
+	}, {
+		name:     "no step",
+		path:     "/logs/foo/build",
+		token:    "alice",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "no token",
+		path:     "/logs/foo/build/compile",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:      "token in cleartext",
+		path:      "/logs/foo/build/compile",
+		token:     "alice",
+		cleartext: true,
+		wantCode:  http.StatusForbidden,
+	}, {
+		name:     "invalid token",
+		path:     "/logs/foo/build/compile",
+		token:    "mallory",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "not allowed to get the logs",
+		path:     "/logs/foo/build/compile",
+		token:    "bob",
+		wantCode: http.StatusForbidden,
+	}, {
+		name:     "allowed to get the logs of the pod of the taskrun",
+		path:     "/logs/foo/build/compile",
+		token:    "carol",
+		wantCode: http.StatusOK,
+		wantBody: "compiling\n",
+	}, {
+		name:     "taskrun not found without access to all the pods",
+		path:     "/logs/foo/test/compile",
+		token:    "carol",
+		wantCode: http.StatusForbidden,
+	}, {
+		name:     "taskrun not found",
+		path:     "/logs/foo/test/compile",
+		token:    "alice",
+		wantCode: http.StatusNotFound,
+	}, {
+		name:     "step not found",
+		path:     "/logs/foo/build/push",
+		token:    "alice",
+		wantCode: http.StatusNotFound,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			scheme := "https"
+			if tc.cleartext {
+				scheme = "http"
+			}
+			req := httptest.NewRequest(method, scheme+"://controller"+tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tc.wantCode {
+				t.Fatalf("Expected status %d, got %d: %s", tc.wantCode, rec.Code, rec.Body.String())
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("Expected logs %q, got %q", tc.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"knative.dev/pkg/logging"
+)
+
+// logURLAnnotation is the annotation holding the URL of the logs of the Pod of a
+// TaskRun, rendered from the default-log-url-template without a containerName.
+const logURLAnnotation = pipeline.GroupName + "/log-url"
+
+// logURLRenderer renders the default-log-url-template. It keeps the last template
+// parsed, and only logs the first error rendering it so that a broken template
+// doesn't flood the logs of the controller.
+type logURLRenderer struct {
+	mu     sync.Mutex
+	text   string
+	tmpl   *template.Template
+	err    error
+	logged bool
+}
+
+// render returns the URL text renders for data, or false if it can't be rendered.
+func (r *logURLRenderer) render(ctx context.Context, text string, data map[string]string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.text != text || (r.tmpl == nil && r.err == nil) {
+		r.text, r.logged = text, false
+		r.tmpl, r.err = template.New("default-log-url-template").Option("missingkey=error").Parse(text)
+	}
+	err := r.err
+	var url strings.Builder
+	if err == nil {
+		err = r.tmpl.Execute(&url, data)
+	}
+	if err != nil {
+		if !r.logged {
+			logging.FromContext(ctx).Errorf("Failed to render the default-log-url-template %q, the URLs of the logs of the steps aren't recorded: %v", text, err)
+			r.logged = true
+		}
+		return "", false
+	}
+	return url.String(), true
+}
+
+// updateStepsLogURL records the URL of the logs of each step of tr, and of its Pod,
+// rendered from the default-log-url-template once the name of its Pod is known.
+func (c *Reconciler) updateStepsLogURL(ctx context.Context, tr *v1beta1.TaskRun) {
+	text := config.FromContextOrDefaults(ctx).Defaults.DefaultLogURLTemplate
+	if text == "" || tr.Status.PodName == "" {
+		return
+	}
+	data := map[string]string{
+		"namespace":     tr.Namespace,
+		"podName":       tr.Status.PodName,
+		"containerName": "",
+		"taskRunName":   tr.Name,
+	}
+	url, ok := c.logURLs.render(ctx, text, data)
+	if !ok {
+		return
+	}
+	if tr.Annotations == nil {
+		tr.Annotations = map[string]string{}
+	}
+	tr.Annotations[logURLAnnotation] = url
+	for i := range tr.Status.Steps {
+		step := &tr.Status.Steps[i]
+		data["containerName"] = step.ContainerName
+		if url, ok := c.logURLs.render(ctx, text, data); ok {
+			step.LogURL = url
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateStepsLogURL(t *testing.T) {
+	for _, tc := range []struct {
+		desc           string
+		template       string
+		podName        string
+		wantURLs       []string
+		wantAnnotation string
+	}{{
+		desc:           "splunk",
+		template:       `https://splunk.example.com/en-US/app/search/search?q=search%20index%3Dk8s%20namespace%3D{{.namespace}}%20pod%3D{{.podName}}{{if .containerName}}%20container%3D{{.containerName}}{{end}}`,
+		podName:        "build-pod",
+		wantURLs:       []string{"https://splunk.example.com/en-US/app/search/search?q=search%20index%3Dk8s%20namespace%3Dfoo%20pod%3Dbuild-pod%20container%3Dstep-compile", "https://splunk.example.com/en-US/app/search/search?q=search%20index%3Dk8s%20namespace%3Dfoo%20pod%3Dbuild-pod%20container%3Dstep-push"},
+		wantAnnotation: "https://splunk.example.com/en-US/app/search/search?q=search%20index%3Dk8s%20namespace%3Dfoo%20pod%3Dbuild-pod",
+	}, {
+		desc:           "loki",
+		template:       `https://grafana.example.com/explore?left={"queries":[{"expr":"{namespace=\"{{.namespace}}\",pod=\"{{.podName}}\"{{with .containerName}},container=\"{{.}}\"{{end}}}"}],"taskrun":"{{.taskRunName}}"}`,
+		podName:        "build-pod",
+		wantURLs:       []string{`https://grafana.example.com/explore?left={"queries":[{"expr":"{namespace=\"foo\",pod=\"build-pod\",container=\"step-compile\"}"}],"taskrun":"build"}`, `https://grafana.example.com/explore?left={"queries":[{"expr":"{namespace=\"foo\",pod=\"build-pod\",container=\"step-push\"}"}],"taskrun":"build"}`},
+		wantAnnotation: `https://grafana.example.com/explore?left={"queries":[{"expr":"{namespace=\"foo\",pod=\"build-pod\"}"}],"taskrun":"build"}`,
+	}, {
+		desc:     "no template",
+		podName:  "build-pod",
+		wantURLs: []string{"", ""},
+	}, {
+		desc:     "no pod",
+		template: "https://logs.example.com/{{.namespace}}/{{.podName}}/{{.containerName}}",
+		wantURLs: []string{"", ""},
+	}, {
+		desc:     "malformed template",
+		template: "https://logs.example.com/{{.namespace",
+		podName:  "build-pod",
+		wantURLs: []string{"", ""},
+	}, {
+		desc:     "unknown key",
+		template: "https://logs.example.com/{{.cluster}}/{{.podName}}",
+		podName:  "build-pod",
+		wantURLs: []string{"", ""},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.Defaults.DefaultLogURLTemplate = tc.template
+			ctx := config.ToContext(context.Background(), cfg)
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName: tc.podName,
+					Steps:   []v1beta1.StepState{{Name: "compile", ContainerName: "step-compile"}, {Name: "push", ContainerName: "step-push"}},
+				}},
+			}
+
+			c := &Reconciler{}
+			c.updateStepsLogURL(ctx, tr)
+			var urls []string
+			for _, step := range tr.Status.Steps {
+				urls = append(urls, step.LogURL)
+			}
+			if d := cmp.Diff(tc.wantURLs, urls); d != "" {
+				t.Errorf("Unexpected log URLs %s", diff.PrintWantGot(d))
+			}
+			if got := tr.Annotations[logURLAnnotation]; got != tc.wantAnnotation {
+				t.Errorf("Expected the %s annotation %q, got %q", logURLAnnotation, tc.wantAnnotation, got)
+			}
+		})
+	}
+}
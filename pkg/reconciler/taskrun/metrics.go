@@ -19,6 +19,7 @@ package taskrun
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 )
@@ -55,9 +57,30 @@ var (
 		"Number of taskruns executing currently",
 		stats.UnitDimensionless)
 
+	runningTRs = stats.Float64("running_taskruns",
+		"Number of taskruns executing currently, by namespace",
+		stats.UnitDimensionless)
+
 	podLatency = stats.Float64("taskruns_pod_latency",
 		"scheduling latency for the taskruns pods",
 		stats.UnitMilliseconds)
+
+	podPending = stats.Float64("taskrun_pod_pending_seconds",
+		"The time in seconds between the creation of the taskrun pod and its first container running",
+		stats.UnitDimensionless)
+	podImagePull = stats.Float64("taskrun_pod_image_pull_seconds",
+		"The time in seconds the scheduled taskrun pod spent pulling images before its first container running",
+		stats.UnitDimensionless)
+	podPendingDistribution = view.Distribution(1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600)
+)
+
+const (
+	// maxRunningNamespaces bounds the number of namespaces the running TaskRuns
+	// are reported for, to keep the cardinality of the metric bounded.
+	maxRunningNamespaces = 100
+	// otherNamespaces is the namespace the running TaskRuns of the namespaces
+	// beyond maxRunningNamespaces are reported for.
+	otherNamespaces = "other"
 )
 
 type Recorder struct {
@@ -71,6 +94,10 @@ type Recorder struct {
 	pipelineRun tag.Key
 	pod         tag.Key
 
+	// running holds the keys of the running TaskRuns, by reported namespace
+	running   map[string]sets.String
+	runningMu sync.Mutex
+
 	ReportingPeriod time.Duration
 }
 
@@ -79,6 +106,7 @@ type Recorder struct {
 func NewRecorder() (*Recorder, error) {
 	r := &Recorder{
 		initialized: true,
+		running:     map[string]sets.String{},
 
 		// Default to reporting metrics every 30s.
 		ReportingPeriod: 30 * time.Second,
@@ -150,12 +178,30 @@ func NewRecorder() (*Recorder, error) {
 			Measure:     runningTRsCount,
 			Aggregation: view.LastValue(),
 		},
+		&view.View{
+			Description: runningTRs.Description(),
+			Measure:     runningTRs,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{r.namespace},
+		},
 		&view.View{
 			Description: podLatency.Description(),
 			Measure:     podLatency,
 			Aggregation: view.LastValue(),
 			TagKeys:     []tag.Key{r.task, r.taskRun, r.namespace, r.pod},
 		},
+		&view.View{
+			Description: podPending.Description(),
+			Measure:     podPending,
+			Aggregation: podPendingDistribution,
+			TagKeys:     []tag.Key{r.task, r.namespace},
+		},
+		&view.View{
+			Description: podImagePull.Description(),
+			Measure:     podImagePull,
+			Aggregation: podPendingDistribution,
+			TagKeys:     []tag.Key{r.task, r.namespace},
+		},
 	)
 
 	if err != nil {
@@ -239,11 +285,25 @@ func (r *Recorder) RunningTaskRuns(lister listers.TaskRunLister) error {
 	}
 
 	var runningTrs int
-	for _, pr := range trs {
-		if !pr.IsDone() {
+	// The running TaskRuns are counted again by namespace, since the ones deleted
+	// while running aren't reconciled anymore.
+	running := map[string]sets.String{}
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	for ns := range r.running {
+		running[ns] = sets.NewString()
+	}
+	for _, tr := range trs {
+		if !tr.IsDone() {
 			runningTrs++
+			ns := runningNamespace(running, tr.Namespace)
+			if running[ns] == nil {
+				running[ns] = sets.NewString()
+			}
+			running[ns].Insert(tr.Namespace + "/" + tr.Name)
 		}
 	}
+	r.running = running
 
 	ctx, err := tag.New(
 		context.Background(),
@@ -253,9 +313,60 @@ func (r *Recorder) RunningTaskRuns(lister listers.TaskRunLister) error {
 	}
 	metrics.Record(ctx, runningTRsCount.M(float64(runningTrs)))
 
+	for ns, keys := range running {
+		if err := r.recordRunningInNamespace(ns, keys.Len()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordRunningTaskRun updates the number of TaskRuns running in the namespace of tr
+// when it starts or stops running.
+func (r *Recorder) RecordRunningTaskRun(tr *v1beta1.TaskRun) error {
+	if !r.initialized {
+		return fmt.Errorf("ignoring the metrics recording for %s , failed to initialize the metrics recorder", tr.Name)
+	}
+
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	ns := runningNamespace(r.running, tr.Namespace)
+	key := tr.Namespace + "/" + tr.Name
+	switch {
+	case !tr.IsDone() && !r.running[ns].Has(key):
+		if r.running[ns] == nil {
+			r.running[ns] = sets.NewString()
+		}
+		r.running[ns].Insert(key)
+	case tr.IsDone() && r.running[ns].Has(key):
+		r.running[ns].Delete(key)
+	default:
+		return nil
+	}
+	return r.recordRunningInNamespace(ns, r.running[ns].Len())
+}
+
+func (r *Recorder) recordRunningInNamespace(ns string, count int) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespace, ns),
+	)
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, runningTRs.M(float64(count)))
 	return nil
 }
 
+// runningNamespace returns the namespace the TaskRuns running in ns are reported for,
+// which is otherNamespaces once maxRunningNamespaces namespaces are reported.
+func runningNamespace(running map[string]sets.String, ns string) string {
+	if _, ok := running[ns]; ok || len(running) < maxRunningNamespaces {
+		return ns
+	}
+	return otherNamespaces
+}
+
 // ReportRunningTaskRuns invokes RunningTaskRuns on our configured PeriodSeconds
 // until the context is cancelled.
 func (r *Recorder) ReportRunningTaskRuns(ctx context.Context, lister listers.TaskRunLister) {
@@ -309,6 +420,94 @@ func (r *Recorder) RecordPodLatency(pod *corev1.Pod, tr *v1beta1.TaskRun) error
 	return nil
 }
 
+// RecordPodPending logs the time the pod for TaskRun spent pending before its
+// first container started running. When the pod scheduling time is known, the
+// time between scheduling and running that wasn't spent running the init
+// containers, which is spent pulling images, is logged separately.
+// It is meant to be called once, when the first container of the pod starts.
+// returns an error if its failed to log the metrics
+func (r *Recorder) RecordPodPending(pod *corev1.Pod, tr *v1beta1.TaskRun) error {
+	if !r.initialized {
+		return errors.New("ignoring the metrics recording for pod , failed to initialize the metrics recorder")
+	}
+	if pod == nil {
+		return errors.New("ignoring the metrics recording for taskrun without pod")
+	}
+
+	runningTime := getRunningTime(pod)
+	if runningTime.IsZero() {
+		return errors.New("pod has never got running")
+	}
+
+	taskName := "anonymous"
+	if tr.Spec.TaskRef != nil {
+		taskName = tr.Spec.TaskRef.Name
+	}
+
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.task, taskName),
+		tag.Insert(r.namespace, tr.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	pending := runningTime.Sub(pod.CreationTimestamp.Time)
+	metrics.Record(ctx, podPending.M(pending.Seconds()))
+
+	if scheduledTime := getScheduledTime(pod); !scheduledTime.IsZero() && !scheduledTime.After(runningTime.Time) {
+		pull := runningTime.Sub(scheduledTime.Time) - getInitRunningDuration(pod)
+		if pull < 0 {
+			pull = 0
+		}
+		metrics.Record(ctx, podImagePull.M(pull.Seconds()))
+	}
+
+	return nil
+}
+
+// getRunningTime returns the earliest time any of the pod containers started
+// running.
+func getRunningTime(pod *corev1.Pod) metav1.Time {
+	var running metav1.Time
+	for _, s := range pod.Status.ContainerStatuses {
+		var startedAt metav1.Time
+		switch {
+		case s.State.Running != nil:
+			startedAt = s.State.Running.StartedAt
+		case s.State.Terminated != nil:
+			startedAt = s.State.Terminated.StartedAt
+		}
+		if !startedAt.IsZero() && (running.IsZero() || startedAt.Before(&running)) {
+			running = startedAt
+		}
+	}
+	return running
+}
+
+// stepsStarted returns true if any of the steps has started running.
+func stepsStarted(steps []v1beta1.StepState) bool {
+	for _, s := range steps {
+		if s.Running != nil || (s.Terminated != nil && !s.Terminated.StartedAt.IsZero()) {
+			return true
+		}
+	}
+	return false
+}
+
+// getInitRunningDuration returns the time the init containers of the pod spent
+// running, which they run one after the other before the containers start.
+func getInitRunningDuration(pod *corev1.Pod) time.Duration {
+	var d time.Duration
+	for _, s := range pod.Status.InitContainerStatuses {
+		if t := s.State.Terminated; t != nil && !t.StartedAt.IsZero() && !t.FinishedAt.IsZero() {
+			d += t.FinishedAt.Sub(t.StartedAt.Time)
+		}
+	}
+	return d
+}
+
 func getScheduledTime(pod *corev1.Pod) metav1.Time {
 	for _, c := range pod.Status.Conditions {
 		if c.Type == corev1.PodScheduled {
@@ -17,14 +17,18 @@ limitations under the License.
 package taskrun
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	informersv1beta1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1beta1"
 	faketaskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun/fake"
+	"github.com/tektoncd/pipeline/test/diff"
+	"go.opencensus.io/stats/view"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
@@ -40,10 +44,12 @@ func TestUninitializedMetrics(t *testing.T) {
 	durationCountError := metrics.DurationAndCount(&v1beta1.TaskRun{})
 	taskrunsCountError := metrics.RunningTaskRuns(nil)
 	podLatencyError := metrics.RecordPodLatency(nil, nil)
+	podPendingError := metrics.RecordPodPending(nil, nil)
 
 	assertErrNotNil(durationCountError, "DurationCount recording expected to return error but got nil", t)
 	assertErrNotNil(taskrunsCountError, "Current TaskrunsCount recording expected to return error but got nil", t)
 	assertErrNotNil(podLatencyError, "Pod Latency recording expected to return error but got nil", t)
+	assertErrNotNil(podPendingError, "Pod Pending recording expected to return error but got nil", t)
 }
 
 func TestRecordTaskrunDurationCount(t *testing.T) {
@@ -221,9 +227,52 @@ func TestRecordRunningTaskrunsCount(t *testing.T) {
 	metrics, err := NewRecorder()
 	assertErrIsNil(err, "Recorder initialization failed", t)
 
+	// A TaskRun deleted while running is only counted until the running TaskRuns are listed
+	err = metrics.RecordRunningTaskRun(tb.TaskRun("deleted", tb.TaskRunNamespace("ns-deleted")))
+	assertErrIsNil(err, "RecordRunningTaskRun recording expected to return nil but got error", t)
+
 	err = metrics.RunningTaskRuns(informer.Lister())
 	assertErrIsNil(err, "RunningTaskRuns recording expected to return nil but got error", t)
 	metricstest.CheckLastValueData(t, "running_taskruns_count", map[string]string{}, 1)
+	checkRunningByNamespace(t, "running_taskruns", map[string]float64{"ns": 1, "ns-deleted": 0})
+}
+
+func TestRecordRunningTaskRunByNamespace(t *testing.T) {
+	unregisterMetrics()
+	taskRun := func(name, ns string, status corev1.ConditionStatus) *v1beta1.TaskRun {
+		return tb.TaskRun(name, tb.TaskRunNamespace(ns),
+			tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: status,
+			})))
+	}
+
+	metrics, err := NewRecorder()
+	assertErrIsNil(err, "Recorder initialization failed", t)
+
+	for _, taskRun := range []*v1beta1.TaskRun{
+		taskRun("taskrun-1", "ns-1", corev1.ConditionUnknown),
+		taskRun("taskrun-2", "ns-1", corev1.ConditionUnknown),
+		taskRun("taskrun-1", "ns-2", corev1.ConditionUnknown),
+		// reconciled again while running
+		taskRun("taskrun-1", "ns-2", corev1.ConditionUnknown),
+		taskRun("taskrun-1", "ns-1", corev1.ConditionTrue),
+		// done before it was seen running
+		taskRun("taskrun-3", "ns-2", corev1.ConditionFalse),
+	} {
+		if err := metrics.RecordRunningTaskRun(taskRun); err != nil {
+			t.Fatalf("RecordRunningTaskRun: %v", err)
+		}
+	}
+	checkRunningByNamespace(t, "running_taskruns", map[string]float64{"ns-1": 1, "ns-2": 1})
+
+	// The namespaces beyond maxRunningNamespaces are reported together
+	for i := 0; i < maxRunningNamespaces+1; i++ {
+		if err := metrics.RecordRunningTaskRun(taskRun("taskrun", fmt.Sprintf("bulk-%d", i), corev1.ConditionUnknown)); err != nil {
+			t.Fatalf("RecordRunningTaskRun: %v", err)
+		}
+	}
+	checkRunningByNamespace(t, "running_taskruns", map[string]float64{"bulk-0": 1, otherNamespaces: 3})
 }
 
 func TestRecordPodLatency(t *testing.T) {
@@ -294,6 +343,133 @@ func TestRecordPodLatency(t *testing.T) {
 
 }
 
+func TestRecordPodPending(t *testing.T) {
+	creationTime := time.Now()
+	taskRun := tb.TaskRun("test-taskrun",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(
+			tb.TaskRunTaskRef("task-1"),
+		),
+	)
+	running := func(name string, startedAt time.Time) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name: name,
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{StartedAt: metav1.Time{Time: startedAt}},
+			},
+		}
+	}
+	testData := []struct {
+		name               string
+		pod                *corev1.Pod
+		expectedTags       map[string]string
+		expectedPending    float64
+		expectedImagePull  float64
+		expectingImagePull bool
+		expectingError     bool
+	}{{
+		name: "for_running_pod",
+		pod: tb.Pod("test-taskrun-pod-123456",
+			tb.PodNamespace("foo"),
+			tb.PodCreationTimestamp(creationTime),
+			tb.PodStatus(
+				tb.PodStatusConditions(corev1.PodCondition{
+					Type:               corev1.PodScheduled,
+					LastTransitionTime: metav1.Time{Time: creationTime.Add(4 * time.Second)},
+				}),
+				tb.PodStatusContainerStatuses(
+					running("step-two", creationTime.Add(20*time.Second)),
+					corev1.ContainerStatus{
+						Name: "step-one",
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{StartedAt: metav1.Time{Time: creationTime.Add(10 * time.Second)}},
+						},
+					},
+				),
+			)),
+		expectedTags: map[string]string{
+			"task":      "task-1",
+			"namespace": "foo",
+		},
+		expectedPending:    10,
+		expectedImagePull:  6,
+		expectingImagePull: true,
+	}, {
+		name: "for_running_pod_without_scheduled_condition",
+		pod: tb.Pod("test-taskrun-pod-123456",
+			tb.PodNamespace("foo"),
+			tb.PodCreationTimestamp(creationTime),
+			tb.PodStatus(
+				tb.PodStatusContainerStatuses(running("step-one", creationTime.Add(7*time.Second))),
+			)),
+		expectedTags: map[string]string{
+			"task":      "task-1",
+			"namespace": "foo",
+		},
+		expectedPending: 7,
+	}, {
+		name: "for_running_pod_with_init_containers",
+		pod: tb.Pod("test-taskrun-pod-123456",
+			tb.PodNamespace("foo"),
+			tb.PodCreationTimestamp(creationTime),
+			tb.PodStatus(
+				tb.PodStatusConditions(corev1.PodCondition{
+					Type:               corev1.PodScheduled,
+					LastTransitionTime: metav1.Time{Time: creationTime.Add(2 * time.Second)},
+				}),
+				tb.PodStatusInitContainerStatuses(corev1.ContainerStatus{
+					Name: "place-tools",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							StartedAt:  metav1.Time{Time: creationTime.Add(5 * time.Second)},
+							FinishedAt: metav1.Time{Time: creationTime.Add(8 * time.Second)},
+						},
+					},
+				}),
+				tb.PodStatusContainerStatuses(running("step-one", creationTime.Add(12*time.Second))),
+			)),
+		expectedTags: map[string]string{
+			"task":      "task-1",
+			"namespace": "foo",
+		},
+		expectedPending:    12,
+		expectedImagePull:  7,
+		expectingImagePull: true,
+	}, {
+		name: "for_pending_pod",
+		pod: tb.Pod("test-taskrun-pod-123456",
+			tb.PodNamespace("foo"),
+			tb.PodCreationTimestamp(creationTime),
+		),
+		expectingError: true,
+	}, {
+		name:           "without_pod",
+		expectingError: true,
+	}}
+
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			unregisterMetrics()
+
+			metrics, err := NewRecorder()
+			assertErrIsNil(err, "Recorder initialization failed", t)
+
+			err = metrics.RecordPodPending(td.pod, taskRun)
+			if td.expectingError {
+				assertErrNotNil(err, "Pod Pending recording expected to return error but got nil", t)
+				return
+			}
+			assertErrIsNil(err, "RecordPodPending recording expected to return nil but got error", t)
+			metricstest.CheckDistributionData(t, "taskrun_pod_pending_seconds", td.expectedTags, 1, td.expectedPending, td.expectedPending)
+			if td.expectingImagePull {
+				metricstest.CheckDistributionData(t, "taskrun_pod_image_pull_seconds", td.expectedTags, 1, td.expectedImagePull, td.expectedImagePull)
+			} else {
+				metricstest.CheckStatsNotReported(t, "taskrun_pod_image_pull_seconds")
+			}
+		})
+	}
+}
+
 func addTaskruns(informer informersv1beta1.TaskRunInformer, taskrun, task, ns string, status corev1.ConditionStatus, t *testing.T) {
 	err := informer.Informer().GetIndexer().Add(tb.TaskRun(taskrun,
 		tb.TaskRunNamespace(ns),
@@ -326,6 +502,64 @@ func assertErrNotNil(err error, message string, t *testing.T) {
 	}
 }
 
+// checkRunningByNamespace checks the last values of the metric name for the given namespaces.
+func checkRunningByNamespace(t *testing.T, name string, want map[string]float64) {
+	t.Helper()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatalf("Failed to retrieve the data of %s: %v", name, err)
+	}
+	got := map[string]float64{}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if _, ok := want[tag.Value]; ok && tag.Key.Name() == "namespace" {
+				got[tag.Value] = row.Data.(*view.LastValueData).Value
+			}
+		}
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected values of %s %s", name, diff.PrintWantGot(d))
+	}
+}
+
 func unregisterMetrics() {
-	metricstest.Unregister("taskrun_duration_seconds", "pipelinerun_taskrun_duration_seconds", "taskrun_count", "running_taskruns_count", "taskruns_pod_latency")
+	metricstest.Unregister("taskrun_duration_seconds", "pipelinerun_taskrun_duration_seconds", "taskrun_count", "running_taskruns_count", "running_taskruns", "taskruns_pod_latency", "taskrun_pod_pending_seconds", "taskrun_pod_image_pull_seconds")
+}
+
+func TestStepsStarted(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		steps []v1beta1.StepState
+		want  bool
+	}{{
+		name: "no steps",
+	}, {
+		name: "waiting",
+		steps: []v1beta1.StepState{{ContainerState: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+		}}},
+	}, {
+		name: "terminated without starting",
+		steps: []v1beta1.StepState{{ContainerState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: "Error"},
+		}}},
+	}, {
+		name: "running",
+		steps: []v1beta1.StepState{{}, {ContainerState: corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()},
+		}}},
+		want: true,
+	}, {
+		name: "terminated",
+		steps: []v1beta1.StepState{{ContainerState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{StartedAt: metav1.Now()},
+		}}},
+		want: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := stepsStarted(tc.steps); got != tc.want {
+				t.Errorf("Expected stepsStarted to be %t, got %t", tc.want, got)
+			}
+		})
+	}
 }
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// reconcilePlatforms runs tr in one Pod per platform of its Task, and updates its
+// status from the status of all of them: tr is done once all the Pods are, and
+// only succeeds if they all succeed. The results of each Pod are stored in the
+// platformResults of tr.
+func (c *Reconciler) reconcilePlatforms(ctx context.Context, tr *v1beta1.TaskRun, taskSpec *v1beta1.TaskSpec, rtr *resources.ResolvedTaskResources) error {
+	logger := logging.FromContext(ctx)
+	recorder := controller.GetEventRecorder(ctx)
+
+	pods := make([]*corev1.Pod, len(taskSpec.Platforms))
+	workspacesPrepared := false
+	for i, platform := range taskSpec.Platforms {
+		name := podconvert.PlatformPodName(tr, platform)
+		pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			if !workspacesPrepared {
+				if err := c.prepareWorkspaces(ctx, tr); err != nil {
+					return err
+				}
+				workspacesPrepared = true
+			}
+			if pod, err = c.createPod(ctx, tr, rtr, platform); err != nil {
+				newErr := c.handlePodCreationError(ctx, tr, err)
+				logger.Errorf("Failed to create the pod of platform %s for taskrun %q: %v", platform, tr.Name, newErr)
+				return newErr
+			}
+		} else if err != nil {
+			logger.Errorf("Error getting pod %q: %v", name, err)
+			return err
+		}
+		pods[i] = pod
+
+		if podconvert.IsPodExceedingNodeResources(pod) {
+			recorder.Eventf(tr, corev1.EventTypeWarning, podconvert.ReasonExceededNodeResources, "Insufficient resources to schedule pod %q", pod.Name)
+		}
+		if podconvert.SidecarsReady(pod.Status) {
+			if err := podconvert.UpdateReady(c.KubeClientSet, *pod); err != nil {
+				return err
+			}
+		}
+	}
+	if workspacesPrepared {
+		go c.timeoutHandler.WaitTaskRun(tr, tr.Status.StartTime)
+	}
+
+	// Convert the status of the Pod of each platform as if it was the only Pod of tr.
+	var running, failed *apis.Condition
+	var runningPlatform, failedPlatform string
+	platformResults := make(map[string][]v1beta1.TaskRunResult, len(pods))
+	for i, pod := range pods {
+		platform := taskSpec.Platforms[i]
+		attempt := tr.DeepCopy()
+		attempt.Status.Steps = nil
+		attempt.Status.TaskRunResults = nil
+		attempt.Status.ResourcesResult = nil
+		attempt.Status = podconvert.MakeTaskRunStatus(logger, *attempt, pod, *taskSpec)
+		if err := updateTaskRunResourceResult(ctx, attempt, *pod); err != nil {
+			return err
+		}
+		if err := c.updateTaskRunResultsFromLogs(ctx, attempt, pod); err != nil {
+			logger.Errorf("Failed to read the results of the pod of platform %s of taskrun %q from its logs: %v", platform, tr.Name, err)
+			return err
+		}
+		if len(attempt.Status.TaskRunResults) > 0 {
+			platformResults[platform] = attempt.Status.TaskRunResults
+		}
+
+		cond := attempt.Status.GetCondition(apis.ConditionSucceeded)
+		switch {
+		case !attempt.IsDone():
+			if running == nil {
+				running, runningPlatform = cond, platform
+			}
+		case !attempt.IsSuccessful():
+			if failed == nil {
+				failed, failedPlatform = cond, platform
+			}
+		}
+	}
+
+	tr.Status.PodName = ""
+	tr.Status.Steps = nil
+	tr.Status.Sidecars = nil
+	if len(platformResults) > 0 {
+		tr.Status.PlatformResults = platformResults
+	}
+	switch {
+	case running != nil:
+		podconvert.MarkStatusRunning(&tr.Status, running.Reason, fmt.Sprintf("Platform %s: %s", runningPlatform, running.Message))
+	case failed != nil:
+		tr.Status.SetCondition(&apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  failed.Reason,
+			Message: fmt.Sprintf("Platform %s: %s", failedPlatform, failed.Message),
+		})
+		tr.Status.CompletionTime = &metav1.Time{Time: c.clock.Now()}
+	default:
+		podconvert.MarkStatusSuccess(&tr.Status)
+		tr.Status.CompletionTime = &metav1.Time{Time: c.clock.Now()}
+	}
+
+	logger.Infof("Successfully reconciled taskrun %s/%s on platforms %v with status: %#v", tr.Name, tr.Namespace, taskSpec.Platforms, tr.Status.GetCondition(apis.ConditionSucceeded))
+	return nil
+}
+
+// deletePlatformPods deletes the Pods of the platforms of tr, when its Task lists
+// platforms.
+func (c *Reconciler) deletePlatformPods(tr *v1beta1.TaskRun) error {
+	if tr.Status.TaskSpec == nil {
+		return nil
+	}
+	for _, platform := range tr.Status.TaskSpec.Platforms {
+		err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Delete(podconvert.PlatformPodName(tr, platform), &metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopPlatformSidecarsAndRecordMetrics stops the sidecars of the Pods of the
+// platforms of tr once it is done, and records the metrics of tr and of the
+// latency of each of its Pods.
+func (c *Reconciler) stopPlatformSidecarsAndRecordMetrics(ctx context.Context, tr *v1beta1.TaskRun) error {
+	logger := logging.FromContext(ctx)
+	if err := c.metrics.DurationAndCount(tr); err != nil {
+		logger.Warnf("Failed to log the metrics : %v", err)
+	}
+	for _, platform := range tr.Status.TaskSpec.Platforms {
+		pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(podconvert.PlatformPodName(tr, platform), metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := c.metrics.RecordPodLatency(pod, tr); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+		if err := podconvert.StopSidecars(c.Images.NopImage, c.KubeClientSet, *pod); err != nil {
+			return fmt.Errorf("error stopping the sidecars of pod %q: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
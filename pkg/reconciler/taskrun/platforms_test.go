@@ -0,0 +1,282 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/system"
+	test "github.com/tektoncd/pipeline/test"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+var multiPlatformTask = &v1beta1.Task{
+	ObjectMeta: metav1.ObjectMeta{Name: "multi-platform-task", Namespace: "foo"},
+	Spec: v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{
+			Name:    "build",
+			Image:   "foo",
+			Command: []string{"/mycmd"},
+		}}},
+		Results:   []v1beta1.TaskResult{{Name: "digest"}},
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	},
+}
+
+var alphaFeatureFlags = []*corev1.ConfigMap{{
+	ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+	Data: map[string]string{
+		"enable-api-fields": config.AlphaAPIFields,
+	},
+}}
+
+func multiPlatformTaskRun() *v1beta1.TaskRun {
+	return &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-multi-platform", Namespace: "foo"},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: multiPlatformTask.Name},
+		},
+		Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			StartTime: &metav1.Time{Time: time.Now()},
+		}},
+	}
+}
+
+func platformPod(tr *v1beta1.TaskRun, platform string, phase corev1.PodPhase, digest string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            podconvert.PlatformPodName(tr, platform),
+			Labels:          map[string]string{pipeline.GroupName + pipeline.TaskRunLabelKey: tr.Name},
+			Annotations:     map[string]string{podconvert.PlatformAnnotation: platform},
+			OwnerReferences: []metav1.OwnerReference{tr.GetOwnerReference()},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}}},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	switch phase {
+	case corev1.PodRunning:
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "step-build",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}
+	case corev1.PodSucceeded:
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "step-build",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Message: `[{"key":"digest","value":"` + digest + `","type":"TaskRunResult"}]`,
+			}},
+		}}
+	case corev1.PodFailed:
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: "step-build",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+			}},
+		}}
+	}
+	return pod
+}
+
+func TestReconcilePlatforms(t *testing.T) {
+	tr := multiPlatformTaskRun()
+	now := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name            string
+		pods            []*corev1.Pod
+		wantStatus      corev1.ConditionStatus
+		wantMessage     string
+		wantResults     map[string][]v1beta1.TaskRunResult
+		wantCompletedAt bool
+	}{{
+		name:        "pods created",
+		wantStatus:  corev1.ConditionUnknown,
+		wantMessage: "Platform linux/amd64: ",
+	}, {
+		name: "platform still running",
+		pods: []*corev1.Pod{
+			platformPod(tr, "linux/amd64", corev1.PodSucceeded, "sha256:amd64"),
+			platformPod(tr, "linux/arm64", corev1.PodRunning, ""),
+		},
+		wantStatus:  corev1.ConditionUnknown,
+		wantMessage: "Platform linux/arm64: Not all Steps in the Task have finished executing",
+		wantResults: map[string][]v1beta1.TaskRunResult{
+			"linux/amd64": {{Name: "digest", Value: "sha256:amd64"}},
+		},
+	}, {
+		name: "all platforms succeeded",
+		pods: []*corev1.Pod{
+			platformPod(tr, "linux/amd64", corev1.PodSucceeded, "sha256:amd64"),
+			platformPod(tr, "linux/arm64", corev1.PodSucceeded, "sha256:arm64"),
+		},
+		wantStatus:  corev1.ConditionTrue,
+		wantMessage: "All Steps have completed executing",
+		wantResults: map[string][]v1beta1.TaskRunResult{
+			"linux/amd64": {{Name: "digest", Value: "sha256:amd64"}},
+			"linux/arm64": {{Name: "digest", Value: "sha256:arm64"}},
+		},
+		wantCompletedAt: true,
+	}, {
+		name: "platform failed",
+		pods: []*corev1.Pod{
+			platformPod(tr, "linux/amd64", corev1.PodSucceeded, "sha256:amd64"),
+			platformPod(tr, "linux/arm64", corev1.PodFailed, ""),
+		},
+		wantStatus:  corev1.ConditionFalse,
+		wantMessage: "Platform linux/arm64: ",
+		wantResults: map[string][]v1beta1.TaskRunResult{
+			"linux/amd64": {{Name: "digest", Value: "sha256:amd64"}},
+		},
+		wantCompletedAt: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := multiPlatformTaskRun()
+			d := test.Data{
+				TaskRuns:   []*v1beta1.TaskRun{taskRun},
+				Tasks:      []*v1beta1.Task{multiPlatformTask},
+				Pods:       tc.pods,
+				ConfigMaps: alphaFeatureFlags,
+			}
+			testAssets, cancel := getTaskRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			clients := testAssets.Clients
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			for _, platform := range multiPlatformTask.Spec.Platforms {
+				pod, err := clients.Kube.CoreV1().Pods("foo").Get(podconvert.PlatformPodName(taskRun, platform), metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Expected a pod for platform %s: %v", platform, err)
+				}
+				if tc.pods != nil {
+					continue
+				}
+				parts := strings.Split(platform, "/")
+				wantSelector := map[string]string{"kubernetes.io/os": parts[0], "kubernetes.io/arch": parts[1]}
+				if d := cmp.Diff(wantSelector, pod.Spec.NodeSelector); d != "" {
+					t.Errorf("Unexpected node selector of the pod of platform %s %s", platform, diff.PrintWantGot(d))
+				}
+				if got := pod.Annotations[podconvert.PlatformAnnotation]; got != platform {
+					t.Errorf("Expected the pod to be annotated with platform %s, got %q", platform, got)
+				}
+			}
+
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			cond := newTr.Status.GetCondition(apis.ConditionSucceeded)
+			if cond.Status != tc.wantStatus {
+				t.Errorf("Expected the TaskRun condition to be %s but got %s", tc.wantStatus, cond.Status)
+			}
+			if !strings.HasPrefix(cond.Message, tc.wantMessage) {
+				t.Errorf("Expected the TaskRun condition message to start with %q but got %q", tc.wantMessage, cond.Message)
+			}
+			if d := cmp.Diff(tc.wantResults, newTr.Status.PlatformResults); d != "" {
+				t.Errorf("Unexpected platform results %s", diff.PrintWantGot(d))
+			}
+			if completed := newTr.Status.CompletionTime != nil; completed != tc.wantCompletedAt {
+				t.Errorf("Expected the TaskRun to be completed: %t, got completion time %v", tc.wantCompletedAt, newTr.Status.CompletionTime)
+			} else if completed && !newTr.Status.CompletionTime.Time.Equal(now) {
+				t.Errorf("Expected the TaskRun to be completed at %v, got %v", now, newTr.Status.CompletionTime.Time)
+			}
+			if newTr.Status.PodName != "" {
+				t.Errorf("Expected no single pod for the TaskRun, got %q", newTr.Status.PodName)
+			}
+		})
+	}
+}
+
+func TestReconcilePlatformsDone(t *testing.T) {
+	unregisterMetrics()
+	taskRun := multiPlatformTaskRun()
+	taskRun.Status.TaskSpec = &multiPlatformTask.Spec
+	taskRun.Status.CompletionTime = &metav1.Time{Time: taskRun.Status.StartTime.Add(time.Minute)}
+	taskRun.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	})
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{multiPlatformTask},
+		Pods: []*corev1.Pod{
+			platformPod(taskRun, "linux/amd64", corev1.PodSucceeded, "sha256:amd64"),
+			platformPod(taskRun, "linux/arm64", corev1.PodSucceeded, "sha256:arm64"),
+		},
+		ConfigMaps: alphaFeatureFlags,
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	metricstest.CheckStatsReported(t, "taskrun_count")
+}
+
+func TestReconcilePlatformsCancelled(t *testing.T) {
+	taskRun := multiPlatformTaskRun()
+	taskRun.Spec.Status = v1beta1.TaskRunSpecStatusCancelled
+	taskRun.Status.TaskSpec = &multiPlatformTask.Spec
+	taskRun.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown,
+	})
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{multiPlatformTask},
+		Pods: []*corev1.Pod{
+			platformPod(taskRun, "linux/amd64", corev1.PodRunning, ""),
+			platformPod(taskRun, "linux/arm64", corev1.PodRunning, ""),
+		},
+		ConfigMaps: alphaFeatureFlags,
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+	for _, platform := range multiPlatformTask.Spec.Platforms {
+		name := podconvert.PlatformPodName(taskRun, platform)
+		if _, err := clients.Kube.CoreV1().Pods("foo").Get(name, metav1.GetOptions{}); !k8sapierrors.IsNotFound(err) {
+			t.Errorf("Expected the pod %s of platform %s to be deleted, got %v", name, platform, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"time"
+
+	apisconfig "github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// progressUpdateInterval is how often the progress of running steps is updated.
+const progressUpdateInterval = 10 * time.Second
+
+// updateStepsProgress sets the percentage of each step of tr from the time elapsed since
+// the step started against the timeout of tr. It returns true if a step is still running,
+// in which case its progress needs to be updated again later.
+func updateStepsProgress(tr *v1beta1.TaskRun, now time.Time) bool {
+	timeout := tr.GetTimeout()
+	running := false
+	for i := range tr.Status.Steps {
+		step := &tr.Status.Steps[i]
+		switch {
+		case step.Terminated != nil:
+			step.Percentage = int32Ptr(100)
+		case timeout == apisconfig.NoTimeoutDuration:
+			step.Percentage = nil
+		case step.Running != nil:
+			percentage := int64(now.Sub(step.Running.StartedAt.Time) * 100 / timeout)
+			if percentage > 100 {
+				percentage = 100
+			} else if percentage < 0 {
+				percentage = 0
+			}
+			step.Percentage = int32Ptr(int32(percentage))
+			running = true
+		default:
+			step.Percentage = int32Ptr(0)
+		}
+	}
+	return running
+}
+
+func int32Ptr(i int32) *int32 { return &i }
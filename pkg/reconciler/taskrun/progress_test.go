@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateStepsProgress(t *testing.T) {
+	now := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	runningFor := func(d time.Duration) v1beta1.StepState {
+		return v1beta1.StepState{ContainerState: corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-d))},
+		}}
+	}
+	terminated := v1beta1.StepState{ContainerState: corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{},
+	}}
+	waiting := v1beta1.StepState{ContainerState: corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{},
+	}}
+	for _, tc := range []struct {
+		desc            string
+		timeout         *metav1.Duration
+		steps           []v1beta1.StepState
+		wantPercentages []*int32
+		wantRunning     bool
+	}{{
+		desc:            "running",
+		timeout:         &metav1.Duration{Duration: 4 * time.Minute},
+		steps:           []v1beta1.StepState{terminated, runningFor(time.Minute), waiting},
+		wantPercentages: []*int32{int32Ptr(100), int32Ptr(25), int32Ptr(0)},
+		wantRunning:     true,
+	}, {
+		desc:            "running past the timeout",
+		timeout:         &metav1.Duration{Duration: time.Minute},
+		steps:           []v1beta1.StepState{runningFor(2 * time.Minute)},
+		wantPercentages: []*int32{int32Ptr(100)},
+		wantRunning:     true,
+	}, {
+		desc:            "default timeout",
+		steps:           []v1beta1.StepState{runningFor(6 * time.Minute)},
+		wantPercentages: []*int32{int32Ptr(10)},
+		wantRunning:     true,
+	}, {
+		desc:            "no timeout",
+		timeout:         &metav1.Duration{Duration: 0},
+		steps:           []v1beta1.StepState{terminated, runningFor(time.Minute), waiting},
+		wantPercentages: []*int32{int32Ptr(100), nil, nil},
+	}, {
+		desc:            "terminated",
+		timeout:         &metav1.Duration{Duration: time.Minute},
+		steps:           []v1beta1.StepState{terminated, terminated},
+		wantPercentages: []*int32{int32Ptr(100), int32Ptr(100)},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr := &v1beta1.TaskRun{
+				Spec:   v1beta1.TaskRunSpec{Timeout: tc.timeout},
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{Steps: tc.steps}},
+			}
+			running := updateStepsProgress(tr, now)
+			if running != tc.wantRunning {
+				t.Errorf("Expected a step to be running: %t, got %t", tc.wantRunning, running)
+			}
+			var percentages []*int32
+			for _, step := range tr.Status.Steps {
+				percentages = append(percentages, step.Percentage)
+			}
+			if d := cmp.Diff(tc.wantPercentages, percentages); d != "" {
+				t.Errorf("Unexpected step percentages %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// ReasonResourceOverprovisioned indicates that a step used much less memory or CPU than
+	// it requests in its recent TaskRuns.
+	ReasonResourceOverprovisioned = "ResourceOverprovisioned"
+	// ReasonResourceUnderprovisioned indicates that a step used more memory or CPU than its limit.
+	ReasonResourceUnderprovisioned = "ResourceUnderprovisioned"
+
+	// overprovisionedRuns is the number of consecutive TaskRuns in which a step has to use
+	// less than overprovisionedRatio of its request to be reported as overprovisioned.
+	overprovisionedRuns = 3
+	// overprovisionedRatio is the ratio of its request under which a step is overprovisioned.
+	overprovisionedRatio = 0.5
+)
+
+// checkStepsResourceUsage emits warning events on tr, which just finished, when the peak
+// usage of its steps recorded with the "enable-step-metrics" feature flag shows that they
+// request too much memory or CPU, or exceed their limits. A step is overprovisioned when it
+// used less than half of its request in tr and in the previous TaskRuns of the same Task,
+// and the event suggests a request fitting the highest of these peaks.
+func (c *Reconciler) checkStepsResourceUsage(ctx context.Context, tr *v1beta1.TaskRun, ts *v1beta1.TaskSpec) {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableStepMetrics {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	recorder := controller.GetEventRecorder(ctx)
+
+	steps, err := v1beta1.MergeStepsWithStepTemplate(ts.StepTemplate, ts.Steps)
+	if err != nil {
+		logger.Errorf("Failed to merge the steps of taskrun %s with their template: %v", tr.Name, err)
+		return
+	}
+	history := c.previousTaskRuns(tr, overprovisionedRuns-1)
+
+	for _, step := range steps {
+		if step.Name == "" {
+			continue
+		}
+		metrics := stepMetrics(tr, step.Name)
+		if metrics == nil {
+			continue
+		}
+		for _, r := range []struct {
+			name corev1.ResourceName
+			peak func(*v1beta1.StepMetrics) *resource.Quantity
+		}{{
+			name: corev1.ResourceMemory,
+			peak: func(m *v1beta1.StepMetrics) *resource.Quantity { return m.PeakMemory },
+		}, {
+			name: corev1.ResourceCPU,
+			peak: func(m *v1beta1.StepMetrics) *resource.Quantity { return m.PeakCPU },
+		}} {
+			peak := r.peak(metrics)
+			if peak == nil {
+				continue
+			}
+			if limit, ok := step.Resources.Limits[r.name]; ok && peak.Cmp(limit) > 0 {
+				recorder.Eventf(tr, corev1.EventTypeWarning, ReasonResourceUnderprovisioned,
+					"Step %q used %s of %s, more than its limit of %s", step.Name, peak.String(), r.name, limit.String())
+				continue
+			}
+			request, ok := step.Resources.Requests[r.name]
+			if !ok || request.IsZero() || len(history) < overprovisionedRuns-1 {
+				continue
+			}
+			highest := peak
+			overprovisioned := isOverprovisioned(peak, request)
+			for _, previous := range history {
+				m := stepMetrics(previous, step.Name)
+				if m == nil || r.peak(m) == nil || !isOverprovisioned(r.peak(m), request) {
+					overprovisioned = false
+					break
+				}
+				if r.peak(m).Cmp(*highest) > 0 {
+					highest = r.peak(m)
+				}
+			}
+			if overprovisioned {
+				recorder.Eventf(tr, corev1.EventTypeWarning, ReasonResourceOverprovisioned,
+					"Step %q used less than half of its %s request of %s in its last %d runs, consider requesting %s",
+					step.Name, r.name, request.String(), overprovisionedRuns, suggestedRequest(r.name, *highest).String())
+			}
+		}
+	}
+}
+
+// previousTaskRuns returns up to n of the most recently completed TaskRuns of the Task
+// referenced by tr, excluding tr.
+func (c *Reconciler) previousTaskRuns(tr *v1beta1.TaskRun, n int) []*v1beta1.TaskRun {
+	taskName, ok := tr.Labels[pipeline.GroupName+pipeline.TaskLabelKey]
+	if !ok || tr.Spec.TaskRef == nil {
+		return nil
+	}
+	trs, err := c.taskRunLister.TaskRuns(tr.Namespace).List(labels.SelectorFromSet(labels.Set{
+		pipeline.GroupName + pipeline.TaskLabelKey: taskName,
+	}))
+	if err != nil {
+		return nil
+	}
+	var previous []*v1beta1.TaskRun
+	for _, p := range trs {
+		if p.Name == tr.Name || !p.IsDone() || p.Status.CompletionTime == nil ||
+			p.Spec.TaskRef == nil || p.Spec.TaskRef.Kind != tr.Spec.TaskRef.Kind {
+			continue
+		}
+		previous = append(previous, p)
+	}
+	sort.Slice(previous, func(i, j int) bool {
+		return previous[j].Status.CompletionTime.Before(previous[i].Status.CompletionTime)
+	})
+	if len(previous) > n {
+		previous = previous[:n]
+	}
+	return previous
+}
+
+// stepMetrics returns the metrics recorded for the step called name of tr.
+func stepMetrics(tr *v1beta1.TaskRun, name string) *v1beta1.StepMetrics {
+	for _, s := range tr.Status.Steps {
+		if s.Name == name {
+			return s.Metrics
+		}
+	}
+	return nil
+}
+
+// isOverprovisioned returns true if peak is less than overprovisionedRatio of request.
+func isOverprovisioned(peak *resource.Quantity, request resource.Quantity) bool {
+	return float64(peak.MilliValue()) < overprovisionedRatio*float64(request.MilliValue())
+}
+
+// suggestedRequest returns a request for the resource called name leaving 25% of headroom
+// above peak, rounded up to the mebibyte for memory and to the millicore for CPU.
+func suggestedRequest(name corev1.ResourceName, peak resource.Quantity) *resource.Quantity {
+	if name == corev1.ResourceMemory {
+		const mebibyte = 1024 * 1024
+		mebibytes := (peak.Value()*5/4 + mebibyte - 1) / mebibyte
+		return resource.NewQuantity(mebibytes*mebibyte, resource.BinarySI)
+	}
+	return resource.NewMilliQuantity((peak.MilliValue()*5+3)/4, resource.DecimalSI)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+)
+
+func TestCheckStepsResourceUsage(t *testing.T) {
+	start := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{
+			Name:  "build",
+			Image: "builder",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+					corev1.ResourceCPU:    resource.MustParse("1"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		}}},
+	}
+	// taskRun returns the n-th TaskRun of the Task, in which the build step used memory and cpu.
+	taskRun := func(n int, memory, cpu string) *v1beta1.TaskRun {
+		tr := &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("build-%d", n),
+				Namespace: "foo",
+				Labels:    map[string]string{pipeline.GroupName + pipeline.TaskLabelKey: "build"},
+			},
+			Spec: v1beta1.TaskRunSpec{TaskRef: &v1beta1.TaskRef{Name: "build"}},
+		}
+		tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+		tr.Status.CompletionTime = &metav1.Time{Time: start.Add(time.Duration(n) * time.Hour)}
+		peakMemory, peakCPU := resource.MustParse(memory), resource.MustParse(cpu)
+		tr.Status.Steps = []v1beta1.StepState{{
+			Name:    "build",
+			Metrics: &v1beta1.StepMetrics{PeakMemory: &peakMemory, PeakCPU: &peakCPU},
+		}}
+		return tr
+	}
+	for _, tc := range []struct {
+		desc       string
+		disabled   bool
+		history    []*v1beta1.TaskRun
+		tr         *v1beta1.TaskRun
+		wantEvents []string
+	}{{
+		desc:    "overprovisioned in the last three runs",
+		history: []*v1beta1.TaskRun{taskRun(0, "900Mi", "900m"), taskRun(1, "300Mi", "100m"), taskRun(2, "400Mi", "200m")},
+		tr:      taskRun(3, "200Mi", "300m"),
+		wantEvents: []string{
+			`Warning ResourceOverprovisioned Step "build" used less than half of its memory request of 1Gi in its last 3 runs, consider requesting 500Mi`,
+			`Warning ResourceOverprovisioned Step "build" used less than half of its cpu request of 1 in its last 3 runs, consider requesting 375m`,
+		},
+	}, {
+		desc:    "overprovisioned in the last two runs only",
+		history: []*v1beta1.TaskRun{taskRun(0, "300Mi", "100m"), taskRun(1, "900Mi", "900m"), taskRun(2, "400Mi", "200m")},
+		tr:      taskRun(3, "200Mi", "300m"),
+	}, {
+		desc:    "not enough runs",
+		history: []*v1beta1.TaskRun{taskRun(0, "300Mi", "100m")},
+		tr:      taskRun(1, "200Mi", "300m"),
+	}, {
+		desc:    "over the limit",
+		history: []*v1beta1.TaskRun{taskRun(0, "300Mi", "100m"), taskRun(1, "300Mi", "100m")},
+		tr:      taskRun(2, "3Gi", "100m"),
+		wantEvents: []string{
+			`Warning ResourceUnderprovisioned Step "build" used 3Gi of memory, more than its limit of 2Gi`,
+			`Warning ResourceOverprovisioned Step "build" used less than half of its cpu request of 1 in its last 3 runs, consider requesting 125m`,
+		},
+	}, {
+		desc:     "disabled",
+		disabled: true,
+		history:  []*v1beta1.TaskRun{taskRun(0, "300Mi", "100m"), taskRun(1, "300Mi", "100m")},
+		tr:       taskRun(2, "3Gi", "100m"),
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, tr := range append(tc.history, tc.tr) {
+				if err := indexer.Add(tr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := &Reconciler{taskRunLister: listers.NewTaskRunLister(indexer)}
+
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableStepMetrics = !tc.disabled
+			ctx := config.ToContext(context.Background(), cfg)
+			recorder := record.NewFakeRecorder(10)
+			ctx = controller.WithEventRecorder(ctx, recorder)
+
+			c.checkStepsResourceUsage(ctx, tc.tr, ts)
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if d := cmp.Diff(tc.wantEvents, events); d != "" {
+				t.Errorf("Unexpected events %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
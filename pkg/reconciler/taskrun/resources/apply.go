@@ -30,7 +30,12 @@ import (
 // ApplyParameters applies the params from a TaskRun.Input.Parameters to a TaskSpec
 func ApplyParameters(spec *v1beta1.TaskSpec, tr *v1beta1.TaskRun, defaults ...v1beta1.ParamSpec) *v1beta1.TaskSpec {
 	// This assumes that the TaskRun inputs have been validated against what the Task requests.
+	stringReplacements, arrayReplacements := paramReplacements(tr, defaults)
+	return ApplyReplacements(spec, stringReplacements, arrayReplacements)
+}
 
+// paramReplacements returns the values of the params of tr, or of their defaults.
+func paramReplacements(tr *v1beta1.TaskRun, defaults []v1beta1.ParamSpec) (map[string]string, map[string][]string) {
 	// stringReplacements is used for standard single-string stringReplacements, while arrayReplacements contains arrays
 	// that need to be further processed.
 	stringReplacements := map[string]string{}
@@ -57,12 +62,17 @@ func ApplyParameters(spec *v1beta1.TaskSpec, tr *v1beta1.TaskRun, defaults ...v1
 			// FIXME(vdemeester) Remove that with deprecating v1beta1
 			stringReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Value.StringVal
 		} else {
-			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.ArrayVal
+			value := p.Value.ArrayVal
+			if p.Append {
+				// The supplied values are appended to the default ones
+				value = append(append([]string{}, arrayReplacements[fmt.Sprintf("params.%s", p.Name)]...), value...)
+			}
+			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = value
 			// FIXME(vdemeester) Remove that with deprecating v1beta1
-			arrayReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Value.ArrayVal
+			arrayReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = value
 		}
 	}
-	return ApplyReplacements(spec, stringReplacements, arrayReplacements)
+	return stringReplacements, arrayReplacements
 }
 
 // ApplyResources applies the substitution from values in resources which are referenced in spec as subitems
@@ -99,13 +109,37 @@ func ApplyResources(spec *v1beta1.TaskSpec, resolvedResources map[string]v1beta1
 // ApplyContexts applies the substitution from $(context.(taskRun|task).*) with the specified values.
 // Uses "" as a default if a value is not available.
 func ApplyContexts(spec *v1beta1.TaskSpec, rtr *ResolvedTaskResources, tr *v1beta1.TaskRun) *v1beta1.TaskSpec {
-	replacements := map[string]string{
+	return ApplyReplacements(spec, contextReplacements(rtr, tr), map[string][]string{})
+}
+
+func contextReplacements(rtr *ResolvedTaskResources, tr *v1beta1.TaskRun) map[string]string {
+	return map[string]string{
 		"context.taskRun.name":      tr.Name,
 		"context.task.name":         rtr.TaskName,
 		"context.taskRun.namespace": tr.Namespace,
 		"context.taskRun.uid":       string(tr.ObjectMeta.UID),
 	}
-	return ApplyReplacements(spec, replacements, map[string][]string{})
+}
+
+// ApplyWorkspaceSubPaths returns the workspace bindings of tr with the substitution of its
+// params and $(context.(taskRun|task).*) applied to their subPath. The name of tr is appended
+// to the subPath of the bindings setting subPathPerRun.
+func ApplyWorkspaceSubPaths(rtr *ResolvedTaskResources, tr *v1beta1.TaskRun, defaults ...v1beta1.ParamSpec) []v1beta1.WorkspaceBinding {
+	replacements, _ := paramReplacements(tr, defaults)
+	for k, v := range contextReplacements(rtr, tr) {
+		replacements[k] = v
+	}
+	wb := make([]v1beta1.WorkspaceBinding, 0, len(tr.Spec.Workspaces))
+	for _, b := range tr.Spec.Workspaces {
+		b := *b.DeepCopy()
+		b.SubPath = substitution.ApplyReplacements(b.SubPath, replacements)
+		if b.SubPathPerRun {
+			b.SubPath = filepath.Join(b.SubPath, tr.Name)
+			b.SubPathPerRun = false
+		}
+		wb = append(wb, b)
+	}
+	return wb
 }
 
 // ApplyWorkspaces applies the substitution from paths that the workspaces in w are mounted to, the
@@ -132,12 +166,17 @@ func ApplyWorkspaces(spec *v1beta1.TaskSpec, w []v1beta1.WorkspaceDeclaration, w
 }
 
 // ApplyTaskResults applies the substitution from values in results which are referenced in spec as subitems
-// of the replacementStr.
+// of the replacementStr, and of the directory results are written to.
 func ApplyTaskResults(spec *v1beta1.TaskSpec) *v1beta1.TaskSpec {
-	stringReplacements := map[string]string{}
+	stringReplacements := map[string]string{
+		"tekton.results.dir": pipeline.DefaultResultPath,
+	}
 
 	for _, result := range spec.Results {
 		stringReplacements[fmt.Sprintf("results.%s.path", result.Name)] = filepath.Join(pipeline.DefaultResultPath, result.Name)
+		for _, alias := range result.Aliases {
+			stringReplacements[fmt.Sprintf("results.%s.path", alias)] = filepath.Join(pipeline.DefaultResultPath, result.Name)
+		}
 	}
 	return ApplyReplacements(spec, stringReplacements, map[string][]string{})
 }
@@ -214,7 +253,13 @@ func ApplyReplacements(spec *v1beta1.TaskSpec, stringReplacements map[string]str
 	// Apply variable substitution to the sidecar definitions
 	sidecars := spec.Sidecars
 	for i := range sidecars {
-		v1beta1.ApplyContainerReplacements(&sidecars[i].Container, stringReplacements, arrayReplacements)
+		v1beta1.ApplySidecarReplacements(&sidecars[i], stringReplacements, arrayReplacements)
+	}
+
+	// Apply variable substitution to the init container definitions
+	initContainers := spec.InitContainers
+	for i := range initContainers {
+		v1beta1.ApplyContainerReplacements(&initContainers[i], stringReplacements, arrayReplacements)
 	}
 
 	return spec
@@ -55,7 +55,20 @@ var (
 					Name:  "foo",
 					Value: "$(inputs.params.FOO)",
 				}},
+				Command: []string{"$(params.myarray)"},
+				Args:    []string{"--foo=$(params.FOO)", "$(params.myarray)"},
 			},
+		}, {
+			Container: corev1.Container{
+				Name:  "bar",
+				Image: "$(params.myimage)",
+			},
+			Script: "echo $(params.FOO)",
+		}},
+		InitContainers: []corev1.Container{{
+			Name:  "setup",
+			Image: "$(inputs.params.myimage)",
+			Args:  []string{"$(inputs.params.FOO)"},
 		}},
 		StepTemplate: &corev1.Container{
 			Env: []corev1.EnvVar{{
@@ -496,6 +509,69 @@ func TestApplyArrayParameters(t *testing.T) {
 		want: applyMutation(arrayParamTaskSpec, func(spec *v1beta1.TaskSpec) {
 			spec.Steps[1].Args = []string{"first", "second", "defaulted", "value!", "last"}
 		}),
+	}, {
+		name: "array parameter appended to the default",
+		args: args{
+			ts: arrayParamTaskSpec,
+			tr: &v1beta1.TaskRun{
+				Spec: v1beta1.TaskRunSpec{
+					Params: []v1beta1.Param{{
+						Name:   "array-param",
+						Value:  *tb.ArrayOrString("foo", "bar"),
+						Append: true,
+					}},
+				},
+			},
+			dp: []v1beta1.ParamSpec{{
+				Name:    "array-param",
+				Default: tb.ArrayOrString("defaulted", "value!"),
+			}},
+		},
+		want: applyMutation(arrayParamTaskSpec, func(spec *v1beta1.TaskSpec) {
+			spec.Steps[1].Args = []string{"first", "second", "defaulted", "value!", "foo", "bar", "last"}
+		}),
+	}, {
+		name: "array parameter with 0 elements appended to the default",
+		args: args{
+			ts: arrayParamTaskSpec,
+			tr: &v1beta1.TaskRun{
+				Spec: v1beta1.TaskRunSpec{
+					Params: []v1beta1.Param{{
+						Name:   "array-param",
+						Value:  v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray},
+						Append: true,
+					}},
+				},
+			},
+			dp: []v1beta1.ParamSpec{{
+				Name:    "array-param",
+				Default: tb.ArrayOrString("defaulted", "value!"),
+			}},
+		},
+		want: applyMutation(arrayParamTaskSpec, func(spec *v1beta1.TaskSpec) {
+			spec.Steps[1].Args = []string{"first", "second", "defaulted", "value!", "last"}
+		}),
+	}, {
+		name: "array parameter appended without default",
+		args: args{
+			ts: arrayParamTaskSpec,
+			tr: &v1beta1.TaskRun{
+				Spec: v1beta1.TaskRunSpec{
+					Params: []v1beta1.Param{{
+						Name:   "array-param",
+						Value:  *tb.ArrayOrString("foo", "bar"),
+						Append: true,
+					}},
+				},
+			},
+			dp: []v1beta1.ParamSpec{{
+				Name: "array-param",
+				Type: v1beta1.ParamTypeArray,
+			}},
+		},
+		want: applyMutation(arrayParamTaskSpec, func(spec *v1beta1.TaskSpec) {
+			spec.Steps[1].Args = []string{"first", "second", "foo", "bar", "last"}
+		}),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -516,6 +592,9 @@ func TestApplyParameters(t *testing.T) {
 			}, {
 				Name:  "FOO",
 				Value: *tb.ArrayOrString("world"),
+			}, {
+				Name:  "myarray",
+				Value: *tb.ArrayOrString("a", "b", "c"),
 			}},
 		},
 	}
@@ -568,6 +647,13 @@ func TestApplyParameters(t *testing.T) {
 
 		spec.Sidecars[0].Container.Image = "bar"
 		spec.Sidecars[0].Container.Env[0].Value = "world"
+		spec.Sidecars[0].Container.Command = []string{"a", "b", "c"}
+		spec.Sidecars[0].Container.Args = []string{"--foo=world", "a", "b", "c"}
+		spec.Sidecars[1].Container.Image = "bar"
+		spec.Sidecars[1].Script = "echo world"
+
+		spec.InitContainers[0].Image = "bar"
+		spec.InitContainers[0].Args = []string{"world"}
 	})
 	got := resources.ApplyParameters(simpleTaskSpec, tr, dp...)
 	if d := cmp.Diff(want, got); d != "" {
@@ -768,6 +854,60 @@ func TestApplyWorkspaces(t *testing.T) {
 	}
 }
 
+func TestApplyWorkspaceSubPaths(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-1", Namespace: "ci"},
+		Spec: v1beta1.TaskRunSpec{
+			Params: []v1beta1.Param{{Name: "project", Value: v1beta1.NewArrayOrString("tekton")}},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:                  "source",
+				SubPath:               "$(params.project)/$(context.taskRun.name)",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "cache",
+				SubPath:               "$(context.task.name)/$(params.branch)",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "output",
+				SubPath:               "$(context.taskRun.namespace)",
+				SubPathPerRun:         true,
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}, {
+				Name:                  "scratch",
+				SubPathPerRun:         true,
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+			}},
+		},
+	}
+	rtr := &resources.ResolvedTaskResources{TaskName: "compile"}
+	defaults := []v1beta1.ParamSpec{{Name: "branch", Default: &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "main"}}}
+
+	want := []v1beta1.WorkspaceBinding{{
+		Name:                  "source",
+		SubPath:               "tekton/build-1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:                  "cache",
+		SubPath:               "compile/main",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:                  "output",
+		SubPath:               "ci/build-1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}, {
+		Name:                  "scratch",
+		SubPath:               "build-1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+	}}
+	got := resources.ApplyWorkspaceSubPaths(rtr, tr, defaults...)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ApplyWorkspaceSubPaths() got diff %s", diff.PrintWantGot(d))
+	}
+	if tr.Spec.Workspaces[0].SubPath != "$(params.project)/$(context.taskRun.name)" || !tr.Spec.Workspaces[2].SubPathPerRun {
+		t.Errorf("Expected the workspace bindings of the TaskRun to be left unchanged, got %v", tr.Spec.Workspaces)
+	}
+}
+
 func TestContext(t *testing.T) {
 	for _, tc := range []struct {
 		description string
@@ -984,7 +1124,8 @@ func TestTaskResults(t *testing.T) {
 			Description: "The current date in unix timestamp format",
 		}, {
 			Name:        "current-date-human-readable",
-			Description: "The current date in humand readable format"},
+			Description: "The current date in humand readable format",
+			Aliases:     []string{"current-date"}},
 		},
 		Steps: []v1beta1.Step{{
 			Container: corev1.Container{
@@ -997,14 +1138,23 @@ func TestTaskResults(t *testing.T) {
 			Container: corev1.Container{
 				Name:  "print-date-human-readable",
 				Image: "bash:latest",
+				Args:  []string{"$(tekton.results.dir)"},
 			},
 			Script: "#!/usr/bin/env bash\ndate | tee $(results.current-date-human-readable.path)",
+		}, {
+			Container: corev1.Container{
+				Name:  "print-date-by-alias",
+				Image: "bash:latest",
+			},
+			Script: "#!/usr/bin/env bash\ndate | tee $(results.current-date.path)",
 		}},
 	}
 	want := applyMutation(ts, func(spec *v1beta1.TaskSpec) {
 		spec.Steps[0].Script = "#!/usr/bin/env bash\ndate +%s | tee /tekton/results/current-date-unix-timestamp"
 		spec.Steps[0].Args[0] = "/tekton/results/current-date-unix-timestamp"
 		spec.Steps[1].Script = "#!/usr/bin/env bash\ndate | tee /tekton/results/current-date-human-readable"
+		spec.Steps[1].Args[0] = "/tekton/results"
+		spec.Steps[2].Script = "#!/usr/bin/env bash\ndate | tee /tekton/results/current-date-human-readable"
 	})
 	got := resources.ApplyTaskResults(ts)
 	if d := cmp.Diff(want, got); d != "" {
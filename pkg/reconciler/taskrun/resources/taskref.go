@@ -19,8 +19,10 @@ package resources
 import (
 	"fmt"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/remote/oci"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -48,3 +50,29 @@ func (l *LocalTaskRefResolver) GetTask(name string) (v1beta1.TaskInterface, erro
 	}
 	return l.Tektonclient.TektonV1beta1().Tasks(l.Namespace).Get(name, metav1.GetOptions{})
 }
+
+// BundleTaskRefResolver resolves task references to the Tasks stored in Tekton Bundles, OCI images
+// fetched from a registry.
+type BundleTaskRefResolver struct {
+	Keychain authn.Keychain
+}
+
+// GetTask will fetch the bundle of ref and retrieve either the Task or ClusterTask it references from it.
+// It will return an error if the bundle can't be fetched or doesn't contain an appropriate Task.
+func (b *BundleTaskRefResolver) GetTask(ref *v1beta1.TaskRef) (v1beta1.TaskInterface, error) {
+	kind := "task"
+	if ref.Kind == v1beta1.ClusterTaskKind {
+		kind = "clustertask"
+	}
+	obj, err := oci.NewResolver(ref.Bundle, b.Keychain).Get(kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch task := obj.(type) {
+	case *v1beta1.Task:
+		return task, nil
+	case *v1beta1.ClusterTask:
+		return task, nil
+	}
+	return nil, fmt.Errorf("bundle %s contains a %s %s which is not a v1beta1 Task", ref.Bundle, kind, ref.Name)
+}
@@ -17,15 +17,21 @@
 package resources_test
 
 import (
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/registry"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
 )
 
@@ -96,3 +102,60 @@ func TestTaskRef(t *testing.T) {
 		})
 	}
 }
+
+func TestBundleTaskRef(t *testing.T) {
+	// Set up a fake registry to push the bundle to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := test.CreateImage(u.Host+"/bundles/simple",
+		tb.Task("simple", tb.TaskType()),
+		tb.ClusterTask("cluster-task", tb.ClusterTaskType()),
+	)
+	if err != nil {
+		t.Fatalf("could not push image: %#v", err)
+	}
+
+	testcases := []struct {
+		name     string
+		ref      *v1beta1.TaskRef
+		expected runtime.Object
+		wantErr  bool
+	}{
+		{
+			name:     "bundle-task",
+			ref:      &v1beta1.TaskRef{Name: "simple", Bundle: bundle},
+			expected: tb.Task("simple", tb.TaskType()),
+		},
+		{
+			name:     "bundle-clustertask",
+			ref:      &v1beta1.TaskRef{Name: "cluster-task", Kind: v1beta1.ClusterTaskKind, Bundle: bundle},
+			expected: tb.ClusterTask("cluster-task", tb.ClusterTaskType()),
+		},
+		{
+			name:    "task-not-in-bundle",
+			ref:     &v1beta1.TaskRef{Name: "dummy", Bundle: bundle},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := &resources.BundleTaskRefResolver{Keychain: authn.DefaultKeychain}
+
+			task, err := resolver.GetTask(tc.ref)
+			if tc.wantErr && err == nil {
+				t.Fatal("Expected error but found nil instead")
+			} else if !tc.wantErr && err != nil {
+				t.Fatalf("Received unexpected error ( %#v )", err)
+			}
+
+			if d := cmp.Diff(task, tc.expected); tc.expected != nil && d != "" {
+				t.Error(diff.PrintWantGot(d))
+			}
+		})
+	}
+}
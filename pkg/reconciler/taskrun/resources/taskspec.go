@@ -19,10 +19,14 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/contexts"
+	"github.com/tektoncd/pipeline/pkg/substitution"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // GetTask is a function used to retrieve Tasks.
@@ -32,6 +36,9 @@ type GetTaskRun func(string) (*v1beta1.TaskRun, error)
 // GetClusterTask is a function that will retrieve the Task from name and namespace.
 type GetClusterTask func(name string) (v1beta1.TaskInterface, error)
 
+// GetBundleTask is a function that will retrieve the Task referenced by a TaskRef from its bundle.
+type GetBundleTask func(ref *v1beta1.TaskRef) (v1beta1.TaskInterface, error)
+
 // GetTaskData will retrieve the Task metadata and Spec associated with the
 // provided TaskRun. This can come from a reference Task or from the TaskRun's
 // metadata and embedded TaskSpec.
@@ -40,8 +47,12 @@ func GetTaskData(ctx context.Context, taskRun *v1beta1.TaskRun, getTask GetTask)
 	taskSpec := v1beta1.TaskSpec{}
 	switch {
 	case taskRun.Spec.TaskRef != nil && taskRun.Spec.TaskRef.Name != "":
+		name, err := taskRefName(ctx, taskRun)
+		if err != nil {
+			return nil, nil, err
+		}
 		// Get related task for taskrun
-		t, err := getTask(taskRun.Spec.TaskRef.Name)
+		t, err := getTask(name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error when listing tasks for taskRun %s: %w", taskRun.Name, err)
 		}
@@ -56,3 +67,21 @@ func GetTaskData(ctx context.Context, taskRun *v1beta1.TaskRun, getTask GetTask)
 	}
 	return &taskMeta, &taskSpec, nil
 }
+
+// taskRefName returns the name of the Task taskRun references, with the params
+// of taskRun substituted when the alpha API fields are enabled.
+func taskRefName(ctx context.Context, taskRun *v1beta1.TaskRun) (string, error) {
+	name := taskRun.Spec.TaskRef.Name
+	if config.FromContextOrDefaults(ctx).FeatureFlags.EnableAPIFields != config.AlphaAPIFields {
+		return name, nil
+	}
+	stringReplacements, _ := paramReplacements(taskRun, nil)
+	resolved := substitution.ApplyReplacements(name, stringReplacements)
+	if resolved == name {
+		return name, nil
+	}
+	if errs := validation.IsDNS1123Subdomain(resolved); len(errs) != 0 {
+		return "", fmt.Errorf("taskRun %s references Task %q, resolved from %q, which isn't a valid name: %s", taskRun.Name, resolved, name, strings.Join(errs, ","))
+	}
+	return resolved, nil
+}
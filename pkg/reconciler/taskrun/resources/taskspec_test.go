@@ -21,6 +21,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -122,3 +123,63 @@ func TestGetTaskSpec_Error(t *testing.T) {
 		t.Fatalf("Expected error when unable to find referenced Task but got none")
 	}
 }
+
+func TestGetTaskSpec_RefNameParams(t *testing.T) {
+	task := &v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "build-go"}}
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "mytaskrun"},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "build-$(params.language)"},
+			Params:  []v1beta1.Param{{Name: "language", Value: v1beta1.NewArrayOrString("go")}},
+		},
+	}
+	alpha := config.FromContextOrDefaults(context.Background())
+	alpha.FeatureFlags.EnableAPIFields = config.AlphaAPIFields
+	alphaCtx := config.ToContext(context.Background(), alpha)
+
+	for _, tc := range []struct {
+		desc     string
+		ctx      context.Context
+		language string
+		wantName string
+		wantErr  bool
+	}{{
+		desc:     "params substituted",
+		ctx:      alphaCtx,
+		language: "go",
+		wantName: "build-go",
+	}, {
+		desc:     "params not substituted without alpha fields",
+		ctx:      context.Background(),
+		language: "go",
+		wantName: "build-$(params.language)",
+	}, {
+		desc:     "invalid name",
+		ctx:      alphaCtx,
+		language: "Go_1.16",
+		wantErr:  true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr := tr.DeepCopy()
+			tr.Spec.Params[0].Value = v1beta1.NewArrayOrString(tc.language)
+			var gotName string
+			gt := func(n string) (v1beta1.TaskInterface, error) {
+				gotName = n
+				return task, nil
+			}
+			_, _, err := GetTaskData(tc.ctx, tr, gt)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error getting a Task with an invalid name")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect error getting task spec but got: %s", err)
+			}
+			if gotName != tc.wantName {
+				t.Errorf("Expected the Task %q to be fetched, got %q", tc.wantName, gotName)
+			}
+		})
+	}
+}
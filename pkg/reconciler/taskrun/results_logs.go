@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/termination"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resultsLogTailLines is the number of lines read from the end of the logs of a
+// step. The results are printed once the command of the step has exited, so
+// only output of processes left running in the background can follow them.
+const resultsLogTailLines int64 = 100
+
+// podLogsSource returns the logs of a container of a Pod.
+type podLogsSource interface {
+	ContainerLogs(namespace, name, container string) ([]byte, error)
+}
+
+// kubeLogsSource reads the logs of containers from the Kubernetes API.
+type kubeLogsSource struct {
+	client kubernetes.Interface
+}
+
+func (s *kubeLogsSource) ContainerLogs(namespace, name, container string) ([]byte, error) {
+	tailLines := resultsLogTailLines
+	return s.client.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	}).DoRaw()
+}
+
+// updateTaskRunResultsFromLogs adds the results the steps of tr printed to their
+// logs when the "results-from" feature flag is "container-logs". Only the steps
+// whose entrypoint was given a delimiter print their results, the results of
+// the others are read from their termination message.
+func (c *Reconciler) updateTaskRunResultsFromLogs(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod) error {
+	if !resultsAvailable(ctx, tr) {
+		return nil
+	}
+	delimiters := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		if delimiter := podconvert.ResultsLogDelimiter(container); delimiter != "" {
+			delimiters[container.Name] = delimiter
+		}
+	}
+	if len(delimiters) == 0 {
+		return nil
+	}
+
+	sorted := pod.DeepCopy()
+	podconvert.SortContainerStatuses(sorted)
+	found := false
+	iterations := newForEachResults(pod)
+	for _, cs := range sorted.Status.ContainerStatuses {
+		delimiter, ok := delimiters[cs.Name]
+		if !ok || cs.State.Terminated == nil {
+			continue
+		}
+		logs, err := c.podLogs.ContainerLogs(pod.Namespace, pod.Name, cs.Name)
+		if err != nil {
+			return fmt.Errorf("failed to read the results of container %s of pod %s from its logs: %w", cs.Name, pod.Name, err)
+		}
+		taskResults, pipelineResourceResults := getResults(termination.ParseResultsLog(logs, delimiter))
+		if !iterations.add(cs.Name, taskResults) {
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults, taskResults...)
+		}
+		tr.Status.ResourcesResult = append(tr.Status.ResourcesResult, pipelineResourceResults...)
+		found = true
+	}
+	if found {
+		tr.Status.TaskRunResults = append(tr.Status.TaskRunResults, iterations.merged()...)
+		tr.Status.TaskRunResults = removeDuplicateResults(tr.Status.TaskRunResults)
+	}
+	return nil
+}
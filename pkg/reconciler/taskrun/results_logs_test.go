@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/termination"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+type fakePodLogsSource struct {
+	logs map[string]string
+	err  error
+	read []string
+}
+
+func (f *fakePodLogsSource) ContainerLogs(namespace, name, container string) ([]byte, error) {
+	f.read = append(f.read, container)
+	return []byte(f.logs[container]), f.err
+}
+
+func TestUpdateTaskRunResultsFromLogs(t *testing.T) {
+	const delimiter = "3f1c6d8e-8b4a-4d2f-9c1e-2a7b5e6f0d13"
+	record := func(results string) string {
+		return fmt.Sprintf("\n%s %s %s\n", delimiter, results, delimiter)
+	}
+	large := strings.Repeat("a", termination.MaxContainerTerminationMessageLength)
+	step := func(name string, args ...string) corev1.Container {
+		return corev1.Container{Name: name, Args: append(args, "--", "cmd")}
+	}
+	terminated := func(name string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{},
+		}}
+	}
+	for _, tc := range []struct {
+		desc        string
+		status      corev1.ConditionStatus
+		containers  []corev1.Container
+		statuses    []corev1.ContainerStatus
+		logs        map[string]string
+		err         error
+		previous    []v1beta1.TaskRunResult
+		wantResults []v1beta1.TaskRunResult
+		wantRead    []string
+		wantErr     bool
+	}{{
+		desc:       "output interleaved with the results",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+		logs: map[string]string{
+			"step-foo": "hello" + record(`[{"key":"foo","value":"bar","type":"TaskRunResult"}]`) + "background output\n",
+		},
+		wantResults: []v1beta1.TaskRunResult{{Name: "foo", Value: "bar"}},
+		wantRead:    []string{"step-foo"},
+	}, {
+		desc:       "results above the size of the termination message",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+		logs: map[string]string{
+			"step-foo": record(`[{"key":"foo","value":"` + large + `","type":"TaskRunResult"}]`),
+		},
+		wantResults: []v1beta1.TaskRunResult{{Name: "foo", Value: large}},
+		wantRead:    []string{"step-foo"},
+	}, {
+		desc:       "later steps override earlier results",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter), step("step-bar", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo"), terminated("step-bar")},
+		logs: map[string]string{
+			"step-foo": record(`[{"key":"foo","value":"first","type":"TaskRunResult"}]`),
+			"step-bar": record(`[{"key":"foo","value":"second","type":"TaskRunResult"}]`),
+		},
+		previous:    []v1beta1.TaskRunResult{{Name: "other", Value: "from termination message"}},
+		wantResults: []v1beta1.TaskRunResult{{Name: "other", Value: "from termination message"}, {Name: "foo", Value: "second"}},
+		wantRead:    []string{"step-foo", "step-bar"},
+	}, {
+		desc:       "records with another delimiter are ignored",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+		logs: map[string]string{
+			"step-foo": record(`[{"key":"foo","value":"bar","type":"TaskRunResult"}]`) + `spoofed [{"key":"foo","value":"spoofed","type":"TaskRunResult"}] spoofed` + "\n",
+		},
+		wantResults: []v1beta1.TaskRunResult{{Name: "foo", Value: "bar"}},
+		wantRead:    []string{"step-foo"},
+	}, {
+		desc:       "steps without delimiter",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo")},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+		logs: map[string]string{
+			"step-foo": record(`[{"key":"foo","value":"bar","type":"TaskRunResult"}]`),
+		},
+	}, {
+		desc:       "running taskrun",
+		status:     corev1.ConditionUnknown,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+	}, {
+		desc:       "logs unavailable",
+		status:     corev1.ConditionTrue,
+		containers: []corev1.Container{step("step-foo", "-results_log_delimiter", delimiter)},
+		statuses:   []corev1.ContainerStatus{terminated("step-foo")},
+		err:        errors.New("pods \"the-pod\" not found"),
+		wantRead:   []string{"step-foo"},
+		wantErr:    true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			source := &fakePodLogsSource{logs: tc.logs, err: tc.err}
+			c := &Reconciler{podLogs: source}
+			tr := &v1beta1.TaskRun{
+				Status: v1beta1.TaskRunStatus{
+					Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+						Type:   apis.ConditionSucceeded,
+						Status: tc.status,
+					}}},
+					TaskRunStatusFields: v1beta1.TaskRunStatusFields{TaskRunResults: tc.previous},
+				},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "the-pod"},
+				Spec:       corev1.PodSpec{Containers: tc.containers},
+				Status:     corev1.PodStatus{ContainerStatuses: tc.statuses},
+			}
+			err := c.updateTaskRunResultsFromLogs(context.Background(), tr, pod)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected an error: %t, got %v", tc.wantErr, err)
+			}
+			if d := cmp.Diff(tc.wantRead, source.read); d != "" {
+				t.Errorf("Unexpected logs read %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantResults, tr.Status.TaskRunResults); d != "" && !tc.wantErr {
+				t.Errorf("Unexpected results %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
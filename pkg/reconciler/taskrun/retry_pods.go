@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+)
+
+// pruneRetryPods deletes the Pods of the failed attempts of tr once pod, the Pod of its
+// current attempt, has started running. The status of each attempt is kept in the
+// retriesStatus of tr, and the Pod of the current attempt is never deleted.
+func (c *Reconciler) pruneRetryPods(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod) error {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableRetryPodPruning || len(tr.Status.RetriesStatus) == 0 {
+		return nil
+	}
+	if pod.Status.Phase == corev1.PodPending || pod.Status.Phase == corev1.PodUnknown || pod.Status.Phase == "" {
+		return nil
+	}
+	retryPods := map[string]bool{}
+	for _, retry := range tr.Status.RetriesStatus {
+		if retry.PodName != "" && retry.PodName != pod.Name {
+			retryPods[retry.PodName] = true
+		}
+	}
+	pods, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).List(metav1.ListOptions{
+		LabelSelector: getLabelSelector(tr),
+	})
+	if err != nil {
+		return err
+	}
+	logger := logging.FromContext(ctx)
+	for i := range pods.Items {
+		po := pods.Items[i]
+		if !retryPods[po.Name] || !metav1.IsControlledBy(&po, tr) {
+			continue
+		}
+		logger.Infof("Deleting pod %q of a failed attempt of taskrun %q", po.Name, tr.Name)
+		if err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Delete(po.Name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearAttemptStatus clears the status of the current attempt of tr which maps to its
+// Pod, so that a new Pod is created for tr when it is reconciled again.
+func ClearAttemptStatus(tr *v1beta1.TaskRun) {
+	tr.Status.PodName = ""
+	// The steps and sidecars of the previous attempt map to the containers of
+	// its Pod.
+	tr.Status.Steps = nil
+	tr.Status.Sidecars = nil
+	// The results of the previous attempt are cleared so that the TaskRun results
+	// reflect the last attempt.
+	tr.Status.TaskRunResults = nil
+	tr.Status.ResourcesResult = nil
+}
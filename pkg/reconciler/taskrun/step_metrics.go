@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"knative.dev/pkg/logging"
+)
+
+// stepMetricsInterval is how often the resource usage of running steps is sampled.
+// metrics-server scrapes the kubelets every 15 seconds by default.
+const stepMetricsInterval = 15 * time.Second
+
+// podMetricsSource returns the current resource usage of the containers of a Pod.
+type podMetricsSource interface {
+	ContainerUsage(namespace, name string) (map[string]corev1.ResourceList, error)
+}
+
+// metricsServerSource reads the usage of Pods from the metrics.k8s.io API served by
+// metrics-server.
+type metricsServerSource struct {
+	client rest.Interface
+}
+
+// podMetrics is the subset of metrics.k8s.io/v1beta1 PodMetrics read by metricsServerSource.
+type podMetrics struct {
+	Containers []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+func (s *metricsServerSource) ContainerUsage(namespace, name string) (map[string]corev1.ResourceList, error) {
+	b, err := s.client.Get().AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods", name).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	var pm podMetrics
+	if err := json.Unmarshal(b, &pm); err != nil {
+		return nil, fmt.Errorf("failed to parse the metrics of pod %s/%s: %w", namespace, name, err)
+	}
+	usage := make(map[string]corev1.ResourceList, len(pm.Containers))
+	for _, c := range pm.Containers {
+		usage[c.Name] = c.Usage
+	}
+	return usage, nil
+}
+
+// updateStepsMetrics sets the metrics of the steps of tr when the "enable-step-metrics"
+// feature flag is set: the duration of terminated steps, and the peak of the memory and
+// CPU usage of running steps sampled from metrics-server, merged with the peaks recorded
+// in previous, the steps of tr before its status was updated from its Pod. The usage is
+// left out when it can't be read, e.g. when metrics-server isn't installed. It returns
+// true if a step is still running, in which case its usage needs to be sampled again
+// later.
+func (c *Reconciler) updateStepsMetrics(ctx context.Context, tr *v1beta1.TaskRun, pod *corev1.Pod, previous []v1beta1.StepState) bool {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableStepMetrics {
+		return false
+	}
+	recorded := make(map[string]*v1beta1.StepMetrics, len(previous))
+	for _, step := range previous {
+		if step.Metrics != nil {
+			recorded[step.Name] = step.Metrics
+		}
+	}
+
+	running := false
+	for _, step := range tr.Status.Steps {
+		if step.Running != nil {
+			running = true
+		}
+	}
+	var usage map[string]corev1.ResourceList
+	if running && pod.Status.Phase == corev1.PodRunning {
+		var err error
+		if usage, err = c.podMetrics.ContainerUsage(pod.Namespace, pod.Name); err != nil {
+			logging.FromContext(ctx).Debugf("Failed to get the resource usage of pod %s: %v", pod.Name, err)
+		}
+	}
+
+	for i := range tr.Status.Steps {
+		step := &tr.Status.Steps[i]
+		metrics := recorded[step.Name].DeepCopy()
+		if metrics == nil {
+			metrics = &v1beta1.StepMetrics{}
+		}
+		if step.Running != nil {
+			if u, ok := usage[step.ContainerName]; ok {
+				metrics.PeakMemory = peakQuantity(metrics.PeakMemory, u, corev1.ResourceMemory)
+				metrics.PeakCPU = peakQuantity(metrics.PeakCPU, u, corev1.ResourceCPU)
+			}
+		}
+		if t := step.Terminated; t != nil && !t.StartedAt.IsZero() && !t.FinishedAt.IsZero() {
+			metrics.Duration = &metav1.Duration{Duration: t.FinishedAt.Sub(t.StartedAt.Time)}
+		}
+		if metrics.Duration != nil || metrics.PeakMemory != nil || metrics.PeakCPU != nil {
+			step.Metrics = metrics
+		}
+	}
+	return running
+}
+
+// peakQuantity returns the highest of peak and the usage of name in usage.
+func peakQuantity(peak *resource.Quantity, usage corev1.ResourceList, name corev1.ResourceName) *resource.Quantity {
+	q, ok := usage[name]
+	if !ok || (peak != nil && peak.Cmp(q) >= 0) {
+		return peak
+	}
+	return &q
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type fakePodMetricsSource struct {
+	usage map[string]corev1.ResourceList
+	err   error
+}
+
+func (f *fakePodMetricsSource) ContainerUsage(namespace, name string) (map[string]corev1.ResourceList, error) {
+	return f.usage, f.err
+}
+
+func TestUpdateStepsMetrics(t *testing.T) {
+	start := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	running := v1beta1.StepState{
+		Name:          "running",
+		ContainerName: "step-running",
+		ContainerState: corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(start)},
+		},
+	}
+	terminated := v1beta1.StepState{
+		Name:          "terminated",
+		ContainerName: "step-terminated",
+		ContainerState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				StartedAt:  metav1.NewTime(start),
+				FinishedAt: metav1.NewTime(start.Add(90 * time.Second)),
+			},
+		},
+	}
+	usage := map[string]corev1.ResourceList{
+		"step-running": {
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+		},
+	}
+	for _, tc := range []struct {
+		desc        string
+		disabled    bool
+		source      podMetricsSource
+		previous    []v1beta1.StepState
+		steps       []v1beta1.StepState
+		wantMetrics []*v1beta1.StepMetrics
+		wantRunning bool
+	}{{
+		desc:        "disabled",
+		disabled:    true,
+		source:      &fakePodMetricsSource{usage: usage},
+		steps:       []v1beta1.StepState{terminated, running},
+		wantMetrics: []*v1beta1.StepMetrics{nil, nil},
+	}, {
+		desc:   "first sample",
+		source: &fakePodMetricsSource{usage: usage},
+		steps:  []v1beta1.StepState{terminated, running},
+		wantMetrics: []*v1beta1.StepMetrics{{
+			Duration: &metav1.Duration{Duration: 90 * time.Second},
+		}, {
+			PeakMemory: quantity("64Mi"),
+			PeakCPU:    quantity("250m"),
+		}},
+		wantRunning: true,
+	}, {
+		desc:   "peaks are kept",
+		source: &fakePodMetricsSource{usage: usage},
+		previous: []v1beta1.StepState{{
+			Name:    "running",
+			Metrics: &v1beta1.StepMetrics{PeakMemory: quantity("128Mi"), PeakCPU: quantity("100m")},
+		}},
+		steps: []v1beta1.StepState{running},
+		wantMetrics: []*v1beta1.StepMetrics{{
+			PeakMemory: quantity("128Mi"),
+			PeakCPU:    quantity("250m"),
+		}},
+		wantRunning: true,
+	}, {
+		desc:   "peaks of a terminated step are kept",
+		source: &fakePodMetricsSource{usage: usage},
+		previous: []v1beta1.StepState{{
+			Name:    "terminated",
+			Metrics: &v1beta1.StepMetrics{PeakMemory: quantity("32Mi")},
+		}},
+		steps: []v1beta1.StepState{terminated},
+		wantMetrics: []*v1beta1.StepMetrics{{
+			Duration:   &metav1.Duration{Duration: 90 * time.Second},
+			PeakMemory: quantity("32Mi"),
+		}},
+	}, {
+		desc:        "metrics-server unavailable",
+		source:      &fakePodMetricsSource{err: errors.New("the server could not find the requested resource")},
+		steps:       []v1beta1.StepState{running},
+		wantMetrics: []*v1beta1.StepMetrics{nil},
+		wantRunning: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableStepMetrics = !tc.disabled
+			ctx := config.ToContext(context.Background(), cfg)
+
+			c := &Reconciler{podMetrics: tc.source}
+			tr := &v1beta1.TaskRun{
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{Steps: tc.steps}},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "the-pod"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+			running := c.updateStepsMetrics(ctx, tr, pod, tc.previous)
+			if running != tc.wantRunning {
+				t.Errorf("Expected a step to be running: %t, got %t", tc.wantRunning, running)
+			}
+			var metrics []*v1beta1.StepMetrics
+			for _, step := range tr.Status.Steps {
+				metrics = append(metrics, step.Metrics)
+			}
+			if d := cmp.Diff(tc.wantMetrics, metrics, cmp.Comparer(func(x, y resource.Quantity) bool {
+				return x.Cmp(y) == 0
+			})); d != "" {
+				t.Errorf("Unexpected step metrics %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestMetricsServerSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/metrics.k8s.io/v1beta1/namespaces/foo/pods/the-pod" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+  "kind": "PodMetrics",
+  "apiVersion": "metrics.k8s.io/v1beta1",
+  "metadata": {"name": "the-pod", "namespace": "foo"},
+  "containers": [{"name": "step-simple-step", "usage": {"cpu": "250m", "memory": "64Mi"}}]
+}`))
+	}))
+	defer srv.Close()
+	kubeclient, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &metricsServerSource{client: kubeclient.CoreV1().RESTClient()}
+
+	usage, err := s.ContainerUsage("foo", "the-pod")
+	if err != nil {
+		t.Fatalf("Unexpected error getting the usage of the pod: %v", err)
+	}
+	want := map[string]corev1.ResourceList{
+		"step-simple-step": {
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+	}
+	if d := cmp.Diff(want, usage, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Cmp(y) == 0
+	})); d != "" {
+		t.Errorf("Unexpected usage %s", diff.PrintWantGot(d))
+	}
+
+	if _, err := s.ContainerUsage("foo", "other-pod"); err == nil {
+		t.Error("Expected an error getting the usage of a pod without metrics")
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/events"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/reconciler/ttl"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/termination"
 	"github.com/tektoncd/pipeline/pkg/timeout"
@@ -47,7 +48,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -66,12 +69,21 @@ type Reconciler struct {
 	taskLister        listers.TaskLister
 	clusterTaskLister listers.ClusterTaskLister
 	resourceLister    resourcelisters.PipelineResourceLister
+	podLister         corev1listers.PodLister
 	cloudEventClient  cloudevent.CEClient
 	tracker           tracker.Interface
 	entrypointCache   podconvert.EntrypointCache
+	digestCache       podconvert.DigestCache
 	timeoutHandler    *timeout.Handler
+	ttlHandler        *ttl.Handler
+	clock             clock.Clock
 	metrics           *Recorder
 	pvcHandler        volumeclaim.PvcHandler
+	podMetrics        podMetricsSource
+	podLogs           podLogsSource
+	logURLs           logURLRenderer
+	// enqueueAfter reconciles a TaskRun again after a delay, to update the progress of its steps.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements taskrunreconciler.Interface
@@ -87,6 +99,13 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 	// Read the initial condition
 	before := tr.Status.GetCondition(apis.ConditionSucceeded)
 
+	// The running TaskRuns are counted by namespace with the status tr has once reconciled
+	defer func() {
+		if err := c.metrics.RecordRunningTaskRun(tr); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+	}()
+
 	// If the TaskRun is just starting, this will also set the starttime,
 	// from which the timeout will immediately begin counting down.
 	if !tr.HasStarted() {
@@ -103,6 +122,7 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 		// on the event to perform user facing initialisations, such has reset a CI check status
 		afterCondition := tr.Status.GetCondition(apis.ConditionSucceeded)
 		events.Emit(ctx, nil, afterCondition, tr)
+		c.recordAuditLog(ctx, tr, nil, afterCondition)
 	}
 
 	// If the TaskRun is complete, run some post run fixtures when applicable
@@ -121,35 +141,27 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 			return merr.ErrorOrNil()
 		}
 		c.timeoutHandler.Release(tr)
-		pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
-		if err == nil {
-			err = podconvert.StopSidecars(c.Images.NopImage, c.KubeClientSet, *pod)
-			if err == nil {
-				// Check if any SidecarStatuses are still shown as Running after stopping
-				// Sidecars. If any Running, update SidecarStatuses based on Pod ContainerStatuses.
-				if podconvert.IsSidecarStatusRunning(tr) {
-					err = updateStoppedSidecarStatus(ctx, pod, tr, c)
-				}
-			}
-		} else if k8serrors.IsNotFound(err) {
-			return merr.ErrorOrNil()
+		if err := c.cleanupWorkspaces(ctx, tr); err != nil {
+			logger.Errorf("Failed to clean up the workspaces of TaskRun %q: %v", tr.Name, err)
+			merr = multierror.Append(merr, err)
 		}
-		if err != nil {
-			logger.Errorf("Error stopping sidecars for TaskRun %q: %v", tr.Name, err)
+		if err := c.scanVulnerabilities(ctx, tr); err != nil {
+			logger.Errorf("Failed to scan the images of TaskRun %q for vulnerabilities: %v", tr.Name, err)
 			merr = multierror.Append(merr, err)
 		}
-
-		go func(metrics *Recorder) {
-			err := metrics.DurationAndCount(tr)
-			if err != nil {
-				logger.Warnf("Failed to log the metrics : %v", err)
-			}
-			err = metrics.RecordPodLatency(pod, tr)
-			if err != nil {
-				logger.Warnf("Failed to log the metrics : %v", err)
+		if tr.Status.TaskSpec != nil && len(tr.Status.TaskSpec.Platforms) > 0 {
+			if err := c.stopPlatformSidecarsAndRecordMetrics(ctx, tr); err != nil {
+				merr = multierror.Append(merr, err)
 			}
-		}(c.metrics)
+		} else if err := c.stopSidecarsAndRecordMetrics(ctx, tr); err != nil {
+			merr = multierror.Append(merr, err)
+		}
 
+		// The TaskRun is only deleted once done with, and is otherwise enqueued again
+		// for when its TTL passes.
+		if err := c.deleteIfExpired(ctx, tr); err != nil {
+			merr = multierror.Append(merr, err)
+		}
 		return merr.ErrorOrNil()
 	}
 
@@ -196,6 +208,7 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, tr *v1
 
 	// Send k8s events and cloud events (when configured)
 	events.Emit(ctx, beforeCondition, afterCondition, tr)
+	c.recordAuditLog(ctx, tr, beforeCondition, afterCondition)
 
 	_, err := c.updateLabelsAndAnnotations(tr)
 	if err != nil {
@@ -249,12 +262,23 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 		logger.Errorf("Failed to store TaskSpec on TaskRun.Statusfor taskrun %s: %v", tr.Name, err)
 	}
 
-	// Propagate labels from Task to TaskRun.
+	// The webhook validates gated fields when objects are created, but a referenced
+	// Task may have been created while the feature flags allowed more fields.
+	if err := taskSpec.ValidateEnabledAPIFields(ctx); err != nil {
+		logger.Errorf("TaskRun %q uses fields which are not enabled: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
+	// Propagate labels from Task to TaskRun. Labels of the TaskRun take
+	// precedence over the ones of the Task.
 	if tr.ObjectMeta.Labels == nil {
 		tr.ObjectMeta.Labels = make(map[string]string, len(taskMeta.Labels)+1)
 	}
-	for key, value := range taskMeta.Labels {
-		tr.ObjectMeta.Labels[key] = value
+	for key, value := range podconvert.PropagatedMetadata(ctx, taskMeta.Labels) {
+		if _, ok := tr.ObjectMeta.Labels[key]; !ok {
+			tr.ObjectMeta.Labels[key] = value
+		}
 	}
 	if tr.Spec.TaskRef != nil {
 		tr.ObjectMeta.Labels[pipeline.GroupName+pipeline.TaskLabelKey] = taskMeta.Name
@@ -263,12 +287,15 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 		}
 	}
 
-	// Propagate annotations from Task to TaskRun.
+	// Propagate annotations from Task to TaskRun. Annotations of the TaskRun
+	// take precedence over the ones of the Task.
 	if tr.ObjectMeta.Annotations == nil {
 		tr.ObjectMeta.Annotations = make(map[string]string, len(taskMeta.Annotations))
 	}
-	for key, value := range taskMeta.Annotations {
-		tr.ObjectMeta.Annotations[key] = value
+	for key, value := range podconvert.PropagatedMetadata(ctx, taskMeta.Annotations) {
+		if _, ok := tr.ObjectMeta.Annotations[key]; !ok {
+			tr.ObjectMeta.Annotations[key] = value
+		}
 	}
 
 	inputs := []v1beta1.TaskResourceBinding{}
@@ -290,13 +317,27 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
+	// Tasks fetched from Tekton Bundles aren't validated by the webhook, and an
+	// ambiguous result alias would resolve to an arbitrary result.
+	if err := v1beta1.ValidateResultAliases(taskSpec.Results); err != nil {
+		logger.Errorf("TaskRun %q results are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
+	if err := validateStepOverrides(taskSpec, tr.Spec.StepOverrides); err != nil {
+		logger.Errorf("TaskRun %q step overrides are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
 	if err := c.updateTaskRunWithDefaultWorkspaces(ctx, tr, taskSpec); err != nil {
 		logger.Errorf("Failed to update taskrun %s with default workspace: %v", tr.Name, err)
 		tr.Status.MarkResourceFailed(podconvert.ReasonFailedResolution, err)
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
-	if err := workspace.ValidateBindings(taskSpec.Workspaces, tr.Spec.Workspaces); err != nil {
+	if err := workspace.ValidateBindings(ctx, taskSpec.Workspaces, tr.Spec.Workspaces); err != nil {
 		logger.Errorf("TaskRun %q workspaces are invalid: %v", tr.Name, err)
 		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
 		return nil, nil, controller.NewPermanentError(err)
@@ -308,6 +349,16 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
+	s3Transfers, err := workspace.ParseS3Annotations(tr.Annotations)
+	if err == nil {
+		err = s3Transfers.Validate(taskSpec.Workspaces, tr.Spec.Workspaces)
+	}
+	if err != nil {
+		logger.Errorf("TaskRun %q S3 workspace annotations are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
 	// Initialize the cloud events if at least a CloudEventResource is defined
 	// and they have not been initialized yet.
 	// FIXME(afrittoli) This resource specific logic will have to be replaced
@@ -327,6 +378,10 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 	taskSpec *v1beta1.TaskSpec, rtr *resources.ResolvedTaskResources) error {
 	logger := logging.FromContext(ctx)
 	recorder := controller.GetEventRecorder(ctx)
+	if len(taskSpec.Platforms) > 0 {
+		return c.reconcilePlatforms(ctx, tr, taskSpec, rtr)
+	}
+
 	// Get the TaskRun's Pod if it should have one. Otherwise, create the Pod.
 	var pod *corev1.Pod
 	var err error
@@ -352,34 +407,37 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 		}
 		for index := range pos.Items {
 			po := pos.Items[index]
-			if metav1.IsControlledBy(&po, tr) && !podconvert.DidTaskRunFail(&po) {
+			if metav1.IsControlledBy(&po, tr) && !podconvert.DidTaskRunFail(&po) && !podconvert.IsPodEvicted(&po) {
 				pod = &po
 			}
 		}
 	}
 
-	if pod == nil {
-		if tr.HasVolumeClaimTemplate() {
-			if err := c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(tr.Spec.Workspaces, tr.GetOwnerReference(), tr.Namespace); err != nil {
-				logger.Errorf("Failed to create PVC for TaskRun %s: %v", tr.Name, err)
-				tr.Status.MarkResourceFailed(volumeclaim.ReasonCouldntCreateWorkspacePVC,
-					fmt.Errorf("Failed to create PVC for TaskRun %s workspaces correctly: %s",
-						fmt.Sprintf("%s/%s", tr.Namespace, tr.Name), err))
-				return controller.NewPermanentError(err)
-			}
+	if pod != nil && c.retryEvictedPod(ctx, tr, pod) {
+		pod = nil
+	}
 
-			taskRunWorkspaces := applyVolumeClaimTemplates(tr.Spec.Workspaces, tr.GetOwnerReference())
-			// This is used by createPod below. Changes to the Spec are not updated.
-			tr.Spec.Workspaces = taskRunWorkspaces
+	if pod == nil {
+		if pulling, err := c.prewarmImages(ctx, tr, taskSpec); pulling || err != nil {
+			return err
+		}
+		if err := c.prepareWorkspaces(ctx, tr); err != nil {
+			return err
 		}
 
-		pod, err = c.createPod(ctx, tr, rtr)
+		pod, err = c.createPod(ctx, tr, rtr, "")
 		if err != nil {
 			newErr := c.handlePodCreationError(ctx, tr, err)
 			logger.Errorf("Failed to create task run pod for taskrun %q: %v", tr.Name, newErr)
 			return newErr
 		}
+		// Record the Pod right away, so it can be found before it starts running.
+		tr.Status.PodName = pod.Name
 		go c.timeoutHandler.WaitTaskRun(tr, tr.Status.StartTime)
+		for _, name := range workspace.ReadOnlySourceBindings(taskSpec.Workspaces, tr.Spec.Workspaces) {
+			recorder.Eventf(tr, corev1.EventTypeWarning, workspace.ReasonReadOnlySource,
+				"Workspace %q is bound to a read-only ConfigMap, Secret or CSI volume but the Task doesn't declare it readOnly", name)
+		}
 	}
 	if err := c.tracker.Track(tr.GetBuildPodRef(), tr); err != nil {
 		logger.Errorf("Failed to create tracker for build pod %q for taskrun %q: %v", tr.Name, tr.Name, err)
@@ -396,17 +454,76 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 		}
 	}
 
+	c.addDebugContainer(ctx, tr, pod)
+
 	// Convert the Pod's status to the equivalent TaskRun Status.
+	previousSteps := tr.Status.Steps
 	tr.Status = podconvert.MakeTaskRunStatus(logger, *tr, pod, *taskSpec)
+	if updateStepsProgress(tr, c.clock.Now()) {
+		c.enqueueAfter(tr, progressUpdateInterval)
+	}
+	if c.updateStepsMetrics(ctx, tr, pod, previousSteps) {
+		c.enqueueAfter(tr, stepMetricsInterval)
+	}
+	c.updateStepsLogURL(ctx, tr)
+	// The time the pod spent pending is only recorded once, when its first step starts.
+	if !stepsStarted(previousSteps) && stepsStarted(tr.Status.Steps) {
+		if err := c.metrics.RecordPodPending(pod, tr); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+	}
+	if tr.IsDone() {
+		c.checkStepsResourceUsage(ctx, tr, taskSpec)
+	}
 
-	if err := updateTaskRunResourceResult(tr, *pod); err != nil {
+	if err := c.deletePrewarmPod(ctx, tr, pod); err != nil {
+		logger.Errorf("Failed to delete the pod pulling the images of taskrun %q: %v", tr.Name, err)
 		return err
 	}
 
+	if err := c.pruneRetryPods(ctx, tr, pod); err != nil {
+		logger.Errorf("Failed to delete the pods of the failed attempts of taskrun %q: %v", tr.Name, err)
+		return err
+	}
+
+	if err := updateTaskRunResourceResult(ctx, tr, *pod); err != nil {
+		return err
+	}
+	if err := c.updateTaskRunResultsFromLogs(ctx, tr, pod); err != nil {
+		logger.Errorf("Failed to read the results of taskrun %q from the logs of its steps: %v", tr.Name, err)
+		return err
+	}
+	tr.Status.TaskResultsSize = taskResultsSize(tr.Status.TaskRunResults)
+
 	logger.Infof("Successfully reconciled taskrun %s/%s with status: %#v", tr.Name, tr.Namespace, tr.Status.GetCondition(apis.ConditionSucceeded))
 	return nil
 }
 
+// prepareWorkspaces creates the PVCs of the volumeClaimTemplate workspaces of tr
+// and references the workspaces it uses, before its Pod is created.
+func (c *Reconciler) prepareWorkspaces(ctx context.Context, tr *v1beta1.TaskRun) error {
+	logger := logging.FromContext(ctx)
+	if tr.HasVolumeClaimTemplate() {
+		if err := c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(tr.Spec.Workspaces, tr.GetOwnerReference(), tr.Namespace); err != nil {
+			logger.Errorf("Failed to create PVC for TaskRun %s: %v", tr.Name, err)
+			tr.Status.MarkResourceFailed(volumeclaim.ReasonCouldntCreateWorkspacePVC,
+				fmt.Errorf("Failed to create PVC for TaskRun %s workspaces correctly: %s",
+					fmt.Sprintf("%s/%s", tr.Namespace, tr.Name), err))
+			return controller.NewPermanentError(err)
+		}
+
+		taskRunWorkspaces := applyVolumeClaimTemplates(tr.Spec.Workspaces, tr.GetOwnerReference())
+		// This is used by createPod. Changes to the Spec are not updated.
+		tr.Spec.Workspaces = taskRunWorkspaces
+	}
+
+	if err := c.addWorkspaceReferences(tr); err != nil {
+		logger.Errorf("Failed to reference the workspaces of TaskRun %s: %v", tr.Name, err)
+		return err
+	}
+	return nil
+}
+
 func (c *Reconciler) updateTaskRunWithDefaultWorkspaces(ctx context.Context, tr *v1beta1.TaskRun, taskSpec *v1beta1.TaskSpec) error {
 	configMap := config.FromContextOrDefaults(ctx)
 	defaults := configMap.Defaults
@@ -502,6 +619,16 @@ func (c *Reconciler) failTaskRun(ctx context.Context, tr *v1beta1.TaskRun, reaso
 	// update tr completed time
 	tr.Status.CompletionTime = &completionTime
 
+	if err := c.deletePlatformPods(tr); err != nil {
+		logger.Infof("Failed to terminate the pods of the platforms: %v", err)
+		return err
+	}
+
+	if err := c.deletePrewarmPod(ctx, tr, nil); err != nil {
+		logger.Infof("Failed to terminate the pod pulling the images: %v", err)
+		return err
+	}
+
 	if tr.Status.PodName == "" {
 		logger.Warnf("task run %q has no pod running yet", tr.Name)
 		return nil
@@ -544,9 +671,10 @@ func (c *Reconciler) failTaskRun(ctx context.Context, tr *v1beta1.TaskRun, reaso
 	return nil
 }
 
-// createPod creates a Pod based on the Task's configuration, with pvcName as a volumeMount
+// createPod creates a Pod based on the Task's configuration, with pvcName as a volumeMount.
+// The Pod runs on platform, unless it is empty.
 // TODO(dibyom): Refactor resource setup/substitution logic to its own function in the resources package
-func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources) (*corev1.Pod, error) {
+func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources, platform string) (*corev1.Pod, error) {
 	logger := logging.FromContext(ctx)
 	ts := rtr.TaskSpec.DeepCopy()
 	inputResources, err := resourceImplBinding(rtr.Inputs, c.Images)
@@ -599,7 +727,18 @@ func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *re
 	// Apply task result substitution
 	ts = resources.ApplyTaskResults(ts)
 
-	ts, err = workspace.Apply(*ts, tr.Spec.Workspaces)
+	// Add the Steps copying workspaces from and to S3
+	s3Transfers, err := workspace.ParseS3Annotations(tr.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	ts, err = workspace.ApplyS3Transfers(*ts, s3Transfers, c.Images.AWSCLIImage)
+	if err != nil {
+		logger.Errorf("Failed to create a pod for taskrun: %s due to S3 workspace error %v", tr.Name, err)
+		return nil, err
+	}
+
+	ts, err = workspace.Apply(*ts, resources.ApplyWorkspaceSubPaths(rtr, tr, defaults...))
 	if err != nil {
 		logger.Errorf("Failed to create a pod for taskrun: %s due to workspace error %v", tr.Name, err)
 		return nil, err
@@ -616,6 +755,9 @@ func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *re
 		KubeClient:      c.KubeClientSet,
 		EntrypointCache: c.entrypointCache,
 		OverrideHomeEnv: shouldOverrideHomeEnv,
+		ExternalSecrets: podconvert.NewExternalSecretResolver(c.KubeClientSet.Discovery()),
+		DigestCache:     c.digestCache,
+		Platform:        platform,
 	}
 	pod, err := podbuilder.Build(ctx, tr, *ts)
 	if err != nil {
@@ -627,10 +769,15 @@ func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *re
 
 type DeletePod func(podName string, options *metav1.DeleteOptions) error
 
-func updateTaskRunResourceResult(taskRun *v1beta1.TaskRun, pod corev1.Pod) error {
+// updateTaskRunResourceResult adds the results written by the containers of pod to the
+// status of taskRun once it succeeded. With the "enable-failed-attempt-results" feature flag
+// set, the results written before the TaskRun failed are added as well, so that they are
+// kept in the status of the attempt when the TaskRun is retried.
+func updateTaskRunResourceResult(ctx context.Context, taskRun *v1beta1.TaskRun, pod corev1.Pod) error {
 	podconvert.SortContainerStatuses(&pod)
 
-	if taskRun.IsSuccessful() {
+	if resultsAvailable(ctx, taskRun) {
+		iterations := newForEachResults(&pod)
 		for idx, cs := range pod.Status.ContainerStatuses {
 			if cs.State.Terminated != nil {
 				msg := cs.State.Terminated.Message
@@ -639,15 +786,84 @@ func updateTaskRunResourceResult(taskRun *v1beta1.TaskRun, pod corev1.Pod) error
 					return fmt.Errorf("parsing message for container status %d: %v", idx, err)
 				}
 				taskResults, pipelineResourceResults := getResults(r)
-				taskRun.Status.TaskRunResults = append(taskRun.Status.TaskRunResults, taskResults...)
+				if !iterations.add(cs.Name, taskResults) {
+					taskRun.Status.TaskRunResults = append(taskRun.Status.TaskRunResults, taskResults...)
+				}
 				taskRun.Status.ResourcesResult = append(taskRun.Status.ResourcesResult, pipelineResourceResults...)
 			}
 		}
+		taskRun.Status.TaskRunResults = append(taskRun.Status.TaskRunResults, iterations.merged()...)
 		taskRun.Status.TaskRunResults = removeDuplicateResults(taskRun.Status.TaskRunResults)
 	}
 	return nil
 }
 
+// resultsAvailable returns true if the results of taskRun are read from its Pod:
+// once it succeeded, or once it is done when the results of failed TaskRuns are
+// kept.
+func resultsAvailable(ctx context.Context, taskRun *v1beta1.TaskRun) bool {
+	keepFailedResults := config.FromContextOrDefaults(ctx).FeatureFlags.EnableFailedAttemptResults
+	return taskRun.IsSuccessful() || (keepFailedResults && taskRun.IsDone())
+}
+
+// stopSidecarsAndRecordMetrics stops the sidecars left running in the Pod of the done
+// TaskRun tr and records its metrics. Nothing is done once the Pod has been deleted.
+func (c *Reconciler) stopSidecarsAndRecordMetrics(ctx context.Context, tr *v1beta1.TaskRun) error {
+	logger := logging.FromContext(ctx)
+	pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		// The client returns an empty Pod along with the error.
+		pod = nil
+	} else {
+		err = podconvert.StopSidecars(c.Images.NopImage, c.KubeClientSet, *pod)
+		if err == nil {
+			// Check if any SidecarStatuses are still shown as Running after stopping
+			// Sidecars. If any Running, update SidecarStatuses based on Pod ContainerStatuses.
+			if podconvert.IsSidecarStatusRunning(tr) {
+				err = updateStoppedSidecarStatus(ctx, pod, tr, c)
+			}
+		}
+	}
+	if err != nil {
+		logger.Errorf("Error stopping sidecars for TaskRun %q: %v", tr.Name, err)
+	}
+
+	if err := c.metrics.DurationAndCount(tr); err != nil {
+		logger.Warnf("Failed to log the metrics : %v", err)
+	}
+	if pod != nil {
+		if err := c.metrics.RecordPodLatency(pod, tr); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+	}
+	return err
+}
+
+// deleteIfExpired deletes the finished TaskRun once its TTL after finishing has passed.
+// TaskRuns created by a PipelineRun are left to be deleted along with it.
+func (c *Reconciler) deleteIfExpired(ctx context.Context, tr *v1beta1.TaskRun) error {
+	if metav1.GetControllerOf(tr) != nil {
+		return nil
+	}
+	logger := logging.FromContext(ctx)
+	deleted, err := c.ttlHandler.Handle(ctx, tr, tr.Status.CompletionTime, func() error {
+		return c.PipelineClientSet.TektonV1beta1().TaskRuns(tr.Namespace).Delete(tr.Name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &tr.UID},
+		})
+	})
+	if err != nil {
+		logger.Errorf("Failed to apply the TTL of TaskRun %s: %v", tr.Name, err)
+		return err
+	}
+	if deleted {
+		logger.Infof("Deleted TaskRun %s after its TTL", tr.Name)
+	}
+	return nil
+}
+
 func getResults(results []v1beta1.PipelineResourceResult) ([]v1beta1.TaskRunResult, []v1beta1.PipelineResourceResult) {
 	var taskResults []v1beta1.TaskRunResult
 	var pipelineResourceResults []v1beta1.PipelineResourceResult
@@ -659,6 +875,9 @@ func getResults(results []v1beta1.PipelineResourceResult) ([]v1beta1.TaskRunResu
 				Value: r.Value,
 			}
 			taskResults = append(taskResults, taskRunResult)
+		case v1beta1.InternalTektonResultType:
+			// Values written by the entrypoint for the controller aren't results.
+			continue
 		case v1beta1.PipelineResourceResultType:
 			fallthrough
 		default:
@@ -683,6 +902,15 @@ func removeDuplicateResults(taskRunResult []v1beta1.TaskRunResult) []v1beta1.Tas
 	return uniq
 }
 
+// taskResultsSize returns the total size in bytes of the values of results.
+func taskResultsSize(results []v1beta1.TaskRunResult) int64 {
+	var size int64
+	for _, r := range results {
+		size += int64(len(r.Value))
+	}
+	return size
+}
+
 func isExceededResourceQuotaError(err error) bool {
 	return err != nil && k8serrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
 }
@@ -702,12 +930,9 @@ func resourceImplBinding(resources map[string]*resourcev1alpha1.PipelineResource
 
 // getLabelSelector get label of centain taskrun
 func getLabelSelector(tr *v1beta1.TaskRun) string {
-	labels := []string{}
-	labelsMap := podconvert.MakeLabels(tr)
-	for key, value := range labelsMap {
-		labels = append(labels, fmt.Sprintf("%s=%s", key, value))
-	}
-	return strings.Join(labels, ",")
+	// Only select on the TaskRun label, since the other labels of the TaskRun
+	// may not have been propagated to its Pod.
+	return fmt.Sprintf("%s=%s", pipeline.GroupName+pipeline.TaskRunLabelKey, tr.Name)
 }
 
 // updateStoppedSidecarStatus updates SidecarStatus for sidecars that were
@@ -762,6 +987,7 @@ func applyVolumeClaimTemplates(workspaceBindings []v1beta1.WorkspaceBinding, own
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 				ClaimName: volumeclaim.GetPersistentVolumeClaimName(wb.VolumeClaimTemplate, wb, owner),
 			},
+			CleanupAfterCompletion: wb.CleanupAfterCompletion,
 		}
 		taskRunWorkspaceBindings = append(taskRunWorkspaceBindings, b)
 	}
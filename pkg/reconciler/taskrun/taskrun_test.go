@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/auditlog"
 	podconvert "github.com/tektoncd/pipeline/pkg/pod"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
@@ -49,14 +51,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sruntimeschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics/metricstest"
 	pkgreconciler "knative.dev/pkg/reconciler"
 )
 
@@ -82,6 +87,8 @@ var (
 		BuildGCSFetcherImage:     "gcr.io/cloud-builders/gcs-fetcher:latest",
 		PRImage:                  "override-with-pr:latest",
 		ImageDigestExporterImage: "override-with-imagedigest-exporter-image:latest",
+		AWSCLIImage:              "amazon/aws-cli",
+		TrivyImage:               "aquasec/trivy",
 	}
 	ignoreLastTransitionTime = cmpopts.IgnoreTypes(apis.Condition{}.LastTransitionTime.Inner.Time)
 	// Pods are created with a random 5-character suffix that we want to
@@ -279,6 +286,10 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 // getTaskRunController returns an instance of the TaskRun controller/reconciler that has been seeded with
 // d, where d represents the state of the system (existing resources) needed for the test.
 func getTaskRunController(t *testing.T, d test.Data) (test.Assets, func()) {
+	return getTaskRunControllerWithClock(t, d, clock.RealClock{})
+}
+
+func getTaskRunControllerWithClock(t *testing.T, d test.Data, clock clock.Clock) (test.Assets, func()) {
 	//unregisterMetrics()
 	ctx, _ := ttesting.SetupFakeContext(t)
 	ctx, cancel := context.WithCancel(ctx)
@@ -286,7 +297,7 @@ func getTaskRunController(t *testing.T, d test.Data) (test.Assets, func()) {
 	c, informers := test.SeedTestData(t, ctx, d)
 	configMapWatcher := configmap.NewInformedWatcher(c.Kube, system.GetNamespace())
 
-	ctl := NewController(namespace, images)(ctx, configMapWatcher)
+	ctl := NewController(namespace, images, clock)(ctx, configMapWatcher)
 	if err := configMapWatcher.Start(ctx.Done()); err != nil {
 		t.Fatalf("error starting configmap watcher: %v", err)
 	}
@@ -1536,6 +1547,60 @@ func TestReconcile_SetsStartTime(t *testing.T) {
 	}
 }
 
+func TestReconcile_SetsPodNameAndContainersWhilePending(t *testing.T) {
+	task := tb.Task("test-task-with-sidecar", tb.TaskSpec(
+		simpleStep,
+		tb.Sidecar("sidecar", "image-id"),
+	), tb.TaskNamespace("foo"))
+	taskRun := tb.TaskRun("test-taskrun", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(task.Name),
+	))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{task},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: "foo",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Errorf("expected no error reconciling valid TaskRun but got %v", err)
+	}
+
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if newTr.Status.PodName == "" {
+		t.Fatal("expected podName to be set by reconcile but it was empty")
+	}
+	pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(newTr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected Pod %s to exist but instead got error when getting it: %v", newTr.Status.PodName, err)
+	}
+	if pod.Status.Phase != "" && pod.Status.Phase != corev1.PodPending {
+		t.Fatalf("expected Pod %s to be pending but it is %s", pod.Name, pod.Status.Phase)
+	}
+
+	wantSteps := []v1beta1.StepState{{Name: "simple-step", ContainerName: "step-simple-step", Percentage: int32Ptr(0)}}
+	if d := cmp.Diff(wantSteps, newTr.Status.Steps); d != "" {
+		t.Errorf("TaskRun steps %s", diff.PrintWantGot(d))
+	}
+	wantSidecars := []v1beta1.SidecarState{{Name: "sidecar", ContainerName: "sidecar-sidecar"}}
+	if d := cmp.Diff(wantSidecars, newTr.Status.Sidecars); d != "" {
+		t.Errorf("TaskRun sidecars %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestReconcile_SortTaskRunStatusSteps(t *testing.T) {
 	taskRun := tb.TaskRun("test-taskrun", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
 		tb.TaskRunTaskRef(taskMultipleSteps.Name)),
@@ -1654,8 +1719,37 @@ func TestReconcileInvalidTaskRuns(t *testing.T) {
 	withWrongRef := tb.TaskRun("taskrun-with-wrong-ref", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
 		tb.TaskRunTaskRef("taskrun-with-wrong-ref", tb.TaskRefKind(v1beta1.ClusterTaskKind)),
 	))
-	taskRuns := []*v1beta1.TaskRun{noTaskRun, withWrongRef}
-	tasks := []*v1beta1.Task{simpleTask}
+	// A Task using alpha fields, created while they were enabled.
+	alphaTask := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "alpha-task", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "simple-step", Image: "foo"},
+				Hermetic:  true,
+			}},
+		},
+	}
+	withAlphaFields := tb.TaskRun("taskrun-with-alpha-fields", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(alphaTask.Name)))
+	withUnknownStepOverride := tb.TaskRun("taskrun-with-unknown-step-override", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		func(spec *v1beta1.TaskRunSpec) {
+			spec.StepOverrides = []v1beta1.TaskRunStepOverride{{Name: "missing-step", ImagePullPolicy: corev1.PullAlways}}
+		},
+	))
+	// A Task whose result aliases collide, e.g. fetched from a bundle.
+	collidingAliasesTask := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "colliding-aliases-task", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{Container: corev1.Container{Name: "simple-step", Image: "foo"}}},
+			Results: []v1beta1.TaskResult{
+				{Name: "digest"},
+				{Name: "image-digest", Aliases: []string{"digest"}},
+			},
+		},
+	}
+	withCollidingAliases := tb.TaskRun("taskrun-with-colliding-aliases", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(collidingAliasesTask.Name)))
+	taskRuns := []*v1beta1.TaskRun{noTaskRun, withWrongRef, withAlphaFields, withUnknownStepOverride, withCollidingAliases}
+	tasks := []*v1beta1.Task{simpleTask, alphaTask, collidingAliasesTask}
 
 	d := test.Data{
 		TaskRuns: taskRuns,
@@ -1685,6 +1779,33 @@ func TestReconcileInvalidTaskRuns(t *testing.T) {
 			"Warning Failed",
 			"Warning InternalError",
 		},
+	}, {
+		name:    "task run with alpha fields not enabled",
+		taskRun: withAlphaFields,
+		reason:  podconvert.ReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started",
+			"Warning Failed",
+			"Warning InternalError",
+		},
+	}, {
+		name:    "task run overriding a step not in the task",
+		taskRun: withUnknownStepOverride,
+		reason:  podconvert.ReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started",
+			"Warning Failed",
+			"Warning InternalError",
+		},
+	}, {
+		name:    "task run with colliding result aliases",
+		taskRun: withCollidingAliases,
+		reason:  podconvert.ReasonFailedValidation,
+		wantEvents: []string{
+			"Normal Started",
+			"Warning Failed",
+			"Warning InternalError",
+		},
 	}}
 
 	for _, tc := range testcases {
@@ -1758,6 +1879,50 @@ func TestReconcilePodFetchError(t *testing.T) {
 	}
 }
 
+func TestReconcileDonePodFetchError(t *testing.T) {
+	unregisterMetrics()
+	startTime := time.Now().Add(-time.Minute)
+	taskRun := tb.TaskRun("test-taskrun-done",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("test-task")),
+		tb.TaskRunStatus(
+			tb.PodName("test-taskrun-done-pod"),
+			tb.TaskRunStartTime(startTime),
+			tb.TaskRunCompletionTime(startTime.Add(30*time.Second)),
+			tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			}),
+		),
+	)
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	// Like the API server client, return an empty Pod along with the error, here
+	// one which has been scheduled so that its latency would be recorded.
+	clients.Kube.PrependReactor("get", "pods", func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, tb.Pod("",
+			tb.PodStatus(tb.PodStatusConditions(corev1.PodCondition{
+				Type:               corev1.PodScheduled,
+				LastTransitionTime: metav1.Now(),
+			})),
+		), errors.New("induce failure fetching pods")
+	})
+
+	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err == nil {
+		t.Fatal("expected error when reconciling a done TaskRun for which we couldn't get the corresponding Pod but got nil")
+	}
+	metricstest.CheckStatsReported(t, "taskrun_count")
+	metricstest.CheckStatsNotReported(t, "taskruns_pod_latency")
+}
+
 func makePod(taskRun *v1beta1.TaskRun, task *v1beta1.Task) (*corev1.Pod, error) {
 	// TODO(jasonhall): This avoids a circular dependency where
 	// getTaskRunController takes a test.Data which must be populated with
@@ -1875,115 +2040,998 @@ func TestReconcilePodUpdateStatus(t *testing.T) {
 	}
 }
 
-func TestReconcileOnCompletedTaskRun(t *testing.T) {
-	taskSt := &apis.Condition{
-		Type:    apis.ConditionSucceeded,
-		Status:  corev1.ConditionTrue,
-		Reason:  "Build succeeded",
-		Message: "Build succeeded",
-	}
-	taskRun := tb.TaskRun("test-taskrun-run-success", tb.TaskRunSpec(
-		tb.TaskRunTaskRef(simpleTask.Name),
-	), tb.TaskRunStatus(tb.StatusCondition(*taskSt)))
-	d := test.Data{
-		TaskRuns: []*v1beta1.TaskRun{
-			taskRun,
-		},
-		Tasks: []*v1beta1.Task{simpleTask},
-	}
-
-	testAssets, cancel := getTaskRunController(t, d)
-	defer cancel()
-	c := testAssets.Controller
-	clients := testAssets.Clients
+func TestReconcilePodEvicted(t *testing.T) {
+	// TestReconcilePodEvicted simulates the eviction of the Pod of a TaskRun by patching its status.
+	// It verifies that the TaskRun fails because of the eviction, and that a new Pod is created when
+	// the TaskRun is retried.
+	for _, tc := range []struct {
+		name   string
+		phase  corev1.PodPhase
+		reason string
+	}{{
+		name:   "evicted",
+		phase:  corev1.PodFailed,
+		reason: "Evicted",
+	}, {
+		name:   "node lost",
+		phase:  corev1.PodRunning,
+		reason: "NodeLost",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-evicted", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+			pod, err := makePod(taskRun, simpleTask)
+			if err != nil {
+				t.Fatalf("MakePod: %v", err)
+			}
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName: pod.Name,
+				},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{pod},
+			}
 
-	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
-		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
-	}
-	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
-	if err != nil {
-		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
-	}
-	if d := cmp.Diff(taskSt, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
-		t.Fatalf("Did not get expected condition %s", diff.PrintWantGot(d))
-	}
-}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
 
-func TestReconcileOnCancelledTaskRun(t *testing.T) {
-	taskRun := tb.TaskRun("test-taskrun-run-cancelled",
-		tb.TaskRunNamespace("foo"),
-		tb.TaskRunSpec(
-			tb.TaskRunTaskRef(simpleTask.Name),
-			tb.TaskRunCancelled,
-		), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionUnknown,
-		})))
-	d := test.Data{
-		TaskRuns: []*v1beta1.TaskRun{taskRun},
-		Tasks:    []*v1beta1.Task{simpleTask},
-	}
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: "foo",
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
 
-	testAssets, cancel := getTaskRunController(t, d)
-	defer cancel()
-	c := testAssets.Controller
-	clients := testAssets.Clients
+			// Evict the pod and trigger reconcile.
+			pod.Spec.NodeName = "node"
+			pod.Status = corev1.PodStatus{
+				Phase:   tc.phase,
+				Reason:  tc.reason,
+				Message: "boom",
+			}
+			if _, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).UpdateStatus(pod); err != nil {
+				t.Fatalf("Unexpected error while updating pod: %v", err)
+			}
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile(): %v", err)
+			}
 
-	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
-		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
-	}
-	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
-	if err != nil {
-		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
-	}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if d := cmp.Diff(&apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  v1beta1.TaskRunReasonEvicted.String(),
+				Message: fmt.Sprintf(`TaskRun Pod %q was evicted from node "node" (%s): boom`, pod.Name, tc.reason),
+			}, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+				t.Fatalf("Did not get expected condition %s", diff.PrintWantGot(d))
+			}
 
-	expectedStatus := &apis.Condition{
-		Type:    apis.ConditionSucceeded,
-		Status:  corev1.ConditionFalse,
-		Reason:  "TaskRunCancelled",
-		Message: `TaskRun "test-taskrun-run-cancelled" was cancelled`,
-	}
-	if d := cmp.Diff(expectedStatus, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
-		t.Fatalf("Did not get expected condition %s", diff.PrintWantGot(d))
-	}
+			// Retry the TaskRun the way the PipelineRun reconciler does, and trigger reconcile.
+			retriedTr := newTr.DeepCopy()
+			retriedTr.Status.RetriesStatus = append(retriedTr.Status.RetriesStatus, *newTr.Status.DeepCopy())
+			retriedTr.Status.PodName = ""
+			retriedTr.Status.StartTime = nil
+			retriedTr.Status.CompletionTime = nil
+			retriedTr.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+			})
+			if _, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).UpdateStatus(retriedTr); err != nil {
+				t.Fatalf("Unexpected error while updating TaskRun: %v", err)
+			}
+			testAssets.Informers.TaskRun.Informer().GetIndexer().Add(retriedTr)
 
-	wantEvents := []string{
-		"Normal Started",
-		"Warning Failed TaskRun \"test-taskrun-run-cancelled\" was cancelled",
-	}
-	err = checkEvents(t, testAssets.Recorder, "test-reconcile-on-cancelled-taskrun", wantEvents)
-	if !(err == nil) {
-		t.Errorf(err.Error())
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile(): %v", err)
+			}
+			newTr, err = clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error fetching taskrun: %v", err)
+			}
+			if newTr.Status.PodName == "" || newTr.Status.PodName == pod.Name {
+				t.Errorf("Expected a new pod to be created for the retry, but the TaskRun pod is %q", newTr.Status.PodName)
+			}
+			if len(newTr.Status.RetriesStatus) != 1 || newTr.Status.RetriesStatus[0].PodName != pod.Name {
+				t.Errorf("Expected the evicted pod %q to be listed in retriesStatus, got %v", pod.Name, newTr.Status.RetriesStatus)
+			}
+		})
 	}
 }
 
-func TestReconcileTimeouts(t *testing.T) {
-	type testCase struct {
-		name           string
-		taskRun        *v1beta1.TaskRun
-		expectedStatus *apis.Condition
-		wantEvents     []string
-	}
+func TestReconcilePodEvictedPolicy(t *testing.T) {
+	// TestReconcilePodEvictedPolicy verifies that a TaskRun whose Pod was evicted is run again in
+	// a new Pod when the evicted-pod-policy is "retry", up to maxEvictionRetries times, and that it
+	// fails otherwise. The evictions are counted in an annotation, not in its retriesStatus.
+	for _, tc := range []struct {
+		name          string
+		policy        string
+		evictions     string
+		retries       []v1beta1.TaskRunStatus
+		wantRetried   bool
+		wantEvictions string
+		wantCondition corev1.ConditionStatus
+	}{{
+		name:          "fail policy",
+		policy:        config.FailEvictedPodPolicy,
+		wantCondition: corev1.ConditionFalse,
+	}, {
+		name:          "retry policy",
+		policy:        config.RetryEvictedPodPolicy,
+		wantRetried:   true,
+		wantEvictions: "1",
+		wantCondition: corev1.ConditionUnknown,
+	}, {
+		name:          "retry policy after other evictions and failures",
+		policy:        config.RetryEvictedPodPolicy,
+		evictions:     "2",
+		retries:       []v1beta1.TaskRunStatus{{}},
+		wantRetried:   true,
+		wantEvictions: "3",
+		wantCondition: corev1.ConditionUnknown,
+	}, {
+		name:          "retry policy with too many evictions",
+		policy:        config.RetryEvictedPodPolicy,
+		evictions:     "3",
+		wantEvictions: "3",
+		wantCondition: corev1.ConditionFalse,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			taskRun := tb.TaskRun("test-taskrun-evicted", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+			if tc.evictions != "" {
+				taskRun.Annotations = map[string]string{podEvictionsAnnotation: tc.evictions}
+			}
+			pod, err := makePod(taskRun, simpleTask)
+			if err != nil {
+				t.Fatalf("MakePod: %v", err)
+			}
+			pod.Spec.NodeName = "node"
+			pod.Status = corev1.PodStatus{
+				Phase:   corev1.PodFailed,
+				Reason:  "Evicted",
+				Message: "boom",
+			}
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName:       pod.Name,
+					RetriesStatus: tc.retries,
+				},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{pod},
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"evicted-pod-policy": tc.policy,
+					},
+				}},
+			}
 
-	testcases := []testCase{
-		{
-			name: "taskrun with timeout",
-			taskRun: tb.TaskRun("test-taskrun-timeout",
-				tb.TaskRunNamespace("foo"),
-				tb.TaskRunSpec(
-					tb.TaskRunTaskRef(simpleTask.Name),
-					tb.TaskRunTimeout(10*time.Second),
-				),
-				tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
-					Type:   apis.ConditionSucceeded,
-					Status: corev1.ConditionUnknown}),
-					tb.TaskRunStartTime(time.Now().Add(-15*time.Second)))),
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
 
-			expectedStatus: &apis.Condition{
-				Type:    apis.ConditionSucceeded,
-				Status:  corev1.ConditionFalse,
-				Reason:  "TaskRunTimeout",
-				Message: `TaskRun "test-taskrun-timeout" failed to finish within "10s"`,
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: "foo",
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile(): %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if got := newTr.Status.GetCondition(apis.ConditionSucceeded).Status; got != tc.wantCondition {
+				t.Errorf("Expected the TaskRun condition to be %s but got %s", tc.wantCondition, got)
+			}
+			if d := cmp.Diff(tc.retries, newTr.Status.RetriesStatus); d != "" {
+				t.Errorf("Expected the retriesStatus to be unchanged %s", diff.PrintWantGot(d))
+			}
+			if got := newTr.Annotations[podEvictionsAnnotation]; got != tc.wantEvictions {
+				t.Errorf("Expected %q evictions to be recorded but got %q", tc.wantEvictions, got)
+			}
+			if !tc.wantRetried {
+				if newTr.Status.PodName != pod.Name {
+					t.Errorf("Expected the TaskRun pod to remain %q but got %q", pod.Name, newTr.Status.PodName)
+				}
+				if r := newTr.Status.GetCondition(apis.ConditionSucceeded).Reason; r != v1beta1.TaskRunReasonEvicted.String() {
+					t.Errorf("Expected the TaskRun to have failed with reason %s but got %s", v1beta1.TaskRunReasonEvicted, r)
+				}
+				return
+			}
+			if newTr.Status.PodName == "" || newTr.Status.PodName == pod.Name {
+				t.Errorf("Expected a new pod to be created for the retry, but the TaskRun pod is %q", newTr.Status.PodName)
+			}
+			wantEvents := []string{
+				"Normal Started ",
+				"Warning PodEvicted Pod \"test-taskrun-evicted-pod-[a-z0-9]*\" was evicted from node \"node\" \\(Evicted\\), running the TaskRun again",
+				"Normal Running Not all Steps",
+			}
+			if err := checkEvents(t, testAssets.Recorder, tc.name, wantEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcileDebugEphemeralContainer(t *testing.T) {
+	// TestReconcileDebugEphemeralContainer verifies that the ephemeral container of the debug
+	// configuration of a TaskRun is added to its running Pod, once.
+	debugContainer := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  "debugger",
+			Image: "busybox",
+		},
+		TargetContainerName: "step-simple-step",
+	}
+	for _, tc := range []struct {
+		name       string
+		phase      corev1.PodPhase
+		existing   []corev1.EphemeralContainer
+		updateErr  error
+		wantUpdate bool
+		wantEvents []string
+	}{{
+		name:       "running pod",
+		phase:      corev1.PodRunning,
+		wantUpdate: true,
+		wantEvents: []string{
+			"Normal Started ",
+			"Normal EphemeralContainerAdded Added ephemeral container \"debugger\" to pod \"test-taskrun-debug-pod\"",
+			"Normal Running Not all Steps",
+		},
+	}, {
+		name:       "pending pod",
+		phase:      corev1.PodPending,
+		wantEvents: []string{"Normal Started ", "Normal Pending "},
+	}, {
+		name:       "already added",
+		phase:      corev1.PodRunning,
+		existing:   []corev1.EphemeralContainer{debugContainer},
+		wantEvents: []string{"Normal Started ", "Normal Running Not all Steps"},
+	}, {
+		name:       "update failure",
+		phase:      corev1.PodRunning,
+		updateErr:  errors.New("ephemeral containers are disabled"),
+		wantUpdate: true,
+		wantEvents: []string{
+			"Normal Started ",
+			"Warning EphemeralContainerFailed Failed to add ephemeral container \"debugger\" to pod \"test-taskrun-debug-pod\": ephemeral containers are disabled",
+			"Normal Running Not all Steps",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-debug", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+			taskRun.Spec.Debug = &v1beta1.TaskRunDebug{AddEphemeralContainer: debugContainer.DeepCopy()}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-debug-pod", Namespace: "foo"},
+				Spec: corev1.PodSpec{
+					Containers:          []corev1.Container{{Name: "step-simple-step"}},
+					EphemeralContainers: tc.existing,
+				},
+				Status: corev1.PodStatus{Phase: tc.phase},
+			}
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{PodName: pod.Name},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{pod},
+			}
+
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+			clients.Kube.PrependReactor("update", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "ephemeralcontainers" {
+					return false, nil, nil
+				}
+				return true, action.(ktesting.UpdateAction).GetObject(), tc.updateErr
+			})
+
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile(): %v", err)
+			}
+
+			var updates []*corev1.EphemeralContainers
+			for _, action := range clients.Kube.Actions() {
+				if action.GetVerb() == "update" && action.GetSubresource() == "ephemeralcontainers" {
+					updates = append(updates, action.(ktesting.UpdateAction).GetObject().(*corev1.EphemeralContainers))
+				}
+			}
+			if !tc.wantUpdate {
+				if len(updates) != 0 {
+					t.Errorf("Expected no update of the ephemeral containers but got %v", updates)
+				}
+			} else {
+				if len(updates) != 1 {
+					t.Fatalf("Expected one update of the ephemeral containers but got %d", len(updates))
+				}
+				if d := cmp.Diff([]corev1.EphemeralContainer{debugContainer}, updates[0].EphemeralContainers); d != "" {
+					t.Errorf("Unexpected ephemeral containers %s", diff.PrintWantGot(d))
+				}
+				if updates[0].Name != pod.Name {
+					t.Errorf("Expected the ephemeral containers of pod %q to be updated but got %q", pod.Name, updates[0].Name)
+				}
+			}
+			if err := checkEvents(t, testAssets.Recorder, tc.name, tc.wantEvents); err != nil {
+				t.Errorf(err.Error())
+			}
+		})
+	}
+}
+
+func TestReconcileAuditLog(t *testing.T) {
+	// TestReconcileAuditLog verifies that the transitions of a TaskRun with an audit log are
+	// appended to its audit log ConfigMap, which is created on the first transition.
+	now := time.Date(2021, time.April, 1, 10, 0, 0, 0, time.UTC)
+	transitions := `{"time":"2021-04-01T10:00:00Z","previous":{},"new":{"status":"Unknown","reason":"Started"},"event":"Started"}
+{"time":"2021-04-01T10:00:00Z","previous":{"status":"Unknown","reason":"Started"},"new":{"status":"Unknown","reason":"Pending"},"event":"Pending","message":"Pending"}
+`
+	for _, tc := range []struct {
+		name      string
+		auditLog  bool
+		existing  *corev1.ConfigMap
+		wantLog   string
+		wantLabel string
+	}{{
+		name:      "first transition",
+		auditLog:  true,
+		wantLog:   transitions,
+		wantLabel: "test-taskrun-audit",
+	}, {
+		name:     "existing audit log",
+		auditLog: true,
+		existing: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "audit-test-taskrun-audit",
+				Namespace: "foo",
+				Labels:    map[string]string{auditlog.LabelKey: "test-taskrun-audit"},
+			},
+			Data: map[string]string{auditlog.DataKey: "{\"event\":\"Started\"}\n"},
+		},
+		wantLog:   "{\"event\":\"Started\"}\n" + transitions,
+		wantLabel: "test-taskrun-audit",
+	}, {
+		// The ConfigMap isn't guarded by the webhook without the label
+		name:     "existing unlabelled configmap",
+		auditLog: true,
+		existing: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "audit-test-taskrun-audit",
+				Namespace: "foo",
+			},
+			Data: map[string]string{auditlog.DataKey: "{\"event\":\"Started\"}\n"},
+		},
+		wantLog: "{\"event\":\"Started\"}\n",
+	}, {
+		name: "no audit log",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-audit", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+			taskRun.Spec.AuditLog = tc.auditLog
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-audit-pod", Namespace: "foo"},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			}
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{PodName: pod.Name},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{pod},
+			}
+			if tc.existing != nil {
+				d.ConfigMaps = []*corev1.ConfigMap{tc.existing}
+			}
+
+			testAssets, cancel := getTaskRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile(): %v", err)
+			}
+
+			cm, err := clients.Kube.CoreV1().ConfigMaps("foo").Get("audit-test-taskrun-audit", metav1.GetOptions{})
+			if tc.wantLog == "" {
+				if !k8sapierrors.IsNotFound(err) {
+					t.Errorf("Expected no audit log but got %v, %v", cm, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to get the audit log: %v", err)
+			}
+			if d := cmp.Diff(tc.wantLog, cm.Data[auditlog.DataKey]); d != "" {
+				t.Errorf("Unexpected audit log %s", diff.PrintWantGot(d))
+			}
+			if cm.Labels[auditlog.LabelKey] != tc.wantLabel {
+				t.Errorf("Expected the audit log to be labelled with %q but got %v", tc.wantLabel, cm.Labels)
+			}
+		})
+	}
+}
+
+func TestReconcileOnCompletedTaskRun(t *testing.T) {
+	taskSt := &apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Build succeeded",
+		Message: "Build succeeded",
+	}
+	taskRun := tb.TaskRun("test-taskrun-run-success", tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+	), tb.TaskRunStatus(tb.StatusCondition(*taskSt)))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{
+			taskRun,
+		},
+		Tasks: []*v1beta1.Task{simpleTask},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if d := cmp.Diff(taskSt, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+		t.Fatalf("Did not get expected condition %s", diff.PrintWantGot(d))
+	}
+}
+
+// delayRecordingQueue records the items added to the queue after a delay instead of
+// waiting for the delay to pass.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[interface{}]time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays[item] = duration
+}
+
+func TestReconcileTTLAfterFinished(t *testing.T) {
+	// TestReconcileTTLAfterFinished runs "Reconcile" on TaskRuns with a TTL after finishing
+	// and a fake clock. It verifies that finished TaskRuns are deleted once their TTL has
+	// passed, that they are enqueued again for when it will have otherwise, and that running
+	// TaskRuns and the ones created by a PipelineRun are never deleted. The metrics of finished
+	// TaskRuns are recorded before they are deleted.
+	now := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	finished := tb.TaskRunStatus(
+		tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+			Reason: v1beta1.TaskRunReasonSuccessful.String(),
+		}),
+		tb.TaskRunStartTime(now.Add(-2*time.Minute)),
+		tb.TaskRunCompletionTime(now.Add(-time.Minute)),
+		tb.PodName("test-taskrun-ttl-pod"),
+	)
+	for _, tc := range []struct {
+		name         string
+		ops          []tb.TaskRunOp
+		defaultTTL   string
+		wantDeleted  bool
+		wantEnqueued time.Duration
+	}{{
+		name: "no ttl",
+		ops:  []tb.TaskRunOp{finished},
+	}, {
+		name:         "ttl not passed",
+		ops:          []tb.TaskRunOp{finished, tb.TaskRunAnnotation("tekton.dev/ttl-seconds-after-finished", "300")},
+		wantEnqueued: 4 * time.Minute,
+	}, {
+		name:        "ttl passed",
+		ops:         []tb.TaskRunOp{finished, tb.TaskRunAnnotation("tekton.dev/ttl-seconds-after-finished", "60")},
+		wantDeleted: true,
+	}, {
+		name:        "default ttl passed",
+		ops:         []tb.TaskRunOp{finished},
+		defaultTTL:  "30",
+		wantDeleted: true,
+	}, {
+		name: "created by a PipelineRun",
+		ops: []tb.TaskRunOp{finished,
+			tb.TaskRunAnnotation("tekton.dev/ttl-seconds-after-finished", "0"),
+			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run", tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"), tb.Controller),
+		},
+	}, {
+		name: "running",
+		ops: []tb.TaskRunOp{
+			tb.TaskRunStatus(tb.TaskRunStartTime(now.Add(-time.Minute))),
+			tb.TaskRunAnnotation("tekton.dev/ttl-seconds-after-finished", "0"),
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			unregisterMetrics()
+			ops := append([]tb.TaskRunOp{tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name))}, tc.ops...)
+			taskRun := tb.TaskRun("test-taskrun-ttl", ops...)
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-ttl-pod", Namespace: "foo"},
+				}},
+			}
+			if tc.defaultTTL != "" {
+				d.ConfigMaps = []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetDefaultsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"default-ttl-seconds-after-finished": tc.defaultTTL,
+					},
+				}}
+			}
+			testAssets, cancel := getTaskRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			queue := &delayRecordingQueue{
+				RateLimitingInterface: testAssets.Controller.WorkQueue,
+				delays:                map[interface{}]time.Duration{},
+			}
+			testAssets.Controller.WorkQueue = queue
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			_, err := testAssets.Clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+			if deleted := k8sapierrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got error %v", tc.wantDeleted, err)
+			}
+			key := types.NamespacedName{Namespace: "foo", Name: taskRun.Name}
+			if enqueued := queue.delays[key]; enqueued != tc.wantEnqueued {
+				t.Errorf("Expected the TaskRun to be enqueued after %v, got %v", tc.wantEnqueued, enqueued)
+			}
+			if tc.wantDeleted {
+				metricstest.CheckStatsReported(t, "taskrun_count")
+			}
+		})
+	}
+}
+
+func TestReconcileStepsProgress(t *testing.T) {
+	// TestReconcileStepsProgress runs "Reconcile" on TaskRuns with a running or terminated
+	// step and a fake clock. It verifies that the percentage of the step is computed from
+	// the timeout of the TaskRun, and that the TaskRun is enqueued again to update it while
+	// the step is running.
+	now := time.Now()
+	running := corev1.ContainerState{
+		Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(now.Add(-time.Minute))},
+	}
+	terminated := corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{ExitCode: 0},
+	}
+	for _, tc := range []struct {
+		name           string
+		timeout        time.Duration
+		state          corev1.ContainerState
+		wantPercentage *int32
+		wantEnqueued   time.Duration
+	}{{
+		name:           "running",
+		timeout:        10 * time.Minute,
+		state:          running,
+		wantPercentage: int32Ptr(10),
+		wantEnqueued:   10 * time.Second,
+	}, {
+		name:    "running without timeout",
+		timeout: 0,
+		state:   running,
+	}, {
+		name:           "terminated",
+		timeout:        10 * time.Minute,
+		state:          terminated,
+		wantPercentage: int32Ptr(100),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-progress", tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name), tb.TaskRunTimeout(tc.timeout)),
+				tb.TaskRunStatus(tb.PodName("the-pod"), tb.TaskRunStartTime(now.Add(-2*time.Minute))),
+			)
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "the-pod"},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						ContainerStatuses: []corev1.ContainerStatus{{
+							Name:  "step-simple-step",
+							State: tc.state,
+						}},
+					},
+				}},
+			}
+			testAssets, cancel := getTaskRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			queue := &delayRecordingQueue{
+				RateLimitingInterface: testAssets.Controller.WorkQueue,
+				delays:                map[interface{}]time.Duration{},
+			}
+			testAssets.Controller.WorkQueue = queue
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			newTr, err := testAssets.Clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if len(newTr.Status.Steps) != 1 {
+				t.Fatalf("Expected one step, got %v", newTr.Status.Steps)
+			}
+			if d := cmp.Diff(tc.wantPercentage, newTr.Status.Steps[0].Percentage); d != "" {
+				t.Errorf("Unexpected step percentage %s", diff.PrintWantGot(d))
+			}
+			key := types.NamespacedName{Namespace: "foo", Name: taskRun.Name}
+			if enqueued := queue.delays[key]; enqueued != tc.wantEnqueued {
+				t.Errorf("Expected the TaskRun to be enqueued after %v, got %v", tc.wantEnqueued, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcileRetryPodPruning(t *testing.T) {
+	// TestReconcileRetryPodPruning runs "Reconcile" on a TaskRun retried after a failed
+	// attempt. It verifies that the Pod of the failed attempt is deleted once the Pod of
+	// the current attempt is running, only when the feature flag is enabled, and that the
+	// Pod of the current attempt and the status of the failed attempt are kept.
+	for _, tc := range []struct {
+		name        string
+		enabled     bool
+		phase       corev1.PodPhase
+		wantDeleted bool
+	}{{
+		name:        "running",
+		enabled:     true,
+		phase:       corev1.PodRunning,
+		wantDeleted: true,
+	}, {
+		name:    "pending",
+		enabled: true,
+		phase:   corev1.PodPending,
+	}, {
+		name:  "disabled",
+		phase: corev1.PodRunning,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-retried", tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+				tb.TaskRunStatus(tb.PodName("attempt-1"), tb.TaskRunStartTime(time.Now())),
+			)
+			taskRun.Status.RetriesStatus = []v1beta1.TaskRunStatus{{
+				Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}}},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{PodName: "attempt-0"},
+			}}
+			attemptPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       "foo",
+						Name:            name,
+						Labels:          map[string]string{pipeline.GroupName + pipeline.TaskRunLabelKey: taskRun.Name},
+						OwnerReferences: []metav1.OwnerReference{taskRun.GetOwnerReference()},
+					},
+					Status: corev1.PodStatus{Phase: phase},
+				}
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{attemptPod("attempt-0", corev1.PodFailed), attemptPod("attempt-1", tc.phase)},
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"enable-retry-pod-pruning": strconv.FormatBool(tc.enabled),
+					},
+				}},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			_, err := clients.Kube.CoreV1().Pods("foo").Get("attempt-0", metav1.GetOptions{})
+			if deleted := k8sapierrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("Expected the pod of the failed attempt to be deleted: %t, got error %v", tc.wantDeleted, err)
+			}
+			if _, err := clients.Kube.CoreV1().Pods("foo").Get("attempt-1", metav1.GetOptions{}); err != nil {
+				t.Errorf("Expected the pod of the current attempt to be kept, got error %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if len(newTr.Status.RetriesStatus) != 1 || newTr.Status.RetriesStatus[0].PodName != "attempt-0" {
+				t.Errorf("Expected the status of the failed attempt to be kept, got %v", newTr.Status.RetriesStatus)
+			}
+		})
+	}
+}
+
+func TestReconcileImagePreWarm(t *testing.T) {
+	// TestReconcileImagePreWarm runs "Reconcile" on a TaskRun targeting a node, before its
+	// Pod is created. It verifies that its Pod is only created once the images of its Steps
+	// were pulled on that node or the pre-warming timed out, when the feature flag is enabled.
+	now := time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC)
+	prewarmPod := func(created time.Time, waiting string) *corev1.Pod {
+		cs := corev1.ContainerStatus{Name: "prewarm-0"}
+		if waiting != "" {
+			cs.State.Waiting = &corev1.ContainerStateWaiting{Reason: waiting}
+		} else {
+			cs.State.Running = &corev1.ContainerStateRunning{}
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "test-taskrun-node-prewarm", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "prewarm-0", Image: "foo"}}},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{cs}},
+		}
+	}
+	for _, tc := range []struct {
+		name          string
+		enabled       bool
+		prewarmPod    *corev1.Pod
+		wantPod       bool
+		wantPrewarmed bool
+	}{{
+		name:          "pulls the images",
+		enabled:       true,
+		wantPrewarmed: true,
+	}, {
+		name:          "waits for the images",
+		enabled:       true,
+		prewarmPod:    prewarmPod(now.Add(-time.Minute), "ContainerCreating"),
+		wantPrewarmed: true,
+	}, {
+		name:          "images pulled",
+		enabled:       true,
+		prewarmPod:    prewarmPod(now.Add(-time.Minute), ""),
+		wantPod:       true,
+		wantPrewarmed: true,
+	}, {
+		name:          "timed out",
+		enabled:       true,
+		prewarmPod:    prewarmPod(now.Add(-3*time.Minute), "ContainerCreating"),
+		wantPod:       true,
+		wantPrewarmed: true,
+	}, {
+		name:    "disabled",
+		wantPod: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-node", tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name), tb.TaskRunNodeSelector(map[string]string{corev1.LabelHostname: "node-1"})),
+			)
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						"enable-image-pre-warm": strconv.FormatBool(tc.enabled),
+					},
+				}},
+			}
+			if tc.prewarmPod != nil {
+				d.Pods = []*corev1.Pod{tc.prewarmPod}
+			}
+			testAssets, cancel := getTaskRunControllerWithClock(t, d, clock.NewFakeClock(now))
+			defer cancel()
+			clients := testAssets.Clients
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			prewarm, err := clients.Kube.CoreV1().Pods("foo").Get("test-taskrun-node-prewarm", metav1.GetOptions{})
+			if prewarmed := err == nil; prewarmed != tc.wantPrewarmed {
+				t.Fatalf("Expected a pod pulling the images: %t, got error %v", tc.wantPrewarmed, err)
+			}
+			if tc.wantPrewarmed && tc.prewarmPod == nil {
+				if prewarm.Spec.NodeName != "node-1" || len(prewarm.Spec.Containers) != 1 || prewarm.Spec.Containers[0].Image != "foo" {
+					t.Errorf("Expected a pod pulling image foo on node-1, got %v", prewarm.Spec)
+				}
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if created := newTr.Status.PodName != ""; created != tc.wantPod {
+				t.Errorf("Expected the pod of the TaskRun to be created: %t, got pod %q", tc.wantPod, newTr.Status.PodName)
+			}
+			if reason := newTr.Status.GetCondition(apis.ConditionSucceeded).Reason; !tc.wantPod && reason != ReasonPullingImages {
+				t.Errorf("Expected reason %s while pulling the images, got %s", ReasonPullingImages, reason)
+			}
+		})
+	}
+}
+
+func TestReconcileImagePreWarmCleanup(t *testing.T) {
+	// TestReconcileImagePreWarmCleanup runs "Reconcile" on a TaskRun whose Pod was created
+	// after pulling the images of its Steps. It verifies that the Pod pulling them is only
+	// deleted once the Pod of the TaskRun is running, and only while it still exists.
+	for _, tc := range []struct {
+		name        string
+		phase       corev1.PodPhase
+		deleted     bool
+		wantDeleted bool
+	}{{
+		name:        "running",
+		phase:       corev1.PodRunning,
+		wantDeleted: true,
+	}, {
+		name:  "pending",
+		phase: corev1.PodPending,
+	}, {
+		name:        "already deleted",
+		phase:       corev1.PodRunning,
+		deleted:     true,
+		wantDeleted: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-node", tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name), tb.TaskRunNodeSelector(map[string]string{corev1.LabelHostname: "node-1"})),
+				tb.TaskRunStatus(tb.PodName("test-taskrun-node-pod"), tb.TaskRunStartTime(time.Now())),
+			)
+			ownedPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       "foo",
+						Name:            name,
+						OwnerReferences: []metav1.OwnerReference{taskRun.GetOwnerReference()},
+					},
+					Status: corev1.PodStatus{Phase: phase},
+				}
+			}
+			pods := []*corev1.Pod{ownedPod("test-taskrun-node-pod", tc.phase)}
+			if !tc.deleted {
+				pods = append(pods, ownedPod("test-taskrun-node-prewarm", corev1.PodSucceeded))
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     pods,
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+					Data:       map[string]string{"enable-image-pre-warm": "true"},
+				}},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			_, err := clients.Kube.CoreV1().Pods("foo").Get("test-taskrun-node-prewarm", metav1.GetOptions{})
+			if deleted := k8sapierrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("Expected the pod pulling the images to be deleted: %t, got error %v", tc.wantDeleted, err)
+			}
+			for _, action := range clients.Kube.Actions() {
+				if action.Matches("delete", "pods") && tc.deleted {
+					t.Errorf("Expected the pod pulling the images not to be deleted again, got %v", action)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileOnCancelledTaskRun(t *testing.T) {
+	taskRun := tb.TaskRun("test-taskrun-run-cancelled",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(
+			tb.TaskRunTaskRef(simpleTask.Name),
+			tb.TaskRunCancelled,
+		), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown,
+		})))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+
+	expectedStatus := &apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  "TaskRunCancelled",
+		Message: `TaskRun "test-taskrun-run-cancelled" was cancelled`,
+	}
+	if d := cmp.Diff(expectedStatus, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+		t.Fatalf("Did not get expected condition %s", diff.PrintWantGot(d))
+	}
+
+	wantEvents := []string{
+		"Normal Started",
+		"Warning Failed TaskRun \"test-taskrun-run-cancelled\" was cancelled",
+	}
+	err = checkEvents(t, testAssets.Recorder, "test-reconcile-on-cancelled-taskrun", wantEvents)
+	if !(err == nil) {
+		t.Errorf(err.Error())
+	}
+}
+
+func TestReconcileTimeouts(t *testing.T) {
+	type testCase struct {
+		name           string
+		taskRun        *v1beta1.TaskRun
+		expectedStatus *apis.Condition
+		wantEvents     []string
+	}
+
+	testcases := []testCase{
+		{
+			name: "taskrun with timeout",
+			taskRun: tb.TaskRun("test-taskrun-timeout",
+				tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(
+					tb.TaskRunTaskRef(simpleTask.Name),
+					tb.TaskRunTimeout(10*time.Second),
+				),
+				tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionUnknown}),
+					tb.TaskRunStartTime(time.Now().Add(-15*time.Second)))),
+
+			expectedStatus: &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  "TaskRunTimeout",
+				Message: `TaskRun "test-taskrun-timeout" failed to finish within "10s"`,
 			},
 			wantEvents: []string{
 				"Warning Failed ",
@@ -2350,7 +3398,7 @@ func TestUpdateTaskRunResourceResult(t *testing.T) {
 				Type:   apis.ConditionSucceeded,
 				Status: corev1.ConditionTrue,
 			})
-			if err := updateTaskRunResourceResult(tr, c.pod); err != nil {
+			if err := updateTaskRunResourceResult(context.Background(), tr, c.pod); err != nil {
 				t.Errorf("updateTaskRunResourceResult: %s", err)
 			}
 			if d := cmp.Diff(c.want, tr.Status.ResourcesResult); d != "" {
@@ -2374,21 +3422,71 @@ func TestUpdateTaskRunResult(t *testing.T) {
 				ContainerStatuses: []corev1.ContainerStatus{{
 					State: corev1.ContainerState{
 						Terminated: &corev1.ContainerStateTerminated{
-							Message: `[{"key":"resultName","value":"resultValue", "type": "TaskRunResult"}, {"key":"digest","value":"sha256:1234","resourceRef":{"name":"source-image"}, "type": "PipelineResourceResult"}]`,
+							Message: `[{"key":"resultName","value":"resultValue", "type": "TaskRunResult"}, {"key":"digest","value":"sha256:1234","resourceRef":{"name":"source-image"}, "type": "PipelineResourceResult"}]`,
+						},
+					},
+				}},
+			},
+		},
+		wantResults: []v1beta1.TaskRunResult{{
+			Name:  "resultName",
+			Value: "resultValue",
+		}},
+		want: []resourcev1alpha1.PipelineResourceResult{{
+			Key:         "digest",
+			Value:       "sha256:1234",
+			ResourceRef: resourcev1alpha1.PipelineResourceRef{Name: "source-image"},
+			ResultType:  "PipelineResourceResult",
+		}},
+	}, {
+		desc: "test results of forEach iterations",
+		pod: corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "step-build",
+				}, {
+					Name: "step-test-0",
+					VolumeMounts: []corev1.VolumeMount{{
+						Name: "tekton-internal-results", MountPath: "/tekton/results", SubPath: "for-each/test/0",
+					}},
+				}, {
+					Name: "step-test-1",
+					VolumeMounts: []corev1.VolumeMount{{
+						Name: "tekton-internal-results", MountPath: "/tekton/results", SubPath: "for-each/test/1",
+					}},
+				}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "step-build",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"key":"image","value":"img", "type": "TaskRunResult"}]`,
+						},
+					},
+				}, {
+					Name: "step-test-1",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"key":"report","value":"darwin ok", "type": "TaskRunResult"}]`,
+						},
+					},
+				}, {
+					Name: "step-test-0",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"key":"report","value":"linux ok", "type": "TaskRunResult"}]`,
 						},
 					},
 				}},
 			},
 		},
 		wantResults: []v1beta1.TaskRunResult{{
-			Name:  "resultName",
-			Value: "resultValue",
-		}},
-		want: []resourcev1alpha1.PipelineResourceResult{{
-			Key:         "digest",
-			Value:       "sha256:1234",
-			ResourceRef: resourcev1alpha1.PipelineResourceRef{Name: "source-image"},
-			ResultType:  "PipelineResourceResult",
+			Name:  "image",
+			Value: "img",
+		}, {
+			Name:  "report",
+			Value: `["linux ok","darwin ok"]`,
 		}},
 	}} {
 		t.Run(c.desc, func(t *testing.T) {
@@ -2398,7 +3496,7 @@ func TestUpdateTaskRunResult(t *testing.T) {
 				Type:   apis.ConditionSucceeded,
 				Status: corev1.ConditionTrue,
 			})
-			if err := updateTaskRunResourceResult(tr, c.pod); err != nil {
+			if err := updateTaskRunResourceResult(context.Background(), tr, c.pod); err != nil {
 				t.Errorf("updateTaskRunResourceResult: %s", err)
 			}
 			if d := cmp.Diff(c.wantResults, tr.Status.TaskRunResults); d != "" {
@@ -2448,7 +3546,7 @@ func TestUpdateTaskRunResult2(t *testing.T) {
 				Type:   apis.ConditionSucceeded,
 				Status: corev1.ConditionTrue,
 			})
-			if err := updateTaskRunResourceResult(tr, c.pod); err != nil {
+			if err := updateTaskRunResourceResult(context.Background(), tr, c.pod); err != nil {
 				t.Errorf("updateTaskRunResourceResult: %s", err)
 			}
 			if d := cmp.Diff(c.wantResults, tr.Status.TaskRunResults); d != "" {
@@ -2501,7 +3599,7 @@ func TestUpdateTaskRunResultTwoResults(t *testing.T) {
 				Type:   apis.ConditionSucceeded,
 				Status: corev1.ConditionTrue,
 			})
-			if err := updateTaskRunResourceResult(tr, c.pod); err != nil {
+			if err := updateTaskRunResourceResult(context.Background(), tr, c.pod); err != nil {
 				t.Errorf("updateTaskRunResourceResult: %s", err)
 			}
 			if d := cmp.Diff(c.want, tr.Status.TaskRunResults); d != "" {
@@ -2550,6 +3648,61 @@ func TestUpdateTaskRunResultWhenTaskFailed(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskRunResultWhenTaskFailed_EnabledFailedAttemptResults(t *testing.T) {
+	pod := corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						Message: `[{"key":"resultName","value":"resultValue", "type": "TaskRunResult"}]`,
+					},
+				},
+			}},
+		},
+	}
+	for _, c := range []struct {
+		desc        string
+		enabled     bool
+		apiFields   string
+		wantResults []v1beta1.TaskRunResult
+	}{{
+		desc:        "results of failed task are dropped by default",
+		apiFields:   config.StableAPIFields,
+		wantResults: nil,
+	}, {
+		desc:        "results of failed task are dropped with alpha api fields",
+		apiFields:   config.AlphaAPIFields,
+		wantResults: nil,
+	}, {
+		desc:      "results of failed task are kept with failed attempt results enabled",
+		enabled:   true,
+		apiFields: config.StableAPIFields,
+		wantResults: []v1beta1.TaskRunResult{{
+			Name:  "resultName",
+			Value: "resultValue",
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableAPIFields = c.apiFields
+			cfg.FeatureFlags.EnableFailedAttemptResults = c.enabled
+			ctx := config.ToContext(context.Background(), cfg)
+
+			tr := &v1beta1.TaskRun{}
+			tr.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+			})
+			if err := updateTaskRunResourceResult(ctx, tr, pod); err != nil {
+				t.Errorf("updateTaskRunResourceResult: %s", err)
+			}
+			if d := cmp.Diff(c.wantResults, tr.Status.TaskRunResults); d != "" {
+				t.Errorf("updateTaskRunResourceResult results %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestUpdateTaskRunResourceResult_Errors(t *testing.T) {
 	for _, c := range []struct {
 		desc          string
@@ -2579,7 +3732,7 @@ func TestUpdateTaskRunResourceResult_Errors(t *testing.T) {
 	}} {
 		t.Run(c.desc, func(t *testing.T) {
 			names.TestingSeed()
-			if err := updateTaskRunResourceResult(&v1beta1.TaskRun{Status: *c.taskRunStatus}, c.pod); err == nil {
+			if err := updateTaskRunResourceResult(context.Background(), &v1beta1.TaskRun{Status: *c.taskRunStatus}, c.pod); err == nil {
 				t.Error("Expected error, got nil")
 			}
 			if d := cmp.Diff(c.want, c.taskRunStatus.ResourcesResult); d != "" {
@@ -2806,6 +3959,128 @@ func TestReconcileValidDefaultWorkspace(t *testing.T) {
 	}
 }
 
+// TestReconcileReadOnlyWorkspaceBinding tests a reconcile of a TaskRun binding a
+// Workspace read-only, while the Task declares it writable. The steps of the resulting
+// pod must not be able to write to the Workspace.
+func TestReconcileReadOnlyWorkspaceBinding(t *testing.T) {
+	taskWithWorkspace := tb.Task("test-task-with-workspace", tb.TaskNamespace("foo"),
+		tb.TaskSpec(
+			tb.TaskWorkspace("ws1", "a test task workspace", "/ws1", false),
+			tb.Step("foo", tb.StepName("simple-step"), tb.StepCommand("/mycmd")),
+		))
+	taskRun := tb.TaskRun("test-taskrun-read-only-workspace", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(taskWithWorkspace.Name),
+		func(spec *v1beta1.TaskRunSpec) {
+			spec.Workspaces = append(spec.Workspaces, v1beta1.WorkspaceBinding{
+				Name:     "ws1",
+				ReadOnly: true,
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "myclaim",
+				},
+			})
+		},
+	))
+	d := test.Data{
+		Tasks:    []*v1beta1.Task{taskWithWorkspace},
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: "foo",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Errorf("Expected no error reconciling valid TaskRun but got %v", err)
+	}
+
+	tr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected pod %s to exist but instead got error when getting it: %v", tr.Status.PodName, err)
+	}
+
+	for _, c := range pod.Spec.Containers {
+		found := false
+		for _, vm := range c.VolumeMounts {
+			if vm.MountPath == "/ws1" {
+				found = true
+				if !vm.ReadOnly {
+					t.Errorf("Expected workspace to be mounted read-only in container %s but it was writable", c.Name)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected workspace to be mounted in container %s but it was not", c.Name)
+		}
+	}
+}
+
+// TestReconcileReadOnlySourceWorkspaceBinding tests a reconcile of a TaskRun binding
+// a ConfigMap and a Secret to Workspaces. A warning event must be emitted for the
+// Workspace the Task doesn't declare readOnly, and only for it.
+func TestReconcileReadOnlySourceWorkspaceBinding(t *testing.T) {
+	taskWithWorkspaces := tb.Task("test-task-with-workspaces", tb.TaskNamespace("foo"),
+		tb.TaskSpec(
+			tb.TaskWorkspace("config", "written by the task", "/config", false),
+			tb.TaskWorkspace("credentials", "only read by the task", "/credentials", true),
+			tb.Step("foo", tb.StepName("simple-step"), tb.StepCommand("/mycmd")),
+		))
+	taskRun := tb.TaskRun("test-taskrun-read-only-sources", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(taskWithWorkspaces.Name),
+		func(spec *v1beta1.TaskRunSpec) {
+			spec.Workspaces = append(spec.Workspaces, v1beta1.WorkspaceBinding{
+				Name: "config",
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "myconfig"},
+				},
+			}, v1beta1.WorkspaceBinding{
+				Name:   "credentials",
+				Secret: &corev1.SecretVolumeSource{SecretName: "mysecret"},
+			})
+		},
+	))
+	d := test.Data{
+		Tasks:    []*v1beta1.Task{taskWithWorkspaces},
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: "foo",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Errorf("Expected no error reconciling valid TaskRun but got %v", err)
+	}
+
+	wantEvents := []string{
+		"Normal Started",
+		`Warning WorkspaceReadOnlySource Workspace "config" is bound to a read-only ConfigMap, Secret or CSI volume`,
+		"Normal Running",
+	}
+	if err := checkEvents(t, testAssets.Recorder, "read-only-sources", wantEvents); err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
 // TestReconcileInvalidDefaultWorkspace tests a reconcile of a TaskRun that does
 // not include a Workspace that the Task is expecting, and gets an error updating
 // the TaskRun with an invalid default workspace.
@@ -2851,6 +4126,112 @@ func TestReconcileInvalidDefaultWorkspace(t *testing.T) {
 	}
 }
 
+func TestReconcileWorkspaceSubPathPerRun(t *testing.T) {
+	// TestReconcileWorkspaceSubPathPerRun runs "Reconcile" on a TaskRun binding a shared claim with a
+	// subPath referencing its params and with subPathPerRun. It verifies that the workspace is mounted
+	// from the resolved subPath followed by the name of the TaskRun, at the path the Task declares.
+	task := tb.Task("test-task-with-workspace", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.TaskParam("project", v1beta1.ParamTypeString),
+		tb.TaskWorkspace("src", "", "", false),
+		tb.Step("foo", tb.StepName("build"), tb.StepCommand("/mycmd")),
+	))
+	taskRun := tb.TaskRun("test-taskrun-shared", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(task.Name),
+		tb.TaskRunParam("project", "tekton"),
+		tb.TaskRunWorkspacePVC("src", "$(params.project)", "shared-claim"),
+	))
+	taskRun.Spec.Workspaces[0].SubPathPerRun = true
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{task},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+			Data:       map[string]string{"enable-api-fields": config.AlphaAPIFields},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	pod, err := clients.Kube.CoreV1().Pods("foo").Get(newTr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the pod of the TaskRun to be created: %v", err)
+	}
+	var mounts []corev1.VolumeMount
+	for _, m := range pod.Spec.Containers[0].VolumeMounts {
+		if m.MountPath == "/workspace/src" {
+			mounts = append(mounts, m)
+		}
+	}
+	if len(mounts) != 1 || mounts[0].SubPath != "tekton/test-taskrun-shared" {
+		t.Errorf("Expected the workspace to be mounted from subPath tekton/test-taskrun-shared, got %v", mounts)
+	}
+}
+
+func TestReconcileTaskResultsSize(t *testing.T) {
+	// TestReconcileTaskResultsSize runs "Reconcile" on a TaskRun whose Pod succeeded
+	// after writing two results. It verifies that the total size of their values is
+	// reported in its status, and that the results of PipelineResources aren't counted.
+	taskRun := tb.TaskRun("test-taskrun-results-size", tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+		tb.TaskRunStatus(tb.PodName("test-taskrun-results-size-pod"), tb.TaskRunStartTime(time.Now())),
+	)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            "test-taskrun-results-size-pod",
+			OwnerReferences: []metav1.OwnerReference{taskRun.GetOwnerReference()},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-simple-step",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `[{"key":"date","value":"Thu Jan 23 16:29:06 UTC 2020","type":"TaskRunResult"},` +
+						`{"key":"timestamp","value":"1579796946","type":"TaskRunResult"},` +
+						`{"key":"digest","value":"sha256:1234","resourceRef":{"name":"source-image"},"type":"PipelineResourceResult"}]`,
+				}},
+			}},
+		},
+	}
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		Pods:     []*corev1.Pod{pod},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Error reconciling: %s", err)
+	}
+
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if len(newTr.Status.TaskRunResults) != 2 {
+		t.Fatalf("Expected 2 results, got %v", newTr.Status.TaskRunResults)
+	}
+	if want := int64(len("Thu Jan 23 16:29:06 UTC 2020") + len("1579796946")); newTr.Status.TaskResultsSize != want {
+		t.Errorf("Expected a results size of %d bytes, got %d", want, newTr.Status.TaskResultsSize)
+	}
+}
+
 func TestReconcileTaskResourceResolutionAndValidation(t *testing.T) {
 	for _, tt := range []struct {
 		desc             string
@@ -3020,7 +4401,7 @@ func TestReconcileWorkspaceWithVolumeClaimTemplate(t *testing.T) {
 	taskWithWorkspace := tb.Task("test-task-with-workspace", tb.TaskNamespace("foo"),
 		tb.TaskSpec(
 			tb.TaskWorkspace(workspaceName, "a test task workspace", "", true),
-			tb.Step("foo", tb.StepName("simple-step"), tb.StepCommand("/mycmd")),
+			tb.Step("foo", tb.StepName("simple-step"), tb.StepCommand("/mycmd"), tb.StepArgs("--claim=$(workspaces.ws1.claim)")),
 		))
 	taskRun := tb.TaskRun("test-taskrun-missing-workspace", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
 		tb.TaskRunTaskRef(taskWithWorkspace.Name, tb.TaskRefAPIVersion("a1")),
@@ -3072,6 +4453,153 @@ func TestReconcileWorkspaceWithVolumeClaimTemplate(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected PVC %s to exist but instead got error when getting it: %v", expectedPVCName, err)
 	}
+
+	// The $(workspaces.ws1.claim) variable is replaced by the name of the PVC created from the template.
+	pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(ttt.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected Pod %s to exist but instead got error when getting it: %v", ttt.Status.PodName, err)
+	}
+	wantArg := "--claim=" + expectedPVCName
+	found := false
+	for _, arg := range pod.Spec.Containers[0].Args {
+		if arg == wantArg {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the args of the step to contain %q, got %v", wantArg, pod.Spec.Containers[0].Args)
+	}
+}
+
+// TestReconcileWorkspaceCleanupAfterCompletion verifies that the PersistentVolumeClaim of a
+// workspace cleaned up after completion is only deleted once the TaskRun has succeeded and
+// no other TaskRun references it anymore.
+func TestReconcileWorkspaceCleanupAfterCompletion(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		status     corev1.ConditionStatus
+		references string
+		wantPVC    bool
+		wantRefs   string
+	}{{
+		name:       "succeeded",
+		status:     corev1.ConditionTrue,
+		references: "test-taskrun-cleanup",
+	}, {
+		name:       "failed",
+		status:     corev1.ConditionFalse,
+		references: "test-taskrun-cleanup",
+		wantPVC:    true,
+	}, {
+		name:       "referenced by another taskrun",
+		status:     corev1.ConditionTrue,
+		references: "other-taskrun,test-taskrun-cleanup",
+		wantPVC:    true,
+		wantRefs:   "other-taskrun",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-cleanup", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+				tb.TaskRunTaskRef(simpleTask.Name),
+				tb.TaskRunWorkspacePVC("ws", "", "my-pvc"),
+			), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: tc.status,
+			})))
+			taskRun.Spec.Workspaces[0].CleanupAfterCompletion = true
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			clients := testAssets.Clients
+
+			if _, err := clients.Kube.CoreV1().PersistentVolumeClaims("foo").Create(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-pvc",
+					Namespace:   "foo",
+					Annotations: map[string]string{workspaceReferencesAnnotation: tc.references},
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Errorf("expected no error reconciling valid TaskRun but got %v", err)
+			}
+
+			pvc, err := clients.Kube.CoreV1().PersistentVolumeClaims("foo").Get("my-pvc", metav1.GetOptions{})
+			switch {
+			case !tc.wantPVC && err == nil:
+				t.Errorf("expected PVC my-pvc to be deleted")
+			case !tc.wantPVC && !k8sapierrors.IsNotFound(err):
+				t.Errorf("expected PVC my-pvc to be deleted but got error when getting it: %v", err)
+			case tc.wantPVC && err != nil:
+				t.Fatalf("expected PVC my-pvc to exist but got error when getting it: %v", err)
+			case tc.wantPVC && pvc.Annotations[workspaceReferencesAnnotation] != tc.wantRefs:
+				t.Errorf("expected PVC my-pvc to be referenced by %q but got %q", tc.wantRefs, pvc.Annotations[workspaceReferencesAnnotation])
+			}
+		})
+	}
+}
+
+// TestReconcileWorkspaceCleanupReferences verifies that a TaskRun references the claim of a
+// workspace cleaned up after completion before its Pod is created.
+func TestReconcileWorkspaceCleanupReferences(t *testing.T) {
+	taskWithWorkspace := tb.Task("test-task-with-workspace", tb.TaskNamespace("foo"),
+		tb.TaskSpec(
+			tb.TaskWorkspace("ws", "a test task workspace", "", false),
+			tb.Step("foo", tb.StepName("simple-step"), tb.StepCommand("/mycmd")),
+		))
+	taskRun := tb.TaskRun("test-taskrun-cleanup", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(taskWithWorkspace.Name),
+		tb.TaskRunWorkspacePVC("ws", "", "my-pvc"),
+	))
+	taskRun.Spec.Workspaces[0].CleanupAfterCompletion = true
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{taskWithWorkspace},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"enable-api-fields": config.AlphaAPIFields,
+			},
+		}},
+	}
+	names.TestingSeed()
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: "foo",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clients.Kube.CoreV1().PersistentVolumeClaims("foo").Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-pvc",
+			Namespace:   "foo",
+			Annotations: map[string]string{workspaceReferencesAnnotation: "other-taskrun"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Errorf("expected no error reconciling valid TaskRun but got %v", err)
+	}
+
+	pvc, err := clients.Kube.CoreV1().PersistentVolumeClaims("foo").Get("my-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected PVC my-pvc to exist but got error when getting it: %v", err)
+	}
+	if d := cmp.Diff("other-taskrun,test-taskrun-cleanup", pvc.Annotations[workspaceReferencesAnnotation]); d != "" {
+		t.Errorf("unexpected references of PVC my-pvc %s", diff.PrintWantGot(d))
+	}
 }
 
 func TestFailTaskRun(t *testing.T) {
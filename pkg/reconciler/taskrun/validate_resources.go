@@ -128,3 +128,21 @@ func ValidateResolvedTaskResources(params []v1alpha1.Param, rtr *resources.Resol
 
 	return nil
 }
+
+// validateStepOverrides checks that the steps overridden by the TaskRun exist in the task spec.
+func validateStepOverrides(ts *v1beta1.TaskSpec, overrides []v1beta1.TaskRunStepOverride) error {
+	stepNames := map[string]bool{}
+	for _, step := range ts.Steps {
+		stepNames[step.Name] = true
+	}
+	var missing []string
+	for _, o := range overrides {
+		if !stepNames[o.Name] {
+			missing = append(missing, o.Name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("invalid stepOverrides: steps %s not found in the task spec", missing)
+	}
+	return nil
+}
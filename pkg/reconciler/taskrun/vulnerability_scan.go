@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/vulnscan"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// ReasonVulnerabilityScanFailed indicates that the images of the Steps of a
+// TaskRun couldn't be scanned for vulnerabilities
+const ReasonVulnerabilityScanFailed = "VulnerabilityScanFailed"
+
+// errTrivyImageUnset is the error returned when the images of Steps should be scanned
+// but the controller wasn't given an image containing Trivy.
+var errTrivyImageUnset = errors.New("the -trivy-image flag of the controller isn't set")
+
+// scanVulnerabilities scans the images the Steps of the completed tr ran for
+// vulnerabilities when the "enable-vulnerability-scanning" feature flag is
+// set. The scan runs in a Pod owned by tr, so tr is reconciled again once it
+// completes, and its result is then recorded in the VulnerabilitySummary of tr
+// and the Pod is deleted.
+func (c *Reconciler) scanVulnerabilities(ctx context.Context, tr *v1beta1.TaskRun) error {
+	if !config.FromContextOrDefaults(ctx).FeatureFlags.EnableVulnerabilityScanning || tr.Status.PodName == "" {
+		return nil
+	}
+	logger := logging.FromContext(ctx)
+	summaries := c.PipelineClientSet.TektonV1alpha1().VulnerabilitySummaries(tr.Namespace)
+	if _, err := summaries.Get(tr.Name, metav1.GetOptions{}); err == nil || !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	pods := c.KubeClientSet.CoreV1().Pods(tr.Namespace)
+	scanPod, err := pods.Get(vulnscan.ScanPodName(tr.Name), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		pod, err := pods.Get(tr.Status.PodName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			logger.Infof("Not scanning the images of taskrun %q, its pod %q was deleted", tr.Name, tr.Status.PodName)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		images := vulnscan.StepImages(pod)
+		if len(images) == 0 {
+			return nil
+		}
+		if c.Images.TrivyImage == "" {
+			// Scanning again won't help until the controller is restarted with the flag.
+			return controller.NewPermanentError(c.vulnerabilityScanError(ctx, tr, errTrivyImageUnset))
+		}
+		if scanPod, err = vulnscan.NewScanPod(tr, images, c.Images.TrivyImage); err == nil {
+			_, err = pods.Create(scanPod)
+		}
+		return c.vulnerabilityScanError(ctx, tr, err)
+	} else if err != nil {
+		return err
+	}
+	if !vulnscan.IsScanDone(scanPod) {
+		return nil
+	}
+
+	summary, err := vulnscan.NewSummary(tr, scanPod, func(container string) ([]byte, error) {
+		return c.podLogs.ContainerLogs(scanPod.Namespace, scanPod.Name, container)
+	})
+	if err != nil {
+		return c.vulnerabilityScanError(ctx, tr, err)
+	}
+	if _, err := summaries.Create(summary); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return c.vulnerabilityScanError(ctx, tr, err)
+	}
+	logger.Infof("Recorded the vulnerabilities of the images of taskrun %q, deleting pod %q", tr.Name, scanPod.Name)
+	if err := pods.Delete(scanPod.Name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// vulnerabilityScanError emits a warning event for tr if err isn't nil, and
+// returns it so that the scan is attempted again.
+func (c *Reconciler) vulnerabilityScanError(ctx context.Context, tr *v1beta1.TaskRun, err error) error {
+	if err == nil {
+		return nil
+	}
+	controller.GetEventRecorder(ctx).Eventf(tr, corev1.EventTypeWarning, ReasonVulnerabilityScanFailed,
+		"Failed to scan the images of the steps for vulnerabilities: %v", err)
+	return err
+}
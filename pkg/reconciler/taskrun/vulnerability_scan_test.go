@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	fakepipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	"github.com/tektoncd/pipeline/pkg/vulnscan"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+)
+
+func TestScanVulnerabilities(t *testing.T) {
+	const imageID = "docker.io/library/ubuntu@sha256:cf31af331f38d1d7158470e095b132acd126a7180a54f263d386da88eb681d93"
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Status: v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{PodName: "build-pod"},
+		},
+	}
+	taskRunPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: "foo"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-compile", Image: "ubuntu"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "step-compile", ImageID: "docker-pullable://" + imageID}},
+		},
+	}
+	scanPod, err := vulnscan.NewScanPod(tr, []v1alpha1.ImageVulnerabilities{{Image: "ubuntu", ImageID: imageID}}, "aquasec/trivy")
+	if err != nil {
+		t.Fatalf("NewScanPod() = %v", err)
+	}
+	runningScanPod := scanPod.DeepCopy()
+	runningScanPod.Status.Phase = corev1.PodRunning
+	completedScanPod := scanPod.DeepCopy()
+	completedScanPod.Status = corev1.PodStatus{
+		Phase: corev1.PodSucceeded,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "scan-0",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+		}},
+	}
+	existingSummary := &v1alpha1.VulnerabilitySummary{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"}}
+
+	for _, tc := range []struct {
+		name        string
+		disabled    bool
+		noTrivy     bool
+		pods        []runtime.Object
+		summaries   []runtime.Object
+		wantPods    []string
+		wantSummary *v1alpha1.ImageVulnerabilities
+	}{{
+		name:     "disabled",
+		disabled: true,
+		pods:     []runtime.Object{taskRunPod},
+		wantPods: []string{"build-pod"},
+	}, {
+		name:     "scan started",
+		pods:     []runtime.Object{taskRunPod},
+		wantPods: []string{"build-pod", "build-vulnscan"},
+	}, {
+		name:     "trivy image unset",
+		noTrivy:  true,
+		pods:     []runtime.Object{taskRunPod},
+		wantPods: []string{"build-pod"},
+	}, {
+		name:     "scan running",
+		pods:     []runtime.Object{taskRunPod, runningScanPod},
+		wantPods: []string{"build-pod", "build-vulnscan"},
+	}, {
+		name:     "scan completed",
+		pods:     []runtime.Object{taskRunPod, completedScanPod},
+		wantPods: []string{"build-pod"},
+		wantSummary: &v1alpha1.ImageVulnerabilities{
+			Image:                   "ubuntu",
+			ImageID:                 imageID,
+			Critical:                1,
+			High:                    1,
+			CriticalVulnerabilities: []string{"CVE-2021-3156"},
+		},
+	}, {
+		name:      "already scanned",
+		pods:      []runtime.Object{taskRunPod},
+		summaries: []runtime.Object{existingSummary},
+		wantPods:  []string{"build-pod"},
+	}, {
+		name: "taskrun pod deleted",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := fakekubeclientset.NewSimpleClientset(tc.pods...)
+			pipelineClient := fakepipelineclientset.NewSimpleClientset(tc.summaries...)
+			images := pipeline.Images{TrivyImage: "aquasec/trivy"}
+			if tc.noTrivy {
+				images.TrivyImage = ""
+			}
+			c := &Reconciler{
+				KubeClientSet:     kubeClient,
+				PipelineClientSet: pipelineClient,
+				Images:            images,
+				podLogs: &fakePodLogsSource{logs: map[string]string{
+					"scan-0": "CRITICAL:CVE-2021-3156 HIGH:CVE-2021-3177 ",
+				}},
+			}
+			cfg := config.FromContextOrDefaults(context.Background())
+			cfg.FeatureFlags.EnableVulnerabilityScanning = !tc.disabled
+			ctx := controller.WithEventRecorder(config.ToContext(context.Background(), cfg), record.NewFakeRecorder(10))
+
+			err := c.scanVulnerabilities(ctx, tr.DeepCopy())
+			if tc.noTrivy {
+				if !controller.IsPermanentError(err) {
+					t.Errorf("Expected a permanent error without the trivy image, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("scanVulnerabilities() = %v", err)
+			}
+
+			pods, err := kubeClient.CoreV1().Pods("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Failed to list pods: %v", err)
+			}
+			var names []string
+			for _, p := range pods.Items {
+				names = append(names, p.Name)
+			}
+			if d := cmp.Diff(tc.wantPods, names); d != "" {
+				t.Errorf("Unexpected pods %s", diff.PrintWantGot(d))
+			}
+
+			summary, err := pipelineClient.TektonV1alpha1().VulnerabilitySummaries("foo").Get("build", metav1.GetOptions{})
+			if tc.wantSummary == nil {
+				if len(tc.summaries) == 0 && !k8sapierrors.IsNotFound(err) {
+					t.Errorf("Expected no VulnerabilitySummary but got %v, %v", summary, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to get the VulnerabilitySummary: %v", err)
+			}
+			if d := cmp.Diff([]v1alpha1.ImageVulnerabilities{*tc.wantSummary}, summary.Spec.Images); d != "" {
+				t.Errorf("Unexpected VulnerabilitySummary %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
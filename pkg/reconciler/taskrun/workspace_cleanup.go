@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/logging"
+)
+
+// workspaceReferencesAnnotation is set on the PersistentVolumeClaims of workspaces
+// which are cleaned up after completion. It holds the comma separated names of the
+// TaskRuns which still use the claim, so that it is only deleted once none of them
+// is running anymore.
+const workspaceReferencesAnnotation = pipeline.GroupName + "/workspace-references"
+
+// addWorkspaceReferences records tr in the references of the claims backing its
+// workspaces. This is done for the workspaces which are cleaned up after completion,
+// and for claims which are already referenced by a TaskRun cleaning them up, so that
+// they are not deleted while tr uses them.
+func (c *Reconciler) addWorkspaceReferences(tr *v1beta1.TaskRun) error {
+	for _, wb := range tr.Spec.Workspaces {
+		claimName := workspaceClaimName(tr, wb)
+		if claimName == "" {
+			continue
+		}
+		pvc, err := c.KubeClientSet.CoreV1().PersistentVolumeClaims(tr.Namespace).Get(claimName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PersistentVolumeClaim %s of workspace %q: %w", claimName, wb.Name, err)
+		}
+		refs, tracked := workspaceReferences(pvc)
+		if (!wb.CleanupAfterCompletion && !tracked) || containsString(refs, tr.Name) {
+			continue
+		}
+		if err := c.updateWorkspaceReferences(pvc, append(refs, tr.Name)); err != nil {
+			return fmt.Errorf("failed to reference PersistentVolumeClaim %s of workspace %q: %w", claimName, wb.Name, err)
+		}
+	}
+	return nil
+}
+
+// cleanupWorkspaces removes tr from the references of the claims backing its
+// workspaces. Claims of workspaces which are cleaned up after completion are
+// deleted once tr has succeeded and no other TaskRun references them anymore.
+func (c *Reconciler) cleanupWorkspaces(ctx context.Context, tr *v1beta1.TaskRun) error {
+	logger := logging.FromContext(ctx)
+	for _, wb := range tr.Spec.Workspaces {
+		claimName := workspaceClaimName(tr, wb)
+		if claimName == "" {
+			continue
+		}
+		pvc, err := c.KubeClientSet.CoreV1().PersistentVolumeClaims(tr.Namespace).Get(claimName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PersistentVolumeClaim %s of workspace %q: %w", claimName, wb.Name, err)
+		}
+		refs, _ := workspaceReferences(pvc)
+		if !containsString(refs, tr.Name) {
+			// Either tr never used the claim, or it was already cleaned up.
+			continue
+		}
+		refs = removeString(refs, tr.Name)
+		if wb.CleanupAfterCompletion && tr.IsSuccessful() && len(refs) == 0 {
+			logger.Infof("Deleting PersistentVolumeClaim %s of workspace %q of TaskRun %s", claimName, wb.Name, tr.Name)
+			err := c.KubeClientSet.CoreV1().PersistentVolumeClaims(tr.Namespace).Delete(claimName, &metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &pvc.UID},
+			})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete PersistentVolumeClaim %s of workspace %q: %w", claimName, wb.Name, err)
+			}
+			continue
+		}
+		if err := c.updateWorkspaceReferences(pvc, refs); err != nil {
+			return fmt.Errorf("failed to release PersistentVolumeClaim %s of workspace %q: %w", claimName, wb.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Reconciler) updateWorkspaceReferences(pvc *corev1.PersistentVolumeClaim, refs []string) error {
+	pvc = pvc.DeepCopy()
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[workspaceReferencesAnnotation] = strings.Join(refs, ",")
+	_, err := c.KubeClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(pvc)
+	return err
+}
+
+// workspaceClaimName returns the name of the PersistentVolumeClaim backing the
+// workspace bound by wb in tr, or "" if the workspace isn't backed by a claim.
+func workspaceClaimName(tr *v1beta1.TaskRun, wb v1beta1.WorkspaceBinding) string {
+	switch {
+	case wb.PersistentVolumeClaim != nil:
+		return wb.PersistentVolumeClaim.ClaimName
+	case wb.VolumeClaimTemplate != nil:
+		return volumeclaim.GetPersistentVolumeClaimName(wb.VolumeClaimTemplate, wb, tr.GetOwnerReference())
+	default:
+		return ""
+	}
+}
+
+// workspaceReferences returns the names of the TaskRuns referencing pvc, and
+// whether its references are tracked at all.
+func workspaceReferences(pvc *corev1.PersistentVolumeClaim) ([]string, bool) {
+	value, tracked := pvc.Annotations[workspaceReferencesAnnotation]
+	if value == "" {
+		return nil, tracked
+	}
+	return strings.Split(value, ","), tracked
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, s string) []string {
+	var out []string
+	for _, v := range values {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
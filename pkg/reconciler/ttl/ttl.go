@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/controller"
+)
+
+// AnnotationKey is the annotation a run sets to be deleted this many seconds after it finished.
+const AnnotationKey = pipeline.GroupName + pipeline.TTLSecondsAfterFinishedKey
+
+// Get returns how long a finished run with the given annotations is kept before being
+// deleted. The annotation of the run takes precedence over the default from the
+// config-defaults ConfigMap. It returns false when the run is kept forever.
+func Get(ctx context.Context, annotations map[string]string) (time.Duration, bool, error) {
+	if value, ok := annotations[AnnotationKey]; ok {
+		seconds, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || seconds < 0 {
+			return 0, false, fmt.Errorf("invalid value %q for annotation %q: expected a non-negative number of seconds", value, AnnotationKey)
+		}
+		return time.Duration(seconds) * time.Second, true, nil
+	}
+	if seconds := config.FromContextOrDefaults(ctx).Defaults.DefaultTTLSecondsAfterFinished; seconds != nil {
+		return time.Duration(*seconds) * time.Second, true, nil
+	}
+	return 0, false, nil
+}
+
+// Handler deletes finished runs once their TTL has passed. Until then, the runs are
+// enqueued again for the time their TTL passes, so that no periodic sweep is needed.
+type Handler struct {
+	clock clock.Clock
+	// enqueueAfterFunc is the function called to reconcile a run again once its TTL
+	// has passed. This is usually set to the function that enqueues the run after a delay.
+	enqueueAfterFunc func(interface{}, time.Duration)
+}
+
+// NewHandler returns a Handler measuring the TTL of runs with the given clock.
+func NewHandler(clock clock.Clock) *Handler {
+	return &Handler{
+		clock:            clock,
+		enqueueAfterFunc: func(interface{}, time.Duration) {},
+	}
+}
+
+// SetEnqueueAfterFunc sets the function called to reconcile a run again once its TTL has passed.
+func (h *Handler) SetEnqueueAfterFunc(f func(interface{}, time.Duration)) {
+	h.enqueueAfterFunc = f
+}
+
+// Handle calls deleteFunc to delete the run if its TTL has passed since completionTime,
+// or enqueues the run for when it will have otherwise. It must only be called for runs
+// in a terminal state and does nothing for runs without a completion time. It returns
+// true once the run has been deleted, and a permanent error when the run sets an
+// invalid TTL.
+func (h *Handler) Handle(ctx context.Context, run metav1.Object, completionTime *metav1.Time, deleteFunc func() error) (bool, error) {
+	if completionTime == nil {
+		return false, nil
+	}
+	ttl, ok, err := Get(ctx, run.GetAnnotations())
+	if err != nil {
+		// Retrying won't fix an invalid annotation, the run is reconciled again once it is updated.
+		return false, controller.NewPermanentError(err)
+	}
+	if !ok {
+		return false, nil
+	}
+	if remaining := completionTime.Add(ttl).Sub(h.clock.Now()); remaining > 0 {
+		h.enqueueAfterFunc(run, remaining)
+		return false, nil
+	}
+	if err := deleteFunc(); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
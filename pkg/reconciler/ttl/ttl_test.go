@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/controller"
+)
+
+var now = time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func withDefaultTTL(ctx context.Context, seconds int32) context.Context {
+	cfg := config.FromContextOrDefaults(ctx)
+	defaults := *cfg.Defaults
+	defaults.DefaultTTLSecondsAfterFinished = &seconds
+	cfg.Defaults = &defaults
+	return config.ToContext(ctx, cfg)
+}
+
+func TestGet(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		ctx         context.Context
+		annotations map[string]string
+		wantTTL     time.Duration
+		wantOK      bool
+	}{{
+		desc: "no ttl",
+		ctx:  context.Background(),
+	}, {
+		desc:        "annotation",
+		ctx:         context.Background(),
+		annotations: map[string]string{AnnotationKey: "60"},
+		wantTTL:     time.Minute,
+		wantOK:      true,
+	}, {
+		desc:    "default",
+		ctx:     withDefaultTTL(context.Background(), 3600),
+		wantTTL: time.Hour,
+		wantOK:  true,
+	}, {
+		desc:        "annotation overrides the default",
+		ctx:         withDefaultTTL(context.Background(), 3600),
+		annotations: map[string]string{AnnotationKey: "0"},
+		wantTTL:     0,
+		wantOK:      true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ttl, ok, err := Get(tc.ctx, tc.annotations)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ttl != tc.wantTTL || ok != tc.wantOK {
+				t.Errorf("Get() = %v, %t, want %v, %t", ttl, ok, tc.wantTTL, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestGet_Invalid(t *testing.T) {
+	for _, value := range []string{"", "-1", "1h", "99999999999"} {
+		t.Run(value, func(t *testing.T) {
+			if _, _, err := Get(context.Background(), map[string]string{AnnotationKey: value}); err == nil {
+				t.Errorf("Expected an error for the annotation value %q", value)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	completed := metav1.NewTime(now.Add(-time.Minute))
+	for _, tc := range []struct {
+		desc           string
+		ttl            string
+		completionTime *metav1.Time
+		wantDeleted    bool
+		wantEnqueued   time.Duration
+	}{{
+		desc:           "no ttl",
+		completionTime: &completed,
+	}, {
+		desc: "not completed",
+		ttl:  "0",
+	}, {
+		desc:           "ttl not passed",
+		ttl:            "300",
+		completionTime: &completed,
+		wantEnqueued:   4 * time.Minute,
+	}, {
+		desc:           "ttl passed",
+		ttl:            "60",
+		completionTime: &completed,
+		wantDeleted:    true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			run := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "run"}}
+			if tc.ttl != "" {
+				run.Annotations = map[string]string{AnnotationKey: tc.ttl}
+			}
+			h := NewHandler(clock.NewFakeClock(now))
+			var enqueued time.Duration
+			h.SetEnqueueAfterFunc(func(_ interface{}, after time.Duration) {
+				enqueued = after
+			})
+			deleteCalled := false
+			deleted, err := h.Handle(context.Background(), run, tc.completionTime, func() error {
+				deleteCalled = true
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if deleted != tc.wantDeleted || deleteCalled != tc.wantDeleted {
+				t.Errorf("Expected the run to be deleted: %t, got %t (delete called: %t)", tc.wantDeleted, deleted, deleteCalled)
+			}
+			if enqueued != tc.wantEnqueued {
+				t.Errorf("Expected the run to be enqueued after %v, got %v", tc.wantEnqueued, enqueued)
+			}
+		})
+	}
+}
+
+func TestHandle_InvalidTTL(t *testing.T) {
+	completed := metav1.NewTime(now)
+	run := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name:        "run",
+		Annotations: map[string]string{AnnotationKey: "forever"},
+	}}
+	deleted, err := NewHandler(clock.NewFakeClock(now)).Handle(context.Background(), run, &completed, func() error {
+		t.Error("Expected the run not to be deleted")
+		return nil
+	})
+	if deleted || !controller.IsPermanentError(err) {
+		t.Errorf("Expected a permanent error and the run to be kept, got %t, %v", deleted, err)
+	}
+}
@@ -37,6 +37,15 @@ type Resolver struct {
 	keychain       authn.Keychain
 }
 
+// NewResolver returns a Resolver retrieving Tekton resources from the OCI image imageReference,
+// authenticating to its registry with keychain.
+func NewResolver(imageReference string, keychain authn.Keychain) *Resolver {
+	return &Resolver{
+		imageReference: imageReference,
+		keychain:       keychain,
+	}
+}
+
 func (o *Resolver) List() ([]remote.ResolvedObject, error) {
 	img, err := o.retrieveImage()
 	if err != nil {
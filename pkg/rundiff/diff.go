@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rundiff compares the executions of two PipelineRuns of the same Pipeline,
+// to help debugging Pipelines which don't behave the same from one run to the next.
+package rundiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// TaskSucceeded is the outcome of a PipelineTask whose TaskRun succeeded.
+	TaskSucceeded = "Succeeded"
+	// TaskFailed is the outcome of a PipelineTask whose TaskRun failed.
+	TaskFailed = "Failed"
+	// TaskRunning is the outcome of a PipelineTask whose TaskRun hasn't completed.
+	TaskRunning = "Running"
+)
+
+// Diff is the difference between the executions of two PipelineRuns. Its JSON
+// encoding is the machine-readable form of the diff.
+type Diff struct {
+	// Pipeline is the name of the Pipeline both PipelineRuns reference, if any.
+	Pipeline string `json:"pipeline,omitempty"`
+	// Left and Right are the names of the compared PipelineRuns.
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// OnlyInLeft and OnlyInRight are the PipelineTasks which ran in one
+	// PipelineRun but not in the other.
+	OnlyInLeft  []string `json:"onlyInLeft,omitempty"`
+	OnlyInRight []string `json:"onlyInRight,omitempty"`
+	// Tasks compares the PipelineTasks which ran in both PipelineRuns.
+	Tasks []TaskDiff `json:"tasks,omitempty"`
+	// Params lists the parameters whose values differ between the PipelineRuns.
+	Params []ParamDiff `json:"params,omitempty"`
+}
+
+// TaskDiff compares the execution of a PipelineTask in both PipelineRuns.
+type TaskDiff struct {
+	Name  string        `json:"name"`
+	Left  TaskExecution `json:"left"`
+	Right TaskExecution `json:"right"`
+	// DurationDelta is how much longer the PipelineTask ran in the right
+	// PipelineRun, negative if it ran faster. It is nil unless the
+	// PipelineTask completed in both PipelineRuns.
+	DurationDelta *metav1.Duration `json:"durationDelta,omitempty"`
+}
+
+// TaskExecution describes how a PipelineTask ran in one PipelineRun.
+type TaskExecution struct {
+	// Outcome is one of TaskSucceeded, TaskFailed or TaskRunning.
+	Outcome string `json:"outcome"`
+	// Duration is nil until the TaskRun of the PipelineTask completes.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// ParamDiff is a parameter whose value differs between the PipelineRuns. The
+// value is nil in the PipelineRun which doesn't set the parameter.
+type ParamDiff struct {
+	Name  string                 `json:"name"`
+	Left  *v1beta1.ArrayOrString `json:"left,omitempty"`
+	Right *v1beta1.ArrayOrString `json:"right,omitempty"`
+}
+
+// Compare returns the difference between the executions of the left and right
+// PipelineRuns, read from their status. It returns an error if the PipelineRuns
+// reference different Pipelines.
+func Compare(left, right *v1beta1.PipelineRun) (*Diff, error) {
+	leftPipeline, rightPipeline := pipelineName(left), pipelineName(right)
+	if leftPipeline != rightPipeline {
+		return nil, fmt.Errorf("PipelineRun %s runs Pipeline %q but PipelineRun %s runs Pipeline %q", left.Name, leftPipeline, right.Name, rightPipeline)
+	}
+	d := &Diff{Pipeline: leftPipeline, Left: left.Name, Right: right.Name}
+
+	leftTasks, rightTasks := taskExecutions(left), taskExecutions(right)
+	taskNames := sets.NewString()
+	for name := range leftTasks {
+		taskNames.Insert(name)
+	}
+	for name := range rightTasks {
+		taskNames.Insert(name)
+	}
+	for _, name := range taskNames.List() {
+		l, inLeft := leftTasks[name]
+		r, inRight := rightTasks[name]
+		switch {
+		case !inLeft:
+			d.OnlyInRight = append(d.OnlyInRight, name)
+			continue
+		case !inRight:
+			d.OnlyInLeft = append(d.OnlyInLeft, name)
+			continue
+		}
+		td := TaskDiff{Name: name, Left: l, Right: r}
+		if l.Duration != nil && r.Duration != nil {
+			td.DurationDelta = &metav1.Duration{Duration: r.Duration.Duration - l.Duration.Duration}
+		}
+		d.Tasks = append(d.Tasks, td)
+	}
+
+	leftParams, rightParams := params(left), params(right)
+	paramNames := sets.NewString()
+	for name := range leftParams {
+		paramNames.Insert(name)
+	}
+	for name := range rightParams {
+		paramNames.Insert(name)
+	}
+	for _, name := range paramNames.List() {
+		l, r := leftParams[name], rightParams[name]
+		if !reflect.DeepEqual(l, r) {
+			d.Params = append(d.Params, ParamDiff{Name: name, Left: l, Right: r})
+		}
+	}
+	return d, nil
+}
+
+// JSON returns the machine-readable form of the diff.
+func (d *Diff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Write writes the human-readable form of the diff to w.
+func (d *Diff) Write(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("PipelineRun %s vs %s", d.Left, d.Right)
+	if d.Pipeline != "" {
+		ew.printf(" (Pipeline %s)", d.Pipeline)
+	}
+	ew.printf("\n")
+	if len(d.OnlyInLeft) > 0 {
+		ew.printf("\nTasks which only ran in %s:\n", d.Left)
+		for _, name := range d.OnlyInLeft {
+			ew.printf("  %s\n", name)
+		}
+	}
+	if len(d.OnlyInRight) > 0 {
+		ew.printf("\nTasks which only ran in %s:\n", d.Right)
+		for _, name := range d.OnlyInRight {
+			ew.printf("  %s\n", name)
+		}
+	}
+	if len(d.Tasks) > 0 {
+		ew.printf("\nTasks which ran in both:\n")
+		for _, t := range d.Tasks {
+			ew.printf("  %s: %s -> %s", t.Name, t.Left, t.Right)
+			if t.DurationDelta != nil {
+				ew.printf(" (%s)", formatDelta(t.DurationDelta.Duration))
+			}
+			ew.printf("\n")
+		}
+	}
+	if len(d.Params) > 0 {
+		ew.printf("\nParams:\n")
+		for _, p := range d.Params {
+			ew.printf("  %s: %s -> %s\n", p.Name, formatValue(p.Left), formatValue(p.Right))
+		}
+	}
+	return ew.err
+}
+
+// String returns the outcome of the execution followed by its duration, if known.
+func (e TaskExecution) String() string {
+	if e.Duration == nil {
+		return e.Outcome
+	}
+	return fmt.Sprintf("%s in %s", e.Outcome, e.Duration.Duration)
+}
+
+func pipelineName(pr *v1beta1.PipelineRun) string {
+	if pr.Spec.PipelineRef != nil {
+		return pr.Spec.PipelineRef.Name
+	}
+	return ""
+}
+
+// taskExecutions returns the executions of the PipelineTasks which ran in pr,
+// keyed by PipelineTask name. Skipped PipelineTasks didn't run so they have
+// no TaskRun status.
+func taskExecutions(pr *v1beta1.PipelineRun) map[string]TaskExecution {
+	executions := map[string]TaskExecution{}
+	for _, trs := range pr.Status.TaskRuns {
+		if trs == nil {
+			continue
+		}
+		e := TaskExecution{Outcome: TaskRunning}
+		if trs.Status != nil {
+			if c := trs.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+				switch c.Status {
+				case corev1.ConditionTrue:
+					e.Outcome = TaskSucceeded
+				case corev1.ConditionFalse:
+					e.Outcome = TaskFailed
+				}
+			}
+			if trs.Status.StartTime != nil && trs.Status.CompletionTime != nil {
+				e.Duration = &metav1.Duration{Duration: trs.Status.CompletionTime.Sub(trs.Status.StartTime.Time)}
+			}
+		}
+		executions[trs.PipelineTaskName] = e
+	}
+	return executions
+}
+
+func params(pr *v1beta1.PipelineRun) map[string]*v1beta1.ArrayOrString {
+	values := map[string]*v1beta1.ArrayOrString{}
+	for i := range pr.Spec.Params {
+		values[pr.Spec.Params[i].Name] = &pr.Spec.Params[i].Value
+	}
+	return values
+}
+
+func formatDelta(d time.Duration) string {
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+func formatValue(v *v1beta1.ArrayOrString) string {
+	if v == nil {
+		return "(unset)"
+	}
+	if v.Type == v1beta1.ParamTypeArray {
+		return fmt.Sprintf("%q", v.ArrayVal)
+	}
+	return fmt.Sprintf("%q", v.StringVal)
+}
+
+// errWriter keeps the first error returned by w so that Write can print
+// without checking the error of every line.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rundiff
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+var start = time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func taskRunStatus(pipelineTask string, status corev1.ConditionStatus, duration time.Duration) *v1beta1.PipelineRunTaskRunStatus {
+	trs := &v1beta1.TaskRunStatus{
+		Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{{
+			Type:   apis.ConditionSucceeded,
+			Status: status,
+		}}},
+		TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			StartTime: &metav1.Time{Time: start},
+		},
+	}
+	if status != corev1.ConditionUnknown {
+		trs.CompletionTime = &metav1.Time{Time: start.Add(duration)}
+	}
+	return &v1beta1.PipelineRunTaskRunStatus{PipelineTaskName: pipelineTask, Status: trs}
+}
+
+func value(s string, values ...string) *v1beta1.ArrayOrString {
+	v := v1beta1.NewArrayOrString(s, values...)
+	return &v
+}
+
+func pipelineRun(name string, params []v1beta1.Param, taskRuns ...*v1beta1.PipelineRunTaskRunStatus) *v1beta1.PipelineRun {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "build"},
+			Params:      params,
+		},
+	}
+	pr.Status.TaskRuns = map[string]*v1beta1.PipelineRunTaskRunStatus{}
+	for _, trs := range taskRuns {
+		pr.Status.TaskRuns[name+"-"+trs.PipelineTaskName] = trs
+	}
+	return pr
+}
+
+func TestCompare(t *testing.T) {
+	left := pipelineRun("build-1",
+		[]v1beta1.Param{
+			{Name: "revision", Value: v1beta1.NewArrayOrString("main")},
+			{Name: "flags", Value: v1beta1.NewArrayOrString("-v", "-race")},
+			{Name: "image", Value: v1beta1.NewArrayOrString("app")},
+		},
+		taskRunStatus("clone", corev1.ConditionTrue, time.Minute),
+		taskRunStatus("lint", corev1.ConditionTrue, 2*time.Minute),
+		taskRunStatus("unit-tests", corev1.ConditionTrue, 3*time.Minute),
+	)
+	right := pipelineRun("build-2",
+		[]v1beta1.Param{
+			{Name: "revision", Value: v1beta1.NewArrayOrString("v0.1")},
+			{Name: "flags", Value: v1beta1.NewArrayOrString("-v", "-race")},
+			{Name: "debug", Value: v1beta1.NewArrayOrString("true")},
+		},
+		taskRunStatus("clone", corev1.ConditionTrue, 90*time.Second),
+		taskRunStatus("unit-tests", corev1.ConditionFalse, time.Minute),
+		taskRunStatus("deploy", corev1.ConditionUnknown, 0),
+	)
+
+	got, err := Compare(left, right)
+	if err != nil {
+		t.Fatalf("Compare() = %v", err)
+	}
+	want := &Diff{
+		Pipeline:    "build",
+		Left:        "build-1",
+		Right:       "build-2",
+		OnlyInLeft:  []string{"lint"},
+		OnlyInRight: []string{"deploy"},
+		Tasks: []TaskDiff{{
+			Name:          "clone",
+			Left:          TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: time.Minute}},
+			Right:         TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: 90 * time.Second}},
+			DurationDelta: &metav1.Duration{Duration: 30 * time.Second},
+		}, {
+			Name:          "unit-tests",
+			Left:          TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: 3 * time.Minute}},
+			Right:         TaskExecution{Outcome: TaskFailed, Duration: &metav1.Duration{Duration: time.Minute}},
+			DurationDelta: &metav1.Duration{Duration: -2 * time.Minute},
+		}},
+		Params: []ParamDiff{{
+			Name:  "debug",
+			Right: value("true"),
+		}, {
+			Name: "image",
+			Left: value("app"),
+		}, {
+			Name:  "revision",
+			Left:  value("main"),
+			Right: value("v0.1"),
+		}},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Compare() %s", diff.PrintWantGot(d))
+	}
+
+	var out bytes.Buffer
+	if err := got.Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	wantText := `PipelineRun build-1 vs build-2 (Pipeline build)
+
+Tasks which only ran in build-1:
+  lint
+
+Tasks which only ran in build-2:
+  deploy
+
+Tasks which ran in both:
+  clone: Succeeded in 1m0s -> Succeeded in 1m30s (+30s)
+  unit-tests: Succeeded in 3m0s -> Failed in 1m0s (-2m0s)
+
+Params:
+  debug: (unset) -> "true"
+  image: "app" -> (unset)
+  revision: "main" -> "v0.1"
+`
+	if d := cmp.Diff(wantText, out.String()); d != "" {
+		t.Errorf("Write() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestCompare_Identical(t *testing.T) {
+	pr := pipelineRun("build-1",
+		[]v1beta1.Param{{Name: "revision", Value: v1beta1.NewArrayOrString("main")}},
+		taskRunStatus("clone", corev1.ConditionTrue, time.Minute),
+		taskRunStatus("deploy", corev1.ConditionUnknown, 0),
+	)
+	got, err := Compare(pr, pr)
+	if err != nil {
+		t.Fatalf("Compare() = %v", err)
+	}
+	want := &Diff{
+		Pipeline: "build",
+		Left:     "build-1",
+		Right:    "build-1",
+		Tasks: []TaskDiff{{
+			Name:          "clone",
+			Left:          TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: time.Minute}},
+			Right:         TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: time.Minute}},
+			DurationDelta: &metav1.Duration{},
+		}, {
+			Name:  "deploy",
+			Left:  TaskExecution{Outcome: TaskRunning},
+			Right: TaskExecution{Outcome: TaskRunning},
+		}},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Compare() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestCompare_DifferentPipelines(t *testing.T) {
+	left := pipelineRun("build-1", nil)
+	right := pipelineRun("release-1", nil)
+	right.Spec.PipelineRef.Name = "release"
+	if _, err := Compare(left, right); err == nil {
+		t.Error("Compare() of PipelineRuns of different Pipelines did not return an error")
+	}
+}
+
+func TestDiff_JSON(t *testing.T) {
+	d := &Diff{
+		Pipeline:   "build",
+		Left:       "build-1",
+		Right:      "build-2",
+		OnlyInLeft: []string{"lint"},
+		Tasks: []TaskDiff{{
+			Name:          "clone",
+			Left:          TaskExecution{Outcome: TaskSucceeded, Duration: &metav1.Duration{Duration: time.Minute}},
+			Right:         TaskExecution{Outcome: TaskFailed, Duration: &metav1.Duration{Duration: 90 * time.Second}},
+			DurationDelta: &metav1.Duration{Duration: 30 * time.Second},
+		}},
+		Params: []ParamDiff{{
+			Name:  "revision",
+			Left:  value("main"),
+			Right: value("v0.1"),
+		}},
+	}
+	got, err := d.JSON()
+	if err != nil {
+		t.Fatalf("JSON() = %v", err)
+	}
+	want := `{
+  "pipeline": "build",
+  "left": "build-1",
+  "right": "build-2",
+  "onlyInLeft": [
+    "lint"
+  ],
+  "tasks": [
+    {
+      "name": "clone",
+      "left": {
+        "outcome": "Succeeded",
+        "duration": "1m0s"
+      },
+      "right": {
+        "outcome": "Failed",
+        "duration": "1m30s"
+      },
+      "durationDelta": "30s"
+    }
+  ],
+  "params": [
+    {
+      "name": "revision",
+      "left": "main",
+      "right": "v0.1"
+    }
+  ]
+}`
+	if d := cmp.Diff(want, string(got)); d != "" {
+		t.Errorf("JSON() %s", diff.PrintWantGot(d))
+	}
+}
@@ -79,6 +79,16 @@ func ValidateVariableIsolated(name, value, prefix, locationName, path string, va
 	return nil
 }
 
+// ExtractVariableNames returns the names of the variables with the prefix which are
+// referenced by value, e.g. "foo" for "$(params.foo)" and "$(params.foo[*])".
+func ExtractVariableNames(value, prefix string) []string {
+	vs, _ := extractVariablesFromString(value, prefix)
+	for i, v := range vs {
+		vs[i] = strings.TrimSuffix(v, "[*]")
+	}
+	return vs
+}
+
 // Extract a the first full string expressions found (e.g "$(input.params.foo)"). Return
 // "" and false if nothing is found.
 func extractExpressionFromString(s, prefix string) (string, bool) {
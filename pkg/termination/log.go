@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package termination
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+const (
+	// MaxResultsLogLength is the upper bound of the results a container may
+	// write to its logs. The results end up in the status of the TaskRun, which
+	// must still fit in etcd.
+	MaxResultsLogLength = 1024 * 256
+)
+
+// WriteResultsLog writes the results to the file at path, and prints them to w
+// as a single line record enclosed by delimiter, so they can be read back from
+// the logs of the container with ParseResultsLog.
+func WriteResultsLog(w io.Writer, path, delimiter string, pro []v1alpha1.PipelineResourceResult) error {
+	jsonOutput, err := json.Marshal(pro)
+	if err != nil {
+		return err
+	}
+	if len(jsonOutput) > MaxResultsLogLength {
+		return ResultsLogLengthError(len(jsonOutput))
+	}
+	if err := ioutil.WriteFile(path, jsonOutput, 0666); err != nil {
+		return err
+	}
+	// The record starts on a new line in case the output of the step doesn't
+	// end with one.
+	_, err = fmt.Fprintf(w, "\n%s %s %s\n", delimiter, jsonOutput, delimiter)
+	return err
+}
+
+// ParseResultsLog returns the results of the last record enclosed by delimiter
+// found in the logs of a container. Lines that only look like a record, such as
+// the output of the step interleaved with it, are ignored. No results are
+// returned when the logs hold no record.
+func ParseResultsLog(logs []byte, delimiter string) []v1alpha1.PipelineResourceResult {
+	if delimiter == "" {
+		return nil
+	}
+	prefix, suffix := delimiter+" ", " "+delimiter
+	var r []v1alpha1.PipelineResourceResult
+	for _, line := range bytes.Split(logs, []byte("\n")) {
+		start := bytes.Index(line, []byte(prefix))
+		end := bytes.LastIndex(line, []byte(suffix))
+		if start < 0 || end < start+len(prefix) {
+			continue
+		}
+		var record []v1alpha1.PipelineResourceResult
+		if err := json.Unmarshal(line[start+len(prefix):end], &record); err != nil {
+			continue
+		}
+		r = record
+	}
+	return r
+}
+
+// ResultsLogLengthError indicates the results of a container are above
+// MaxResultsLogLength.
+type ResultsLogLengthError int
+
+func (e ResultsLogLengthError) Error() string {
+	return fmt.Sprintf("results of %d bytes are above max allowed size %d", int(e), MaxResultsLogLength)
+}
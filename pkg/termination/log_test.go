@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package termination
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+const delimiter = "a3a4c1a4-0d3b-4d4e-9d4a-6d7a0f1b2c3d"
+
+func TestWriteResultsLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".results.json")
+
+	results := []v1alpha1.PipelineResourceResult{{
+		Key:   "foo",
+		Value: strings.Repeat("a", MaxContainerTerminationMessageLength),
+	}}
+	var logs bytes.Buffer
+	logs.WriteString("output of the step without a trailing new line")
+	if err := WriteResultsLog(&logs, path, delimiter, results); err != nil {
+		t.Fatalf("WriteResultsLog: %v", err)
+	}
+
+	if d := cmp.Diff(results, ParseResultsLog(logs.Bytes(), delimiter)); d != "" {
+		t.Errorf("Diff in results read from the logs %s", diff.PrintWantGot(d))
+	}
+	fileContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading results file: %v", err)
+	}
+	if !strings.Contains(logs.String(), string(fileContents)) {
+		t.Errorf("Expected the results file %q to hold the record written to the logs", fileContents)
+	}
+}
+
+func TestWriteResultsLogAboveMax(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".results.json")
+
+	results := []v1alpha1.PipelineResourceResult{{
+		Key:   "foo",
+		Value: strings.Repeat("a", MaxResultsLogLength),
+	}}
+	var logs bytes.Buffer
+	err = WriteResultsLog(&logs, path, delimiter, results)
+	var lengthErr ResultsLogLengthError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("Expected a ResultsLogLengthError but got %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected nothing written to the logs but got %q", logs.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no results file but got %v", err)
+	}
+}
+
+func TestParseResultsLog(t *testing.T) {
+	record := delimiter + ` [{"key":"foo","value":"bar"}] ` + delimiter
+	for _, c := range []struct {
+		desc string
+		logs string
+		want []v1alpha1.PipelineResourceResult
+	}{{
+		desc: "no logs",
+	}, {
+		desc: "no record",
+		logs: "hello\nworld\n",
+	}, {
+		desc: "record after output",
+		logs: "hello\nworld\n" + record + "\n",
+		want: []v1alpha1.PipelineResourceResult{{Key: "foo", Value: "bar"}},
+	}, {
+		desc: "output interleaved on the line of the record",
+		logs: "background output " + record + " more output\n",
+		want: []v1alpha1.PipelineResourceResult{{Key: "foo", Value: "bar"}},
+	}, {
+		desc: "output after the record",
+		logs: record + "\nbackground output\n",
+		want: []v1alpha1.PipelineResourceResult{{Key: "foo", Value: "bar"}},
+	}, {
+		desc: "record with another delimiter",
+		logs: `other [{"key":"foo","value":"spoofed"}] other` + "\n",
+	}, {
+		desc: "invalid record is ignored",
+		logs: record + "\n" + delimiter + " not json " + delimiter + "\n",
+		want: []v1alpha1.PipelineResourceResult{{Key: "foo", Value: "bar"}},
+	}, {
+		desc: "last record wins",
+		logs: delimiter + ` [{"key":"foo","value":"first"}] ` + delimiter + "\n" + record + "\n",
+		want: []v1alpha1.PipelineResourceResult{{Key: "foo", Value: "bar"}},
+	}, {
+		desc: "unterminated record",
+		logs: delimiter + ` [{"key":"foo","value":"bar"}]` + "\n",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got := ParseResultsLog([]byte(c.logs), delimiter)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
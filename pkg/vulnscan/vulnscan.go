@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vulnscan scans the images of the Steps of TaskRuns for vulnerabilities
+// with Trivy, and summarizes the vulnerabilities found in VulnerabilitySummaries.
+package vulnscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// LabelKey labels the scan Pods and the VulnerabilitySummaries with the
+	// name of their TaskRun.
+	LabelKey = pipeline.GroupName + "/vulnerabilityScan"
+	// imagesAnnotationKey annotates a scan Pod with the images its containers
+	// scan, in the order of the containers.
+	imagesAnnotationKey = pipeline.GroupName + "/vulnerabilityScanImages"
+
+	// reportTemplate makes Trivy print the severity and ID of each vulnerability
+	// on a single line, which is read back from the logs of the container.
+	reportTemplate = `{{range .}}{{range .Vulnerabilities}}{{.Severity}}:{{.VulnerabilityID}} {{end}}{{end}}`
+)
+
+var reportEntry = regexp.MustCompile(`^(CRITICAL|HIGH|MEDIUM|LOW|UNKNOWN):(\S+)$`)
+
+// ScanPodName returns the name of the Pod scanning the images of the TaskRun
+// called taskRunName.
+func ScanPodName(taskRunName string) string {
+	return kmeta.ChildName(taskRunName, "-vulnscan")
+}
+
+// StepImages returns the images the Steps of pod ran, once each. The ID of
+// each image is read from the status of its containers.
+func StepImages(pod *corev1.Pod) []v1alpha1.ImageVulnerabilities {
+	imageIDs := map[string]string{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		imageIDs[cs.Name] = cs.ImageID
+	}
+	var images []v1alpha1.ImageVulnerabilities
+	seen := map[string]bool{}
+	for _, c := range pod.Spec.Containers {
+		if !podconvert.IsContainerStep(c.Name) {
+			continue
+		}
+		iv := v1alpha1.ImageVulnerabilities{Image: c.Image, ImageID: pullableImageID(imageIDs[c.Name])}
+		if key := iv.Image + "|" + iv.ImageID; !seen[key] {
+			seen[key] = true
+			images = append(images, iv)
+		}
+	}
+	return images
+}
+
+// pullableImageID strips the scheme the container runtime may prefix an image
+// ID with, and returns an empty string if the ID isn't a digest reference,
+// e.g. for images built on the node.
+func pullableImageID(imageID string) string {
+	imageID = strings.TrimPrefix(imageID, "docker-pullable://")
+	if !strings.Contains(imageID, "@") {
+		return ""
+	}
+	return imageID
+}
+
+// NewScanPod returns the Pod scanning images for tr, with a container running
+// trivyImage for each image. The Pod is owned by tr, which is reconciled
+// again when the scan completes.
+func NewScanPod(tr *v1beta1.TaskRun, images []v1alpha1.ImageVulnerabilities, trivyImage string) (*corev1.Pod, error) {
+	b, err := json.Marshal(images)
+	if err != nil {
+		return nil, err
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ScanPodName(tr.Name),
+			Namespace:       tr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{tr.GetOwnerReference()},
+			Labels:          map[string]string{LabelKey: tr.Name},
+			Annotations:     map[string]string{imagesAnnotationKey: string(b)},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+	for i, iv := range images {
+		target := iv.ImageID
+		if target == "" {
+			target = iv.Image
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+			Name:                     fmt.Sprintf("scan-%d", i),
+			Image:                    trivyImage,
+			Args:                     []string{"image", "--quiet", "--no-progress", "--format", "template", "--template", reportTemplate, target},
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		})
+	}
+	return pod, nil
+}
+
+// IsScanDone returns true if all the containers of the scan Pod have exited.
+func IsScanDone(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// NewSummary returns the VulnerabilitySummary of tr from its completed scan
+// Pod, reading the report of each container from its logs with logs.
+func NewSummary(tr *v1beta1.TaskRun, pod *corev1.Pod, logs func(container string) ([]byte, error)) (*v1alpha1.VulnerabilitySummary, error) {
+	var images []v1alpha1.ImageVulnerabilities
+	if err := json.Unmarshal([]byte(pod.Annotations[imagesAnnotationKey]), &images); err != nil {
+		return nil, fmt.Errorf("failed to read the images scanned by pod %q: %w", pod.Name, err)
+	}
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	for i := range images {
+		container := fmt.Sprintf("scan-%d", i)
+		terminated := statuses[container].State.Terminated
+		switch {
+		case terminated == nil:
+			images[i].Error = "the scan didn't run"
+		case terminated.ExitCode != 0:
+			images[i].Error = fmt.Sprintf("the scan failed with exit code %d: %s", terminated.ExitCode, strings.TrimSpace(terminated.Message))
+		default:
+			report, err := logs(container)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the report of container %s of pod %s: %w", container, pod.Name, err)
+			}
+			ParseReport(report, &images[i])
+		}
+	}
+	return &v1alpha1.VulnerabilitySummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            tr.Name,
+			Namespace:       tr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{tr.GetOwnerReference()},
+			Labels:          map[string]string{LabelKey: tr.Name},
+		},
+		Spec: v1alpha1.VulnerabilitySummarySpec{
+			TaskRunName: tr.Name,
+			Images:      images,
+		},
+	}, nil
+}
+
+// ParseReport counts the vulnerabilities of the report printed by Trivy into
+// iv by severity. A vulnerability is counted once for each affected package,
+// like Trivy does, but the IDs of the critical ones are only listed once.
+// Anything else Trivy logged is ignored.
+func ParseReport(report []byte, iv *v1alpha1.ImageVulnerabilities) {
+	critical := map[string]bool{}
+	for _, field := range strings.Fields(string(report)) {
+		m := reportEntry.FindStringSubmatch(field)
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "CRITICAL":
+			iv.Critical++
+			critical[m[2]] = true
+		case "HIGH":
+			iv.High++
+		case "MEDIUM":
+			iv.Medium++
+		case "LOW":
+			iv.Low++
+		default:
+			iv.Unknown++
+		}
+	}
+	iv.CriticalVulnerabilities = nil
+	for id := range critical {
+		iv.CriticalVulnerabilities = append(iv.CriticalVulnerabilities, id)
+	}
+	sort.Strings(iv.CriticalVulnerabilities)
+}
+
+// CriticalVulnerabilities returns the IDs of the critical vulnerabilities found
+// in image by the most recent of summaries which scanned it. An image referenced
+// by digest matches the scans of the images the Steps ran, and an image
+// referenced by tag the scans of the Steps using the same tag.
+func CriticalVulnerabilities(summaries []v1alpha1.VulnerabilitySummary, image string) []string {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil
+	}
+	var (
+		latest *metav1.Time
+		found  []string
+	)
+	for i := range summaries {
+		s := &summaries[i]
+		for _, iv := range s.Spec.Images {
+			if iv.Error != "" || !sameImage(ref, iv) {
+				continue
+			}
+			if latest == nil || latest.Before(&s.CreationTimestamp) {
+				latest = &s.CreationTimestamp
+				found = iv.CriticalVulnerabilities
+			}
+		}
+	}
+	return found
+}
+
+func sameImage(ref name.Reference, iv v1alpha1.ImageVulnerabilities) bool {
+	if digest, ok := ref.(name.Digest); ok {
+		scanned, err := name.NewDigest(iv.ImageID)
+		return err == nil && scanned.DigestStr() == digest.DigestStr() && scanned.Context().Name() == digest.Context().Name()
+	}
+	scanned, err := name.ParseReference(iv.Image)
+	return err == nil && scanned.Name() == ref.Name()
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnscan
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ubuntuID = "docker.io/library/ubuntu@sha256:cf31af331f38d1d7158470e095b132acd126a7180a54f263d386da88eb681d93"
+	alpineID = "docker.io/library/alpine@sha256:826f70e0ac33e99a72cf20fb0571245a8fee52d68cb26d8bc58e53bfa65dcdfa"
+)
+
+var taskRun = &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"}}
+
+func TestStepImages(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "step-compile", Image: "ubuntu"},
+				{Name: "step-test", Image: "ubuntu"},
+				{Name: "step-local", Image: "local/tool"},
+				{Name: "sidecar-registry", Image: "registry:2"},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-compile", ImageID: "docker-pullable://" + ubuntuID},
+				{Name: "step-test", ImageID: ubuntuID},
+				{Name: "step-local", ImageID: "sha256:4e5021d210f65ebe915670c7089120120bc0a303b90208592851708c1b8c04bd"},
+				{Name: "sidecar-registry", ImageID: "docker-pullable://docker.io/library/registry@sha256:abc"},
+			},
+		},
+	}
+	want := []v1alpha1.ImageVulnerabilities{
+		{Image: "ubuntu", ImageID: ubuntuID},
+		{Image: "local/tool"},
+	}
+	if d := cmp.Diff(want, StepImages(pod)); d != "" {
+		t.Errorf("StepImages() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestNewScanPod(t *testing.T) {
+	images := []v1alpha1.ImageVulnerabilities{
+		{Image: "ubuntu", ImageID: ubuntuID},
+		{Image: "local/tool"},
+	}
+	pod, err := NewScanPod(taskRun, images, "aquasec/trivy")
+	if err != nil {
+		t.Fatalf("NewScanPod() = %v", err)
+	}
+	if pod.Name != "build-vulnscan" || pod.Namespace != "foo" || pod.Labels[LabelKey] != "build" {
+		t.Errorf("NewScanPod() returned pod %s/%s with labels %v", pod.Namespace, pod.Name, pod.Labels)
+	}
+	if len(pod.OwnerReferences) != 1 || pod.OwnerReferences[0].Name != "build" {
+		t.Errorf("NewScanPod() returned pod owned by %v, want taskrun build", pod.OwnerReferences)
+	}
+	var targets []string
+	for _, c := range pod.Spec.Containers {
+		if c.Image != "aquasec/trivy" {
+			t.Errorf("container %s runs image %s, want aquasec/trivy", c.Name, c.Image)
+		}
+		targets = append(targets, c.Name+" "+c.Args[len(c.Args)-1])
+	}
+	want := []string{"scan-0 " + ubuntuID, "scan-1 local/tool"}
+	if d := cmp.Diff(want, targets); d != "" {
+		t.Errorf("NewScanPod() scanned images %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	report := "2021-04-01T10:00:00.000Z\tWARN\tThis OS version is not on the EOL list: ubuntu 21.04\n" +
+		"CRITICAL:CVE-2021-3156 HIGH:CVE-2021-3177 CRITICAL:CVE-2021-3156 LOW:CVE-2020-1234 " +
+		"CRITICAL:CVE-2020-8286 MEDIUM:GHSA-xxxx UNKNOWN:CVE-2021-0001 HIGH:CVE-2021-3178 "
+	var got v1alpha1.ImageVulnerabilities
+	ParseReport([]byte(report), &got)
+	want := v1alpha1.ImageVulnerabilities{
+		Critical:                3,
+		High:                    2,
+		Medium:                  1,
+		Low:                     1,
+		Unknown:                 1,
+		CriticalVulnerabilities: []string{"CVE-2020-8286", "CVE-2021-3156"},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ParseReport() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestNewSummary(t *testing.T) {
+	pod, err := NewScanPod(taskRun, []v1alpha1.ImageVulnerabilities{
+		{Image: "ubuntu", ImageID: ubuntuID},
+		{Image: "alpine", ImageID: alpineID},
+		{Image: "private/tool"},
+	}, "aquasec/trivy")
+	if err != nil {
+		t.Fatalf("NewScanPod() = %v", err)
+	}
+	pod.Status = corev1.PodStatus{
+		Phase: corev1.PodFailed,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "scan-0",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+		}, {
+			Name:  "scan-1",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+		}, {
+			Name:  "scan-2",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "UNAUTHORIZED\n"}},
+		}},
+	}
+	logs := map[string]string{
+		"scan-0": "CRITICAL:CVE-2021-3156 HIGH:CVE-2021-3177 ",
+		"scan-1": "",
+	}
+	got, err := NewSummary(taskRun, pod, func(container string) ([]byte, error) {
+		l, ok := logs[container]
+		if !ok {
+			return nil, errors.New("no logs")
+		}
+		return []byte(l), nil
+	})
+	if err != nil {
+		t.Fatalf("NewSummary() = %v", err)
+	}
+	want := &v1alpha1.VulnerabilitySummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "build",
+			Namespace:       "foo",
+			OwnerReferences: []metav1.OwnerReference{taskRun.GetOwnerReference()},
+			Labels:          map[string]string{LabelKey: "build"},
+		},
+		Spec: v1alpha1.VulnerabilitySummarySpec{
+			TaskRunName: "build",
+			Images: []v1alpha1.ImageVulnerabilities{{
+				Image:                   "ubuntu",
+				ImageID:                 ubuntuID,
+				Critical:                1,
+				High:                    1,
+				CriticalVulnerabilities: []string{"CVE-2021-3156"},
+			}, {
+				Image:   "alpine",
+				ImageID: alpineID,
+			}, {
+				Image: "private/tool",
+				Error: "the scan failed with exit code 1: UNAUTHORIZED",
+			}},
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("NewSummary() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestCriticalVulnerabilities(t *testing.T) {
+	summary := func(created time.Time, images ...v1alpha1.ImageVulnerabilities) v1alpha1.VulnerabilitySummary {
+		return v1alpha1.VulnerabilitySummary{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+			Spec:       v1alpha1.VulnerabilitySummarySpec{Images: images},
+		}
+	}
+	now := time.Date(2021, time.April, 1, 10, 0, 0, 0, time.UTC)
+	summaries := []v1alpha1.VulnerabilitySummary{
+		summary(now.Add(-time.Hour),
+			v1alpha1.ImageVulnerabilities{Image: "ubuntu", ImageID: ubuntuID, Critical: 1, CriticalVulnerabilities: []string{"CVE-2021-3156"}},
+			v1alpha1.ImageVulnerabilities{Image: "alpine:3.13", ImageID: alpineID, Critical: 1, CriticalVulnerabilities: []string{"CVE-2021-36159"}},
+		),
+		// alpine:3.13 was fixed since, and the most recent scan wins.
+		summary(now, v1alpha1.ImageVulnerabilities{Image: "docker.io/library/alpine:3.13", ImageID: "docker.io/library/alpine@sha256:def"}),
+		summary(now.Add(time.Hour), v1alpha1.ImageVulnerabilities{Image: "busybox", Error: "the scan failed with exit code 1"}),
+	}
+	for _, tc := range []struct {
+		image string
+		want  []string
+	}{{
+		image: "ubuntu",
+		want:  []string{"CVE-2021-3156"},
+	}, {
+		image: "index.docker.io/library/ubuntu:latest",
+		want:  []string{"CVE-2021-3156"},
+	}, {
+		image: "ubuntu@sha256:cf31af331f38d1d7158470e095b132acd126a7180a54f263d386da88eb681d93",
+		want:  []string{"CVE-2021-3156"},
+	}, {
+		image: "ubuntu:20.04",
+	}, {
+		image: "alpine:3.13",
+	}, {
+		image: alpineID,
+		want:  []string{"CVE-2021-36159"},
+	}, {
+		image: "busybox",
+	}, {
+		image: "$(params.image)",
+	}} {
+		t.Run(tc.image, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, CriticalVulnerabilities(summaries, tc.image)); d != "" {
+				t.Errorf("CriticalVulnerabilities() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -66,6 +66,8 @@ func GetVolumes(wb []v1beta1.WorkspaceBinding) map[string]corev1.Volume {
 		case w.Secret != nil:
 			s := *w.Secret
 			v.setVolumeSource(w.Name, name, corev1.VolumeSource{Secret: &s})
+		case w.CSI != nil:
+			v.setVolumeSource(w.Name, name, corev1.VolumeSource{CSI: w.CSI.DeepCopy()})
 		}
 	}
 	return v
@@ -106,11 +108,15 @@ func Apply(ts v1beta1.TaskSpec, wb []v1beta1.WorkspaceBinding) (*v1beta1.TaskSpe
 		// Get the volume we should be using for this binding
 		vv := v[wb[i].Name]
 
+		// CSI volumes hold secrets, like those of a secrets store, which
+		// steps are not meant to write to.
+		readOnly := w.ReadOnly || wb[i].ReadOnly || wb[i].CSI != nil
+
 		ts.StepTemplate.VolumeMounts = append(ts.StepTemplate.VolumeMounts, corev1.VolumeMount{
 			Name:      vv.Name,
 			MountPath: w.GetMountPath(),
 			SubPath:   wb[i].SubPath,
-			ReadOnly:  w.ReadOnly,
+			ReadOnly:  readOnly,
 		})
 
 		// Only add this volume if it hasn't already been added
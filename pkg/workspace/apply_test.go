@@ -12,6 +12,7 @@ import (
 )
 
 func TestGetVolumes(t *testing.T) {
+	readOnly := true
 	names.TestingSeed()
 	for _, tc := range []struct {
 		name            string
@@ -183,6 +184,28 @@ func TestGetVolumes(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "binding a single workspace with csi",
+		workspaces: []v1beta1.WorkspaceBinding{{
+			Name: "custom",
+			CSI: &corev1.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+			},
+		}},
+		expectedVolumes: map[string]corev1.Volume{
+			"custom": {
+				Name: "ws-twkr2",
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						ReadOnly:         &readOnly,
+						VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+					},
+				},
+			},
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			v := workspace.GetVolumes(tc.workspaces)
@@ -194,6 +217,7 @@ func TestGetVolumes(t *testing.T) {
 }
 
 func TestApply(t *testing.T) {
+	readOnly := true
 	names.TestingSeed()
 	for _, tc := range []struct {
 		name             string
@@ -509,6 +533,79 @@ func TestApply(t *testing.T) {
 				ReadOnly:  true,
 			}},
 		},
+	}, {
+		name: "readOnly binding marks volume mount readOnly",
+		ts: v1beta1.TaskSpec{
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name:      "custom",
+				MountPath: "/my/fancy/mount/path",
+			}},
+		},
+		workspaces: []v1beta1.WorkspaceBinding{{
+			Name:     "custom",
+			ReadOnly: true,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: "mypvc",
+			},
+		}},
+		expectedTaskSpec: v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "ws-mnq6l",
+					MountPath: "/my/fancy/mount/path",
+					ReadOnly:  true,
+				}},
+			},
+			Volumes: []corev1.Volume{{
+				Name: "ws-mnq6l",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "mypvc",
+					},
+				},
+			}},
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name:      "custom",
+				MountPath: "/my/fancy/mount/path",
+			}},
+		},
+	}, {
+		name: "binding a single workspace with csi mounts it readOnly",
+		ts: v1beta1.TaskSpec{
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name: "custom",
+			}},
+		},
+		workspaces: []v1beta1.WorkspaceBinding{{
+			Name: "custom",
+			CSI: &corev1.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+			},
+		}},
+		expectedTaskSpec: v1beta1.TaskSpec{
+			StepTemplate: &corev1.Container{
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "ws-hvpvf",
+					MountPath: "/workspace/custom",
+					ReadOnly:  true,
+				}},
+			},
+			Volumes: []corev1.Volume{{
+				Name: "ws-hvpvf",
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						ReadOnly:         &readOnly,
+						VolumeAttributes: map[string]string{"secretProviderClass": "vault-database"},
+					},
+				},
+			}},
+			Workspaces: []v1beta1.WorkspaceDeclaration{{
+				Name: "custom",
+			}},
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			ts, err := workspace.Apply(tc.ts, tc.workspaces)
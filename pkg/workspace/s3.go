@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AnnotationS3SourcePrefix is the prefix of the TaskRun annotations giving an S3 location
+	// to download into the workspace named by the rest of the key, before the Steps run.
+	AnnotationS3SourcePrefix = "pipeline.tekton.dev/s3-source."
+
+	// AnnotationS3SinkPrefix is the prefix of the TaskRun annotations giving an S3 location
+	// to upload the workspace named by the rest of the key to, after the Steps succeeded.
+	AnnotationS3SinkPrefix = "pipeline.tekton.dev/s3-sink."
+
+	// AnnotationS3Credentials is the TaskRun annotation naming the workspace holding the
+	// AWS "credentials" and "config" files used to access S3.
+	AnnotationS3Credentials = "pipeline.tekton.dev/s3-credentials"
+
+	s3Scheme = "s3://"
+)
+
+// S3Transfer is a copy between a workspace and an S3 location.
+type S3Transfer struct {
+	// Workspace is the name of the workspace declared by the Task.
+	Workspace string
+	// Location is the s3://bucket/prefix URL to copy from or to.
+	Location string
+}
+
+// S3Transfers holds the copies from and to S3 requested by the annotations of a TaskRun.
+type S3Transfers struct {
+	// Sources are downloaded into their workspace before the Steps run.
+	Sources []S3Transfer
+	// Sinks are uploaded from their workspace after the Steps succeeded.
+	Sinks []S3Transfer
+	// CredentialsWorkspace is the name of the workspace holding the AWS credentials, if any.
+	CredentialsWorkspace string
+}
+
+// ParseS3Annotations returns the S3 transfers requested by annotations, sorted by workspace
+// name. It returns nil if there are none, and an error if a location is not an s3:// URL.
+func ParseS3Annotations(annotations map[string]string) (*S3Transfers, error) {
+	t := &S3Transfers{}
+	for k, v := range annotations {
+		var transfers *[]S3Transfer
+		var workspace string
+		switch {
+		case strings.HasPrefix(k, AnnotationS3SourcePrefix):
+			transfers, workspace = &t.Sources, strings.TrimPrefix(k, AnnotationS3SourcePrefix)
+		case strings.HasPrefix(k, AnnotationS3SinkPrefix):
+			transfers, workspace = &t.Sinks, strings.TrimPrefix(k, AnnotationS3SinkPrefix)
+		case k == AnnotationS3Credentials:
+			t.CredentialsWorkspace = v
+			continue
+		default:
+			continue
+		}
+		if workspace == "" {
+			return nil, fmt.Errorf("annotation %q does not name a workspace", k)
+		}
+		if !strings.HasPrefix(v, s3Scheme) || len(v) == len(s3Scheme) {
+			return nil, fmt.Errorf("annotation %q must be an s3://bucket/prefix location but is %q", k, v)
+		}
+		*transfers = append(*transfers, S3Transfer{Workspace: workspace, Location: v})
+	}
+	if len(t.Sources) == 0 && len(t.Sinks) == 0 {
+		if t.CredentialsWorkspace != "" {
+			return nil, fmt.Errorf("annotation %q is set but no workspace is copied from or to S3", AnnotationS3Credentials)
+		}
+		return nil, nil
+	}
+	sort.Slice(t.Sources, func(i, j int) bool { return t.Sources[i].Workspace < t.Sources[j].Workspace })
+	sort.Slice(t.Sinks, func(i, j int) bool { return t.Sinks[i].Workspace < t.Sinks[j].Workspace })
+	return t, nil
+}
+
+// Validate returns an error if the workspaces of t aren't declared in w, or if a source
+// would be downloaded into a read-only workspace.
+func (t *S3Transfers) Validate(w []v1beta1.WorkspaceDeclaration, wb []v1beta1.WorkspaceBinding) error {
+	if t == nil {
+		return nil
+	}
+	for _, s := range t.Sources {
+		decl, err := getDeclaredWorkspace(s.Workspace, w)
+		if err != nil {
+			return fmt.Errorf("cannot download %s into undeclared workspace %q", s.Location, s.Workspace)
+		}
+		if decl.ReadOnly || isReadOnlyBinding(s.Workspace, wb) {
+			return fmt.Errorf("cannot download %s into read-only workspace %q", s.Location, s.Workspace)
+		}
+	}
+	for _, s := range t.Sinks {
+		if _, err := getDeclaredWorkspace(s.Workspace, w); err != nil {
+			return fmt.Errorf("cannot upload undeclared workspace %q to %s", s.Workspace, s.Location)
+		}
+	}
+	if t.CredentialsWorkspace != "" {
+		if _, err := getDeclaredWorkspace(t.CredentialsWorkspace, w); err != nil {
+			return fmt.Errorf("S3 credentials workspace %q is not declared", t.CredentialsWorkspace)
+		}
+	}
+	return nil
+}
+
+func isReadOnlyBinding(name string, wb []v1beta1.WorkspaceBinding) bool {
+	for _, b := range wb {
+		if b.Name == name {
+			return b.ReadOnly
+		}
+	}
+	return false
+}
+
+// ApplyS3Transfers prepends a Step downloading each source of t into its workspace to the
+// Steps of ts, and appends a Step uploading each sink of t from its workspace. The Steps
+// use image, which must contain the aws CLI, and is only needed when t has transfers.
+func ApplyS3Transfers(ts v1beta1.TaskSpec, t *S3Transfers, image string) (*v1beta1.TaskSpec, error) {
+	if t == nil {
+		return &ts, nil
+	}
+	if image == "" {
+		return nil, fmt.Errorf("cannot copy workspaces from and to S3: the -awscli-image flag of the controller isn't set")
+	}
+	if err := t.Validate(ts.Workspaces, nil); err != nil {
+		return nil, err
+	}
+
+	var env []corev1.EnvVar
+	if t.CredentialsWorkspace != "" {
+		w, _ := getDeclaredWorkspace(t.CredentialsWorkspace, ts.Workspaces)
+		env = []corev1.EnvVar{{
+			Name:  "AWS_SHARED_CREDENTIALS_FILE",
+			Value: filepath.Join(w.GetMountPath(), "credentials"),
+		}, {
+			Name:  "AWS_CONFIG_FILE",
+			Value: filepath.Join(w.GetMountPath(), "config"),
+		}}
+	}
+	s3SyncStep := func(name, from, to string) v1beta1.Step {
+		return v1beta1.Step{Container: corev1.Container{
+			Name:    names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(name),
+			Image:   image,
+			Command: []string{"aws"},
+			Args:    []string{"s3", "sync", from, to},
+			Env:     env,
+		}}
+	}
+
+	var downloads, uploads []v1beta1.Step
+	for _, s := range t.Sources {
+		w, _ := getDeclaredWorkspace(s.Workspace, ts.Workspaces)
+		downloads = append(downloads, s3SyncStep(fmt.Sprintf("s3-download-%s", s.Workspace), s.Location, w.GetMountPath()))
+	}
+	for _, s := range t.Sinks {
+		w, _ := getDeclaredWorkspace(s.Workspace, ts.Workspaces)
+		uploads = append(uploads, s3SyncStep(fmt.Sprintf("s3-upload-%s", s.Workspace), w.GetMountPath(), s.Location))
+	}
+	ts.Steps = append(append(downloads, ts.Steps...), uploads...)
+	return &ts, nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/workspace"
+	"github.com/tektoncd/pipeline/test/diff"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseS3Annotations(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		expected    *workspace.S3Transfers
+	}{{
+		name:        "no annotations",
+		annotations: nil,
+		expected:    nil,
+	}, {
+		name:        "unrelated annotations",
+		annotations: map[string]string{"foo": "bar"},
+		expected:    nil,
+	}, {
+		name: "sources and sinks sorted by workspace",
+		annotations: map[string]string{
+			"pipeline.tekton.dev/s3-source.source-b": "s3://bucket/b",
+			"pipeline.tekton.dev/s3-source.source-a": "s3://bucket/a",
+			"pipeline.tekton.dev/s3-sink.output":     "s3://bucket/output",
+			"pipeline.tekton.dev/s3-credentials":     "aws",
+		},
+		expected: &workspace.S3Transfers{
+			Sources: []workspace.S3Transfer{{
+				Workspace: "source-a",
+				Location:  "s3://bucket/a",
+			}, {
+				Workspace: "source-b",
+				Location:  "s3://bucket/b",
+			}},
+			Sinks: []workspace.S3Transfer{{
+				Workspace: "output",
+				Location:  "s3://bucket/output",
+			}},
+			CredentialsWorkspace: "aws",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			transfers, err := workspace.ParseS3Annotations(tc.annotations)
+			if err != nil {
+				t.Fatalf("Did not expect error but got %v", err)
+			}
+			if d := cmp.Diff(tc.expected, transfers); d != "" {
+				t.Errorf("Didn't get expected S3 transfers %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestParseS3Annotations_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+	}{{
+		name:        "not an s3 location",
+		annotations: map[string]string{"pipeline.tekton.dev/s3-source.source": "gs://bucket/source"},
+	}, {
+		name:        "no bucket",
+		annotations: map[string]string{"pipeline.tekton.dev/s3-sink.output": "s3://"},
+	}, {
+		name:        "no workspace",
+		annotations: map[string]string{"pipeline.tekton.dev/s3-source.": "s3://bucket/source"},
+	}, {
+		name:        "credentials without transfers",
+		annotations: map[string]string{"pipeline.tekton.dev/s3-credentials": "aws"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := workspace.ParseS3Annotations(tc.annotations); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestS3TransfersValidate_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		transfers  *workspace.S3Transfers
+		workspaces []v1beta1.WorkspaceDeclaration
+		bindings   []v1beta1.WorkspaceBinding
+	}{{
+		name: "undeclared source workspace",
+		transfers: &workspace.S3Transfers{
+			Sources: []workspace.S3Transfer{{Workspace: "source", Location: "s3://bucket/source"}},
+		},
+	}, {
+		name: "undeclared sink workspace",
+		transfers: &workspace.S3Transfers{
+			Sinks: []workspace.S3Transfer{{Workspace: "output", Location: "s3://bucket/output"}},
+		},
+	}, {
+		name: "undeclared credentials workspace",
+		transfers: &workspace.S3Transfers{
+			Sinks:                []workspace.S3Transfer{{Workspace: "output", Location: "s3://bucket/output"}},
+			CredentialsWorkspace: "aws",
+		},
+		workspaces: []v1beta1.WorkspaceDeclaration{{Name: "output"}},
+	}, {
+		name: "source workspace declared read-only",
+		transfers: &workspace.S3Transfers{
+			Sources: []workspace.S3Transfer{{Workspace: "source", Location: "s3://bucket/source"}},
+		},
+		workspaces: []v1beta1.WorkspaceDeclaration{{Name: "source", ReadOnly: true}},
+	}, {
+		name: "source workspace bound read-only",
+		transfers: &workspace.S3Transfers{
+			Sources: []workspace.S3Transfer{{Workspace: "source", Location: "s3://bucket/source"}},
+		},
+		workspaces: []v1beta1.WorkspaceDeclaration{{Name: "source"}},
+		bindings: []v1beta1.WorkspaceBinding{{
+			Name:     "source",
+			ReadOnly: true,
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.transfers.Validate(tc.workspaces, tc.bindings); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestApplyS3Transfers(t *testing.T) {
+	names.TestingSeed()
+	userStep := v1beta1.Step{Container: corev1.Container{Name: "build", Image: "builder"}}
+	ts := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{userStep},
+		Workspaces: []v1beta1.WorkspaceDeclaration{{
+			Name: "source",
+		}, {
+			Name:      "output",
+			MountPath: "/output",
+		}, {
+			Name:     "aws",
+			ReadOnly: true,
+		}},
+	}
+	transfers := &workspace.S3Transfers{
+		Sources:              []workspace.S3Transfer{{Workspace: "source", Location: "s3://bucket/source"}},
+		Sinks:                []workspace.S3Transfer{{Workspace: "output", Location: "s3://bucket/output"}},
+		CredentialsWorkspace: "aws",
+	}
+	env := []corev1.EnvVar{{
+		Name:  "AWS_SHARED_CREDENTIALS_FILE",
+		Value: "/workspace/aws/credentials",
+	}, {
+		Name:  "AWS_CONFIG_FILE",
+		Value: "/workspace/aws/config",
+	}}
+	expectedSteps := []v1beta1.Step{{Container: corev1.Container{
+		Name:    "s3-download-source-9l9zj",
+		Image:   "amazon/aws-cli",
+		Command: []string{"aws"},
+		Args:    []string{"s3", "sync", "s3://bucket/source", "/workspace/source"},
+		Env:     env,
+	}}, userStep, {Container: corev1.Container{
+		Name:    "s3-upload-output-mz4c7",
+		Image:   "amazon/aws-cli",
+		Command: []string{"aws"},
+		Args:    []string{"s3", "sync", "/output", "s3://bucket/output"},
+		Env:     env,
+	}}}
+
+	got, err := workspace.ApplyS3Transfers(ts, transfers, "amazon/aws-cli")
+	if err != nil {
+		t.Fatalf("Did not expect error but got %v", err)
+	}
+	if d := cmp.Diff(expectedSteps, got.Steps); d != "" {
+		t.Errorf("Didn't get expected Steps %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(ts.Workspaces, got.Workspaces); d != "" {
+		t.Errorf("Didn't expect workspaces to change %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestApplyS3Transfers_None(t *testing.T) {
+	ts := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{Name: "build", Image: "builder"}}},
+	}
+	got, err := workspace.ApplyS3Transfers(ts, nil, "amazon/aws-cli")
+	if err != nil {
+		t.Fatalf("Did not expect error but got %v", err)
+	}
+	if d := cmp.Diff(ts, *got); d != "" {
+		t.Errorf("Didn't expect TaskSpec to change %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestApplyS3Transfers_NoImage(t *testing.T) {
+	ts := v1beta1.TaskSpec{
+		Steps:      []v1beta1.Step{{Container: corev1.Container{Name: "build", Image: "builder"}}},
+		Workspaces: []v1beta1.WorkspaceDeclaration{{Name: "source"}},
+	}
+	transfers := &workspace.S3Transfers{
+		Sources: []workspace.S3Transfer{{Workspace: "source", Location: "s3://bucket/source"}},
+	}
+	if _, err := workspace.ApplyS3Transfers(ts, transfers, ""); err == nil {
+		t.Error("Expected an error without the aws CLI image")
+	}
+	// The image is only needed to copy workspaces from and to S3.
+	if _, err := workspace.ApplyS3Transfers(ts, nil, ""); err != nil {
+		t.Errorf("Did not expect error but got %v", err)
+	}
+}
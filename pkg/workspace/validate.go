@@ -24,16 +24,23 @@ import (
 	"github.com/tektoncd/pipeline/pkg/list"
 )
 
+// ReasonReadOnlySource is the reason of the warning event emitted when a
+// workspace the Task doesn't declare readOnly is bound to a read-only source.
+const ReasonReadOnlySource = "WorkspaceReadOnlySource"
+
 // ValidateBindings will return an error if the bound workspaces in wb don't satisfy the declared
 // workspaces in w.
-func ValidateBindings(w []v1beta1.WorkspaceDeclaration, wb []v1beta1.WorkspaceBinding) error {
+func ValidateBindings(ctx context.Context, w []v1beta1.WorkspaceDeclaration, wb []v1beta1.WorkspaceBinding) error {
 	// This will also be validated at webhook time but in case the webhook isn't invoked for some
 	// reason we'll invoke the same validation here.
 	for _, b := range wb {
-		if err := b.Validate(context.Background()); err != nil {
+		if err := b.Validate(ctx); err != nil {
 			return fmt.Errorf("binding %q is invalid: %v", b.Name, err)
 		}
 	}
+	if err := v1beta1.ValidateWorkspaceBindingCollisions(wb); err != nil {
+		return err
+	}
 
 	declNames := make([]string, len(w))
 	for i := range w {
@@ -48,3 +55,21 @@ func ValidateBindings(w []v1beta1.WorkspaceDeclaration, wb []v1beta1.WorkspaceBi
 	}
 	return nil
 }
+
+// ReadOnlySourceBindings returns the names of the bindings in wb that bind a
+// ConfigMap, Secret or CSI volume, which are always mounted read-only, to a workspace
+// that w doesn't declare readOnly: a Task writing to it would fail. Bindings
+// marked readOnly themselves are expected to be read-only and are ignored.
+func ReadOnlySourceBindings(w []v1beta1.WorkspaceDeclaration, wb []v1beta1.WorkspaceBinding) []string {
+	readOnly := make(map[string]bool, len(w))
+	for _, d := range w {
+		readOnly[d.Name] = d.ReadOnly
+	}
+	var names []string
+	for _, b := range wb {
+		if (b.ConfigMap != nil || b.Secret != nil || b.CSI != nil) && !b.ReadOnly && !readOnly[b.Name] {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
@@ -17,9 +17,13 @@ limitations under the License.
 package workspace
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -58,7 +62,7 @@ func TestValidateBindingsValid(t *testing.T) {
 		}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			if err := ValidateBindings(tc.declarations, tc.bindings); err != nil {
+			if err := ValidateBindings(context.Background(), tc.declarations, tc.bindings); err != nil {
 				t.Errorf("didnt expect error for valid bindings but got: %v", err)
 			}
 		})
@@ -123,11 +127,57 @@ func TestValidateBindingsInvalid(t *testing.T) {
 			Name:                  "beth",
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{},
 		}},
+	}, {
+		name: "Provided the same pvc and subPath twice",
+		declarations: []v1alpha1.WorkspaceDeclaration{{
+			Name: "beth",
+		}, {
+			Name: "kate",
+		}},
+		bindings: []v1alpha1.WorkspaceBinding{{
+			Name:                  "beth",
+			SubPath:               "src",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+		}, {
+			Name:                  "kate",
+			SubPath:               "src",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pool-party"},
+		}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
-			if err := ValidateBindings(tc.declarations, tc.bindings); err == nil {
+			if err := ValidateBindings(context.Background(), tc.declarations, tc.bindings); err == nil {
 				t.Errorf("expected error for invalid bindings but didn't get any!")
 			}
 		})
 	}
 }
+
+func TestReadOnlySourceBindings(t *testing.T) {
+	declarations := []v1beta1.WorkspaceDeclaration{
+		{Name: "config"},
+		{Name: "credentials", ReadOnly: true},
+		{Name: "source"},
+		{Name: "settings"},
+		{Name: "vault"},
+	}
+	bindings := []v1beta1.WorkspaceBinding{{
+		Name:      "config",
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "myconfig"}},
+	}, {
+		Name:   "credentials",
+		Secret: &corev1.SecretVolumeSource{SecretName: "mysecret"},
+	}, {
+		Name:     "source",
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}, {
+		Name:     "settings",
+		ReadOnly: true,
+		Secret:   &corev1.SecretVolumeSource{SecretName: "mysettings"},
+	}, {
+		Name: "vault",
+		CSI:  &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io"},
+	}}
+	if d := cmp.Diff([]string{"config", "vault"}, ReadOnlySourceBindings(declarations, bindings)); d != "" {
+		t.Errorf("ReadOnlySourceBindings() %s", diff.PrintWantGot(d))
+	}
+}
@@ -49,6 +49,7 @@ const (
 // TestStorageBucketPipelineRun is an integration test that will verify a pipeline
 // can use a bucket for temporary storage of artifacts shared between tasks
 func TestStorageBucketPipelineRun(t *testing.T) {
+	requireCapabilities(t, gcsCapability)
 	configFilePath := os.Getenv("GCP_SERVICE_ACCOUNT_KEY_PATH")
 	if configFilePath == "" {
 		t.Skip("GCP_SERVICE_ACCOUNT_KEY_PATH variable is not set.")
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	// gcsCapability is the access to Google Cloud Storage buckets.
+	gcsCapability = "gcs"
+	// clusterAdminCapability is the permission to create cluster-scoped objects,
+	// like ClusterRoleBindings.
+	clusterAdminCapability = "cluster-admin"
+)
+
+var (
+	clusterCapabilities = initClusterCapabilities()
+
+	// testCapabilities are the capabilities required by the tests which can't
+	// call requireCapabilities themselves, like the examples creating ClusterTasks
+	// or ClusterRoles.
+	testCapabilities = map[string][]string{
+		"TestExamples/v1alpha1/taskruns/build-gcs-targz":                     {gcsCapability},
+		"TestExamples/v1beta1/taskruns/build-gcs-targz":                      {gcsCapability},
+		"TestExamples/v1alpha1/taskruns/build-gcs-zip":                       {gcsCapability},
+		"TestExamples/v1beta1/taskruns/build-gcs-zip":                        {gcsCapability},
+		"TestExamples/v1alpha1/taskruns/gcs-resource":                        {gcsCapability},
+		"TestExamples/v1beta1/taskruns/gcs-resource":                         {gcsCapability},
+		"TestExamples/v1alpha1/pipelineruns/clustertask-pipelinerun":         {clusterAdminCapability},
+		"TestExamples/v1alpha1/pipelineruns/pipelinerun":                     {clusterAdminCapability},
+		"TestExamples/v1alpha1/taskruns/clustertask":                         {clusterAdminCapability},
+		"TestExamples/v1alpha1/taskruns/optional-resources-with-clustertask": {clusterAdminCapability},
+		"TestExamples/v1alpha1/taskruns/task-multiple-output-image":          {clusterAdminCapability},
+		"TestExamples/v1alpha1/taskruns/task-output-image":                   {clusterAdminCapability},
+		"TestExamples/v1beta1/pipelineruns/clustertask-pipelinerun":          {clusterAdminCapability},
+		"TestExamples/v1beta1/pipelineruns/pipelinerun":                      {clusterAdminCapability},
+		"TestExamples/v1beta1/taskruns/clustertask":                          {clusterAdminCapability},
+		"TestExamples/v1beta1/taskruns/optional-resources-with-clustertask":  {clusterAdminCapability},
+		"TestExamples/v1beta1/taskruns/task-multiple-output-image":           {clusterAdminCapability},
+		"TestExamples/v1beta1/taskruns/task-output-image":                    {clusterAdminCapability},
+	}
+)
+
+// return the capabilities of the cluster where test suites will be executed, listed
+// in TEST_CLUSTER_CAPABILITIES, or nil if it isn't set and the cluster is assumed to
+// support them all.
+func initClusterCapabilities() map[string]bool {
+	val, ok := os.LookupEnv("TEST_CLUSTER_CAPABILITIES")
+	if !ok {
+		return nil
+	}
+	return parseCapabilities(val)
+}
+
+// parseCapabilities parses a comma separated list of capabilities.
+func parseCapabilities(s string) map[string]bool {
+	capabilities := map[string]bool{}
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			capabilities[c] = true
+		}
+	}
+	return capabilities
+}
+
+// missingCapabilities returns the sorted capabilities of required which aren't
+// in supported. A nil supported supports all capabilities.
+func missingCapabilities(supported map[string]bool, required []string) []string {
+	if supported == nil {
+		return nil
+	}
+	var missing []string
+	for _, c := range required {
+		if !supported[c] {
+			missing = append(missing, c)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// requireCapabilities skips the test if the cluster doesn't support all capabilities.
+func requireCapabilities(t *testing.T, capabilities ...string) {
+	t.Helper()
+	if missing := missingCapabilities(clusterCapabilities, capabilities); len(missing) > 0 {
+		t.Skipf("skip as the cluster doesn't support %s", strings.Join(missing, ", "))
+	}
+}
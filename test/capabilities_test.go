@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want map[string]bool
+	}{{
+		in:   "",
+		want: map[string]bool{},
+	}, {
+		in:   "gcs",
+		want: map[string]bool{"gcs": true},
+	}, {
+		in:   " GCS, loadbalancer ,,cluster-admin,",
+		want: map[string]bool{"gcs": true, "loadbalancer": true, "cluster-admin": true},
+	}} {
+		t.Run(tc.in, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, parseCapabilities(tc.in)); d != "" {
+				t.Errorf("parseCapabilities(%q) diff -want, +got: %s", tc.in, d)
+			}
+		})
+	}
+}
+
+func TestMissingCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		supported map[string]bool
+		required  []string
+		want      []string
+	}{{
+		name:     "all supported when unset",
+		required: []string{"gcs", "loadbalancer"},
+	}, {
+		name:      "nothing required",
+		supported: map[string]bool{},
+	}, {
+		name:      "none supported",
+		supported: map[string]bool{},
+		required:  []string{"loadbalancer", "gcs"},
+		want:      []string{"gcs", "loadbalancer"},
+	}, {
+		name:      "some supported",
+		supported: map[string]bool{"gcs": true},
+		required:  []string{"gcs", "loadbalancer"},
+		want:      []string{"loadbalancer"},
+	}, {
+		name:      "all supported",
+		supported: map[string]bool{"gcs": true, "loadbalancer": true},
+		required:  []string{"gcs", "loadbalancer"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, missingCapabilities(tc.supported, tc.required)); d != "" {
+				t.Errorf("missingCapabilities() diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestRequireCapabilities(t *testing.T) {
+	defer func(old map[string]bool) { clusterCapabilities = old }(clusterCapabilities)
+	clusterCapabilities = map[string]bool{"gcs": true}
+
+	t.Run("supported", func(t *testing.T) {
+		requireCapabilities(t, gcsCapability)
+		if t.Skipped() {
+			t.Error("Expected the test not to be skipped")
+		}
+	})
+	var skipped bool
+	t.Run("missing", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		requireCapabilities(t, gcsCapability, clusterAdminCapability)
+	})
+	if !skipped {
+		t.Error("Expected the test requiring cluster-admin to be skipped")
+	}
+}
@@ -46,6 +46,8 @@ import (
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/typed/pipeline/v1beta1"
 	resourceversioned "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/client/resource/clientset/versioned/typed/resource/v1alpha1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	knativetest "knative.dev/pkg/test"
 )
 
@@ -60,6 +62,12 @@ type clients struct {
 	PipelineRunClient      v1beta1.PipelineRunInterface
 	PipelineResourceClient resourcev1alpha1.PipelineResourceInterface
 	ConditionClient        v1alpha1.ConditionInterface
+	RunClient              v1alpha1.RunInterface
+
+	// Dynamic and Discovery access objects of any kind, e.g. to check the
+	// objects created by an example without a typed client for them.
+	Dynamic   dynamic.Interface
+	Discovery discovery.DiscoveryInterface
 }
 
 // newClients instantiates and returns several clientsets required for making requests to the
@@ -95,5 +103,15 @@ func newClients(t *testing.T, configPath, clusterName, namespace string) *client
 	c.PipelineRunClient = cs.TektonV1beta1().PipelineRuns(namespace)
 	c.PipelineResourceClient = rcs.TektonV1alpha1().PipelineResources(namespace)
 	c.ConditionClient = cs.TektonV1alpha1().Conditions(namespace)
+	c.RunClient = cs.TektonV1alpha1().Runs(namespace)
+
+	c.Dynamic, err = dynamic.NewForConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create dynamic client from config file at %s: %s", configPath, err)
+	}
+	c.Discovery, err = discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create discovery client from config file at %s: %s", configPath, err)
+	}
 	return c
 }
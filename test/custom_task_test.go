@@ -0,0 +1,149 @@
+// +build e2e
+
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	runv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/apis"
+	knativetest "knative.dev/pkg/test"
+)
+
+const (
+	customTaskAPIVersion = "example.dev/v0"
+	customTaskKind       = "Wait"
+)
+
+// TestCustomTask runs a Pipeline whose first PipelineTask references a custom
+// task, acting as its controller: once the Run is created, the test marks it
+// successful with a result, which the second PipelineTask consumes.
+func TestCustomTask(t *testing.T) {
+	c, namespace := setup(t)
+	knativetest.CleanupOnInterrupt(func() { tearDown(t, c, namespace) }, t.Logf)
+	defer tearDown(t, c, namespace)
+
+	skipIfAlphaAPIFieldsDisabled(t, c)
+
+	pipelineRunName := "custom-task-pipeline"
+	if _, err := c.PipelineRunClient.Create(&v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: pipelineRunName},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineSpec: &v1beta1.PipelineSpec{
+				Tasks: []v1beta1.PipelineTask{{
+					Name: "approval",
+					TaskRef: &v1beta1.TaskRef{
+						APIVersion: customTaskAPIVersion,
+						Kind:       customTaskKind,
+					},
+				}, {
+					Name: "deploy",
+					TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: &v1beta1.TaskSpec{
+						Params: []v1beta1.ParamSpec{{Name: "approver", Type: v1beta1.ParamTypeString}},
+						Steps: []v1beta1.Step{{
+							Container: corev1.Container{Image: "busybox"},
+							Script:    `test "$(params.approver)" = "alice"`,
+						}},
+					}},
+					Params: []v1beta1.Param{{
+						Name:  "approver",
+						Value: v1beta1.NewArrayOrString("$(tasks.approval.results.approver)"),
+					}},
+				}},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("Failed to create PipelineRun %q: %v", pipelineRunName, err)
+	}
+
+	// Wait for the PipelineRun to create the Run of the custom task.
+	var run *v1alpha1.Run
+	selector := fmt.Sprintf("%s=%s,%s=%s",
+		pipeline.GroupName+pipeline.PipelineRunLabelKey, pipelineRunName,
+		pipeline.GroupName+pipeline.PipelineTaskLabelKey, "approval")
+	if err := wait.PollImmediate(interval, time.Minute, func() (bool, error) {
+		runs, err := c.RunClient.List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return true, err
+		}
+		if len(runs.Items) == 0 {
+			return false, nil
+		}
+		run = &runs.Items[0]
+		return true, nil
+	}); err != nil {
+		t.Fatalf("Waiting for the Run of PipelineRun %q: %v", pipelineRunName, err)
+	}
+	if run.Spec.Ref == nil || run.Spec.Ref.APIVersion != customTaskAPIVersion || run.Spec.Ref.Kind != customTaskKind {
+		t.Errorf("Run %q references %v, want %s %s", run.Name, run.Spec.Ref, customTaskAPIVersion, customTaskKind)
+	}
+
+	// Act as the custom task controller, and complete the Run with a result.
+	run.Status.InitializeConditions()
+	run.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+		Reason: "Approved",
+	})
+	run.Status.Results = []runv1alpha1.RunResult{{Name: "approver", Value: "alice"}}
+	if _, err := c.RunClient.UpdateStatus(run); err != nil {
+		t.Fatalf("Failed to update the status of Run %q: %v", run.Name, err)
+	}
+
+	if err := WaitForPipelineRunState(c, pipelineRunName, 5*time.Minute, PipelineRunSucceed(pipelineRunName), "PipelineRunSucceed"); err != nil {
+		t.Fatalf("Waiting for PipelineRun %q to succeed: %v", pipelineRunName, err)
+	}
+
+	pr, err := c.PipelineRunClient.Get(pipelineRunName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PipelineRun %q: %v", pipelineRunName, err)
+	}
+	rs, ok := pr.Status.Runs[run.Name]
+	if !ok {
+		t.Fatalf("PipelineRun %q has no status for Run %q", pipelineRunName, run.Name)
+	}
+	if rs.PipelineTaskName != "approval" {
+		t.Errorf("Run %q has PipelineTask %q, want %q", run.Name, rs.PipelineTaskName, "approval")
+	}
+	if len(pr.Status.TaskRuns) != 1 {
+		t.Errorf("Got %d TaskRun statuses, wanted 1", len(pr.Status.TaskRuns))
+	}
+}
+
+// skipIfAlphaAPIFieldsDisabled skips the test unless the feature flags of the
+// controller allow alpha API fields.
+func skipIfAlphaAPIFieldsDisabled(t *testing.T, c *clients) {
+	t.Helper()
+	cm, err := c.KubeClient.Kube.CoreV1().ConfigMaps(systemNamespace).Get(config.GetFeatureFlagsConfigName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get ConfigMap %q: %v", config.GetFeatureFlagsConfigName(), err)
+	}
+	if cm.Data["enable-api-fields"] != config.AlphaAPIFields {
+		t.Skipf("Custom tasks require %q to be %q", "enable-api-fields", config.AlphaAPIFields)
+	}
+}
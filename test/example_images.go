@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+var (
+	yamlDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+	imageParamReference   = regexp.MustCompile(`\$\((?:inputs\.)?params\.([^)]+)\)`)
+)
+
+// ExampleImages returns the sorted unique images referenced by the `image`
+// fields of the documents of a multi-document YAML file. References to
+// parameters in images are replaced by the value given to the parameter in
+// the same document, or else by its default, and the images referencing
+// parameters without either are left out.
+func ExampleImages(input []byte) ([]string, error) {
+	images := map[string]bool{}
+	for i, doc := range yamlDocumentSeparator.Split(string(input), -1) {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if m, ok := obj.(map[string]interface{}); ok && (m["kind"] == "ConfigMap" || m["kind"] == "Secret") {
+			// Their data may have image keys which aren't container images.
+			continue
+		}
+		var refs []string
+		params := map[string]map[string]string{"value": {}, "default": {}}
+		collectImagesAndParams(obj, "", &refs, params)
+		for _, ref := range refs {
+			ref = imageParamReference.ReplaceAllStringFunc(ref, func(m string) string {
+				name := imageParamReference.FindStringSubmatch(m)[1]
+				if value, ok := params["value"][name]; ok {
+					return value
+				}
+				if value, ok := params["default"][name]; ok {
+					return value
+				}
+				return m
+			})
+			if ref != "" && !strings.Contains(ref, "$(") {
+				images[ref] = true
+			}
+		}
+	}
+
+	var sorted []string
+	for image := range images {
+		sorted = append(sorted, image)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// collectImagesAndParams walks obj, appending the string values of its `image`
+// fields to refs, and adding the string values and defaults of the elements of
+// its `params` lists to params, by field and by parameter name.
+func collectImagesAndParams(obj interface{}, key string, refs *[]string, params map[string]map[string]string) {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if key == "params" {
+			if name, ok := v["name"].(string); ok {
+				for field := range params {
+					if value, ok := v[field].(string); ok {
+						params[field][name] = value
+					}
+				}
+			}
+		}
+		for k, child := range v {
+			if image, ok := child.(string); ok && k == "image" {
+				*refs = append(*refs, image)
+				continue
+			}
+			collectImagesAndParams(child, k, refs, params)
+		}
+	case []interface{}:
+		for _, child := range v {
+			// The elements of a list are identified by the key of the list.
+			collectImagesAndParams(child, key, refs, params)
+		}
+	}
+}
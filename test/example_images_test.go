@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestExampleImages(t *testing.T) {
+	input := []byte(`apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  params:
+  - name: BUILDER_IMAGE
+    default: gcr.io/kaniko-project/executor:latest
+  - name: TAG
+  steps:
+  - name: build
+    image: $(params.BUILDER_IMAGE)
+  - name: push
+    image: "registry.example.com/push:$(inputs.params.TAG)"
+  sidecars:
+  - name: registry
+    image: registry:2
+---
+# A comment between documents
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  generateName: greet-
+spec:
+  params:
+  - name: GREETER
+    value: docker.io/library/busybox
+  taskSpec:
+    params:
+    - name: GREETER
+      default: ubuntu
+    steps:
+    - image: $(params.GREETER)
+      script: echo hello
+    - image: registry:2
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  image: not-an-image-field-of-a-container
+`)
+	got, err := ExampleImages(input)
+	if err != nil {
+		t.Fatalf("ExampleImages() = %v", err)
+	}
+	want := []string{
+		"docker.io/library/busybox",
+		"gcr.io/kaniko-project/executor:latest",
+		"registry:2",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ExampleImages() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestExampleImages_InvalidYAML(t *testing.T) {
+	if _, err := ExampleImages([]byte("kind: Task\n---\nspec: [unterminated\n")); err == nil {
+		t.Error("ExampleImages() should fail for invalid YAML")
+	}
+}
@@ -20,6 +20,7 @@ package test
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -29,12 +30,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	knativetest "knative.dev/pkg/test"
 )
 
 var (
 	pipelineRunTimeout = 10 * time.Minute
+	// prewarmTimeout bounds the pulling of the images of the examples, so
+	// that an image which can't be pulled doesn't stall the examples.
+	prewarmTimeout = 5 * time.Minute
 )
 
 const (
@@ -106,7 +115,7 @@ func KoCreate(input []byte, namespace string) ([]byte, error) {
 // conflicts during test
 func DeleteClusterTask(t *testing.T, c *clients, name string) {
 	t.Logf("Deleting clustertask %s", name)
-	err := c.ClusterTaskClient.Delete(name, &metav1.DeleteOptions{})
+	err := c.Dynamic.Resource(v1beta1.SchemeGroupVersion.WithResource("clustertasks")).Delete(name, &metav1.DeleteOptions{})
 	if err != nil {
 		t.Fatalf("Failed to delete clustertask: %v", err)
 	}
@@ -216,11 +225,117 @@ func extractTestName(baseDir string, path string) string {
 	return string(submatch[1])
 }
 
+// prewarmExampleImages pulls the images referenced by the examples on every
+// node before they run, so that each image is pulled once instead of once per
+// example. It runs a DaemonSet with a container per image, until each image is
+// pulled on every node or prewarmTimeout elapses; the examples are run anyway
+// if some images couldn't be pulled.
+func prewarmExampleImages(t *testing.T, paths []string) {
+	images := sets.NewString()
+	for _, path := range paths {
+		input, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		// The images built by the examples can't be pulled before they run,
+		// but the other images of their repository can.
+		if subbedInput, err := SubstituteEnv(input, "default"); err == nil {
+			input = subbedInput
+		}
+		pathImages, err := ExampleImages(input)
+		if err != nil {
+			t.Logf("Not pre-warming the images of %s: %v", path, err)
+			continue
+		}
+		images.Insert(pathImages...)
+	}
+	if images.Len() == 0 {
+		return
+	}
+
+	c, namespace := setup(t)
+	knativetest.CleanupOnInterrupt(func() { tearDown(t, c, namespace) }, t.Logf)
+	defer tearDown(t, c, namespace)
+
+	labels := map[string]string{"app": "prewarm-example-images"}
+	var containers []corev1.Container
+	for i, image := range images.List() {
+		containers = append(containers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+		})
+	}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "prewarm-example-images", Namespace: namespace},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: containers},
+			},
+		},
+	}
+	t.Logf("Pre-warming %d images of the examples", images.Len())
+	if _, err := c.KubeClient.Kube.AppsV1().DaemonSets(namespace).Create(ds); err != nil {
+		t.Fatalf("Failed to create DaemonSet to pre-warm the images: %v", err)
+	}
+
+	err := wait.PollImmediate(interval, prewarmTimeout, func() (bool, error) {
+		ds, err := c.KubeClient.Kube.AppsV1().DaemonSets(namespace).Get(ds.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		pods, err := c.KubeClient.Kube.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: "app=prewarm-example-images"})
+		if err != nil {
+			return false, err
+		}
+		if ds.Status.DesiredNumberScheduled == 0 || int32(len(pods.Items)) < ds.Status.DesiredNumberScheduled {
+			return false, nil
+		}
+		for _, pod := range pods.Items {
+			if len(pod.Status.ContainerStatuses) < len(containers) {
+				return false, nil
+			}
+			for _, cs := range pod.Status.ContainerStatuses {
+				if !imagePulled(cs) {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Logf("Images of the examples not pre-warmed within %s, running the examples anyway: %v", prewarmTimeout, err)
+	}
+}
+
+// imagePulled returns true if the image of the container has been pulled,
+// whether the container could be started or not.
+func imagePulled(cs corev1.ContainerStatus) bool {
+	if cs.ImageID != "" {
+		return true
+	}
+	if cs.State.Waiting == nil {
+		return false
+	}
+	switch cs.State.Waiting.Reason {
+	case "", "ContainerCreating", "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+		return false
+	}
+	return true
+}
+
 func TestExamples(t *testing.T) {
 	baseDir := "../examples"
 
 	t.Parallel()
-	for _, path := range getExamplePaths(t, baseDir) {
+	paths := getExamplePaths(t, baseDir)
+	if os.Getenv("TEST_EXAMPLES_PREWARM") == "true" {
+		// The examples only start once the pre-warming subtest returns.
+		t.Run("prewarm", func(t *testing.T) { prewarmExampleImages(t, paths) })
+	}
+	for _, path := range paths {
 		testName := extractTestName(baseDir, path)
 		waitValidateFunc := waitValidatePipelineRunDone
 		kind := "pipelinerun"
@@ -139,9 +139,12 @@ func GetTestImage(image int) string {
 	return imageNames[image]
 }
 
-// check if test name is in the excluded list and skip it
+// check if test name is in the excluded list, or requires capabilities the cluster
+// doesn't support, and skip it
 func SkipIfExcluded(t *testing.T) {
+	t.Helper()
 	if excludedTests[t.Name()] {
 		t.Skipf("skip for %s architecture", getTestArch())
 	}
+	requireCapabilities(t, testCapabilities[t.Name()]...)
 }
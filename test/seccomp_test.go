@@ -0,0 +1,89 @@
+// +build e2e
+
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knativetest "knative.dev/pkg/test"
+)
+
+// TestStepSeccompProfile verifies that a Step with the RuntimeDefault seccomp
+// profile runs, and that the profile is set for its container only.
+func TestStepSeccompProfile(t *testing.T) {
+	c, namespace := setup(t)
+	skipIfAlphaAPIFieldsDisabled(t, c)
+	t.Parallel()
+
+	knativetest.CleanupOnInterrupt(func() { tearDown(t, c, namespace) }, t.Logf)
+	defer tearDown(t, c, namespace)
+
+	taskRunName := "seccomp-taskrun"
+
+	t.Logf("Creating TaskRun in namespace %s", namespace)
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: taskRunName, Namespace: namespace},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec: &v1beta1.TaskSpec{
+				Steps: []v1beta1.Step{{
+					Container: corev1.Container{
+						Name:    "filtered",
+						Image:   GetTestImage(BusyboxSha),
+						Command: []string{"/bin/sh"},
+						Args:    []string{"-c", "echo hello"},
+					},
+					SeccompProfile: &v1beta1.SeccompProfile{Type: v1beta1.SeccompProfileTypeRuntimeDefault},
+				}, {
+					Container: corev1.Container{
+						Name:    "unfiltered",
+						Image:   GetTestImage(BusyboxSha),
+						Command: []string{"/bin/sh"},
+						Args:    []string{"-c", "echo hello"},
+					},
+				}},
+			},
+		},
+	}
+	if _, err := c.TaskRunClient.Create(taskRun); err != nil {
+		t.Fatalf("Failed to create TaskRun: %s", err)
+	}
+
+	t.Logf("Waiting for TaskRun in namespace %s to succeed", namespace)
+	if err := WaitForTaskRunState(c, taskRunName, TaskRunSucceed(taskRunName), "TaskRunSucceed"); err != nil {
+		t.Fatalf("Error waiting for TaskRun to finish: %s", err)
+	}
+
+	tr, err := c.TaskRunClient.Get(taskRunName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get expected TaskRun %s: %s", taskRunName, err)
+	}
+	pod, err := c.KubeClient.Kube.CoreV1().Pods(namespace).Get(tr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the Pod %s of TaskRun %s: %s", tr.Status.PodName, taskRunName, err)
+	}
+	if v := pod.Annotations["container.seccomp.security.alpha.kubernetes.io/step-filtered"]; v != "runtime/default" {
+		t.Errorf("Expected the seccomp profile of step-filtered to be runtime/default, got %q", v)
+	}
+	if v, ok := pod.Annotations["container.seccomp.security.alpha.kubernetes.io/step-unfiltered"]; ok {
+		t.Errorf("Expected no seccomp profile for step-unfiltered, got %q", v)
+	}
+}
@@ -49,12 +49,20 @@ import (
 	"strings"
 	"time"
 
+	pipelinescheme "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
 	"go.opencensus.io/trace"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
 	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 )
 
 const (
@@ -71,16 +79,13 @@ type ConditionAccessorFn func(ca apis.ConditionAccessor) (bool, error)
 // track how long it took for name to get into the state checked by inState.
 func WaitForTaskRunState(c *clients, name string, inState ConditionAccessorFn, desc string) error {
 	metricName := fmt.Sprintf("WaitForTaskRunState/%s/%s", name, desc)
-	_, span := trace.StartSpan(context.Background(), metricName)
-	defer span.End()
-
-	return wait.PollImmediate(interval, timeout, func() (bool, error) {
+	return waitForConditionAccessor(metricName, timeout, func() (apis.ConditionAccessor, error) {
 		r, err := c.TaskRunClient.Get(name, metav1.GetOptions{})
 		if err != nil {
-			return true, err
+			return nil, err
 		}
-		return inState(&r.Status)
-	})
+		return &r.Status, nil
+	}, inState)
 }
 
 // WaitForDeploymentState polls the status of the Deployment called name
@@ -125,18 +130,110 @@ func WaitForPodState(c *clients, name string, namespace string, inState func(r *
 // track how long it took for name to get into the state checked by inState.
 func WaitForPipelineRunState(c *clients, name string, polltimeout time.Duration, inState ConditionAccessorFn, desc string) error {
 	metricName := fmt.Sprintf("WaitForPipelineRunState/%s/%s", name, desc)
+	return waitForConditionAccessor(metricName, polltimeout, func() (apis.ConditionAccessor, error) {
+		r, err := c.PipelineRunClient.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &r.Status, nil
+	}, inState)
+}
+
+// WaitForRunState polls the status of the Run called name from client every
+// interval until inState returns `true` indicating it is done, returns an
+// error or timeout. desc will be used to name the metric that is emitted to
+// track how long it took for name to get into the state checked by inState.
+func WaitForRunState(c *clients, name string, polltimeout time.Duration, inState ConditionAccessorFn, desc string) error {
+	metricName := fmt.Sprintf("WaitForRunState/%s/%s", name, desc)
+	return waitForConditionAccessor(metricName, polltimeout, func() (apis.ConditionAccessor, error) {
+		r, err := c.RunClient.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &r.Status, nil
+	}, inState)
+}
+
+// WaitForCondition polls obj, which can be any object with conditions in its
+// status, e.g. a TaskRun, a PipelineRun or a Run, every interval until its
+// condition of type condType has status, returns an error or polltimeout.
+// Only the namespace and name of obj are read, and its kind from its type.
+// As the Succeeded condition doesn't change anymore once it is true or false,
+// an error is returned right away if it is done with another status.
+func WaitForCondition(c *clients, obj runtime.Object, condType apis.ConditionType, status corev1.ConditionStatus, polltimeout time.Duration) error {
+	gvr, err := objectResource(obj)
+	if err != nil {
+		return err
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	metricName := fmt.Sprintf("WaitForCondition/%s/%s/%s/%s", gvr.Resource, m.GetName(), condType, status)
+	client := dynamicResource(c, gvr, m.GetNamespace())
+	return waitForConditionAccessor(metricName, polltimeout, func() (apis.ConditionAccessor, error) {
+		u, err := client.Get(m.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		var st duckv1beta1.Status
+		if s, ok := u.Object["status"].(map[string]interface{}); ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(s, &st); err != nil {
+				return nil, fmt.Errorf("failed to read the status of %s %s: %w", gvr.Resource, m.GetName(), err)
+			}
+		}
+		return &st, nil
+	}, func(ca apis.ConditionAccessor) (bool, error) {
+		cond := ca.GetCondition(condType)
+		switch {
+		case cond == nil:
+			return false, nil
+		case cond.Status == status:
+			return true, nil
+		case condType == apis.ConditionSucceeded && cond.Status != corev1.ConditionUnknown:
+			return true, fmt.Errorf("%s %s is done with condition %s %s instead of %s: %s", gvr.Resource, m.GetName(), condType, cond.Status, status, cond.Message)
+		}
+		return false, nil
+	})
+}
+
+// waitForConditionAccessor polls the status returned by get every interval until
+// inState returns `true` indicating it is done, returns an error or polltimeout.
+// metricName names the metric emitted to track how long it took.
+func waitForConditionAccessor(metricName string, polltimeout time.Duration, get func() (apis.ConditionAccessor, error), inState ConditionAccessorFn) error {
 	_, span := trace.StartSpan(context.Background(), metricName)
 	defer span.End()
 
 	return wait.PollImmediate(interval, polltimeout, func() (bool, error) {
-		r, err := c.PipelineRunClient.Get(name, metav1.GetOptions{})
+		ca, err := get()
 		if err != nil {
 			return true, err
 		}
-		return inState(&r.Status)
+		return inState(ca)
 	})
 }
 
+// objectResource returns the resource of the kind of obj, a Tekton or a
+// Kubernetes object.
+func objectResource(obj runtime.Object) (schema.GroupVersionResource, error) {
+	for _, s := range []*runtime.Scheme{pipelinescheme.Scheme, kubescheme.Scheme} {
+		if gvks, _, err := s.ObjectKinds(obj); err == nil {
+			gvr, _ := meta.UnsafeGuessKindToResource(gvks[0])
+			return gvr, nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("unknown kind of object %T", obj)
+}
+
+// dynamicResource returns the dynamic client of the objects of gvr in namespace,
+// which is empty for cluster-scoped resources.
+func dynamicResource(c *clients, gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return c.Dynamic.Resource(gvr)
+	}
+	return c.Dynamic.Resource(gvr).Namespace(namespace)
+}
+
 // WaitForServiceExternalIPState polls the status of the a k8s Service called name from client every
 // interval until an external ip is assigned indicating it is done, returns an
 // error or timeout. desc will be used to name the metric that is emitted to
@@ -155,6 +252,26 @@ func WaitForServiceExternalIPState(c *clients, namespace, name string, inState f
 	})
 }
 
+// WaitForObjectCondition polls the object called name, of the resource gvr, in
+// namespace every interval until cond returns `true` indicating it is done,
+// returns an error or timeout. namespace is empty for cluster-scoped resources.
+// It works with any kind of object, e.g. Runs of custom tasks or the PVCs
+// created for volumeClaimTemplates, through the dynamic client of c.
+func WaitForObjectCondition(c *clients, gvr schema.GroupVersionResource, namespace, name string, cond func(*unstructured.Unstructured) (bool, error)) error {
+	metricName := fmt.Sprintf("WaitForObjectCondition/%s/%s", gvr.Resource, name)
+	_, span := trace.StartSpan(context.Background(), metricName)
+	defer span.End()
+
+	client := dynamicResource(c, gvr, namespace)
+	return wait.PollImmediate(interval, timeout, func() (bool, error) {
+		u, err := client.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return true, err
+		}
+		return cond(u)
+	})
+}
+
 // Succeed provides a poll condition function that checks if the ConditionAccessor
 // resource has successfully completed or not.
 func Succeed(name string) ConditionAccessorFn {
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	fakepipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+// fakeDynamic serves Get requests for objects keyed by their resource,
+// namespace and name. The fake of client-go isn't vendored.
+type fakeDynamic struct {
+	objects map[string]*unstructured.Unstructured
+}
+
+func (f *fakeDynamic) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &fakeDynamicResource{f: f, gvr: gvr}
+}
+
+type fakeDynamicResource struct {
+	// Calling the methods of the interface which aren't overridden panics.
+	dynamic.NamespaceableResourceInterface
+	f         *fakeDynamic
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (r *fakeDynamicResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &fakeDynamicResource{f: r.f, gvr: r.gvr, namespace: namespace}
+}
+
+func (r *fakeDynamicResource) Get(name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	if u, ok := r.f.objects[r.gvr.Resource+"/"+r.namespace+"/"+name]; ok {
+		return u.DeepCopy(), nil
+	}
+	return nil, k8serrors.NewNotFound(r.gvr.GroupResource(), name)
+}
+
+func TestWaitForObjectCondition(t *testing.T) {
+	runs := v1alpha1.SchemeGroupVersion.WithResource("runs")
+	clusterTasks := v1beta1.SchemeGroupVersion.WithResource("clustertasks")
+	run := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "run", "namespace": "foo"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Succeeded", "status": "True"}},
+		},
+	}}
+	clusterTask := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "build"},
+	}}
+	c := &clients{Dynamic: &fakeDynamic{objects: map[string]*unstructured.Unstructured{
+		"runs/foo/run":        run,
+		"clustertasks//build": clusterTask,
+	}}}
+
+	t.Run("namespaced", func(t *testing.T) {
+		err := WaitForObjectCondition(c, runs, "foo", "run", func(u *unstructured.Unstructured) (bool, error) {
+			conditions, _, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+			return len(conditions) == 1, err
+		})
+		if err != nil {
+			t.Errorf("WaitForObjectCondition() = %v", err)
+		}
+	})
+
+	t.Run("cluster-scoped", func(t *testing.T) {
+		err := WaitForObjectCondition(c, clusterTasks, "", "build", func(u *unstructured.Unstructured) (bool, error) {
+			return u.GetName() == "build", nil
+		})
+		if err != nil {
+			t.Errorf("WaitForObjectCondition() = %v", err)
+		}
+	})
+
+	t.Run("polls until the condition is met", func(t *testing.T) {
+		polls := 0
+		err := WaitForObjectCondition(c, runs, "foo", "run", func(*unstructured.Unstructured) (bool, error) {
+			polls++
+			return polls == 2, nil
+		})
+		if err != nil || polls != 2 {
+			t.Errorf("WaitForObjectCondition() = %v after %d polls, want nil after 2 polls", err, polls)
+		}
+	})
+
+	t.Run("condition error", func(t *testing.T) {
+		want := errors.New("run failed")
+		err := WaitForObjectCondition(c, runs, "foo", "run", func(*unstructured.Unstructured) (bool, error) {
+			return true, want
+		})
+		if !errors.Is(err, want) {
+			t.Errorf("WaitForObjectCondition() = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("object not found", func(t *testing.T) {
+		err := WaitForObjectCondition(c, runs, "bar", "run", func(*unstructured.Unstructured) (bool, error) {
+			t.Error("condition called for an object which doesn't exist")
+			return true, nil
+		})
+		if !k8serrors.IsNotFound(err) {
+			t.Errorf("WaitForObjectCondition() = %v, want a NotFound error", err)
+		}
+	})
+}
+
+func TestWaitForCondition(t *testing.T) {
+	withCondition := func(kind, name, condType, status string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     kind,
+			"metadata": map[string]interface{}{"name": name, "namespace": "foo"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": condType, "status": status, "message": "step build failed"}},
+			},
+		}}
+	}
+	c := &clients{Dynamic: &fakeDynamic{objects: map[string]*unstructured.Unstructured{
+		"taskruns/foo/succeeded":   withCondition("TaskRun", "succeeded", "Succeeded", "True"),
+		"taskruns/foo/failed":      withCondition("TaskRun", "failed", "Succeeded", "False"),
+		"pipelineruns/foo/started": withCondition("PipelineRun", "started", "Succeeded", "Unknown"),
+		"runs/foo/ready":           withCondition("Run", "ready", "Ready", "False"),
+	}}}
+	taskRun := func(name string) *v1beta1.TaskRun {
+		return &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "foo"}}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		typed    bool
+		condType apis.ConditionType
+		status   corev1.ConditionStatus
+		wantErr  string
+	}{{
+		name:     "taskrun succeeded",
+		typed:    true,
+		obj:      withCondition("TaskRun", "succeeded", "", ""),
+		condType: apis.ConditionSucceeded,
+		status:   corev1.ConditionTrue,
+	}, {
+		name:     "taskrun failed",
+		typed:    true,
+		obj:      withCondition("TaskRun", "failed", "", ""),
+		condType: apis.ConditionSucceeded,
+		status:   corev1.ConditionFalse,
+	}, {
+		name:     "taskrun done with another status",
+		typed:    true,
+		obj:      withCondition("TaskRun", "failed", "", ""),
+		condType: apis.ConditionSucceeded,
+		status:   corev1.ConditionTrue,
+		wantErr:  "taskruns failed is done with condition Succeeded False instead of True: step build failed",
+	}, {
+		name:     "pipelinerun running",
+		obj:      withCondition("PipelineRun", "started", "", ""),
+		condType: apis.ConditionSucceeded,
+		status:   corev1.ConditionUnknown,
+	}, {
+		name:     "other condition type",
+		obj:      withCondition("Run", "ready", "", ""),
+		condType: apis.ConditionReady,
+		status:   corev1.ConditionFalse,
+	}, {
+		name:     "not found",
+		typed:    true,
+		obj:      withCondition("TaskRun", "missing", "", ""),
+		condType: apis.ConditionSucceeded,
+		status:   corev1.ConditionTrue,
+		wantErr:  `taskruns.tekton.dev "missing" not found`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.typed {
+				err = WaitForCondition(c, taskRun(tc.obj.GetName()), tc.condType, tc.status, time.Second)
+			} else {
+				tc.obj.SetAPIVersion(map[string]string{"PipelineRun": "tekton.dev/v1beta1", "Run": "tekton.dev/v1alpha1"}[tc.obj.GetKind()])
+				err = WaitForCondition(c, tc.obj, tc.condType, tc.status, time.Second)
+			}
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("WaitForCondition() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("WaitForCondition() = %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("times out", func(t *testing.T) {
+		err := WaitForCondition(c, taskRun("succeeded"), apis.ConditionReady, corev1.ConditionTrue, 10*time.Millisecond)
+		if err == nil {
+			t.Error("WaitForCondition() = nil, want a timeout error")
+		}
+	})
+}
+
+func TestWaitForTaskRunState(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+		Status: v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+			Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}},
+		}},
+	}
+	c := &clients{TaskRunClient: fakepipelineclientset.NewSimpleClientset(tr).TektonV1beta1().TaskRuns("foo")}
+
+	if err := WaitForTaskRunState(c, "run", TaskRunFailed("run"), "TaskRunFailed"); err != nil {
+		t.Errorf("WaitForTaskRunState() = %v", err)
+	}
+	want := `"run" failed`
+	if err := WaitForTaskRunState(c, "run", TaskRunSucceed("run"), "TaskRunSucceed"); err == nil || err.Error() != want {
+		t.Errorf("WaitForTaskRunState() = %v, want %s", err, want)
+	}
+}